@@ -207,7 +207,13 @@ build_image() {
         build_args="--no-cache"
     fi
     
-    docker build ${build_args} -t skynet:latest .
+    local git_commit=$(git rev-parse --short HEAD 2>/dev/null || echo "")
+    local build_date=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+
+    docker build ${build_args} \
+        --build-arg GIT_COMMIT="${git_commit}" \
+        --build-arg BUILD_DATE="${build_date}" \
+        -t skynet:latest .
     log_info "Build completed successfully"
 }
 
@@ -0,0 +1,294 @@
+/*
+Package main implements the command line subcommands of the Skynet Agent.
+
+Besides serving the HTTP API, the binary runs the agent directly in the
+terminal, sharing the configuration and the agent setup of the server:
+
+	skynet [--config file] serve              Serve the HTTP API (default)
+	skynet [--config file] chat               Interactive conversation in the terminal
+	skynet [--config file] exec -m "message"  Run one message and print the response
+	skynet tui [--server url]                 Terminal UI for a local or remote server
+	skynet [--config file] validate           Check the configuration against this host
+
+The command tree is built with cobra, so --config may be given before or after
+the subcommand, and "skynet help <command>" or --help describes every flag.
+
+chat and exec run the agent in process without opening a port. Their logs go to
+stderr at the warn level unless --verbose is given, so the response on stdout can
+be piped into other programs. tui instead is a client of a running server and
+talks to its HTTP API.
+*/
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"skynet/core"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newRootCommand builds the command tree of the binary. The root command serves
+// the HTTP API, so running skynet without a subcommand keeps serving by default.
+//
+// Parameters:
+//   - exitCode: Receives the process exit code of the command that ran
+//
+// Returns:
+//   - *cobra.Command: Root command to execute
+func newRootCommand(exitCode *int) *cobra.Command {
+	var configPath string
+	root := &cobra.Command{
+		Use:   "skynet",
+		Short: "AI agent for infrastructure operations",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			*exitCode = runServe(configPath)
+		},
+	}
+	root.CompletionOptions.DisableDefaultCmd = true
+	root.PersistentFlags().StringVar(&configPath, "config", "", "YAML or TOML configuration file (default: $SKYNET_CONFIG or ./skynet.yaml)")
+
+	serve := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the HTTP API (default)",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			*exitCode = runServe(configPath)
+		},
+	}
+
+	var chatSession string
+	var chatVerbose bool
+	chat := &cobra.Command{
+		Use:   "chat",
+		Short: "Interactive conversation in the terminal",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			*exitCode = runChat(configPath, chatSession, chatVerbose)
+		},
+	}
+	chat.Flags().StringVar(&chatSession, "session", "", "Session ID to continue; empty starts a new session")
+	chat.Flags().BoolVar(&chatVerbose, "verbose", false, "Log agent activity to stderr")
+
+	var execMessage string
+	var execVerbose bool
+	exec := &cobra.Command{
+		Use:   "exec -m message",
+		Short: "Run one message and print the response",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			*exitCode = runExec(configPath, execMessage, execVerbose)
+		},
+	}
+	exec.Flags().StringVarP(&execMessage, "message", "m", "", "Message to run; \"-\" reads it from stdin")
+	exec.Flags().BoolVar(&execVerbose, "verbose", false, "Log agent activity to stderr")
+
+	defaultServer := os.Getenv("SKYNET_SERVER")
+	if defaultServer == "" {
+		defaultServer = "http://localhost:8080"
+	}
+	var tuiServer, tuiSession string
+	tui := &cobra.Command{
+		Use:   "tui",
+		Short: "Terminal UI for a local or remote server",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			*exitCode = runTUI(tuiServer, tuiSession)
+		},
+	}
+	tui.Flags().StringVar(&tuiServer, "server", defaultServer, "Base URL of the Skynet server ($SKYNET_SERVER)")
+	tui.Flags().StringVar(&tuiSession, "session", "", "Session ID to continue; empty starts a new session")
+
+	validate := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration against this host",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			*exitCode = runValidate(configPath)
+		},
+	}
+
+	root.AddCommand(serve, chat, exec, tui, validate)
+	return root
+}
+
+// loadConfig loads the configuration, printing the error to stderr on failure.
+func loadConfig(configPath string) (*core.Config, bool) {
+	config, err := core.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return nil, false
+	}
+	return config, true
+}
+
+// newLocalServer creates the agent for the terminal subcommands. Logs go to stderr,
+// and only warnings and errors are logged unless verbose is set.
+func newLocalServer(config *core.Config, verbose bool) (*core.Server, bool) {
+	if !verbose {
+		config.LogLevel = "warn"
+	}
+	// Requests report provider errors directly, so the background readiness probe is not needed
+	config.LLMProbeRetries = 0
+	// Responses are printed to stdout, so logs meant for it go to stderr
+	config.LogOutput = strings.ReplaceAll(strings.ToLower(config.LogOutput), "stdout", "stderr")
+	logger := core.InitializeLogger(config)
+	if !verbose {
+		logrus.SetLevel(logrus.WarnLevel)
+	}
+
+	server, err := core.NewServer(config, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create agent: %v\n", err)
+		return nil, false
+	}
+	return server, true
+}
+
+// runChat runs an interactive conversation with the agent in the terminal. Every
+// line read from stdin is one message; the conversation keeps its session memory
+// until "exit" or "quit" or the end of input.
+//
+// Parameters:
+//   - configPath: Configuration file given with the global --config flag
+//   - sessionID: Session to continue; empty starts a new session
+//   - verbose: Whether agent activity is logged below the warn level
+//
+// Returns:
+//   - int: Process exit code
+func runChat(configPath, sessionID string, verbose bool) int {
+
+	config, ok := loadConfig(configPath)
+	if !ok {
+		return 1
+	}
+	server, ok := newLocalServer(config, verbose)
+	if !ok {
+		return 1
+	}
+	defer server.Close()
+
+	// Interrupting a running message cancels it instead of ending the conversation
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	fmt.Printf("Skynet %s (%s). Type \"exit\" to quit.\n", core.Version, config.LLMProvider)
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for {
+		fmt.Print("skynet> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			break
+		}
+
+		message := strings.TrimSpace(scanner.Text())
+		if message == "" {
+			continue
+		}
+		if message == "exit" || message == "quit" {
+			break
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-interrupt:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		response, err := server.Chat(ctx, sessionID, message)
+		cancel()
+
+		sessionID = response.SessionID
+		if err != nil {
+			fmt.Fprintln(os.Stderr, response.Response)
+			continue
+		}
+		fmt.Println(response.Response)
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runExec runs a single message through the agent and prints the response. The
+// message is taken from -m, or read from stdin when -m is "-".
+//
+// Parameters:
+//   - configPath: Configuration file given with the global --config flag
+//   - message: Message to run; "-" reads it from stdin
+//   - verbose: Whether agent activity is logged below the warn level
+//
+// Returns:
+//   - int: Process exit code; 1 when the agent failed
+func runExec(configPath, message string, verbose bool) int {
+	if message == "-" {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read message: %v\n", err)
+			return 1
+		}
+		message = string(input)
+	}
+	if strings.TrimSpace(message) == "" {
+		fmt.Fprintln(os.Stderr, "A message is required: skynet exec -m \"message\"")
+		return 2
+	}
+
+	config, ok := loadConfig(configPath)
+	if !ok {
+		return 1
+	}
+	server, ok := newLocalServer(config, verbose)
+	if !ok {
+		return 1
+	}
+	defer server.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	response, err := server.Chat(ctx, "", strings.TrimSpace(message))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, response.Response)
+		return 1
+	}
+	fmt.Println(response.Response)
+	return 0
+}
+
+// runValidate checks the configuration against this host and prints a report.
+//
+// Parameters:
+//   - configPath: Configuration file given with the global --config flag
+//
+// Returns:
+//   - int: Process exit code; 1 when a check failed
+func runValidate(configPath string) int {
+
+	config, ok := loadConfig(configPath)
+	if !ok {
+		return 1
+	}
+
+	report := core.ValidateEnvironment(context.Background(), config)
+	report.Write(os.Stdout)
+	if report.Failed() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+/*
+Package core provides the network allowlist for the Skynet Agent application.
+
+The agent can run root commands on request, so authentication alone should not
+be the only barrier. With ALLOWED_CIDRS set, the HTTP server accepts requests
+only from addresses in the listed networks, such as "10.0.0.0/8,192.168.1.0/24";
+single addresses may omit the prefix length. Other clients receive 403 before
+any route is matched.
+
+The address checked is the TCP peer of the connection. X-Forwarded-For and
+X-Real-IP are ignored, since any client can set them; behind a reverse proxy the
+proxy's address must be allowed, and the proxy is responsible for filtering.
+*/
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// parseAllowedCIDRs parses ALLOWED_CIDRS entries, accepting plain addresses as single-host networks.
+//
+// Parameters:
+//   - entries: CIDR networks or IP addresses
+//
+// Returns:
+//   - []*net.IPNet: Parsed networks
+//   - error: An entry that is neither a network nor an address
+func parseAllowedCIDRs(entries []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ALLOWED_CIDRS entry %q: not a network or IP address", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ALLOWED_CIDRS entry %q: %w", entry, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// peerIP returns the address of the connection's remote end.
+func peerIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// allowlistMiddleware rejects requests from addresses outside ALLOWED_CIDRS.
+// The list is read from the current configuration, so a reload applies it to new requests.
+func (s *Server) allowlistMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		entries := s.currentConfig().AllowedCIDRs
+		if len(entries) == 0 {
+			return next(c)
+		}
+		// Entries were checked by Config.Validate
+		networks, _ := parseAllowedCIDRs(entries)
+
+		ip := peerIP(c.Request())
+		for _, network := range networks {
+			if ip != nil && network.Contains(ip) {
+				return next(c)
+			}
+		}
+
+		s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+			"remoteAddr": c.Request().RemoteAddr,
+			"method":     c.Request().Method,
+			"path":       c.Request().URL.Path,
+		}).Warn("Rejected request from address outside ALLOWED_CIDRS")
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Access from this address is not allowed"})
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Package core provides the Prometheus Alertmanager webhook receiver for the Skynet Agent application.
+
+POST /integrations/alertmanager accepts Alertmanager webhook notifications. Every
+firing alert starts an agent execution in the background that investigates it
+with ALERTMANAGER_PROMPT, a Go template rendered with the alert. Investigations
+share the request queue with chat requests, and an alert that is already being
+investigated is not investigated again. Each alert gets its own session
+(alert_<fingerprint>), so an alert that fires again is investigated with the
+findings of the previous run in context.
+
+The findings are posted as JSON to ALERTMANAGER_NOTIFY_URL. Alertmanager has to
+authenticate with ALERTMANAGER_TOKEN (http_config.authorization in its receiver
+configuration); the receiver is disabled without a token.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultAlertPrompt is the investigation prompt used unless ALERTMANAGER_PROMPT is set
+const defaultAlertPrompt = `A Prometheus alert is firing. Investigate it on this host with read-only commands, then report the most likely cause, the evidence you found, and the remediation you recommend. Do not change the system.
+
+Alert: {{.Labels.alertname}}
+Severity: {{or .Labels.severity "unknown"}}
+Started: {{.StartsAt.Format "2006-01-02 15:04:05 MST"}}
+Labels:{{range $name, $value := .Labels}}
+  {{$name}}={{$value}}{{end}}
+Annotations:{{range $name, $value := .Annotations}}
+  {{$name}}: {{$value}}{{end}}`
+
+// AlertmanagerPayload is the webhook notification sent by Alertmanager (version 4).
+type AlertmanagerPayload struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []Alert           `json:"alerts"`
+}
+
+// Alert is one alert of an Alertmanager notification.
+type Alert struct {
+	Status       string            `json:"status"` // firing or resolved
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// AlertInvestigation is the result of an alert investigation posted to ALERTMANAGER_NOTIFY_URL.
+type AlertInvestigation struct {
+	Alert       Alert  `json:"alert"`           // The investigated alert
+	SessionID   string `json:"sessionId"`       // Session holding the investigation
+	ExecutionID string `json:"executionId"`     // Execution whose timeline GET /executions/:id/trace returns
+	Findings    string `json:"findings"`        // The agent's report
+	Error       string `json:"error,omitempty"` // Why the investigation failed
+}
+
+// alertInvestigations tracks the alerts being investigated, by fingerprint.
+type alertInvestigations struct {
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+// start marks an alert as being investigated; it returns false if it already is.
+func (a *alertInvestigations) start(fingerprint string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running[fingerprint] {
+		return false
+	}
+	a.running[fingerprint] = true
+	return true
+}
+
+// finish marks an alert investigation as done.
+func (a *alertInvestigations) finish(fingerprint string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.running, fingerprint)
+}
+
+// alertFingerprint returns the Alertmanager fingerprint of an alert, or one derived from its labels.
+func alertFingerprint(alert Alert) string {
+	if alert.Fingerprint != "" {
+		return alert.Fingerprint
+	}
+	names := make([]string, 0, len(alert.Labels))
+	for name := range alert.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var key strings.Builder
+	for _, name := range names {
+		key.WriteString(name + "=" + alert.Labels[name] + ",")
+	}
+	return fmt.Sprintf("%x", key.String())
+}
+
+// handleAlertmanager handles POST /integrations/alertmanager requests by starting
+// an investigation for every firing alert of the notification.
+func (s *Server) handleAlertmanager(c echo.Context) error {
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"endpoint": "/integrations/alertmanager",
+		"method":   "POST",
+		"clientIP": c.RealIP(),
+	})
+
+	config := s.currentConfig()
+	if config.AlertmanagerToken == "" && !s.apiKeys.HasScope(ScopeAlertmanager) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Alertmanager integration is disabled; set ALERTMANAGER_TOKEN to enable it"})
+	}
+	if locked, err := s.rejectLockedOut(c, "alertmanager"); locked {
+		return err
+	}
+	provided, found := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if !found || !s.authorizedBearer(provided, config.AlertmanagerToken, ScopeAlertmanager) {
+		requestLogger.Warn("Rejected Alertmanager notification with invalid token")
+		s.authFailed(c, "alertmanager")
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+	s.authSucceeded(c)
+
+	var payload AlertmanagerPayload
+	if err := c.Bind(&payload); err != nil {
+		requestLogger.WithError(err).Error("Failed to parse Alertmanager notification")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid Alertmanager payload"})
+	}
+
+	prompt, err := template.New("alert").Option("missingkey=zero").Parse(config.AlertmanagerPrompt)
+	if err != nil {
+		requestLogger.WithError(err).Error("Invalid ALERTMANAGER_PROMPT template")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Invalid investigation prompt template"})
+	}
+
+	started := []map[string]string{}
+	for _, alert := range payload.Alerts {
+		if alert.Status != "firing" {
+			continue
+		}
+		fingerprint := alertFingerprint(alert)
+		alertLogger := requestLogger.WithFields(logrus.Fields{
+			"alert":       alert.Labels["alertname"],
+			"fingerprint": fingerprint,
+		})
+
+		if !s.alerts.start(fingerprint) {
+			alertLogger.Info("Alert is already being investigated")
+			continue
+		}
+
+		var message bytes.Buffer
+		if err := prompt.Execute(&message, alert); err != nil {
+			s.alerts.finish(fingerprint)
+			alertLogger.WithError(err).Error("Failed to render investigation prompt")
+			continue
+		}
+
+		ticket, err := s.queue.Enqueue()
+		if err != nil {
+			s.alerts.finish(fingerprint)
+			alertLogger.Warn("Request queue is full, not investigating alert")
+			continue
+		}
+
+		// The investigation outlives the webhook request but keeps its request ID
+		ctx := localtools.WithRequestID(context.Background(), localtools.RequestIDFromContext(c.Request().Context()))
+		sessionID := "alert_" + fingerprint
+		go s.investigateAlert(ctx, ticket, alert, sessionID, message.String(), alertLogger)
+
+		alertLogger.Info("Started alert investigation")
+		started = append(started, map[string]string{"fingerprint": fingerprint, "sessionId": sessionID})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{"investigations": started})
+}
+
+// investigateAlert runs the agent on an alert once the queue admits it and posts the findings.
+func (s *Server) investigateAlert(ctx context.Context, ticket *QueueTicket, alert Alert, sessionID, message string, alertLogger *logrus.Entry) {
+	defer s.alerts.finish(alertFingerprint(alert))
+	defer ticket.Release()
+	if err := ticket.Wait(ctx, nil); err != nil {
+		return
+	}
+
+	ctx = WithNotificationReport(ctx, fmt.Sprintf("Alert %s investigation", alert.Labels["alertname"]))
+	response, err := s.chat(ctx, sessionID, message, "alertmanager", alertLogger)
+	result := AlertInvestigation{
+		Alert:       alert,
+		SessionID:   response.SessionID,
+		ExecutionID: response.ExecutionID,
+		Findings:    response.Response,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if err := s.postAlertFindings(ctx, result); err != nil {
+		alertLogger.WithError(err).Error("Failed to post alert investigation findings")
+		return
+	}
+	alertLogger.WithField("executionID", result.ExecutionID).Info("Alert investigation completed")
+}
+
+// postAlertFindings sends an investigation result to ALERTMANAGER_NOTIFY_URL, if configured.
+func (s *Server) postAlertFindings(ctx context.Context, result AlertInvestigation) error {
+	notifyURL := s.currentConfig().AlertmanagerNotifyURL
+	if notifyURL == "" {
+		return nil
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notifyURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("notification endpoint returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,415 @@
+/*
+Package core provides conversation analytics for the Skynet Agent application.
+
+This file implements an optional post-processing job that tags each completed
+agent execution with an intent category, an outcome, and the tools that were
+used. Tags are produced asynchronously by a background worker so classification
+never adds latency to chat responses, and are kept in memory (optionally also
+appended to a JSONL file) for reporting through GET /analytics.
+
+Key components:
+- ConversationTags: Classification result for a single execution
+- ToolUsageRecorder: Per-execution collector of invoked tool names
+- TrackedTool: Tool wrapper feeding the recorder found in the context
+- Analytics: Background classifier and tag store with summary reporting
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// Conversation outcome values recorded in ConversationTags.Outcome
+const (
+	OutcomeSuccess   = "success"   // The agent produced a final answer
+	OutcomeFailed    = "failed"    // The agent execution returned an error
+	OutcomeCancelled = "cancelled" // The execution was stopped by the user
+)
+
+// maxStoredTags bounds the number of tags retained in memory for reporting.
+const maxStoredTags = 10000
+
+// ConversationTags holds the analytics classification of a single agent execution.
+type ConversationTags struct {
+	Timestamp   time.Time `json:"timestamp"`             // When the execution completed
+	SessionID   string    `json:"sessionId"`             // Chat session the execution belongs to
+	ExecutionID string    `json:"executionId,omitempty"` // Agent execution identifier
+	Intent      string    `json:"intent"`                // Intent category, e.g. "containers" or "network"
+	Outcome     string    `json:"outcome"`               // One of "success", "failed", "cancelled"
+	ToolsUsed   []string  `json:"toolsUsed"`             // Distinct tools invoked during the execution
+	Owner       string    `json:"owner,omitempty"`       // Owner of the execution's session, which limits who may see the tags
+	Tenant      string    `json:"tenant,omitempty"`      // Tenant the execution was accounted to
+}
+
+// intentCategory describes how a single intent category is recognized.
+type intentCategory struct {
+	name     string   // Category name reported in tags
+	keywords []string // Lower-case words matched against the words of the user message
+	tools    []string // Tools whose use indicates this category
+}
+
+// intentCategories lists the known categories in priority order; earlier entries win ties.
+// Every built-in tool belongs to one category or to intentNeutralTools, which the
+// server checks at startup.
+var intentCategories = []intentCategory{
+	{name: "kubernetes", keywords: []string{"kubernetes", "k8s", "kubectl", "pod", "deployment", "namespace", "helm", "chart", "cluster"}, tools: []string{"kubectl", "helm", "pod_logs"}},
+	{name: "containers", keywords: []string{"docker", "podman", "container", "image", "compose"}, tools: []string{"docker", "podman"}},
+	{name: "packages", keywords: []string{"install", "package", "apk", "pip", "npm", "upgrade", "dependency", "module"}, tools: []string{"apk", "pip", "npm", "go"}},
+	{name: "services", keywords: []string{"service", "systemctl", "daemon", "restart", "enable", "cron", "schedule", "timer"}, tools: []string{"systemctl", "cron"}},
+	{name: "network", keywords: []string{"network", "ping", "port", "dns", "curl", "connection", "firewall", "http", "download", "upload", "vpn", "wireguard", "packet"}, tools: []string{"network", "netstat", "http", "transfer", "capture", "wireguard", "webserver"}},
+	{name: "security", keywords: []string{"security", "certificate", "ssl", "tls", "certbot", "gpg", "encrypt", "decrypt", "signature", "checksum", "ban", "fail2ban"}, tools: []string{"certbot", "gpg", "fail2ban", "hash"}},
+	{name: "cloud", keywords: []string{"aws", "ec2", "s3", "cloud"}, tools: []string{"aws"}},
+	{name: "remote", keywords: []string{"ssh", "remote", "host", "target", "ansible", "playbook", "inventory"}, tools: []string{"ansible"}},
+	{name: "monitoring", keywords: []string{"cpu", "memory", "disk", "process", "load", "uptime", "usage", "performance", "kernel", "sysctl"}, tools: []string{"top", "ps", "sysinfo", "proc", "lsof", "perf", "smart", "env"}},
+	{name: "logs", keywords: []string{"log", "journal", "dmesg"}, tools: []string{"logs", "dmesg"}},
+	{name: "models", keywords: []string{"ollama", "model", "llm"}, tools: []string{"ollama"}},
+	{name: "files", keywords: []string{"file", "directory", "folder", "read", "write", "edit", "delete", "copy", "move", "chmod"}, tools: []string{"file", "cat", "tee", "ls", "stat", "cd", "grep", "text"}},
+	{name: "shell", keywords: []string{"script", "command", "run", "execute", "bash"}, tools: []string{"shell"}},
+}
+
+// intentNeutralTools lists the built-in tools whose use says nothing about the intent
+var intentNeutralTools = []string{"calc", "datetime", moreToolName}
+
+// classifyIntent scores each intent category against the user message and the tools used.
+// Each keyword found as a word of the message, or as its plural, scores one point and
+// each matching tool scores two, since tool usage is stronger evidence of what the
+// conversation was about.
+//
+// Parameters:
+//   - message: The user's message
+//   - toolsUsed: Names of the tools invoked during the execution
+//
+// Returns:
+//   - string: The best matching category, or "general" if nothing matched
+func classifyIntent(message string, toolsUsed []string) string {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = true
+	}
+
+	best, bestScore := "general", 0
+	for _, category := range intentCategories {
+		score := 0
+		for _, keyword := range category.keywords {
+			if words[keyword] || words[keyword+"s"] || words[keyword+"es"] {
+				score++
+			}
+		}
+		for _, used := range toolsUsed {
+			if slices.Contains(category.tools, used) {
+				score += 2
+			}
+		}
+		if score > bestScore {
+			best, bestScore = category.name, score
+		}
+	}
+	return best
+}
+
+// unclassifiedTools returns the names of the given tools that belong to no intent
+// category and are not listed as neutral.
+//
+// Parameters:
+//   - toolsList: Tools to check, typically the built-in tools
+//
+// Returns:
+//   - []string: Names of the tools analytics cannot attribute to an intent
+func unclassifiedTools(toolsList []tools.Tool) []string {
+	var names []string
+	for _, tool := range toolsList {
+		name := tool.Name()
+		if slices.Contains(intentNeutralTools, name) {
+			continue
+		}
+		if !slices.ContainsFunc(intentCategories, func(category intentCategory) bool {
+			return slices.Contains(category.tools, name)
+		}) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// toolUsageKey is the unexported context key type for ToolUsageRecorder values.
+type toolUsageKey struct{}
+
+// ToolUsageRecorder collects the distinct tools invoked during one execution.
+type ToolUsageRecorder struct {
+	tools []string   // Distinct tool names in first-use order
+	mutex sync.Mutex // Guards tools against concurrent tool calls
+}
+
+// WithToolUsageRecorder returns a derived context carrying a fresh recorder.
+//
+// Parameters:
+//   - ctx: Parent context
+//
+// Returns:
+//   - context.Context: Derived context carrying the recorder
+//   - *ToolUsageRecorder: The attached recorder
+func WithToolUsageRecorder(ctx context.Context) (context.Context, *ToolUsageRecorder) {
+	recorder := &ToolUsageRecorder{tools: make([]string, 0)}
+	return context.WithValue(ctx, toolUsageKey{}, recorder), recorder
+}
+
+// Record notes that the named tool was used.
+func (r *ToolUsageRecorder) Record(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, existing := range r.tools {
+		if existing == name {
+			return
+		}
+	}
+	r.tools = append(r.tools, name)
+}
+
+// Tools returns a copy of the distinct tool names recorded so far.
+func (r *ToolUsageRecorder) Tools() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]string(nil), r.tools...)
+}
+
+// TrackedTool wraps a tool and records its use in the ToolUsageRecorder found in the context.
+type TrackedTool struct {
+	tool tools.Tool // The underlying tool being tracked
+}
+
+// Name returns the wrapped tool's name.
+func (t *TrackedTool) Name() string {
+	return t.tool.Name()
+}
+
+// Description returns the wrapped tool's description.
+func (t *TrackedTool) Description() string {
+	return t.tool.Description()
+}
+
+// Call records the tool use when a recorder is present and invokes the wrapped tool.
+func (t *TrackedTool) Call(ctx context.Context, input string) (string, error) {
+	if recorder, ok := ctx.Value(toolUsageKey{}).(*ToolUsageRecorder); ok {
+		recorder.Record(t.tool.Name())
+	}
+	return t.tool.Call(ctx, input)
+}
+
+// WrapToolsWithUsageTracking wraps every tool so its use is recorded per execution.
+//
+// Parameters:
+//   - toolsList: Tools to wrap
+//
+// Returns:
+//   - []tools.Tool: Tools with usage tracking applied
+func WrapToolsWithUsageTracking(toolsList []tools.Tool) []tools.Tool {
+	wrapped := make([]tools.Tool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		wrapped = append(wrapped, &TrackedTool{tool: tool})
+	}
+	return wrapped
+}
+
+// analyticsJob is a completed execution waiting to be classified.
+type analyticsJob struct {
+	info      ExecutionInfo // Execution identifiers
+	message   string        // The user's original message
+	toolsUsed []string      // Tools invoked during the execution
+	outcome   string        // Execution outcome
+	completed time.Time     // When the execution completed
+}
+
+// Analytics classifies completed executions in the background and stores the resulting tags.
+type Analytics struct {
+	jobs   chan analyticsJob  // Queue of executions awaiting classification
+	tags   []ConversationTags // Stored tags, oldest first, bounded by maxStoredTags
+	file   *os.File           // Optional JSONL file receiving every tag
+	mutex  sync.RWMutex       // Guards tags and file writes
+	logger *logrus.Logger     // Structured logger for operational monitoring
+}
+
+// NewAnalytics creates the analytics store and starts its background worker.
+// When path is non-empty tags are additionally appended to that JSONL file.
+//
+// Parameters:
+//   - path: Optional JSONL file path for persisting tags
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *Analytics: Running analytics store
+//   - error: Any error opening the persistence file
+func NewAnalytics(path string, logger *logrus.Logger) (*Analytics, error) {
+	analytics := &Analytics{
+		jobs:   make(chan analyticsJob, 256),
+		tags:   make([]ConversationTags, 0),
+		logger: logger,
+	}
+
+	if path != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create analytics directory: %w", err)
+		}
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open analytics log: %w", err)
+		}
+		analytics.file = file
+	}
+
+	// Start background classification worker
+	go analytics.processJobs()
+
+	logger.WithField("path", path).Info("Conversation analytics initialized")
+	return analytics, nil
+}
+
+// Submit queues a completed execution for classification.
+// If the queue is full the execution is dropped rather than blocking the request.
+//
+// Parameters:
+//   - info: Execution identifiers
+//   - message: The user's original message
+//   - toolsUsed: Tools invoked during the execution
+//   - outcome: Execution outcome (OutcomeSuccess, OutcomeFailed, OutcomeCancelled)
+func (a *Analytics) Submit(info ExecutionInfo, message string, toolsUsed []string, outcome string) {
+	job := analyticsJob{
+		info:      info,
+		message:   message,
+		toolsUsed: toolsUsed,
+		outcome:   outcome,
+		completed: time.Now(),
+	}
+
+	select {
+	case a.jobs <- job:
+	default:
+		a.logger.WithField("sessionID", info.SessionID).Warn("Analytics queue full, dropping conversation")
+	}
+}
+
+// processJobs runs as a background goroutine classifying queued executions.
+func (a *Analytics) processJobs() {
+	for job := range a.jobs {
+		tags := ConversationTags{
+			Timestamp:   job.completed,
+			SessionID:   job.info.SessionID,
+			ExecutionID: job.info.ExecutionID,
+			Intent:      classifyIntent(job.message, job.toolsUsed),
+			Outcome:     job.outcome,
+			ToolsUsed:   job.toolsUsed,
+			Owner:       job.info.Owner,
+			Tenant:      job.info.Tenant,
+		}
+		a.store(tags)
+
+		a.logger.WithFields(logrus.Fields{
+			"sessionID": tags.SessionID,
+			"intent":    tags.Intent,
+			"outcome":   tags.Outcome,
+			"toolsUsed": tags.ToolsUsed,
+		}).Debug("Conversation tagged")
+	}
+}
+
+// store appends tags to memory (evicting the oldest beyond maxStoredTags) and the optional file.
+func (a *Analytics) store(tags ConversationTags) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.tags = append(a.tags, tags)
+	if len(a.tags) > maxStoredTags {
+		a.tags = a.tags[len(a.tags)-maxStoredTags:]
+	}
+
+	if a.file != nil {
+		data, err := json.Marshal(tags)
+		if err != nil {
+			a.logger.WithError(err).Error("Failed to encode conversation tags")
+			return
+		}
+		if _, err := a.file.Write(append(data, '\n')); err != nil {
+			a.logger.WithError(err).Error("Failed to write conversation tags")
+		}
+	}
+}
+
+// Tags returns the stored tags the caller may see, optionally filtered by session,
+// most recent last.
+//
+// Parameters:
+//   - sessionID: Only return tags for this session when non-empty
+//   - caller: Caller asking for the tags; admins see all of them
+//
+// Returns:
+//   - []ConversationTags: Matching tags in chronological order
+func (a *Analytics) Tags(sessionID string, caller sessionCaller) []ConversationTags {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	result := make([]ConversationTags, 0)
+	for _, tags := range a.tags {
+		if (sessionID == "" || tags.SessionID == sessionID) && caller.mayAccess(tags.Owner, tags.Tenant) {
+			result = append(result, tags)
+		}
+	}
+	return result
+}
+
+// Summary aggregates the stored tags the caller may see into counts by intent,
+// outcome, and tool.
+//
+// Parameters:
+//   - caller: Caller asking for the summary; admins see all conversations
+//
+// Returns:
+//   - map[string]interface{}: Report with total conversations and per-dimension counts
+func (a *Analytics) Summary(caller sessionCaller) map[string]interface{} {
+	visible := a.Tags("", caller)
+
+	intents := make(map[string]int)
+	outcomes := make(map[string]int)
+	toolCounts := make(map[string]int)
+	for _, tags := range visible {
+		intents[tags.Intent]++
+		outcomes[tags.Outcome]++
+		for _, tool := range tags.ToolsUsed {
+			toolCounts[tool]++
+		}
+	}
+
+	// Report tools ordered by usage for readability
+	toolNames := make([]string, 0, len(toolCounts))
+	for name := range toolCounts {
+		toolNames = append(toolNames, name)
+	}
+	sort.Slice(toolNames, func(i, j int) bool {
+		return toolCounts[toolNames[i]] > toolCounts[toolNames[j]]
+	})
+
+	return map[string]interface{}{
+		"totalConversations": len(visible),
+		"intents":            intents,
+		"outcomes":           outcomes,
+		"tools":              toolCounts,
+		"topTools":           toolNames,
+	}
+}
+
+var _ tools.Tool = (*TrackedTool)(nil)
@@ -0,0 +1,443 @@
+/*
+Package core provides API keys for the Skynet Agent application.
+
+ADMIN_TOKEN and ALERTMANAGER_TOKEN are read from the environment, so replacing
+one means editing the host's configuration and reloading or restarting. API keys
+are credentials managed at runtime instead, through the admin API:
+
+	GET    /admin/keys        list keys, without their secrets
+	POST   /admin/keys        {"name": "ci", "scopes": ["admin"], "expiresIn": 86400}
+	DELETE /admin/keys/:id    revoke a key
+
+A key is sent like the token it stands in for, as "Authorization: Bearer <key>",
+and is accepted by the endpoints of its scopes: "admin" for the /admin endpoints,
+including key management itself, "alertmanager" for the Alertmanager
+integration, and "chat" for the chat and session endpoints, where the key owns
+the sessions it creates. A key may expire, set with expiresIn in seconds or expiresAt as an
+RFC 3339 time. To rotate a credential, create a new key, switch the client to
+it, and revoke the old one.
+
+The secret of a key is returned once, when the key is created; the store keeps
+only its SHA-256 hash. Keys are persisted to API_KEYS_PATH, so they survive
+restarts; revoked keys are kept there for the record.
+*/
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// API key scopes
+const (
+	ScopeAdmin        = "admin"        // The /admin endpoints, and every session
+	ScopeAlertmanager = "alertmanager" // POST /integrations/alertmanager
+	ScopeChat         = "chat"         // Chat and session endpoints, limited to the key's own sessions
+)
+
+// apiKeySecretPrefix starts every key secret, so leaked keys are easy to recognize
+const apiKeySecretPrefix = "skynet_"
+
+// APIKey is a runtime-managed credential. The secret itself is not stored.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	Tenant    string     `json:"tenant,omitempty"` // Tenant of the key's requests; empty for none
+	Quota     Quota      `json:"quota"`            // Usage limits of the key's requests; zero limits are unlimited
+	Hash      string     `json:"hash,omitempty"`   // Hex SHA-256 of the secret; omitted from API responses
+	Hint      string     `json:"hint"`             // Last characters of the secret, to tell keys apart
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // Nil for keys that do not expire
+	RevokedAt *time.Time `json:"revokedAt,omitempty"` // Nil for keys that were not revoked
+}
+
+// activeAt reports whether the key can be used at the given time.
+func (k *APIKey) activeAt(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// hasScope reports whether the key grants a scope.
+func (k *APIKey) hasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// public returns a copy of the key without its hash.
+func (k *APIKey) public() APIKey {
+	copied := *k
+	copied.Hash = ""
+	copied.Scopes = append([]string(nil), k.Scopes...)
+	return copied
+}
+
+// APIKeyStore holds the API keys and persists them to disk.
+type APIKeyStore struct {
+	keys   map[string]*APIKey // Map of key ID to key
+	path   string             // JSON file the keys are persisted to; empty keeps them in memory
+	mutex  sync.RWMutex       // Guards keys
+	logger *logrus.Logger     // Structured logger for operational monitoring
+}
+
+// NewAPIKeyStore creates a store and loads previously persisted keys. A missing
+// file is not an error; it is created with the first key.
+//
+// Parameters:
+//   - path: JSON file used for persistence; empty disables persistence
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *APIKeyStore: Store ready for use
+//   - error: Any error reading or decoding the persisted keys
+func NewAPIKeyStore(path string, logger *logrus.Logger) (*APIKeyStore, error) {
+	store := &APIKeyStore{
+		keys:   make(map[string]*APIKey),
+		path:   path,
+		logger: logger,
+	}
+
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+
+	var keys []*APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to decode API keys file: %w", err)
+	}
+	for _, key := range keys {
+		store.keys[key.ID] = key
+	}
+
+	logger.WithFields(logrus.Fields{
+		"path": path,
+		"keys": len(store.keys),
+	}).Info("API keys loaded")
+	return store, nil
+}
+
+// save writes all keys to the persistence file. Callers must hold the mutex.
+func (s *APIKeyStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	keys := make([]*APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode API keys: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial file behind
+	staged := s.path + ".tmp"
+	if err := os.WriteFile(staged, data, 0600); err != nil {
+		return fmt.Errorf("failed to write API keys file: %w", err)
+	}
+	if err := os.Rename(staged, s.path); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to replace API keys file: %w", err)
+	}
+	return nil
+}
+
+// hashAPIKey returns the hex SHA-256 of a key secret.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create generates a key and persists the store.
+//
+// Parameters:
+//   - name: Name describing the key's holder
+//   - scopes: Scopes the key grants
+//   - tenant: Tenant of the key's requests; empty for none
+//   - quota: Usage limits of the key's requests
+//   - expiresAt: Expiry of the key; nil for none
+//
+// Returns:
+//   - APIKey: The new key, without its hash
+//   - string: The key secret, which is not stored and cannot be retrieved later
+//   - error: Any error persisting the key
+func (s *APIKeyStore) Create(name string, scopes []string, tenant string, quota Quota, expiresAt *time.Time) (APIKey, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return APIKey{}, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret := apiKeySecretPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	key := &APIKey{
+		ID:        fmt.Sprintf("key_%d", now.UnixNano()),
+		Name:      name,
+		Scopes:    scopes,
+		Tenant:    tenant,
+		Quota:     quota,
+		Hash:      hashAPIKey(secret),
+		Hint:      secret[len(secret)-4:],
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys[key.ID] = key
+	if err := s.save(); err != nil {
+		delete(s.keys, key.ID)
+		return APIKey{}, "", err
+	}
+	return key.public(), secret, nil
+}
+
+// Revoke revokes a key and persists the store.
+//
+// Parameters:
+//   - id: ID of the key
+//
+// Returns:
+//   - APIKey: The revoked key, without its hash
+//   - bool: Whether the key exists
+//   - error: Any error persisting the revocation
+func (s *APIKeyStore) Revoke(id string) (APIKey, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return APIKey{}, false, nil
+	}
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+		if err := s.save(); err != nil {
+			key.RevokedAt = nil
+			return APIKey{}, true, err
+		}
+	}
+	return key.public(), true, nil
+}
+
+// List returns all keys without their hashes, newest first.
+func (s *APIKeyStore) List() []APIKey {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := make([]APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key.public())
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// Get returns a key by ID, without its hash.
+func (s *APIKeyStore) Get(id string) (APIKey, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return APIKey{}, false
+	}
+	return key.public(), true
+}
+
+// HasScope reports whether any active key grants a scope.
+func (s *APIKeyStore) HasScope(scope string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := time.Now()
+	for _, key := range s.keys {
+		if key.activeAt(now) && key.hasScope(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the active key a secret belongs to, without its hash.
+func (s *APIKeyStore) Lookup(secret string) (APIKey, bool) {
+	if !strings.HasPrefix(secret, apiKeySecretPrefix) {
+		return APIKey{}, false
+	}
+	hash := []byte(hashAPIKey(secret))
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, key := range s.keys {
+		if subtle.ConstantTimeCompare(hash, []byte(key.Hash)) == 1 {
+			if !key.activeAt(time.Now()) {
+				return APIKey{}, false
+			}
+			return key.public(), true
+		}
+	}
+	return APIKey{}, false
+}
+
+// Authenticate reports whether a secret belongs to an active key granting a scope.
+func (s *APIKeyStore) Authenticate(secret, scope string) bool {
+	key, found := s.Lookup(secret)
+	return found && key.hasScope(scope)
+}
+
+// authorizedBearer reports whether a bearer token is the configured token or an
+// active API key granting the scope. An empty configured token matches nothing.
+func (s *Server) authorizedBearer(provided, token, scope string) bool {
+	if token != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1 {
+		return true
+	}
+	return s.apiKeys.Authenticate(provided, scope)
+}
+
+// handleListAPIKeys handles GET /admin/keys requests.
+func (s *Server) handleListAPIKeys(c echo.Context) error {
+	keys := s.apiKeys.List()
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"keys":  keys,
+		"count": len(keys),
+	})
+}
+
+// handleCreateAPIKey handles POST /admin/keys requests by creating a key. The
+// response is the only one that contains the key's secret.
+func (s *Server) handleCreateAPIKey(c echo.Context) error {
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"endpoint": "/admin/keys",
+		"method":   "POST",
+		"clientIP": c.RealIP(),
+	})
+
+	var req struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		Tenant    string     `json:"tenant"`
+		Quota     Quota      `json:"quota"`
+		ExpiresIn int        `json:"expiresIn"` // Seconds until the key expires
+		ExpiresAt *time.Time `json:"expiresAt"`
+	}
+	if err := c.Bind(&req); err != nil {
+		requestLogger.WithError(err).Error("Failed to parse API key request")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Name is required"})
+	}
+	if len(req.Scopes) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "At least one scope is required: admin, alertmanager, or chat"})
+	}
+	for i, scope := range req.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope != ScopeAdmin && scope != ScopeAlertmanager && scope != ScopeChat {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Unsupported scope %q: use admin, alertmanager, or chat", scope)})
+		}
+		req.Scopes[i] = scope
+	}
+
+	if req.Tenant != "" {
+		if _, exists := s.tenants.Get(req.Tenant); !exists {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Tenant %q is not declared", req.Tenant)})
+		}
+		for _, scope := range req.Scopes {
+			if scope == ScopeAdmin {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Keys of a tenant cannot have the admin scope"})
+			}
+		}
+	}
+
+	if err := req.Quota.validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	expiresAt := req.ExpiresAt
+	switch {
+	case req.ExpiresIn < 0:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "expiresIn must not be negative"})
+	case req.ExpiresIn > 0 && expiresAt != nil:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Set either expiresIn or expiresAt, not both"})
+	case req.ExpiresIn > 0:
+		expiry := time.Now().Add(time.Duration(req.ExpiresIn) * time.Second)
+		expiresAt = &expiry
+	case expiresAt != nil && !expiresAt.After(time.Now()):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "expiresAt must be in the future"})
+	}
+
+	key, secret, err := s.apiKeys.Create(req.Name, req.Scopes, req.Tenant, req.Quota, expiresAt)
+	if err != nil {
+		requestLogger.WithError(err).Error("Failed to create API key")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create API key"})
+	}
+
+	requestLogger.WithFields(logrus.Fields{
+		"keyID":     key.ID,
+		"name":      key.Name,
+		"scopes":    key.Scopes,
+		"tenant":    key.Tenant,
+		"quota":     key.Quota,
+		"expiresAt": key.ExpiresAt,
+	}).Info("API key created")
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"key":    key,
+		"secret": secret,
+	})
+}
+
+// handleRevokeAPIKey handles DELETE /admin/keys/:id requests by revoking a key.
+func (s *Server) handleRevokeAPIKey(c echo.Context) error {
+	id := c.Param("id")
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"endpoint": "/admin/keys/:id",
+		"method":   "DELETE",
+		"keyID":    id,
+		"clientIP": c.RealIP(),
+	})
+
+	key, exists, err := s.apiKeys.Revoke(id)
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "API key not found"})
+	}
+	if err != nil {
+		requestLogger.WithError(err).Error("Failed to revoke API key")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to revoke API key"})
+	}
+
+	requestLogger.WithField("name", key.Name).Info("API key revoked")
+	return c.JSON(http.StatusOK, key)
+}
@@ -0,0 +1,258 @@
+/*
+Package core provides image and file attachments for the Skynet Agent application.
+
+A chat request may carry images, such as a screenshot of an error or a dashboard
+graph, inline as base64 or by URL. The server decodes or downloads them before
+the execution starts, so an unusable image is rejected with the request, and
+attaches them to the execution context. The LLM wrapper then adds them to the
+user's message in every model call of the execution, since the model sees only
+what each call contains. Both providers accept images: Gemini models are
+multimodal, and Ollama needs a vision model such as llava.
+
+Text files, such as config snippets or log excerpts, are written to
+attachments/<session ID> in the session's working directory instead of being
+pasted into the message. The message sent to the agent lists their paths, and
+the agent reads them with its file tools when it needs them.
+*/
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	localtools "skynet/tools"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Limits on the attachments of one request
+const (
+	maxAttachments         = 4
+	maxAttachmentBytes     = 8 << 20
+	maxFileAttachments     = 10
+	maxFileAttachmentBytes = 256 << 10
+)
+
+// attachmentClient downloads images given by URL
+var attachmentClient = &http.Client{Timeout: 20 * time.Second}
+
+// attachmentsKey is the unexported context key type for execution attachments
+type attachmentsKey struct{}
+
+// withAttachments returns a copy of the parent context whose model calls include images.
+func withAttachments(ctx context.Context, images []llms.BinaryContent) context.Context {
+	if len(images) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, attachmentsKey{}, images)
+}
+
+// attachmentsFromContext returns the images attached with withAttachments.
+func attachmentsFromContext(ctx context.Context) []llms.BinaryContent {
+	images, _ := ctx.Value(attachmentsKey{}).([]llms.BinaryContent)
+	return images
+}
+
+// loadAttachments decodes or downloads the images of a chat request.
+//
+// Parameters:
+//   - ctx: Context bounding the downloads
+//   - attachments: Attachments of the request
+//
+// Returns:
+//   - []llms.BinaryContent: Image data with MIME types, in request order
+//   - error: Too many or too large attachments, or one that is not a usable image, suitable for the client
+func loadAttachments(ctx context.Context, attachments []Attachment) ([]llms.BinaryContent, error) {
+	if len(attachments) > maxAttachments {
+		return nil, fmt.Errorf("at most %d attachments are allowed per message", maxAttachments)
+	}
+
+	images := make([]llms.BinaryContent, 0, len(attachments))
+	for i, attachment := range attachments {
+		var data []byte
+		var err error
+		mimeType := attachment.MimeType
+		switch {
+		case attachment.Data != "":
+			data, mimeType, err = decodeAttachment(attachment.Data, mimeType)
+		case attachment.URL != "":
+			data, err = downloadAttachment(ctx, attachment.URL)
+		default:
+			err = fmt.Errorf("either data or url is required")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("attachment %d: %w", i+1, err)
+		}
+		if len(data) > maxAttachmentBytes {
+			return nil, fmt.Errorf("attachment %d: images may be at most %d MB", i+1, maxAttachmentBytes>>20)
+		}
+
+		if mimeType == "" {
+			mimeType = http.DetectContentType(data)
+		}
+		if !strings.HasPrefix(mimeType, "image/") {
+			return nil, fmt.Errorf("attachment %d: %s is not an image", i+1, mimeType)
+		}
+		images = append(images, llms.BinaryPart(mimeType, data))
+	}
+	return images, nil
+}
+
+// decodeAttachment decodes base64 image data, taking the MIME type from a data URL prefix.
+func decodeAttachment(encoded, mimeType string) ([]byte, string, error) {
+	if header, payload, found := strings.Cut(encoded, ","); found && strings.HasPrefix(header, "data:") {
+		if mimeType == "" {
+			mimeType = strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+		}
+		encoded = payload
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid base64 data: %w", err)
+	}
+	return data, mimeType, nil
+}
+
+// downloadAttachment fetches an image from an HTTP(S) URL.
+func downloadAttachment(ctx context.Context, url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("url must use http or https")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	resp, err := attachmentClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: %s", resp.Status)
+	}
+
+	// Read one byte beyond the limit so oversized images are detected
+	return io.ReadAll(io.LimitReader(resp.Body, maxAttachmentBytes+1))
+}
+
+// attachImages returns messages with the images added to the first user message,
+// which carries the user's input in both agent modes. The input slice is not modified.
+func attachImages(messages []llms.MessageContent, images []llms.BinaryContent) []llms.MessageContent {
+	attached := make([]llms.MessageContent, len(messages))
+	copy(attached, messages)
+	for i, message := range attached {
+		if message.Role != llms.ChatMessageTypeHuman {
+			continue
+		}
+		parts := make([]llms.ContentPart, 0, len(message.Parts)+len(images))
+		parts = append(parts, message.Parts...)
+		for _, image := range images {
+			parts = append(parts, image)
+		}
+		attached[i].Parts = parts
+		break
+	}
+	return attached
+}
+
+// describeAttachments notes the images of a message for the session history, which stores text only.
+func describeAttachments(message string, images []llms.BinaryContent) string {
+	if len(images) == 0 {
+		return message
+	}
+	return fmt.Sprintf("%s\n[%d image(s) attached]", message, len(images))
+}
+
+// checkFileAttachments validates the text files of a chat request before it is queued.
+//
+// Parameters:
+//   - files: File attachments of the request
+//
+// Returns:
+//   - error: Too many or too large files, an unusable name, or binary content, suitable for the client
+func checkFileAttachments(files []FileAttachment) error {
+	if len(files) > maxFileAttachments {
+		return fmt.Errorf("at most %d files are allowed per message", maxFileAttachments)
+	}
+	names := make(map[string]bool)
+	for i, file := range files {
+		name := filepath.Base(file.Name)
+		switch {
+		case strings.TrimSpace(file.Name) == "" || name == "." || name == ".." || name == string(filepath.Separator):
+			return fmt.Errorf("file %d: a file name is required", i+1)
+		case names[name]:
+			return fmt.Errorf("file %d: %s is attached twice", i+1, name)
+		case len(file.Content) > maxFileAttachmentBytes:
+			return fmt.Errorf("file %s: files may be at most %d KB", name, maxFileAttachmentBytes>>10)
+		case !utf8.ValidString(file.Content):
+			return fmt.Errorf("file %s: only UTF-8 text files can be attached", name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// saveFileAttachments writes checked text files to attachments/<session ID> in the
+// session's working directory.
+//
+// Parameters:
+//   - workspace: The session's workspace
+//   - sessionID: Session the files belong to
+//   - files: File attachments that passed checkFileAttachments
+//
+// Returns:
+//   - []string: Absolute paths of the written files, in request order
+//   - error: Failure creating the directory or writing a file
+func saveFileAttachments(workspace *localtools.WorkspaceContext, sessionID string, files []FileAttachment) ([]string, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	// Client-chosen session IDs must not lead outside the attachment directory
+	sessionDir := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' {
+			return '_'
+		}
+		return r
+	}, sessionID)
+	dir, err := filepath.Abs(workspace.Resolve(filepath.Join("attachments", sessionDir)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve attachment directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.Base(file.Name))
+		if err := os.WriteFile(path, []byte(file.Content), 0o644); err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", filepath.Base(file.Name), err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// describeFiles adds the paths of saved attachments to a message, so the agent
+// knows to read them and follow-up messages can refer to them.
+func describeFiles(message string, paths []string) string {
+	if len(paths) == 0 {
+		return message
+	}
+	var b strings.Builder
+	b.WriteString(message)
+	b.WriteString("\n\n[Attached files, saved on this host; read them with the cat or grep tool:")
+	for _, path := range paths {
+		b.WriteString("\n- " + path)
+	}
+	b.WriteString("]")
+	return b.String()
+}
@@ -0,0 +1,332 @@
+/*
+Package core provides persistent auditing of tool executions for the Skynet Agent application.
+
+This file implements an append-only audit log that records every tool invocation
+performed by the agent. Each invocation is written as a single JSON line so the
+log can be tailed, shipped to log aggregation systems, or queried directly through
+the GET /admin/audit endpoint. Entries hold every tenant's decrypted tool inputs,
+so only admins may query them.
+
+Key components:
+- AuditEntry: A single recorded tool invocation
+- AuditLog: Thread-safe append-only JSONL writer with query support
+- AuditedTool: Tool wrapper that records each call to the audit log
+
+Audit entries store a SHA-256 hash of the tool output rather than the output itself,
+which keeps the log compact while still allowing results to be verified later.
+Calls answered from the execution's tool cache are recorded too, marked cached;
+their hash covers the output as served from the cache, after truncation.
+Tools report most failures as output starting with "Error:" rather than as Go
+errors, so such calls are recorded with the exit status "failed" and the first
+line of the output as their error. With ENCRYPTION_KEY set, the input and error of each entry are encrypted in the
+file and decrypted by Query; the other fields stay readable for filtering.
+*/
+package core
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// AuditEntry represents a single tool invocation recorded in the audit log.
+type AuditEntry struct {
+	Timestamp   time.Time `json:"timestamp"`             // When the tool invocation started
+	SessionID   string    `json:"sessionId,omitempty"`   // Chat session that triggered the invocation
+	ExecutionID string    `json:"executionId,omitempty"` // Agent execution that triggered the invocation
+	User        string    `json:"user,omitempty"`        // Identity of the requesting user
+	Tenant      string    `json:"tenant,omitempty"`      // Tenant the execution is accounted to
+	Target      string    `json:"target,omitempty"`      // Host the tool's commands ran on, when not local
+	Tool        string    `json:"tool"`                  // Name of the invoked tool
+	Input       string    `json:"input"`                 // Full tool input as provided by the agent
+	ExitStatus  string    `json:"exitStatus"`            // "ok", "failed" when the tool reported a failure in its output, or "error" when it returned one
+	Error       string    `json:"error,omitempty"`       // Error message, or the failure the tool reported, unless ExitStatus is "ok"
+	OutputHash  string    `json:"outputHash"`            // Hex-encoded SHA-256 hash of the tool output
+	Duration    string    `json:"duration"`              // Wall-clock execution time of the tool
+	Cached      bool      `json:"cached,omitempty"`      // Whether the output was served from the execution's tool cache without running the tool
+}
+
+// Exit statuses of audited tool calls
+const (
+	AuditStatusOK     = "ok"     // The tool succeeded
+	AuditStatusFailed = "failed" // The tool reported a failure to the agent in its output
+	AuditStatusError  = "error"  // The tool returned an error
+)
+
+// toolFailurePrefix starts the output of a tool that failed. Tools report most
+// failures to the agent as output, so it can correct its input, rather than as errors.
+const toolFailurePrefix = "Error:"
+
+// maxToolFailureLength bounds the failure message taken from a tool's output
+const maxToolFailureLength = 500
+
+// toolOutputFailure returns the failure a tool reported in its output: the
+// output's first line when it starts with toolFailurePrefix, otherwise "".
+func toolOutputFailure(output string) string {
+	if !strings.HasPrefix(output, toolFailurePrefix) {
+		return ""
+	}
+	failure, _, _ := strings.Cut(output, "\n")
+	if len(failure) > maxToolFailureLength {
+		failure = strings.ToValidUTF8(failure[:maxToolFailureLength], "") + "..."
+	}
+	return failure
+}
+
+// AuditQuery describes the filters applied when reading entries from the audit log.
+// Zero values disable the corresponding filter.
+type AuditQuery struct {
+	SessionID string    // Only return entries for this session
+	Since     time.Time // Only return entries at or after this time
+	Until     time.Time // Only return entries at or before this time
+	Limit     int       // Maximum number of entries to return (most recent kept)
+}
+
+// AuditLog is an append-only JSONL audit log of tool executions.
+// Writes are serialized through a mutex so concurrent agent executions
+// never interleave partial lines.
+type AuditLog struct {
+	path   string         // Filesystem path of the JSONL audit file
+	file   *os.File       // Open handle used for appending entries
+	cipher *FieldCipher   // Encrypts entry inputs and errors; nil stores them in plain text
+	mutex  sync.Mutex     // Serializes writes and reads against the file
+	logger *logrus.Logger // Structured logger for operational monitoring
+}
+
+// NewAuditLog opens (or creates) the audit log file at the given path.
+// Parent directories are created as needed and the file is opened in append-only mode.
+//
+// Parameters:
+//   - path: Filesystem path of the JSONL audit file
+//   - cipher: Cipher for entry inputs and errors, or nil to store them in plain text
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *AuditLog: Audit log ready to record entries
+//   - error: Any error creating directories or opening the file
+func NewAuditLog(path string, cipher *FieldCipher, logger *logrus.Logger) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"path":      path,
+		"encrypted": cipher != nil,
+	}).Info("Audit log initialized")
+	return &AuditLog{
+		path:   path,
+		file:   file,
+		cipher: cipher,
+		logger: logger,
+	}, nil
+}
+
+// Record appends a single entry to the audit log.
+// Failures are logged rather than returned so auditing problems never
+// interrupt agent execution.
+//
+// Parameters:
+//   - entry: The audit entry to append
+func (a *AuditLog) Record(entry AuditEntry) {
+	entry.Input = a.cipher.Encrypt(entry.Input)
+	entry.Error = a.cipher.Encrypt(entry.Error)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		a.logger.WithError(err).Error("Failed to encode audit entry")
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if _, err := a.file.Write(append(data, '\n')); err != nil {
+		a.logger.WithError(err).WithField("tool", entry.Tool).Error("Failed to write audit entry")
+	}
+}
+
+// Query reads the audit log and returns entries matching the given filters
+// in chronological order.
+//
+// Parameters:
+//   - query: Filters to apply to the audit entries
+//
+// Returns:
+//   - []AuditEntry: Matching entries in chronological order
+//   - error: Any error reading the audit file
+func (a *AuditLog) Query(query AuditQuery) ([]AuditEntry, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	file, err := os.Open(a.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	entries := make([]AuditEntry, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024) // Allow long tool inputs
+
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// Skip corrupt lines (e.g. from a crash mid-write) instead of failing the query
+			continue
+		}
+
+		if query.SessionID != "" && entry.SessionID != query.SessionID {
+			continue
+		}
+		if !query.Since.IsZero() && entry.Timestamp.Before(query.Since) {
+			continue
+		}
+		if !query.Until.IsZero() && entry.Timestamp.After(query.Until) {
+			continue
+		}
+		if entry.Input, err = a.cipher.Decrypt(entry.Input); err == nil {
+			entry.Error, err = a.cipher.Decrypt(entry.Error)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt audit entry of %s: %w", entry.Timestamp.Format(time.RFC3339), err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	// Keep only the most recent entries when a limit is requested
+	if query.Limit > 0 && len(entries) > query.Limit {
+		entries = entries[len(entries)-query.Limit:]
+	}
+
+	return entries, nil
+}
+
+// Close flushes and closes the underlying audit file.
+//
+// Returns:
+//   - error: Any error closing the file
+func (a *AuditLog) Close() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.file.Close()
+}
+
+// AuditedTool wraps a tool and records every invocation to an audit log.
+// It is transparent to the agent: name and description are delegated to the wrapped tool.
+type AuditedTool struct {
+	tool  tools.Tool // The underlying tool being audited
+	audit *AuditLog  // Destination for audit entries
+}
+
+// NewAuditedTool wraps a tool so that each call is recorded to the audit log.
+//
+// Parameters:
+//   - tool: The tool to wrap
+//   - audit: Audit log receiving the entries
+//
+// Returns:
+//   - *AuditedTool: Wrapped tool implementing tools.Tool
+func NewAuditedTool(tool tools.Tool, audit *AuditLog) *AuditedTool {
+	return &AuditedTool{tool: tool, audit: audit}
+}
+
+// Name returns the wrapped tool's name.
+func (t *AuditedTool) Name() string {
+	return t.tool.Name()
+}
+
+// Description returns the wrapped tool's description.
+func (t *AuditedTool) Description() string {
+	return t.tool.Description()
+}
+
+// Call invokes the wrapped tool and appends an audit entry describing the invocation.
+// Session, execution, and user details are taken from the ExecutionInfo attached
+// to the context by the HTTP handlers.
+//
+// Parameters:
+//   - ctx: Execution context, optionally carrying ExecutionInfo
+//   - input: Tool input provided by the agent
+//
+// Returns:
+//   - string: The wrapped tool's output
+//   - error: The wrapped tool's error
+func (t *AuditedTool) Call(ctx context.Context, input string) (string, error) {
+	startTime := time.Now()
+	output, err := t.tool.Call(ctx, input)
+	t.audit.Record(newAuditEntry(ctx, t.tool.Name(), input, output, err, startTime))
+	return output, err
+}
+
+// newAuditEntry describes a tool call that started at startTime and ended now.
+func newAuditEntry(ctx context.Context, tool, input, output string, err error, startTime time.Time) AuditEntry {
+	hash := sha256.Sum256([]byte(output))
+	entry := AuditEntry{
+		Timestamp:  startTime,
+		Tool:       tool,
+		Input:      input,
+		ExitStatus: AuditStatusOK,
+		OutputHash: hex.EncodeToString(hash[:]),
+		Duration:   time.Since(startTime).String(),
+	}
+	if err != nil {
+		entry.ExitStatus = AuditStatusError
+		entry.Error = err.Error()
+	} else if failure := toolOutputFailure(output); failure != "" {
+		entry.ExitStatus = AuditStatusFailed
+		entry.Error = failure
+	}
+	if info, ok := ExecutionInfoFromContext(ctx); ok {
+		entry.SessionID = info.SessionID
+		entry.ExecutionID = info.ExecutionID
+		entry.User = info.User
+		entry.Tenant = info.Tenant
+	}
+	if target := localtools.TargetFromContext(ctx); target.Kind != localtools.TargetLocal {
+		entry.Target = target.String()
+	}
+	return entry
+}
+
+// WrapToolsWithAudit wraps every tool in the list with auditing.
+// When audit is nil the list is returned unchanged.
+//
+// Parameters:
+//   - toolsList: Tools to wrap
+//   - audit: Audit log receiving the entries, or nil to disable auditing
+//
+// Returns:
+//   - []tools.Tool: Tools with auditing applied
+func WrapToolsWithAudit(toolsList []tools.Tool, audit *AuditLog) []tools.Tool {
+	if audit == nil {
+		return toolsList
+	}
+
+	wrapped := make([]tools.Tool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		wrapped = append(wrapped, NewAuditedTool(tool, audit))
+	}
+	return wrapped
+}
+
+var _ tools.Tool = (*AuditedTool)(nil)
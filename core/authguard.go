@@ -0,0 +1,267 @@
+/*
+Package core provides brute-force protection for the Skynet Agent application.
+
+The admin API and the integrations authenticate with shared secrets: a bearer
+token, a hook secret, or a request signature. Without a limit, an exposed
+instance lets a client try secrets as fast as it can send requests. The
+AuthGuard counts consecutive failed attempts per client address; after
+AUTH_MAX_FAILURES of them the address is locked out for AUTH_LOCKOUT, and every
+further lockout of the same address doubles the duration, up to an hour. A
+locked-out client receives 429 with a Retry-After header, and its credentials
+are not checked. A successful attempt clears the address's record, and
+addresses without failures for a day are forgotten.
+
+Every failure is logged as a warning with the client address, the endpoint, and
+the number of consecutive failures; every lockout is logged as an error and
+delivered to the notification sinks subscribed to security events.
+
+Clients are identified by the TCP peer address, for the reasons given for
+ALLOWED_CIDRS: behind a reverse proxy all clients share the proxy's address, so
+a lockout there applies to every client of the proxy.
+*/
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxAuthLockout caps the doubling lockout duration
+	maxAuthLockout = time.Hour
+	// authForgetAfter is how long an address's record is kept after its last failure
+	authForgetAfter = 24 * time.Hour
+	// authPruneInterval is the minimum time between sweeps for forgotten records
+	authPruneInterval = time.Minute
+)
+
+// authAttempts is the failure record of one client address.
+type authAttempts struct {
+	failures    int       // Consecutive failures since the last success or lockout
+	lockouts    int       // Lockouts so far; each one doubles the next duration
+	lockedUntil time.Time // End of the current lockout
+	lastFailure time.Time // Time of the last failure
+}
+
+// AuthGuard tracks failed authentication attempts and locks out clients that
+// repeat them. A nil guard, or one with no failure limit, never locks out.
+type AuthGuard struct {
+	maxFailures   int           // Consecutive failures that trigger a lockout; 0 disables lockouts
+	lockout       time.Duration // Duration of the first lockout
+	notifications *Notifications
+	logger        *logrus.Logger
+
+	mutex     sync.Mutex
+	clients   map[string]*authAttempts // Failure records by client address
+	lastPrune time.Time
+	failed    uint64 // Failed attempts since startup
+	lockouts  uint64 // Lockouts since startup
+	rejected  uint64 // Requests rejected during a lockout since startup
+}
+
+// NewAuthGuard creates a guard.
+//
+// Parameters:
+//   - maxFailures: Consecutive failures that trigger a lockout; 0 disables lockouts
+//   - lockout: Duration of the first lockout
+//   - notifications: Sinks notified of lockouts; may be nil
+//   - logger: Logger for failures and lockouts
+//
+// Returns:
+//   - *AuthGuard: Guard with no recorded failures
+func NewAuthGuard(maxFailures int, lockout time.Duration, notifications *Notifications, logger *logrus.Logger) *AuthGuard {
+	return &AuthGuard{
+		maxFailures:   maxFailures,
+		lockout:       lockout,
+		notifications: notifications,
+		logger:        logger,
+		clients:       make(map[string]*authAttempts),
+	}
+}
+
+// Locked reports whether a client is locked out and for how much longer.
+func (g *AuthGuard) Locked(client string) (time.Duration, bool) {
+	if g == nil || g.maxFailures <= 0 {
+		return 0, false
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	attempts, exists := g.clients[client]
+	if !exists {
+		return 0, false
+	}
+	remaining := time.Until(attempts.lockedUntil)
+	if remaining <= 0 {
+		return 0, false
+	}
+	g.rejected++
+	return remaining, true
+}
+
+// Failure records a failed attempt, locking the client out when it reaches the limit.
+//
+// Parameters:
+//   - scope: What the client tried to authenticate to, such as "admin" or "hook:deploy"
+//   - client: Client address
+func (g *AuthGuard) Failure(scope, client string) {
+	if g == nil {
+		return
+	}
+	now := time.Now()
+	g.mutex.Lock()
+	g.pruneLocked(now)
+	g.failed++
+	attempts, exists := g.clients[client]
+	if !exists {
+		attempts = &authAttempts{}
+		g.clients[client] = attempts
+	}
+	attempts.failures++
+	attempts.lastFailure = now
+	failures := attempts.failures
+
+	var lockout time.Duration
+	if g.maxFailures > 0 && failures >= g.maxFailures {
+		lockout = g.lockout << min(attempts.lockouts, 20)
+		if lockout <= 0 || lockout > maxAuthLockout {
+			lockout = maxAuthLockout
+		}
+		attempts.lockouts++
+		attempts.failures = 0
+		attempts.lockedUntil = now.Add(lockout)
+		g.lockouts++
+	}
+	lockouts := attempts.lockouts
+	g.mutex.Unlock()
+
+	fields := logrus.Fields{
+		"scope":    scope,
+		"clientIP": client,
+		"failures": failures,
+	}
+	if lockout == 0 {
+		g.logger.WithFields(fields).Warn("Failed authentication attempt")
+		return
+	}
+
+	fields["lockout"] = lockout
+	fields["lockouts"] = lockouts
+	g.logger.WithFields(fields).Error("Locked out client after repeated authentication failures")
+	g.notifications.Notify(Notification{
+		Kind:    NotifyOnSecurity,
+		Status:  "triggered",
+		Title:   "Authentication lockout",
+		Message: fmt.Sprintf("%s failed to authenticate to %s %d times in a row.", client, scope, failures),
+		Error:   fmt.Sprintf("Locked out for %s (lockout %d for this address).", lockout, lockouts),
+	})
+}
+
+// Success clears a client's failure record after a successful attempt.
+func (g *AuthGuard) Success(client string) {
+	if g == nil {
+		return
+	}
+	g.mutex.Lock()
+	delete(g.clients, client)
+	g.mutex.Unlock()
+}
+
+// pruneLocked forgets clients that are not locked out and have not failed for a day.
+// The caller must hold the mutex.
+func (g *AuthGuard) pruneLocked(now time.Time) {
+	if now.Sub(g.lastPrune) < authPruneInterval {
+		return
+	}
+	g.lastPrune = now
+	for client, attempts := range g.clients {
+		if now.After(attempts.lockedUntil) && now.Sub(attempts.lastFailure) > authForgetAfter {
+			delete(g.clients, client)
+		}
+	}
+}
+
+// WritePrometheus writes the authentication counters and the number of locked-out
+// clients in the Prometheus text exposition format.
+//
+// Parameters:
+//   - w: Destination of the metrics
+//
+// Returns:
+//   - error: Write failure
+func (g *AuthGuard) WritePrometheus(w io.Writer) error {
+	if g == nil {
+		return nil
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	locked := 0
+	now := time.Now()
+	for _, attempts := range g.clients {
+		if now.Before(attempts.lockedUntil) {
+			locked++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("# HELP skynet_auth_failures_total Total number of failed authentication attempts.\n")
+	b.WriteString("# TYPE skynet_auth_failures_total counter\n")
+	fmt.Fprintf(&b, "skynet_auth_failures_total %d\n", g.failed)
+	b.WriteString("# HELP skynet_auth_lockouts_total Total number of clients locked out after repeated authentication failures.\n")
+	b.WriteString("# TYPE skynet_auth_lockouts_total counter\n")
+	fmt.Fprintf(&b, "skynet_auth_lockouts_total %d\n", g.lockouts)
+	b.WriteString("# HELP skynet_auth_rejected_total Total number of requests rejected because their client was locked out.\n")
+	b.WriteString("# TYPE skynet_auth_rejected_total counter\n")
+	fmt.Fprintf(&b, "skynet_auth_rejected_total %d\n", g.rejected)
+	b.WriteString("# HELP skynet_auth_locked_clients Number of client addresses currently locked out.\n")
+	b.WriteString("# TYPE skynet_auth_locked_clients gauge\n")
+	fmt.Fprintf(&b, "skynet_auth_locked_clients %d\n", locked)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// authClient returns the address a request's authentication attempts are counted under.
+func authClient(r *http.Request) string {
+	if ip := peerIP(r); ip != nil {
+		return ip.String()
+	}
+	return r.RemoteAddr
+}
+
+// rejectLockedOut answers a request from a locked-out client with 429. It reports
+// whether the request was answered, along with the result of writing the response.
+func (s *Server) rejectLockedOut(c echo.Context, scope string) (bool, error) {
+	client := authClient(c.Request())
+	remaining, locked := s.authGuard.Locked(client)
+	if !locked {
+		return false, nil
+	}
+	s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"scope":    scope,
+		"clientIP": client,
+		"endpoint": c.Path(),
+	}).Debug("Rejected request from locked-out client")
+	seconds := int(remaining.Round(time.Second) / time.Second)
+	c.Response().Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+	return true, c.JSON(http.StatusTooManyRequests, map[string]string{"error": "Too many failed authentication attempts; try again later"})
+}
+
+// authFailed records a failed authentication attempt of a request.
+func (s *Server) authFailed(c echo.Context, scope string) {
+	s.authGuard.Failure(scope, authClient(c.Request()))
+}
+
+// authSucceeded clears the failure record of a request's client.
+func (s *Server) authSucceeded(c echo.Context) {
+	s.authGuard.Success(authClient(c.Request()))
+}
@@ -0,0 +1,166 @@
+/*
+Package core provides per-execution caching of read-only tool results for the Skynet Agent application.
+
+Agents frequently re-run the same read-only command in consecutive iterations.
+This file implements a short-lived cache attached to each execution's context:
+results of configured read-only tools (sysinfo, ls, stat, cat by default) are
+reused for a short TTL keyed by tool name and input. Any call to a tool that is
+not read-only clears the cache, since it may have changed the system state.
+Calls served from the cache never reach the audit wrapper, so they are recorded
+in the audit log here, marked cached.
+*/
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// toolCacheKey is the unexported context key type for ToolCache values.
+type toolCacheKey struct{}
+
+// cachedResult is a single cached tool output.
+type cachedResult struct {
+	output  string    // Tool output returned to the agent
+	expires time.Time // When the cached output stops being valid
+}
+
+// ToolCache holds cached tool outputs for a single execution.
+type ToolCache struct {
+	results map[string]cachedResult // Map of tool+input key to cached output
+	mutex   sync.Mutex              // Guards results against concurrent tool calls
+}
+
+// WithToolCache returns a derived context carrying a fresh, empty tool cache.
+//
+// Parameters:
+//   - ctx: Parent context
+//
+// Returns:
+//   - context.Context: Derived context carrying the cache
+func WithToolCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, toolCacheKey{}, &ToolCache{results: make(map[string]cachedResult)})
+}
+
+// get returns a cached output if present and not expired.
+func (c *ToolCache) get(key string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result, exists := c.results[key]
+	if !exists || time.Now().After(result.expires) {
+		delete(c.results, key)
+		return "", false
+	}
+	return result.output, true
+}
+
+// put stores an output for the given TTL.
+func (c *ToolCache) put(key, output string, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.results[key] = cachedResult{output: output, expires: time.Now().Add(ttl)}
+}
+
+// clear drops every cached output.
+func (c *ToolCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.results = make(map[string]cachedResult)
+}
+
+// CachingTool wraps a tool and serves repeated read-only calls from the execution's cache.
+type CachingTool struct {
+	tool      tools.Tool    // The underlying tool
+	cacheable bool          // Whether the tool is read-only and its results may be cached
+	ttl       time.Duration // How long cached results remain valid
+	audit     *AuditLog     // Audit log recording calls served from the cache; nil disables auditing
+}
+
+// Name returns the wrapped tool's name.
+func (t *CachingTool) Name() string {
+	return t.tool.Name()
+}
+
+// Description returns the wrapped tool's description.
+func (t *CachingTool) Description() string {
+	return t.tool.Description()
+}
+
+// Call serves the result from the execution cache when possible, otherwise invokes the tool.
+// Calls to tools that are not cacheable invalidate the cache before running.
+//
+// Parameters:
+//   - ctx: Execution context, optionally carrying a ToolCache
+//   - input: Tool input provided by the agent
+//
+// Returns:
+//   - string: The (possibly cached) tool output
+//   - error: The wrapped tool's error
+func (t *CachingTool) Call(ctx context.Context, input string) (string, error) {
+	cache, ok := ctx.Value(toolCacheKey{}).(*ToolCache)
+	if !ok {
+		return t.tool.Call(ctx, input)
+	}
+
+	if !t.cacheable {
+		// The tool may modify system state, so earlier reads can no longer be trusted
+		cache.clear()
+		return t.tool.Call(ctx, input)
+	}
+
+	key := t.tool.Name() + "\x00" + input
+	if output, hit := cache.get(key); hit {
+		if t.audit != nil {
+			entry := newAuditEntry(ctx, t.tool.Name(), input, output, nil, time.Now())
+			entry.Cached = true
+			t.audit.Record(entry)
+		}
+		return output, nil
+	}
+
+	output, err := t.tool.Call(ctx, input)
+	if err == nil {
+		cache.put(key, output, t.ttl)
+	}
+	return output, err
+}
+
+// WrapToolsWithCache enables per-execution result caching for the configured read-only tools.
+// When the cache TTL is zero the list is returned unchanged.
+//
+// Parameters:
+//   - toolsList: Tools to wrap
+//   - config: Configuration providing the cache TTL and cacheable tool names
+//   - audit: Audit log recording calls served from the cache, or nil
+//
+// Returns:
+//   - []tools.Tool: Tools with caching applied
+func WrapToolsWithCache(toolsList []tools.Tool, config *Config, audit *AuditLog) []tools.Tool {
+	if config.ToolCacheTTL <= 0 {
+		return toolsList
+	}
+
+	cacheable := make(map[string]bool, len(config.CacheableTools))
+	for _, name := range config.CacheableTools {
+		cacheable[name] = true
+	}
+
+	wrapped := make([]tools.Tool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		wrapped = append(wrapped, &CachingTool{
+			tool:      tool,
+			cacheable: cacheable[tool.Name()],
+			ttl:       config.ToolCacheTTL,
+			audit:     audit,
+		})
+	}
+	return wrapped
+}
+
+var _ tools.Tool = (*CachingTool)(nil)
@@ -0,0 +1,142 @@
+/*
+Package core provides request-scoped routing of agent callbacks for the Skynet Agent application.
+
+This file implements the RoutingCallbackHandler, which is installed once on the
+shared agent executor and forwards every agent event to the callback handler
+attached to the execution context. Debug requests attach a streaming handler that
+reports progress to their client; all other requests fall back to verbose logging.
+This lets one executor, with one LLM client and one tool list, serve both kinds of
+requests instead of building a debug executor per request. Agent decisions are
+also recorded in the execution timeline of the context.
+*/
+package core
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// callbackHandlerKey is the unexported context key type for per-request callback handlers
+type callbackHandlerKey struct{}
+
+// WithCallbackHandler returns a copy of the parent context carrying a callback handler
+// that receives the agent events of this execution instead of the default handler.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - handler: Handler for the events of this execution
+//
+// Returns:
+//   - context.Context: Derived context carrying the handler
+func WithCallbackHandler(ctx context.Context, handler callbacks.Handler) context.Context {
+	return context.WithValue(ctx, callbackHandlerKey{}, handler)
+}
+
+// ToolOutputHandler is implemented by callback handlers that also want the input
+// and output of every tool call, which the agent executor does not report as an event.
+type ToolOutputHandler interface {
+	HandleToolOutput(ctx context.Context, tool, input, output string, err error)
+}
+
+// toolOutputHandlerFromContext returns the execution's handler if it wants tool outputs.
+func toolOutputHandlerFromContext(ctx context.Context) (ToolOutputHandler, bool) {
+	handler, ok := ctx.Value(callbackHandlerKey{}).(ToolOutputHandler)
+	return handler, ok
+}
+
+// RoutingCallbackHandler forwards agent events to the handler attached to the
+// execution context with WithCallbackHandler, or to its fallback handler.
+type RoutingCallbackHandler struct {
+	fallback callbacks.Handler // Handler for executions without their own handler
+}
+
+// NewRoutingCallbackHandler creates a handler that routes events by execution context.
+//
+// Parameters:
+//   - fallback: Handler for executions without their own handler
+//
+// Returns:
+//   - *RoutingCallbackHandler: Handler ready to be installed on the shared executor
+func NewRoutingCallbackHandler(fallback callbacks.Handler) *RoutingCallbackHandler {
+	return &RoutingCallbackHandler{fallback: fallback}
+}
+
+// handler returns the handler responsible for the execution the context belongs to.
+func (h *RoutingCallbackHandler) handler(ctx context.Context) callbacks.Handler {
+	if handler, ok := ctx.Value(callbackHandlerKey{}).(callbacks.Handler); ok && handler != nil {
+		return handler
+	}
+	return h.fallback
+}
+
+func (h *RoutingCallbackHandler) HandleText(ctx context.Context, text string) {
+	h.handler(ctx).HandleText(ctx, text)
+}
+
+func (h *RoutingCallbackHandler) HandleLLMStart(ctx context.Context, prompts []string) {
+	h.handler(ctx).HandleLLMStart(ctx, prompts)
+}
+
+func (h *RoutingCallbackHandler) HandleLLMGenerateContentStart(ctx context.Context, ms []llms.MessageContent) {
+	h.handler(ctx).HandleLLMGenerateContentStart(ctx, ms)
+}
+
+func (h *RoutingCallbackHandler) HandleLLMGenerateContentEnd(ctx context.Context, res *llms.ContentResponse) {
+	h.handler(ctx).HandleLLMGenerateContentEnd(ctx, res)
+}
+
+func (h *RoutingCallbackHandler) HandleLLMError(ctx context.Context, err error) {
+	h.handler(ctx).HandleLLMError(ctx, err)
+}
+
+func (h *RoutingCallbackHandler) HandleChainStart(ctx context.Context, inputs map[string]any) {
+	h.handler(ctx).HandleChainStart(ctx, inputs)
+}
+
+func (h *RoutingCallbackHandler) HandleChainEnd(ctx context.Context, outputs map[string]any) {
+	h.handler(ctx).HandleChainEnd(ctx, outputs)
+}
+
+func (h *RoutingCallbackHandler) HandleChainError(ctx context.Context, err error) {
+	h.handler(ctx).HandleChainError(ctx, err)
+}
+
+func (h *RoutingCallbackHandler) HandleToolStart(ctx context.Context, input string) {
+	h.handler(ctx).HandleToolStart(ctx, input)
+}
+
+func (h *RoutingCallbackHandler) HandleToolEnd(ctx context.Context, output string) {
+	h.handler(ctx).HandleToolEnd(ctx, output)
+}
+
+func (h *RoutingCallbackHandler) HandleToolError(ctx context.Context, err error) {
+	h.handler(ctx).HandleToolError(ctx, err)
+}
+
+func (h *RoutingCallbackHandler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
+	ExecutionTraceFromContext(ctx).Record(TraceEvent{Type: TraceEventAgentAction, Tool: action.Tool, Content: action.Log})
+	h.handler(ctx).HandleAgentAction(ctx, action)
+}
+
+func (h *RoutingCallbackHandler) HandleAgentFinish(ctx context.Context, finish schema.AgentFinish) {
+	ExecutionTraceFromContext(ctx).Record(TraceEvent{Type: TraceEventAgentFinish, Content: finish.Log})
+	h.handler(ctx).HandleAgentFinish(ctx, finish)
+}
+
+func (h *RoutingCallbackHandler) HandleRetrieverStart(ctx context.Context, query string) {
+	h.handler(ctx).HandleRetrieverStart(ctx, query)
+}
+
+func (h *RoutingCallbackHandler) HandleRetrieverEnd(ctx context.Context, query string, documents []schema.Document) {
+	h.handler(ctx).HandleRetrieverEnd(ctx, query, documents)
+}
+
+func (h *RoutingCallbackHandler) HandleStreamingFunc(ctx context.Context, chunk []byte) {
+	h.handler(ctx).HandleStreamingFunc(ctx, chunk)
+}
+
+// Ensure RoutingCallbackHandler implements the callbacks.Handler interface
+var _ callbacks.Handler = (*RoutingCallbackHandler)(nil)
@@ -9,6 +9,8 @@ The cancellation system provides:
 - Thread-safe tracking of active executions
 - Context-based cancellation for clean shutdown
 - Execution lifecycle management
+- Cancellation of all executions of a session or HTTP request
+- Access limited to the callers that may use the execution's session
 - Active execution monitoring and reporting
 
 The system integrates with Go's context cancellation patterns to ensure
@@ -18,6 +20,7 @@ package core
 
 import (
 	"context"
+	"sort"
 	"sync"
 )
 
@@ -29,8 +32,17 @@ import (
 // The manager integrates with Go's context cancellation patterns to ensure
 // clean shutdown and proper resource cleanup when executions are cancelled.
 type CancelManager struct {
-	executions map[string]context.CancelFunc // Map of execution ID to cancellation function
-	mutex      sync.RWMutex                  // Read-write mutex for thread-safe access to the executions map
+	executions map[string]*runningExecution // Map of execution ID to running execution
+	mutex      sync.RWMutex                 // Read-write mutex for thread-safe access to the executions map
+}
+
+// runningExecution is a registered execution with what it can be stopped by.
+type runningExecution struct {
+	cancel    context.CancelFunc // Context cancellation function that stops the execution
+	sessionID string             // Session the execution belongs to
+	requestID string             // HTTP request or command that started the execution
+	owner     string             // Owner of the execution's session; empty for sessions open to every caller
+	tenant    string             // Tenant the execution is accounted to
 }
 
 // NewCancelManager creates and initializes a new cancel manager instance.
@@ -41,7 +53,7 @@ type CancelManager struct {
 //   - *CancelManager: Initialized cancel manager ready for use
 func NewCancelManager() *CancelManager {
 	return &CancelManager{
-		executions: make(map[string]context.CancelFunc),
+		executions: make(map[string]*runningExecution),
 	}
 }
 
@@ -51,12 +63,19 @@ func NewCancelManager() *CancelManager {
 // and the cancel function should properly clean up all associated resources.
 //
 // Parameters:
-//   - executionID: Unique identifier for the execution
+//   - info: Execution to register, with its session, owner, and tenant
+//   - requestID: Request that started the execution; may be empty
 //   - cancel: Context cancellation function that will stop the execution
-func (cm *CancelManager) AddExecution(executionID string, cancel context.CancelFunc) {
+func (cm *CancelManager) AddExecution(info ExecutionInfo, requestID string, cancel context.CancelFunc) {
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
-	cm.executions[executionID] = cancel
+	cm.executions[info.ExecutionID] = &runningExecution{
+		cancel:    cancel,
+		sessionID: info.SessionID,
+		requestID: requestID,
+		owner:     info.Owner,
+		tenant:    info.Tenant,
+	}
 }
 
 // RemoveExecution removes a completed or cancelled execution from tracking.
@@ -72,6 +91,21 @@ func (cm *CancelManager) RemoveExecution(executionID string) {
 	delete(cm.executions, executionID)
 }
 
+// MayStop reports whether a caller may stop a running execution.
+//
+// Parameters:
+//   - executionID: Unique identifier of the execution
+//   - caller: Caller asking to stop it
+//
+// Returns:
+//   - bool: false if the execution is running and belongs to another caller
+func (cm *CancelManager) MayStop(executionID string, caller sessionCaller) bool {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+	execution, exists := cm.executions[executionID]
+	return !exists || caller.mayAccess(execution.owner, execution.tenant)
+}
+
 // CancelExecution attempts to cancel a running execution by ID.
 // This method looks up the execution's cancellation function and invokes it
 // if the execution is found. The execution is automatically removed from
@@ -85,12 +119,12 @@ func (cm *CancelManager) RemoveExecution(executionID string) {
 func (cm *CancelManager) CancelExecution(executionID string) bool {
 	// Use read lock to check existence and get cancel function
 	cm.mutex.RLock()
-	cancel, exists := cm.executions[executionID]
+	execution, exists := cm.executions[executionID]
 	cm.mutex.RUnlock()
 
 	if exists {
 		// Cancel the execution using its context cancellation function
-		cancel()
+		execution.cancel()
 		// Remove from tracking after successful cancellation
 		cm.RemoveExecution(executionID)
 		return true
@@ -98,20 +132,75 @@ func (cm *CancelManager) CancelExecution(executionID string) bool {
 	return false
 }
 
-// GetActiveExecutions returns a list of all currently active execution IDs.
+// CancelSession cancels every running execution of a session the caller may
+// stop, such as the steps of a workflow run or a message sent while another was
+// still running.
+//
+// Parameters:
+//   - sessionID: Session whose executions to cancel
+//   - caller: Caller asking to stop them
+//
+// Returns:
+//   - []string: IDs of the cancelled executions; empty if none was running
+func (cm *CancelManager) CancelSession(sessionID string, caller sessionCaller) []string {
+	return cm.cancelWhere(func(execution *runningExecution) bool {
+		return execution.sessionID == sessionID && caller.mayAccess(execution.owner, execution.tenant)
+	})
+}
+
+// CancelRequest cancels every running execution started by a request, as
+// identified by its X-Request-ID, that the caller may stop.
+//
+// Parameters:
+//   - requestID: Request whose executions to cancel
+//   - caller: Caller asking to stop them
+//
+// Returns:
+//   - []string: IDs of the cancelled executions; empty if none was running
+func (cm *CancelManager) CancelRequest(requestID string, caller sessionCaller) []string {
+	return cm.cancelWhere(func(execution *runningExecution) bool {
+		return execution.requestID != "" && execution.requestID == requestID &&
+			caller.mayAccess(execution.owner, execution.tenant)
+	})
+}
+
+// cancelWhere cancels and removes the executions matching a predicate.
+func (cm *CancelManager) cancelWhere(match func(*runningExecution) bool) []string {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	var cancelled []string
+	for id, execution := range cm.executions {
+		if match(execution) {
+			execution.cancel()
+			delete(cm.executions, id)
+			cancelled = append(cancelled, id)
+		}
+	}
+	sort.Strings(cancelled)
+	return cancelled
+}
+
+// GetActiveExecutions returns the IDs of the currently active executions the
+// caller may see, that is all of them for admins.
 // This method provides visibility into what executions are currently running
 // and can be used for monitoring, debugging, or administrative purposes.
 //
+// Parameters:
+//   - caller: Caller asking for the executions
+//
 // Returns:
-//   - []string: Slice containing all active execution IDs
-func (cm *CancelManager) GetActiveExecutions() []string {
+//   - []string: Slice containing the visible active execution IDs
+func (cm *CancelManager) GetActiveExecutions(caller sessionCaller) []string {
 	cm.mutex.RLock()
 	defer cm.mutex.RUnlock()
 
 	// Create a slice with appropriate capacity to avoid reallocations
 	executions := make([]string, 0, len(cm.executions))
-	for id := range cm.executions {
-		executions = append(executions, id)
+	for id, execution := range cm.executions {
+		if caller.mayAccess(execution.owner, execution.tenant) {
+			executions = append(executions, id)
+		}
 	}
 	return executions
 }
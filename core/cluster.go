@@ -0,0 +1,196 @@
+/*
+Package core provides the Kubernetes in-cluster mode of the Skynet Agent application.
+
+Deployed as a pod, Skynet administers the cluster rather than the container it
+runs in. CLUSTER_MODE selects the mode: "auto" (the default) enables it when the
+pod environment is detected, that is when KUBERNETES_SERVICE_HOST is set and a
+service account token is mounted; "on" and "off" force it.
+
+In cluster mode the host-oriented tools (package managers, systemd, container
+runtimes, disks, and the like) are replaced by cluster-oriented ones: kubectl,
+helm, pod_logs for following logs, and a few general tools. kubectl and helm use
+the pod's service account, and commands run in the pod's namespace unless
+KUBECTL_NAMESPACE or the command selects another one. At startup the agent asks
+the API server what the service account may do ("kubectl auth can-i --list") and
+includes the answer in the system prompt, so the agent plans within its RBAC
+permissions instead of discovering them through failed commands.
+*/
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// Cluster modes
+const (
+	ClusterModeAuto = "auto" // Enable cluster mode when running in a pod
+	ClusterModeOn   = "on"   // Always use cluster mode
+	ClusterModeOff  = "off"  // Never use cluster mode
+)
+
+// serviceAccountDir is where Kubernetes mounts the pod's service account
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// clusterPermissionsLimit bounds the permission summary added to the system prompt
+const clusterPermissionsLimit = 4000
+
+// ClusterInfo describes the cluster identity the agent acts with in cluster mode.
+type ClusterInfo struct {
+	Namespace      string // Default namespace of the agent's commands
+	ServiceAccount string // Service account the pod runs as, "system:serviceaccount:<ns>:<name>"
+	Permissions    string // Output of "kubectl auth can-i --list"; empty when it could not be read
+}
+
+// inCluster reports whether the configuration selects cluster mode on this host.
+func inCluster(config *Config) bool {
+	switch config.ClusterMode {
+	case ClusterModeOn:
+		return true
+	case ClusterModeOff:
+		return false
+	}
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(serviceAccountDir, "token"))
+	return err == nil
+}
+
+// clusterNamespace returns the namespace of the agent's commands: KUBECTL_NAMESPACE,
+// or the pod's own namespace.
+func clusterNamespace(config *Config) string {
+	if config.KubectlNamespace != "" {
+		return config.KubectlNamespace
+	}
+	namespace, _ := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+	return strings.TrimSpace(string(namespace))
+}
+
+// clusterServiceAccount reads the service account name from the subject of the mounted token.
+func clusterServiceAccount() string {
+	token, err := os.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.TrimSpace(string(token)), ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	json.Unmarshal(payload, &claims)
+	return claims.Subject
+}
+
+// detectCluster returns the cluster identity when cluster mode is active, or nil.
+//
+// Parameters:
+//   - config: Configuration selecting the mode
+//   - logger: Logger for the detection outcome
+//
+// Returns:
+//   - *ClusterInfo: Namespace, service account, and permissions; nil outside cluster mode
+func detectCluster(config *Config, logger *logrus.Logger) *ClusterInfo {
+	if !inCluster(config) {
+		return nil
+	}
+	info := &ClusterInfo{
+		Namespace:      clusterNamespace(config),
+		ServiceAccount: clusterServiceAccount(),
+	}
+
+	args := []string{"auth", "can-i", "--list"}
+	if config.KubectlKubeconfig != "" {
+		args = append(args, "--kubeconfig", config.KubectlKubeconfig)
+	}
+	if info.Namespace != "" {
+		args = append(args, "--namespace", info.Namespace)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "kubectl", args...).Output()
+	if err != nil {
+		logger.WithError(err).Warn("Failed to read the service account's permissions; the agent will check them with kubectl auth can-i")
+	} else {
+		info.Permissions = strings.TrimSpace(string(output))
+		if len(info.Permissions) > clusterPermissionsLimit {
+			info.Permissions = info.Permissions[:clusterPermissionsLimit] + "\n... (truncated; use kubectl auth can-i for others)"
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"namespace":      info.Namespace,
+		"serviceAccount": info.ServiceAccount,
+		"permissions":    info.Permissions != "",
+	}).Info("Running in Kubernetes cluster mode")
+	return info
+}
+
+// promptContext describes the cluster identity for the system prompt.
+func (c *ClusterInfo) promptContext() string {
+	var b strings.Builder
+	b.WriteString("CLUSTER IDENTITY:\n")
+	if c.ServiceAccount != "" {
+		b.WriteString("- Service account: " + c.ServiceAccount + "\n")
+	}
+	if c.Namespace != "" {
+		b.WriteString("- Default namespace: " + c.Namespace + "\n")
+	}
+	if c.Permissions != "" {
+		b.WriteString("- RBAC permissions in the default namespace (kubectl auth can-i --list):\n")
+		b.WriteString(c.Permissions)
+	} else {
+		b.WriteString("- RBAC permissions are unknown; check them with kubectl 'auth can-i <verb> <resource>' before changes")
+	}
+	return b.String()
+}
+
+// clusterTools instantiates the tools offered in cluster mode.
+//
+// Parameters:
+//   - config: Configuration providing the tool settings
+//   - workspace: Default workspace shared by the tools
+//   - outputStore: Store of truncated outputs paged by the more tool
+//
+// Returns:
+//   - []tools.Tool: Cluster-oriented tools
+func clusterTools(config *Config, workspace *localtools.WorkspaceContext, outputStore *OutputStore) []tools.Tool {
+	namespace := clusterNamespace(config)
+	toolsList := []tools.Tool{
+		localtools.NewKubectlTool(config.KubectlKubeconfig, namespace, config.KubectlReadOnly, workspace),
+		localtools.NewHelmTool(config.KubectlKubeconfig, namespace, config.KubectlReadOnly, workspace),
+		localtools.NewPodLogsTool(config.KubectlKubeconfig, namespace),
+		localtools.NewDateTimeTool(),
+		localtools.NewLsTool(workspace),
+		localtools.NewCdTool(workspace),
+		localtools.NewCatTool(workspace),
+		localtools.NewGrepTool(workspace),
+		localtools.NewFileTool(workspace),
+		localtools.NewShellTool(workspace),
+		localtools.NewHttpTool(),
+		localtools.NewTextTool(workspace),
+		localtools.NewCalcTool(),
+		NewMoreTool(outputStore),
+	}
+	if config.NetworkToolEnabled {
+		toolsList = append(toolsList, localtools.NewNetworkTool())
+	}
+	return toolsList
+}
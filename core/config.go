@@ -15,6 +15,7 @@ providing reasonable defaults for development.
 package core
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -27,8 +28,19 @@ import (
 // This structure centralizes all operational parameters including server settings,
 // AI model configuration, performance tuning, and behavioral controls.
 type Config struct {
+	// Configuration source
+	ConfigFile string // YAML or TOML configuration file that was loaded; empty when none (default: "")
+
 	// Server configuration
-	Port string // HTTP server port number (default: "8080")
+	Port             string        // HTTP server port number (default: "8080")
+	HTTPCompression  bool          // Gzip-compress responses for clients that accept it, except streams (default: false)
+	HTTPReadTimeout  time.Duration // Maximum time to read a request, including its body; 0 means none (default: 0)
+	HTTPWriteTimeout time.Duration // Maximum time to write a response, except streams; 0 means none (default: 0)
+	HTTPIdleTimeout  time.Duration // Time an idle keep-alive connection is kept open (default: 120s)
+	HTTPKeepAlive    bool          // Reuse connections for several requests (default: true)
+	AllowedCIDRs     []string      // Networks allowed to reach the HTTP server; empty allows all (default: none)
+	AuthMaxFailures  int           // Consecutive failed authentication attempts that lock a client out; 0 disables lockouts (default: 5)
+	AuthLockout      time.Duration // Duration of a client's first lockout, doubled for each further one up to an hour (default: 60s)
 
 	// LLM Provider configuration
 	LLMProvider string // LLM provider to use: "ollama" or "gemini" (default: "ollama")
@@ -36,57 +48,392 @@ type Config struct {
 	// Ollama LLM configuration
 	OllamaEndpoint string // Base URL for the Ollama API service (default: "http://localhost:11434")
 	OllamaModel    string // Name of the Ollama model to use for inference (default: "qwen3")
+	OllamaAutoPull bool   // Pull the Ollama model at startup when it is missing (default: false)
+
+	// LLM readiness probe configuration
+	LLMProbeRetries int           // Startup probe attempts before giving up; 0 disables the probe (default: 10)
+	LLMProbeBackoff time.Duration // Delay before the second probe attempt, doubled for each further attempt up to 1m (default: 1s)
 
 	// Gemini LLM configuration
 	GeminiAPIKey string // API key for Google Gemini (required when using gemini provider)
 	GeminiModel  string // Name of the Gemini model to use for inference (default: "gemini-1.5-pro")
 
 	// Agent execution configuration
-	MaxIterations  int           // Maximum number of iterations for agent reasoning loops (default: 100)
-	RequestTimeout time.Duration // Timeout for individual requests to prevent hanging (default: 300s)
-	ContextLimit   int           // Maximum number of messages to include in conversation context (default: 10)
+	MaxIterations   int           // Maximum number of iterations for agent reasoning loops (default: 100)
+	RequestTimeout  time.Duration // Timeout for individual requests to prevent hanging (default: 300s)
+	ContextLimit    int           // Maximum number of messages to include in conversation context (default: 10)
+	AgentMode       string        // Agent planning mode: "react" or "functions" (default: "react")
+	StreamHeartbeat time.Duration // Interval of keepalive and progress events on streams; 0 disables (default: 10s)
+	DisconnectGrace time.Duration // Time a stream's execution keeps running after its client disconnects; negative never cancels (default: 10s)
+
+	// Parallel tool execution configuration
+	ToolParallelism int // Maximum concurrent read-only tool calls per step in function-calling mode; 1 disables (default: 4)
+
+	// Tool execution configuration
+	ToolTimeout  time.Duration            // Default timeout applied to every tool call (default: 60s)
+	ToolTimeouts map[string]time.Duration // Per-tool timeout overrides keyed by tool name (default: docker=30s, apk=60s, systemctl=30s, ps=15s, capture=150s)
+
+	// Tool retry configuration
+	ToolRetries      map[string]int // Retries after a failed call keyed by tool name (default: network=2, docker=2, apk=2)
+	ToolRetryBackoff time.Duration  // Delay before the first retry, doubled for each further retry (default: 1s)
+
+	// Tool output configuration
+	ToolMaxOutput       int            // Maximum tool output in bytes returned to the agent before truncation (default: 16000)
+	ToolMaxOutputs      map[string]int // Per-tool output limit overrides keyed by tool name (default: none)
+	ToolOutputRetention time.Duration  // How long full truncated outputs are kept for paging (default: 1h)
+
+	// Tool result caching configuration
+	ToolCacheTTL   time.Duration // How long read-only tool results are reused within an execution; 0 disables (default: 10s)
+	CacheableTools []string      // Read-only tools whose results may be cached (default: sysinfo, ls, stat, cat, more)
+
+	// Tool resource limit configuration
+	ToolLimitsFile string // YAML file declaring CPU, memory, process, and output limits of tool commands per tool class (default: "tool_limits.yaml")
+	ToolCgroupPath string // cgroup v2 directory in which each tool class gets a cgroup capping its commands; empty disables cgroups (default: "")
+
+	// Tool scheduling priority configuration
+	ToolNice    int    // Niceness of tool commands, 0 (unchanged) to 19 (lowest CPU priority) (default: 0)
+	ToolIOClass string // I/O scheduling class of tool commands: "best-effort", "idle", or empty for unchanged (default: "")
+	ToolIOLevel int    // I/O priority within the best-effort class, 0 (highest) to 7 (lowest) (default: 7)
+
+	// Process control configuration
+	ProcProtected []string // Process names the proc tool refuses to signal or renice (default: init, systemd, sshd, containerd, dockerd)
+
+	// Kernel parameter configuration
+	SysctlWriteEnabled bool // Allow the env tool to set kernel parameters (default: false)
+
+	// Custom tool configuration
+	ToolsFilePath   string // YAML file declaring operator-defined command tools (default: "tools.yaml")
+	PluginDir       string // Directory of Go plugins and external executable tools (default: "plugins")
+	MCPConfigPath   string // YAML file declaring MCP servers whose tools are exposed to the agent (default: "mcp.yaml")
+	CustomToolsPath string // JSON file persisting tools registered through POST /admin/tools; empty keeps them in memory (default: "custom_tools.json")
+
+	// Kubernetes tool configuration
+	KubectlKubeconfig string // Kubeconfig file for the kubectl tool; empty uses kubectl's default resolution (default: "")
+	KubectlNamespace  string // Default namespace for kubectl commands that do not select one (default: "")
+	KubectlReadOnly   bool   // Restrict the kubectl tool to get, describe, logs, and top (default: false)
+	ClusterMode       string // Kubernetes in-cluster mode: "auto", "on", or "off" (default: "auto")
+
+	// Ansible tool configuration
+	AnsibleInventory string // Default inventory file for the ansible tool; empty uses Ansible's default (default: "")
+
+	// AWS tool configuration
+	AwsProfile  string // Named profile for the aws tool; empty uses the CLI's default resolution (default: "")
+	AwsRegion   string // Region for aws commands that do not select one (default: "")
+	AwsReadOnly bool   // Restrict the aws tool to describe, list, and get operations (default: true)
+
+	// Network tool configuration
+	NetworkToolEnabled bool // Offer the network tool (ip, ss, arp, ping, dig, curl, ...) to the agent (default: true)
+
+	// Tool availability configuration
+	DisabledTools []string // Tools hidden from the agent and the tools API (default: none)
+
+	// Direct tool invocation configuration
+	ToolInvokeEnabled bool // Allow POST /admin/tools/:name/invoke to run tools without the LLM (default: false)
 
 	// Memory store configuration for session management
 	SessionMaxAge      time.Duration // How long to keep sessions in memory before expiring (default: 24h)
 	CleanupInterval    time.Duration // How often to run cleanup of expired sessions (default: 1h)
 	MaxSessionsPerUser int           // Maximum sessions allowed per user to prevent memory exhaustion (default: 50)
+	SessionMaxCount    int           // Maximum sessions in memory before the least recently used are evicted; 0 disables (default: 1000)
+	SessionMaxMessages int           // Maximum messages across all sessions before eviction; 0 disables (default: 100000)
+	SessionMaxBytes    int64         // Maximum total message size in bytes across all sessions before eviction; 0 disables (default: 256 MB)
+	SessionMessages    int           // Maximum messages kept per session, dropping the oldest; 0 disables (default: 500)
+	MessageMaxBytes    int           // Maximum size of a stored message, keeping its head and tail; 0 disables (default: 64 KB)
 
 	// Logging and debugging configuration
-	LogLevel          string // Minimum log level: debug, info, warn, error (default: "info")
-	LogTruncateLength int    // Maximum length for log message truncation to prevent excessive output (default: 500)
-	DebugMode         bool   // Enable debug mode for detailed internal logging (default: true)
+	LogLevel          string        // Minimum log level: debug, info, warn, error (default: "info")
+	LogTruncateLength int           // Maximum length for log message truncation to prevent excessive output (default: 500)
+	LogFormat         string        // Log entry format: json or text (default: "json")
+	LogOutput         string        // Comma-separated log sinks: stdout, stderr, file, syslog (default: "stdout")
+	LogFile           string        // Log file written by the file sink (default: "skynet.log")
+	LogFileMaxSize    int           // Size in megabytes that rotates the log file; 0 disables size-based rotation (default: 100)
+	LogFileMaxAge     time.Duration // Age that rotates the log file; 0 disables age-based rotation (default: 24h)
+	LogFileMaxBackups int           // Rotated log files kept; 0 keeps all (default: 7)
+	LogSyslogAddress  string        // Remote syslog server as [udp|tcp://]host:port; empty uses the local daemon (default: "")
+	DebugMode         bool          // Enable debug mode for detailed internal logging (default: true)
 
 	// Performance tuning parameters
-	MaxConcurrentRequests int // Maximum number of concurrent requests to handle (default: 100)
+	MaxConcurrentRequests int // Maximum number of concurrent agent executions for chat requests (default: 100)
+	QueueMaxSize          int // Maximum chat requests waiting for an execution slot; 0 rejects when all slots are busy (default: 100)
+
+	// Audit configuration
+	AuditLogPath  string // Path of the append-only JSONL tool audit log; empty disables auditing (default: "")
+	EncryptionKey string // Base64 AES key encrypting tool inputs and errors at rest; empty disables encryption (default: "")
+
+	// Redaction configuration
+	RedactionEnabled  bool     // Mask secrets in logs, session history, and streamed events (default: true)
+	RedactionPatterns []string // Additional regular expressions of secrets to mask (default: none)
+
+	// Conversation analytics configuration
+	AnalyticsEnabled bool   // Enable background intent/outcome tagging of conversations (default: false)
+	AnalyticsLogPath string // Optional JSONL file receiving conversation tags (default: "")
+
+	// Self-update configuration
+	SelfUpdateEnabled bool   // Allow installing new releases through the update API (default: false)
+	UpdateURL         string // Release manifest endpoint checked for new versions (default: "")
+	UpdatePublicKey   string // Base64 Ed25519 public key used to verify signed release statements (default: "")
+
+	// Admin API configuration
+	AdminToken      string // Bearer token required by the /admin endpoints; empty disables them unless an API key has the admin scope (default: "")
+	APIKeysPath     string // JSON file persisting API keys created through /admin/keys; empty keeps them in memory (default: "api_keys.json")
+	TenantsFile     string // YAML file declaring the tenants API keys can belong to (default: "tenants.yaml")
+	AnonymousAccess string // Serve requests without an API key: "auto" (until chat keys or tenants exist), "on", or "off" (default: "auto")
+	QuotaUsagePath  string // JSON file persisting the usage counted against quotas; empty keeps it in memory (default: "quota_usage.json")
+
+	// Tracing configuration
+	OTelEndpoint    string            // OTLP/HTTP collector base URL receiving trace spans; empty disables tracing (default: "")
+	OTelHeaders     map[string]string // Extra headers of trace export requests, e.g. for authentication (default: none)
+	OTelServiceName string            // service.name reported with the spans (default: "skynet")
+
+	// Error reporting configuration
+	SentryDSN         string // Sentry DSN receiving panics and failed executions; empty disables reporting (default: "")
+	SentryEnvironment string // Environment reported with the events, e.g. production (default: "")
+
+	// Alertmanager integration configuration
+	AlertmanagerToken     string // Bearer token required from Alertmanager; empty disables the receiver (default: "")
+	AlertmanagerPrompt    string // Go template of the investigation prompt, rendered with the alert (default: built-in prompt)
+	AlertmanagerNotifyURL string // Webhook receiving the investigation findings as JSON (default: "")
+
+	// Slack bot configuration
+	SlackBotToken      string   // Bot token (xoxb-...) used to post answers; empty disables the bot (default: "")
+	SlackSigningSecret string   // Signing secret verifying requests from Slack (default: "")
+	SlackChannels      []string // IDs of the channels whose messages the bot answers (default: none)
+	SlackAllowDMs      bool     // Answer direct messages to the bot (default: false)
+
+	// Matrix bot configuration
+	MatrixHomeserverURL string   // Homeserver (or E2EE proxy) base URL; empty disables the bot (default: "")
+	MatrixAccessToken   string   // Access token of the bot account (default: "")
+	MatrixRooms         []string // IDs of the rooms whose messages the bot answers (default: none)
+
+	// MQTT integration configuration
+	MQTTBrokerURL    string // Broker URL (tcp://, ssl://, ...); empty disables the integration (default: "")
+	MQTTClientID     string // Client ID of the agent at the broker (default: "skynet-<hostname>")
+	MQTTUsername     string // Username authenticating to the broker (default: "")
+	MQTTPassword     string // Password authenticating to the broker (default: "")
+	MQTTCommandTopic string // Topic the agent receives commands on (default: "skynet/<hostname>/commands")
+	MQTTResultTopic  string // Topic the agent publishes progress and answers to (default: "skynet/<hostname>/results")
+
+	// Execution targets
+	Targets []string // Remote targets chat requests may run tool commands on, such as "ssh:web1" (default: none)
+
+	// SSH chat server configuration
+	SSHAddr           string // Address the SSH chat server listens on, such as ":2222"; empty disables it (default: "")
+	SSHHostKeyFile    string // Host key of the SSH server, generated when missing (default: "ssh_host_ed25519_key")
+	SSHAuthorizedKeys string // authorized_keys file listing the public keys allowed to connect (default: "")
+
+	// Audio transcription configuration
+	TranscriptionBackend string // Backend transcribing POST /chat/audio clips: "whisper" or "gemini"; empty disables it (default: "")
+	WhisperBinary        string // whisper.cpp command-line program (default: "whisper-cli")
+	WhisperModel         string // whisper.cpp model file, such as "ggml-base.en.bin" (default: "")
+
+	// Speech output configuration
+	TTSBackend string // Backend synthesizing spoken answers: "piper" or "espeak"; empty disables it (default: "")
+	TTSBinary  string // Synthesizer program; empty uses "piper" or "espeak-ng" (default: "")
+	TTSVoice   string // Piper voice model file, or eSpeak voice name (default: "")
+
+	// GitHub integration configuration
+	GitHubWebhookSecret string   // Secret verifying GitHub webhook signatures; empty disables the integration (default: "")
+	GitHubToken         string   // Token used to post answers as comments (default: "")
+	GitHubRepos         []string // Repositories (owner/name) whose "/skynet" comments are run (default: none)
+	GitHubAPIURL        string   // GitHub REST API base URL, for GitHub Enterprise Server (default: "https://api.github.com")
+
+	// Background task configuration
+	TasksPath    string // JSON file persisting background tasks; empty keeps them in memory (default: "tasks.json")
+	TaskMaxSteps int    // Steps after which a task fails unless it sets its own limit (default: 50)
+
+	// Workflow configuration
+	WorkflowsFile string // YAML file declaring the workflows run with POST /workflows/<name>/run (default: "workflows.yaml")
+
+	// Inbound webhook configuration
+	HooksFile string // YAML file declaring the hooks served at POST /hooks/<name> (default: "hooks.yaml")
+
+	// Notification configuration
+	NotifiersFile   string   // YAML file declaring notification sinks (default: "notifiers.yaml")
+	SMTPHost        string   // SMTP server sending notifications; empty disables email (default: "")
+	SMTPPort        int      // SMTP server port (default: 587)
+	SMTPUsername    string   // SMTP login; empty sends without authentication (default: "")
+	SMTPPassword    string   // SMTP password (default: "")
+	EmailFrom       string   // Sender address (default: SMTP username)
+	EmailRecipients []string // Addresses of the "email" notification sink (default: none)
+	EmailNotifyOn   []string // Events sent to the "email" sink: failure, success, report, security (default: failure, report, security)
+	EmailSubject    string   // Go template of email subjects, rendered with the notification (default: built-in subject)
 }
 
-// LoadConfig loads configuration from environment variables with sensible defaults.
-// This function implements the configuration loading strategy by first setting
-// reasonable defaults and then overriding them with environment variables if present.
-// All environment variable parsing includes validation to ensure sensible values.
+// LoadConfig loads configuration from environment variables and an optional
+// configuration file with sensible defaults. This function implements the configuration
+// loading strategy by first setting reasonable defaults and then overriding them with
+// the settings of the configuration file and environment variables, in increasing
+// precedence. All value parsing includes validation to ensure sensible values.
+//
+// Parameters:
+//   - path: YAML or TOML configuration file; empty uses SKYNET_CONFIG or skynet.yaml/.yml/.toml if present
+//
+// Returns:
+//   - *Config: Loaded configuration
+//   - error: Read or parse error of the configuration file, or missing or contradictory settings
+//
+// Configuration file keys are the names of the environment variables below.
 //
 // Environment Variables:
+//   - SKYNET_CONFIG: Configuration file path (string)
+//   - <NAME>_FILE: File holding the value of any variable below, e.g. GEMINI_API_KEY_FILE (string)
 //   - PORT: Server port (string)
+//   - HTTP_COMPRESSION: Gzip-compress responses (boolean)
+//   - HTTP_READ_TIMEOUT: Request read timeout in seconds, 0 for none (integer)
+//   - HTTP_WRITE_TIMEOUT: Response write timeout in seconds, 0 for none (integer)
+//   - HTTP_IDLE_TIMEOUT: Idle keep-alive connection timeout in seconds (integer)
+//   - HTTP_KEEPALIVE: Enable HTTP keep-alive (boolean)
+//   - ALLOWED_CIDRS: Networks allowed to reach the HTTP server (comma-separated CIDRs or addresses)
+//   - AUTH_MAX_FAILURES: Failed authentication attempts that lock a client out, 0 to disable (integer)
+//   - AUTH_LOCKOUT: First lockout duration in seconds (integer)
 //   - LLM_PROVIDER: LLM provider to use: "ollama" or "gemini" (string)
 //   - OLLAMA_ENDPOINT: Ollama API endpoint URL (string)
 //   - OLLAMA_MODEL: Model name for inference (string)
+//   - OLLAMA_AUTO_PULL: Pull a missing Ollama model at startup (boolean: "true"/"1")
+//   - LLM_PROBE_RETRIES: Startup LLM probe attempts, 0 disables (integer)
+//   - LLM_PROBE_BACKOFF_MS: Initial LLM probe backoff in milliseconds (integer)
 //   - GEMINI_API_KEY: Google Gemini API key (string)
 //   - GEMINI_MODEL: Gemini model name for inference (string)
 //   - MAX_ITERATIONS: Maximum agent iterations (integer)
 //   - REQUEST_TIMEOUT: Request timeout in seconds (integer)
 //   - CONTEXT_LIMIT: Maximum context messages (integer)
+//   - AGENT_MODE: Agent planning mode: "react" or "functions" (string)
+//   - STREAM_HEARTBEAT: Seconds between stream keepalive and progress events, 0 disables (integer)
+//   - DISCONNECT_GRACE: Seconds before a disconnected stream's execution is cancelled, -1 never (integer)
+//   - TOOL_PARALLELISM: Concurrent read-only tool calls per step (integer)
+//   - TOOL_TIMEOUT: Default tool timeout in seconds (integer)
+//   - TOOL_TIMEOUTS: Per-tool timeouts in seconds (string: "docker=30,apk=120")
+//   - TOOL_RETRIES: Per-tool retries after a failed call (string: "network=2,docker=3")
+//   - TOOL_RETRY_BACKOFF_MS: Initial retry backoff in milliseconds (integer)
+//   - TOOL_MAX_OUTPUT: Default tool output limit in bytes (integer)
+//   - TOOL_MAX_OUTPUTS: Per-tool output limits in bytes (string: "cat=32000,ps=8000")
+//   - TOOL_OUTPUT_RETENTION_MINUTES: Retention of truncated outputs in minutes (integer)
+//   - TOOL_CACHE_TTL: Read-only tool cache TTL in seconds, 0 disables (integer)
+//   - TOOL_CACHE_TOOLS: Cacheable read-only tools (string: "sysinfo,ls,stat,cat")
+//   - TOOL_LIMITS_FILE: Tool resource limits YAML file path (string)
+//   - TOOL_CGROUP_PATH: cgroup v2 directory for the tool classes' cgroups (string)
+//   - TOOL_NICE: Niceness of tool commands, 0-19 (integer)
+//   - TOOL_IONICE_CLASS: I/O scheduling class of tool commands: "best-effort" or "idle" (string)
+//   - TOOL_IONICE_LEVEL: Best-effort I/O priority of tool commands, 0-7 (integer)
+//   - PROC_PROTECTED: Processes protected from the proc tool (string: "init,systemd,sshd")
+//   - SYSCTL_WRITE_ENABLED: Allow setting kernel parameters (boolean: "true"/"1")
+//   - TOOLS_FILE: Command tools YAML file path (string)
+//   - PLUGIN_DIR: Tool plugin directory (string)
+//   - MCP_CONFIG_PATH: MCP servers YAML file path (string)
+//   - CUSTOM_TOOLS_PATH: Runtime-registered tools file path (string)
+//   - KUBECTL_KUBECONFIG: Kubeconfig file for the kubectl tool (string)
+//   - KUBECTL_NAMESPACE: Default kubectl namespace (string)
+//   - KUBECTL_READ_ONLY: Restrict kubectl to read-only commands (boolean: "true"/"1")
+//   - CLUSTER_MODE: Kubernetes in-cluster mode, "auto", "on", or "off" (string)
+//   - ANSIBLE_INVENTORY: Default inventory file for the ansible tool (string)
+//   - AWS_TOOL_PROFILE: Named profile for the aws tool (string)
+//   - AWS_TOOL_REGION: Default region for the aws tool (string)
+//   - AWS_TOOL_READ_ONLY: Restrict the aws tool to read-only operations (boolean: "true"/"1")
+//   - NETWORK_TOOL_ENABLED: Offer the network tool to the agent (boolean: "true"/"1")
+//   - TOOLS_DISABLED: Comma-separated tools hidden from the agent (string)
+//   - TOOL_INVOKE_ENABLED: Allow direct tool invocation (boolean: "true"/"1")
 //   - SESSION_MAX_AGE_HOURS: Session expiry in hours (integer)
 //   - CLEANUP_INTERVAL_MINUTES: Cleanup frequency in minutes (integer)
 //   - MAX_SESSIONS_PER_USER: Maximum sessions per user (integer)
+//   - SESSION_MAX_COUNT: Maximum sessions in memory, 0 disables (integer)
+//   - SESSION_MAX_MESSAGES: Maximum messages across all sessions, 0 disables (integer)
+//   - SESSION_MAX_MB: Maximum total message size in megabytes, 0 disables (integer)
+//   - SESSION_MESSAGE_LIMIT: Maximum messages per session, 0 disables (integer)
+//   - MESSAGE_MAX_KB: Maximum size of a stored message in kilobytes, 0 disables (integer)
 //   - LOG_LEVEL: Logging level (string)
 //   - LOG_TRUNCATE_LENGTH: Log truncation length (integer)
+//   - LOG_FORMAT: Log entry format, json or text (string)
+//   - LOG_OUTPUT: Comma-separated log sinks (string)
+//   - LOG_FILE: Log file path (string)
+//   - LOG_FILE_MAX_SIZE_MB: Log file rotation size in megabytes (integer)
+//   - LOG_FILE_MAX_AGE_HOURS: Log file rotation age in hours (integer)
+//   - LOG_FILE_MAX_BACKUPS: Rotated log files kept (integer)
+//   - LOG_SYSLOG_ADDRESS: Remote syslog server (string)
 //   - DEBUG_MODE: Enable debug mode (boolean: "true"/"1")
 //   - MAX_CONCURRENT_REQUESTS: Concurrent request limit (integer)
-func LoadConfig() *Config {
+//   - QUEUE_MAX_SIZE: Queued chat request limit (integer)
+//   - AUDIT_LOG_PATH: Tool audit log file path (string)
+//   - ENCRYPTION_KEY: Base64 AES key for encryption at rest (string)
+//   - REDACTION_ENABLED: Mask secrets in logs, history, and streams (boolean)
+//   - REDACTION_PATTERNS: Additional secret patterns (comma-separated regular expressions)
+//   - ANALYTICS_ENABLED: Enable conversation analytics (boolean: "true"/"1")
+//   - ANALYTICS_LOG_PATH: Conversation tags file path (string)
+//   - SELF_UPDATE_ENABLED: Allow self-update (boolean: "true"/"1")
+//   - UPDATE_URL: Release manifest URL (string)
+//   - UPDATE_PUBLIC_KEY: Base64 Ed25519 release signing key (string)
+//   - ADMIN_TOKEN: Bearer token for the admin API (string)
+//   - API_KEYS_PATH: JSON file persisting runtime API keys (string)
+//   - TENANTS_FILE: Tenants YAML file path (string)
+//   - ANONYMOUS_ACCESS: Requests without an API key, "auto", "on", or "off" (string)
+//   - QUOTA_USAGE_PATH: JSON file persisting quota usage (string)
+//   - OTEL_EXPORTER_OTLP_ENDPOINT: OTLP/HTTP trace collector URL (string)
+//   - OTEL_EXPORTER_OTLP_HEADERS: Trace export headers as key=value pairs separated by commas (string)
+//   - OTEL_SERVICE_NAME: Service name reported with trace spans (string)
+//   - SENTRY_DSN: Sentry DSN for error reporting (string)
+//   - SENTRY_ENVIRONMENT: Environment reported to Sentry (string)
+//   - ALERTMANAGER_TOKEN: Bearer token for the Alertmanager webhook receiver (string)
+//   - ALERTMANAGER_PROMPT: Alert investigation prompt template (string)
+//   - ALERTMANAGER_NOTIFY_URL: Webhook for alert investigation findings (string)
+//   - SLACK_BOT_TOKEN: Slack bot token (string)
+//   - SLACK_SIGNING_SECRET: Slack app signing secret (string)
+//   - SLACK_CHANNELS: Comma-separated Slack channel IDs the bot answers in (string)
+//   - SLACK_ALLOW_DMS: Answer direct messages to the Slack bot (boolean: "true"/"1")
+//   - MATRIX_HOMESERVER_URL: Matrix homeserver base URL (string)
+//   - MATRIX_ACCESS_TOKEN: Access token of the Matrix bot account (string)
+//   - MATRIX_ROOMS: Comma-separated Matrix room IDs the bot answers in (string)
+//   - MQTT_BROKER_URL: MQTT broker URL (string)
+//   - MQTT_CLIENT_ID: MQTT client ID (string)
+//   - MQTT_USERNAME: MQTT broker username (string)
+//   - MQTT_PASSWORD: MQTT broker password (string)
+//   - MQTT_COMMAND_TOPIC: MQTT topic receiving commands (string)
+//   - MQTT_RESULT_TOPIC: MQTT topic receiving results (string)
+//   - TARGETS: Comma-separated execution targets, such as "ssh:web1,docker:postgres" (string)
+//   - SSH_ADDR: Listen address of the SSH chat server (string)
+//   - SSH_HOST_KEY_FILE: Host key file of the SSH chat server (string)
+//   - SSH_AUTHORIZED_KEYS: authorized_keys file of the SSH chat server (string)
+//   - TRANSCRIPTION_BACKEND: Audio transcription backend, "whisper" or "gemini" (string)
+//   - WHISPER_BINARY: whisper.cpp program (string)
+//   - WHISPER_MODEL: whisper.cpp model file (string)
+//   - TTS_BACKEND: Speech synthesis backend, "piper" or "espeak" (string)
+//   - TTS_BINARY: Speech synthesis program (string)
+//   - TTS_VOICE: Piper voice model file or eSpeak voice (string)
+//   - GITHUB_WEBHOOK_SECRET: Secret of the GitHub webhook (string)
+//   - GITHUB_TOKEN: GitHub token posting answers (string)
+//   - GITHUB_REPOS: Comma-separated owner/name repositories accepting commands (string)
+//   - GITHUB_API_URL: GitHub REST API base URL (string)
+//   - TASKS_PATH: JSON file persisting background tasks (string)
+//   - TASK_MAX_STEPS: Default step limit of background tasks (integer)
+//   - WORKFLOWS_FILE: YAML file declaring workflows (string)
+//   - HOOKS_FILE: YAML file declaring inbound webhook triggers (string)
+//   - NOTIFIERS_FILE: YAML file declaring notification sinks (string)
+//   - SMTP_HOST: SMTP server for email notifications (string)
+//   - SMTP_PORT: SMTP server port (integer)
+//   - SMTP_USERNAME: SMTP login (string)
+//   - SMTP_PASSWORD: SMTP password (string)
+//   - EMAIL_FROM: Sender address of email notifications (string)
+//   - EMAIL_RECIPIENTS: Comma-separated notification recipients (string)
+//   - EMAIL_NOTIFY_ON: Comma-separated notifications to send: failure, success, report, security (string)
+//   - EMAIL_SUBJECT: Email subject template (string)
+func LoadConfig(path string) (*Config, error) {
+	source, err := newConfigSource(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// The MQTT defaults give every device of a fleet its own client ID and topics
+	hostname, _ := os.Hostname()
+
 	// Initialize configuration with sensible defaults
 	config := &Config{
+		// Configuration source
+		ConfigFile: source.path,
+
 		// Server defaults
-		Port: "8080",
+		Port:            "8080",
+		HTTPIdleTimeout: 120 * time.Second,
+		HTTPKeepAlive:   true,
+		AuthMaxFailures: 5,
+		AuthLockout:     60 * time.Second,
 
 		// LLM Provider defaults
 		LLMProvider: "gemini",
@@ -94,142 +441,1024 @@ func LoadConfig() *Config {
 		// Ollama service defaults
 		OllamaEndpoint: "http://localhost:11434",
 		OllamaModel:    "qwen3",
+		OllamaAutoPull: false,
+
+		// LLM readiness probe defaults; about 8 minutes of retries in total
+		LLMProbeRetries: 10,
+		LLMProbeBackoff: 1 * time.Second,
 
 		// Gemini service defaults
 		GeminiAPIKey: "", // Must be provided via environment variable
 		GeminiModel:  "gemini-2.0-flash",
 
 		// Agent behavior defaults
-		MaxIterations:  100,
-		RequestTimeout: 300 * time.Second, // 5 minutes
-		ContextLimit:   10,
+		MaxIterations:   100,
+		RequestTimeout:  300 * time.Second, // 5 minutes
+		ContextLimit:    10,
+		AgentMode:       AgentModeReAct,
+		StreamHeartbeat: 10 * time.Second,
+		DisconnectGrace: 10 * time.Second,
+
+		// Parallel tool execution defaults
+		ToolParallelism: 4,
+
+		// Tool execution defaults
+		ToolTimeout: 60 * time.Second,
+		ToolTimeouts: map[string]time.Duration{
+			"docker":    30 * time.Second,
+			"podman":    30 * time.Second,
+			"apk":       60 * time.Second,
+			"systemctl": 30 * time.Second,
+			"ps":        15 * time.Second,
+			"capture":   150 * time.Second,  // Covers the capture tool's maximum duration
+			"ansible":   600 * time.Second,  // Playbooks against many hosts run for minutes
+			"certbot":   180 * time.Second,  // ACME challenges wait for validation
+			"ollama":    1800 * time.Second, // Pulling large models downloads gigabytes
+		},
+
+		// Tool retry defaults
+		ToolRetries: map[string]int{
+			"network": 2,
+			"docker":  2,
+			"podman":  2,
+			"apk":     2,
+		},
+		ToolRetryBackoff: 1 * time.Second,
+
+		// Tool output defaults
+		ToolMaxOutput:       16000,
+		ToolMaxOutputs:      map[string]int{},
+		ToolOutputRetention: 1 * time.Hour,
+
+		// Tool result caching defaults
+		ToolCacheTTL:   10 * time.Second,
+		CacheableTools: []string{"sysinfo", "ls", "stat", "cat", "more"},
+
+		// Tool resource limit defaults; no limits until the file declares them
+		ToolLimitsFile: "tool_limits.yaml",
+		ToolCgroupPath: "",
+
+		// Tool scheduling priority defaults; commands run at the server's priority
+		ToolNice:    0,
+		ToolIOClass: "",
+		ToolIOLevel: 7,
+
+		// Process control defaults
+		ProcProtected: []string{"init", "systemd", "sshd", "containerd", "dockerd"},
+
+		// Kernel parameter defaults
+		SysctlWriteEnabled: false,
+
+		// Custom tool defaults
+		ToolsFilePath:   "tools.yaml",
+		PluginDir:       "plugins",
+		MCPConfigPath:   "mcp.yaml",
+		CustomToolsPath: "custom_tools.json",
+
+		// Kubernetes tool defaults
+		KubectlKubeconfig: "", // Use KUBECONFIG or ~/.kube/config
+		KubectlNamespace:  "", // Use the kubeconfig context's namespace
+		KubectlReadOnly:   false,
+		ClusterMode:       ClusterModeAuto, // Detect the pod environment
+
+		// Ansible tool defaults
+		AnsibleInventory: "", // Use /etc/ansible/hosts or ansible.cfg
+
+		// AWS tool defaults; incident response only needs to read resources
+		AwsProfile:  "", // Use AWS_PROFILE or the default profile
+		AwsRegion:   "", // Use AWS_REGION or the profile's region
+		AwsReadOnly: true,
+
+		// Network tool defaults
+		NetworkToolEnabled: true,
+
+		// Direct tool invocation defaults
+		ToolInvokeEnabled: false,
 
 		// Session management defaults
 		SessionMaxAge:      24 * time.Hour, // 1 day
 		CleanupInterval:    1 * time.Hour,  // 1 hour
 		MaxSessionsPerUser: 50,
+		SessionMaxCount:    1000,
+		SessionMaxMessages: 100000,
+		SessionMaxBytes:    256 << 20,
+		SessionMessages:    500,
+		MessageMaxBytes:    64 << 10,
 
 		// Logging defaults
 		LogLevel:          "info",
 		LogTruncateLength: 500,
+		LogFormat:         "json",
+		LogOutput:         "stdout",
+		LogFile:           "skynet.log",
+		LogFileMaxSize:    100,
+		LogFileMaxAge:     24 * time.Hour,
+		LogFileMaxBackups: 7,
+		LogSyslogAddress:  "",
 		DebugMode:         true,
 
 		// Performance defaults
 		MaxConcurrentRequests: 100,
+		QueueMaxSize:          100,
+
+		// Audit defaults
+		AuditLogPath: "", // Auditing disabled unless a path is configured
+
+		// Redaction defaults
+		RedactionEnabled: true,
+
+		// Analytics defaults
+		AnalyticsEnabled: false,
+		AnalyticsLogPath: "",
+
+		// Self-update defaults
+		SelfUpdateEnabled: false,
+		UpdateURL:         "",
+		UpdatePublicKey:   "",
+
+		// Admin API defaults; disabled until a token is configured
+		AdminToken:      "",
+		APIKeysPath:     "api_keys.json",
+		TenantsFile:     "tenants.yaml",
+		AnonymousAccess: AnonymousAccessAuto, // Rejected once chat keys or tenants exist
+		QuotaUsagePath:  "quota_usage.json",
+
+		// Tracing defaults; disabled until a collector is configured
+		OTelEndpoint:    "",
+		OTelServiceName: "skynet",
+
+		// Error reporting defaults; disabled until a DSN is configured
+		SentryDSN:         "",
+		SentryEnvironment: "",
+
+		// Alertmanager defaults; disabled until a token is configured
+		AlertmanagerToken:     "",
+		AlertmanagerPrompt:    defaultAlertPrompt,
+		AlertmanagerNotifyURL: "",
+
+		// Slack defaults; disabled until a bot token is configured
+		SlackBotToken:      "",
+		SlackSigningSecret: "",
+		SlackAllowDMs:      false,
+
+		// Matrix defaults; disabled until a homeserver and access token are configured
+		MatrixHomeserverURL: "",
+		MatrixAccessToken:   "",
+
+		// MQTT defaults; disabled until a broker is configured
+		MQTTBrokerURL:    "",
+		MQTTClientID:     "skynet-" + hostname,
+		MQTTCommandTopic: "skynet/" + hostname + "/commands",
+		MQTTResultTopic:  "skynet/" + hostname + "/results",
+
+		// SSH defaults; disabled until a listen address is configured
+		SSHAddr:        "",
+		SSHHostKeyFile: "ssh_host_ed25519_key",
+
+		// Transcription defaults; disabled until a backend is configured
+		TranscriptionBackend: "",
+		WhisperBinary:        "whisper-cli",
+
+		// GitHub defaults; disabled until a webhook secret and token are configured
+		GitHubWebhookSecret: "",
+		GitHubToken:         "",
+		GitHubAPIURL:        "https://api.github.com",
+
+		// Background task defaults
+		TasksPath:    "tasks.json",
+		TaskMaxSteps: 50,
+
+		// Workflow defaults; no workflows until declared
+		WorkflowsFile: "workflows.yaml",
+
+		// Inbound webhook defaults; no hooks until declared
+		HooksFile: "hooks.yaml",
+
+		// Notification defaults; no sinks until declared
+		NotifiersFile: "notifiers.yaml",
+		SMTPHost:      "",
+		SMTPPort:      587,
+		EmailNotifyOn: defaultNotifyOn,
+		EmailSubject:  defaultEmailSubject,
 	}
 
 	// Override defaults with environment variables if present
 
 	// Server configuration
-	if port := os.Getenv("PORT"); port != "" {
+	if port := source.get("PORT"); port != "" {
 		config.Port = port
 	}
 
-	// LLM Provider configuration
-	if provider := os.Getenv("LLM_PROVIDER"); provider != "" {
-		if provider == "ollama" || provider == "gemini" {
-			config.LLMProvider = provider
+	if compression := source.get("HTTP_COMPRESSION"); compression != "" {
+		config.HTTPCompression = strings.ToLower(compression) == "true" || compression == "1"
+	}
+
+	if readTimeout := source.get("HTTP_READ_TIMEOUT"); readTimeout != "" {
+		if val, err := strconv.Atoi(readTimeout); err == nil && val >= 0 {
+			config.HTTPReadTimeout = time.Duration(val) * time.Second
 		}
 	}
 
+	if writeTimeout := source.get("HTTP_WRITE_TIMEOUT"); writeTimeout != "" {
+		if val, err := strconv.Atoi(writeTimeout); err == nil && val >= 0 {
+			config.HTTPWriteTimeout = time.Duration(val) * time.Second
+		}
+	}
+
+	if idleTimeout := source.get("HTTP_IDLE_TIMEOUT"); idleTimeout != "" {
+		if val, err := strconv.Atoi(idleTimeout); err == nil && val > 0 {
+			config.HTTPIdleTimeout = time.Duration(val) * time.Second
+		}
+	}
+
+	if keepAlive := source.get("HTTP_KEEPALIVE"); keepAlive != "" {
+		config.HTTPKeepAlive = strings.ToLower(keepAlive) == "true" || keepAlive == "1"
+	}
+
+	if cidrs := source.get("ALLOWED_CIDRS"); cidrs != "" {
+		for _, cidr := range strings.Split(cidrs, ",") {
+			if cidr = strings.TrimSpace(cidr); cidr != "" {
+				config.AllowedCIDRs = append(config.AllowedCIDRs, cidr)
+			}
+		}
+	}
+
+	if maxFailures := source.get("AUTH_MAX_FAILURES"); maxFailures != "" {
+		if val, err := strconv.Atoi(maxFailures); err == nil && val >= 0 {
+			config.AuthMaxFailures = val
+		}
+	}
+
+	if lockout := source.get("AUTH_LOCKOUT"); lockout != "" {
+		if val, err := strconv.Atoi(lockout); err == nil && val > 0 {
+			config.AuthLockout = time.Duration(val) * time.Second
+		}
+	}
+
+	// LLM Provider configuration
+	if provider := source.get("LLM_PROVIDER"); provider != "" {
+		config.LLMProvider = strings.ToLower(provider)
+	}
+
 	// Ollama configuration
-	if endpoint := os.Getenv("OLLAMA_ENDPOINT"); endpoint != "" {
+	if endpoint := source.get("OLLAMA_ENDPOINT"); endpoint != "" {
 		config.OllamaEndpoint = endpoint
 	}
 
-	if model := os.Getenv("OLLAMA_MODEL"); model != "" {
+	if model := source.get("OLLAMA_MODEL"); model != "" {
 		config.OllamaModel = model
 	}
 
+	if autoPull := source.get("OLLAMA_AUTO_PULL"); autoPull != "" {
+		config.OllamaAutoPull = strings.ToLower(autoPull) == "true" || autoPull == "1"
+	}
+
+	// LLM readiness probe parameters with validation
+	if retries := source.get("LLM_PROBE_RETRIES"); retries != "" {
+		if val, err := strconv.Atoi(retries); err == nil && val >= 0 {
+			config.LLMProbeRetries = val
+		}
+	}
+
+	if backoff := source.get("LLM_PROBE_BACKOFF_MS"); backoff != "" {
+		if val, err := strconv.Atoi(backoff); err == nil && val > 0 {
+			config.LLMProbeBackoff = time.Duration(val) * time.Millisecond
+		}
+	}
+
 	// Gemini configuration
-	if apiKey := os.Getenv("GEMINI_API_KEY"); apiKey != "" {
+	if apiKey := source.get("GEMINI_API_KEY"); apiKey != "" {
 		config.GeminiAPIKey = apiKey
 	}
 
-	if model := os.Getenv("GEMINI_MODEL"); model != "" {
+	if model := source.get("GEMINI_MODEL"); model != "" {
 		config.GeminiModel = model
 	}
 
 	// Agent execution parameters with validation
-	if maxIter := os.Getenv("MAX_ITERATIONS"); maxIter != "" {
+	if maxIter := source.get("MAX_ITERATIONS"); maxIter != "" {
 		if val, err := strconv.Atoi(maxIter); err == nil && val > 0 {
 			config.MaxIterations = val
 		}
 	}
 
-	if timeout := os.Getenv("REQUEST_TIMEOUT"); timeout != "" {
+	if timeout := source.get("REQUEST_TIMEOUT"); timeout != "" {
 		if val, err := strconv.Atoi(timeout); err == nil && val > 0 {
 			config.RequestTimeout = time.Duration(val) * time.Second
 		}
 	}
 
-	if contextLimit := os.Getenv("CONTEXT_LIMIT"); contextLimit != "" {
+	if contextLimit := source.get("CONTEXT_LIMIT"); contextLimit != "" {
 		if val, err := strconv.Atoi(contextLimit); err == nil && val > 0 {
 			config.ContextLimit = val
 		}
 	}
 
+	if agentMode := source.get("AGENT_MODE"); agentMode != "" {
+		switch mode := strings.ToLower(agentMode); mode {
+		case AgentModeReAct, AgentModeFunctions:
+			config.AgentMode = mode
+		}
+	}
+
+	if heartbeat := source.get("STREAM_HEARTBEAT"); heartbeat != "" {
+		if val, err := strconv.Atoi(heartbeat); err == nil && val >= 0 {
+			config.StreamHeartbeat = time.Duration(val) * time.Second
+		}
+	}
+
+	if grace := source.get("DISCONNECT_GRACE"); grace != "" {
+		if val, err := strconv.Atoi(grace); err == nil {
+			config.DisconnectGrace = time.Duration(val) * time.Second
+		}
+	}
+
+	if parallelism := source.get("TOOL_PARALLELISM"); parallelism != "" {
+		if val, err := strconv.Atoi(parallelism); err == nil && val > 0 {
+			config.ToolParallelism = val
+		}
+	}
+
+	// Tool timeout parameters with validation
+	if toolTimeout := source.get("TOOL_TIMEOUT"); toolTimeout != "" {
+		if val, err := strconv.Atoi(toolTimeout); err == nil && val > 0 {
+			config.ToolTimeout = time.Duration(val) * time.Second
+		}
+	}
+
+	if toolTimeouts := source.get("TOOL_TIMEOUTS"); toolTimeouts != "" {
+		for name, seconds := range parseKeyValueList(toolTimeouts) {
+			if val, err := strconv.Atoi(seconds); err == nil && val > 0 {
+				config.ToolTimeouts[name] = time.Duration(val) * time.Second
+			}
+		}
+	}
+
+	// Tool retry parameters with validation
+	if toolRetries := source.get("TOOL_RETRIES"); toolRetries != "" {
+		for name, retries := range parseKeyValueList(toolRetries) {
+			if val, err := strconv.Atoi(retries); err == nil && val >= 0 {
+				config.ToolRetries[name] = val
+			}
+		}
+	}
+
+	if backoff := source.get("TOOL_RETRY_BACKOFF_MS"); backoff != "" {
+		if val, err := strconv.Atoi(backoff); err == nil && val > 0 {
+			config.ToolRetryBackoff = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	// Tool output parameters with validation
+	if maxOutput := source.get("TOOL_MAX_OUTPUT"); maxOutput != "" {
+		if val, err := strconv.Atoi(maxOutput); err == nil && val > 0 {
+			config.ToolMaxOutput = val
+		}
+	}
+
+	if maxOutputs := source.get("TOOL_MAX_OUTPUTS"); maxOutputs != "" {
+		for name, size := range parseKeyValueList(maxOutputs) {
+			if val, err := strconv.Atoi(size); err == nil && val > 0 {
+				config.ToolMaxOutputs[name] = val
+			}
+		}
+	}
+
+	if retention := source.get("TOOL_OUTPUT_RETENTION_MINUTES"); retention != "" {
+		if val, err := strconv.Atoi(retention); err == nil && val > 0 {
+			config.ToolOutputRetention = time.Duration(val) * time.Minute
+		}
+	}
+
+	// Tool result caching parameters with validation
+	if cacheTTL := source.get("TOOL_CACHE_TTL"); cacheTTL != "" {
+		if val, err := strconv.Atoi(cacheTTL); err == nil && val >= 0 {
+			config.ToolCacheTTL = time.Duration(val) * time.Second
+		}
+	}
+
+	if cacheTools := source.get("TOOL_CACHE_TOOLS"); cacheTools != "" {
+		config.CacheableTools = make([]string, 0)
+		for _, name := range strings.Split(cacheTools, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				config.CacheableTools = append(config.CacheableTools, name)
+			}
+		}
+	}
+
+	// Tool resource limit configuration
+	if toolLimitsFile := source.get("TOOL_LIMITS_FILE"); toolLimitsFile != "" {
+		config.ToolLimitsFile = toolLimitsFile
+	}
+
+	if cgroupPath := source.get("TOOL_CGROUP_PATH"); cgroupPath != "" {
+		config.ToolCgroupPath = cgroupPath
+	}
+
+	// Tool scheduling priority configuration
+	if nice := source.get("TOOL_NICE"); nice != "" {
+		if val, err := strconv.Atoi(nice); err == nil && val >= 0 && val <= 19 {
+			config.ToolNice = val
+		}
+	}
+
+	if ioClass := source.get("TOOL_IONICE_CLASS"); ioClass != "" {
+		config.ToolIOClass = strings.ToLower(strings.TrimSpace(ioClass))
+	}
+
+	if ioLevel := source.get("TOOL_IONICE_LEVEL"); ioLevel != "" {
+		if val, err := strconv.Atoi(ioLevel); err == nil && val >= 0 && val <= 7 {
+			config.ToolIOLevel = val
+		}
+	}
+
+	// Process control configuration
+	if protected := source.get("PROC_PROTECTED"); protected != "" {
+		config.ProcProtected = make([]string, 0)
+		for _, name := range strings.Split(protected, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				config.ProcProtected = append(config.ProcProtected, name)
+			}
+		}
+	}
+
+	// Kernel parameter configuration
+	if sysctlWrite := source.get("SYSCTL_WRITE_ENABLED"); sysctlWrite != "" {
+		config.SysctlWriteEnabled = strings.ToLower(sysctlWrite) == "true" || sysctlWrite == "1"
+	}
+
+	// Custom tool configuration
+	if toolsFile := source.get("TOOLS_FILE"); toolsFile != "" {
+		config.ToolsFilePath = toolsFile
+	}
+
+	if pluginDir := source.get("PLUGIN_DIR"); pluginDir != "" {
+		config.PluginDir = pluginDir
+	}
+
+	if mcpConfig := source.get("MCP_CONFIG_PATH"); mcpConfig != "" {
+		config.MCPConfigPath = mcpConfig
+	}
+
+	if customToolsPath, ok := os.LookupEnv("CUSTOM_TOOLS_PATH"); ok {
+		config.CustomToolsPath = customToolsPath
+	}
+
+	// Kubernetes tool configuration
+	if kubeconfig := source.get("KUBECTL_KUBECONFIG"); kubeconfig != "" {
+		config.KubectlKubeconfig = kubeconfig
+	}
+
+	if namespace := source.get("KUBECTL_NAMESPACE"); namespace != "" {
+		config.KubectlNamespace = namespace
+	}
+
+	if kubectlReadOnly := source.get("KUBECTL_READ_ONLY"); kubectlReadOnly != "" {
+		config.KubectlReadOnly = strings.ToLower(kubectlReadOnly) == "true" || kubectlReadOnly == "1"
+	}
+
+	if clusterMode := source.get("CLUSTER_MODE"); clusterMode != "" {
+		config.ClusterMode = strings.ToLower(clusterMode)
+	}
+
+	// Ansible tool configuration
+	if inventory := source.get("ANSIBLE_INVENTORY"); inventory != "" {
+		config.AnsibleInventory = inventory
+	}
+
+	// AWS tool configuration
+	if awsProfile := source.get("AWS_TOOL_PROFILE"); awsProfile != "" {
+		config.AwsProfile = awsProfile
+	}
+
+	if awsRegion := source.get("AWS_TOOL_REGION"); awsRegion != "" {
+		config.AwsRegion = awsRegion
+	}
+
+	if awsReadOnly := source.get("AWS_TOOL_READ_ONLY"); awsReadOnly != "" {
+		config.AwsReadOnly = strings.ToLower(awsReadOnly) == "true" || awsReadOnly == "1"
+	}
+
+	// Network tool configuration
+	if networkTool := source.get("NETWORK_TOOL_ENABLED"); networkTool != "" {
+		config.NetworkToolEnabled = strings.ToLower(networkTool) == "true" || networkTool == "1"
+	}
+
+	// Tool availability configuration
+	if disabledTools := source.get("TOOLS_DISABLED"); disabledTools != "" {
+		config.DisabledTools = make([]string, 0)
+		for _, name := range strings.Split(disabledTools, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				config.DisabledTools = append(config.DisabledTools, name)
+			}
+		}
+	}
+
+	// Direct tool invocation configuration
+	if toolInvoke := source.get("TOOL_INVOKE_ENABLED"); toolInvoke != "" {
+		config.ToolInvokeEnabled = strings.ToLower(toolInvoke) == "true" || toolInvoke == "1"
+	}
+
 	// Session management parameters with validation
-	if sessionMaxAge := os.Getenv("SESSION_MAX_AGE_HOURS"); sessionMaxAge != "" {
+	if sessionMaxAge := source.get("SESSION_MAX_AGE_HOURS"); sessionMaxAge != "" {
 		if val, err := strconv.Atoi(sessionMaxAge); err == nil && val > 0 {
 			config.SessionMaxAge = time.Duration(val) * time.Hour
 		}
 	}
 
-	if cleanupInterval := os.Getenv("CLEANUP_INTERVAL_MINUTES"); cleanupInterval != "" {
+	if cleanupInterval := source.get("CLEANUP_INTERVAL_MINUTES"); cleanupInterval != "" {
 		if val, err := strconv.Atoi(cleanupInterval); err == nil && val > 0 {
 			config.CleanupInterval = time.Duration(val) * time.Minute
 		}
 	}
 
-	if maxSessions := os.Getenv("MAX_SESSIONS_PER_USER"); maxSessions != "" {
+	if maxSessions := source.get("MAX_SESSIONS_PER_USER"); maxSessions != "" {
 		if val, err := strconv.Atoi(maxSessions); err == nil && val > 0 {
 			config.MaxSessionsPerUser = val
 		}
 	}
 
+	if maxCount := source.get("SESSION_MAX_COUNT"); maxCount != "" {
+		if val, err := strconv.Atoi(maxCount); err == nil && val >= 0 {
+			config.SessionMaxCount = val
+		}
+	}
+
+	if maxMessages := source.get("SESSION_MAX_MESSAGES"); maxMessages != "" {
+		if val, err := strconv.Atoi(maxMessages); err == nil && val >= 0 {
+			config.SessionMaxMessages = val
+		}
+	}
+
+	if maxMB := source.get("SESSION_MAX_MB"); maxMB != "" {
+		if val, err := strconv.Atoi(maxMB); err == nil && val >= 0 {
+			config.SessionMaxBytes = int64(val) << 20
+		}
+	}
+
+	if sessionMessages := source.get("SESSION_MESSAGE_LIMIT"); sessionMessages != "" {
+		if val, err := strconv.Atoi(sessionMessages); err == nil && val >= 0 {
+			config.SessionMessages = val
+		}
+	}
+
+	if messageKB := source.get("MESSAGE_MAX_KB"); messageKB != "" {
+		if val, err := strconv.Atoi(messageKB); err == nil && val >= 0 {
+			config.MessageMaxBytes = val << 10
+		}
+	}
+
 	// Logging configuration
-	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
+	if logLevel := source.get("LOG_LEVEL"); logLevel != "" {
 		config.LogLevel = logLevel
 	}
 
-	if truncateLen := os.Getenv("LOG_TRUNCATE_LENGTH"); truncateLen != "" {
+	if truncateLen := source.get("LOG_TRUNCATE_LENGTH"); truncateLen != "" {
 		if val, err := strconv.Atoi(truncateLen); err == nil && val > 0 {
 			config.LogTruncateLength = val
 		}
 	}
 
 	// Debug mode parsing (accepts "true", "1", or case variations)
-	if debug := os.Getenv("DEBUG_MODE"); debug != "" {
+	if format := source.get("LOG_FORMAT"); format != "" {
+		config.LogFormat = strings.ToLower(format)
+	}
+
+	if output := source.get("LOG_OUTPUT"); output != "" {
+		config.LogOutput = output
+	}
+
+	if logFile := source.get("LOG_FILE"); logFile != "" {
+		config.LogFile = logFile
+	}
+
+	if maxSize := source.get("LOG_FILE_MAX_SIZE_MB"); maxSize != "" {
+		if val, err := strconv.Atoi(maxSize); err == nil && val >= 0 {
+			config.LogFileMaxSize = val
+		}
+	}
+
+	if maxAge := source.get("LOG_FILE_MAX_AGE_HOURS"); maxAge != "" {
+		if val, err := strconv.Atoi(maxAge); err == nil && val >= 0 {
+			config.LogFileMaxAge = time.Duration(val) * time.Hour
+		}
+	}
+
+	if maxBackups := source.get("LOG_FILE_MAX_BACKUPS"); maxBackups != "" {
+		if val, err := strconv.Atoi(maxBackups); err == nil && val >= 0 {
+			config.LogFileMaxBackups = val
+		}
+	}
+
+	if syslogAddress := source.get("LOG_SYSLOG_ADDRESS"); syslogAddress != "" {
+		config.LogSyslogAddress = syslogAddress
+	}
+
+	if debug := source.get("DEBUG_MODE"); debug != "" {
 		config.DebugMode = strings.ToLower(debug) == "true" || debug == "1"
 	}
 
 	// Performance tuning
-	if maxConcurrent := os.Getenv("MAX_CONCURRENT_REQUESTS"); maxConcurrent != "" {
+	if maxConcurrent := source.get("MAX_CONCURRENT_REQUESTS"); maxConcurrent != "" {
 		if val, err := strconv.Atoi(maxConcurrent); err == nil && val > 0 {
 			config.MaxConcurrentRequests = val
 		}
 	}
 
-	// Validate provider-specific configuration
-	if config.LLMProvider == "gemini" && config.GeminiAPIKey == "" {
-		// Note: We'll also validate this in the server initialization for better error messages
-		// but this provides early validation during config loading
-		config.LLMProvider = "ollama" // Fallback to ollama if Gemini key is missing
+	if queueSize := source.get("QUEUE_MAX_SIZE"); queueSize != "" {
+		if val, err := strconv.Atoi(queueSize); err == nil && val >= 0 {
+			config.QueueMaxSize = val
+		}
+	}
+
+	// Audit configuration
+	if auditPath := source.get("AUDIT_LOG_PATH"); auditPath != "" {
+		config.AuditLogPath = auditPath
+	}
+
+	if encryptionKey := source.get("ENCRYPTION_KEY"); encryptionKey != "" {
+		config.EncryptionKey = encryptionKey
+	}
+
+	// Redaction configuration
+	if redaction := source.get("REDACTION_ENABLED"); redaction != "" {
+		config.RedactionEnabled = strings.ToLower(redaction) == "true" || redaction == "1"
+	}
+
+	if patterns := source.get("REDACTION_PATTERNS"); patterns != "" {
+		for _, pattern := range strings.Split(patterns, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				config.RedactionPatterns = append(config.RedactionPatterns, pattern)
+			}
+		}
+	}
+
+	// Conversation analytics configuration
+	if analytics := source.get("ANALYTICS_ENABLED"); analytics != "" {
+		config.AnalyticsEnabled = strings.ToLower(analytics) == "true" || analytics == "1"
+	}
+
+	if analyticsPath := source.get("ANALYTICS_LOG_PATH"); analyticsPath != "" {
+		config.AnalyticsLogPath = analyticsPath
+	}
+
+	// Self-update configuration
+	if selfUpdate := source.get("SELF_UPDATE_ENABLED"); selfUpdate != "" {
+		config.SelfUpdateEnabled = strings.ToLower(selfUpdate) == "true" || selfUpdate == "1"
+	}
+
+	if updateURL := source.get("UPDATE_URL"); updateURL != "" {
+		config.UpdateURL = updateURL
+	}
+
+	if publicKey := source.get("UPDATE_PUBLIC_KEY"); publicKey != "" {
+		config.UpdatePublicKey = publicKey
+	}
+
+	// Admin API configuration
+	if adminToken := source.get("ADMIN_TOKEN"); adminToken != "" {
+		config.AdminToken = adminToken
+	}
+
+	if apiKeysPath, ok := os.LookupEnv("API_KEYS_PATH"); ok {
+		config.APIKeysPath = apiKeysPath
+	}
+
+	if tenantsFile := source.get("TENANTS_FILE"); tenantsFile != "" {
+		config.TenantsFile = tenantsFile
+	}
+
+	if anonymousAccess := source.get("ANONYMOUS_ACCESS"); anonymousAccess != "" {
+		config.AnonymousAccess = strings.ToLower(anonymousAccess)
+	}
+
+	if quotaUsagePath, ok := os.LookupEnv("QUOTA_USAGE_PATH"); ok {
+		config.QuotaUsagePath = quotaUsagePath
+	}
+
+	// Tracing configuration
+	if endpoint := source.get("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		config.OTelEndpoint = endpoint
+	}
+
+	if headers := source.get("OTEL_EXPORTER_OTLP_HEADERS"); headers != "" {
+		config.OTelHeaders = make(map[string]string)
+		for _, pair := range strings.Split(headers, ",") {
+			if name, value, ok := strings.Cut(pair, "="); ok && strings.TrimSpace(name) != "" {
+				config.OTelHeaders[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	if serviceName := source.get("OTEL_SERVICE_NAME"); serviceName != "" {
+		config.OTelServiceName = serviceName
+	}
+
+	// Error reporting configuration
+	if dsn := source.get("SENTRY_DSN"); dsn != "" {
+		config.SentryDSN = dsn
+	}
+
+	if environment := source.get("SENTRY_ENVIRONMENT"); environment != "" {
+		config.SentryEnvironment = environment
+	}
+
+	// Alertmanager integration configuration
+	if token := source.get("ALERTMANAGER_TOKEN"); token != "" {
+		config.AlertmanagerToken = token
+	}
+
+	if prompt := source.get("ALERTMANAGER_PROMPT"); prompt != "" {
+		config.AlertmanagerPrompt = prompt
+	}
+
+	if notifyURL := source.get("ALERTMANAGER_NOTIFY_URL"); notifyURL != "" {
+		config.AlertmanagerNotifyURL = notifyURL
+	}
+
+	// Slack bot configuration
+	if botToken := source.get("SLACK_BOT_TOKEN"); botToken != "" {
+		config.SlackBotToken = botToken
+	}
+
+	if signingSecret := source.get("SLACK_SIGNING_SECRET"); signingSecret != "" {
+		config.SlackSigningSecret = signingSecret
+	}
+
+	if channels := source.get("SLACK_CHANNELS"); channels != "" {
+		config.SlackChannels = make([]string, 0)
+		for _, channel := range strings.Split(channels, ",") {
+			if channel = strings.TrimSpace(channel); channel != "" {
+				config.SlackChannels = append(config.SlackChannels, channel)
+			}
+		}
+	}
+
+	if allowDMs := source.get("SLACK_ALLOW_DMS"); allowDMs != "" {
+		config.SlackAllowDMs = strings.ToLower(allowDMs) == "true" || allowDMs == "1"
+	}
+
+	// Matrix bot configuration
+	if homeserverURL := source.get("MATRIX_HOMESERVER_URL"); homeserverURL != "" {
+		config.MatrixHomeserverURL = homeserverURL
+	}
+
+	if accessToken := source.get("MATRIX_ACCESS_TOKEN"); accessToken != "" {
+		config.MatrixAccessToken = accessToken
+	}
+
+	if rooms := source.get("MATRIX_ROOMS"); rooms != "" {
+		config.MatrixRooms = make([]string, 0)
+		for _, room := range strings.Split(rooms, ",") {
+			if room = strings.TrimSpace(room); room != "" {
+				config.MatrixRooms = append(config.MatrixRooms, room)
+			}
+		}
+	}
+
+	// MQTT integration configuration
+	if brokerURL := source.get("MQTT_BROKER_URL"); brokerURL != "" {
+		config.MQTTBrokerURL = brokerURL
+	}
+
+	if clientID := source.get("MQTT_CLIENT_ID"); clientID != "" {
+		config.MQTTClientID = clientID
+	}
+
+	if username := source.get("MQTT_USERNAME"); username != "" {
+		config.MQTTUsername = username
+	}
+
+	if password := source.get("MQTT_PASSWORD"); password != "" {
+		config.MQTTPassword = password
+	}
+
+	if commandTopic := source.get("MQTT_COMMAND_TOPIC"); commandTopic != "" {
+		config.MQTTCommandTopic = commandTopic
+	}
+
+	if resultTopic := source.get("MQTT_RESULT_TOPIC"); resultTopic != "" {
+		config.MQTTResultTopic = resultTopic
+	}
+
+	// Execution targets
+	if targets := source.get("TARGETS"); targets != "" {
+		config.Targets = make([]string, 0)
+		for _, target := range strings.Split(targets, ",") {
+			if target = strings.TrimSpace(target); target != "" {
+				config.Targets = append(config.Targets, target)
+			}
+		}
+	}
+
+	// SSH chat server configuration
+	if sshAddr := source.get("SSH_ADDR"); sshAddr != "" {
+		config.SSHAddr = sshAddr
+	}
+
+	if hostKeyFile := source.get("SSH_HOST_KEY_FILE"); hostKeyFile != "" {
+		config.SSHHostKeyFile = hostKeyFile
+	}
+
+	if authorizedKeys := source.get("SSH_AUTHORIZED_KEYS"); authorizedKeys != "" {
+		config.SSHAuthorizedKeys = authorizedKeys
+	}
+
+	// Audio transcription configuration
+	if backend := source.get("TRANSCRIPTION_BACKEND"); backend != "" {
+		config.TranscriptionBackend = strings.ToLower(backend)
+	}
+
+	if whisperBinary := source.get("WHISPER_BINARY"); whisperBinary != "" {
+		config.WhisperBinary = whisperBinary
+	}
+
+	if whisperModel := source.get("WHISPER_MODEL"); whisperModel != "" {
+		config.WhisperModel = whisperModel
+	}
+
+	// Speech output configuration
+	if ttsBackend := source.get("TTS_BACKEND"); ttsBackend != "" {
+		config.TTSBackend = strings.ToLower(ttsBackend)
+	}
+
+	if ttsBinary := source.get("TTS_BINARY"); ttsBinary != "" {
+		config.TTSBinary = ttsBinary
+	}
+
+	if ttsVoice := source.get("TTS_VOICE"); ttsVoice != "" {
+		config.TTSVoice = ttsVoice
+	}
+
+	// GitHub integration configuration
+	if webhookSecret := source.get("GITHUB_WEBHOOK_SECRET"); webhookSecret != "" {
+		config.GitHubWebhookSecret = webhookSecret
+	}
+
+	if githubToken := source.get("GITHUB_TOKEN"); githubToken != "" {
+		config.GitHubToken = githubToken
+	}
+
+	if repos := source.get("GITHUB_REPOS"); repos != "" {
+		config.GitHubRepos = make([]string, 0)
+		for _, repo := range strings.Split(repos, ",") {
+			if repo = strings.TrimSpace(repo); repo != "" {
+				config.GitHubRepos = append(config.GitHubRepos, repo)
+			}
+		}
+	}
+
+	if apiURL := source.get("GITHUB_API_URL"); apiURL != "" {
+		config.GitHubAPIURL = apiURL
+	}
+
+	// Background task configuration
+	if tasksPath, ok := os.LookupEnv("TASKS_PATH"); ok {
+		config.TasksPath = tasksPath
+	}
+
+	if maxSteps := source.get("TASK_MAX_STEPS"); maxSteps != "" {
+		if val, err := strconv.Atoi(maxSteps); err == nil && val > 0 {
+			config.TaskMaxSteps = val
+		}
+	}
+
+	// Workflow configuration
+	if workflowsFile := source.get("WORKFLOWS_FILE"); workflowsFile != "" {
+		config.WorkflowsFile = workflowsFile
+	}
+
+	// Inbound webhook configuration
+	if hooksFile := source.get("HOOKS_FILE"); hooksFile != "" {
+		config.HooksFile = hooksFile
+	}
+
+	// Notification configuration
+	if notifiersFile := source.get("NOTIFIERS_FILE"); notifiersFile != "" {
+		config.NotifiersFile = notifiersFile
+	}
+
+	if smtpHost := source.get("SMTP_HOST"); smtpHost != "" {
+		config.SMTPHost = smtpHost
+	}
+
+	if smtpPort := source.get("SMTP_PORT"); smtpPort != "" {
+		if val, err := strconv.Atoi(smtpPort); err == nil && val > 0 {
+			config.SMTPPort = val
+		}
+	}
+
+	if username := source.get("SMTP_USERNAME"); username != "" {
+		config.SMTPUsername = username
+	}
+
+	if password := source.get("SMTP_PASSWORD"); password != "" {
+		config.SMTPPassword = password
+	}
+
+	if from := source.get("EMAIL_FROM"); from != "" {
+		config.EmailFrom = from
+	}
+
+	if recipients := source.get("EMAIL_RECIPIENTS"); recipients != "" {
+		config.EmailRecipients = make([]string, 0)
+		for _, recipient := range strings.Split(recipients, ",") {
+			if recipient = strings.TrimSpace(recipient); recipient != "" {
+				config.EmailRecipients = append(config.EmailRecipients, recipient)
+			}
+		}
+	}
+
+	if notifyOn := source.get("EMAIL_NOTIFY_ON"); notifyOn != "" {
+		config.EmailNotifyOn = make([]string, 0)
+		for _, kind := range strings.Split(notifyOn, ",") {
+			if kind = strings.ToLower(strings.TrimSpace(kind)); kind != "" {
+				config.EmailNotifyOn = append(config.EmailNotifyOn, kind)
+			}
+		}
+	}
+
+	if subject := source.get("EMAIL_SUBJECT"); subject != "" {
+		config.EmailSubject = subject
+	}
+
+	// Without an explicit provider, use Gemini only when a key is configured. A
+	// provider selected explicitly is never replaced; Validate reports a missing key.
+	if source.get("LLM_PROVIDER") == "" && config.GeminiAPIKey == "" {
+		config.LLMProvider = "ollama"
+	}
+
+	// Fail fast on unresolvable secrets and missing or contradictory settings
+	if err := source.err(); err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	return config
+	return config, nil
+}
+
+// TimeoutForTool returns the execution timeout for the named tool,
+// falling back to the global tool timeout when no override is configured.
+//
+// Parameters:
+//   - name: Tool name as reported by the tool's Name method
+//
+// Returns:
+//   - time.Duration: Timeout to apply to the tool call
+func (c *Config) TimeoutForTool(name string) time.Duration {
+	if timeout, ok := c.ToolTimeouts[name]; ok {
+		return timeout
+	}
+	return c.ToolTimeout
+}
+
+// MaxOutputForTool returns the output size limit in bytes for the named tool,
+// falling back to the global limit when no override is configured.
+//
+// Parameters:
+//   - name: Tool name as reported by the tool's Name method
+//
+// Returns:
+//   - int: Maximum output size returned to the agent
+func (c *Config) MaxOutputForTool(name string) int {
+	if size, ok := c.ToolMaxOutputs[name]; ok {
+		return size
+	}
+	return c.ToolMaxOutput
+}
+
+// parseKeyValueList parses a comma-separated list of key=value pairs such as
+// "docker=30,apk=120". Keys are trimmed and lower-cased; malformed entries are skipped.
+//
+// Parameters:
+//   - value: Raw comma-separated list
+//
+// Returns:
+//   - map[string]string: Parsed key/value pairs
+func parseKeyValueList(value string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(pair, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !found || key == "" {
+			continue
+		}
+		result[key] = strings.TrimSpace(val)
+	}
+	return result
 }
 
 // InitializeLogger configures and returns a structured logger based on the provided configuration.
-// The logger uses JSON formatting for structured logging, which is ideal for production
+// By default the logger writes JSON to stdout, which is ideal for production
 // environments, log aggregation, and automated log processing.
 //
 // Features:
-// - JSON formatted output for structured logging
+// - JSON or human-readable text output (LOG_FORMAT)
 // - Configurable log levels (debug, info, warn, error)
 // - RFC3339 timestamp format for precise timing
-// - Output to stdout for container-friendly logging
+// - Output to stdout, stderr, rotating files, and syslog (LOG_OUTPUT)
 // - Configuration value logging for operational visibility
 //
 // Parameters:
@@ -241,47 +1470,149 @@ func InitializeLogger(config *Config) *logrus.Logger {
 	// Create new logger instance
 	logger := logrus.New()
 
-	// Configure JSON formatter for structured logging
-	logger.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: time.RFC3339, // Use RFC3339 for ISO 8601 compatibility
-	})
-
 	// Set log level based on configuration with case-insensitive matching
-	switch strings.ToLower(config.LogLevel) {
-	case "debug":
-		logger.SetLevel(logrus.DebugLevel)
-	case "info":
-		logger.SetLevel(logrus.InfoLevel)
-	case "warn", "warning":
-		logger.SetLevel(logrus.WarnLevel)
-	case "error":
-		logger.SetLevel(logrus.ErrorLevel)
-	default:
-		// Default to info level if unrecognized level is specified
-		logger.SetLevel(logrus.InfoLevel)
-	}
+	logger.SetLevel(parseLogLevel(config.LogLevel))
+
+	// Tag entries logged with a request context with the request ID
+	installRequestIDHook(logger)
 
-	// Set output to stdout for container/cloud environments
-	// This allows log aggregation systems to capture logs properly
-	logger.SetOutput(os.Stdout)
+	// Mask secrets before any output hook sees the entries
+	installRedactionHook(logger, configRedactor(config))
+
+	// Install the configured format and sinks; unavailable sinks are reported
+	// but do not prevent startup
+	if err := configureLogOutput(logger, config); err != nil {
+		logger.WithError(err).Error("Some log outputs are unavailable")
+	}
 
 	// Log the loaded configuration for operational visibility
 	// This helps with debugging configuration issues in production
 	logger.WithFields(logrus.Fields{
+		"configFile":            config.ConfigFile,
+		"httpCompression":       config.HTTPCompression,
+		"httpReadTimeout":       config.HTTPReadTimeout,
+		"httpWriteTimeout":      config.HTTPWriteTimeout,
+		"httpIdleTimeout":       config.HTTPIdleTimeout,
+		"httpKeepAlive":         config.HTTPKeepAlive,
+		"allowedCIDRs":          config.AllowedCIDRs,
+		"authMaxFailures":       config.AuthMaxFailures,
+		"authLockout":           config.AuthLockout,
 		"llmProvider":           config.LLMProvider,
 		"ollamaEndpoint":        config.OllamaEndpoint,
 		"ollamaModel":           config.OllamaModel,
+		"ollamaAutoPull":        config.OllamaAutoPull,
+		"llmProbeRetries":       config.LLMProbeRetries,
+		"llmProbeBackoff":       config.LLMProbeBackoff,
 		"geminiModel":           config.GeminiModel,
 		"maxIterations":         config.MaxIterations,
 		"requestTimeout":        config.RequestTimeout,
 		"contextLimit":          config.ContextLimit,
+		"agentMode":             config.AgentMode,
+		"streamHeartbeat":       config.StreamHeartbeat,
+		"disconnectGrace":       config.DisconnectGrace,
+		"toolParallelism":       config.ToolParallelism,
+		"toolTimeout":           config.ToolTimeout,
+		"toolTimeouts":          config.ToolTimeouts,
+		"toolRetries":           config.ToolRetries,
+		"toolRetryBackoff":      config.ToolRetryBackoff,
+		"toolMaxOutput":         config.ToolMaxOutput,
+		"toolMaxOutputs":        config.ToolMaxOutputs,
+		"toolCacheTTL":          config.ToolCacheTTL,
+		"cacheableTools":        config.CacheableTools,
+		"toolLimitsFile":        config.ToolLimitsFile,
+		"toolCgroupPath":        config.ToolCgroupPath,
+		"toolNice":              config.ToolNice,
+		"toolIOClass":           config.ToolIOClass,
+		"toolIOLevel":           config.ToolIOLevel,
+		"procProtected":         config.ProcProtected,
+		"sysctlWriteEnabled":    config.SysctlWriteEnabled,
+		"toolsFilePath":         config.ToolsFilePath,
+		"pluginDir":             config.PluginDir,
+		"mcpConfigPath":         config.MCPConfigPath,
+		"customToolsPath":       config.CustomToolsPath,
+		"kubectlKubeconfig":     config.KubectlKubeconfig,
+		"kubectlNamespace":      config.KubectlNamespace,
+		"kubectlReadOnly":       config.KubectlReadOnly,
+		"clusterMode":           config.ClusterMode,
+		"ansibleInventory":      config.AnsibleInventory,
+		"awsProfile":            config.AwsProfile,
+		"awsRegion":             config.AwsRegion,
+		"awsReadOnly":           config.AwsReadOnly,
+		"networkToolEnabled":    config.NetworkToolEnabled,
+		"disabledTools":         config.DisabledTools,
+		"toolInvokeEnabled":     config.ToolInvokeEnabled,
 		"sessionMaxAge":         config.SessionMaxAge,
 		"cleanupInterval":       config.CleanupInterval,
 		"maxSessionsPerUser":    config.MaxSessionsPerUser,
+		"sessionMaxCount":       config.SessionMaxCount,
+		"sessionMaxMessages":    config.SessionMaxMessages,
+		"sessionMaxBytes":       config.SessionMaxBytes,
+		"sessionMessages":       config.SessionMessages,
+		"messageMaxBytes":       config.MessageMaxBytes,
 		"logTruncateLength":     config.LogTruncateLength,
+		"logFormat":             config.LogFormat,
+		"logOutput":             config.LogOutput,
+		"logFile":               config.LogFile,
 		"debugMode":             config.DebugMode,
 		"maxConcurrentRequests": config.MaxConcurrentRequests,
+		"queueMaxSize":          config.QueueMaxSize,
+		"auditLogPath":          config.AuditLogPath,
+		"encryptionEnabled":     config.EncryptionKey != "",
+		"redactionEnabled":      config.RedactionEnabled,
+		"redactionPatterns":     len(config.RedactionPatterns),
+		"analyticsEnabled":      config.AnalyticsEnabled,
+		"selfUpdateEnabled":     config.SelfUpdateEnabled,
+		"updateURL":             config.UpdateURL,
+		"adminEnabled":          config.AdminToken != "",
+		"apiKeysPath":           config.APIKeysPath,
+		"tenantsFile":           config.TenantsFile,
+		"anonymousAccess":       config.AnonymousAccess,
+		"quotaUsagePath":        config.QuotaUsagePath,
+		"otelEndpoint":          config.OTelEndpoint,
+		"otelServiceName":       config.OTelServiceName,
+		"sentryEnabled":         config.SentryDSN != "",
+		"sentryEnvironment":     config.SentryEnvironment,
+		"alertmanagerEnabled":   config.AlertmanagerToken != "",
+		"alertmanagerNotifyURL": config.AlertmanagerNotifyURL,
+		"slackEnabled":          config.SlackBotToken != "",
+		"slackChannels":         config.SlackChannels,
+		"slackAllowDMs":         config.SlackAllowDMs,
+		"matrixEnabled":         config.MatrixHomeserverURL != "" && config.MatrixAccessToken != "",
+		"matrixHomeserverURL":   config.MatrixHomeserverURL,
+		"matrixRooms":           config.MatrixRooms,
+		"mqttBrokerURL":         config.MQTTBrokerURL,
+		"mqttCommandTopic":      config.MQTTCommandTopic,
+		"mqttResultTopic":       config.MQTTResultTopic,
+		"targets":               config.Targets,
+		"sshAddr":               config.SSHAddr,
+		"transcriptionBackend":  config.TranscriptionBackend,
+		"ttsBackend":            config.TTSBackend,
+		"githubEnabled":         config.GitHubWebhookSecret != "" && config.GitHubToken != "",
+		"githubRepos":           config.GitHubRepos,
+		"tasksPath":             config.TasksPath,
+		"taskMaxSteps":          config.TaskMaxSteps,
+		"workflowsFile":         config.WorkflowsFile,
+		"hooksFile":             config.HooksFile,
+		"notifiersFile":         config.NotifiersFile,
+		"emailEnabled":          config.SMTPHost != "" && len(config.EmailRecipients) > 0,
+		"emailRecipients":       config.EmailRecipients,
+		"emailNotifyOn":         config.EmailNotifyOn,
 	}).Info("Configuration loaded")
 
 	return logger
 }
+
+// parseLogLevel maps a configured log level name to a logrus level, case-insensitively.
+// Unrecognized names default to the info level.
+func parseLogLevel(level string) logrus.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return logrus.DebugLevel
+	case "warn", "warning":
+		return logrus.WarnLevel
+	case "error":
+		return logrus.ErrorLevel
+	default:
+		return logrus.InfoLevel
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Package core provides configuration file loading for the Skynet Agent application.
+
+Every setting can also be given in a YAML or TOML file, selected with --config or
+SKYNET_CONFIG, or found as skynet.yaml, skynet.yml, or skynet.toml in the working
+directory. File keys are the environment variable names, matched case-insensitively,
+so every existing and future setting is supported without a second schema:
+
+	ollama_model: qwen3
+	request_timeout: 600
+	tool_cache_tools: [sysinfo, ls, stat]
+	tool_timeouts:
+	  docker: 30
+	  ansible: 900
+	gemini:
+	  api_key: "..."
+
+Nested keys are joined with underscores (gemini.api_key sets GEMINI_API_KEY), lists
+become comma-separated values, and maps of scalars become key=value lists. Environment
+variables take precedence over the file.
+*/
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileEnv names the environment variable selecting the configuration file
+const configFileEnv = "SKYNET_CONFIG"
+
+// defaultConfigFiles are looked up in the working directory when no file is selected
+var defaultConfigFiles = []string{"skynet.yaml", "skynet.yml", "skynet.toml"}
+
+// configSource resolves settings from the environment, then from the configuration file.
+type configSource struct {
+	path    string                       // Configuration file that was loaded; empty when none
+	values  map[string]string            // File settings keyed by environment variable name
+	secrets map[string]map[string]string // Vault secrets already fetched, keyed by path
+	errs    map[string]error             // Secrets that could not be resolved, keyed by setting name
+}
+
+// newConfigSource loads the configuration file at path, or the one selected by
+// SKYNET_CONFIG, or the first default file that exists. A selected file must exist;
+// missing default files are ignored.
+//
+// Parameters:
+//   - path: Configuration file path; empty selects it from SKYNET_CONFIG or the defaults
+//
+// Returns:
+//   - *configSource: Source resolving settings with environment precedence
+//   - error: Read or parse error of the configuration file
+func newConfigSource(path string) (*configSource, error) {
+	source := &configSource{
+		values:  make(map[string]string),
+		secrets: make(map[string]map[string]string),
+		errs:    make(map[string]error),
+	}
+
+	if path == "" {
+		path = os.Getenv(configFileEnv)
+	}
+	if path == "" {
+		for _, candidate := range defaultConfigFiles {
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+	}
+	if path == "" {
+		return source, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	document, err := parseConfigDocument(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	// Files encrypted with SOPS are decrypted with the sops binary
+	if _, encrypted := document["sops"]; encrypted {
+		if document, err = decryptSOPS(path); err != nil {
+			return nil, err
+		}
+	}
+
+	flattenConfig("", document, source.values)
+	source.path = path
+	return source, nil
+}
+
+// parseConfigDocument parses a configuration file as TOML for the .toml extension and as YAML otherwise.
+func parseConfigDocument(path string, data []byte) (map[string]interface{}, error) {
+	var document map[string]interface{}
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &document)
+	} else {
+		err = yaml.Unmarshal(data, &document)
+	}
+	return document, err
+}
+
+// get returns the named setting from the environment, or from the configuration
+// file when the environment variable is unset or empty. In both places NAME_FILE
+// may name a file holding the value instead, and values of the form
+// "vault:<path>#<field>" are resolved from Vault; see secrets.go.
+func (s *configSource) get(name string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		value = s.fromFile(name, os.Getenv(name+"_FILE"))
+	}
+	if value == "" {
+		value = s.values[name]
+	}
+	if value == "" {
+		value = s.fromFile(name, s.values[name+"_FILE"])
+	}
+	return s.resolve(name, value)
+}
+
+// err returns the errors of all secrets that could not be resolved, joined.
+func (s *configSource) err() error {
+	names := make([]string, 0, len(s.errs))
+	for name := range s.errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, s.errs[name])
+	}
+	return errors.Join(errs...)
+}
+
+// flattenConfig converts a parsed configuration document into settings keyed by
+// environment variable name.
+func flattenConfig(prefix string, document map[string]interface{}, values map[string]string) {
+	for key, value := range document {
+		name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			flattenConfig(name, typed, values)
+			// A map of scalars is also a key=value list setting such as TOOL_TIMEOUTS
+			if pairs, ok := configPairs(typed); ok {
+				values[name] = pairs
+			}
+		case []interface{}:
+			items := make([]string, 0, len(typed))
+			for _, item := range typed {
+				items = append(items, configScalar(item))
+			}
+			values[name] = strings.Join(items, ",")
+		case nil:
+		default:
+			values[name] = configScalar(typed)
+		}
+	}
+}
+
+// configPairs formats a map of scalars as a sorted "key=value,..." list.
+func configPairs(document map[string]interface{}) (string, bool) {
+	pairs := make([]string, 0, len(document))
+	for key, value := range document {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return "", false
+		}
+		pairs = append(pairs, key+"="+configScalar(value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ","), true
+}
+
+// configScalar formats a scalar configuration value the way it would be written in an environment variable.
+func configScalar(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,90 @@
+/*
+Package core provides the redacted view of the effective configuration for the Skynet Agent application.
+
+GET /admin/config returns the configuration a running instance actually uses,
+after defaults, the configuration file, the environment, and any reload were
+applied. Secrets are masked: settings whose names end in APIKey, EncryptionKey, Token,
+Password, Secret, Headers, or DSN are reported only as set or unset, and passwords embedded in URLs are
+replaced with xxxxx. Durations are rendered as Go duration strings such as "5m0s".
+*/
+package core
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// redactedValue replaces secrets that are set
+const redactedValue = "[REDACTED]"
+
+// secretFieldSuffixes mark Config fields holding secrets
+var secretFieldSuffixes = []string{"APIKey", "EncryptionKey", "Token", "Password", "Secret", "Headers", "DSN"}
+
+// RedactedConfig returns the configuration keyed by field name with secrets masked.
+//
+// Parameters:
+//   - config: Configuration to render
+//
+// Returns:
+//   - map[string]interface{}: JSON-ready settings
+func RedactedConfig(config *Config) map[string]interface{} {
+	view := make(map[string]interface{})
+	value := reflect.ValueOf(config).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		view[field.Name] = redactConfigValue(field.Name, value.Field(i).Interface())
+	}
+	return view
+}
+
+// redactConfigValue masks a secret setting and renders durations readably.
+func redactConfigValue(name string, value interface{}) interface{} {
+	for _, suffix := range secretFieldSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			if unset := reflect.ValueOf(value); (unset.Kind() == reflect.String || unset.Kind() == reflect.Map) && unset.Len() == 0 {
+				return value
+			}
+			return redactedValue
+		}
+	}
+
+	switch typed := value.(type) {
+	case time.Duration:
+		return typed.String()
+	case map[string]time.Duration:
+		durations := make(map[string]string, len(typed))
+		for key, duration := range typed {
+			durations[key] = duration.String()
+		}
+		return durations
+	case string:
+		// Hide credentials embedded in endpoint URLs
+		if parsed, err := url.Parse(typed); err == nil && parsed.User != nil {
+			if _, hasPassword := parsed.User.Password(); hasPassword {
+				return parsed.Redacted()
+			}
+		}
+	}
+	return value
+}
+
+// handleAdminConfig handles GET /admin/config requests by returning the effective
+// configuration with secrets masked.
+func (s *Server) handleAdminConfig(c echo.Context) error {
+	s.logger.WithFields(logrus.Fields{
+		"endpoint": "/admin/config",
+		"method":   "GET",
+		"clientIP": c.RealIP(),
+	}).Info("Effective configuration requested")
+
+	return c.JSON(http.StatusOK, RedactedConfig(s.currentConfig()))
+}
@@ -0,0 +1,51 @@
+/*
+Package core provides request-scoped execution metadata for the Skynet Agent application.
+
+This file defines the ExecutionInfo structure that is attached to the context of
+every agent execution. Because the agent framework passes the execution context
+through to each tool call, wrappers around tools can recover which session and
+execution an invocation belongs to without changing the tool interface.
+*/
+package core
+
+import "context"
+
+// executionInfoKey is the unexported context key type for ExecutionInfo values.
+// Using a private type prevents collisions with context keys from other packages.
+type executionInfoKey struct{}
+
+// ExecutionInfo identifies the agent execution a context belongs to.
+// It is attached by the HTTP handlers before the agent runs and read by
+// tool wrappers (such as auditing) during tool invocation.
+type ExecutionInfo struct {
+	SessionID   string // Chat session the execution belongs to
+	ExecutionID string // Unique identifier of the agent execution
+	User        string // Identity of the requesting user (client address until authentication exists)
+	Owner       string // Owner of the execution's session; empty for sessions open to every caller
+	Tenant      string // Tenant the execution is accounted to; empty for callers without a tenant
+}
+
+// WithExecutionInfo returns a copy of the parent context carrying the given execution info.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - info: Execution metadata to attach
+//
+// Returns:
+//   - context.Context: Derived context carrying the execution info
+func WithExecutionInfo(ctx context.Context, info ExecutionInfo) context.Context {
+	return context.WithValue(ctx, executionInfoKey{}, info)
+}
+
+// ExecutionInfoFromContext extracts execution info previously attached with WithExecutionInfo.
+//
+// Parameters:
+//   - ctx: Context to inspect
+//
+// Returns:
+//   - ExecutionInfo: The attached execution info, or the zero value if none
+//   - bool: Whether execution info was present
+func ExecutionInfoFromContext(ctx context.Context) (ExecutionInfo, bool) {
+	info, ok := ctx.Value(executionInfoKey{}).(ExecutionInfo)
+	return info, ok
+}
@@ -0,0 +1,219 @@
+/*
+Package core provides runtime tool registration for the Skynet Agent application.
+
+Operators can add command-backed tools without rebuilding the binary by posting a
+definition to POST /admin/tools:
+
+	{"name": "kubectl", "description": "Run kubectl commands. Usage: '<args>'", "command": "kubectl {{.args}}"}
+
+The template must start with a fixed executable. A definition never replaces a
+built-in tool, and replaces a registered one only when the request sets
+overwrite=true. Definitions are persisted to a JSON file so they survive restarts, and the agent
+executor is rebuilt so new tools immediately appear in the tool list and prompt.
+With ENCRYPTION_KEY set, the command templates, which may embed credentials, are
+encrypted on disk.
+*/
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// toolNamePattern restricts tool names to identifiers the agent can reliably reproduce
+var toolNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// ToolDefinition describes a command-template tool registered at runtime.
+type ToolDefinition struct {
+	Name        string    `json:"name"`        // Tool identifier exposed to the agent
+	Description string    `json:"description"` // Usage description exposed to the agent
+	Command     string    `json:"command"`     // Command template, e.g. "kubectl {{.args}}"
+	CreatedAt   time.Time `json:"createdAt"`   // When the tool was registered
+}
+
+// CustomToolStore holds runtime-registered tool definitions and persists them to disk.
+type CustomToolStore struct {
+	definitions map[string]ToolDefinition // Map of tool name to definition
+	path        string                    // JSON file the definitions are persisted to; empty keeps them in memory
+	cipher      *FieldCipher              // Encrypts command templates on disk; nil stores them in plain text
+	mutex       sync.RWMutex              // Read-write mutex for thread-safe map operations
+	logger      *logrus.Logger            // Structured logger for operational monitoring
+}
+
+// NewCustomToolStore creates a store and loads previously persisted definitions.
+// A missing file is not an error; it is created on the first registration.
+//
+// Parameters:
+//   - path: JSON file used for persistence; empty disables persistence
+//   - cipher: Cipher for command templates, or nil to store them in plain text
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *CustomToolStore: Store ready for use
+//   - error: Any error reading, decoding, or decrypting the persisted definitions
+func NewCustomToolStore(path string, cipher *FieldCipher, logger *logrus.Logger) (*CustomToolStore, error) {
+	store := &CustomToolStore{
+		definitions: make(map[string]ToolDefinition),
+		path:        path,
+		cipher:      cipher,
+		logger:      logger,
+	}
+
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read custom tools file: %w", err)
+	}
+
+	var definitions []ToolDefinition
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return nil, fmt.Errorf("failed to decode custom tools file: %w", err)
+	}
+	for _, definition := range definitions {
+		if definition.Command, err = cipher.Decrypt(definition.Command); err != nil {
+			return nil, fmt.Errorf("failed to decrypt custom tool %s: %w", definition.Name, err)
+		}
+		store.definitions[definition.Name] = definition
+	}
+
+	logger.WithFields(logrus.Fields{
+		"path":  path,
+		"tools": len(store.definitions),
+	}).Info("Custom tools loaded")
+	return store, nil
+}
+
+// Validate checks a definition's name, description, and command template.
+//
+// Parameters:
+//   - definition: Definition to validate
+//
+// Returns:
+//   - error: Description of the first problem found, nil if valid
+func (s *CustomToolStore) Validate(definition ToolDefinition) error {
+	if !toolNamePattern.MatchString(definition.Name) {
+		return fmt.Errorf("tool name must be lowercase letters, digits, '-' or '_' and start with a letter")
+	}
+	if definition.Description == "" {
+		return fmt.Errorf("tool description is required")
+	}
+	if definition.Command == "" {
+		return fmt.Errorf("tool command template is required")
+	}
+	if _, err := localtools.NewTemplateTool(definition.Name, definition.Description, definition.Command, localtools.NewWorkspaceContext("")); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Add registers or replaces a definition and persists the store.
+//
+// Parameters:
+//   - definition: Validated definition to register
+//
+// Returns:
+//   - error: Any error persisting the definitions
+func (s *CustomToolStore) Add(definition ToolDefinition) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, existed := s.definitions[definition.Name]
+	s.definitions[definition.Name] = definition
+
+	if err := s.save(); err != nil {
+		// Roll back so memory and disk stay consistent
+		if existed {
+			s.definitions[definition.Name] = previous
+		} else {
+			delete(s.definitions, definition.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes all definitions to the persistence file. Callers must hold the mutex.
+func (s *CustomToolStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	definitions := s.sortedDefinitions()
+	for i := range definitions {
+		definitions[i].Command = s.cipher.Encrypt(definitions[i].Command)
+	}
+	data, err := json.MarshalIndent(definitions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode custom tools: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial file behind
+	staged := s.path + ".tmp"
+	if err := os.WriteFile(staged, data, 0600); err != nil {
+		return fmt.Errorf("failed to write custom tools file: %w", err)
+	}
+	if err := os.Rename(staged, s.path); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to replace custom tools file: %w", err)
+	}
+	return nil
+}
+
+// sortedDefinitions returns definitions ordered by name. Callers must hold the mutex.
+func (s *CustomToolStore) sortedDefinitions() []ToolDefinition {
+	definitions := make([]ToolDefinition, 0, len(s.definitions))
+	for _, definition := range s.definitions {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+	return definitions
+}
+
+// Definitions returns all registered definitions ordered by name.
+func (s *CustomToolStore) Definitions() []ToolDefinition {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.sortedDefinitions()
+}
+
+// Tools instantiates a tool for every registered definition.
+// Definitions whose template no longer parses are skipped with a warning.
+//
+// Parameters:
+//   - workspace: Workspace shared with the other tools
+//
+// Returns:
+//   - []tools.Tool: Instantiated custom tools
+func (s *CustomToolStore) Tools(workspace *localtools.WorkspaceContext) []tools.Tool {
+	definitions := s.Definitions()
+
+	toolsList := make([]tools.Tool, 0, len(definitions))
+	for _, definition := range definitions {
+		tool, err := localtools.NewTemplateTool(definition.Name, definition.Description, definition.Command, workspace)
+		if err != nil {
+			s.logger.WithError(err).WithField("tool", definition.Name).Warn("Skipping invalid custom tool")
+			continue
+		}
+		toolsList = append(toolsList, tool)
+	}
+	return toolsList
+}
@@ -0,0 +1,89 @@
+/*
+Package core provides the email notification sink for the Skynet Agent application.
+
+Email sinks send notifications through the SMTP server configured with SMTP_HOST,
+using STARTTLS when the server offers it. Subjects are rendered from a Go
+template with the host, session, and execution identifiers, EMAIL_SUBJECT unless
+the sink sets its own, so mail filters can sort notifications by host or session.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultEmailSubject is the subject template used unless EMAIL_SUBJECT is set
+const defaultEmailSubject = `[skynet {{.Host}}] {{.Title}} {{.Status}} (session {{.SessionID}})`
+
+// emailNotifier emails notifications to a list of recipients.
+type emailNotifier struct {
+	addr       string // SMTP server as host:port
+	auth       smtp.Auth
+	from       string
+	recipients []string
+	subject    *template.Template
+}
+
+// newEmailNotifier creates an email sink sending through the configured SMTP server.
+func newEmailNotifier(config *Config, recipients []string, subject string) (*emailNotifier, error) {
+	if config.SMTPHost == "" {
+		return nil, errors.New("email notifiers require SMTP_HOST")
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("email notifiers require recipients")
+	}
+	subjectTemplate, err := template.New("subject").Option("missingkey=zero").Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+
+	var auth smtp.Auth
+	if config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
+	}
+	from := config.EmailFrom
+	if from == "" {
+		from = config.SMTPUsername
+	}
+
+	return &emailNotifier{
+		addr:       net.JoinHostPort(config.SMTPHost, strconv.Itoa(config.SMTPPort)),
+		auth:       auth,
+		from:       from,
+		recipients: recipients,
+		subject:    subjectTemplate,
+	}, nil
+}
+
+// Notify renders a notification and delivers it to the recipients. The context is
+// not used: net/smtp does not support cancellation.
+func (n *emailNotifier) Notify(ctx context.Context, notification Notification) error {
+	var subject bytes.Buffer
+	if err := n.subject.Execute(&subject, notification); err != nil {
+		return fmt.Errorf("failed to render subject: %w", err)
+	}
+
+	// Header values must not contain line breaks
+	headerValue := strings.NewReplacer("\r", " ", "\n", " ").Replace
+	var email bytes.Buffer
+	fmt.Fprintf(&email, "From: %s\r\n", headerValue(n.from))
+	fmt.Fprintf(&email, "To: %s\r\n", headerValue(strings.Join(n.recipients, ", ")))
+	fmt.Fprintf(&email, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(subject.String())))
+	fmt.Fprintf(&email, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	email.WriteString("MIME-Version: 1.0\r\n")
+	email.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	email.WriteString("\r\n")
+	email.WriteString(strings.ReplaceAll(notificationText(notification, 1<<20), "\n", "\r\n"))
+
+	return smtp.SendMail(n.addr, n.auth, n.from, n.recipients, email.Bytes())
+}
@@ -0,0 +1,115 @@
+/*
+Package core provides encryption at rest for the Skynet Agent application.
+
+Tool inputs and errors contain the commands the agent ran and what they printed,
+which may include passwords, tokens, and other secrets. With ENCRYPTION_KEY set,
+such fields are encrypted with AES-GCM before they are written to disk and
+decrypted when they are read back:
+
+  - the audit log: tool inputs and errors
+  - the tasks file: goals, step answers and errors, and results
+  - the custom tools file: command templates
+
+Everything else the server writes stays in plain text: API key names and scopes
+(secrets are stored only as hashes), quota usage counters, analytics tags, the
+log file, which at the debug level includes messages and tool output, the SSH
+host key, and files in the working directory, such as chat attachments and what
+tools write there. Session messages are held in memory only.
+
+The key is the base64 encoding of 16, 24, or 32 random bytes (AES-128, -192, or
+-256), for example from "openssl rand -base64 32". Like every setting it can be
+read from a file with ENCRYPTION_KEY_FILE or from Vault with a vault: reference,
+so it need not be stored next to the data it protects. Encrypted values carry
+the "enc:v1:" prefix; values written before encryption was enabled are read as
+they are.
+
+Decrypted values are served only where their owner could read them anyway: the
+audit log, which spans every tenant, through the admin API only, and tasks to
+the callers that may use them.
+*/
+package core
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks encrypted values and the format version
+const encryptedPrefix = "enc:v1:"
+
+// FieldCipher encrypts individual string fields with AES-GCM. A nil cipher
+// leaves values unchanged, so stores can use one whether or not encryption is enabled.
+type FieldCipher struct {
+	aead cipher.AEAD // Authenticated cipher keyed with ENCRYPTION_KEY
+}
+
+// NewFieldCipher creates a cipher from a base64-encoded key.
+//
+// Parameters:
+//   - key: Base64 encoding of a 16, 24, or 32 byte key; empty disables encryption
+//
+// Returns:
+//   - *FieldCipher: Cipher for the key, or nil when the key is empty
+//   - error: The key is not valid base64 or has the wrong length
+func NewFieldCipher(key string) (*FieldCipher, error) {
+	if key == "" {
+		return nil, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, fmt.Errorf("ENCRYPTION_KEY must be base64-encoded: %w", err)
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("ENCRYPTION_KEY must be 16, 24, or 32 bytes, got %d", len(raw))
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES-GCM cipher: %w", err)
+	}
+	return &FieldCipher{aead: aead}, nil
+}
+
+// Encrypt returns the encrypted form of a value. Empty values stay empty.
+func (f *FieldCipher) Encrypt(value string) string {
+	if f == nil || value == "" {
+		return value
+	}
+	nonce := make([]byte, f.aead.NonceSize())
+	rand.Read(nonce)
+	sealed := f.aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed)
+}
+
+// Decrypt returns the plaintext of a value produced by Encrypt. Values without
+// the encrypted prefix are returned unchanged.
+//
+// Parameters:
+//   - value: Stored value
+//
+// Returns:
+//   - string: Plaintext
+//   - error: The value is encrypted but no key is configured, or the key does not match
+func (f *FieldCipher) Decrypt(value string) (string, error) {
+	encoded, encrypted := strings.CutPrefix(value, encryptedPrefix)
+	if !encrypted {
+		return value, nil
+	}
+	if f == nil {
+		return "", fmt.Errorf("value is encrypted but ENCRYPTION_KEY is not set")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < f.aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:f.aead.NonceSize()], sealed[f.aead.NonceSize():]
+	plaintext, err := f.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value; was it written with another ENCRYPTION_KEY? %w", err)
+	}
+	return string(plaintext), nil
+}
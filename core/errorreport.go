@@ -0,0 +1,280 @@
+/*
+Package core provides error reporting to Sentry for the Skynet Agent application.
+
+When SENTRY_DSN is set, panics recovered during agent execution and failed agent
+executions are sent to Sentry (or a compatible service such as GlitchTip) with
+their request ID, session, execution, and stack trace, so crashes in production
+installs are noticed instead of disappearing into logs. Cancelled executions are
+not reported.
+
+Events are sent in the background with Sentry's envelope HTTP API; Close waits
+briefly for events still in flight.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/sirupsen/logrus"
+)
+
+// errorReportTimeout bounds sending one event
+const errorReportTimeout = 10 * time.Second
+
+// sentryDSN is a parsed Sentry DSN.
+type sentryDSN struct {
+	envelopeURL string // Envelope endpoint of the project
+	publicKey   string // Key authenticating the events
+}
+
+// parseSentryDSN parses a DSN of the form https://<key>@<host>[/<path>]/<project>.
+func parseSentryDSN(dsn string) (sentryDSN, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return sentryDSN{}, fmt.Errorf("invalid SENTRY_DSN: expected https://<key>@<host>/<project>")
+	}
+	if parsed.User == nil || parsed.User.Username() == "" {
+		return sentryDSN{}, fmt.Errorf("invalid SENTRY_DSN: missing public key")
+	}
+
+	path := strings.Trim(parsed.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	prefix, project := "", path
+	if slash >= 0 {
+		prefix, project = "/"+path[:slash], path[slash+1:]
+	}
+	if project == "" {
+		return sentryDSN{}, fmt.Errorf("invalid SENTRY_DSN: missing project ID")
+	}
+
+	return sentryDSN{
+		envelopeURL: fmt.Sprintf("%s://%s%s/api/%s/envelope/", parsed.Scheme, parsed.Host, prefix, project),
+		publicKey:   parsed.User.Username(),
+	}, nil
+}
+
+// ErrorReporter sends errors and panics to Sentry. A nil reporter ignores all
+// reports, so callers do not need to check whether reporting is enabled.
+type ErrorReporter struct {
+	dsn         sentryDSN
+	rawDSN      string
+	environment string
+	serverName  string
+	client      *http.Client
+	logger      *logrus.Logger
+	pending     sync.WaitGroup // Events being sent
+}
+
+// NewErrorReporter creates a reporter for the configured DSN.
+//
+// Parameters:
+//   - config: Configuration providing the Sentry DSN and environment
+//   - logger: Logger for delivery failures
+//
+// Returns:
+//   - *ErrorReporter: Reporter, or nil when SENTRY_DSN is not set
+//   - error: Invalid DSN
+func NewErrorReporter(config *Config, logger *logrus.Logger) (*ErrorReporter, error) {
+	if config.SentryDSN == "" {
+		return nil, nil
+	}
+	dsn, err := parseSentryDSN(config.SentryDSN)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	logger.WithField("environment", config.SentryEnvironment).Info("Sentry error reporting enabled")
+	return &ErrorReporter{
+		dsn:         dsn,
+		rawDSN:      config.SentryDSN,
+		environment: config.SentryEnvironment,
+		serverName:  hostname,
+		client:      &http.Client{Timeout: errorReportTimeout},
+		logger:      logger,
+	}, nil
+}
+
+// CaptureError reports a failed execution. Cancellations are ignored.
+//
+// Parameters:
+//   - ctx: Execution context providing the request and execution details
+//   - err: Execution error
+func (r *ErrorReporter) CaptureError(ctx context.Context, err error) {
+	if r == nil || err == nil || errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+	r.send(ctx, "error", fmt.Sprintf("%T", err), err.Error(), 2)
+}
+
+// CapturePanic reports a recovered panic with the stack of the panicking goroutine.
+// It must be called from the deferred function that recovered the panic.
+//
+// Parameters:
+//   - ctx: Execution context providing the request and execution details
+//   - recovered: Value returned by recover
+func (r *ErrorReporter) CapturePanic(ctx context.Context, recovered interface{}) {
+	if r == nil || recovered == nil {
+		return
+	}
+	r.send(ctx, "fatal", "panic", fmt.Sprint(recovered), 2)
+}
+
+// Close waits up to the given timeout for events still being sent.
+func (r *ErrorReporter) Close(timeout time.Duration) {
+	if r == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		r.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// send builds an event with the stack above the skipped frames (send and its
+// caller count as two) and delivers it in the background.
+func (r *ErrorReporter) send(ctx context.Context, level, errorType, message string, skip int) {
+	eventID := make([]byte, 16)
+	rand.Read(eventID)
+
+	tags := map[string]string{}
+	if requestID := localtools.RequestIDFromContext(ctx); requestID != "" {
+		tags["request_id"] = requestID
+	}
+	var user map[string]string
+	if info, ok := ExecutionInfoFromContext(ctx); ok {
+		tags["session_id"] = info.SessionID
+		tags["execution_id"] = info.ExecutionID
+		user = map[string]string{"id": info.User}
+	}
+
+	event := map[string]interface{}{
+		"event_id":    hex.EncodeToString(eventID),
+		"timestamp":   time.Now().UTC().Format(time.RFC3339Nano),
+		"level":       level,
+		"platform":    "go",
+		"logger":      "skynet",
+		"release":     "skynet@" + Version,
+		"environment": r.environment,
+		"server_name": r.serverName,
+		"tags":        tags,
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{{
+				"type":       errorType,
+				"value":      message,
+				"stacktrace": map[string]interface{}{"frames": stackFrames(skip)},
+			}},
+		},
+	}
+	if user != nil {
+		event["user"] = user
+	}
+
+	r.pending.Add(1)
+	go func() {
+		defer r.pending.Done()
+		if err := r.deliver(event); err != nil {
+			r.logger.WithError(err).Warn("Failed to report error to Sentry")
+		}
+	}()
+}
+
+// deliver posts an event envelope to Sentry.
+func (r *ErrorReporter) deliver(event map[string]interface{}) error {
+	header, err := json.Marshal(map[string]interface{}{
+		"event_id": event["event_id"],
+		"dsn":      r.rawDSN,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	body.Write(header)
+	body.WriteString("\n{\"type\":\"event\"}\n")
+	body.Write(payload)
+	body.WriteString("\n")
+
+	req, err := http.NewRequest(http.MethodPost, r.dsn.envelopeURL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=skynet/%s, sentry_key=%s", Version, r.dsn.publicKey))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("sentry returned %s", resp.Status)
+	}
+	return nil
+}
+
+// stackFrames returns the stack of the calling goroutine in Sentry's frame
+// format, oldest call first, skipping the given number of frames above stackFrames.
+func stackFrames(skip int) []map[string]interface{} {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []map[string]interface{}
+	for {
+		frame, more := frames.Next()
+		module, function := splitFunctionName(frame.Function)
+		stack = append(stack, map[string]interface{}{
+			"function": function,
+			"module":   module,
+			"abs_path": frame.File,
+			"lineno":   frame.Line,
+			"in_app":   strings.HasPrefix(module, "skynet"),
+		})
+		if !more {
+			break
+		}
+	}
+
+	// Sentry expects the innermost frame last
+	for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+		stack[i], stack[j] = stack[j], stack[i]
+	}
+	return stack
+}
+
+// splitFunctionName splits a qualified function name such as
+// skynet/core.(*Server).chat into its package and function.
+func splitFunctionName(name string) (string, string) {
+	lastSlash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[lastSlash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:lastSlash+1+dot], name[lastSlash+1+dot+1:]
+}
@@ -0,0 +1,185 @@
+/*
+Package core provides the function-calling agent for the Skynet Agent application.
+
+In the default ReAct mode the model writes Thought/Action/Action Input text that
+is parsed into one tool call per step. Models with native tool calling (Gemini,
+recent Ollama models) can instead use the function-calling mode enabled with
+AGENT_MODE=functions: every tool is declared as a function taking a single
+"input" string, and the model may request several tool calls in one response.
+Independent read-only calls requested together are executed concurrently by
+the ParallelAgent wrapper.
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// Supported agent modes for the AGENT_MODE setting
+const (
+	AgentModeReAct     = "react"     // Text-based Thought/Action/Observation loop
+	AgentModeFunctions = "functions" // Native function calling with multiple calls per step
+)
+
+// FunctionCallingAgent plans tool use through the model's native function-calling API.
+type FunctionCallingAgent struct {
+	llm     llms.Model   // Model generating tool calls and final answers
+	tools   []tools.Tool // Tools exposed to the model as functions
+	host    *HostInfo    // Host environment described in the system prompt
+	cluster *ClusterInfo // Cluster identity described in the system prompt; nil outside cluster mode
+}
+
+// NewFunctionCallingAgent creates a function-calling agent over the given tools.
+//
+// Parameters:
+//   - llm: Model with native tool-calling support
+//   - toolsList: Tools exposed to the model as functions
+//   - host: Host environment for the system prompt
+//   - cluster: Cluster identity for the system prompt; nil outside cluster mode
+//
+// Returns:
+//   - *FunctionCallingAgent: Agent ready to be used by an executor
+func NewFunctionCallingAgent(llm llms.Model, toolsList []tools.Tool, host *HostInfo, cluster *ClusterInfo) *FunctionCallingAgent {
+	return &FunctionCallingAgent{llm: llm, tools: toolsList, host: host, cluster: cluster}
+}
+
+// definitions declares every tool as a function taking a single "input" string.
+func (a *FunctionCallingAgent) definitions() []llms.Tool {
+	definitions := make([]llms.Tool, 0, len(a.tools))
+	for _, tool := range a.tools {
+		definitions = append(definitions, llms.Tool{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name:        tool.Name(),
+				Description: tool.Description(),
+				Parameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"input": map[string]any{
+							"type":        "string",
+							"description": "Input for the tool, in the format given in the tool description",
+						},
+					},
+					"required": []string{"input"},
+				},
+			},
+		})
+	}
+	return definitions
+}
+
+// Plan sends the conversation and previous tool results to the model and returns
+// either the requested tool calls or the final answer.
+//
+// Parameters:
+//   - ctx: Execution context
+//   - intermediateSteps: Tool calls and observations from earlier iterations
+//   - inputs: Chain inputs; "input" holds the user's message
+//
+// Returns:
+//   - []schema.AgentAction: Tool calls requested by the model, possibly several
+//   - *schema.AgentFinish: Final answer when the model requested no tool calls
+//   - error: Any error from the model
+func (a *FunctionCallingAgent) Plan(ctx context.Context, intermediateSteps []schema.AgentStep, inputs map[string]string) ([]schema.AgentAction, *schema.AgentFinish, error) {
+	messages := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, CreateFunctionCallingPrompt(time.Now(), a.host, a.cluster)),
+		llms.TextParts(llms.ChatMessageTypeHuman, inputs["input"]),
+	}
+
+	for _, step := range intermediateSteps {
+		arguments, err := json.Marshal(map[string]string{"input": step.Action.ToolInput})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode tool call: %w", err)
+		}
+
+		messages = append(messages,
+			llms.MessageContent{
+				Role: llms.ChatMessageTypeAI,
+				Parts: []llms.ContentPart{llms.ToolCall{
+					ID:   step.Action.ToolID,
+					Type: "function",
+					FunctionCall: &llms.FunctionCall{
+						Name:      step.Action.Tool,
+						Arguments: string(arguments),
+					},
+				}},
+			},
+			llms.MessageContent{
+				Role: llms.ChatMessageTypeTool,
+				Parts: []llms.ContentPart{llms.ToolCallResponse{
+					ToolCallID: step.Action.ToolID,
+					Name:       step.Action.Tool,
+					Content:    step.Observation,
+				}},
+			},
+		)
+	}
+
+	response, err := a.llm.GenerateContent(ctx, messages, llms.WithTools(a.definitions()))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(response.Choices) == 0 {
+		return nil, nil, fmt.Errorf("model returned no choices")
+	}
+
+	choice := response.Choices[0]
+	if len(choice.ToolCalls) == 0 {
+		return nil, &schema.AgentFinish{
+			ReturnValues: map[string]any{"output": choice.Content},
+			Log:          choice.Content,
+		}, nil
+	}
+
+	actions := make([]schema.AgentAction, 0, len(choice.ToolCalls))
+	for i, call := range choice.ToolCalls {
+		if call.FunctionCall == nil {
+			continue
+		}
+
+		// Fall back to the raw arguments when the model ignored the input schema
+		input := call.FunctionCall.Arguments
+		var arguments map[string]any
+		if err := json.Unmarshal([]byte(call.FunctionCall.Arguments), &arguments); err == nil {
+			if value, ok := arguments["input"].(string); ok {
+				input = value
+			}
+		}
+
+		// Some providers (Gemini) do not assign call IDs
+		id := call.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%d_%d", len(intermediateSteps), i)
+		}
+
+		actions = append(actions, schema.AgentAction{
+			Tool:      call.FunctionCall.Name,
+			ToolInput: input,
+			Log:       fmt.Sprintf("Invoking: %s with %s\n", call.FunctionCall.Name, input),
+			ToolID:    id,
+		})
+	}
+	return actions, nil, nil
+}
+
+// GetInputKeys returns the chain input keys used by the agent.
+func (a *FunctionCallingAgent) GetInputKeys() []string {
+	return []string{"input"}
+}
+
+// GetOutputKeys returns the chain output keys produced by the agent.
+func (a *FunctionCallingAgent) GetOutputKeys() []string {
+	return []string{"output"}
+}
+
+// GetTools returns the tools available to the agent.
+func (a *FunctionCallingAgent) GetTools() []tools.Tool {
+	return a.tools
+}
@@ -0,0 +1,213 @@
+/*
+Package core provides the GitHub chat-ops integration for the Skynet Agent application.
+
+GitHub posts webhook events to /integrations/github, signed with GITHUB_WEBHOOK_SECRET.
+A comment on an issue or pull request of a repository listed in GITHUB_REPOS whose
+first line is "/skynet <request>" starts an agent run, and the answer is posted
+back as a comment on the same issue or pull request. Each issue and pull request
+has its own session (github_<owner>_<repo>_<number>), so follow-up commands see
+the earlier conversation.
+
+Because the agent acts on this host, only comments by repository owners, members
+of the owning organization, and collaborators are accepted. The answer is posted
+with GITHUB_TOKEN, a personal access token or GitHub App installation token with
+write access to issues and pull requests.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// githubCommandPrefix starts the comments that are requests to the agent
+const githubCommandPrefix = "/skynet"
+
+// githubCommentLimit is the maximum length of a comment body accepted by GitHub
+const githubCommentLimit = 65000
+
+// githubTrustedAssociations are the author associations whose commands are run
+var githubTrustedAssociations = map[string]bool{
+	"OWNER":        true,
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+}
+
+// githubCommentEvent is the payload of an issue_comment webhook event, which
+// GitHub sends for comments on both issues and pull requests.
+type githubCommentEvent struct {
+	Action string `json:"action"` // created, edited, or deleted
+	Issue  struct {
+		Number int `json:"number"`
+	} `json:"issue"`
+	Comment struct {
+		Body              string `json:"body"`
+		AuthorAssociation string `json:"author_association"`
+		User              struct {
+			Login string `json:"login"`
+			Type  string `json:"type"` // User or Bot
+		} `json:"user"`
+	} `json:"comment"`
+	Repository struct {
+		FullName string `json:"full_name"` // owner/name
+	} `json:"repository"`
+}
+
+// githubCommand extracts the request of a "/skynet <request>" comment. The request
+// continues on the following lines of the comment.
+func githubCommand(body string) (string, bool) {
+	body = strings.TrimSpace(body)
+	request, found := strings.CutPrefix(body, githubCommandPrefix)
+	if !found || (request != "" && request[0] != ' ' && request[0] != '\n' && request[0] != '\r') {
+		return "", false
+	}
+	request = strings.TrimSpace(request)
+	return request, request != ""
+}
+
+// handleGitHub handles POST /integrations/github requests from GitHub webhooks by
+// starting an agent run for every "/skynet" command comment.
+func (s *Server) handleGitHub(c echo.Context) error {
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"endpoint": "/integrations/github",
+		"method":   "POST",
+		"clientIP": c.RealIP(),
+	})
+
+	config := s.currentConfig()
+	if config.GitHubWebhookSecret == "" || config.GitHubToken == "" {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "GitHub integration is disabled; set GITHUB_WEBHOOK_SECRET and GITHUB_TOKEN to enable it"})
+	}
+
+	if locked, err := s.rejectLockedOut(c, "github"); locked {
+		return err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, 1<<20))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read request body"})
+	}
+	if !validHubSignature(config.GitHubWebhookSecret, c.Request().Header, body) {
+		requestLogger.Warn("Rejected GitHub event with invalid signature")
+		s.authFailed(c, "github")
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid signature"})
+	}
+	s.authSucceeded(c)
+
+	// GitHub also sends ping and other subscribed events; only new comments are commands
+	if c.Request().Header.Get("X-GitHub-Event") != "issue_comment" {
+		return c.NoContent(http.StatusNoContent)
+	}
+	var event githubCommentEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		requestLogger.WithError(err).Error("Failed to parse GitHub event")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid GitHub event"})
+	}
+	if event.Action != "created" || event.Comment.User.Type == "Bot" {
+		return c.NoContent(http.StatusNoContent)
+	}
+	request, isCommand := githubCommand(event.Comment.Body)
+	if !isCommand {
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	repository := event.Repository.FullName
+	eventLogger := requestLogger.WithFields(logrus.Fields{
+		"repository":  repository,
+		"issue":       event.Issue.Number,
+		"githubUser":  event.Comment.User.Login,
+		"association": event.Comment.AuthorAssociation,
+	})
+
+	allowed := false
+	for _, configured := range config.GitHubRepos {
+		allowed = allowed || strings.EqualFold(configured, repository)
+	}
+	if !allowed {
+		eventLogger.Info("Ignoring GitHub command from a repository that is not configured")
+		return c.NoContent(http.StatusNoContent)
+	}
+	if !githubTrustedAssociations[event.Comment.AuthorAssociation] {
+		eventLogger.Warn("Ignoring GitHub command from an untrusted user")
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	ticket, err := s.queue.Enqueue()
+	if err != nil {
+		return s.rejectQueueFull(c, eventLogger)
+	}
+
+	// GitHub expects an answer within ten seconds, so the agent runs after responding
+	ctx := localtools.WithRequestID(context.Background(), localtools.RequestIDFromContext(c.Request().Context()))
+	sessionID := fmt.Sprintf("github_%s_%d", strings.ReplaceAll(repository, "/", "_"), event.Issue.Number)
+	go s.runGitHubCommand(ctx, ticket, repository, event.Issue.Number, event.Comment.User.Login, sessionID, request, eventLogger)
+
+	eventLogger.Info("Accepted GitHub command")
+	return c.JSON(http.StatusAccepted, map[string]string{"sessionId": sessionID})
+}
+
+// runGitHubCommand runs a command through the agent once the queue admits it and
+// posts the answer as a comment.
+func (s *Server) runGitHubCommand(ctx context.Context, ticket *QueueTicket, repository string, number int, user, sessionID, request string, eventLogger *logrus.Entry) {
+	defer ticket.Release()
+	if err := ticket.Wait(ctx, nil); err != nil {
+		return
+	}
+
+	response, err := s.chat(ctx, sessionID, request, "github:"+user, eventLogger)
+	status := ""
+	if err != nil {
+		status = " (failed)"
+	}
+
+	comment := fmt.Sprintf("@%s%s\n\n%s\n\n<sub>Execution `%s` on session `%s`</sub>", user, status, response.Response, response.ExecutionID, sessionID)
+	if len(comment) > githubCommentLimit {
+		comment = comment[:githubCommentLimit-3] + "..."
+	}
+	if err := s.postGitHubComment(ctx, repository, number, comment); err != nil {
+		eventLogger.WithError(err).Error("Failed to post answer to GitHub")
+		return
+	}
+	eventLogger.WithField("executionID", response.ExecutionID).Info("Posted answer to GitHub")
+}
+
+// postGitHubComment adds a comment to an issue or pull request.
+func (s *Server) postGitHubComment(ctx context.Context, repository string, number int, comment string) error {
+	config := s.currentConfig()
+	payload, err := json.Marshal(map[string]string{"body": comment})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/comments", strings.TrimSuffix(config.GitHubAPIURL, "/"), repository, number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+config.GitHubToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+	return nil
+}
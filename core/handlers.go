@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"github.com/tmc/langchaingo/llms"
@@ -29,7 +30,60 @@ func NewVerboseCallbackHandler(requestLogger *logrus.Entry, config *Config) *Ver
 // StreamingCallbackHandler extends VerboseCallbackHandler to stream debug info to client
 type StreamingCallbackHandler struct {
 	*VerboseCallbackHandler
-	streamFunc func(msg StreamMessage)
+	streamFunc  func(msg StreamMessage)
+	outputMutex sync.Mutex // Serializes tool outputs of parallel tool calls
+}
+
+// maxStreamedToolOutput limits the tool output sent in a "tool" stream message
+const maxStreamedToolOutput = 8 << 10
+
+// toolOutputMessage creates the "tool" stream message reporting a finished tool
+// call: the tool, its input (the command it ran), and its output, truncated.
+func toolOutputMessage(tool, input, output string, err error) StreamMessage {
+	content := output
+	if len(content) > maxStreamedToolOutput {
+		content = content[:maxStreamedToolOutput] + fmt.Sprintf("\n... (%d more bytes)", len(output)-maxStreamedToolOutput)
+	}
+	msg := StreamMessage{
+		Type:     "tool",
+		Tool:     tool,
+		Content:  content,
+		Complete: true,
+		Details: map[string]interface{}{
+			"input":        input,
+			"outputLength": len(output),
+			"truncated":    len(output) > maxStreamedToolOutput,
+		},
+	}
+	if err != nil {
+		msg.Details["error"] = err.Error()
+	}
+	return msg
+}
+
+// ToolStreamHandler streams tool calls with their output to a client that did not
+// ask for debug events; all other events are only logged.
+type ToolStreamHandler struct {
+	*VerboseCallbackHandler
+	streamFunc  func(msg StreamMessage)
+	outputMutex sync.Mutex // Serializes tool outputs of parallel tool calls
+}
+
+// NewToolStreamHandler creates a handler streaming tool calls through streamFunc.
+func NewToolStreamHandler(requestLogger *logrus.Entry, config *Config, streamFunc func(msg StreamMessage)) *ToolStreamHandler {
+	return &ToolStreamHandler{
+		VerboseCallbackHandler: NewVerboseCallbackHandler(requestLogger, config),
+		streamFunc:             streamFunc,
+	}
+}
+
+// HandleToolOutput streams the tool call as a "tool" message.
+func (h *ToolStreamHandler) HandleToolOutput(ctx context.Context, tool, input, output string, err error) {
+	h.outputMutex.Lock()
+	defer h.outputMutex.Unlock()
+	msg := toolOutputMessage(tool, input, output, err)
+	msg.Iteration = h.iteration
+	h.streamFunc(msg)
 }
 
 func NewStreamingCallbackHandler(requestLogger *logrus.Entry, config *Config, streamFunc func(msg StreamMessage)) *StreamingCallbackHandler {
@@ -48,7 +102,7 @@ func (h *VerboseCallbackHandler) truncateForLog(text string) string {
 }
 
 func (h *VerboseCallbackHandler) HandleText(ctx context.Context, text string) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration":  h.iteration,
 		"step":       h.step,
 		"text":       h.truncateForLog(text),
@@ -59,7 +113,7 @@ func (h *VerboseCallbackHandler) HandleText(ctx context.Context, text string) {
 func (h *VerboseCallbackHandler) HandleLLMStart(ctx context.Context, prompts []string) {
 	h.iteration++
 	h.step = 0 // Reset step counter for new iteration
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration":   h.iteration,
 		"step":        h.step,
 		"promptCount": len(prompts),
@@ -73,7 +127,7 @@ func (h *VerboseCallbackHandler) HandleLLMStart(ctx context.Context, prompts []s
 }
 
 func (h *VerboseCallbackHandler) HandleLLMGenerateContentStart(ctx context.Context, ms []llms.MessageContent) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration":    h.iteration,
 		"step":         h.step,
 		"messageCount": len(ms),
@@ -81,7 +135,7 @@ func (h *VerboseCallbackHandler) HandleLLMGenerateContentStart(ctx context.Conte
 }
 
 func (h *VerboseCallbackHandler) HandleLLMGenerateContentEnd(ctx context.Context, res *llms.ContentResponse) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration": h.iteration,
 		"step":      h.step,
 		"response": func() string {
@@ -94,7 +148,7 @@ func (h *VerboseCallbackHandler) HandleLLMGenerateContentEnd(ctx context.Context
 }
 
 func (h *VerboseCallbackHandler) HandleLLMError(ctx context.Context, err error) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration": h.iteration,
 		"step":      h.step,
 		"error":     err.Error(),
@@ -102,7 +156,7 @@ func (h *VerboseCallbackHandler) HandleLLMError(ctx context.Context, err error)
 }
 
 func (h *VerboseCallbackHandler) HandleChainStart(ctx context.Context, inputs map[string]any) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration": h.iteration,
 		"step":      h.step,
 		"inputs":    inputs,
@@ -110,7 +164,7 @@ func (h *VerboseCallbackHandler) HandleChainStart(ctx context.Context, inputs ma
 }
 
 func (h *VerboseCallbackHandler) HandleChainEnd(ctx context.Context, outputs map[string]any) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration":       h.iteration,
 		"step":            h.step,
 		"outputs":         outputs,
@@ -119,7 +173,7 @@ func (h *VerboseCallbackHandler) HandleChainEnd(ctx context.Context, outputs map
 }
 
 func (h *VerboseCallbackHandler) HandleChainError(ctx context.Context, err error) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration":       h.iteration,
 		"step":            h.step,
 		"error":           err.Error(),
@@ -128,7 +182,7 @@ func (h *VerboseCallbackHandler) HandleChainError(ctx context.Context, err error
 }
 
 func (h *VerboseCallbackHandler) HandleToolStart(ctx context.Context, input string) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration": h.iteration,
 		"step":      h.step,
 		"input":     input,
@@ -136,7 +190,7 @@ func (h *VerboseCallbackHandler) HandleToolStart(ctx context.Context, input stri
 }
 
 func (h *VerboseCallbackHandler) HandleToolEnd(ctx context.Context, output string) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration":    h.iteration,
 		"step":         h.step,
 		"output":       h.truncateForLog(output),
@@ -145,7 +199,7 @@ func (h *VerboseCallbackHandler) HandleToolEnd(ctx context.Context, output strin
 }
 
 func (h *VerboseCallbackHandler) HandleToolError(ctx context.Context, err error) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration": h.iteration,
 		"step":      h.step,
 		"error":     err.Error(),
@@ -153,7 +207,7 @@ func (h *VerboseCallbackHandler) HandleToolError(ctx context.Context, err error)
 }
 
 func (h *VerboseCallbackHandler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration": h.iteration,
 		"step":      h.step,
 		"action":    action.Tool,
@@ -163,7 +217,7 @@ func (h *VerboseCallbackHandler) HandleAgentAction(ctx context.Context, action s
 }
 
 func (h *VerboseCallbackHandler) HandleAgentFinish(ctx context.Context, finish schema.AgentFinish) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration": h.iteration,
 		"step":      h.step,
 		"finalResponse": func() string {
@@ -178,7 +232,7 @@ func (h *VerboseCallbackHandler) HandleAgentFinish(ctx context.Context, finish s
 }
 
 func (h *VerboseCallbackHandler) HandleRetrieverStart(ctx context.Context, query string) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration": h.iteration,
 		"step":      h.step,
 		"query":     query,
@@ -186,7 +240,7 @@ func (h *VerboseCallbackHandler) HandleRetrieverStart(ctx context.Context, query
 }
 
 func (h *VerboseCallbackHandler) HandleRetrieverEnd(ctx context.Context, query string, documents []schema.Document) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration":     h.iteration,
 		"step":          h.step,
 		"query":         query,
@@ -195,7 +249,7 @@ func (h *VerboseCallbackHandler) HandleRetrieverEnd(ctx context.Context, query s
 }
 
 func (h *VerboseCallbackHandler) HandleStreamingFunc(ctx context.Context, chunk []byte) {
-	h.requestLogger.WithFields(logrus.Fields{
+	h.requestLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"iteration": h.iteration,
 		"step":      h.step,
 		"chunkSize": len(chunk),
@@ -337,6 +391,18 @@ func (h *StreamingCallbackHandler) HandleToolEnd(ctx context.Context, output str
 	}
 }
 
+// HandleToolOutput streams every tool call with its output as a "tool" message.
+func (h *StreamingCallbackHandler) HandleToolOutput(ctx context.Context, tool, input, output string, err error) {
+	if h.streamFunc == nil {
+		return
+	}
+	h.outputMutex.Lock()
+	defer h.outputMutex.Unlock()
+	msg := toolOutputMessage(tool, input, output, err)
+	msg.Iteration = h.iteration
+	h.streamFunc(msg)
+}
+
 func (h *StreamingCallbackHandler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
 	h.VerboseCallbackHandler.HandleAgentAction(ctx, action)
 	h.step++ // Increment step for each action
@@ -385,3 +451,9 @@ func (h *StreamingCallbackHandler) HandleAgentFinish(ctx context.Context, finish
 		})
 	}
 }
+
+// Ensure StreamingCallbackHandler and ToolStreamHandler stream tool outputs
+var (
+	_ ToolOutputHandler = (*StreamingCallbackHandler)(nil)
+	_ ToolOutputHandler = (*ToolStreamHandler)(nil)
+)
@@ -0,0 +1,123 @@
+/*
+Package core provides stream heartbeats for the Skynet Agent application.
+
+A long tool call or a long wait in the request queue can leave a server-sent
+event stream silent for minutes, and proxies and load balancers close idle
+connections. While a stream is open, a heartbeat writes every STREAM_HEARTBEAT
+seconds: an SSE comment (": keepalive"), which clients ignore, while the request
+waits, and a "progress" event with the elapsed time, the agent iteration, and
+the tools currently running once the execution has started.
+*/
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// streamMutexKey is the echo context key of the mutex serializing writes to a stream
+const streamMutexKey = "streamMutex"
+
+// streamHeartbeat periodically writes keepalive and progress events to one stream.
+type streamHeartbeat struct {
+	mutex     sync.Mutex      // Guards the execution fields
+	trace     *ExecutionTrace // Timeline of the running execution; nil while queued
+	startedAt time.Time       // When the execution started
+	stop      chan struct{}   // Closed to stop the heartbeat
+	done      chan struct{}   // Closed when the heartbeat goroutine has exited
+}
+
+// startHeartbeat starts the heartbeat of a stream whose headers have been set.
+// Writes to the stream through sendStreamMessage are serialized from then on.
+//
+// Parameters:
+//   - c: Echo context of the streaming request
+//
+// Returns:
+//   - *streamHeartbeat: Running heartbeat; call Stop before the handler returns
+func (s *Server) startHeartbeat(c echo.Context) *streamHeartbeat {
+	c.Set(streamMutexKey, &sync.Mutex{})
+	heartbeat := &streamHeartbeat{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	interval := s.currentConfig().StreamHeartbeat
+	if interval <= 0 {
+		close(heartbeat.done)
+		return heartbeat
+	}
+
+	go func() {
+		defer close(heartbeat.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeat.stop:
+				return
+			case <-c.Request().Context().Done():
+				return
+			case <-ticker.C:
+			}
+
+			heartbeat.mutex.Lock()
+			trace, startedAt := heartbeat.trace, heartbeat.startedAt
+			heartbeat.mutex.Unlock()
+
+			// Streams that do not receive progress events still need the keepalive
+			if trace == nil || !streamIncludes(c, StreamMessage{Type: "progress"}) {
+				s.writeStream(c, ": keepalive\n\n")
+				continue
+			}
+			iteration, running := trace.Progress()
+			elapsed := time.Since(startedAt).Round(time.Second)
+			content := fmt.Sprintf("Working for %s (iteration %d)", elapsed, iteration)
+			if len(running) > 0 {
+				content = fmt.Sprintf("Running %s for %s (iteration %d)", strings.Join(running, ", "), elapsed, iteration)
+			}
+			s.sendStreamMessage(c, StreamMessage{
+				Type:      "progress",
+				Content:   content,
+				Iteration: iteration,
+				Details: map[string]interface{}{
+					"elapsedSeconds": int(elapsed.Seconds()),
+					"runningTools":   running,
+				},
+			})
+		}
+	}()
+	return heartbeat
+}
+
+// ExecutionStarted switches the heartbeat from keepalives to progress events.
+func (h *streamHeartbeat) ExecutionStarted(trace *ExecutionTrace, startedAt time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.trace, h.startedAt = trace, startedAt
+}
+
+// Stop ends the heartbeat and waits until it no longer writes. It is safe to call more than once.
+func (h *streamHeartbeat) Stop() {
+	select {
+	case <-h.stop:
+	default:
+		close(h.stop)
+	}
+	<-h.done
+}
+
+// writeStream writes raw data to a stream and flushes it, serialized with the
+// stream's other writers when a heartbeat is running.
+func (s *Server) writeStream(c echo.Context, data string) {
+	if mutex, ok := c.Get(streamMutexKey).(*sync.Mutex); ok {
+		mutex.Lock()
+		defer mutex.Unlock()
+	}
+	fmt.Fprint(c.Response(), data)
+	c.Response().Flush()
+}
@@ -0,0 +1,266 @@
+/*
+Package core provides inbound webhook triggers for the Skynet Agent application.
+
+Hooks are declared in a YAML file. Each one is served at POST /hooks/<name> and
+turns the payloads it receives into an agent prompt with a Go template, so other
+systems can ask the agent for work when something happens:
+
+	hooks:
+	  - name: deploy
+	    secret: example-secret
+	    prompt: >-
+	      {{.Payload.repository.name}} was just deployed at {{.Payload.deployment.sha}}.
+	      Verify the deployment of the service on this host and report any problems.
+	    session: deploy_{{.Payload.repository.name}}
+	    notify: true
+	    tenant: payments
+
+Callers authenticate with the hook's secret, either as a bearer token or, as
+GitHub and compatible senders do, with an X-Hub-Signature-256 HMAC of the body.
+The prompt and session templates are rendered with .Payload, the decoded JSON
+body, and .Headers, the request headers; without a session template every
+trigger starts a new session. The execution runs in the background through the
+request queue, and with notify enabled its result is delivered to the
+notification sinks as a report. A hook with a tenant runs as that tenant: its
+triggers count against the tenant's rate limit, its executions may only use the
+tenant's tools, and their usage is accounted to the tenant.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// HookConfig declares an inbound webhook trigger.
+type HookConfig struct {
+	Name    string `yaml:"name"`    // Hook name, served at POST /hooks/<name>
+	Secret  string `yaml:"secret"`  // Bearer token or HMAC key authenticating callers
+	Prompt  string `yaml:"prompt"`  // Go template of the agent prompt
+	Session string `yaml:"session"` // Go template of the session ID (default: a new session per trigger)
+	Notify  bool   `yaml:"notify"`  // Deliver the result to the notification sinks as a report
+	Tenant  string `yaml:"tenant"`  // Tenant the executions run as (default: none)
+}
+
+// hooksFile is the top-level structure of the hooks file.
+type hooksFile struct {
+	Hooks []HookConfig `yaml:"hooks"`
+}
+
+// webhookHook is a loaded hook with its parsed templates.
+type webhookHook struct {
+	HookConfig
+	prompt  *template.Template
+	session *template.Template // nil starts a new session per trigger
+}
+
+// hookPayload is the data the prompt and session templates are rendered with.
+type hookPayload struct {
+	Payload interface{}       // Decoded JSON body, nil for an empty body
+	Headers map[string]string // Request headers, by canonical name
+}
+
+// loadHooks reads the hooks file and parses the templates of every hook. A missing
+// file is not an error.
+//
+// Parameters:
+//   - path: Path of the YAML hooks file; empty disables hooks
+//
+// Returns:
+//   - map[string]*webhookHook: Hooks by name
+//   - error: Any error reading or decoding the file, or an invalid hook
+func loadHooks(path string) (map[string]*webhookHook, error) {
+	hooks := make(map[string]*webhookHook)
+	if path == "" {
+		return hooks, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return hooks, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks file: %w", err)
+	}
+
+	var file hooksFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode hooks file: %w", err)
+	}
+
+	for _, declaration := range file.Hooks {
+		if declaration.Name == "" || strings.Contains(declaration.Name, "/") {
+			return nil, fmt.Errorf("hook %q: name must be non-empty and must not contain '/'", declaration.Name)
+		}
+		if _, exists := hooks[declaration.Name]; exists {
+			return nil, fmt.Errorf("hook %q is declared more than once", declaration.Name)
+		}
+		if declaration.Secret == "" || declaration.Prompt == "" {
+			return nil, fmt.Errorf("hook %q: secret and prompt are required", declaration.Name)
+		}
+
+		hook := &webhookHook{HookConfig: declaration}
+		if hook.prompt, err = template.New("prompt").Option("missingkey=zero").Parse(declaration.Prompt); err != nil {
+			return nil, fmt.Errorf("hook %q: invalid prompt template: %w", declaration.Name, err)
+		}
+		if declaration.Session != "" {
+			if hook.session, err = template.New("session").Option("missingkey=zero").Parse(declaration.Session); err != nil {
+				return nil, fmt.Errorf("hook %q: invalid session template: %w", declaration.Name, err)
+			}
+		}
+		hooks[declaration.Name] = hook
+	}
+	return hooks, nil
+}
+
+// authenticate checks the bearer token or the X-Hub-Signature-256 HMAC of a request.
+func (h *webhookHook) authenticate(header http.Header, body []byte) bool {
+	if header.Get("X-Hub-Signature-256") != "" {
+		return validHubSignature(h.Secret, header, body)
+	}
+	provided, found := strings.CutPrefix(header.Get(echo.HeaderAuthorization), "Bearer ")
+	return found && subtle.ConstantTimeCompare([]byte(provided), []byte(h.Secret)) == 1
+}
+
+// validHubSignature checks the X-Hub-Signature-256 header GitHub and compatible
+// senders compute as an HMAC-SHA256 of the body with the shared secret.
+func validHubSignature(secret string, header http.Header, body []byte) bool {
+	signature, found := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if !found {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(signature)) == 1
+}
+
+// renderHookTemplate executes a template with the payload and trims the result.
+func renderHookTemplate(tmpl *template.Template, data hookPayload) (string, error) {
+	var text bytes.Buffer
+	if err := tmpl.Execute(&text, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(text.String()), nil
+}
+
+// handleHook handles POST /hooks/:name requests by running the hook's prompt,
+// rendered with the payload, through the agent in the background.
+func (s *Server) handleHook(c echo.Context) error {
+	name := c.Param("name")
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"endpoint": "/hooks/:name",
+		"method":   "POST",
+		"hook":     name,
+		"clientIP": c.RealIP(),
+	})
+
+	hook, exists := s.hooks[name]
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": fmt.Sprintf("Hook %q not found", name)})
+	}
+
+	if locked, err := s.rejectLockedOut(c, "hook:"+name); locked {
+		return err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, 1<<20))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read request body"})
+	}
+	if !hook.authenticate(c.Request().Header, body) {
+		requestLogger.Warn("Rejected hook request with invalid secret")
+		s.authFailed(c, "hook:"+name)
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid secret"})
+	}
+	s.authSucceeded(c)
+
+	caller := sessionCaller{tenant: hook.Tenant}
+	if _, exists := s.tenants.Get(hook.Tenant); hook.Tenant != "" && !exists {
+		requestLogger.WithField("tenant", hook.Tenant).Error("Hook tenant is not declared")
+		return c.JSON(http.StatusForbidden, map[string]string{"error": fmt.Sprintf("Tenant %q of the hook is not declared", hook.Tenant)})
+	}
+	if limited, err := s.rejectRateLimited(c, caller, requestLogger); limited {
+		return err
+	}
+	if exceeded, err := s.rejectQuotaExceeded(c, caller, requestLogger); exceeded {
+		return err
+	}
+
+	data := hookPayload{Headers: make(map[string]string)}
+	for header := range c.Request().Header {
+		data.Headers[header] = c.Request().Header.Get(header)
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &data.Payload); err != nil {
+			requestLogger.WithError(err).Warn("Failed to parse hook payload")
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Payload must be JSON"})
+		}
+	}
+
+	message, err := renderHookTemplate(hook.prompt, data)
+	if err == nil && message == "" {
+		err = errors.New("prompt is empty")
+	}
+	if err != nil {
+		requestLogger.WithError(err).Warn("Failed to render hook prompt")
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": fmt.Sprintf("Failed to render prompt: %v", err)})
+	}
+	sessionID := fmt.Sprintf("hook_%s_%d", name, time.Now().UnixNano())
+	if hook.session != nil {
+		if sessionID, err = renderHookTemplate(hook.session, data); err != nil || sessionID == "" {
+			requestLogger.WithError(err).Warn("Failed to render hook session")
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Failed to render session ID"})
+		}
+	}
+
+	ticket, err := s.queue.Enqueue()
+	if err != nil {
+		return s.rejectQueueFull(c, requestLogger)
+	}
+
+	// The execution outlives the hook request but keeps its request ID
+	ctx := localtools.WithRequestID(context.Background(), localtools.RequestIDFromContext(c.Request().Context()))
+	ctx = s.withCaller(ctx, caller)
+	if hook.Notify {
+		ctx = WithNotificationReport(ctx, fmt.Sprintf("Hook %s", name))
+	}
+	hookLogger := requestLogger.WithField("sessionID", sessionID)
+	go s.runHook(ctx, ticket, name, sessionID, message, hookLogger)
+
+	hookLogger.Info("Hook triggered agent execution")
+	return c.JSON(http.StatusAccepted, map[string]string{"hook": name, "sessionId": sessionID})
+}
+
+// runHook runs a hook's prompt through the agent once the queue admits it.
+func (s *Server) runHook(ctx context.Context, ticket *QueueTicket, name, sessionID, message string, hookLogger *logrus.Entry) {
+	defer ticket.Release()
+	if err := ticket.Wait(ctx, nil); err != nil {
+		return
+	}
+
+	response, err := s.chat(ctx, sessionID, message, "hook:"+name, hookLogger)
+	if err != nil {
+		hookLogger.WithError(err).Error("Hook execution failed")
+		return
+	}
+	hookLogger.WithField("executionID", response.ExecutionID).Info("Hook execution completed")
+}
@@ -0,0 +1,193 @@
+/*
+Package core provides host environment detection for the Skynet Agent application.
+
+The system prompt tells the agent what kind of system it administers. Rather
+than assuming one platform, the server inspects the host once at startup: the
+distribution from /etc/os-release, the init system, the user it runs as and
+whether sudo is available, the package manager, and whether it runs inside a
+container. The SYSTEM CONTEXT section of the prompt is generated from the result,
+so the agent uses the right commands and does not attempt changes it lacks the
+privileges for.
+*/
+package core
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"os/user"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HostInfo describes the system the agent runs on.
+type HostInfo struct {
+	OS             string // Distribution name, such as "Alpine Linux v3.20"
+	InitSystem     string // "systemd", "OpenRC", "SysV init", or "" when none manages services
+	User           string // User the agent runs as
+	Root           bool   // Whether the agent runs with root privileges
+	Sudo           bool   // Whether sudo is available to an unprivileged user
+	PackageManager string // Package manager command, such as "apk" or "apt-get"; empty when none was found
+	Container      string // Container runtime the agent runs in, such as "Docker"; empty on a host
+}
+
+// packageManagers lists the package managers recognized, in order of preference
+var packageManagers = []string{"apk", "apt-get", "dnf", "yum", "zypper", "pacman"}
+
+// detectHost inspects the host the agent runs on.
+//
+// Parameters:
+//   - logger: Logger for the detection outcome
+//
+// Returns:
+//   - *HostInfo: Description of the host
+func detectHost(logger *logrus.Logger) *HostInfo {
+	host := &HostInfo{
+		OS:         hostOS(),
+		InitSystem: hostInitSystem(),
+		Root:       os.Geteuid() == 0,
+		Container:  hostContainer(),
+	}
+	if current, err := user.Current(); err == nil {
+		host.User = current.Username
+	}
+	if !host.Root {
+		_, err := exec.LookPath("sudo")
+		host.Sudo = err == nil
+	}
+	for _, manager := range packageManagers {
+		if _, err := exec.LookPath(manager); err == nil {
+			host.PackageManager = manager
+			break
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"os":             host.OS,
+		"initSystem":     host.InitSystem,
+		"user":           host.User,
+		"root":           host.Root,
+		"packageManager": host.PackageManager,
+		"container":      host.Container,
+	}).Info("Host environment detected")
+	return host
+}
+
+// hostOS returns the distribution's name from /etc/os-release, or the platform.
+func hostOS() string {
+	file, err := os.Open("/etc/os-release")
+	if err != nil {
+		return runtime.GOOS
+	}
+	defer file.Close()
+
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if key, value, found := strings.Cut(scanner.Text(), "="); found {
+			fields[key] = strings.Trim(value, `"'`)
+		}
+	}
+	for _, key := range []string{"PRETTY_NAME", "NAME"} {
+		if fields[key] != "" {
+			return fields[key]
+		}
+	}
+	return runtime.GOOS
+}
+
+// hostInitSystem identifies the init system managing services.
+func hostInitSystem() string {
+	if info, err := os.Stat("/run/systemd/system"); err == nil && info.IsDir() {
+		return "systemd"
+	}
+	if _, err := os.Stat("/run/openrc"); err == nil {
+		return "OpenRC"
+	}
+	comm, _ := os.ReadFile("/proc/1/comm")
+	switch strings.TrimSpace(string(comm)) {
+	case "systemd":
+		return "systemd"
+	case "openrc-init":
+		return "OpenRC"
+	case "init":
+		return "SysV init"
+	}
+	return ""
+}
+
+// hostContainer identifies the container runtime the agent runs in, if any.
+func hostContainer() string {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return "a Kubernetes pod"
+	}
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return "Docker"
+	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return "Podman"
+	}
+	cgroup, _ := os.ReadFile("/proc/1/cgroup")
+	for _, candidate := range []struct{ marker, name string }{
+		{"docker", "Docker"},
+		{"containerd", "containerd"},
+		{"lxc", "LXC"},
+	} {
+		if strings.Contains(string(cgroup), candidate.marker) {
+			return candidate.name
+		}
+	}
+	return ""
+}
+
+// privileges describes the agent's privileges in a few words for the prompt.
+func (h *HostInfo) privileges() string {
+	switch {
+	case h.Root:
+		return "FULL ROOT ACCESS"
+	case h.Sudo:
+		return "the privileges of user " + h.User + " (not root; sudo is available)"
+	default:
+		return "the privileges of user " + h.User + " (not root; no sudo)"
+	}
+}
+
+// systemContext generates the SYSTEM CONTEXT section of the system prompt.
+func (h *HostInfo) systemContext() string {
+	lines := []string{"SYSTEM CONTEXT:"}
+	if h.Container != "" {
+		lines = append(lines, "- You are running on "+h.OS+" inside "+h.Container+" with "+h.privileges())
+		lines = append(lines, "- Changes affect the container, which may be replaced; they do not reach the machine hosting it")
+	} else {
+		lines = append(lines, "- You are running directly on "+h.OS+" with "+h.privileges())
+	}
+
+	if h.Root {
+		lines = append(lines,
+			"- You have FULL WRITE AND READ ACCESS to the entire file system",
+			"- You can execute ANY shell commands, modify ANY files, install packages, manage services",
+		)
+	} else {
+		lines = append(lines, "- You can read and write only what user "+h.User+" may access; system files, packages, and services need root")
+		if h.Sudo {
+			lines = append(lines, "- Prefix commands that need root with sudo; if sudo asks for a password, report that instead of retrying")
+		} else {
+			lines = append(lines, "- Do not attempt changes that need root; explain what an administrator would have to do instead")
+		}
+	}
+
+	if h.PackageManager != "" {
+		lines = append(lines, "- Install packages with "+h.PackageManager)
+	} else {
+		lines = append(lines, "- No package manager is available")
+	}
+	if h.InitSystem != "" {
+		lines = append(lines, "- Services are managed by "+h.InitSystem)
+	} else {
+		lines = append(lines, "- No init system manages services here; processes are started directly")
+	}
+	lines = append(lines, "- Users expect you to perform real actions on the system")
+	return strings.Join(lines, "\n")
+}
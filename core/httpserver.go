@@ -0,0 +1,65 @@
+/*
+Package core provides HTTP server tuning for the Skynet Agent application.
+
+ConfigureHTTP applies the HTTP_* settings to the Echo instance before it starts:
+read, write, and idle timeouts and keep-alive on the underlying http.Server, and
+optional gzip compression of responses such as session exports and transcripts.
+Server-sent event streams are neither compressed, since compression buffers the
+events, nor bound by the write timeout, since they last as long as an execution.
+*/
+package core
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// compressionMinLength is the smallest response that is compressed; gzip overhead outweighs the savings below it
+const compressionMinLength = 1024
+
+// streamingRoutes are the routes that respond with server-sent events
+var streamingRoutes = map[string]bool{
+	"/chat/stream":         true,
+	"/workflows/:name/run": true,
+	"/admin/logs/stream":   true,
+}
+
+// isStreamingRoute reports whether a request was routed to a server-sent event stream.
+func isStreamingRoute(c echo.Context) bool {
+	return streamingRoutes[c.Path()]
+}
+
+// ConfigureHTTP applies the HTTP server settings to an Echo instance. Call it
+// before registering routes, so its middleware covers all of them.
+//
+// Parameters:
+//   - e: Echo instance that has not been started
+//   - config: Configuration with the HTTP_* settings
+func ConfigureHTTP(e *echo.Echo, config *Config) {
+	e.Server.ReadTimeout = config.HTTPReadTimeout
+	e.Server.WriteTimeout = config.HTTPWriteTimeout
+	e.Server.IdleTimeout = config.HTTPIdleTimeout
+	e.Server.SetKeepAlivesEnabled(config.HTTPKeepAlive)
+
+	if config.HTTPWriteTimeout > 0 {
+		// Streams write for as long as their execution runs
+		e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				if isStreamingRoute(c) {
+					http.NewResponseController(c.Response().Writer).SetWriteDeadline(time.Time{})
+				}
+				return next(c)
+			}
+		})
+	}
+
+	if config.HTTPCompression {
+		e.Use(middleware.GzipWithConfig(middleware.GzipConfig{
+			Skipper:   isStreamingRoute,
+			MinLength: compressionMinLength,
+		}))
+	}
+}
@@ -17,9 +17,13 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/tmc/langchaingo/llms"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // CleaningLLMWrapper is a custom LLM wrapper that preprocesses and cleans responses
@@ -162,11 +166,34 @@ func (w *CleaningLLMWrapper) cleanAgentResponse(response string) string {
 //   - *llms.ContentResponse: Cleaned response with processed content choices
 //   - error: Any error from the underlying LLM or processing
 func (w *CleaningLLMWrapper) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	ctx, span := tracer.Start(ctx, "llm.generate", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("llm.provider", w.config.LLMProvider),
+		attribute.Int("llm.messages", len(messages)),
+	))
+	defer span.End()
+
+	// Show the request's images to the model in every call of the execution
+	if images := attachmentsFromContext(ctx); len(images) > 0 {
+		messages = attachImages(messages, images)
+		span.SetAttributes(attribute.Int("llm.images", len(images)))
+	}
+
+	executionTrace := ExecutionTraceFromContext(ctx)
+	executionTrace.Record(TraceEvent{Type: TraceEventLLMStart})
+	startTime := time.Now()
+
 	// Call the underlying LLM for content generation
 	response, err := w.wrappedLLM.GenerateContent(ctx, messages, options...)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		executionTrace.Record(TraceEvent{Type: TraceEventLLMError, Error: err.Error(), DurationMs: time.Since(startTime).Milliseconds()})
 		return response, err
 	}
+	if response != nil {
+		span.SetAttributes(attribute.Int("llm.choices", len(response.Choices)))
+	}
+	tokenMeterFromContext(ctx).Add(response)
 
 	// Clean the response content for each choice
 	if response != nil && len(response.Choices) > 0 {
@@ -177,13 +204,14 @@ func (w *CleaningLLMWrapper) GenerateContent(ctx context.Context, messages []llm
 
 			// Log if significant cleaning occurred for monitoring purposes
 			if len(original) != len(cleaned) {
-				w.logger.WithFields(logrus.Fields{
+				w.logger.WithContext(ctx).WithFields(logrus.Fields{
 					"originalLength":  len(original),
 					"cleanedLength":   len(cleaned),
 					"originalPreview": w.truncateForLog(original),
 				}).Debug("Cleaned LLM response content")
 			}
 		}
+		executionTrace.Record(TraceEvent{Type: TraceEventLLMEnd, Content: response.Choices[0].Content, DurationMs: time.Since(startTime).Milliseconds()})
 	}
 
 	return response, nil
@@ -214,7 +242,7 @@ func (w *CleaningLLMWrapper) Call(ctx context.Context, prompt string, options ..
 
 	// Log if significant cleaning occurred for debugging and monitoring
 	if len(response) != len(cleaned) {
-		w.logger.WithFields(logrus.Fields{
+		w.logger.WithContext(ctx).WithFields(logrus.Fields{
 			"originalLength":  len(response),
 			"cleanedLength":   len(cleaned),
 			"originalPreview": w.truncateForLog(response),
@@ -0,0 +1,276 @@
+/*
+Package core provides the log sinks of the Skynet Agent application.
+
+LOG_OUTPUT selects where log entries are written, as a comma-separated list of
+sinks that all receive every entry:
+
+  - stdout, stderr: the process output streams (the default is stdout)
+  - file: LOG_FILE, rotated once it exceeds LOG_FILE_MAX_SIZE_MB or is older than
+    LOG_FILE_MAX_AGE_HOURS; LOG_FILE_MAX_BACKUPS rotated files are kept
+  - syslog: the local syslog daemon, or the server at LOG_SYSLOG_ADDRESS, with
+    the entry's level mapped to the syslog severity
+
+LOG_FORMAT selects JSON (the default, for log aggregation) or human-readable text
+for interactive use.
+*/
+package core
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	lsyslog "github.com/sirupsen/logrus/hooks/syslog"
+)
+
+// logSinks are the supported LOG_OUTPUT values
+var logSinks = map[string]bool{"stdout": true, "stderr": true, "file": true, "syslog": true}
+
+// logOutputs returns the sinks listed in LOG_OUTPUT, lowercased and without blanks.
+func logOutputs(value string) []string {
+	var outputs []string
+	for _, output := range strings.Split(value, ",") {
+		if output = strings.ToLower(strings.TrimSpace(output)); output != "" {
+			outputs = append(outputs, output)
+		}
+	}
+	return outputs
+}
+
+// validateLogOutput reports unsupported log sinks and formats.
+func (c *Config) validateLogOutput() []error {
+	var problems []error
+	for _, output := range logOutputs(c.LogOutput) {
+		if !logSinks[output] {
+			problems = append(problems, fmt.Errorf("unsupported LOG_OUTPUT %q: use stdout, stderr, file, or syslog", output))
+		}
+		if output == "file" && c.LogFile == "" {
+			problems = append(problems, fmt.Errorf("LOG_OUTPUT file requires LOG_FILE"))
+		}
+	}
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		problems = append(problems, fmt.Errorf("unsupported LOG_FORMAT %q: use json or text", c.LogFormat))
+	}
+	return problems
+}
+
+// configureLogOutput installs the configured formatter and sinks on a logger.
+// Sinks that cannot be opened are skipped and reported; stdout is used when no
+// sink could be opened.
+//
+// Parameters:
+//   - logger: Logger to configure
+//   - config: Configuration providing the log format and sinks
+//
+// Returns:
+//   - error: Joined errors of the sinks that could not be opened
+func configureLogOutput(logger *logrus.Logger, config *Config) error {
+	if config.LogFormat == "text" {
+		logger.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: time.RFC3339,
+		})
+	} else {
+		logger.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339, // Use RFC3339 for ISO 8601 compatibility
+		})
+	}
+
+	var writers []io.Writer
+	var problems []string
+	hasHook := false
+	for _, output := range logOutputs(config.LogOutput) {
+		switch output {
+		case "stdout":
+			writers = append(writers, os.Stdout)
+		case "stderr":
+			writers = append(writers, os.Stderr)
+		case "file":
+			file, err := NewRotatingFile(config.LogFile, config.LogFileMaxSize, config.LogFileMaxAge, config.LogFileMaxBackups)
+			if err != nil {
+				problems = append(problems, err.Error())
+				continue
+			}
+			writers = append(writers, file)
+		case "syslog":
+			hook, err := newSyslogHook(config.LogSyslogAddress)
+			if err != nil {
+				problems = append(problems, err.Error())
+				continue
+			}
+			logger.AddHook(hook)
+			hasHook = true
+		}
+	}
+
+	switch {
+	case len(writers) == 1:
+		logger.SetOutput(writers[0])
+	case len(writers) > 1:
+		logger.SetOutput(io.MultiWriter(writers...))
+	case hasHook:
+		// Entries reach syslog through the hook only
+		logger.SetOutput(io.Discard)
+	default:
+		logger.SetOutput(os.Stdout)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("failed to open log output: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// newSyslogHook connects to the local syslog daemon, or to a remote server given
+// as network://host:port (udp, tcp) or host:port (udp).
+func newSyslogHook(address string) (logrus.Hook, error) {
+	network, raddr := "", ""
+	if address != "" {
+		network, raddr = "udp", address
+		if scheme, rest, ok := strings.Cut(address, "://"); ok {
+			network, raddr = scheme, rest
+		}
+	}
+
+	hook, err := lsyslog.NewSyslogHook(network, raddr, syslog.LOG_INFO|syslog.LOG_DAEMON, "skynet")
+	if err != nil {
+		return nil, fmt.Errorf("syslog: %w", err)
+	}
+	return hook, nil
+}
+
+// RotatingFile is a log file that is rotated by size and age. Rotated files are
+// renamed with a timestamp suffix, and the oldest are removed beyond the backup
+// limit. It is safe for concurrent use.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string        // Active log file
+	maxSize    int64         // Size in bytes that triggers rotation; 0 disables it
+	maxAge     time.Duration // Age of the active file that triggers rotation; 0 disables it
+	maxBackups int           // Rotated files kept; 0 keeps all
+	file       *os.File      // Open active file
+	size       int64         // Bytes in the active file
+	opened     time.Time     // When the active file was created
+}
+
+// NewRotatingFile opens a log file for appending, creating its directory if needed.
+//
+// Parameters:
+//   - path: Log file path
+//   - maxSizeMB: Size in megabytes that triggers rotation; 0 disables size-based rotation
+//   - maxAge: Age that triggers rotation; 0 disables age-based rotation
+//   - maxBackups: Rotated files to keep; 0 keeps all
+//
+// Returns:
+//   - *RotatingFile: Open log file
+//   - error: Error creating or opening the file
+func NewRotatingFile(path string, maxSizeMB int, maxAge time.Duration, maxBackups int) (*RotatingFile, error) {
+	file := &RotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("log file %s: %w", path, err)
+	}
+	if err := file.open(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// open opens the active file, continuing an existing one.
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("log file %s: %w", f.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("log file %s: %w", f.path, err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.opened = time.Now()
+	if f.size > 0 {
+		// An existing file ages from when it was last rotated, approximated by its modification time
+		f.opened = info.ModTime()
+	}
+	return nil
+}
+
+// Write appends a log entry, rotating the file first when it is due.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.size > 0 && ((f.maxSize > 0 && f.size+int64(len(p)) > f.maxSize) || (f.maxAge > 0 && time.Since(f.opened) > f.maxAge)) {
+		if err := f.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to rotate log file %s: %v\n", f.path, err)
+		}
+	}
+	if f.file == nil {
+		return 0, fmt.Errorf("log file %s is not open", f.path)
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the active file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// rotate renames the active file with a timestamp suffix, opens a new one, and
+// removes rotated files beyond the backup limit; the caller must hold the lock.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	rotated := f.path + "." + time.Now().Format("20060102-150405.000")
+	if err := os.Rename(f.path, rotated); err != nil {
+		// Keep writing to the current file rather than losing entries
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.maxBackups > 0 {
+		backups, err := filepath.Glob(f.path + ".*")
+		if err != nil {
+			return err
+		}
+		// Timestamp suffixes sort chronologically
+		sort.Strings(backups)
+		for len(backups) > f.maxBackups {
+			os.Remove(backups[0])
+			backups = backups[1:]
+		}
+	}
+	return nil
+}
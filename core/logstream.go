@@ -0,0 +1,193 @@
+/*
+Package core provides the live log stream for the Skynet Agent application.
+
+GET /admin/logs/stream tails the server's structured logs as server-sent events,
+so operators can watch what the agent is doing on a headless machine without a
+shell. Entries are broadcast by a logrus hook on the server logger and on the
+standard logger used by the tools. Each client can filter by minimum level and by
+component (the component or tool field of an entry).
+
+Streaming never slows down logging: entries are dropped for clients that do not
+keep up, and the hook does no work while nobody is watching.
+*/
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// logStreamBuffer is the number of entries buffered per client before entries are dropped
+const logStreamBuffer = 256
+
+// logStreamHeartbeat is the interval of keep-alive comments on idle streams
+const logStreamHeartbeat = 15 * time.Second
+
+// LogEvent is a log entry sent to log stream clients.
+type LogEvent struct {
+	Time    time.Time              `json:"time"`             // When the entry was logged
+	Level   string                 `json:"level"`            // Entry level, e.g. "info"
+	Message string                 `json:"message"`          // Log message
+	Fields  map[string]interface{} `json:"fields,omitempty"` // Structured fields of the entry
+}
+
+// LogBroadcaster is a logrus hook that fans log entries out to stream subscribers.
+// It is safe for concurrent use.
+type LogBroadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[chan LogEvent]struct{}
+	count       atomic.Int32 // Number of subscribers, checked without locking
+}
+
+// NewLogBroadcaster creates a broadcaster without subscribers.
+//
+// Returns:
+//   - *LogBroadcaster: Broadcaster to be added as a hook to loggers
+func NewLogBroadcaster() *LogBroadcaster {
+	return &LogBroadcaster{subscribers: make(map[chan LogEvent]struct{})}
+}
+
+// Levels returns all levels; clients filter by level themselves.
+func (b *LogBroadcaster) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire sends an entry to every subscriber with room in its buffer.
+func (b *LogBroadcaster) Fire(entry *logrus.Entry) error {
+	if b.count.Load() == 0 {
+		return nil
+	}
+
+	event := LogEvent{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Fields:  make(map[string]interface{}, len(entry.Data)),
+	}
+	for key, value := range entry.Data {
+		// Errors do not encode to JSON by themselves
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		event.Fields[key] = value
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// subscribe registers a new subscriber and returns its channel.
+func (b *LogBroadcaster) subscribe() chan LogEvent {
+	events := make(chan LogEvent, logStreamBuffer)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[events] = struct{}{}
+	b.count.Add(1)
+	return events
+}
+
+// unsubscribe removes a subscriber.
+func (b *LogBroadcaster) unsubscribe(events chan LogEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[events]; ok {
+		delete(b.subscribers, events)
+		b.count.Add(-1)
+	}
+}
+
+// logEventMatches reports whether an event passes the level and component filters of a client.
+func logEventMatches(event LogEvent, minLevel logrus.Level, components map[string]bool) bool {
+	level, err := logrus.ParseLevel(event.Level)
+	if err == nil && level > minLevel {
+		return false
+	}
+	if len(components) == 0 {
+		return true
+	}
+	for _, field := range []string{"component", "tool"} {
+		if name, ok := event.Fields[field].(string); ok && components[strings.ToLower(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// handleAdminLogStream handles GET /admin/logs/stream requests by streaming log
+// entries as server-sent events until the client disconnects.
+//
+// Query parameters:
+//   - level: Minimum level to stream (debug, info, warn, error); default debug
+//   - component: Comma-separated components or tool names; default all
+func (s *Server) handleAdminLogStream(c echo.Context) error {
+	minLevel := logrus.DebugLevel
+	if level := c.QueryParam("level"); level != "" {
+		parsed, err := logrus.ParseLevel(level)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid level: use debug, info, warn, or error"})
+		}
+		minLevel = parsed
+	}
+	components := make(map[string]bool)
+	for _, component := range strings.Split(c.QueryParam("component"), ",") {
+		if component = strings.ToLower(strings.TrimSpace(component)); component != "" {
+			components[component] = true
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"endpoint":   "/admin/logs/stream",
+		"method":     "GET",
+		"clientIP":   c.RealIP(),
+		"level":      minLevel.String(),
+		"components": c.QueryParam("component"),
+	}).Info("Log stream client connected")
+
+	events := s.logStream.subscribe()
+	defer s.logStream.unsubscribe(events)
+
+	c.Response().Header().Set("Content-Type", "text/event-stream")
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	c.Response().Header().Set("Connection", "keep-alive")
+	c.Response().WriteHeader(http.StatusOK)
+	c.Response().Flush()
+
+	heartbeat := time.NewTicker(logStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			// Logging here would be streamed to the other clients only; the disconnect is not worth it
+			return nil
+		case <-heartbeat.C:
+			fmt.Fprint(c.Response(), ": keep-alive\n\n")
+			c.Response().Flush()
+		case event := <-events:
+			if !logEventMatches(event, minLevel, components) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Response(), "data: %s\n\n", data)
+			c.Response().Flush()
+		}
+	}
+}
@@ -0,0 +1,394 @@
+/*
+Package core provides the Matrix bot integration for the Skynet Agent application.
+
+The bot logs in to a Matrix homeserver with MATRIX_ACCESS_TOKEN, joins the rooms
+listed in MATRIX_ROOMS, and follows them with the client-server sync API. Every
+message in those rooms becomes a chat request, and each room has its own session
+(matrix_<room>), so a conversation continues across messages. Direct messages
+are rooms too; add their IDs to MATRIX_ROOMS to answer them.
+
+Like the Slack bot, the bot answers in a thread under the message, posts every
+tool the agent runs to the thread while it works, and offers approval prompts for
+a decision: it reacts to an answer that asks for approval or confirmation with
+✅ and ❌, and a user's ✅ or ❌ reaction decides the operations the session's
+tools are waiting to have approved and is sent to the agent as the reply in the
+same session.
+
+The bot does not implement end-to-end encryption and ignores encrypted messages.
+To use it in encrypted rooms, point MATRIX_HOMESERVER_URL at an E2EE-aware proxy
+such as pantalaimon.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// matrixSyncTimeout is how long a sync request waits for new events
+const matrixSyncTimeout = 30 * time.Second
+
+// matrixRetryDelay is the pause after a failed sync before the next one
+const matrixRetryDelay = 5 * time.Second
+
+// Reactions offering an approval decision
+const (
+	matrixApprove = "✅"
+	matrixDeny    = "❌"
+)
+
+// matrixEvent is a room event of a sync response.
+type matrixEvent struct {
+	Type    string `json:"type"`
+	Sender  string `json:"sender"`
+	EventID string `json:"event_id"`
+	Content struct {
+		MsgType   string `json:"msgtype"`
+		Body      string `json:"body"`
+		RelatesTo struct {
+			RelType string `json:"rel_type"` // m.thread for thread replies, m.annotation for reactions
+			EventID string `json:"event_id"`
+			Key     string `json:"key"` // Reaction emoji
+		} `json:"m.relates_to"`
+	} `json:"content"`
+}
+
+// matrixSyncResponse is the part of a sync response the bot uses.
+type matrixSyncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []matrixEvent `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+	} `json:"rooms"`
+}
+
+// matrixClient calls the Matrix client-server API with an access token.
+type matrixClient struct {
+	homeserver string
+	token      string
+	client     *http.Client
+	txnID      atomic.Int64 // Counter making transaction IDs unique
+}
+
+// newMatrixClient creates a client for the homeserver.
+func newMatrixClient(homeserver, token string) *matrixClient {
+	return &matrixClient{
+		homeserver: strings.TrimSuffix(homeserver, "/"),
+		token:      token,
+		client:     &http.Client{Timeout: matrixSyncTimeout + 30*time.Second},
+	}
+}
+
+// call invokes an API endpoint below /_matrix/client/v3 and decodes the response into result.
+func (mc *matrixClient) call(ctx context.Context, method, path string, payload, result interface{}) error {
+	var body bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, mc.homeserver+"/_matrix/client/v3"+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+mc.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := mc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var matrixError struct {
+			ErrCode string `json:"errcode"`
+			Error   string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&matrixError)
+		return fmt.Errorf("matrix %s: %s %s %s", path, resp.Status, matrixError.ErrCode, matrixError.Error)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// send sends a room event and returns its event ID.
+func (mc *matrixClient) send(ctx context.Context, room, eventType string, content map[string]interface{}) (string, error) {
+	txnID := fmt.Sprintf("skynet%d_%d", time.Now().UnixNano(), mc.txnID.Add(1))
+	var result struct {
+		EventID string `json:"event_id"`
+	}
+	path := fmt.Sprintf("/rooms/%s/send/%s/%s", url.PathEscape(room), eventType, txnID)
+	err := mc.call(ctx, http.MethodPut, path, content, &result)
+	return result.EventID, err
+}
+
+// sendMessage posts a text message, in the thread under threadRoot when set.
+func (mc *matrixClient) sendMessage(ctx context.Context, room, threadRoot, text string) (string, error) {
+	content := map[string]interface{}{"msgtype": "m.text", "body": text}
+	if threadRoot != "" {
+		content["m.relates_to"] = map[string]interface{}{"rel_type": "m.thread", "event_id": threadRoot}
+	}
+	return mc.send(ctx, room, "m.room.message", content)
+}
+
+// react annotates an event with a reaction.
+func (mc *matrixClient) react(ctx context.Context, room, eventID, key string) error {
+	_, err := mc.send(ctx, room, "m.reaction", map[string]interface{}{
+		"m.relates_to": map[string]interface{}{"rel_type": "m.annotation", "event_id": eventID, "key": key},
+	})
+	return err
+}
+
+// matrixApproval is an answer waiting for an approval decision.
+type matrixApproval struct {
+	room       string
+	threadRoot string
+}
+
+// MatrixBot follows the configured rooms and answers their messages.
+type MatrixBot struct {
+	server    *Server
+	matrix    *matrixClient
+	userID    string                    // The bot's own user ID, whose events are ignored
+	rooms     map[string]bool           // Rooms whose messages are answered
+	approvals map[string]matrixApproval // Answers offering approval reactions, by event ID
+	mutex     sync.Mutex                // Guards approvals
+	logger    *logrus.Entry
+}
+
+// StartMatrixBot starts the Matrix bot in the background when MATRIX_HOMESERVER_URL
+// and MATRIX_ACCESS_TOKEN are set. The bot stops when the server is closed.
+func (s *Server) StartMatrixBot() {
+	config := s.currentConfig()
+	if config.MatrixHomeserverURL == "" || config.MatrixAccessToken == "" {
+		return
+	}
+
+	rooms := make(map[string]bool)
+	for _, room := range config.MatrixRooms {
+		rooms[room] = true
+	}
+	bot := &MatrixBot{
+		server:    s,
+		matrix:    newMatrixClient(config.MatrixHomeserverURL, config.MatrixAccessToken),
+		rooms:     rooms,
+		approvals: make(map[string]matrixApproval),
+		logger:    s.logger.WithField("component", "matrix"),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopMatrix = cancel
+	go bot.run(ctx)
+}
+
+// run logs in, joins the configured rooms, and handles new events until ctx ends.
+func (b *MatrixBot) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		var whoami struct {
+			UserID string `json:"user_id"`
+		}
+		err := b.matrix.call(ctx, http.MethodGet, "/account/whoami", nil, &whoami)
+		if err == nil {
+			b.userID = whoami.UserID
+			break
+		}
+		b.logger.WithError(err).Error("Failed to log in to the Matrix homeserver")
+		sleepContext(ctx, matrixRetryDelay)
+	}
+
+	for room := range b.rooms {
+		if err := b.matrix.call(ctx, http.MethodPost, "/join/"+url.PathEscape(room), map[string]interface{}{}, nil); err != nil {
+			b.logger.WithError(err).WithField("room", room).Error("Failed to join Matrix room")
+		}
+	}
+	b.logger.WithFields(logrus.Fields{
+		"userID": b.userID,
+		"rooms":  len(b.rooms),
+	}).Info("Matrix bot started")
+
+	// The first sync only finds the position after the existing history, which is not answered
+	since := ""
+	for ctx.Err() == nil {
+		timeout := matrixSyncTimeout
+		if since == "" {
+			timeout = 0
+		}
+		var response matrixSyncResponse
+		path := fmt.Sprintf("/sync?timeout=%d", timeout.Milliseconds())
+		if since != "" {
+			path += "&since=" + url.QueryEscape(since)
+		}
+		if err := b.matrix.call(ctx, http.MethodGet, path, nil, &response); err != nil {
+			if ctx.Err() == nil {
+				b.logger.WithError(err).Warn("Matrix sync failed")
+				sleepContext(ctx, matrixRetryDelay)
+			}
+			continue
+		}
+
+		if since != "" {
+			for room, joined := range response.Rooms.Join {
+				if !b.rooms[room] {
+					continue
+				}
+				for _, event := range joined.Timeline.Events {
+					b.handleEvent(ctx, room, event)
+				}
+			}
+		}
+		since = response.NextBatch
+	}
+}
+
+// sleepContext waits for the given duration or until ctx ends.
+func sleepContext(ctx context.Context, duration time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(duration):
+	}
+}
+
+// handleEvent answers a message or applies an approval reaction.
+func (b *MatrixBot) handleEvent(ctx context.Context, room string, event matrixEvent) {
+	if event.Sender == b.userID {
+		return
+	}
+	eventLogger := b.logger.WithFields(logrus.Fields{
+		"room":       room,
+		"matrixUser": event.Sender,
+	})
+
+	switch event.Type {
+	case "m.room.encrypted":
+		eventLogger.Warn("Ignoring encrypted Matrix message; use an E2EE proxy such as pantalaimon for encrypted rooms")
+
+	case "m.room.message":
+		message := strings.TrimSpace(event.Content.Body)
+		if event.Content.MsgType != "m.text" || message == "" {
+			return
+		}
+		threadRoot := event.EventID
+		if event.Content.RelatesTo.RelType == "m.thread" {
+			threadRoot = event.Content.RelatesTo.EventID
+		}
+		eventLogger.Info("Accepted Matrix message")
+		go b.runChat(ensureRequestID(ctx), room, threadRoot, event.Sender, message, eventLogger)
+
+	case "m.reaction":
+		relation := event.Content.RelatesTo
+		if relation.RelType != "m.annotation" || (relation.Key != matrixApprove && relation.Key != matrixDeny) {
+			return
+		}
+		b.mutex.Lock()
+		approval, pending := b.approvals[relation.EventID]
+		delete(b.approvals, relation.EventID)
+		b.mutex.Unlock()
+		if !pending {
+			return
+		}
+
+		decision, reply := "Approved", "Approved, go ahead."
+		if relation.Key == matrixDeny {
+			decision, reply = "Denied", "Denied, do not go ahead."
+		}
+		eventLogger.WithField("action", strings.ToLower(decision)).Info("Accepted Matrix approval decision")
+		b.server.decideApprovals("matrix_"+approval.room, relation.Key == matrixApprove, eventLogger)
+		go func(ctx context.Context) {
+			if _, err := b.matrix.sendMessage(ctx, approval.room, approval.threadRoot, fmt.Sprintf("%s by %s", decision, event.Sender)); err != nil {
+				eventLogger.WithError(err).Warn("Failed to post Matrix approval decision")
+			}
+			b.runChat(ctx, approval.room, approval.threadRoot, event.Sender, reply, eventLogger)
+		}(ensureRequestID(ctx))
+	}
+}
+
+// runChat runs a message through the agent in the room's session and posts the
+// answer to the thread.
+func (b *MatrixBot) runChat(ctx context.Context, room, threadRoot, user, message string, eventLogger *logrus.Entry) {
+	s := b.server
+	eventLogger = eventLogger.WithField("requestId", localtools.RequestIDFromContext(ctx))
+
+	ticket, err := s.queue.Enqueue()
+	if err != nil {
+		eventLogger.Warn("Request queue is full, rejecting Matrix message")
+		if _, err := b.matrix.sendMessage(ctx, room, threadRoot, "⏳ The server is busy, please try again later."); err != nil {
+			eventLogger.WithError(err).Warn("Failed to post to Matrix")
+		}
+		return
+	}
+	defer ticket.Release()
+	if err := ticket.Wait(ctx, nil); err != nil {
+		return
+	}
+
+	progress := &matrixProgressHandler{
+		VerboseCallbackHandler: NewVerboseCallbackHandler(eventLogger.WithField("component", "matrix_agent"), s.currentConfig()),
+		ctx:                    ctx,
+		matrix:                 b.matrix,
+		room:                   room,
+		threadRoot:             threadRoot,
+	}
+	response, _ := s.chat(WithCallbackHandler(ctx, progress), "matrix_"+room, message, "matrix:"+user, eventLogger)
+
+	eventID, err := b.matrix.sendMessage(ctx, room, threadRoot, response.Response)
+	if err != nil {
+		eventLogger.WithError(err).Error("Failed to post answer to Matrix")
+		return
+	}
+	if !approvalRequestPattern.MatchString(response.Response) {
+		return
+	}
+
+	// Offer the decision as reactions on the answer
+	b.mutex.Lock()
+	b.approvals[eventID] = matrixApproval{room: room, threadRoot: threadRoot}
+	b.mutex.Unlock()
+	for _, key := range []string{matrixApprove, matrixDeny} {
+		if err := b.matrix.react(ctx, room, eventID, key); err != nil {
+			eventLogger.WithError(err).Warn("Failed to offer Matrix approval reaction")
+		}
+	}
+}
+
+// matrixProgressHandler posts the tools the agent runs to the Matrix thread of the request.
+type matrixProgressHandler struct {
+	*VerboseCallbackHandler
+	ctx        context.Context // Context for posting, independent of the agent's callbacks
+	matrix     *matrixClient
+	room       string
+	threadRoot string
+}
+
+func (h *matrixProgressHandler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
+	h.VerboseCallbackHandler.HandleAgentAction(ctx, action)
+	input := action.ToolInput
+	if len(input) > 200 {
+		input = input[:200] + "..."
+	}
+	if _, err := h.matrix.sendMessage(h.ctx, h.room, h.threadRoot, fmt.Sprintf("⚙️ Running %s %s", action.Tool, input)); err != nil {
+		h.requestLogger.WithError(err).Warn("Failed to post tool progress to Matrix")
+	}
+}
+
+// Ensure matrixProgressHandler implements the callbacks.Handler interface
+var _ callbacks.Handler = (*matrixProgressHandler)(nil)
@@ -0,0 +1,447 @@
+/*
+Package core provides the Model Context Protocol (MCP) client for the Skynet Agent application.
+
+Skynet can connect to MCP servers and expose their tools to the agent alongside
+the built-in ones. Servers are declared in a YAML file and launched as child
+processes speaking JSON-RPC 2.0 over stdio:
+
+	servers:
+	  - name: github
+	    command: npx
+	    args: ["-y", "@modelcontextprotocol/server-github"]
+	    env:
+	      GITHUB_PERSONAL_ACCESS_TOKEN: ghp_example
+	  - name: filesystem
+	    command: /usr/local/bin/mcp-filesystem
+	    args: ["/srv"]
+	    enabled: false
+
+At startup each enabled server is initialized and its tools are discovered with
+tools/list. Every discovered tool is exposed as "<server>_<tool>" so tools from
+different servers never collide.
+*/
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// mcpProtocolVersion is the MCP revision announced during initialization
+const mcpProtocolVersion = "2024-11-05"
+
+// mcpStartupTimeout bounds server initialization and tool discovery
+const mcpStartupTimeout = 30 * time.Second
+
+// MCPServerConfig declares an MCP server launched over stdio.
+type MCPServerConfig struct {
+	Name    string            `yaml:"name"`    // Server name, used as the tool name prefix
+	Command string            `yaml:"command"` // Executable launching the server
+	Args    []string          `yaml:"args"`    // Command-line arguments
+	Env     map[string]string `yaml:"env"`     // Additional environment variables
+	Enabled *bool             `yaml:"enabled"` // Whether the server is started (default: true)
+}
+
+// mcpConfigFile is the top-level structure of the MCP configuration file.
+type mcpConfigFile struct {
+	Servers []MCPServerConfig `yaml:"servers"`
+}
+
+// mcpRequest is an outgoing JSON-RPC 2.0 request or notification.
+type mcpRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// mcpResponse is an incoming JSON-RPC 2.0 message.
+type mcpResponse struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// mcpToolInfo describes a tool advertised by an MCP server.
+type mcpToolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// MCPClient is a connection to a single MCP server process.
+type MCPClient struct {
+	name    string                      // Server name from configuration
+	cmd     *exec.Cmd                   // Server process
+	stdin   io.WriteCloser              // Pipe for outgoing messages
+	nextID  int64                       // Next JSON-RPC request ID
+	pending map[int64]chan *mcpResponse // Requests awaiting a response
+	mutex   sync.Mutex                  // Guards nextID, pending, and writes to stdin
+	logger  *logrus.Entry               // Structured logger tagged with the server name
+}
+
+// LoadMCPServers reads the MCP configuration file. A missing file is not an error.
+//
+// Parameters:
+//   - path: Path of the YAML configuration file; empty disables MCP
+//
+// Returns:
+//   - []MCPServerConfig: Declared servers, including disabled ones
+//   - error: Any error reading or decoding the file
+func LoadMCPServers(path string) ([]MCPServerConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP config: %w", err)
+	}
+
+	var file mcpConfigFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode MCP config: %w", err)
+	}
+	return file.Servers, nil
+}
+
+// StartMCPServers launches every enabled server and discovers its tools.
+// Servers that fail to start are logged and skipped.
+//
+// Parameters:
+//   - servers: Server declarations from LoadMCPServers
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - []*MCPClient: Connected clients, to be closed on shutdown
+//   - []tools.Tool: Tools discovered across all connected servers
+func StartMCPServers(servers []MCPServerConfig, logger *logrus.Logger) ([]*MCPClient, []tools.Tool) {
+	var clients []*MCPClient
+	var mcpTools []tools.Tool
+
+	for _, server := range servers {
+		serverLogger := logger.WithField("mcpServer", server.Name)
+		if server.Enabled != nil && !*server.Enabled {
+			serverLogger.Info("MCP server disabled, skipping")
+			continue
+		}
+		if !toolNamePattern.MatchString(server.Name) || server.Command == "" {
+			serverLogger.Warn("MCP server requires a lowercase name and a command, skipping")
+			continue
+		}
+
+		client, err := NewMCPClient(server, serverLogger)
+		if err != nil {
+			serverLogger.WithError(err).Warn("Failed to start MCP server")
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), mcpStartupTimeout)
+		discovered, err := client.ListTools(ctx)
+		cancel()
+		if err != nil {
+			serverLogger.WithError(err).Warn("Failed to discover MCP tools")
+			client.Close()
+			continue
+		}
+
+		serverLogger.WithField("tools", len(discovered)).Info("MCP server connected")
+		clients = append(clients, client)
+		mcpTools = append(mcpTools, discovered...)
+	}
+	return clients, mcpTools
+}
+
+// NewMCPClient launches an MCP server and performs the initialization handshake.
+//
+// Parameters:
+//   - server: Server declaration
+//   - logger: Logger tagged with the server name
+//
+// Returns:
+//   - *MCPClient: Initialized client
+//   - error: Any error launching the process or initializing the session
+func NewMCPClient(server MCPServerConfig, logger *logrus.Entry) (*MCPClient, error) {
+	cmd := exec.Command(server.Command, server.Args...)
+	cmd.Env = os.Environ()
+	for key, value := range server.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stderr = logger.WriterLevel(logrus.DebugLevel)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start MCP server: %w", err)
+	}
+
+	client := &MCPClient{
+		name:    server.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[int64]chan *mcpResponse),
+		logger:  logger,
+	}
+	go client.readResponses(stdout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), mcpStartupTimeout)
+	defer cancel()
+
+	_, err = client.request(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]string{
+			"name":    "skynet",
+			"version": Version,
+		},
+	})
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("MCP initialize failed: %w", err)
+	}
+
+	if err := client.send(mcpRequest{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// readResponses dispatches incoming messages to waiting requests until the server exits.
+func (c *MCPClient) readResponses(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+
+	for scanner.Scan() {
+		var response mcpResponse
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			c.logger.WithError(err).Debug("Ignoring malformed MCP message")
+			continue
+		}
+		if response.ID == nil {
+			// Server notifications are not used by this client
+			continue
+		}
+
+		c.mutex.Lock()
+		waiter, exists := c.pending[*response.ID]
+		delete(c.pending, *response.ID)
+		c.mutex.Unlock()
+
+		if exists {
+			waiter <- &response
+		}
+	}
+
+	// Fail every outstanding request once the server's output closes
+	c.mutex.Lock()
+	for id, waiter := range c.pending {
+		close(waiter)
+		delete(c.pending, id)
+	}
+	c.mutex.Unlock()
+	c.logger.Warn("MCP server connection closed")
+}
+
+// send writes a single newline-delimited JSON-RPC message.
+func (c *MCPClient) send(message mcpRequest) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode MCP message: %w", err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, err := c.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to MCP server: %w", err)
+	}
+	return nil
+}
+
+// request sends a JSON-RPC request and waits for its response.
+func (c *MCPClient) request(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	waiter := make(chan *mcpResponse, 1)
+
+	c.mutex.Lock()
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = waiter
+	c.mutex.Unlock()
+
+	if err := c.send(mcpRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		c.mutex.Lock()
+		delete(c.pending, id)
+		c.mutex.Unlock()
+		return nil, err
+	}
+
+	select {
+	case response, ok := <-waiter:
+		if !ok {
+			return nil, fmt.Errorf("MCP server %s exited", c.name)
+		}
+		if response.Error != nil {
+			return nil, fmt.Errorf("MCP error %d: %s", response.Error.Code, response.Error.Message)
+		}
+		return response.Result, nil
+	case <-ctx.Done():
+		c.mutex.Lock()
+		delete(c.pending, id)
+		c.mutex.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// ListTools discovers the server's tools and wraps them for the agent.
+//
+// Parameters:
+//   - ctx: Context bounding the discovery request
+//
+// Returns:
+//   - []tools.Tool: Discovered tools named "<server>_<tool>"
+//   - error: Any error querying the server
+func (c *MCPClient) ListTools(ctx context.Context) ([]tools.Tool, error) {
+	var discovered []tools.Tool
+	var cursor string
+
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		result, err := c.request(ctx, "tools/list", params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Tools      []mcpToolInfo `json:"tools"`
+			NextCursor string        `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(result, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode MCP tool list: %w", err)
+		}
+
+		for _, info := range page.Tools {
+			discovered = append(discovered, &MCPTool{client: c, info: info})
+		}
+
+		if page.NextCursor == "" {
+			return discovered, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// Close terminates the server process.
+func (c *MCPClient) Close() {
+	c.stdin.Close()
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+	c.cmd.Wait()
+}
+
+// MCPTool exposes a single MCP server tool to the agent.
+type MCPTool struct {
+	client *MCPClient  // Connection to the owning server
+	info   mcpToolInfo // Tool metadata from tools/list
+}
+
+// Name returns the tool name prefixed with the server name.
+func (t *MCPTool) Name() string {
+	return t.client.name + "_" + t.info.Name
+}
+
+// Description returns the server-provided description and the JSON input schema.
+func (t *MCPTool) Description() string {
+	description := strings.TrimSpace(t.info.Description)
+	if len(t.info.InputSchema) > 0 {
+		description += " Input: a JSON object matching this schema: " + string(t.info.InputSchema)
+	}
+	return description
+}
+
+// InputSchema returns the JSON schema of the tool's arguments.
+func (t *MCPTool) InputSchema() json.RawMessage {
+	return t.info.InputSchema
+}
+
+// Call invokes the tool on the MCP server.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON object of tool arguments
+//
+// Returns:
+//   - string: Text content returned by the tool, or an error message
+//   - error: Always nil (errors are returned as string messages)
+func (t *MCPTool) Call(ctx context.Context, input string) (string, error) {
+	arguments := map[string]interface{}{}
+	if trimmed := strings.TrimSpace(input); trimmed != "" {
+		if err := json.Unmarshal([]byte(trimmed), &arguments); err != nil {
+			return fmt.Sprintf("Error: %s expects a JSON object as input: %v", t.Name(), err), nil
+		}
+	}
+
+	result, err := t.client.request(ctx, "tools/call", map[string]interface{}{
+		"name":      t.info.Name,
+		"arguments": arguments,
+	})
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+
+	var callResult struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if err := json.Unmarshal(result, &callResult); err != nil {
+		return fmt.Sprintf("Error: failed to decode MCP tool result: %v", err), nil
+	}
+
+	var output strings.Builder
+	for _, content := range callResult.Content {
+		if content.Type == "text" {
+			output.WriteString(content.Text)
+			output.WriteString("\n")
+		} else {
+			output.WriteString(fmt.Sprintf("[%s content omitted]\n", content.Type))
+		}
+	}
+
+	if callResult.IsError {
+		return "Error: " + output.String(), nil
+	}
+	return output.String(), nil
+}
+
+var _ tools.Tool = (*MCPTool)(nil)
@@ -9,9 +9,12 @@ Key components:
 - ChatMessage: Individual conversation messages with metadata
 - ChatSession: Complete conversation context with thread-safe operations
 - MemoryStore: Centralized session management with automatic cleanup
+- MemoryLimits: Caps on all sessions together, enforced by LRU eviction
 
 The memory system is designed for high-concurrency scenarios with proper
-locking mechanisms and automatic resource management.
+locking mechanisms and automatic resource management. Since clients can create
+sessions at will, expiry alone does not bound memory: when a new session or
+message exceeds a cap, the least recently used other sessions are evicted.
 */
 package core
 
@@ -19,10 +22,13 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"time"
 
+	localtools "skynet/tools"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -39,11 +45,37 @@ type ChatMessage struct {
 // Sessions maintain conversation history and provide thread-safe access to message
 // operations. Each session has a unique identifier and tracks its lifecycle.
 type ChatSession struct {
-	ID       string        `json:"id"`       // Unique session identifier for client reference
-	Messages []ChatMessage `json:"messages"` // Ordered list of conversation messages
-	Created  time.Time     `json:"created"`  // Session creation timestamp
-	Updated  time.Time     `json:"updated"`  // Last activity timestamp for cleanup decisions
+	ID       string        `json:"id"`               // Unique session identifier for client reference
+	Messages []ChatMessage `json:"messages"`         // Ordered list of conversation messages
+	Created  time.Time     `json:"created"`          // Session creation timestamp
+	Updated  time.Time     `json:"updated"`          // Last activity timestamp for cleanup decisions
+	Owner    string        `json:"owner,omitempty"`  // Caller that created the session; empty for sessions open to every caller
+	Tenant   string        `json:"tenant,omitempty"` // Tenant of the caller that created the session
 	mutex    sync.RWMutex  // Read-write mutex for thread-safe concurrent access
+
+	workspace *localtools.WorkspaceContext // Session working directory, created on first use
+	approvals *localtools.Approvals        // Operations waiting for the user's approval, created on first use
+	store     *MemoryStore                 // Store accounting for the session's messages
+	bytes     int64                        // Total content size of the session's messages
+	trimmed   int                          // Number of oldest messages dropped to stay within the per-session limit
+}
+
+// MessagePage is one page of a session's messages.
+type MessagePage struct {
+	Messages []ChatMessage // Copy of the page's messages in chronological order
+	Offset   int           // Position of the page's first message
+	First    int           // Position of the oldest message kept; older messages were trimmed
+	Total    int           // Number of messages added to the session, including trimmed ones
+}
+
+// MemoryLimits caps the memory held by all sessions of a store together and by
+// each session. A zero value disables the corresponding cap.
+type MemoryLimits struct {
+	MaxSessions        int   // Maximum number of sessions
+	MaxMessages        int   // Maximum number of messages across all sessions
+	MaxBytes           int64 // Maximum total message content size in bytes
+	MaxSessionMessages int   // Maximum messages per session; the oldest are dropped
+	MaxMessageBytes    int   // Maximum size of one message; longer messages keep their head and tail
 }
 
 // MemoryStore manages multiple chat sessions with automatic lifecycle management.
@@ -54,6 +86,11 @@ type MemoryStore struct {
 	mutex           sync.RWMutex            // Read-write mutex for thread-safe map operations
 	maxAge          time.Duration           // Maximum age for sessions before cleanup eligibility
 	cleanupInterval time.Duration           // How frequently to run automatic cleanup
+	limits          MemoryLimits            // Caps enforced by evicting least recently used sessions
+	redactor        *Redactor               // Masks secrets in stored messages; nil stores them unchanged
+	messages        int                     // Number of messages across all sessions
+	bytes           int64                   // Total message content size across all sessions
+	evictions       int64                   // Number of sessions evicted to stay within the limits
 	logger          *logrus.Logger          // Structured logger for operational monitoring
 }
 
@@ -63,15 +100,19 @@ type MemoryStore struct {
 // Parameters:
 //   - maxAge: Duration after which inactive sessions become eligible for cleanup
 //   - cleanupInterval: How often to run the cleanup process
+//   - redactor: Redactor masking secrets in stored messages, or nil
+//   - limits: Caps on all sessions together
 //   - logger: Logger instance for operational monitoring and debugging
 //
 // Returns:
 //   - *MemoryStore: Configured memory store ready for use
-func NewMemoryStore(maxAge time.Duration, cleanupInterval time.Duration, logger *logrus.Logger) *MemoryStore {
+func NewMemoryStore(maxAge time.Duration, cleanupInterval time.Duration, redactor *Redactor, limits MemoryLimits, logger *logrus.Logger) *MemoryStore {
 	store := &MemoryStore{
 		sessions:        make(map[string]*ChatSession),
 		maxAge:          maxAge,
 		cleanupInterval: cleanupInterval,
+		limits:          limits,
+		redactor:        redactor,
 		logger:          logger,
 	}
 
@@ -106,6 +147,22 @@ func generateSessionID() string {
 // Returns:
 //   - *ChatSession: Valid session object (existing or newly created)
 func (m *MemoryStore) GetOrCreateSession(sessionID string) *ChatSession {
+	return m.GetOrCreateOwnedSession(sessionID, "", "")
+}
+
+// GetOrCreateOwnedSession works like GetOrCreateSession, except that a session it
+// creates belongs to the given owner and tenant. Existing sessions keep their
+// owner and tenant, which never change, so callers must check them before using
+// the session.
+//
+// Parameters:
+//   - sessionID: Existing session ID, or empty string to create new session
+//   - owner: Owner of a new session; empty leaves it open to every caller
+//   - tenant: Tenant of a new session; empty for none
+//
+// Returns:
+//   - *ChatSession: Valid session object (existing or newly created)
+func (m *MemoryStore) GetOrCreateOwnedSession(sessionID, owner, tenant string) *ChatSession {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -122,9 +179,17 @@ func (m *MemoryStore) GetOrCreateSession(sessionID string) *ChatSession {
 			Messages: make([]ChatMessage, 0),
 			Created:  time.Now(),
 			Updated:  time.Now(),
+			Owner:    owner,
+			Tenant:   tenant,
+			store:    m,
 		}
 		m.sessions[sessionID] = session
-		m.logger.WithField("sessionID", sessionID).Info("Created new chat session")
+		m.logger.WithFields(logrus.Fields{
+			"sessionID": sessionID,
+			"owner":     owner,
+			"tenant":    tenant,
+		}).Info("Created new chat session")
+		m.evictLocked(session)
 	} else {
 		// Update access time for existing session
 		session.Updated = time.Now()
@@ -170,7 +235,7 @@ func (m *MemoryStore) DeleteSession(sessionID string) bool {
 
 	_, exists := m.sessions[sessionID]
 	if exists {
-		delete(m.sessions, sessionID)
+		m.removeLocked(sessionID)
 		m.logger.WithField("sessionID", sessionID).Info("Session deleted")
 	}
 	return exists
@@ -201,9 +266,16 @@ func (m *MemoryStore) GetAllSessions() []*ChatSession {
 //   - role: The message sender ("user" or "assistant")
 //   - content: The message text content
 func (s *ChatSession) AddMessage(role, content string) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	var limits MemoryLimits
+	if s.store != nil {
+		limits = s.store.limits
+		content = s.store.redactor.Redact(content)
+	}
+	if limits.MaxMessageBytes > 0 && len(content) > limits.MaxMessageBytes {
+		content = truncateMessage(content, limits.MaxMessageBytes)
+	}
 
+	s.mutex.Lock()
 	message := ChatMessage{
 		Role:      role,
 		Content:   content,
@@ -211,7 +283,34 @@ func (s *ChatSession) AddMessage(role, content string) {
 	}
 
 	s.Messages = append(s.Messages, message)
+	count, size := 1, int64(len(content))
+
+	// Drop the oldest messages beyond the per-session limit
+	if excess := len(s.Messages) - limits.MaxSessionMessages; limits.MaxSessionMessages > 0 && excess > 0 {
+		for _, dropped := range s.Messages[:excess] {
+			size -= int64(len(dropped.Content))
+		}
+		s.Messages = append([]ChatMessage(nil), s.Messages[excess:]...)
+		s.trimmed += excess
+		count -= excess
+	}
+	s.bytes += size
 	s.Updated = time.Now()
+	s.mutex.Unlock()
+
+	// The store is updated without the session lock, which it takes while evicting
+	if s.store != nil {
+		s.store.accountMessages(s, count, size)
+	}
+}
+
+// truncateMessage keeps the head and tail of a message within maxSize bytes.
+// Cut points are trimmed to valid UTF-8 so multi-byte characters are never split.
+func truncateMessage(content string, maxSize int) string {
+	half := maxSize / 2
+	head := strings.ToValidUTF8(content[:half], "")
+	tail := strings.ToValidUTF8(content[len(content)-half:], "")
+	return head + fmt.Sprintf("\n\n[... %d bytes of this message omitted ...]\n\n", len(content)-2*half) + tail
 }
 
 // GetRecentMessages returns the most recent messages up to a specified limit.
@@ -235,6 +334,79 @@ func (s *ChatSession) GetRecentMessages(limit int) []ChatMessage {
 	return s.Messages[len(s.Messages)-limit:]
 }
 
+// GetMessagePage returns one page of the session's messages. Messages are
+// addressed by their position in the session, which stays stable as messages
+// are appended and the oldest are trimmed, so positions serve as pagination cursors.
+//
+// Parameters:
+//   - before: Return the last messages before this position; -1 for none
+//   - after: Return the first messages from this position on; -1 for none
+//   - limit: Maximum number of messages to return
+//
+// Returns:
+//   - MessagePage: The page with its position and the session's range of positions
+func (s *ChatSession) GetMessagePage(before, after, limit int) MessagePage {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	// Work with indexes into the kept messages
+	count := len(s.Messages)
+	start, end := 0, count
+	switch {
+	case after >= 0:
+		start = min(max(after-s.trimmed, 0), count)
+		end = min(start+limit, count)
+	case before >= 0:
+		end = min(max(before-s.trimmed, 0), count)
+		start = max(end-limit, 0)
+	default:
+		// Without a cursor the page holds the most recent messages
+		start = max(count-limit, 0)
+	}
+
+	page := MessagePage{
+		Messages: make([]ChatMessage, end-start),
+		Offset:   s.trimmed + start,
+		First:    s.trimmed,
+		Total:    s.trimmed + count,
+	}
+	copy(page.Messages, s.Messages[start:end])
+	return page
+}
+
+// Workspace returns the session's own workspace so directory changes made in this
+// conversation do not affect other sessions. It is created on first use.
+//
+// Parameters:
+//   - root: Initial working directory of a newly created workspace
+//
+// Returns:
+//   - *localtools.WorkspaceContext: The session workspace
+func (s *ChatSession) Workspace(root string) *localtools.WorkspaceContext {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.workspace == nil {
+		s.workspace = localtools.NewWorkspaceContext(root)
+	}
+	return s.workspace
+}
+
+// Approvals returns the session's approval requests, which tools record for
+// operations that need the user's decision. They are created on first use.
+//
+// Returns:
+//   - *localtools.Approvals: The session approvals
+func (s *ChatSession) Approvals() *localtools.Approvals {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.approvals == nil {
+		s.approvals = localtools.NewApprovals()
+	}
+	return s.approvals
+}
+
 // ClearMessages removes all messages from the session.
 // This method provides a way to reset conversation context while
 // maintaining the session identity. Returns the count of cleared messages for logging.
@@ -243,11 +415,17 @@ func (s *ChatSession) GetRecentMessages(limit int) []ChatMessage {
 //   - int: Number of messages that were cleared
 func (s *ChatSession) ClearMessages() int {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
 	messageCount := len(s.Messages)
+	clearedBytes := s.bytes
 	s.Messages = make([]ChatMessage, 0)
+	s.bytes = 0
+	s.trimmed = 0
 	s.Updated = time.Now()
+	s.mutex.Unlock()
+
+	if s.store != nil {
+		s.store.accountMessages(s, -messageCount, -clearedBytes)
+	}
 	return messageCount
 }
 
@@ -304,7 +482,7 @@ func (m *MemoryStore) cleanupExpiredSessions() {
 
 		// Remove expired sessions from the store
 		for _, id := range expired {
-			delete(m.sessions, id)
+			m.removeLocked(id)
 		}
 
 		// Log cleanup results for operational monitoring
@@ -339,7 +517,123 @@ func (m *MemoryStore) GetSessionStats() map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"totalSessions": len(m.sessions),
-		"totalMessages": totalMessages,
+		"totalSessions":   len(m.sessions),
+		"totalMessages":   totalMessages,
+		"totalBytes":      m.bytes,
+		"evictedSessions": m.evictions,
+	}
+}
+
+// accountMessages records messages added to or removed from a session and evicts
+// other sessions when the store exceeds its limits. Sessions that were already
+// removed from the store, such as an evicted session still in use by a running
+// execution, are not counted.
+//
+// Parameters:
+//   - session: Session whose messages changed
+//   - count: Change in the number of messages
+//   - size: Change in the total content size in bytes
+func (m *MemoryStore) accountMessages(session *ChatSession, count int, size int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.sessions[session.ID] != session {
+		return
+	}
+	m.messages += count
+	m.bytes += size
+	if count > 0 || size > 0 {
+		m.evictLocked(session)
+	}
+}
+
+// overLimitsLocked reports which limit the store exceeds, or "" when it is within
+// all of them. The caller must hold the store lock.
+func (m *MemoryStore) overLimitsLocked() string {
+	switch {
+	case m.limits.MaxSessions > 0 && len(m.sessions) > m.limits.MaxSessions:
+		return "sessions"
+	case m.limits.MaxMessages > 0 && m.messages > m.limits.MaxMessages:
+		return "messages"
+	case m.limits.MaxBytes > 0 && m.bytes > m.limits.MaxBytes:
+		return "bytes"
+	default:
+		return ""
+	}
+}
+
+// evictLocked removes least recently used sessions until the store is within its
+// limits. The session being used is kept even if it alone exceeds a limit. The
+// caller must hold the store lock.
+func (m *MemoryStore) evictLocked(keep *ChatSession) {
+	for reason := m.overLimitsLocked(); reason != ""; reason = m.overLimitsLocked() {
+		var oldestID string
+		var oldest time.Time
+		for id, session := range m.sessions {
+			if session == keep {
+				continue
+			}
+			session.mutex.RLock()
+			updated := session.Updated
+			session.mutex.RUnlock()
+			if oldestID == "" || updated.Before(oldest) {
+				oldestID, oldest = id, updated
+			}
+		}
+		if oldestID == "" {
+			return
+		}
+
+		m.removeLocked(oldestID)
+		m.evictions++
+		m.logger.WithFields(logrus.Fields{
+			"sessionID":    oldestID,
+			"limit":        reason,
+			"lastActivity": oldest,
+		}).Warn("Evicted least recently used chat session to stay within memory limits")
+	}
+}
+
+// removeLocked deletes a session and its messages from the store's accounting.
+// The caller must hold the store lock.
+func (m *MemoryStore) removeLocked(sessionID string) {
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return
 	}
+	session.mutex.RLock()
+	m.messages -= len(session.Messages)
+	m.bytes -= session.bytes
+	session.mutex.RUnlock()
+	delete(m.sessions, sessionID)
+}
+
+// WritePrometheus writes session gauges and the eviction counter in the
+// Prometheus text exposition format.
+//
+// Parameters:
+//   - w: Destination of the metrics
+//
+// Returns:
+//   - error: Write failure
+func (m *MemoryStore) WritePrometheus(w io.Writer) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP skynet_sessions Number of chat sessions in memory.\n")
+	b.WriteString("# TYPE skynet_sessions gauge\n")
+	fmt.Fprintf(&b, "skynet_sessions %d\n", len(m.sessions))
+	b.WriteString("# HELP skynet_session_messages Number of messages across all chat sessions.\n")
+	b.WriteString("# TYPE skynet_session_messages gauge\n")
+	fmt.Fprintf(&b, "skynet_session_messages %d\n", m.messages)
+	b.WriteString("# HELP skynet_session_bytes Total message content size across all chat sessions.\n")
+	b.WriteString("# TYPE skynet_session_bytes gauge\n")
+	fmt.Fprintf(&b, "skynet_session_bytes %d\n", m.bytes)
+	b.WriteString("# HELP skynet_session_evictions_total Total number of chat sessions evicted to stay within memory limits.\n")
+	b.WriteString("# TYPE skynet_session_evictions_total counter\n")
+	fmt.Fprintf(&b, "skynet_session_evictions_total %d\n", m.evictions)
+
+	_, err := io.WriteString(w, b.String())
+	return err
 }
@@ -0,0 +1,418 @@
+/*
+Package core provides the MQTT integration for the Skynet Agent application.
+
+Edge devices behind NAT often cannot accept HTTP connections. With MQTT_BROKER_URL
+set, the agent connects out to an MQTT broker, subscribes to MQTT_COMMAND_TOPIC,
+and runs every message published there as a chat request. Its progress and
+answer are published to MQTT_RESULT_TOPIC, so a fleet can be administered
+through the broker alone.
+
+A command is a JSON object:
+
+	{"id": "cmd-42", "sessionId": "edge-7", "message": "How full is the disk?"}
+
+id is echoed in every result message so callers can match results to their
+commands, and sessionId continues a conversation; both are optional. A payload
+that is not a JSON object is taken as the message itself, in the "mqtt" session.
+Results are StreamMessage objects carrying the command's id and sessionId: "queued"
+while the command waits, "tool" for every tool the agent runs, then a "response"
+or "error" message with complete set.
+
+The client speaks MQTT 3.1.1 itself, since no MQTT module is a dependency of the
+project. Commands are received with QoS 1 and results are published with QoS 0.
+*/
+package core
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// mqttKeepAlive is the keep alive interval announced to the broker
+const mqttKeepAlive = 60 * time.Second
+
+// mqttRetryDelay is the pause after a lost or failed connection before reconnecting
+const mqttRetryDelay = 5 * time.Second
+
+// MQTT control packet types, in the upper four bits of the fixed header
+const (
+	mqttConnect    = 1
+	mqttConnAck    = 2
+	mqttPublish    = 3
+	mqttPubAck     = 4
+	mqttSubscribe  = 8
+	mqttSubAck     = 9
+	mqttPingReq    = 12
+	mqttPingResp   = 13
+	mqttDisconnect = 14
+)
+
+// mqttCommand is a chat request received on the command topic.
+type mqttCommand struct {
+	ID        string `json:"id"`
+	SessionID string `json:"sessionId"`
+	Message   string `json:"message"`
+}
+
+// mqttResult is a progress or answer message published to the result topic.
+type mqttResult struct {
+	ID        string `json:"id,omitempty"`
+	SessionID string `json:"sessionId"`
+	StreamMessage
+}
+
+// mqttConn is a connection to an MQTT broker.
+type mqttConn struct {
+	conn       net.Conn
+	reader     *bufio.Reader
+	writeMutex sync.Mutex // Serializes packets written by the command goroutines
+}
+
+// dialMQTT connects to the broker at brokerURL (tcp://, mqtt://, ssl://, tls://, or
+// mqtts://) and sends the CONNECT packet.
+func dialMQTT(ctx context.Context, brokerURL, clientID, username, password string) (*mqttConn, error) {
+	parsed, err := url.Parse(brokerURL)
+	if err != nil {
+		return nil, err
+	}
+	secure := false
+	switch parsed.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		secure = true
+	default:
+		return nil, fmt.Errorf("unsupported MQTT broker scheme %q", parsed.Scheme)
+	}
+	address := parsed.Host
+	if parsed.Port() == "" {
+		port := "1883"
+		if secure {
+			port = "8883"
+		}
+		address = net.JoinHostPort(parsed.Hostname(), port)
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	if secure {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: parsed.Hostname()}}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	mc := &mqttConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	// Variable header: protocol name, level 4 (3.1.1), flags, keep alive; clean session
+	flags := byte(0x02)
+	payload := mqttString(clientID)
+	if username != "" {
+		flags |= 0x80
+		payload = append(payload, mqttString(username)...)
+	}
+	if password != "" {
+		flags |= 0x40
+		payload = append(payload, mqttString(password)...)
+	}
+	body := append(mqttString("MQTT"), 4, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(mqttKeepAlive/time.Second))
+	body = append(body, payload...)
+
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+	if err := mc.write(mqttConnect<<4, body); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	packetType, response, err := mc.read()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if packetType>>4 != mqttConnAck || len(response) != 2 {
+		conn.Close()
+		return nil, errors.New("MQTT broker did not acknowledge the connection")
+	}
+	if response[1] != 0 {
+		conn.Close()
+		return nil, fmt.Errorf("MQTT broker refused the connection with return code %d", response[1])
+	}
+	conn.SetDeadline(time.Time{})
+	return mc, nil
+}
+
+// mqttString encodes a length-prefixed UTF-8 string.
+func mqttString(s string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(s))), s...)
+}
+
+// write sends a control packet with the given first header byte and body.
+func (mc *mqttConn) write(header byte, body []byte) error {
+	packet := []byte{header}
+	// Remaining length: seven bits per byte, least significant first
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			break
+		}
+	}
+	packet = append(packet, body...)
+
+	mc.writeMutex.Lock()
+	defer mc.writeMutex.Unlock()
+	_, err := mc.conn.Write(packet)
+	return err
+}
+
+// read receives the next control packet and returns its first header byte and body.
+func (mc *mqttConn) read() (byte, []byte, error) {
+	header, err := mc.reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		digit, err := mc.reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		if i == 3 && digit&0x80 != 0 {
+			return 0, nil, errors.New("malformed MQTT remaining length")
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(mc.reader, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// subscribe subscribes to a topic with QoS 1. The SUBACK is handled by the read loop.
+func (mc *mqttConn) subscribe(topic string) error {
+	body := binary.BigEndian.AppendUint16(nil, 1)
+	body = append(body, mqttString(topic)...)
+	body = append(body, 1)
+	return mc.write(mqttSubscribe<<4|0x02, body)
+}
+
+// publish publishes a message with QoS 0.
+func (mc *mqttConn) publish(topic string, payload []byte) error {
+	return mc.write(mqttPublish<<4, append(mqttString(topic), payload...))
+}
+
+// StartMQTT connects to the MQTT broker in the background when MQTT_BROKER_URL is
+// set, reconnecting whenever the connection is lost. The client stops when the
+// server is closed.
+func (s *Server) StartMQTT() {
+	config := s.currentConfig()
+	if config.MQTTBrokerURL == "" {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopMQTT = cancel
+	go s.runMQTT(ctx, config)
+}
+
+// runMQTT keeps a broker connection open and handles its commands until ctx ends.
+func (s *Server) runMQTT(ctx context.Context, config *Config) {
+	mqttLogger := s.logger.WithFields(logrus.Fields{
+		"component": "mqtt",
+		"broker":    config.MQTTBrokerURL,
+	})
+	for ctx.Err() == nil {
+		err := s.serveMQTT(ctx, config, mqttLogger)
+		if ctx.Err() != nil {
+			return
+		}
+		mqttLogger.WithError(err).Warn("MQTT connection lost, reconnecting")
+		sleepContext(ctx, mqttRetryDelay)
+	}
+}
+
+// serveMQTT connects to the broker, subscribes to the command topic, and runs the
+// commands it receives until the connection fails or ctx ends.
+func (s *Server) serveMQTT(ctx context.Context, config *Config, mqttLogger *logrus.Entry) error {
+	mc, err := dialMQTT(ctx, config.MQTTBrokerURL, config.MQTTClientID, config.MQTTUsername, config.MQTTPassword)
+	if err != nil {
+		return err
+	}
+	defer mc.conn.Close()
+	if err := mc.subscribe(config.MQTTCommandTopic); err != nil {
+		return err
+	}
+	mqttLogger.WithFields(logrus.Fields{
+		"commandTopic": config.MQTTCommandTopic,
+		"resultTopic":  config.MQTTResultTopic,
+	}).Info("Connected to MQTT broker")
+
+	// Keep the connection alive, and close it when the server stops
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(mqttKeepAlive / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				mc.write(mqttDisconnect<<4, nil)
+				mc.conn.Close()
+				return
+			case <-ticker.C:
+				if err := mc.write(mqttPingReq<<4, nil); err != nil {
+					mc.conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		// The broker answers pings, so a silent connection is dead
+		mc.conn.SetReadDeadline(time.Now().Add(mqttKeepAlive * 3 / 2))
+		header, body, err := mc.read()
+		if err != nil {
+			return err
+		}
+		switch header >> 4 {
+		case mqttSubAck:
+			if len(body) == 3 && body[2] == 0x80 {
+				return fmt.Errorf("MQTT broker refused the subscription to %s", config.MQTTCommandTopic)
+			}
+		case mqttPublish:
+			qos := (header >> 1) & 0x03
+			if len(body) < 2 {
+				return errors.New("malformed MQTT PUBLISH packet")
+			}
+			topicLength := int(binary.BigEndian.Uint16(body))
+			offset := 2 + topicLength
+			if qos > 0 {
+				offset += 2
+			}
+			if offset > len(body) {
+				return errors.New("malformed MQTT PUBLISH packet")
+			}
+			if qos > 0 {
+				if err := mc.write(mqttPubAck<<4, body[offset-2:offset]); err != nil {
+					return err
+				}
+			}
+			s.handleMQTTCommand(ensureRequestID(ctx), mc, config.MQTTResultTopic, body[offset:], mqttLogger)
+		case mqttPingResp:
+		}
+	}
+}
+
+// handleMQTTCommand parses a command and runs it in the background, publishing its
+// progress and answer to the result topic.
+func (s *Server) handleMQTTCommand(ctx context.Context, mc *mqttConn, resultTopic string, payload []byte, mqttLogger *logrus.Entry) {
+	var command mqttCommand
+	trimmed := strings.TrimSpace(string(payload))
+	if !strings.HasPrefix(trimmed, "{") || json.Unmarshal(payload, &command) != nil {
+		command = mqttCommand{Message: trimmed}
+	}
+	if command.SessionID == "" {
+		command.SessionID = "mqtt"
+	}
+	commandLogger := mqttLogger.WithFields(logrus.Fields{
+		"requestId": localtools.RequestIDFromContext(ctx),
+		"commandId": command.ID,
+		"sessionID": command.SessionID,
+	})
+
+	publish := func(msg StreamMessage) {
+		result, err := json.Marshal(mqttResult{ID: command.ID, SessionID: command.SessionID, StreamMessage: msg})
+		if err != nil {
+			return
+		}
+		if err := mc.publish(resultTopic, result); err != nil {
+			commandLogger.WithError(err).Warn("Failed to publish MQTT result")
+		}
+	}
+	if command.Message == "" {
+		publish(StreamMessage{Type: "error", Content: "Message is required", Complete: true})
+		return
+	}
+
+	ticket, err := s.queue.Enqueue()
+	if err != nil {
+		commandLogger.Warn("Request queue is full, rejecting MQTT command")
+		publish(StreamMessage{Type: "error", Content: "The server is busy, please try again later.", Complete: true})
+		return
+	}
+	commandLogger.Info("Accepted MQTT command")
+
+	go func() {
+		defer ticket.Release()
+		if err := ticket.Wait(ctx, func(position int, eta time.Duration) {
+			publish(StreamMessage{
+				Type:    "queued",
+				Content: fmt.Sprintf("Waiting in queue (position %d, about %s)", position, eta.Round(time.Second)),
+				Details: map[string]interface{}{
+					"position":   position,
+					"etaSeconds": int(eta.Seconds()),
+				},
+			})
+		}); err != nil {
+			return
+		}
+
+		progress := &mqttProgressHandler{
+			VerboseCallbackHandler: NewVerboseCallbackHandler(commandLogger.WithField("component", "mqtt_agent"), s.currentConfig()),
+			publish:                publish,
+		}
+		response, err := s.chat(WithCallbackHandler(ctx, progress), command.SessionID, command.Message, "mqtt", commandLogger)
+		msg := StreamMessage{Type: "response", Content: response.Response, Complete: true, Details: map[string]interface{}{"executionId": response.ExecutionID}}
+		if err != nil {
+			msg.Type = "error"
+		}
+		publish(msg)
+	}()
+}
+
+// mqttProgressHandler publishes the tools the agent runs to the result topic.
+type mqttProgressHandler struct {
+	*VerboseCallbackHandler
+	publish func(msg StreamMessage)
+}
+
+func (h *mqttProgressHandler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
+	h.VerboseCallbackHandler.HandleAgentAction(ctx, action)
+	h.publish(StreamMessage{
+		Type:    "tool",
+		Tool:    action.Tool,
+		Content: action.ToolInput,
+	})
+}
+
+// Ensure mqttProgressHandler implements the callbacks.Handler interface
+var _ callbacks.Handler = (*mqttProgressHandler)(nil)
@@ -0,0 +1,490 @@
+/*
+Package core provides outbound notifications for the Skynet Agent application.
+
+A Notifier delivers a Notification to one sink: a webhook, a Slack channel, email
+recipients, or a Telegram chat. Sinks are declared in a YAML file, and any number
+of them can be active at once, each subscribed to its own events:
+
+	notifiers:
+	  - name: ops-webhook
+	    type: webhook
+	    url: https://hooks.example.com/skynet
+	    headers:
+	      Authorization: Bearer example
+	    events: [failure, report]
+	  - name: oncall
+	    type: slack
+	    channel: C0123456789
+	  - name: team
+	    type: email
+	    recipients: [ops@example.com]
+	    events: [failure, success, report]
+	  - name: phone
+	    type: telegram
+	    token: "123456:ABC-example"
+	    chat_id: "-1001234567890"
+	    events: [failure]
+
+Events are failure (failed executions), success (successful executions),
+report (the results of background tasks such as alert investigations, marked
+with WithNotificationReport), and security (lockouts of clients that repeatedly
+failed to authenticate); sinks without events receive all but successes.
+Slack sinks post with SLACK_BOT_TOKEN unless they set their own token, and email
+sinks send through the SMTP_* server. EMAIL_RECIPIENTS adds an email sink named
+"email" subscribed to EMAIL_NOTIFY_ON.
+
+Notifications are delivered in the background; failed deliveries are logged as
+warnings, and Close waits briefly for deliveries still in flight.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// notificationTimeout bounds delivering one notification to one sink
+const notificationTimeout = 30 * time.Second
+
+// telegramAPIURL is the base URL of the Telegram Bot API
+const telegramAPIURL = "https://api.telegram.org"
+
+// Notification events sinks can subscribe to
+const (
+	NotifyOnFailure  = "failure"  // Failed executions
+	NotifyOnSuccess  = "success"  // Successful executions
+	NotifyOnReport   = "report"   // Results of background tasks, successful or not
+	NotifyOnSecurity = "security" // Security events such as authentication lockouts
+)
+
+// defaultNotifyOn are the events of sinks that do not list their own
+var defaultNotifyOn = []string{NotifyOnFailure, NotifyOnReport, NotifyOnSecurity}
+
+// validNotifyOn reports whether an event is one sinks can subscribe to.
+func validNotifyOn(event string) bool {
+	switch event {
+	case NotifyOnFailure, NotifyOnSuccess, NotifyOnReport, NotifyOnSecurity:
+		return true
+	}
+	return false
+}
+
+// Notification is an event delivered to the notification sinks.
+type Notification struct {
+	Kind        string        `json:"kind"`            // failure, success, report, or security
+	Status      string        `json:"status"`          // succeeded or failed; triggered for security events
+	Title       string        `json:"title"`           // What ran: "Execution" or the title of the report or security event
+	Host        string        `json:"host"`            // Hostname of the server
+	SessionID   string        `json:"sessionId"`       // Session of the execution
+	ExecutionID string        `json:"executionId"`     // Execution whose timeline GET /executions/:id/trace returns
+	RequestID   string        `json:"requestId"`       // Request that started the execution
+	User        string        `json:"user"`            // Requesting user
+	Duration    time.Duration `json:"-"`               // Execution time
+	Message     string        `json:"message"`         // Message sent to the agent
+	Response    string        `json:"response"`        // Answer of the agent
+	Error       string        `json:"error,omitempty"` // Why the execution failed
+}
+
+// Notifier delivers notifications to one sink.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// NotifierConfig declares a notification sink.
+type NotifierConfig struct {
+	Name       string            `yaml:"name"`       // Sink name, used in logs
+	Type       string            `yaml:"type"`       // webhook, slack, email, or telegram
+	Events     []string          `yaml:"events"`     // Events delivered to the sink (default: failure, report, security)
+	URL        string            `yaml:"url"`        // Webhook URL
+	Headers    map[string]string `yaml:"headers"`    // Extra webhook request headers
+	Channel    string            `yaml:"channel"`    // Slack channel ID
+	Token      string            `yaml:"token"`      // Slack bot token (default: SLACK_BOT_TOKEN) or Telegram bot token
+	ChatID     string            `yaml:"chat_id"`    // Telegram chat ID
+	Recipients []string          `yaml:"recipients"` // Email recipients
+	Subject    string            `yaml:"subject"`    // Email subject template (default: EMAIL_SUBJECT)
+}
+
+// notifiersFile is the top-level structure of the notifiers file.
+type notifiersFile struct {
+	Notifiers []NotifierConfig `yaml:"notifiers"`
+}
+
+// notificationReportKey is the unexported context key type for report titles
+type notificationReportKey struct{}
+
+// WithNotificationReport returns a copy of the parent context marking the execution
+// as a background task whose result is delivered as a report with the given title.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - title: Title of the report, e.g. "Alert HighLoad investigation"
+//
+// Returns:
+//   - context.Context: Derived context carrying the report title
+func WithNotificationReport(ctx context.Context, title string) context.Context {
+	return context.WithValue(ctx, notificationReportKey{}, title)
+}
+
+// LoadNotifiers reads the notifiers file. A missing file is not an error.
+//
+// Parameters:
+//   - path: Path of the YAML notifiers file; empty disables it
+//
+// Returns:
+//   - []NotifierConfig: Declared sinks
+//   - error: Any error reading or decoding the file
+func LoadNotifiers(path string) ([]NotifierConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notifiers file: %w", err)
+	}
+
+	var file notifiersFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode notifiers file: %w", err)
+	}
+	return file.Notifiers, nil
+}
+
+// NewNotifier creates the notifier of a sink declaration.
+//
+// Parameters:
+//   - declaration: Sink declaration
+//   - config: Configuration providing the Slack token and SMTP server
+//
+// Returns:
+//   - Notifier: Notifier for the sink
+//   - error: Incomplete or unsupported declaration
+func NewNotifier(declaration NotifierConfig, config *Config) (Notifier, error) {
+	switch declaration.Type {
+	case "webhook":
+		if declaration.URL == "" {
+			return nil, errors.New("webhook notifiers require url")
+		}
+		return &webhookNotifier{url: declaration.URL, headers: declaration.Headers, client: &http.Client{}}, nil
+	case "slack":
+		token := declaration.Token
+		if token == "" {
+			token = config.SlackBotToken
+		}
+		if token == "" || declaration.Channel == "" {
+			return nil, errors.New("slack notifiers require channel and token or SLACK_BOT_TOKEN")
+		}
+		return &slackNotifier{slack: newSlackClient(token), channel: declaration.Channel}, nil
+	case "email":
+		subject := declaration.Subject
+		if subject == "" {
+			subject = config.EmailSubject
+		}
+		return newEmailNotifier(config, declaration.Recipients, subject)
+	case "telegram":
+		if declaration.Token == "" || declaration.ChatID == "" {
+			return nil, errors.New("telegram notifiers require token and chat_id")
+		}
+		return &telegramNotifier{token: declaration.Token, chatID: declaration.ChatID, client: &http.Client{}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported notifier type %q: use webhook, slack, email, or telegram", declaration.Type)
+	}
+}
+
+// notificationSink is an active sink with the events it subscribed to.
+type notificationSink struct {
+	name     string
+	events   map[string]bool
+	notifier Notifier
+}
+
+// Notifications delivers notifications to every sink subscribed to their event. A
+// nil Notifications ignores all notifications, so callers do not need to check
+// whether any sink is configured.
+type Notifications struct {
+	sinks   []notificationSink
+	host    string
+	logger  *logrus.Logger
+	pending sync.WaitGroup // Deliveries in flight
+}
+
+// NewNotifications creates the sinks declared in the notifiers file and the email
+// sink of EMAIL_RECIPIENTS.
+//
+// Parameters:
+//   - config: Configuration providing the notifiers file and sink settings
+//   - logger: Logger for delivery failures
+//
+// Returns:
+//   - *Notifications: Dispatcher, or nil when no sink is configured
+//   - error: Unreadable notifiers file or invalid sink declaration
+func NewNotifications(config *Config, logger *logrus.Logger) (*Notifications, error) {
+	declarations, err := LoadNotifiers(config.NotifiersFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.EmailRecipients) > 0 {
+		declarations = append(declarations, NotifierConfig{
+			Name:       "email",
+			Type:       "email",
+			Events:     config.EmailNotifyOn,
+			Recipients: config.EmailRecipients,
+		})
+	}
+	if len(declarations) == 0 {
+		return nil, nil
+	}
+
+	hostname, _ := os.Hostname()
+	notifications := &Notifications{host: hostname, logger: logger}
+	for i, declaration := range declarations {
+		if declaration.Name == "" {
+			declaration.Name = fmt.Sprintf("%s-%d", declaration.Type, i+1)
+		}
+		notifier, err := NewNotifier(declaration, config)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %q: %w", declaration.Name, err)
+		}
+
+		events := declaration.Events
+		if len(events) == 0 {
+			events = defaultNotifyOn
+		}
+		sink := notificationSink{name: declaration.Name, events: make(map[string]bool), notifier: notifier}
+		for _, event := range events {
+			event = strings.ToLower(strings.TrimSpace(event))
+			if !validNotifyOn(event) {
+				return nil, fmt.Errorf("notifier %q: unsupported event %q: use failure, success, report, or security", declaration.Name, event)
+			}
+			sink.events[event] = true
+		}
+		notifications.sinks = append(notifications.sinks, sink)
+
+		logger.WithFields(logrus.Fields{
+			"notifier": declaration.Name,
+			"type":     declaration.Type,
+			"events":   events,
+		}).Info("Notification sink enabled")
+	}
+	return notifications, nil
+}
+
+// NotifyExecution delivers the result of an execution to the sinks subscribed to
+// it. Executions marked with WithNotificationReport are delivered as reports, and
+// cancelled executions are ignored.
+//
+// Parameters:
+//   - ctx: Execution context providing the request and execution details
+//   - message: Message sent to the agent
+//   - response: Answer of the agent
+//   - duration: Execution time
+//   - err: Execution error, nil on success
+func (n *Notifications) NotifyExecution(ctx context.Context, message, response string, duration time.Duration, err error) {
+	if n == nil || errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+
+	notification := Notification{
+		Kind:      NotifyOnSuccess,
+		Status:    "succeeded",
+		Title:     "Execution",
+		RequestID: localtools.RequestIDFromContext(ctx),
+		Duration:  duration.Round(time.Millisecond),
+		Message:   message,
+		Response:  response,
+	}
+	if err != nil {
+		notification.Kind, notification.Status, notification.Error = NotifyOnFailure, "failed", err.Error()
+	}
+	if title, ok := ctx.Value(notificationReportKey{}).(string); ok {
+		notification.Kind, notification.Title = NotifyOnReport, title
+	}
+	if info, ok := ExecutionInfoFromContext(ctx); ok {
+		notification.SessionID = info.SessionID
+		notification.ExecutionID = info.ExecutionID
+		notification.User = info.User
+	}
+	n.Notify(notification)
+}
+
+// Notify delivers a notification in the background to the sinks subscribed to its kind.
+func (n *Notifications) Notify(notification Notification) {
+	if n == nil {
+		return
+	}
+	notification.Host = n.host
+	for _, sink := range n.sinks {
+		if !sink.events[notification.Kind] {
+			continue
+		}
+		n.pending.Add(1)
+		go func(sink notificationSink) {
+			defer n.pending.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+			defer cancel()
+			if err := sink.notifier.Notify(ctx, notification); err != nil {
+				n.logger.WithError(err).WithFields(logrus.Fields{
+					"notifier":    sink.name,
+					"executionID": notification.ExecutionID,
+				}).Warn("Failed to deliver notification")
+			}
+		}(sink)
+	}
+}
+
+// Close waits up to the given timeout for deliveries still in flight.
+func (n *Notifications) Close(timeout time.Duration) {
+	if n == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		n.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// notificationText renders a notification as plain text, cutting the message and
+// the answer to the given number of bytes each.
+func notificationText(notification Notification, limit int) string {
+	truncate := func(text string) string {
+		if len(text) > limit {
+			return text[:limit] + "..."
+		}
+		return text
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s %s on %s.\n\n", notification.Title, notification.Status, notification.Host)
+	// Security events are not about an execution
+	if notification.Kind != NotifyOnSecurity {
+		fmt.Fprintf(&text, "Session:   %s\n", notification.SessionID)
+		fmt.Fprintf(&text, "Execution: %s\n", notification.ExecutionID)
+		if notification.RequestID != "" {
+			fmt.Fprintf(&text, "Request:   %s\n", notification.RequestID)
+		}
+		fmt.Fprintf(&text, "User:      %s\n", notification.User)
+		fmt.Fprintf(&text, "Duration:  %s\n", notification.Duration)
+	}
+	fmt.Fprintf(&text, "\nMessage:\n%s\n", truncate(notification.Message))
+	if notification.Error != "" {
+		fmt.Fprintf(&text, "\nError:\n%s\n", truncate(notification.Error))
+	} else {
+		fmt.Fprintf(&text, "\nResponse:\n%s\n", truncate(notification.Response))
+	}
+	return text.String()
+}
+
+// webhookNotifier posts notifications as JSON.
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	payload, err := json.Marshal(struct {
+		Notification
+		DurationMs int64 `json:"durationMs"`
+	}{notification, notification.Duration.Milliseconds()})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// slackNotifier posts notifications to a Slack channel.
+type slackNotifier struct {
+	slack   *slackClient
+	channel string
+}
+
+func (s *slackNotifier) Notify(ctx context.Context, notification Notification) error {
+	return s.slack.postMessage(ctx, s.channel, "", "```"+notificationText(notification, 1000)+"```", nil)
+}
+
+// telegramNotifier sends notifications to a Telegram chat with a bot.
+type telegramNotifier struct {
+	token  string
+	chatID string
+	client *http.Client
+}
+
+func (t *telegramNotifier) Notify(ctx context.Context, notification Notification) error {
+	// Telegram messages are limited to 4096 characters
+	payload, err := json.Marshal(map[string]string{
+		"chat_id": t.chatID,
+		"text":    notificationText(notification, 1500),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/sendMessage", telegramAPIURL, t.token), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram: %s", resp.Status)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram: %s", result.Description)
+	}
+	return nil
+}
+
+// Ensure the sinks implement the Notifier interface
+var (
+	_ Notifier = (*webhookNotifier)(nil)
+	_ Notifier = (*slackNotifier)(nil)
+	_ Notifier = (*emailNotifier)(nil)
+	_ Notifier = (*telegramNotifier)(nil)
+)
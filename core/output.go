@@ -0,0 +1,305 @@
+/*
+Package core provides tool output truncation and paging for the Skynet Agent application.
+
+Large tool outputs (a huge log file, ps aux on a busy host) can overflow the
+LLM context window. This file implements:
+- OutputStore: Short-lived server-side storage of full tool outputs
+- TruncatingTool: Tool wrapper returning the head and tail of oversized output
+- MoreTool: The "more" tool the agent uses to page through stored outputs
+
+When an output is truncated the agent receives a note containing an output ID
+and can request further pages with the "more <outputId> <page>" convention. Each
+output is paged in pages of the output limit of the tool that produced it, so the
+page count in the note matches what more returns. The pages of more are never
+truncated themselves.
+*/
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// moreToolName is the name of the paging tool, which is exempt from truncation
+const moreToolName = "more"
+
+// storedOutput is a full tool output retained for paging.
+type storedOutput struct {
+	tool     string    // Tool that produced the output
+	content  string    // Complete, untruncated output
+	pageSize int       // Size in bytes of each page, the output limit of the tool
+	created  time.Time // When the output was stored, for expiry
+}
+
+// OutputStore retains full tool outputs for a limited time so they can be paged.
+type OutputStore struct {
+	outputs   map[string]*storedOutput // Map of output ID to stored output
+	mutex     sync.RWMutex             // Read-write mutex for thread-safe map operations
+	retention time.Duration            // How long outputs are kept before expiry
+	logger    *logrus.Logger           // Structured logger for operational monitoring
+}
+
+// NewOutputStore creates an output store and starts its background expiry.
+//
+// Parameters:
+//   - retention: How long stored outputs remain available
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *OutputStore: Output store ready for use
+func NewOutputStore(retention time.Duration, logger *logrus.Logger) *OutputStore {
+	store := &OutputStore{
+		outputs:   make(map[string]*storedOutput),
+		retention: retention,
+		logger:    logger,
+	}
+
+	// Start background cleanup goroutine for expired outputs
+	go store.cleanupExpiredOutputs()
+
+	return store
+}
+
+// generateOutputID creates a unique identifier for a stored output.
+func generateOutputID() string {
+	bytes := make([]byte, 6)
+	if _, err := rand.Read(bytes); err != nil {
+		return fmt.Sprintf("out_%d", time.Now().UnixNano())
+	}
+	return "out_" + hex.EncodeToString(bytes)
+}
+
+// Save stores a full output and returns its identifier.
+//
+// Parameters:
+//   - tool: Name of the tool that produced the output
+//   - content: Complete output to store
+//   - pageSize: Size in bytes of the pages the output is read in
+//
+// Returns:
+//   - string: Identifier used to page through the output
+func (o *OutputStore) Save(tool, content string, pageSize int) string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	id := generateOutputID()
+	o.outputs[id] = &storedOutput{
+		tool:     tool,
+		content:  content,
+		pageSize: pageSize,
+		created:  time.Now(),
+	}
+	return id
+}
+
+// Get retrieves a stored output by identifier.
+//
+// Parameters:
+//   - id: Output identifier returned by Save
+//
+// Returns:
+//   - string: The complete output
+//   - int: Size in bytes of the output's pages
+//   - bool: Whether the output exists and has not expired
+func (o *OutputStore) Get(id string) (string, int, bool) {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	output, exists := o.outputs[id]
+	if !exists {
+		return "", 0, false
+	}
+	return output.content, output.pageSize, true
+}
+
+// cleanupExpiredOutputs runs as a background goroutine removing outputs older than the retention period.
+func (o *OutputStore) cleanupExpiredOutputs() {
+	ticker := time.NewTicker(o.retention / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		o.mutex.Lock()
+		now := time.Now()
+		expired := 0
+		for id, output := range o.outputs {
+			if now.Sub(output.created) > o.retention {
+				delete(o.outputs, id)
+				expired++
+			}
+		}
+		remaining := len(o.outputs)
+		o.mutex.Unlock()
+
+		if expired > 0 {
+			o.logger.WithFields(logrus.Fields{
+				"expiredOutputs":   expired,
+				"remainingOutputs": remaining,
+			}).Debug("Cleaned up expired tool outputs")
+		}
+	}
+}
+
+// pageCount returns the number of pages of the given size needed for content.
+func pageCount(content string, pageSize int) int {
+	return (len(content) + pageSize - 1) / pageSize
+}
+
+// truncateOutput keeps the head and tail of content within maxSize bytes.
+// Cut points are trimmed to valid UTF-8 so multi-byte characters are never split.
+func truncateOutput(content string, maxSize int) string {
+	half := maxSize / 2
+	head := strings.ToValidUTF8(content[:half], "")
+	tail := strings.ToValidUTF8(content[len(content)-half:], "")
+	return head + "\n\n[... output truncated ...]\n\n" + tail
+}
+
+// TruncatingTool wraps a tool and truncates oversized output, storing the full text for paging.
+type TruncatingTool struct {
+	tool      tools.Tool   // The underlying tool whose output is bounded
+	maxOutput int          // Maximum output size in bytes returned to the agent
+	store     *OutputStore // Store receiving full outputs that were truncated
+}
+
+// Name returns the wrapped tool's name.
+func (t *TruncatingTool) Name() string {
+	return t.tool.Name()
+}
+
+// Description returns the wrapped tool's description.
+func (t *TruncatingTool) Description() string {
+	return t.tool.Description()
+}
+
+// Call invokes the wrapped tool and truncates the output when it exceeds the configured size.
+//
+// Parameters:
+//   - ctx: Execution context
+//   - input: Tool input provided by the agent
+//
+// Returns:
+//   - string: The output, or its head and tail plus a paging note when truncated
+//   - error: The wrapped tool's error
+func (t *TruncatingTool) Call(ctx context.Context, input string) (string, error) {
+	output, err := t.tool.Call(ctx, input)
+	if err != nil || len(output) <= t.maxOutput {
+		return output, err
+	}
+
+	id := t.store.Save(t.tool.Name(), output, t.maxOutput)
+	pages := pageCount(output, t.maxOutput)
+
+	note := fmt.Sprintf("\n\n[Output truncated: %d bytes total, showing first and last %d bytes. Full output stored as %s in %d pages; use the more tool with input '%s 2' to read page 2.]",
+		len(output), t.maxOutput/2, id, pages, id)
+
+	return truncateOutput(output, t.maxOutput) + note, nil
+}
+
+// WrapToolsWithTruncation bounds the output size of every tool using the configured
+// limits. The more tool is returned unwrapped: its pages already have the size of
+// the limit of the tool whose output they belong to.
+//
+// Parameters:
+//   - toolsList: Tools to wrap
+//   - config: Configuration providing global and per-tool output limits
+//   - store: Store receiving full outputs that were truncated
+//
+// Returns:
+//   - []tools.Tool: Tools with output truncation applied
+func WrapToolsWithTruncation(toolsList []tools.Tool, config *Config, store *OutputStore) []tools.Tool {
+	wrapped := make([]tools.Tool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		if tool.Name() == moreToolName {
+			wrapped = append(wrapped, tool)
+			continue
+		}
+		wrapped = append(wrapped, &TruncatingTool{
+			tool:      tool,
+			maxOutput: config.MaxOutputForTool(tool.Name()),
+			store:     store,
+		})
+	}
+	return wrapped
+}
+
+// MoreTool lets the agent page through outputs that were truncated by TruncatingTool.
+type MoreTool struct {
+	store *OutputStore // Store holding full outputs and their page sizes
+}
+
+// NewMoreTool creates the paging tool backed by the given output store.
+//
+// Parameters:
+//   - store: Store holding full outputs
+//
+// Returns:
+//   - *MoreTool: Paging tool ready for use
+func NewMoreTool(store *OutputStore) *MoreTool {
+	return &MoreTool{store: store}
+}
+
+// Name returns the identifier for this tool.
+func (m *MoreTool) Name() string {
+	return moreToolName
+}
+
+// Description returns usage information for the paging tool.
+func (m *MoreTool) Description() string {
+	return "Read further pages of a truncated tool output. Usage: '<outputId> <page>' (e.g. 'out_1a2b3c 2'). Output IDs are given in truncation notes."
+}
+
+// Call returns the requested page of a stored output.
+//
+// Parameters:
+//   - ctx: Execution context
+//   - input: Output ID optionally followed by a 1-based page number (default: 1)
+//
+// Returns:
+//   - string: The requested page with a position header, or an error message
+//   - error: Always nil (errors are returned as string messages)
+func (m *MoreTool) Call(ctx context.Context, input string) (string, error) {
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) == 0 {
+		return "Error: Please provide an output ID, e.g. 'out_1a2b3c 2'", nil
+	}
+
+	content, pageSize, exists := m.store.Get(parts[0])
+	if !exists {
+		return fmt.Sprintf("Error: Output %s not found or expired", parts[0]), nil
+	}
+
+	page := 1
+	if len(parts) > 1 {
+		val, err := strconv.Atoi(parts[1])
+		if err != nil || val < 1 {
+			return "Error: Page must be a positive integer", nil
+		}
+		page = val
+	}
+
+	pages := pageCount(content, pageSize)
+	if page > pages {
+		return fmt.Sprintf("Error: Output %s only has %d pages", parts[0], pages), nil
+	}
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if end > len(content) {
+		end = len(content)
+	}
+
+	return fmt.Sprintf("[Page %d of %d]\n%s", page, pages, strings.ToValidUTF8(content[start:end], "")), nil
+}
+
+var (
+	_ tools.Tool = (*TruncatingTool)(nil)
+	_ tools.Tool = (*MoreTool)(nil)
+)
@@ -0,0 +1,145 @@
+/*
+Package core provides session ownership for the Skynet Agent application.
+
+Session IDs are not secrets: they appear in logs, URLs, and notifications, and
+anyone who learns one could read the conversation, clear it, or continue it.
+A session created by an authenticated caller therefore belongs to that caller:
+
+  - a request with an API key that grants the chat scope is identified by the key,
+    and the sessions it creates belong to the key and to the key's tenant, whose
+    other keys may use them as well
+  - a request with ADMIN_TOKEN or an API key with the admin scope is an admin,
+    who may use every session; sessions created with ADMIN_TOKEN belong to admins
+  - a request without either is anonymous, and its sessions are open to every caller,
+    as they were before API keys existed
+
+Only the owner or an admin may read, clear, delete, or continue an owned session,
+or change its environment; other callers receive 403. GET /sessions lists only
+the sessions the caller may use. The same rule covers what the session's
+executions leave behind: stopping them, their traces, their synthesized speech,
+their analytics, and the executions GET /status lists. A request with an API key that is unknown,
+expired, or revoked receives 401 rather than being treated as anonymous, and
+counts as a failed authentication attempt.
+
+Once chat API keys or tenants exist, anonymous requests would escape the limits,
+quotas, and tool restrictions that apply to keys, so ANONYMOUS_ACCESS "auto" (the
+default) then answers them with 401. "on" keeps serving anonymous callers, for
+deployments behind an authenticating proxy, and "off" always requires a key or
+ADMIN_TOKEN.
+*/
+package core
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// adminSessionOwner owns the sessions created with ADMIN_TOKEN
+const adminSessionOwner = "admin"
+
+// Anonymous access modes
+const (
+	AnonymousAccessAuto = "auto" // Serve anonymous callers until a key with the chat scope or a tenant exists
+	AnonymousAccessOn   = "on"   // Always serve anonymous callers
+	AnonymousAccessOff  = "off"  // Require an API key or ADMIN_TOKEN
+)
+
+// sessionCaller identifies the caller of a session endpoint.
+type sessionCaller struct {
+	owner  string // Owner of the sessions the caller creates; empty for anonymous callers
+	keyID  string // ID of the caller's API key; empty for callers without one
+	tenant string // Tenant of the caller's API key; empty for callers without a tenant
+	admin  bool   // Authenticated with ADMIN_TOKEN or an API key with the admin scope
+}
+
+// mayUse reports whether the caller may use a session. Sessions of a tenant are
+// shared by all of the tenant's keys.
+func (caller sessionCaller) mayUse(session *ChatSession) bool {
+	return caller.mayAccess(session.Owner, session.Tenant)
+}
+
+// mayAccess reports whether the caller may use what belongs to an owner and
+// tenant, such as a session, its executions, or their traces and analytics.
+// Everything without an owner is open to every caller.
+func (caller sessionCaller) mayAccess(owner, tenant string) bool {
+	return owner == "" || caller.admin || owner == caller.owner ||
+		(tenant != "" && tenant == caller.tenant)
+}
+
+// callerOf identifies the caller of a request from its bearer token. Tokens that
+// are neither ADMIN_TOKEN nor API keys, such as those of an authenticating proxy,
+// leave the caller anonymous. A request with an invalid API key, or an anonymous
+// request while anonymous access is off, has already been answered: answered is
+// true and err is the result of writing the response.
+func (s *Server) callerOf(c echo.Context) (caller sessionCaller, answered bool, err error) {
+	provided, found := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if !found {
+		return s.anonymousCaller(c)
+	}
+	if token := s.currentConfig().AdminToken; token != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1 {
+		return sessionCaller{owner: adminSessionOwner, admin: true}, false, nil
+	}
+	if !strings.HasPrefix(provided, apiKeySecretPrefix) {
+		return s.anonymousCaller(c)
+	}
+
+	if locked, err := s.rejectLockedOut(c, "api key"); locked {
+		return sessionCaller{}, true, err
+	}
+	key, valid := s.apiKeys.Lookup(provided)
+	if !valid {
+		s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+			"endpoint": c.Path(),
+			"clientIP": c.RealIP(),
+		}).Warn("Rejected request with an unknown, expired, or revoked API key")
+		s.authFailed(c, "api key")
+		return sessionCaller{}, true, c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid API key"})
+	}
+	s.authSucceeded(c)
+	if key.hasScope(ScopeAdmin) {
+		return sessionCaller{owner: key.ID, keyID: key.ID, admin: true}, false, nil
+	}
+	if !key.hasScope(ScopeChat) {
+		return sessionCaller{}, true, c.JSON(http.StatusForbidden, map[string]string{"error": "API key does not grant the chat scope"})
+	}
+	if _, exists := s.tenants.Get(key.Tenant); key.Tenant != "" && !exists {
+		return sessionCaller{}, true, c.JSON(http.StatusForbidden, map[string]string{"error": fmt.Sprintf("Tenant %q of the API key is not declared", key.Tenant)})
+	}
+	return sessionCaller{owner: key.ID, keyID: key.ID, tenant: key.Tenant}, false, nil
+}
+
+// anonymousAllowed reports whether requests without an API key or ADMIN_TOKEN are
+// served, following ANONYMOUS_ACCESS.
+func (s *Server) anonymousAllowed() bool {
+	switch s.currentConfig().AnonymousAccess {
+	case AnonymousAccessOn:
+		return true
+	case AnonymousAccessOff:
+		return false
+	}
+	return !s.apiKeys.HasScope(ScopeChat) && !s.tenants.Declared()
+}
+
+// anonymousCaller returns the anonymous caller, or answers the request with 401
+// when anonymous access is off.
+func (s *Server) anonymousCaller(c echo.Context) (sessionCaller, bool, error) {
+	if s.anonymousAllowed() {
+		return sessionCaller{}, false, nil
+	}
+	s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"endpoint": c.Path(),
+		"clientIP": c.RealIP(),
+	}).Warn("Rejected request without an API key")
+	return sessionCaller{}, true, c.JSON(http.StatusUnauthorized, map[string]string{"error": "An API key is required"})
+}
+
+// rejectForeignSession answers a request for a session the caller does not own with 403.
+func (s *Server) rejectForeignSession(c echo.Context, session *ChatSession, requestLogger *logrus.Entry) error {
+	requestLogger.WithField("owner", session.Owner).Warn("Rejected access to a session owned by another caller")
+	return c.JSON(http.StatusForbidden, map[string]string{"error": "Session belongs to another caller"})
+}
@@ -0,0 +1,169 @@
+/*
+Package core provides parallel execution of independent tool calls for the Skynet Agent application.
+
+The langchaingo executor runs the actions of a step one after another. When the
+agent requests several read-only tool calls in a single step (function-calling
+mode), the ParallelAgent wrapper runs them concurrently on a bounded worker pool
+before handing the actions to the executor. Results land in the execution's
+tool cache, so the executor's sequential calls return immediately and the
+observations are reported together.
+
+Only tools configured as cacheable (read-only) are prefetched. State-changing
+calls still run in order through the executor and invalidate the cache as usual.
+*/
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/agents"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/tools"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ParallelAgent wraps an agent and concurrently prefetches independent read-only actions.
+type ParallelAgent struct {
+	agent     agents.Agent          // The underlying planning agent
+	tools     map[string]tools.Tool // Wrapped tools keyed by upper-case name, as the executor looks them up
+	cacheable map[string]bool       // Read-only tools eligible for concurrent execution
+	workers   int                   // Maximum number of concurrent tool calls
+	logger    *logrus.Logger        // Structured logger for operational monitoring
+}
+
+// NewParallelAgent wraps an agent with concurrent prefetching of read-only actions.
+//
+// Parameters:
+//   - agent: The underlying planning agent
+//   - toolsList: The wrapped tools used by the executor
+//   - config: Configuration providing cacheable tools and the worker pool size
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *ParallelAgent: Agent wrapper implementing agents.Agent
+func NewParallelAgent(agent agents.Agent, toolsList []tools.Tool, config *Config, logger *logrus.Logger) *ParallelAgent {
+	byName := make(map[string]tools.Tool, len(toolsList))
+	for _, tool := range toolsList {
+		byName[strings.ToUpper(tool.Name())] = tool
+	}
+
+	cacheable := make(map[string]bool, len(config.CacheableTools))
+	for _, name := range config.CacheableTools {
+		cacheable[strings.ToUpper(name)] = true
+	}
+
+	// Prefetched results are handed over through the tool cache, so parallelism needs it
+	workers := config.ToolParallelism
+	if config.ToolCacheTTL <= 0 {
+		workers = 1
+	}
+
+	return &ParallelAgent{
+		agent:     agent,
+		tools:     byName,
+		cacheable: cacheable,
+		workers:   workers,
+		logger:    logger,
+	}
+}
+
+// Plan delegates to the wrapped agent and prefetches its read-only actions concurrently.
+//
+// Parameters:
+//   - ctx: Execution context carrying the tool cache
+//   - intermediateSteps: Tool calls and observations from earlier iterations
+//   - inputs: Chain inputs
+//
+// Returns:
+//   - []schema.AgentAction: Actions planned by the wrapped agent
+//   - *schema.AgentFinish: Final answer from the wrapped agent
+//   - error: The wrapped agent's error
+func (p *ParallelAgent) Plan(ctx context.Context, intermediateSteps []schema.AgentStep, inputs map[string]string) ([]schema.AgentAction, *schema.AgentFinish, error) {
+	ctx, span := tracer.Start(ctx, "agent.iteration", trace.WithAttributes(
+		attribute.Int("agent.iteration", len(intermediateSteps)+1),
+	))
+	defer span.End()
+
+	actions, finish, err := p.agent.Plan(ctx, intermediateSteps, inputs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(
+		attribute.Int("agent.actions", len(actions)),
+		attribute.Bool("agent.finished", finish != nil),
+	)
+	if err == nil && len(actions) > 1 && p.workers > 1 {
+		p.prefetch(ctx, actions)
+	}
+	return actions, finish, err
+}
+
+// prefetch runs the distinct read-only actions concurrently so their results are cached.
+func (p *ParallelAgent) prefetch(ctx context.Context, actions []schema.AgentAction) {
+	// Without an execution cache the results could not be reused by the executor
+	if _, ok := ctx.Value(toolCacheKey{}).(*ToolCache); !ok {
+		return
+	}
+
+	seen := make(map[string]bool, len(actions))
+	eligible := make([]schema.AgentAction, 0, len(actions))
+	for _, action := range actions {
+		name := strings.ToUpper(action.Tool)
+		key := name + "\x00" + action.ToolInput
+		if !p.cacheable[name] || p.tools[name] == nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		eligible = append(eligible, action)
+	}
+	if len(eligible) < 2 {
+		return
+	}
+
+	startTime := time.Now()
+	semaphore := make(chan struct{}, p.workers)
+	var wg sync.WaitGroup
+
+	for _, action := range eligible {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(action schema.AgentAction) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			// Errors surface again when the executor makes the same call
+			p.tools[strings.ToUpper(action.Tool)].Call(ctx, action.ToolInput)
+		}(action)
+	}
+	wg.Wait()
+
+	p.logger.WithFields(logrus.Fields{
+		"actions":  len(eligible),
+		"workers":  p.workers,
+		"duration": time.Since(startTime),
+	}).Debug("Executed independent tool calls in parallel")
+}
+
+// GetInputKeys returns the wrapped agent's input keys.
+func (p *ParallelAgent) GetInputKeys() []string {
+	return p.agent.GetInputKeys()
+}
+
+// GetOutputKeys returns the wrapped agent's output keys.
+func (p *ParallelAgent) GetOutputKeys() []string {
+	return p.agent.GetOutputKeys()
+}
+
+// GetTools returns the wrapped agent's tools.
+func (p *ParallelAgent) GetTools() []tools.Tool {
+	return p.agent.GetTools()
+}
+
+var _ agents.Agent = (*ParallelAgent)(nil)
@@ -0,0 +1,123 @@
+/*
+Package core provides the tool plugin loader for the Skynet Agent application.
+
+Third parties can extend the toolbox without modifying Skynet by placing plugins
+in the plugin directory. Two kinds of plugins are supported:
+  - Go plugins (*.so) built with "go build -buildmode=plugin", exporting
+    "func Tools(workingDir *string) []tools.Tool"
+  - Executables of any language speaking the JSON stdin/stdout protocol
+    implemented by tools.ExternalTool
+
+Go plugins require a cgo-enabled build of Skynet compiled with the same Go
+version and dependency versions as the plugin; external executables have no
+such constraint and are the recommended option.
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"strings"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// pluginDescribeTimeout bounds the describe request sent to external plugins at startup
+const pluginDescribeTimeout = 10 * time.Second
+
+// pluginToolsSymbol is the constructor Go plugins must export
+const pluginToolsSymbol = "Tools"
+
+// LoadPlugins loads every Go plugin and external executable in the plugin directory.
+// Plugins that fail to load are logged and skipped so one broken plugin does not
+// prevent startup. A missing directory is not an error.
+//
+// Parameters:
+//   - dir: Plugin directory; empty disables plugin loading
+//   - workspace: Workspace shared with the other tools
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - []tools.Tool: Tools provided by the loaded plugins
+func LoadPlugins(dir string, workspace *localtools.WorkspaceContext, logger *logrus.Logger) []tools.Tool {
+	if dir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.WithError(err).WithField("dir", dir).Warn("Failed to read plugin directory")
+		}
+		return nil
+	}
+
+	var pluginTools []tools.Tool
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		pluginLogger := logger.WithField("plugin", path)
+
+		var loaded []tools.Tool
+		if strings.HasSuffix(entry.Name(), ".so") {
+			loaded, err = loadGoPlugin(path, workspace)
+		} else {
+			info, statErr := entry.Info()
+			if statErr != nil || info.Mode()&0111 == 0 {
+				// Not executable, e.g. a README or config file next to the plugins
+				continue
+			}
+			loaded, err = loadExternalPlugin(path, workspace)
+		}
+		if err != nil {
+			pluginLogger.WithError(err).Warn("Failed to load plugin")
+			continue
+		}
+
+		for _, tool := range loaded {
+			pluginLogger.WithField("tool", tool.Name()).Info("Plugin tool loaded")
+		}
+		pluginTools = append(pluginTools, loaded...)
+	}
+	return pluginTools
+}
+
+// loadGoPlugin opens a Go plugin and calls its exported Tools constructor.
+func loadGoPlugin(path string, workspace *localtools.WorkspaceContext) ([]tools.Tool, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Go plugin: %w", err)
+	}
+
+	symbol, err := p.Lookup(pluginToolsSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin does not export %s: %w", pluginToolsSymbol, err)
+	}
+
+	constructor, ok := symbol.(func(*string) []tools.Tool)
+	if !ok {
+		return nil, fmt.Errorf("plugin symbol %s must be func(*string) []tools.Tool", pluginToolsSymbol)
+	}
+	return constructor(workspace.DirRef()), nil
+}
+
+// loadExternalPlugin describes an executable plugin and wraps it as a tool.
+func loadExternalPlugin(path string, workspace *localtools.WorkspaceContext) ([]tools.Tool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginDescribeTimeout)
+	defer cancel()
+
+	tool, err := localtools.NewExternalTool(ctx, path, workspace)
+	if err != nil {
+		return nil, err
+	}
+	return []tools.Tool{tool}, nil
+}
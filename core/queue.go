@@ -0,0 +1,214 @@
+/*
+Package core provides the request queue for the Skynet Agent application.
+
+Agent executions are expensive: each one holds LLM capacity and may run several
+tools. The RequestQueue admits at most MAX_CONCURRENT_REQUESTS executions at a
+time and queues further chat requests in arrival order, up to QUEUE_MAX_SIZE.
+Streaming clients receive "queued" events with their position and an estimated
+wait, based on the average duration of recent executions. When the queue is full,
+requests are rejected right away with 503 and a Retry-After header instead of
+piling up.
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrQueueFull is returned by Enqueue when no more requests can wait.
+var ErrQueueFull = errors.New("request queue is full")
+
+// defaultExecutionEstimate is assumed before any execution has completed
+const defaultExecutionEstimate = 30 * time.Second
+
+// RequestQueue limits concurrent agent executions and queues the excess. It is safe for concurrent use.
+type RequestQueue struct {
+	mu          sync.Mutex
+	limit       int            // Maximum concurrent executions
+	maxWaiting  int            // Maximum queued requests
+	active      int            // Executions currently admitted
+	waiting     []*QueueTicket // Queued requests in arrival order
+	averageTime time.Duration  // Moving average of execution durations
+}
+
+// QueueTicket is a request's place in the queue.
+type QueueTicket struct {
+	queue    *RequestQueue
+	admitted chan struct{} // Closed when the request may execute
+	started  time.Time     // When the request was admitted
+	released bool          // Whether the slot was given back
+}
+
+// NewRequestQueue creates a queue admitting limit concurrent executions.
+//
+// Parameters:
+//   - limit: Maximum concurrent executions; values below 1 admit one
+//   - maxWaiting: Maximum queued requests; 0 rejects requests once all slots are taken
+//
+// Returns:
+//   - *RequestQueue: Empty queue
+func NewRequestQueue(limit, maxWaiting int) *RequestQueue {
+	return &RequestQueue{
+		limit:       max(limit, 1),
+		maxWaiting:  max(maxWaiting, 0),
+		averageTime: defaultExecutionEstimate,
+	}
+}
+
+// Enqueue reserves a place for a request without blocking. The request is admitted
+// immediately when a slot is free; otherwise it waits in the queue until Wait returns.
+//
+// Returns:
+//   - *QueueTicket: Ticket to wait on and release
+//   - error: ErrQueueFull when the queue has no room
+func (q *RequestQueue) Enqueue() (*QueueTicket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ticket := &QueueTicket{queue: q, admitted: make(chan struct{})}
+	if q.active < q.limit && len(q.waiting) == 0 {
+		q.active++
+		ticket.admit()
+		return ticket, nil
+	}
+	if len(q.waiting) >= q.maxWaiting {
+		return nil, ErrQueueFull
+	}
+	q.waiting = append(q.waiting, ticket)
+	return ticket, nil
+}
+
+// RetryAfter estimates when a rejected request could be admitted.
+func (q *RequestQueue) RetryAfter() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.estimate(len(q.waiting) + 1)
+}
+
+// Stats returns the number of running and queued executions.
+func (q *RequestQueue) Stats() (active, waiting int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.active, len(q.waiting)
+}
+
+// estimate returns the expected wait for the given queue position; the caller must hold the lock.
+func (q *RequestQueue) estimate(position int) time.Duration {
+	rounds := (position + q.limit - 1) / q.limit
+	return time.Duration(rounds) * q.averageTime
+}
+
+// position returns the 1-based queue position of a ticket, or 0 once it is admitted.
+func (q *RequestQueue) position(ticket *QueueTicket) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, waiting := range q.waiting {
+		if waiting == ticket {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// admit marks the ticket as admitted; the caller must hold the queue lock.
+func (t *QueueTicket) admit() {
+	t.started = time.Now()
+	close(t.admitted)
+}
+
+// Wait blocks until the request is admitted or ctx is done. While queued, onPosition
+// is called with the current position and estimated wait whenever the position changes.
+//
+// Parameters:
+//   - ctx: Context of the request; cancelling it leaves the queue
+//   - onPosition: Optional callback receiving queue position updates
+//
+// Returns:
+//   - error: Context error when the request left the queue before admission
+func (t *QueueTicket) Wait(ctx context.Context, onPosition func(position int, eta time.Duration)) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	reported := -1
+	for {
+		if position := t.queue.position(t); position > 0 && position != reported && onPosition != nil {
+			reported = position
+			t.queue.mu.Lock()
+			eta := t.queue.estimate(position)
+			t.queue.mu.Unlock()
+			onPosition(position, eta)
+		}
+
+		select {
+		case <-t.admitted:
+			return nil
+		case <-ctx.Done():
+			t.Release()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Release gives the slot of an admitted request to the next queued request, or
+// removes a queued request from the queue. It is safe to call more than once.
+func (t *QueueTicket) Release() {
+	q := t.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if t.released {
+		return
+	}
+	t.released = true
+
+	select {
+	case <-t.admitted:
+	default:
+		// Still queued: leave the queue without taking a slot
+		for i, waiting := range q.waiting {
+			if waiting == t {
+				q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+
+	// Weight recent executions more so the estimate follows load changes
+	q.averageTime = (q.averageTime*4 + time.Since(t.started)) / 5
+
+	if len(q.waiting) > 0 {
+		next := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		next.admit()
+		return
+	}
+	q.active--
+}
+
+// rejectQueueFull answers a request that found the queue full with 503 and a Retry-After estimate.
+func (s *Server) rejectQueueFull(c echo.Context, requestLogger *logrus.Entry) error {
+	retryAfter := s.queue.RetryAfter()
+	requestLogger.WithField("retryAfter", retryAfter).Warn("Request queue is full, rejecting request")
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "Server is busy, please retry later"})
+}
+
+// queueStats reports the request queue for the status endpoint.
+func (s *Server) queueStats() map[string]interface{} {
+	active, waiting := s.queue.Stats()
+	return map[string]interface{}{
+		"running": active,
+		"queued":  waiting,
+	}
+}
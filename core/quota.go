@@ -0,0 +1,446 @@
+/*
+Package core provides usage quotas for the Skynet Agent application.
+
+Rate limits smooth out bursts; quotas cap the total spent over a day or a month.
+An API key and a tenant can each have a quota, set on the key when it is created
+and in the tenant's declaration:
+
+	POST /admin/keys {"name": "ci", "scopes": ["chat"], "quota": {"dailyRequests": 200, "monthlyTokens": 5000000}}
+
+	tenants:
+	  - id: payments
+	    quota:
+	      daily_requests: 1000
+	      monthly_tokens: 20000000
+
+Every limit is optional: requests and LLM tokens, per UTC day and per calendar
+month. Before an execution starts, the quotas of the caller's key and tenant are
+checked; a request over any of them receives 429 with a message naming the
+quota, how much of it was used, and when it resets, and a Retry-After header.
+This applies to every request that starts executions: chat, tasks, workflow runs,
+hooks of a tenant, and direct tool invocations. Accepted requests count
+immediately. Tokens are counted when the execution ends, from the token usage the
+LLM reports, so the execution that crosses a token quota completes and the next
+request is refused. A task counts as one request, and each of its steps is
+checked against the quotas before it starts; a task over a quota fails and can
+be resumed once the quota resets.
+
+Quotas are set on keys and tenants, so an anonymous caller would escape them.
+While a key with the chat scope exists or tenants are declared, ANONYMOUS_ACCESS
+"auto" rejects anonymous callers (see ownership.go).
+
+GET /usage/quota returns the quotas of the caller with what was used and what
+remains. Usage is persisted to QUOTA_USAGE_PATH after every change, so monthly
+quotas survive restarts.
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Quota limits the usage of an API key or a tenant. Zero limits are unlimited.
+type Quota struct {
+	DailyRequests   int64 `yaml:"daily_requests" json:"dailyRequests,omitempty"`     // Chat requests per UTC day
+	MonthlyRequests int64 `yaml:"monthly_requests" json:"monthlyRequests,omitempty"` // Chat requests per calendar month
+	DailyTokens     int64 `yaml:"daily_tokens" json:"dailyTokens,omitempty"`         // LLM tokens per UTC day
+	MonthlyTokens   int64 `yaml:"monthly_tokens" json:"monthlyTokens,omitempty"`     // LLM tokens per calendar month
+}
+
+// IsZero reports whether the quota sets no limit.
+func (q Quota) IsZero() bool {
+	return q == Quota{}
+}
+
+// validate reports a negative limit.
+func (q Quota) validate() error {
+	if q.DailyRequests < 0 || q.MonthlyRequests < 0 || q.DailyTokens < 0 || q.MonthlyTokens < 0 {
+		return fmt.Errorf("quota limits must not be negative")
+	}
+	return nil
+}
+
+// quotaUsage is what a key or tenant used in the current day and month.
+type quotaUsage struct {
+	Day             string `json:"day"`   // UTC date the daily counters belong to
+	Month           string `json:"month"` // UTC month the monthly counters belong to
+	DailyRequests   int64  `json:"dailyRequests"`
+	MonthlyRequests int64  `json:"monthlyRequests"`
+	DailyTokens     int64  `json:"dailyTokens"`
+	MonthlyTokens   int64  `json:"monthlyTokens"`
+}
+
+// roll resets the counters of a day or month that has ended.
+func (u *quotaUsage) roll(now time.Time) {
+	if day := now.Format("2006-01-02"); u.Day != day {
+		u.Day, u.DailyRequests, u.DailyTokens = day, 0, 0
+	}
+	if month := now.Format("2006-01"); u.Month != month {
+		u.Month, u.MonthlyRequests, u.MonthlyTokens = month, 0, 0
+	}
+}
+
+// quotaSubject is a key or tenant whose quota applies to a request.
+type quotaSubject struct {
+	id    string // Usage key: "key:<id>" or "tenant:<id>"
+	label string // Description used in messages, such as `tenant "payments"`
+	quota Quota
+}
+
+// QuotaLimit is the state of one limit of a quota, as reported by GET /usage/quota.
+type QuotaLimit struct {
+	Period    string    `json:"period"` // day or month
+	Unit      string    `json:"unit"`   // requests or tokens
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetsAt  time.Time `json:"resetsAt"`
+}
+
+// limits returns the limits a quota sets with the usage counted against them.
+func (subject quotaSubject) limits(usage quotaUsage, now time.Time) []QuotaLimit {
+	dayEnd := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	monthEnd := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	candidates := []QuotaLimit{
+		{Period: "day", Unit: "requests", Limit: subject.quota.DailyRequests, Used: usage.DailyRequests, ResetsAt: dayEnd},
+		{Period: "month", Unit: "requests", Limit: subject.quota.MonthlyRequests, Used: usage.MonthlyRequests, ResetsAt: monthEnd},
+		{Period: "day", Unit: "tokens", Limit: subject.quota.DailyTokens, Used: usage.DailyTokens, ResetsAt: dayEnd},
+		{Period: "month", Unit: "tokens", Limit: subject.quota.MonthlyTokens, Used: usage.MonthlyTokens, ResetsAt: monthEnd},
+	}
+	limits := make([]QuotaLimit, 0, len(candidates))
+	for _, limit := range candidates {
+		if limit.Limit > 0 {
+			limit.Remaining = max(limit.Limit-limit.Used, 0)
+			limits = append(limits, limit)
+		}
+	}
+	return limits
+}
+
+// QuotaExceededError reports the quota that refused a request.
+type QuotaExceededError struct {
+	Subject string     // The key or tenant whose quota is used up
+	Limit   QuotaLimit // The exhausted limit
+}
+
+func (e *QuotaExceededError) Error() string {
+	period := "Daily"
+	if e.Limit.Period == "month" {
+		period = "Monthly"
+	}
+	return fmt.Sprintf("%s %s quota of %s is used up (%d of %d %s used); it resets at %s",
+		period, e.Limit.Unit[:len(e.Limit.Unit)-1], e.Subject, e.Limit.Used, e.Limit.Limit, e.Limit.Unit,
+		e.Limit.ResetsAt.Format(time.RFC3339))
+}
+
+// QuotaStore counts the usage of keys and tenants against their quotas and persists it.
+type QuotaStore struct {
+	usage  map[string]*quotaUsage // Usage by subject ID
+	path   string                 // JSON file the usage is persisted to; empty keeps it in memory
+	mutex  sync.Mutex             // Guards usage
+	logger *logrus.Logger         // Structured logger for operational monitoring
+}
+
+// NewQuotaStore creates a store and loads previously persisted usage. A missing
+// file is not an error; it is created with the first counted request.
+//
+// Parameters:
+//   - path: JSON file used for persistence; empty disables persistence
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *QuotaStore: Store ready for use
+//   - error: Any error reading or decoding the persisted usage
+func NewQuotaStore(path string, logger *logrus.Logger) (*QuotaStore, error) {
+	store := &QuotaStore{
+		usage:  make(map[string]*quotaUsage),
+		path:   path,
+		logger: logger,
+	}
+
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota usage file: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.usage); err != nil {
+		return nil, fmt.Errorf("failed to decode quota usage file: %w", err)
+	}
+	return store, nil
+}
+
+// save writes the usage to the persistence file. Callers must hold the mutex.
+// A failed write is logged; the usage in memory stays authoritative.
+func (q *QuotaStore) save() {
+	if q.path == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(q.usage, "", "  ")
+	if err == nil {
+		// Write to a temporary file first so a crash never leaves a partial file behind
+		staged := q.path + ".tmp"
+		if err = os.WriteFile(staged, data, 0600); err == nil {
+			if err = os.Rename(staged, q.path); err != nil {
+				os.Remove(staged)
+			}
+		}
+	}
+	if err != nil {
+		q.logger.WithError(err).WithField("path", q.path).Error("Failed to persist quota usage")
+	}
+}
+
+// usageLocked returns the current usage of a subject. Callers must hold the mutex.
+func (q *QuotaStore) usageLocked(id string, now time.Time) *quotaUsage {
+	usage, exists := q.usage[id]
+	if !exists {
+		usage = &quotaUsage{}
+		q.usage[id] = usage
+	}
+	usage.roll(now)
+	return usage
+}
+
+// exceededLocked returns the first exhausted limit of the subjects' quotas, or nil.
+// Callers must hold the mutex.
+func (q *QuotaStore) exceededLocked(subjects []quotaSubject, now time.Time) *QuotaExceededError {
+	for _, subject := range subjects {
+		for _, limit := range subject.limits(*q.usageLocked(subject.id, now), now) {
+			if limit.Remaining == 0 {
+				return &QuotaExceededError{Subject: subject.label, Limit: limit}
+			}
+		}
+	}
+	return nil
+}
+
+// Check reports an exhausted quota of the subjects without counting a request,
+// for executions that belong to a request already admitted.
+//
+// Parameters:
+//   - subjects: Keys and tenants whose quotas apply to the execution
+//
+// Returns:
+//   - error: *QuotaExceededError for the first exhausted limit, or nil
+func (q *QuotaStore) Check(subjects []quotaSubject) error {
+	if len(subjects) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if exceeded := q.exceededLocked(subjects, now); exceeded != nil {
+		return exceeded
+	}
+	return nil
+}
+
+// Admit checks a request against the quotas of its subjects and counts it when
+// none of them is used up.
+//
+// Parameters:
+//   - subjects: Keys and tenants whose quotas apply to the request
+//
+// Returns:
+//   - error: *QuotaExceededError for the first exhausted limit, or nil
+func (q *QuotaStore) Admit(subjects []quotaSubject) error {
+	if len(subjects) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if exceeded := q.exceededLocked(subjects, now); exceeded != nil {
+		return exceeded
+	}
+	for _, subject := range subjects {
+		usage := q.usageLocked(subject.id, now)
+		usage.DailyRequests++
+		usage.MonthlyRequests++
+	}
+	q.save()
+	return nil
+}
+
+// AddTokens counts the LLM tokens of a finished execution against its subjects.
+func (q *QuotaStore) AddTokens(subjects []quotaSubject, tokens int64) {
+	if len(subjects) == 0 || tokens <= 0 {
+		return
+	}
+	now := time.Now().UTC()
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, subject := range subjects {
+		usage := q.usageLocked(subject.id, now)
+		usage.DailyTokens += tokens
+		usage.MonthlyTokens += tokens
+	}
+	q.save()
+}
+
+// Limits returns the limits of a subject's quota with the current usage.
+func (q *QuotaStore) Limits(subject quotaSubject) []QuotaLimit {
+	now := time.Now().UTC()
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	usage := quotaUsage{}
+	if stored, exists := q.usage[subject.id]; exists {
+		usage = *stored
+	}
+	usage.roll(now)
+	return subject.limits(usage, now)
+}
+
+// quotaSubjects returns the key and tenant quotas that apply to a caller.
+func (s *Server) quotaSubjects(caller sessionCaller) []quotaSubject {
+	var subjects []quotaSubject
+	if caller.keyID != "" {
+		if key, exists := s.apiKeys.Get(caller.keyID); exists && !key.Quota.IsZero() {
+			subjects = append(subjects, quotaSubject{id: "key:" + key.ID, label: fmt.Sprintf("API key %q", key.Name), quota: key.Quota})
+		}
+	}
+	if caller.tenant != "" {
+		if tenant, exists := s.tenants.Get(caller.tenant); exists && !tenant.Quota.IsZero() {
+			subjects = append(subjects, quotaSubject{id: "tenant:" + tenant.ID, label: fmt.Sprintf("tenant %q", tenant.ID), quota: tenant.Quota})
+		}
+	}
+	return subjects
+}
+
+// rejectQuotaExceeded checks a request against the caller's quotas and answers it
+// with 429 when one is used up. It reports whether the request was answered, along
+// with the result of writing the response.
+func (s *Server) rejectQuotaExceeded(c echo.Context, caller sessionCaller, requestLogger *logrus.Entry) (bool, error) {
+	err := s.quotas.Admit(s.quotaSubjects(caller))
+	if err == nil {
+		return false, nil
+	}
+	exceeded := err.(*QuotaExceededError)
+	requestLogger.WithFields(logrus.Fields{
+		"quota":  exceeded.Subject,
+		"period": exceeded.Limit.Period,
+		"unit":   exceeded.Limit.Unit,
+		"limit":  exceeded.Limit.Limit,
+		"used":   exceeded.Limit.Used,
+	}).Warn("Rejected request over its quota")
+	retry := time.Until(exceeded.Limit.ResetsAt)
+	c.Response().Header().Set("Retry-After", strconv.Itoa(max(int(retry.Seconds()), 1)))
+	return true, c.JSON(http.StatusTooManyRequests, map[string]interface{}{
+		"error": exceeded.Error(),
+		"quota": exceeded.Limit,
+	})
+}
+
+// recordTokens counts the tokens of a finished execution against the quotas of
+// the caller it was attributed to and the tenant's usage.
+func (s *Server) recordTokens(ctx context.Context, meter *TokenMeter) {
+	caller := callerFromContext(ctx)
+	tokens := meter.Total()
+	s.quotas.AddTokens(s.quotaSubjects(caller), tokens)
+	s.tenants.RecordTokens(caller.tenant, tokens)
+}
+
+// handleUsageQuota handles GET /usage/quota requests with the quotas of the caller.
+func (s *Server) handleUsageQuota(c echo.Context) error {
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+
+	quotas := make([]map[string]interface{}, 0)
+	for _, subject := range s.quotaSubjects(caller) {
+		quotas = append(quotas, map[string]interface{}{
+			"subject": subject.label,
+			"limits":  s.quotas.Limits(subject),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"quotas": quotas,
+	})
+}
+
+// tokenMeterKey is the unexported context key type for TokenMeter values.
+type tokenMeterKey struct{}
+
+// TokenMeter adds up the LLM tokens used by one execution.
+type TokenMeter struct {
+	total atomic.Int64
+}
+
+// WithTokenMeter returns a derived context carrying a fresh meter.
+func WithTokenMeter(ctx context.Context) (context.Context, *TokenMeter) {
+	meter := &TokenMeter{}
+	return context.WithValue(ctx, tokenMeterKey{}, meter), meter
+}
+
+// tokenMeterFromContext returns the meter of an execution, or nil.
+func tokenMeterFromContext(ctx context.Context) *TokenMeter {
+	meter, _ := ctx.Value(tokenMeterKey{}).(*TokenMeter)
+	return meter
+}
+
+// Add counts the tokens of an LLM response. A nil meter ignores them.
+func (m *TokenMeter) Add(response *llms.ContentResponse) {
+	if m == nil {
+		return
+	}
+	m.total.Add(responseTokens(response))
+}
+
+// Total returns the tokens counted so far.
+func (m *TokenMeter) Total() int64 {
+	if m == nil {
+		return 0
+	}
+	return m.total.Load()
+}
+
+// responseTokens returns the total tokens an LLM response reports. Providers
+// report usage in the generation info of the first choice, under
+// "TotalTokens" (Ollama, OpenAI) or "total_tokens" (Gemini).
+func responseTokens(response *llms.ContentResponse) int64 {
+	if response == nil || len(response.Choices) == 0 {
+		return 0
+	}
+	info := response.Choices[0].GenerationInfo
+	for _, key := range []string{"TotalTokens", "total_tokens"} {
+		switch value := info[key].(type) {
+		case int:
+			return int64(value)
+		case int32:
+			return int64(value)
+		case int64:
+			return value
+		case float64:
+			return int64(value)
+		}
+	}
+	return 0
+}
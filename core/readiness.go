@@ -0,0 +1,272 @@
+/*
+Package core provides the LLM readiness probe for the Skynet Agent application.
+
+Creating the LLM client does not contact the provider, so a server whose Ollama
+instance is down or lacks the configured model would start and then fail the
+first user request. The probe started by NewServer checks the provider in the
+background, retrying with exponential backoff, and optionally pulls a missing
+Ollama model. Its state is reported by GET /readyz, which answers 503 until the
+provider is usable, so load balancers and orchestrators hold traffic back.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// maxProbeBackoff caps the delay between probe attempts
+const maxProbeBackoff = time.Minute
+
+// Readiness tracks whether the LLM provider has been reached. It is safe for concurrent use.
+type Readiness struct {
+	mu        sync.RWMutex
+	ready     bool      // Whether the last probe succeeded
+	probing   bool      // Whether the background probe is still retrying
+	attempts  int       // Probe attempts made so far
+	lastError string    // Error of the last failed probe
+	checkedAt time.Time // Time of the last probe
+}
+
+// ReadinessStatus is the JSON representation of the readiness state.
+type ReadinessStatus struct {
+	Ready     bool      `json:"ready"`               // Whether the LLM provider is usable
+	Provider  string    `json:"provider"`            // Configured LLM provider
+	Model     string    `json:"model"`               // Configured model
+	Probing   bool      `json:"probing"`             // Whether the startup probe is still retrying
+	Attempts  int       `json:"attempts"`            // Probe attempts made so far
+	Error     string    `json:"error,omitempty"`     // Error of the last failed probe
+	CheckedAt time.Time `json:"checkedAt,omitempty"` // Time of the last probe
+}
+
+// record stores the outcome of a probe attempt.
+func (r *Readiness) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	r.checkedAt = time.Now()
+	r.ready = err == nil
+	r.lastError = ""
+	if err != nil {
+		r.lastError = err.Error()
+	}
+}
+
+// setProbing marks whether the background probe is running.
+func (r *Readiness) setProbing(probing bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.probing = probing
+}
+
+// status returns a snapshot of the readiness state.
+func (r *Readiness) status(config *Config) ReadinessStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	model := config.OllamaModel
+	if config.LLMProvider == "gemini" {
+		model = config.GeminiModel
+	}
+	return ReadinessStatus{
+		Ready:     r.ready,
+		Provider:  config.LLMProvider,
+		Model:     model,
+		Probing:   r.probing,
+		Attempts:  r.attempts,
+		Error:     r.lastError,
+		CheckedAt: r.checkedAt,
+	}
+}
+
+// probeLLMWithRetry probes the LLM provider until it is usable, the configured
+// number of attempts is exhausted, or ctx is cancelled. The delay between attempts
+// starts at the configured backoff and doubles up to one minute.
+func (s *Server) probeLLMWithRetry(ctx context.Context) {
+	config := s.currentConfig()
+	s.readiness.setProbing(true)
+	defer s.readiness.setProbing(false)
+
+	// Without probing the provider is assumed to be usable
+	if config.LLMProbeRetries <= 0 {
+		s.readiness.record(nil)
+		return
+	}
+
+	backoff := config.LLMProbeBackoff
+	for attempt := 1; attempt <= config.LLMProbeRetries; attempt++ {
+		err := s.probeLLM(ctx, config.OllamaAutoPull)
+		s.readiness.record(err)
+		if err == nil {
+			s.logger.WithFields(logrus.Fields{
+				"provider": config.LLMProvider,
+				"attempt":  attempt,
+			}).Info("LLM provider is ready")
+			return
+		}
+
+		probeLogger := s.logger.WithError(err).WithFields(logrus.Fields{
+			"provider": config.LLMProvider,
+			"attempt":  attempt,
+			"attempts": config.LLMProbeRetries,
+		})
+		if attempt == config.LLMProbeRetries {
+			probeLogger.Error("LLM provider is unreachable; requests will fail until it recovers (GET /readyz probes again)")
+			return
+		}
+		probeLogger.WithField("retryIn", backoff).Warn("LLM provider is not ready, retrying")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxProbeBackoff)
+	}
+}
+
+// probeLLM checks once that the configured provider and model can be used.
+//
+// Parameters:
+//   - ctx: Context bounding the probe
+//   - pull: Pull a missing Ollama model instead of failing
+//
+// Returns:
+//   - error: Why the provider is not usable, or nil
+func (s *Server) probeLLM(ctx context.Context, pull bool) error {
+	config := s.currentConfig()
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if config.LLMProvider == "gemini" {
+		return probeGemini(checkCtx, config.GeminiAPIKey, config.GeminiModel)
+	}
+
+	models, err := ollamaModels(checkCtx, config.OllamaEndpoint)
+	if err != nil {
+		return err
+	}
+	for _, model := range models {
+		if model == config.OllamaModel || model == config.OllamaModel+":latest" {
+			return nil
+		}
+	}
+	if !pull {
+		return fmt.Errorf("ollama at %s does not have model %s; run: ollama pull %s, or set OLLAMA_AUTO_PULL=true", config.OllamaEndpoint, config.OllamaModel, config.OllamaModel)
+	}
+
+	s.logger.WithField("model", config.OllamaModel).Info("Pulling missing Ollama model")
+	// Pulling downloads gigabytes, so it is bounded by the caller's context only
+	return pullOllamaModel(ctx, config.OllamaEndpoint, config.OllamaModel)
+}
+
+// ollamaModels lists the models installed on an Ollama server.
+//
+// Parameters:
+//   - ctx: Context bounding the request
+//   - endpoint: Ollama API base URL
+//
+// Returns:
+//   - []string: Installed model names, including their tags
+//   - error: Request or response error
+func ollamaModels(ctx context.Context, endpoint string) ([]string, error) {
+	endpoint = strings.TrimRight(endpoint, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OLLAMA_ENDPOINT %q: %w", endpoint, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ollama endpoint %s is unreachable: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama endpoint %s returned %s", endpoint, resp.Status)
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("ollama endpoint %s returned an invalid model list: %w", endpoint, err)
+	}
+
+	models := make([]string, len(tags.Models))
+	for i, model := range tags.Models {
+		models[i] = model.Name
+	}
+	return models, nil
+}
+
+// pullOllamaModel downloads a model to an Ollama server and waits for completion.
+func pullOllamaModel(ctx context.Context, endpoint, model string) error {
+	payload, err := json.Marshal(map[string]interface{}{"model": model, "stream": false})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/api/pull", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to pull model %s: %w", model, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("failed to pull model %s: %s: %s", model, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// probeGemini checks that the Gemini API accepts the key and knows the model.
+func probeGemini(ctx context.Context, apiKey, model string) error {
+	endpoint := "https://generativelanguage.googleapis.com/v1beta/models/" + url.PathEscape(model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-goog-api-key", apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("gemini API is unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gemini API rejected model %s: %s", model, resp.Status)
+	}
+	return nil
+}
+
+// handleReadyz handles GET /readyz requests. It answers 200 when the LLM provider
+// is usable and 503 otherwise. Once the startup probe has given up, each request
+// probes the provider again so the instance recovers when the provider does.
+func (s *Server) handleReadyz(c echo.Context) error {
+	status := s.readiness.status(s.currentConfig())
+	if !status.Ready && !status.Probing && status.Attempts > 0 {
+		s.readiness.record(s.probeLLM(c.Request().Context(), false))
+		status = s.readiness.status(s.currentConfig())
+	}
+
+	if !status.Ready {
+		return c.JSON(http.StatusServiceUnavailable, status)
+	}
+	return c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,248 @@
+/*
+Package core provides the tool registry for the Skynet Agent application.
+
+This file implements the ToolRegistry, the single ordered list of tools offered
+to the agent. Built-in tools are declared once in builtinTools; command tools,
+plugins, MCP tools, and tools registered at runtime are added with their source
+so name conflicts are detected in one place. The agent executor and the tools
+API read the registry through filters, for example to hide tools disabled in
+the configuration.
+*/
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	localtools "skynet/tools"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// ToolSource identifies where a registered tool comes from.
+type ToolSource string
+
+const (
+	ToolSourceBuiltin ToolSource = "builtin" // Compiled into Skynet
+	ToolSourceCommand ToolSource = "command" // Declared in the tools file
+	ToolSourcePlugin  ToolSource = "plugin"  // Loaded from the plugin directory
+	ToolSourceMCP     ToolSource = "mcp"     // Exposed by an MCP server
+	ToolSourceCustom  ToolSource = "custom"  // Registered at runtime through POST /admin/tools
+)
+
+// RegisteredTool is a tool together with its source.
+type RegisteredTool struct {
+	Tool   tools.Tool // The unwrapped tool
+	Source ToolSource // Where the tool comes from
+}
+
+// ToolFilter reports whether a registered tool is offered.
+type ToolFilter func(tool RegisteredTool) bool
+
+// ToolRegistry holds the tools offered to the agent in registration order.
+// Tool names are unique across all sources. It is safe for concurrent use.
+type ToolRegistry struct {
+	mu      sync.RWMutex
+	entries []RegisteredTool
+}
+
+// NewToolRegistry creates an empty tool registry.
+//
+// Returns:
+//   - *ToolRegistry: Registry ready for tools to be added
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{}
+}
+
+// Register appends a tool, rejecting it if a tool with the same name is already registered.
+//
+// Parameters:
+//   - source: Where the tool comes from
+//   - tool: Tool to register
+//
+// Returns:
+//   - error: Conflict with an already registered tool, or nil
+func (r *ToolRegistry) Register(source ToolSource, tool tools.Tool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.lookup(tool.Name()); ok {
+		return fmt.Errorf("tool %q conflicts with a %s tool of the same name", tool.Name(), existing.Source)
+	}
+	r.entries = append(r.entries, RegisteredTool{Tool: tool, Source: source})
+	return nil
+}
+
+// ReplaceSource replaces all tools of one source, for sources that change at runtime.
+// The new tools take the place of the old ones in the registration order. Tools that
+// conflict with a tool of another source are skipped and returned as errors.
+//
+// Parameters:
+//   - source: Source whose tools are replaced
+//   - toolsList: New tools of the source
+//
+// Returns:
+//   - []error: One error per skipped tool
+func (r *ToolRegistry) ReplaceSource(source ToolSource, toolsList []tools.Tool) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	position := -1
+	others := make([]RegisteredTool, 0, len(r.entries))
+	for _, entry := range r.entries {
+		if entry.Source == source {
+			if position < 0 {
+				position = len(others)
+			}
+			continue
+		}
+		others = append(others, entry)
+	}
+	if position < 0 {
+		position = len(others)
+	}
+	r.entries = others
+
+	var errs []error
+	replacement := make([]RegisteredTool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		if existing, ok := r.lookup(tool.Name()); ok {
+			errs = append(errs, fmt.Errorf("tool %q conflicts with a %s tool of the same name", tool.Name(), existing.Source))
+			continue
+		}
+		replacement = append(replacement, RegisteredTool{Tool: tool, Source: source})
+	}
+
+	entries := make([]RegisteredTool, 0, len(others)+len(replacement))
+	entries = append(entries, others[:position]...)
+	entries = append(entries, replacement...)
+	r.entries = append(entries, others[position:]...)
+	return errs
+}
+
+// Get returns the registered tool with the given name.
+func (r *ToolRegistry) Get(name string) (RegisteredTool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lookup(name)
+}
+
+// lookup finds a tool by name; the caller must hold the lock.
+func (r *ToolRegistry) lookup(name string) (RegisteredTool, bool) {
+	for _, entry := range r.entries {
+		if entry.Tool.Name() == name {
+			return entry, true
+		}
+	}
+	return RegisteredTool{}, false
+}
+
+// Entries returns the registered tools accepted by every filter, in registration order.
+func (r *ToolRegistry) Entries(filters ...ToolFilter) []RegisteredTool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]RegisteredTool, 0, len(r.entries))
+	for _, entry := range r.entries {
+		accepted := true
+		for _, filter := range filters {
+			if !filter(entry) {
+				accepted = false
+				break
+			}
+		}
+		if accepted {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Tools returns the tools accepted by every filter, in registration order.
+func (r *ToolRegistry) Tools(filters ...ToolFilter) []tools.Tool {
+	entries := r.Entries(filters...)
+	toolsList := make([]tools.Tool, len(entries))
+	for i, entry := range entries {
+		toolsList[i] = entry.Tool
+	}
+	return toolsList
+}
+
+// EnabledTools returns a filter hiding the tools disabled in the configuration.
+func EnabledTools(config *Config) ToolFilter {
+	disabled := make(map[string]bool, len(config.DisabledTools))
+	for _, name := range config.DisabledTools {
+		disabled[name] = true
+	}
+	return func(tool RegisteredTool) bool {
+		return !disabled[tool.Tool.Name()]
+	}
+}
+
+// builtinTools instantiates the built-in tools. Adding a built-in tool is a change
+// to this function only. In Kubernetes cluster mode the cluster tools replace them.
+//
+// Parameters:
+//   - config: Configuration providing the tool settings
+//   - workspace: Default workspace shared by the tools
+//   - outputStore: Store of truncated outputs paged by the more tool
+//
+// Returns:
+//   - []tools.Tool: Built-in tools in the order they are offered to the agent
+func builtinTools(config *Config, workspace *localtools.WorkspaceContext, outputStore *OutputStore) []tools.Tool {
+	if inCluster(config) {
+		return clusterTools(config, workspace, outputStore)
+	}
+
+	builtins := []tools.Tool{
+		localtools.NewDateTimeTool(),
+		localtools.NewLsTool(workspace),
+		localtools.NewCdTool(workspace),
+		localtools.NewTopTool(),
+		localtools.NewGrepTool(workspace),
+		localtools.NewStatTool(workspace),
+		localtools.NewCatTool(workspace),
+		localtools.NewFileTool(workspace),
+		localtools.NewShellTool(workspace),
+		localtools.NewTeeTool(workspace),
+		localtools.NewPsTool(),
+		localtools.NewNetstatTool(),
+		localtools.NewSysInfoTool(),
+		localtools.NewSystemctlTool(),
+		localtools.NewApkTool(),
+		localtools.NewKubectlTool(config.KubectlKubeconfig, config.KubectlNamespace, config.KubectlReadOnly, workspace),
+		localtools.NewHttpTool(),
+		localtools.NewLogsTool(),
+		localtools.NewCronTool(),
+		localtools.NewTransferTool(workspace),
+		localtools.NewProcTool(config.ProcProtected),
+		localtools.NewLsofTool(),
+		localtools.NewCaptureTool(workspace),
+		localtools.NewDmesgTool(),
+		localtools.NewPerfTool(),
+		localtools.NewSmartTool(),
+		localtools.NewHashTool(workspace),
+		localtools.NewTextTool(workspace),
+		localtools.NewEnvTool(config.SysctlWriteEnabled),
+		localtools.NewPipTool(workspace),
+		localtools.NewNpmTool(workspace),
+		localtools.NewGoTool(workspace),
+		localtools.NewAnsibleTool(config.AnsibleInventory, workspace),
+		localtools.NewAwsTool(config.AwsProfile, config.AwsRegion, config.AwsReadOnly),
+		localtools.NewWgTool(),
+		localtools.NewWebServerTool(),
+		localtools.NewCertbotTool(),
+		localtools.NewFail2banTool(),
+		localtools.NewGpgTool(workspace),
+		localtools.NewCalcTool(),
+		localtools.NewOllamaTool(config.OllamaEndpoint, config.OllamaModel),
+		NewMoreTool(outputStore),
+	}
+
+	// Offer only the container runtimes installed on this host
+	builtins = append(builtins, localtools.ContainerRuntimeTools()...)
+	if config.NetworkToolEnabled {
+		builtins = append(builtins, localtools.NewNetworkTool())
+	}
+	return builtins
+}
@@ -0,0 +1,138 @@
+/*
+Package core provides runtime configuration reloading for the Skynet Agent application.
+
+This file implements Server.Reload, triggered by SIGHUP or POST /admin/reload,
+and the bearer token check guarding the /admin endpoints. A reload re-reads the
+environment and the configuration file and applies the settings that can change
+without a restart: the log level, agent limits, and tool settings such as the
+disabled tools, timeouts, retries, caching, and read-only modes. The built-in
+tools are re-created with the new settings and the agent executor is swapped
+for subsequent requests; sessions and running executions are kept.
+
+Settings that bind resources at startup, such as the port, the LLM provider and
+model, the tool files, and the session store limits, still require a restart.
+*/
+package core
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Reload re-reads the configuration from the configuration file loaded at startup
+// and the environment, applies the reloadable settings, and rebuilds the agent
+// executor. Active sessions are kept.
+//
+// Returns:
+//   - error: Load error of the configuration; the active configuration is kept
+func (s *Server) Reload() error {
+	current := s.currentConfig()
+
+	loaded, err := LoadConfig(current.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	// Start from the active configuration so settings bound at startup stay consistent
+	next := *current
+	next.LogLevel = loaded.LogLevel
+	next.LogTruncateLength = loaded.LogTruncateLength
+	next.MaxIterations = loaded.MaxIterations
+	next.RequestTimeout = loaded.RequestTimeout
+	next.ContextLimit = loaded.ContextLimit
+	next.ToolParallelism = loaded.ToolParallelism
+	next.ToolTimeout = loaded.ToolTimeout
+	next.ToolTimeouts = loaded.ToolTimeouts
+	next.ToolRetries = loaded.ToolRetries
+	next.ToolRetryBackoff = loaded.ToolRetryBackoff
+	next.ToolMaxOutput = loaded.ToolMaxOutput
+	next.ToolMaxOutputs = loaded.ToolMaxOutputs
+	next.ToolCacheTTL = loaded.ToolCacheTTL
+	next.CacheableTools = loaded.CacheableTools
+	next.ToolNice = loaded.ToolNice
+	next.ToolIOClass = loaded.ToolIOClass
+	next.ToolIOLevel = loaded.ToolIOLevel
+	next.ProcProtected = loaded.ProcProtected
+	next.SysctlWriteEnabled = loaded.SysctlWriteEnabled
+	next.KubectlNamespace = loaded.KubectlNamespace
+	next.KubectlReadOnly = loaded.KubectlReadOnly
+	next.AwsReadOnly = loaded.AwsReadOnly
+	next.NetworkToolEnabled = loaded.NetworkToolEnabled
+	next.DisabledTools = loaded.DisabledTools
+	next.ToolInvokeEnabled = loaded.ToolInvokeEnabled
+	next.AdminToken = loaded.AdminToken
+	next.AnonymousAccess = loaded.AnonymousAccess
+	next.AllowedCIDRs = loaded.AllowedCIDRs
+
+	s.configMutex.Lock()
+	s.config = &next
+	s.configMutex.Unlock()
+
+	s.logger.SetLevel(parseLogLevel(next.LogLevel))
+
+	// Re-create the built-in tools, which take their settings at construction
+	for _, err := range s.registry.ReplaceSource(ToolSourceBuiltin, builtinTools(&next, s.workspace, s.outputStore)) {
+		s.logger.WithError(err).Warn("Skipping built-in tool that conflicts with an existing tool")
+	}
+	if err := s.rebuildExecutor(); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"configFile":    next.ConfigFile,
+		"logLevel":      next.LogLevel,
+		"disabledTools": next.DisabledTools,
+	}).Info("Configuration reloaded")
+	return nil
+}
+
+// requireAdmin is middleware restricting the /admin endpoints to requests carrying
+// the configured admin token or an API key with the admin scope as a bearer token.
+// Without either the endpoints are disabled.
+func (s *Server) requireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := s.currentConfig().AdminToken
+		if token == "" && !s.apiKeys.HasScope(ScopeAdmin) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Admin API is disabled"})
+		}
+		if locked, err := s.rejectLockedOut(c, "admin"); locked {
+			return err
+		}
+
+		provided, found := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if !found || !s.authorizedBearer(provided, token, ScopeAdmin) {
+			s.logger.WithFields(logrus.Fields{
+				"endpoint": c.Path(),
+				"clientIP": c.RealIP(),
+			}).Warn("Rejected admin request with a missing or invalid token")
+			s.authFailed(c, "admin")
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid admin token"})
+		}
+		s.authSucceeded(c)
+		return next(c)
+	}
+}
+
+// handleReload handles POST /admin/reload requests by reloading the configuration.
+func (s *Server) handleReload(c echo.Context) error {
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint": "/admin/reload",
+		"method":   "POST",
+		"clientIP": c.RealIP(),
+	})
+
+	if err := s.Reload(); err != nil {
+		requestLogger.WithError(err).Error("Failed to reload configuration")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to reload configuration: " + err.Error()})
+	}
+
+	requestLogger.Info("Configuration reloaded through the admin API")
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":    "Configuration reloaded",
+		"configFile": s.currentConfig().ConfigFile,
+		"logLevel":   s.currentConfig().LogLevel,
+	})
+}
@@ -0,0 +1,94 @@
+/*
+Package core provides request ID propagation for the Skynet Agent application.
+
+Every HTTP request gets an ID: the X-Request-ID header sent by the client, or a
+generated one. The ID is returned in the X-Request-ID response header and attached
+to the request context, which the agent execution inherits. Loggers created with
+WithContext(ctx), such as those of the LLM wrapper, the callback handlers, the
+tool wrappers, and the tools, receive the ID as the requestId field through
+RequestIDHook, so all log lines of one chat turn can be correlated.
+*/
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRequestIDLength bounds client-provided request IDs
+const maxRequestIDLength = 128
+
+// standardLoggerHook guards installing the request ID hook on the standard logger used by the tools
+var standardLoggerHook sync.Once
+
+// newRequestID generates a unique request ID.
+func newRequestID() string {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return fmt.Sprintf("req_%d", time.Now().UnixNano())
+	}
+	return "req_" + hex.EncodeToString(bytes)
+}
+
+// ensureRequestID returns ctx carrying a request ID, generating one if none is attached.
+func ensureRequestID(ctx context.Context) context.Context {
+	if localtools.RequestIDFromContext(ctx) != "" {
+		return ctx
+	}
+	return localtools.WithRequestID(ctx, newRequestID())
+}
+
+// requestIDMiddleware assigns every request an ID, taken from the X-Request-ID
+// header when the client sends a usable one, and returns it in the response.
+func (s *Server) requestIDMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+
+		c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+		c.SetRequest(c.Request().WithContext(localtools.WithRequestID(c.Request().Context(), requestID)))
+		return next(c)
+	}
+}
+
+// RequestIDHook adds the request ID of an entry's context as the requestId field.
+type RequestIDHook struct{}
+
+// Levels returns all levels; every entry may carry a request context.
+func (RequestIDHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the requestId field unless the entry already has one.
+func (RequestIDHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	if _, ok := entry.Data["requestId"]; ok {
+		return nil
+	}
+	if requestID := localtools.RequestIDFromContext(entry.Context); requestID != "" {
+		entry.Data["requestId"] = requestID
+	}
+	return nil
+}
+
+// installRequestIDHook adds RequestIDHook to a logger and, once, to the standard
+// logger the tools log through.
+func installRequestIDHook(logger *logrus.Logger) {
+	logger.AddHook(RequestIDHook{})
+	standardLoggerHook.Do(func() {
+		logrus.AddHook(RequestIDHook{})
+	})
+}
@@ -0,0 +1,107 @@
+/*
+Package core provides per-execution tool restrictions for the Skynet Agent application.
+
+The agent executor and its tool list are shared by all requests. An execution
+that may only use some of the tools, such as a workflow step, attaches the
+allowed tool names to its context with WithAllowedTools; calls to any other tool
+are refused with a message telling the agent which tools it may use, without
+running the tool. Allowlists narrow each other: a workflow step of a tenant's
+execution may only call the tools both the step and the tenant allow.
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// allowedToolsKey is the unexported context key type for tool allowlists.
+type allowedToolsKey struct{}
+
+// WithAllowedTools returns a derived context restricting the execution to the named
+// tools. When the parent context already carries an allowlist, only the tools on
+// both lists remain allowed.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - names: Tools the execution may call; empty leaves the parent's restriction unchanged
+//
+// Returns:
+//   - context.Context: Derived context carrying the allowlist
+func WithAllowedTools(ctx context.Context, names []string) context.Context {
+	if len(names) == 0 {
+		return ctx
+	}
+	if parent, ok := ctx.Value(allowedToolsKey{}).([]string); ok {
+		narrowed := []string{}
+		for _, name := range names {
+			if slices.Contains(parent, name) {
+				narrowed = append(narrowed, name)
+			}
+		}
+		names = narrowed
+	}
+	return context.WithValue(ctx, allowedToolsKey{}, names)
+}
+
+// RestrictedTool wraps a tool and refuses calls that the execution's allowlist does not permit.
+type RestrictedTool struct {
+	tool tools.Tool // The underlying tool
+}
+
+// Name returns the wrapped tool's name.
+func (t *RestrictedTool) Name() string {
+	return t.tool.Name()
+}
+
+// Description returns the wrapped tool's description.
+func (t *RestrictedTool) Description() string {
+	return t.tool.Description()
+}
+
+// Call invokes the tool if the execution may use it. A refused call is reported
+// to the agent as the tool output so it can choose an allowed tool instead.
+//
+// Parameters:
+//   - ctx: Execution context, optionally carrying an allowlist
+//   - input: Tool input provided by the agent
+//
+// Returns:
+//   - string: The tool output, or the refusal
+//   - error: The wrapped tool's error
+func (t *RestrictedTool) Call(ctx context.Context, input string) (string, error) {
+	allowed, ok := ctx.Value(allowedToolsKey{}).([]string)
+	if !ok {
+		return t.tool.Call(ctx, input)
+	}
+	for _, name := range allowed {
+		if name == t.tool.Name() {
+			return t.tool.Call(ctx, input)
+		}
+	}
+	if len(allowed) == 0 {
+		return fmt.Sprintf("The %s tool is not allowed in this step, and no other tool is either. Answer without tools.", t.tool.Name()), nil
+	}
+	return fmt.Sprintf("The %s tool is not allowed in this step. Use only these tools: %s.", t.tool.Name(), strings.Join(allowed, ", ")), nil
+}
+
+// WrapToolsWithRestrictions enforces per-execution tool allowlists on a tool list.
+//
+// Parameters:
+//   - toolsList: Tools to wrap
+//
+// Returns:
+//   - []tools.Tool: Tools refusing calls outside the execution's allowlist
+func WrapToolsWithRestrictions(toolsList []tools.Tool) []tools.Tool {
+	wrapped := make([]tools.Tool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		wrapped = append(wrapped, &RestrictedTool{tool: tool})
+	}
+	return wrapped
+}
+
+var _ tools.Tool = (*RestrictedTool)(nil)
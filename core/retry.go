@@ -0,0 +1,153 @@
+/*
+Package core provides retries for transiently failing tools in the Skynet Agent application.
+
+Some tools fail for reasons that resolve themselves within seconds: the network
+tool during a brief outage, docker while the daemon restarts, apk when a mirror
+hiccups. The RetryTool wrapper re-runs such calls with exponential backoff before
+reporting the failure to the agent, which would otherwise spend an iteration
+deciding to try again.
+
+Retries are opt-in per tool through configuration. Each retry is logged and
+reported to an optional observer attached to the execution context, which the
+streaming endpoint uses to surface retry attempts to the client.
+*/
+package core
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// retryObserverKey is the unexported context key type for retry observers.
+type retryObserverKey struct{}
+
+// RetryAttempt describes a failed tool call that is about to be retried.
+type RetryAttempt struct {
+	Tool        string        // Name of the retried tool
+	Attempt     int           // Number of the upcoming attempt, starting at 2
+	MaxAttempts int           // Total number of attempts allowed
+	Delay       time.Duration // Backoff before the upcoming attempt
+	Reason      string        // Error or error observation of the failed attempt
+}
+
+// WithRetryObserver returns a copy of the parent context that reports retries to observer.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - observer: Function called before every retry
+//
+// Returns:
+//   - context.Context: Derived context carrying the observer
+func WithRetryObserver(ctx context.Context, observer func(RetryAttempt)) context.Context {
+	return context.WithValue(ctx, retryObserverKey{}, observer)
+}
+
+// RetryTool wraps a tool and retries failed calls with exponential backoff.
+type RetryTool struct {
+	tool    tools.Tool     // The underlying tool being retried
+	retries int            // Maximum number of retries after the first attempt
+	backoff time.Duration  // Delay before the first retry, doubled for each further retry
+	logger  *logrus.Logger // Structured logger for retry reporting
+}
+
+// Name returns the wrapped tool's name.
+func (t *RetryTool) Name() string {
+	return t.tool.Name()
+}
+
+// Description returns the wrapped tool's description.
+func (t *RetryTool) Description() string {
+	return t.tool.Description()
+}
+
+// Call invokes the wrapped tool, retrying while it fails and the context is alive.
+// A call fails when the tool returns an error or an observation starting with "Error".
+//
+// Parameters:
+//   - ctx: Execution context, optionally carrying a retry observer
+//   - input: Tool input provided by the agent
+//
+// Returns:
+//   - string: Output of the first successful attempt, or of the last failed one
+//   - error: Error of the last attempt
+func (t *RetryTool) Call(ctx context.Context, input string) (string, error) {
+	observer, _ := ctx.Value(retryObserverKey{}).(func(RetryAttempt))
+	delay := t.backoff
+
+	for attempt := 1; ; attempt++ {
+		output, err := t.tool.Call(ctx, input)
+		if attempt > t.retries || !toolCallFailed(output, err) || ctx.Err() != nil {
+			return output, err
+		}
+
+		reason := strings.TrimSpace(output)
+		if err != nil {
+			reason = err.Error()
+		}
+		retry := RetryAttempt{
+			Tool:        t.tool.Name(),
+			Attempt:     attempt + 1,
+			MaxAttempts: t.retries + 1,
+			Delay:       delay,
+			Reason:      reason,
+		}
+
+		t.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"tool":        retry.Tool,
+			"attempt":     retry.Attempt,
+			"maxAttempts": retry.MaxAttempts,
+			"delay":       retry.Delay,
+			"reason":      retry.Reason,
+		}).Warn("Retrying tool after failure")
+		if observer != nil {
+			observer(retry)
+		}
+
+		select {
+		case <-ctx.Done():
+			return output, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// toolCallFailed reports whether a tool call failed, following the tool convention of
+// returning problems as observations starting with "Error".
+func toolCallFailed(output string, err error) bool {
+	return err != nil || strings.HasPrefix(strings.TrimSpace(output), "Error")
+}
+
+// WrapToolsWithRetries applies the configured retry policy to every tool that has one.
+// Tools without configured retries are returned unwrapped.
+//
+// Parameters:
+//   - toolsList: Tools to wrap
+//   - config: Configuration providing per-tool retries and the backoff
+//   - logger: Logger for retry reporting
+//
+// Returns:
+//   - []tools.Tool: Tools with retries applied
+func WrapToolsWithRetries(toolsList []tools.Tool, config *Config, logger *logrus.Logger) []tools.Tool {
+	wrapped := make([]tools.Tool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		retries := config.ToolRetries[tool.Name()]
+		if retries <= 0 {
+			wrapped = append(wrapped, tool)
+			continue
+		}
+		wrapped = append(wrapped, &RetryTool{
+			tool:    tool,
+			retries: retries,
+			backoff: config.ToolRetryBackoff,
+			logger:  logger,
+		})
+	}
+	return wrapped
+}
+
+var _ tools.Tool = (*RetryTool)(nil)
@@ -0,0 +1,178 @@
+/*
+Package core provides secret resolution for the configuration of the Skynet Agent application.
+
+API keys and tokens do not have to be stored in plain environment variables or
+configuration files. Every setting can be resolved in one of these ways:
+
+  - NAME_FILE: path of a file holding the value, such as a Docker or Kubernetes
+    secret mounted at /run/secrets/gemini_api_key. Trailing newlines are removed.
+  - vault:<path>#<field>: the field of a secret read from HashiCorp Vault, for
+    example GEMINI_API_KEY=vault:secret/data/skynet#gemini_api_key. VAULT_ADDR and
+    VAULT_TOKEN (or VAULT_TOKEN_FILE) select the server; KV version 1 and 2 paths
+    are supported.
+  - A configuration file encrypted with SOPS, which is decrypted with the sops
+    binary using the keys available to it.
+
+Secrets are resolved once at startup and on reload. A secret that cannot be
+resolved stops LoadConfig instead of leaving the setting empty.
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// vaultPrefix marks setting values resolved from Vault
+const vaultPrefix = "vault:"
+
+// fromFile reads the value of a setting from the file named by its NAME_FILE variant.
+//
+// Parameters:
+//   - name: Setting name, for error messages
+//   - path: File holding the value; empty returns an empty value
+//
+// Returns:
+//   - string: File content without trailing newlines
+func (s *configSource) fromFile(name, path string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		s.errs[name] = fmt.Errorf("failed to read %s_FILE: %w", name, err)
+		return ""
+	}
+	return strings.TrimRight(string(data), "\r\n")
+}
+
+// resolve replaces a "vault:<path>#<field>" reference by the secret it names.
+// Other values are returned unchanged.
+func (s *configSource) resolve(name, value string) string {
+	reference, ok := strings.CutPrefix(value, vaultPrefix)
+	if !ok {
+		return value
+	}
+
+	path, field, ok := strings.Cut(reference, "#")
+	if !ok || path == "" || field == "" {
+		s.errs[name] = fmt.Errorf("%s: Vault reference must be vault:<path>#<field>", name)
+		return ""
+	}
+
+	secret, fetched := s.secrets[path]
+	if !fetched {
+		var err error
+		if secret, err = readVaultSecret(path); err != nil {
+			s.errs[name] = fmt.Errorf("%s: %w", name, err)
+			return ""
+		}
+		s.secrets[path] = secret
+	}
+
+	resolved, ok := secret[field]
+	if !ok {
+		s.errs[name] = fmt.Errorf("%s: Vault secret %s has no field %q", name, path, field)
+		return ""
+	}
+	return resolved
+}
+
+// readVaultSecret reads a secret from the Vault server selected by VAULT_ADDR and VAULT_TOKEN.
+//
+// Parameters:
+//   - path: Secret path, such as secret/data/skynet for a KV version 2 engine
+//
+// Returns:
+//   - map[string]string: Secret fields
+//   - error: Missing Vault settings, request, or response error
+func readVaultSecret(path string) (map[string]string, error) {
+	address := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		if tokenFile := os.Getenv("VAULT_TOKEN_FILE"); tokenFile != "" {
+			data, err := os.ReadFile(tokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read VAULT_TOKEN_FILE: %w", err)
+			}
+			token = strings.TrimSpace(string(data))
+		}
+	}
+	if address == "" || token == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required to read %s", path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	url := strings.TrimRight(address, "/") + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault secret %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read Vault secret %s: %s", path, resp.Status)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid Vault response for %s: %w", path, err)
+	}
+
+	// KV version 2 nests the fields in data.data next to data.metadata
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+
+	secret := make(map[string]string, len(data))
+	for field, value := range data {
+		secret[field] = configScalar(value)
+	}
+	return secret, nil
+}
+
+// decryptSOPS decrypts a SOPS-encrypted configuration file with the sops binary.
+//
+// Parameters:
+//   - path: Encrypted YAML or TOML configuration file
+//
+// Returns:
+//   - map[string]interface{}: Decrypted configuration document
+//   - error: Decryption or parse error
+func decryptSOPS(path string) (map[string]interface{}, error) {
+	if _, err := exec.LookPath("sops"); err != nil {
+		return nil, fmt.Errorf("config file %s is encrypted with SOPS, but sops is not installed", path)
+	}
+
+	output, err := exec.Command("sops", "--decrypt", path).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to decrypt config file %s: %s", path, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to decrypt config file %s: %w", path, err)
+	}
+
+	document, err := parseConfigDocument(path, output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted config file %s: %w", path, err)
+	}
+	return document, nil
+}
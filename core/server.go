@@ -3,11 +3,15 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	localtools "skynet/tools"
@@ -15,6 +19,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 	"github.com/tmc/langchaingo/agents"
+	"github.com/tmc/langchaingo/callbacks"
 	"github.com/tmc/langchaingo/chains"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/googleai"
@@ -23,12 +28,51 @@ import (
 )
 
 type Server struct {
-	executor      *agents.Executor
-	toolsList     []tools.Tool
-	memoryStore   *MemoryStore
-	cancelManager *CancelManager
-	config        *Config
-	logger        *logrus.Logger
+	executor        *agents.Executor
+	toolsList       []tools.Tool
+	executorMutex   sync.RWMutex
+	llm             llms.Model
+	host            *HostInfo    // Environment of the host the agent runs on
+	cluster         *ClusterInfo // Kubernetes identity of the agent; nil outside cluster mode
+	workspace       *localtools.WorkspaceContext
+	registry        *ToolRegistry
+	customTools     *CustomToolStore
+	mcpClients      []*MCPClient
+	memoryStore     *MemoryStore
+	redactor        *Redactor // Masks secrets in streamed events; nil when redaction is disabled
+	cancelManager   *CancelManager
+	auditLog        *AuditLog
+	analytics       *Analytics
+	updater         *Updater
+	restartCh       chan struct{}
+	outputStore     *OutputStore
+	toolLimits      *ToolLimits
+	speechStore     *SpeechStore
+	toolStats       *ToolStats
+	readiness       *Readiness
+	queue           *RequestQueue
+	executionTraces *ExecutionTraceStore
+	logStream       *LogBroadcaster
+	errorReporter   *ErrorReporter
+	notifications   *Notifications
+	authGuard       *AuthGuard
+	apiKeys         *APIKeyStore
+	tenants         *TenantStore
+	quotas          *QuotaStore
+	hooks           map[string]*webhookHook
+	workflows       map[string]*Workflow
+	tasks           *TaskStore
+	tasksCtx        context.Context // Parent of the task runners, cancelled on Close
+	stopTasks       context.CancelFunc
+	stopMatrix      context.CancelFunc // Stops the Matrix bot; nil when it is not running
+	stopMQTT        context.CancelFunc // Stops the MQTT client; nil when it is not running
+	stopSSH         func()             // Closes the SSH chat listener; nil when it is not running
+	alerts          *alertInvestigations
+	probeCancel     context.CancelFunc
+	stopTracing     func(context.Context)
+	config          *Config
+	configMutex     sync.RWMutex
+	logger          *logrus.Logger
 }
 
 // NewServer creates a new server instance with all dependencies initialized
@@ -42,8 +86,19 @@ func NewServer(config *Config, logger *logrus.Logger) (*Server, error) {
 	}
 	logger.WithField("workingDir", workingDir).Info("Working directory set")
 
+	// The workspace is shared by every tool so directory changes are seen by all of them
+	workspace := localtools.NewWorkspaceContext(workingDir)
+
 	// Initialize memory store
-	memoryStore := NewMemoryStore(config.SessionMaxAge, config.CleanupInterval, logger)
+	redactor := configRedactor(config)
+	memoryStore := NewMemoryStore(config.SessionMaxAge, config.CleanupInterval, redactor, MemoryLimits{
+		MaxSessions: config.SessionMaxCount,
+		MaxMessages: config.SessionMaxMessages,
+		MaxBytes:    config.SessionMaxBytes,
+
+		MaxSessionMessages: config.SessionMessages,
+		MaxMessageBytes:    config.MessageMaxBytes,
+	}, logger)
 	logger.WithField("sessionMaxAge", config.SessionMaxAge).Info("Memory store initialized with configurable session expiry")
 
 	// Initialize LLM based on configured provider
@@ -112,74 +167,342 @@ func NewServer(config *Config, logger *logrus.Logger) (*Server, error) {
 		logger.Info("Ollama LLM initialized successfully")
 	}
 
+	// Stores encrypt the sensitive fields they persist when a key is set
+	fieldCipher, err := NewFieldCipher(config.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the tool audit log when configured
+	var auditLog *AuditLog
+	if config.AuditLogPath != "" {
+		auditLog, err = NewAuditLog(config.AuditLogPath, fieldCipher, logger)
+		if err != nil {
+			logger.WithError(err).WithField("path", config.AuditLogPath).Error("Failed to initialize audit log")
+			return nil, fmt.Errorf("failed to initialize audit log: %w", err)
+		}
+	}
+
+	// Report panics and failed executions to Sentry when configured
+	errorReporter, err := NewErrorReporter(config, logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to initialize error reporting")
+		return nil, fmt.Errorf("failed to initialize error reporting: %w", err)
+	}
+
+	// Deliver execution results to the configured notification sinks
+	notifications, err := NewNotifications(config, logger)
+	if err != nil {
+		logger.WithError(err).WithField("path", config.NotifiersFile).Error("Failed to initialize notifications")
+		return nil, fmt.Errorf("failed to initialize notifications: %w", err)
+	}
+
+	// Load the background tasks; running ones are resumed by ResumeTasks
+	tasks, err := NewTaskStore(config.TasksPath, fieldCipher, logger)
+	if err != nil {
+		logger.WithError(err).WithField("path", config.TasksPath).Error("Failed to load tasks")
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	// Load the API keys managed through /admin/keys
+	apiKeys, err := NewAPIKeyStore(config.APIKeysPath, logger)
+	if err != nil {
+		logger.WithError(err).WithField("path", config.APIKeysPath).Error("Failed to load API keys")
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
+	}
+
+	// Load the tenants that API keys can belong to
+	tenants, err := NewTenantStore(config.TenantsFile, logger)
+	if err != nil {
+		logger.WithError(err).WithField("path", config.TenantsFile).Error("Failed to load tenants")
+		return nil, fmt.Errorf("failed to load tenants: %w", err)
+	}
+
+	// Load the usage counted against the quotas of keys and tenants
+	quotas, err := NewQuotaStore(config.QuotaUsagePath, logger)
+	if err != nil {
+		logger.WithError(err).WithField("path", config.QuotaUsagePath).Error("Failed to load quota usage")
+		return nil, fmt.Errorf("failed to load quota usage: %w", err)
+	}
+
+	// Load the workflows run with POST /workflows/:name/run
+	workflows, err := loadWorkflows(config.WorkflowsFile)
+	if err != nil {
+		logger.WithError(err).WithField("path", config.WorkflowsFile).Error("Failed to load workflows file")
+		return nil, fmt.Errorf("failed to load workflows file: %w", err)
+	}
+
+	// Load the inbound webhook triggers served at /hooks/:name
+	hooks, err := loadHooks(config.HooksFile)
+	if err != nil {
+		logger.WithError(err).WithField("path", config.HooksFile).Error("Failed to load hooks file")
+		return nil, fmt.Errorf("failed to load hooks file: %w", err)
+	}
+
+	// Initialize conversation analytics when enabled
+	var analytics *Analytics
+	if config.AnalyticsEnabled {
+		analytics, err = NewAnalytics(config.AnalyticsLogPath, logger)
+		if err != nil {
+			logger.WithError(err).WithField("path", config.AnalyticsLogPath).Error("Failed to initialize conversation analytics")
+			return nil, fmt.Errorf("failed to initialize conversation analytics: %w", err)
+		}
+	}
+
+	// Load the resource limits tool commands run under
+	toolLimits, err := LoadToolLimits(config.ToolLimitsFile, config.ToolCgroupPath, logger)
+	if err != nil {
+		logger.WithError(err).WithField("path", config.ToolLimitsFile).Error("Failed to load tool limits")
+		return nil, fmt.Errorf("failed to load tool limits: %w", err)
+	}
+
+	// Initialize storage for truncated tool outputs so the agent can page through them
+	outputStore := NewOutputStore(config.ToolOutputRetention, logger)
+
 	// Wrap the LLM with the cleaning wrapper to handle think tags
 	cleanedLLM := NewCleaningLLMWrapper(llm, config, logger)
 	logger.Info("LLM wrapped with response cleaning functionality")
 
-	// Initialize tools slice
+	// Load tools registered at runtime through the tools API
+	customTools, err := NewCustomToolStore(config.CustomToolsPath, fieldCipher, logger)
+	if err != nil {
+		logger.WithError(err).WithField("path", config.CustomToolsPath).Error("Failed to load custom tools")
+		return nil, fmt.Errorf("failed to load custom tools: %w", err)
+	}
+
+	// Load operator-declared command tools from the tools file
+	commandToolDefinitions, err := LoadCommandTools(config.ToolsFilePath, config, logger)
+	if err != nil {
+		logger.WithError(err).WithField("path", config.ToolsFilePath).Error("Failed to load tools file")
+		return nil, fmt.Errorf("failed to load tools file: %w", err)
+	}
+
+	// Register the tools offered to the agent
 	logger.Debug("Initializing tools")
-	toolsList := []tools.Tool{
-		localtools.NewDateTimeTool(),
-		localtools.NewLsTool(),
-		localtools.NewCdTool(&workingDir),
-		localtools.NewTopTool(),
-		localtools.NewGrepTool(&workingDir),
-		localtools.NewStatTool(&workingDir),
-		localtools.NewCatTool(&workingDir),
-		localtools.NewFileTool(&workingDir),
-		localtools.NewShellTool(&workingDir),
-		localtools.NewTeeTool(&workingDir),
-		localtools.NewDockerTool(),
-		localtools.NewPsTool(),
-		localtools.NewNetstatTool(),
-		localtools.NewSysInfoTool(),
-		localtools.NewSystemctlTool(),
-		localtools.NewApkTool(),
-	}
-	logger.WithField("toolsCount", len(toolsList)).Info("Tools initialized")
-
-	// Create agent executor with ZeroShotReact pattern for better tool handling
-	logger.Debug("Creating agent executor with ZeroShotReact pattern")
-
-	// Create a general verbose callback handler for the executor
-	generalCallbackHandler := NewVerboseCallbackHandler(logger.WithField("component", "agent"), config)
-
-	// Create custom optimized prompt for minimal tool usage
-	customPrompt := CreateOptimizedPrompt(toolsList)
-
-	executor, err := agents.Initialize(
-		cleanedLLM,
-		toolsList,
-		agents.ZeroShotReactDescription,
-		agents.WithPrompt(customPrompt), // Use custom optimized prompt
-		agents.WithMaxIterations(config.MaxIterations),      // Use configured max iterations
-		agents.WithReturnIntermediateSteps(),                // Enable intermediate steps for debugging
-		agents.WithCallbacksHandler(generalCallbackHandler), // Add verbose logging
-	)
+	registry := NewToolRegistry()
+	for _, tool := range builtinTools(config, workspace, outputStore) {
+		if err := registry.Register(ToolSourceBuiltin, tool); err != nil {
+			return nil, fmt.Errorf("failed to register built-in tools: %w", err)
+		}
+	}
+
+	if analytics != nil {
+		if names := unclassifiedTools(registry.Tools()); len(names) > 0 {
+			logger.WithField("tools", names).Warn("Built-in tools belong to no analytics intent category")
+		}
+	}
+
+	// Command tools must not shadow built-in tools
+	for _, tool := range newCommandTools(commandToolDefinitions, workspace) {
+		if err := registry.Register(ToolSourceCommand, tool); err != nil {
+			return nil, fmt.Errorf("tools file declares %q, which conflicts with a built-in tool", tool.Name())
+		}
+	}
+
+	// Load third-party tools from the plugin directory, skipping any that shadow existing tools
+	for _, tool := range LoadPlugins(config.PluginDir, workspace, logger) {
+		if err := registry.Register(ToolSourcePlugin, tool); err != nil {
+			logger.WithError(err).WithField("tool", tool.Name()).Warn("Skipping plugin tool that conflicts with an existing tool")
+		}
+	}
+
+	// Connect to configured MCP servers and expose their tools
+	mcpServers, err := LoadMCPServers(config.MCPConfigPath)
 	if err != nil {
+		logger.WithError(err).WithField("path", config.MCPConfigPath).Error("Failed to load MCP config")
+		return nil, fmt.Errorf("failed to load MCP config: %w", err)
+	}
+	mcpClients, mcpTools := StartMCPServers(mcpServers, logger)
+	for _, tool := range mcpTools {
+		if err := registry.Register(ToolSourceMCP, tool); err != nil {
+			logger.WithError(err).WithField("tool", tool.Name()).Warn("Skipping MCP tool that conflicts with an existing tool")
+		}
+	}
+
+	server := &Server{
+		llm:             cleanedLLM,
+		host:            detectHost(logger),
+		cluster:         detectCluster(config, logger),
+		workspace:       workspace,
+		registry:        registry,
+		customTools:     customTools,
+		mcpClients:      mcpClients,
+		memoryStore:     memoryStore,
+		redactor:        redactor,
+		cancelManager:   NewCancelManager(),
+		auditLog:        auditLog,
+		analytics:       analytics,
+		updater:         NewUpdater(config, logger),
+		restartCh:       make(chan struct{}, 1),
+		outputStore:     outputStore,
+		toolLimits:      toolLimits,
+		speechStore:     NewSpeechStore(),
+		toolStats:       NewToolStats(),
+		readiness:       &Readiness{},
+		queue:           NewRequestQueue(config.MaxConcurrentRequests, config.QueueMaxSize),
+		executionTraces: NewExecutionTraceStore(redactor),
+		logStream:       NewLogBroadcaster(),
+		errorReporter:   errorReporter,
+		notifications:   notifications,
+		authGuard:       NewAuthGuard(config.AuthMaxFailures, config.AuthLockout, notifications, logger),
+		apiKeys:         apiKeys,
+		tenants:         tenants,
+		quotas:          quotas,
+		hooks:           hooks,
+		workflows:       workflows,
+		tasks:           tasks,
+		alerts:          &alertInvestigations{running: make(map[string]bool)},
+		stopTracing:     InitTracing(config, logger),
+		config:          config,
+		logger:          logger,
+	}
+
+	// Feed the admin log stream from the server logger and the tools' standard logger
+	logger.AddHook(server.logStream)
+	logrus.AddHook(server.logStream)
+
+	if err := server.rebuildExecutor(); err != nil {
 		logger.WithError(err).Error("Failed to initialize agent executor")
 		return nil, fmt.Errorf("failed to initialize agent executor: %w", err)
 	}
 
+	// Probe the LLM provider in the background; GET /readyz reports the result
+	probeCtx, probeCancel := context.WithCancel(context.Background())
+	server.probeCancel = probeCancel
+	go server.probeLLMWithRetry(probeCtx)
+
+	// Task runners started later by ResumeTasks or the API stop with the server
+	server.tasksCtx, server.stopTasks = context.WithCancel(context.Background())
+
 	logger.Info("Server initialization completed successfully")
-	return &Server{
-		executor:      executor,
-		toolsList:     toolsList,
-		memoryStore:   memoryStore,
-		cancelManager: NewCancelManager(),
-		config:        config,
-		logger:        logger,
-	}, nil
+	return server, nil
 }
 
-func (s *Server) handleChat(c echo.Context) error {
-	requestID := c.Request().Header.Get("X-Request-ID")
-	if requestID == "" {
-		requestID = fmt.Sprintf("req_%d", time.Now().UnixNano())
+// Close releases resources held by the server, such as MCP server processes
+func (s *Server) Close() {
+	s.probeCancel()
+
+	// Interrupted task steps are not checkpointed and run again after a restart
+	s.stopTasks()
+	if s.stopMatrix != nil {
+		s.stopMatrix()
+	}
+	if s.stopMQTT != nil {
+		s.stopMQTT()
+	}
+	if s.stopSSH != nil {
+		s.stopSSH()
+	}
+	for _, client := range s.mcpClients {
+		client.Close()
+	}
+	s.toolLimits.Close()
+	if s.auditLog != nil {
+		if err := s.auditLog.Close(); err != nil {
+			s.logger.WithError(err).Error("Failed to close audit log")
+		}
 	}
 
-	requestLogger := s.logger.WithFields(logrus.Fields{
-		"requestId": requestID,
+	// Export the spans, error reports, and notifications of the last requests before exiting
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.stopTracing(ctx)
+	s.errorReporter.Close(5 * time.Second)
+	s.notifications.Close(5 * time.Second)
+}
+
+// wrapTools applies the standard execution wrappers (resource limits and priority,
+// timeouts, audit, truncation, caching, tracing, usage tracking, and restrictions)
+// to a tool list
+func (s *Server) wrapTools(toolsList []tools.Tool) []tools.Tool {
+	toolsList = WrapToolsWithLimits(toolsList, s.toolLimits, s.currentConfig())
+	toolsList = WrapToolsWithTimeouts(toolsList, s.currentConfig(), s.logger)
+	toolsList = WrapToolsWithRetries(toolsList, s.currentConfig(), s.logger)
+	toolsList = WrapToolsWithStats(toolsList, s.toolStats)
+	toolsList = WrapToolsWithAudit(toolsList, s.auditLog)
+	toolsList = WrapToolsWithTruncation(toolsList, s.currentConfig(), s.outputStore)
+	toolsList = WrapToolsWithCache(toolsList, s.currentConfig(), s.auditLog)
+	toolsList = WrapToolsWithTracing(toolsList)
+	if s.analytics != nil {
+		toolsList = WrapToolsWithUsageTracking(toolsList)
+	}
+
+	// Outermost, so refused calls are never run, audited, or counted
+	toolsList = WrapToolsWithRestrictions(toolsList)
+	return toolsList
+}
+
+// rebuildExecutor creates the agent executor from the built-in and custom tools
+// and swaps it in, so newly registered tools are available to subsequent requests
+func (s *Server) rebuildExecutor() error {
+	for _, err := range s.registry.ReplaceSource(ToolSourceCustom, s.customTools.Tools(s.workspace)) {
+		s.logger.WithError(err).Warn("Skipping custom tool that conflicts with an existing tool")
+	}
+	rawTools := s.registry.Tools(EnabledTools(s.currentConfig()))
+	toolsList := s.wrapTools(rawTools)
+	s.logger.WithField("toolsCount", len(toolsList)).Info("Tools initialized")
+
+	s.logger.WithField("agentMode", s.currentConfig().AgentMode).Debug("Creating agent executor")
+
+	// Route agent events to the handler of each execution; debug requests attach a
+	// streaming handler, all others fall back to the general verbose handler
+	generalCallbackHandler := NewVerboseCallbackHandler(s.logger.WithField("component", "agent"), s.currentConfig())
+	executor := s.newExecutor(s.llm, toolsList, NewRoutingCallbackHandler(generalCallbackHandler))
+
+	s.executorMutex.Lock()
+	s.executor = executor
+	s.toolsList = toolsList
+	s.executorMutex.Unlock()
+	return nil
+}
+
+// newExecutor creates an agent executor for the configured agent mode. Independent
+// read-only tool calls requested in the same step are run concurrently.
+//
+// Parameters:
+//   - llm: Model used for planning
+//   - toolsList: Wrapped tools available to the agent
+//   - handler: Callback handler receiving agent events
+//
+// Returns:
+//   - *agents.Executor: Executor ready to be run with chains.Run
+func (s *Server) newExecutor(llm llms.Model, toolsList []tools.Tool, handler callbacks.Handler) *agents.Executor {
+	var agent agents.Agent
+	if s.currentConfig().AgentMode == AgentModeFunctions {
+		agent = NewFunctionCallingAgent(llm, toolsList, s.host, s.cluster)
+	} else {
+		// ZeroShotReact pattern with the custom optimized prompt for minimal tool usage
+		agent = agents.NewOneShotAgent(llm, toolsList,
+			agents.WithPrompt(CreateOptimizedPrompt(toolsList, s.host, s.cluster)),
+			agents.WithCallbacksHandler(handler),
+		)
+	}
+
+	return agents.NewExecutor(
+		NewParallelAgent(agent, toolsList, s.currentConfig(), s.logger),
+		agents.WithMaxIterations(s.currentConfig().MaxIterations), // Use configured max iterations
+		agents.WithReturnIntermediateSteps(),                      // Enable intermediate steps for debugging
+		agents.WithCallbacksHandler(handler),
+	)
+}
+
+// currentConfig returns the active configuration, which a reload may replace
+func (s *Server) currentConfig() *Config {
+	s.configMutex.RLock()
+	defer s.configMutex.RUnlock()
+	return s.config
+}
+
+// currentExecutor returns the active agent executor
+func (s *Server) currentExecutor() *agents.Executor {
+	s.executorMutex.RLock()
+	defer s.executorMutex.RUnlock()
+	return s.executor
+}
+
+func (s *Server) handleChat(c echo.Context) error {
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"requestId": localtools.RequestIDFromContext(c.Request().Context()),
 		"endpoint":  "/chat",
 		"method":    "POST",
 		"clientIP":  c.RealIP(),
@@ -192,23 +515,130 @@ func (s *Server) handleChat(c echo.Context) error {
 		requestLogger.WithError(err).Error("Failed to parse request body")
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+	if limited, err := s.rejectRateLimited(c, caller, requestLogger); limited {
+		return err
+	}
+	if exceeded, err := s.rejectQuotaExceeded(c, caller, requestLogger); exceeded {
+		return err
+	}
+
+	// Tool commands run on the requested target
+	target, err := s.resolveTarget(req.Target)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	requestLogger = requestLogger.WithField("target", target.String())
+
+	images, err := loadAttachments(c.Request().Context(), req.Attachments)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := checkFileAttachments(req.Files); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Wait for an execution slot; the client disconnecting leaves the queue
+	ticket, err := s.queue.Enqueue()
+	if err != nil {
+		return s.rejectQueueFull(c, requestLogger)
+	}
+	defer ticket.Release()
+	if err := ticket.Wait(c.Request().Context(), nil); err != nil {
+		requestLogger.WithError(err).Info("Client left the request queue")
+		return nil
+	}
+
+	// The execution outlives a disconnected client but stays part of the request's trace
+	ctx := localtools.WithTarget(context.WithoutCancel(c.Request().Context()), target)
+	ctx = withAttachments(ctx, images)
+	ctx = s.withCaller(ctx, caller)
+
+	// Attached files are saved in the session's workspace and listed in the message
+	session := s.memoryStore.GetOrCreateOwnedSession(req.SessionID, caller.owner, caller.tenant)
+	if !caller.mayUse(session) {
+		return s.rejectForeignSession(c, session, requestLogger)
+	}
+	paths, err := saveFileAttachments(session.Workspace(s.workspace.Dir()), session.ID, req.Files)
+	if err != nil {
+		requestLogger.WithError(err).Error("Failed to save attached files")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	response, err := s.chat(ctx, session.ID, describeFiles(req.Message, paths), c.RealIP(), requestLogger)
+	if err == nil && req.Speech {
+		response.SpeechURL = s.speak(ctx, session, response.Response, requestLogger)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// Chat runs one message through the agent in a session, as POST /chat does, for
+// clients inside the process such as the command line interface.
+//
+// Parameters:
+//   - ctx: Parent context; the configured request timeout is applied on top
+//   - sessionID: Session to continue; empty or unknown creates a new session
+//   - message: User message
+//
+// Returns:
+//   - ChatResponse: Agent response, or a user-facing error message, and the session ID
+//   - error: Agent execution error, or nil
+func (s *Server) Chat(ctx context.Context, sessionID, message string) (ChatResponse, error) {
+	ctx = ensureRequestID(ctx)
+	return s.chat(ctx, sessionID, message, "cli", s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"interface": "cli",
+		"requestId": localtools.RequestIDFromContext(ctx),
+	}))
+}
 
+// chat runs one message through the agent with the session's memory and workspace.
+// On failure the response carries a user-facing error message and is not stored in memory.
+func (s *Server) chat(ctx context.Context, sessionID, message, user string, requestLogger *logrus.Entry) (ChatResponse, error) {
 	// Get or create chat session
-	session := s.memoryStore.GetOrCreateSession(req.SessionID)
+	session := s.memoryStore.GetOrCreateSession(sessionID)
 
 	requestLogger.WithFields(logrus.Fields{
 		"sessionID":     session.ID,
-		"messageLength": len(req.Message),
-		"message":       req.Message,
+		"messageLength": len(message),
+		"message":       message,
 		"messageCount":  len(session.Messages),
 	}).Debug("Chat request details with session info")
 
 	// Add user message to session memory
-	session.AddMessage("user", req.Message)
+	session.AddMessage("user", describeAttachments(message, attachmentsFromContext(ctx)))
+
+	// Generate execution ID for audit correlation and cancellation
+	executionID := fmt.Sprintf("exec_%d", time.Now().UnixNano())
 
 	// Create context with timeout to prevent long-running requests
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.RequestTimeout)
-	defer cancel()
+	ctx, cancel := context.WithTimeout(ctx, s.currentConfig().RequestTimeout)
+	defer func() {
+		s.cancelManager.RemoveExecution(executionID)
+		cancel()
+	}()
+
+	// Attach execution metadata so tool wrappers can attribute invocations
+	execInfo := ExecutionInfo{
+		SessionID:   session.ID,
+		ExecutionID: executionID,
+		User:        user,
+		Owner:       session.Owner,
+		Tenant:      callerFromContext(ctx).tenant,
+	}
+
+	// Register execution so POST /stop can cancel it
+	s.cancelManager.AddExecution(execInfo, localtools.RequestIDFromContext(ctx), cancel)
+
+	ctx = WithExecutionInfo(ctx, execInfo)
+	ctx = localtools.WithWorkspace(ctx, session.Workspace(s.workspace.Dir()))
+	ctx = localtools.WithApprovals(ctx, session.Approvals())
+	ctx, toolUsage := WithToolUsageRecorder(ctx)
+	ctx, tokenMeter := WithTokenMeter(ctx)
+	ctx = WithToolCache(ctx)
+	ctx, executionTrace := s.executionTraces.Start(ctx, execInfo)
 
 	startTime := time.Now()
 
@@ -218,8 +648,8 @@ func (s *Server) handleChat(c echo.Context) error {
 	var messageWithContext string
 	if len(session.Messages) > 1 { // More than just the current message
 		// Include recent conversation history
-		conversationContext := session.GetConversationContext(s.config.ContextLimit)
-		messageWithContext = conversationContext + "Human: " + req.Message
+		conversationContext := session.GetConversationContext(s.currentConfig().ContextLimit)
+		messageWithContext = conversationContext + "Human: " + message
 
 		requestLogger.WithFields(logrus.Fields{
 			"sessionID":      session.ID,
@@ -227,24 +657,34 @@ func (s *Server) handleChat(c echo.Context) error {
 			"contextLength":  len(conversationContext),
 		}).Debug("Including conversation context in request")
 	} else {
-		messageWithContext = req.Message
+		messageWithContext = message
 		requestLogger.WithField("sessionID", session.ID).Debug("No previous context, using message as-is")
 	}
 
 	// Use chains.Run directly with the executor
-	result, err := chains.Run(ctx, s.executor, messageWithContext)
+	result, err := chains.Run(ctx, s.currentExecutor(), messageWithContext)
 	executionTime := time.Since(startTime)
+	executionTrace.Finish(ctx, err)
+	s.tenants.RecordExecution(execInfo.Tenant, executionTime, err)
+	s.recordTokens(ctx, tokenMeter)
+	s.submitAnalytics(ctx, execInfo, message, toolUsage, err)
+	s.notifications.NotifyExecution(ctx, message, result, executionTime, err)
 
 	if err != nil {
+		s.errorReporter.CaptureError(ctx, err)
+
 		// Log the error for debugging
 		requestLogger.WithError(err).WithFields(logrus.Fields{
 			"sessionID":     session.ID,
 			"executionTime": executionTime,
-			"message":       req.Message,
+			"message":       message,
 		}).Error("Agent execution failed")
 
 		// Provide a more helpful error message to the user
 		errorMsg := s.getErrorMessage(err)
+		if errors.Is(ctx.Err(), context.Canceled) {
+			errorMsg = "Agent execution was stopped"
+		}
 
 		// Don't add error responses to memory
 		requestLogger.WithFields(logrus.Fields{
@@ -254,10 +694,11 @@ func (s *Server) handleChat(c echo.Context) error {
 			"executionTime": executionTime,
 		}).Warn("Returning error response to user")
 
-		return c.JSON(http.StatusOK, ChatResponse{
-			Response:  errorMsg,
-			SessionID: session.ID,
-		})
+		return ChatResponse{
+			Response:    errorMsg,
+			SessionID:   session.ID,
+			ExecutionID: executionID,
+		}, err
 	}
 
 	// Add assistant response to session memory
@@ -271,20 +712,16 @@ func (s *Server) handleChat(c echo.Context) error {
 		"messageCount":   len(session.Messages),
 	}).Info("Agent execution completed successfully with memory updated")
 
-	return c.JSON(http.StatusOK, ChatResponse{
-		Response:  result,
-		SessionID: session.ID,
-	})
+	return ChatResponse{
+		Response:    result,
+		SessionID:   session.ID,
+		ExecutionID: executionID,
+	}, nil
 }
 
 func (s *Server) handleStreamChat(c echo.Context) error {
-	requestID := c.Request().Header.Get("X-Request-ID")
-	if requestID == "" {
-		requestID = fmt.Sprintf("stream_req_%d", time.Now().UnixNano())
-	}
-
-	requestLogger := s.logger.WithFields(logrus.Fields{
-		"requestId": requestID,
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"requestId": localtools.RequestIDFromContext(c.Request().Context()),
 		"endpoint":  "/chat/stream",
 		"method":    "POST",
 		"clientIP":  c.RealIP(),
@@ -297,9 +734,50 @@ func (s *Server) handleStreamChat(c echo.Context) error {
 		requestLogger.WithError(err).Error("Failed to parse streaming request body")
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+	if limited, err := s.rejectRateLimited(c, caller, requestLogger); limited {
+		return err
+	}
+	if exceeded, err := s.rejectQuotaExceeded(c, caller, requestLogger); exceeded {
+		return err
+	}
+
+	// Tool commands run on the requested target
+	target, err := s.resolveTarget(req.Target)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	requestLogger = requestLogger.WithField("target", target.String())
+
+	images, err := loadAttachments(c.Request().Context(), req.Attachments)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := checkFileAttachments(req.Files); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	verbosity, err := s.streamVerbosity(req.Verbosity)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	c.Set(verbosityKey, verbosity)
+
+	// Reserve a place in the request queue before the stream starts, so a full
+	// queue can still be rejected with a status code
+	ticket, err := s.queue.Enqueue()
+	if err != nil {
+		return s.rejectQueueFull(c, requestLogger)
+	}
+	defer ticket.Release()
 
 	// Get or create chat session
-	session := s.memoryStore.GetOrCreateSession(req.SessionID)
+	session := s.memoryStore.GetOrCreateOwnedSession(req.SessionID, caller.owner, caller.tenant)
+	if !caller.mayUse(session) {
+		return s.rejectForeignSession(c, session, requestLogger)
+	}
 
 	requestLogger.WithFields(logrus.Fields{
 		"sessionID":     session.ID,
@@ -308,20 +786,52 @@ func (s *Server) handleStreamChat(c echo.Context) error {
 		"messageCount":  len(session.Messages),
 	}).Debug("Streaming chat request details with session info")
 
-	// Add user message to session memory
-	session.AddMessage("user", req.Message)
-
 	c.Response().Header().Set("Content-Type", "text/event-stream")
 	c.Response().Header().Set("Cache-Control", "no-cache")
 	c.Response().Header().Set("Connection", "keep-alive")
 	c.Response().Header().Set("Access-Control-Allow-Origin", "*")
 
+	// Keep the connection alive through the queue wait and long tool calls
+	heartbeat := s.startHeartbeat(c)
+	defer heartbeat.Stop()
+
 	// Send session ID to client first
 	s.sendStreamMessage(c, StreamMessage{
 		Type:    "session",
 		Content: session.ID,
 	})
 
+	// Report the queue position until an execution slot is free
+	err = ticket.Wait(c.Request().Context(), func(position int, eta time.Duration) {
+		s.sendStreamMessage(c, StreamMessage{
+			Type:    "queued",
+			Content: fmt.Sprintf("Waiting in queue (position %d, about %s)", position, eta.Round(time.Second)),
+			Details: map[string]interface{}{
+				"position":   position,
+				"etaSeconds": int(eta.Seconds()),
+			},
+		})
+	})
+	if err != nil {
+		requestLogger.WithError(err).Info("Client left the request queue")
+		return nil
+	}
+
+	// Attached files are saved in the session's workspace and listed in the message
+	paths, err := saveFileAttachments(session.Workspace(s.workspace.Dir()), session.ID, req.Files)
+	if err != nil {
+		requestLogger.WithError(err).Error("Failed to save attached files")
+		s.sendStreamMessage(c, StreamMessage{
+			Type:    "error",
+			Content: err.Error(),
+		})
+		return nil
+	}
+	req.Message = describeFiles(req.Message, paths)
+
+	// Add user message to session memory once the request runs
+	session.AddMessage("user", describeAttachments(req.Message, images))
+
 	// Generate execution ID for tracking and cancellation
 	executionID := fmt.Sprintf("exec_%d", time.Now().UnixNano())
 
@@ -332,17 +842,63 @@ func (s *Server) handleStreamChat(c echo.Context) error {
 	})
 
 	// Create context with timeout to prevent long-running requests
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.RequestTimeout)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request().Context()), s.currentConfig().RequestTimeout)
 	defer func() {
 		// Always remove execution when done
 		s.cancelManager.RemoveExecution(executionID)
 		cancel()
 	}()
 
+	// Attach execution metadata so tool wrappers can attribute invocations
+	execInfo := ExecutionInfo{
+		SessionID:   session.ID,
+		ExecutionID: executionID,
+		User:        c.RealIP(),
+		Owner:       session.Owner,
+		Tenant:      caller.tenant,
+	}
+
 	// Register execution for cancellation
-	s.cancelManager.AddExecution(executionID, cancel)
+	s.cancelManager.AddExecution(execInfo, localtools.RequestIDFromContext(ctx), cancel)
+
+	// Commands stop running for a client that went away, after a grace period
+	// that lets an execution about to finish complete and reach session memory
+	stopWatching := s.cancelOnDisconnect(c.Request().Context(), executionID, requestLogger)
+	defer stopWatching()
+
+	ctx = WithExecutionInfo(ctx, execInfo)
+	ctx = localtools.WithWorkspace(ctx, session.Workspace(s.workspace.Dir()))
+	ctx = localtools.WithApprovals(ctx, session.Approvals())
+	ctx = localtools.WithTarget(ctx, target)
+	ctx = withAttachments(ctx, images)
+	ctx = s.withCaller(ctx, caller)
+	ctx, toolUsage := WithToolUsageRecorder(ctx)
+	ctx, tokenMeter := WithTokenMeter(ctx)
+	ctx = WithToolCache(ctx)
+	ctx, executionTrace := s.executionTraces.Start(ctx, execInfo)
+
+	// Report transient tool failures that are being retried to the client.
+	// Parallel tool calls may retry concurrently, so writes to the stream are serialized.
+	var retryMutex sync.Mutex
+	ctx = WithRetryObserver(ctx, func(retry RetryAttempt) {
+		retryMutex.Lock()
+		defer retryMutex.Unlock()
+		s.sendStreamMessage(c, StreamMessage{
+			Type:    "debug",
+			Content: fmt.Sprintf("Retrying %s (attempt %d of %d) in %s", retry.Tool, retry.Attempt, retry.MaxAttempts, retry.Delay),
+			Tool:    retry.Tool,
+			Debug:   true,
+			Details: map[string]interface{}{
+				"attempt":     retry.Attempt,
+				"maxAttempts": retry.MaxAttempts,
+				"delayMs":     retry.Delay.Milliseconds(),
+				"reason":      retry.Reason,
+			},
+		})
+	})
 
 	startTime := time.Now()
+	heartbeat.ExecutionStarted(executionTrace, startTime)
 
 	requestLogger.WithFields(logrus.Fields{
 		"sessionID":   session.ID,
@@ -359,7 +915,7 @@ func (s *Server) handleStreamChat(c echo.Context) error {
 	var messageWithContext string
 	if len(session.Messages) > 1 { // More than just the current message
 		// Include recent conversation history
-		conversationContext := session.GetConversationContext(s.config.ContextLimit)
+		conversationContext := session.GetConversationContext(s.currentConfig().ContextLimit)
 		messageWithContext = conversationContext + "Human: " + req.Message
 
 		requestLogger.WithFields(logrus.Fields{
@@ -373,10 +929,18 @@ func (s *Server) handleStreamChat(c echo.Context) error {
 	}
 
 	// Create a custom chain wrapper to capture intermediate steps
-	result, err := s.executeWithStreaming(ctx, messageWithContext, s.config.DebugMode, c, requestLogger)
+	result, err := s.executeWithStreaming(ctx, messageWithContext, verbosity, c, requestLogger)
 	executionTime := time.Since(startTime)
+	heartbeat.Stop()
+	executionTrace.Finish(ctx, err)
+	s.tenants.RecordExecution(execInfo.Tenant, executionTime, err)
+	s.recordTokens(ctx, tokenMeter)
+	s.submitAnalytics(ctx, execInfo, req.Message, toolUsage, err)
+	s.notifications.NotifyExecution(ctx, req.Message, result, executionTime, err)
 
 	if err != nil {
+		s.errorReporter.CaptureError(ctx, err)
+
 		requestLogger.WithError(err).WithFields(logrus.Fields{
 			"sessionID":     session.ID,
 			"executionID":   executionID,
@@ -430,11 +994,65 @@ func (s *Server) handleStreamChat(c echo.Context) error {
 		Complete: true,
 	})
 
+	// The spoken answer follows the text, which the client can show meanwhile
+	if req.Speech {
+		if speechURL := s.speak(ctx, session, result, requestLogger); speechURL != "" {
+			s.sendStreamMessage(c, StreamMessage{
+				Type:     "speech",
+				Content:  speechURL,
+				Complete: true,
+			})
+		}
+	}
+
 	return nil
 }
 
-func (s *Server) executeWithStreaming(ctx context.Context, message string, debug bool, c echo.Context, requestLogger *logrus.Entry) (string, error) {
-	requestLogger.WithField("debugMode", debug).Debug("Starting streaming execution")
+// cancelOnDisconnect cancels an execution once its stream client has been gone for
+// DISCONNECT_GRACE.
+//
+// Parameters:
+//   - client: Context of the client's request, done when the client disconnects
+//   - executionID: Registered execution to cancel
+//   - requestLogger: Logger for the abort
+//
+// Returns:
+//   - func(): Stops watching; call it when the execution ends
+func (s *Server) cancelOnDisconnect(client context.Context, executionID string, requestLogger *logrus.Entry) func() {
+	grace := s.currentConfig().DisconnectGrace
+	if grace < 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-client.Done():
+		}
+		requestLogger.WithFields(logrus.Fields{
+			"executionID": executionID,
+			"grace":       grace,
+		}).Info("Stream client disconnected during execution")
+
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+		if s.cancelManager.CancelExecution(executionID) {
+			requestLogger.WithField("executionID", executionID).Warn("Cancelled execution of disconnected stream client")
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (s *Server) executeWithStreaming(ctx context.Context, message string, verbosity Verbosity, c echo.Context, requestLogger *logrus.Entry) (string, error) {
+	requestLogger.WithField("verbosity", verbosity).Debug("Starting streaming execution")
+	debug := verbosity == VerbosityDebug
 
 	// Send thinking message
 	s.sendStreamMessage(c, StreamMessage{
@@ -453,145 +1071,36 @@ func (s *Server) executeWithStreaming(ctx context.Context, message string, debug
 	func() {
 		defer func() {
 			if r := recover(); r != nil {
+				s.errorReporter.CapturePanic(ctx, r)
 				requestLogger.WithField("panic", r).Error("Panic occurred during execution")
 				err = fmt.Errorf("execution failed due to internal error: %v", r)
 			}
 		}()
 
-		if debug {
-			// Create a custom executor with streaming callback handler for debug mode
-			requestLogger.Info("Creating debug-enabled executor with streaming callbacks")
-
-			// Get the working directory for tools
-			workingDir, dirErr := os.Getwd()
-			if dirErr != nil {
-				requestLogger.WithError(dirErr).Error("Failed to get working directory for debug executor")
-				err = fmt.Errorf("failed to get working directory: %w", dirErr)
-				return
-			}
-
-			// Initialize LLM based on configured provider
-			var llm llms.Model
-
-			switch s.config.LLMProvider {
-			case "gemini":
-				requestLogger.WithField("provider", "gemini").Info("Initializing Gemini LLM")
-
-				// Validate API key for Gemini
-				if s.config.GeminiAPIKey == "" {
-					requestLogger.Error("Gemini API key is required when using gemini provider")
-					return
-				}
-
-				modelName := s.config.GeminiModel
-				if modelName == "" {
-					modelName = "gemini-1.5-pro"
-				}
-				requestLogger.WithField("model", modelName).Info("Using Gemini model")
-
-				requestLogger.Debug("Initializing Gemini LLM connection")
-				llm, err = googleai.New(
-					context.Background(),
-					googleai.WithAPIKey(s.config.GeminiAPIKey),
-					googleai.WithDefaultModel(modelName),
-				)
-				if err != nil {
-					requestLogger.WithError(err).WithFields(logrus.Fields{
-						"provider": "gemini",
-						"model":    modelName,
-					}).Error("Failed to initialize Gemini LLM")
-					return
-				}
-				requestLogger.Info("Gemini LLM initialized successfully")
-
-			case "ollama":
-				fallthrough
-			default:
-				requestLogger.WithField("provider", "ollama").Info("Initializing Ollama LLM")
-
-				ollamaEndpoint := s.config.OllamaEndpoint
-				if ollamaEndpoint == "" {
-					ollamaEndpoint = "http://localhost:11434"
-				}
-				requestLogger.WithField("endpoint", ollamaEndpoint).Info("Using Ollama endpoint")
-
-				modelName := s.config.OllamaModel
-				if modelName == "" {
-					modelName = "qwen3"
-				}
-				requestLogger.WithField("model", modelName).Info("Using Ollama model")
-
-				requestLogger.Debug("Initializing Ollama LLM connection")
-				llm, err = ollama.New(
-					ollama.WithServerURL(ollamaEndpoint),
-					ollama.WithModel(modelName),
-				)
-				if err != nil {
-					requestLogger.WithError(err).WithFields(logrus.Fields{
-						"endpoint": ollamaEndpoint,
-						"model":    modelName,
-					}).Error("Failed to initialize Ollama LLM")
-					return
-				}
-				requestLogger.Info("Ollama LLM initialized successfully")
-			}
-
-			// Wrap the debug LLM with cleaning wrapper too
-			cleanedDebugLLM := NewCleaningLLMWrapper(llm, s.config, s.logger)
-
-			// Create streaming callback handler
+		execCtx := ctx
+		switch verbosity {
+		case VerbosityDebug:
+			// Stream the agent's intermediate steps to this client through the shared executor
+			requestLogger.Info("Attaching streaming callbacks for debug mode")
 			streamingHandler := NewStreamingCallbackHandler(
 				requestLogger.WithField("component", "debug_agent"),
-				s.config,
+				s.currentConfig(),
 				func(msg StreamMessage) {
 					s.sendStreamMessage(c, msg)
 				},
 			)
-
-			// Initialize tools for debug executor
-			debugToolsList := []tools.Tool{
-				localtools.NewDateTimeTool(),
-				localtools.NewLsTool(),
-				localtools.NewCdTool(&workingDir),
-				localtools.NewTopTool(),
-				localtools.NewGrepTool(&workingDir),
-				localtools.NewStatTool(&workingDir),
-				localtools.NewCatTool(&workingDir),
-				localtools.NewFileTool(&workingDir),
-				localtools.NewShellTool(&workingDir),
-				localtools.NewTeeTool(&workingDir),
-				localtools.NewDockerTool(),
-				localtools.NewPsTool(),
-				localtools.NewNetstatTool(),
-				localtools.NewSysInfoTool(),
-				localtools.NewSystemctlTool(),
-				localtools.NewApkTool(),
-			}
-
-			// Create debug executor with streaming callbacks
-			customPrompt := CreateOptimizedPrompt(debugToolsList)
-
-			debugExecutor, execErr := agents.Initialize(
-				cleanedDebugLLM, // Use cleaned LLM wrapper
-				debugToolsList,
-				agents.ZeroShotReactDescription,
-				agents.WithPrompt(customPrompt),                  // Use same optimized prompt as main executor
-				agents.WithMaxIterations(s.config.MaxIterations), // Reduced to match main executor
-				agents.WithReturnIntermediateSteps(),
-				agents.WithCallbacksHandler(streamingHandler),
-			)
-			if execErr != nil {
-				requestLogger.WithError(execErr).Error("Failed to initialize debug executor")
-				err = fmt.Errorf("failed to initialize debug executor: %w", execErr)
-				return
-			}
-
-			// Use the debug executor
-			result, err = chains.Run(ctx, debugExecutor, message)
-		} else {
-			// Use the standard executor for non-debug mode
-			result, err = chains.Run(ctx, s.executor, message)
+			execCtx = WithCallbackHandler(ctx, streamingHandler)
+		case VerbosityTools:
+			// Stream tool calls and their output
+			execCtx = WithCallbackHandler(ctx, NewToolStreamHandler(
+				requestLogger.WithField("component", "agent"),
+				s.currentConfig(),
+				func(msg StreamMessage) {
+					s.sendStreamMessage(c, msg)
+				},
+			))
 		}
+		result, err = chains.Run(execCtx, s.currentExecutor(), message)
 
 		// Handle specific parsing errors
 		if err != nil && strings.Contains(err.Error(), "unable to parse agent output") {
@@ -658,9 +1167,15 @@ func (s *Server) executeWithStreaming(ctx context.Context, message string, debug
 }
 
 func (s *Server) sendStreamMessage(c echo.Context, msg StreamMessage) {
+	if !streamIncludes(c, msg) {
+		return
+	}
+	// The final answer is left intact for the client who asked for it
+	if eventVerbosity(msg) != VerbosityFinal {
+		msg = s.redactor.RedactMessage(msg)
+	}
 	data, _ := json.Marshal(msg)
-	fmt.Fprintf(c.Response(), "data: %s\n\n", string(data))
-	c.Response().Flush()
+	s.writeStream(c, fmt.Sprintf("data: %s\n\n", string(data)))
 }
 
 func (s *Server) getErrorMessage(err error) string {
@@ -677,9 +1192,25 @@ func (s *Server) getErrorMessage(err error) string {
 	return errorMsg
 }
 
+// submitAnalytics queues a completed execution for conversation tagging when analytics is enabled
+func (s *Server) submitAnalytics(ctx context.Context, info ExecutionInfo, message string, toolUsage *ToolUsageRecorder, err error) {
+	if s.analytics == nil {
+		return
+	}
+
+	outcome := OutcomeSuccess
+	if ctx.Err() == context.Canceled {
+		outcome = OutcomeCancelled
+	} else if err != nil {
+		outcome = OutcomeFailed
+	}
+
+	s.analytics.Submit(info, message, toolUsage.Tools(), outcome)
+}
+
 func (s *Server) cleanAgentResponse(response string) string {
 	// Create a temporary cleaning LLM wrapper to use the cleaning functionality
-	tempWrapper := NewCleaningLLMWrapper(nil, s.config, s.logger)
+	tempWrapper := NewCleaningLLMWrapper(nil, s.currentConfig(), s.logger)
 	return tempWrapper.CleanAgentResponse(response)
 }
 
@@ -692,20 +1223,24 @@ func (s *Server) handleStatus(c echo.Context) error {
 
 	requestLogger.Debug("Health check requested")
 
-	workingDir, _ := os.Getwd()
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
 
 	// Include memory store statistics
 	memoryStats := s.memoryStore.GetSessionStats()
 
-	// Include active executions
-	activeExecutions := s.cancelManager.GetActiveExecutions()
+	// Include the active executions the caller may see
+	activeExecutions := s.cancelManager.GetActiveExecutions(caller)
 
 	response := map[string]interface{}{
 		"status":           "healthy",
-		"workingDir":       workingDir,
+		"workingDir":       s.workspace.Dir(),
 		"memory":           memoryStats,
 		"activeExecutions": activeExecutions,
 		"executionCount":   len(activeExecutions),
+		"queue":            s.queueStats(),
 	}
 
 	requestLogger.WithFields(logrus.Fields{
@@ -716,41 +1251,141 @@ func (s *Server) handleStatus(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
-// handleGetSession returns information about a specific chat session
-func (s *Server) handleGetSession(c echo.Context) error {
-	sessionID := c.Param("sessionId")
+// handleWorkspace reports the default workspace state shared by the tools,
+// or the workspace of the session given by the sessionId query parameter
+func (s *Server) handleWorkspace(c echo.Context) error {
+	sessionID := c.QueryParam("sessionId")
 
 	requestLogger := s.logger.WithFields(logrus.Fields{
-		"endpoint":  "/sessions/:sessionId",
+		"endpoint":  "/workspace",
 		"method":    "GET",
 		"sessionID": sessionID,
 		"clientIP":  c.RealIP(),
 	})
+	requestLogger.Debug("Workspace requested")
 
-	if sessionID == "" {
-		requestLogger.Warn("Session ID not provided")
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Session ID required"})
+	workspace := s.workspace
+	if sessionID != "" {
+		caller, answered, err := s.callerOf(c)
+		if answered {
+			return err
+		}
+		session, exists := s.memoryStore.GetSession(sessionID)
+		if !exists {
+			requestLogger.Warn("Session not found")
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Session not found"})
+		}
+		if !caller.mayUse(session) {
+			return s.rejectForeignSession(c, session, requestLogger)
+		}
+		workspace = session.Workspace(s.workspace.Dir())
 	}
 
-	// Try to get the session (don't create if it doesn't exist)
-	session, exists := s.memoryStore.GetSession(sessionID)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"sessionId":  sessionID,
+		"workingDir": workspace.Dir(),
+		"env":        workspace.EnvNames(),
+	})
+}
+
+// Page sizes of GET /sessions/:sessionId when paging parameters are given
+const (
+	defaultSessionPageSize = 50
+	maxSessionPageSize     = 500
+)
+
+// handleGetSession returns information about a specific chat session
+func (s *Server) handleGetSession(c echo.Context) error {
+	sessionID := c.Param("sessionId")
+
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint":  "/sessions/:sessionId",
+		"method":    "GET",
+		"sessionID": sessionID,
+		"clientIP":  c.RealIP(),
+	})
+
+	if sessionID == "" {
+		requestLogger.Warn("Session ID not provided")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Session ID required"})
+	}
+
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+
+	// Try to get the session (don't create if it doesn't exist)
+	session, exists := s.memoryStore.GetSession(sessionID)
 
 	if !exists {
 		requestLogger.Warn("Session not found")
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Session not found"})
 	}
+	if !caller.mayUse(session) {
+		return s.rejectForeignSession(c, session, requestLogger)
+	}
 
+	// Messages are paged by position when any of the paging parameters is set
+	before, after, limit := -1, -1, 0
+	for _, param := range []struct {
+		name  string
+		value *int
+		min   int
+	}{{"before", &before, 0}, {"after", &after, 0}, {"limit", &limit, 1}} {
+		raw := c.QueryParam(param.name)
+		if raw == "" {
+			continue
+		}
+		val, err := strconv.Atoi(raw)
+		if err != nil || val < param.min {
+			requestLogger.WithField(param.name, raw).Warn("Invalid session paging parameter")
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid '%s', expected an integer of at least %d", param.name, param.min)})
+		}
+		*param.value = val
+	}
+	if before >= 0 && after >= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Use either 'before' or 'after', not both"})
+	}
+	switch {
+	case before < 0 && after < 0 && limit == 0:
+		// Unpaged requests return the whole history
+		limit = math.MaxInt
+	case limit == 0:
+		limit = defaultSessionPageSize
+	default:
+		limit = min(limit, maxSessionPageSize)
+	}
+
+	page := session.GetMessagePage(before, after, limit)
 	session.mutex.RLock()
 	sessionInfo := map[string]interface{}{
 		"id":           session.ID,
 		"created":      session.Created,
 		"updated":      session.Updated,
-		"messageCount": len(session.Messages),
-		"messages":     session.Messages,
+		"messageCount": page.Total,
+		"messages":     page.Messages,
+		"offset":       page.Offset,
 	}
 	session.mutex.RUnlock()
+	// Cursors lead to the older and newer neighbouring pages when there are any
+	if page.Offset > page.First {
+		sessionInfo["prevCursor"] = page.Offset
+	}
+	if next := page.Offset + len(page.Messages); next < page.Total {
+		sessionInfo["nextCursor"] = next
+	}
+	if page.First > 0 {
+		sessionInfo["trimmedMessages"] = page.First
+	}
+	workspace := session.Workspace(s.workspace.Dir())
+	sessionInfo["workingDir"] = workspace.Dir()
+	sessionInfo["env"] = workspace.EnvNames()
 
-	requestLogger.WithField("messageCount", len(session.Messages)).Info("Session information retrieved")
+	requestLogger.WithFields(logrus.Fields{
+		"messageCount": page.Total,
+		"pageSize":     len(page.Messages),
+	}).Info("Session information retrieved")
 
 	return c.JSON(http.StatusOK, sessionInfo)
 }
@@ -771,6 +1406,11 @@ func (s *Server) handleClearSession(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Session ID required"})
 	}
 
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+
 	// Try to get the session
 	session, exists := s.memoryStore.GetSession(sessionID)
 
@@ -778,6 +1418,9 @@ func (s *Server) handleClearSession(c echo.Context) error {
 		requestLogger.Warn("Session not found for clearing")
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Session not found"})
 	}
+	if !caller.mayUse(session) {
+		return s.rejectForeignSession(c, session, requestLogger)
+	}
 
 	// Clear the session messages
 	messageCount := session.ClearMessages()
@@ -791,6 +1434,150 @@ func (s *Server) handleClearSession(c echo.Context) error {
 	})
 }
 
+// handleApproveSession approves or denies the operations a session's tools are
+// waiting to have approved. The decision is the user's: the agent can only ask
+// for it, so it is taken over the API rather than through chat messages.
+func (s *Server) handleApproveSession(c echo.Context) error {
+	sessionID := c.Param("sessionId")
+
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint":  "/sessions/:sessionId/approve",
+		"method":    "POST",
+		"sessionID": sessionID,
+		"clientIP":  c.RealIP(),
+	})
+
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+
+	var req SessionApprovalRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			requestLogger.WithError(err).Error("Failed to parse session approval request body")
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
+		}
+	}
+
+	session, exists := s.memoryStore.GetSession(sessionID)
+	if !exists {
+		requestLogger.Warn("Session not found for approval")
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Session not found"})
+	}
+	if !caller.mayUse(session) {
+		return s.rejectForeignSession(c, session, requestLogger)
+	}
+
+	decision := "approved"
+	var operations []string
+	if req.Deny {
+		decision = "denied"
+		operations = session.Approvals().Deny()
+	} else {
+		operations = session.Approvals().Approve()
+	}
+	if operations == nil {
+		operations = []string{}
+	}
+
+	requestLogger.WithFields(logrus.Fields{
+		"decision":   decision,
+		"operations": operations,
+	}).Warn("Session operations decided by the user")
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"sessionId":  sessionID,
+		"decision":   decision,
+		"operations": operations,
+	})
+}
+
+// decideApprovals approves or denies the operations a session's tools are
+// waiting to have approved, on a decision a user made in a chat integration.
+func (s *Server) decideApprovals(sessionID string, approve bool, logger *logrus.Entry) {
+	session, exists := s.memoryStore.GetSession(sessionID)
+	if !exists {
+		return
+	}
+	decision, operations := "approved", []string(nil)
+	if approve {
+		operations = session.Approvals().Approve()
+	} else {
+		decision, operations = "denied", session.Approvals().Deny()
+	}
+	if len(operations) > 0 {
+		logger.WithFields(logrus.Fields{
+			"sessionID":  sessionID,
+			"decision":   decision,
+			"operations": operations,
+		}).Warn("Session operations decided by the user")
+	}
+}
+
+// envNamePattern matches valid environment variable names
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// handleSessionEnv sets and unsets environment variables of a chat session.
+// The session is created if it does not exist yet, so variables can be set
+// before the first message. Values are never echoed back.
+func (s *Server) handleSessionEnv(c echo.Context) error {
+	sessionID := c.Param("sessionId")
+
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint":  "/sessions/:sessionId/env",
+		"method":    "POST",
+		"sessionID": sessionID,
+		"clientIP":  c.RealIP(),
+	})
+
+	if sessionID == "" {
+		requestLogger.Warn("Session ID not provided for environment update")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Session ID required"})
+	}
+
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+
+	var req SessionEnvRequest
+	if err := c.Bind(&req); err != nil {
+		requestLogger.WithError(err).Error("Failed to parse session environment request body")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
+	}
+
+	for name := range req.Set {
+		if !envNamePattern.MatchString(name) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid environment variable name %q", name)})
+		}
+	}
+
+	session := s.memoryStore.GetOrCreateOwnedSession(sessionID, caller.owner, caller.tenant)
+	if !caller.mayUse(session) {
+		return s.rejectForeignSession(c, session, requestLogger)
+	}
+	workspace := session.Workspace(s.workspace.Dir())
+	for _, name := range req.Unset {
+		workspace.UnsetEnv(name)
+	}
+	for name, value := range req.Set {
+		workspace.SetEnv(name, value)
+	}
+
+	names := workspace.EnvNames()
+	requestLogger.WithFields(logrus.Fields{
+		"set":   len(req.Set),
+		"unset": len(req.Unset),
+		"env":   names,
+	}).Info("Session environment updated")
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"sessionId": sessionID,
+		"env":       names,
+	})
+}
+
 // handleDeleteSession deletes a specific chat session
 func (s *Server) handleDeleteSession(c echo.Context) error {
 	sessionID := c.Param("sessionId")
@@ -807,6 +1594,14 @@ func (s *Server) handleDeleteSession(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Session ID required"})
 	}
 
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+	if session, exists := s.memoryStore.GetSession(sessionID); exists && !caller.mayUse(session) {
+		return s.rejectForeignSession(c, session, requestLogger)
+	}
+
 	// Try to delete the session
 	exists := s.memoryStore.DeleteSession(sessionID)
 
@@ -833,7 +1628,18 @@ func (s *Server) handleListSessions(c echo.Context) error {
 
 	requestLogger.Debug("Listing all sessions")
 
-	sessions := s.memoryStore.GetAllSessions()
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+
+	// Callers see only the sessions they may use
+	sessions := make([]*ChatSession, 0)
+	for _, session := range s.memoryStore.GetAllSessions() {
+		if caller.mayUse(session) {
+			sessions = append(sessions, session)
+		}
+	}
 
 	requestLogger.WithField("sessionCount", len(sessions)).Info("Sessions listed successfully")
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -860,26 +1666,76 @@ func (s *Server) handleStopExecution(c echo.Context) error {
 		})
 	}
 
-	if req.ExecutionID == "" {
-		requestLogger.Error("Empty execution ID in stop request")
+	if req.ExecutionID == "" && req.SessionID == "" && req.RequestID == "" {
+		requestLogger.Error("No execution, session, or request ID in stop request")
 		return c.JSON(http.StatusBadRequest, StopResponse{
 			Success: false,
-			Message: "Execution ID is required",
+			Message: "An execution, session, or request ID is required",
 			Stopped: false,
 		})
 	}
 
+	// Callers may only stop the executions of sessions they may use
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+	if req.SessionID != "" {
+		if session, exists := s.memoryStore.GetSession(req.SessionID); exists && !caller.mayUse(session) {
+			return s.rejectForeignSession(c, session, requestLogger)
+		}
+	}
+
+	// Sessions and requests may have several executions running, such as workflow steps
+	if req.ExecutionID == "" {
+		var stopped []string
+		if req.SessionID != "" {
+			stopped = s.cancelManager.CancelSession(req.SessionID, caller)
+		} else {
+			stopped = s.cancelManager.CancelRequest(req.RequestID, caller)
+		}
+		requestLogger = requestLogger.WithFields(logrus.Fields{
+			"sessionID": req.SessionID,
+			"requestID": req.RequestID,
+		})
+		if len(stopped) == 0 {
+			requestLogger.Warn("No running executions to stop")
+			return c.JSON(http.StatusNotFound, StopResponse{
+				Success: false,
+				Message: "No running executions found",
+				Stopped: false,
+			})
+		}
+		requestLogger.WithField("executions", stopped).Info("Executions stopped successfully")
+		return c.JSON(http.StatusOK, StopResponse{
+			Success:    true,
+			Message:    fmt.Sprintf("Stopped %d execution(s)", len(stopped)),
+			Stopped:    true,
+			Executions: stopped,
+		})
+	}
+
 	requestLogger.WithField("executionID", req.ExecutionID).Info("Attempting to stop execution")
 
+	if !s.cancelManager.MayStop(req.ExecutionID, caller) {
+		requestLogger.WithField("executionID", req.ExecutionID).Warn("Rejected stopping an execution of another caller")
+		return c.JSON(http.StatusForbidden, StopResponse{
+			Success: false,
+			Message: "Execution belongs to another caller",
+			Stopped: false,
+		})
+	}
+
 	// Try to cancel the execution
 	stopped := s.cancelManager.CancelExecution(req.ExecutionID)
 
 	if stopped {
 		requestLogger.WithField("executionID", req.ExecutionID).Info("Execution stopped successfully")
 		return c.JSON(http.StatusOK, StopResponse{
-			Success: true,
-			Message: "Execution stopped successfully",
-			Stopped: true,
+			Success:    true,
+			Message:    "Execution stopped successfully",
+			Stopped:    true,
+			Executions: []string{req.ExecutionID},
 		})
 	} else {
 		requestLogger.WithField("executionID", req.ExecutionID).Warn("Execution not found or already completed")
@@ -891,22 +1747,466 @@ func (s *Server) handleStopExecution(c echo.Context) error {
 	}
 }
 
+// handleAudit returns recorded tool executions filtered by session and time range
+func (s *Server) handleAudit(c echo.Context) error {
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint": "/admin/audit",
+		"method":   "GET",
+		"clientIP": c.RealIP(),
+	})
+
+	if s.auditLog == nil {
+		requestLogger.Warn("Audit log requested but auditing is disabled")
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Audit log is not enabled"})
+	}
+
+	query := AuditQuery{SessionID: c.QueryParam("sessionId")}
+
+	if since := c.QueryParam("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			requestLogger.WithError(err).Warn("Invalid since parameter")
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid 'since' time, expected RFC3339"})
+		}
+		query.Since = t
+	}
+
+	if until := c.QueryParam("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			requestLogger.WithError(err).Warn("Invalid until parameter")
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid 'until' time, expected RFC3339"})
+		}
+		query.Until = t
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		val, err := strconv.Atoi(limit)
+		if err != nil || val <= 0 {
+			requestLogger.WithField("limit", limit).Warn("Invalid limit parameter")
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid 'limit', expected a positive integer"})
+		}
+		query.Limit = val
+	}
+
+	entries, err := s.auditLog.Query(query)
+	if err != nil {
+		requestLogger.WithError(err).Error("Failed to query audit log")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to read audit log"})
+	}
+
+	requestLogger.WithField("entryCount", len(entries)).Info("Audit log queried")
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// handleAnalytics returns the conversation analytics summary and recorded tags
+func (s *Server) handleAnalytics(c echo.Context) error {
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint": "/analytics",
+		"method":   "GET",
+		"clientIP": c.RealIP(),
+	})
+
+	if s.analytics == nil {
+		requestLogger.Warn("Analytics requested but conversation analytics is disabled")
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Conversation analytics is not enabled"})
+	}
+
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+
+	// Callers see only the conversations of sessions they may use
+	tags := s.analytics.Tags(c.QueryParam("sessionId"), caller)
+
+	requestLogger.WithField("tagCount", len(tags)).Debug("Analytics report generated")
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"summary":       s.analytics.Summary(caller),
+		"conversations": tags,
+	})
+}
+
+// schemaTool is implemented by tools that publish a JSON schema for structured input
+type schemaTool interface {
+	InputSchema() json.RawMessage
+}
+
+// handleListTools lists every tool available to the agent with its description and input schema
+func (s *Server) handleListTools(c echo.Context) error {
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint": "/tools",
+		"method":   "GET",
+		"clientIP": c.RealIP(),
+	})
+
+	entries := s.registry.Entries(EnabledTools(s.currentConfig()))
+
+	toolInfos := make([]ToolInfo, 0, len(entries))
+	for _, entry := range entries {
+		tool := entry.Tool
+		info := ToolInfo{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			Source:      string(entry.Source),
+		}
+		if withSchema, ok := tool.(schemaTool); ok {
+			info.Schema = withSchema.InputSchema()
+		}
+		toolInfos = append(toolInfos, info)
+	}
+
+	requestLogger.WithField("toolsCount", len(toolInfos)).Debug("Tools listed")
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tools": toolInfos,
+		"count": len(toolInfos),
+	})
+}
+
+// handleInvokeTool executes a single tool directly, bypassing the LLM, for debugging tool behavior.
+// The invocation passes through the same wrappers as agent calls, so timeouts, auditing,
+// and truncation apply. It runs any tool with any input, so it is an admin endpoint.
+func (s *Server) handleInvokeTool(c echo.Context) error {
+	toolName := c.Param("name")
+
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint": "/admin/tools/:name/invoke",
+		"method":   "POST",
+		"tool":     toolName,
+		"clientIP": c.RealIP(),
+	})
+
+	if !s.currentConfig().ToolInvokeEnabled {
+		requestLogger.Warn("Direct tool invocation requested but it is disabled")
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Direct tool invocation is not enabled"})
+	}
+
+	var req ToolInvokeRequest
+	if err := c.Bind(&req); err != nil {
+		requestLogger.WithError(err).Error("Failed to parse invoke request body")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+	if limited, err := s.rejectRateLimited(c, caller, requestLogger); limited {
+		return err
+	}
+	if exceeded, err := s.rejectQuotaExceeded(c, caller, requestLogger); exceeded {
+		return err
+	}
+
+	s.executorMutex.RLock()
+	var tool tools.Tool
+	for _, candidate := range s.toolsList {
+		if candidate.Name() == toolName {
+			tool = candidate
+			break
+		}
+	}
+	s.executorMutex.RUnlock()
+
+	if tool == nil {
+		requestLogger.Warn("Tool not found")
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Tool not found"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), s.currentConfig().RequestTimeout)
+	defer cancel()
+
+	// Attribute the invocation in the audit log like an agent execution
+	ctx = WithExecutionInfo(ctx, ExecutionInfo{
+		ExecutionID: fmt.Sprintf("invoke_%d", time.Now().UnixNano()),
+		User:        c.RealIP(),
+		Tenant:      caller.tenant,
+	})
+	ctx = s.withCaller(ctx, caller)
+
+	requestLogger.WithField("input", req.Input).Info("Invoking tool directly")
+	startTime := time.Now()
+	output, err := tool.Call(ctx, req.Input)
+
+	response := ToolInvokeResponse{
+		Tool:     toolName,
+		Output:   output,
+		Duration: time.Since(startTime).String(),
+	}
+	if err != nil {
+		response.Error = err.Error()
+	}
+
+	requestLogger.WithFields(logrus.Fields{
+		"duration":     response.Duration,
+		"outputLength": len(output),
+	}).Info("Direct tool invocation completed")
+	return c.JSON(http.StatusOK, response)
+}
+
+// handleRegisterTool registers a command-template tool at runtime and rebuilds the executor
+func (s *Server) handleRegisterTool(c echo.Context) error {
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint": "/admin/tools",
+		"method":   "POST",
+		"clientIP": c.RealIP(),
+	})
+
+	var definition ToolDefinition
+	if err := c.Bind(&definition); err != nil {
+		requestLogger.WithError(err).Error("Failed to parse tool definition")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	if err := s.customTools.Validate(definition); err != nil {
+		requestLogger.WithError(err).Warn("Invalid tool definition")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Built-in and other startup tools cannot be shadowed by runtime definitions
+	if existing, ok := s.registry.Get(definition.Name); ok && existing.Source != ToolSourceCustom {
+		requestLogger.WithField("tool", definition.Name).Warn("Tool name conflicts with a built-in tool")
+		return c.JSON(http.StatusConflict, map[string]string{"error": "A built-in tool with this name already exists"})
+	}
+
+	// Replacing a registered tool changes what every session's agent runs, so it must be explicit
+	if _, ok := s.registry.Get(definition.Name); ok && c.QueryParam("overwrite") != "true" {
+		requestLogger.WithField("tool", definition.Name).Warn("Tool name conflicts with a registered tool")
+		return c.JSON(http.StatusConflict, map[string]string{"error": "A tool with this name is already registered; repeat the request with ?overwrite=true to replace it"})
+	}
+
+	definition.CreatedAt = time.Now()
+	if err := s.customTools.Add(definition); err != nil {
+		requestLogger.WithError(err).Error("Failed to persist tool definition")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to persist tool definition"})
+	}
+
+	if err := s.rebuildExecutor(); err != nil {
+		requestLogger.WithError(err).Error("Failed to rebuild agent executor")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to rebuild agent executor"})
+	}
+
+	requestLogger.WithFields(logrus.Fields{
+		"tool":    definition.Name,
+		"command": definition.Command,
+	}).Info("Custom tool registered")
+	return c.JSON(http.StatusCreated, definition)
+}
+
+// handleToolStats returns per-tool call counts, failure rates, and latency percentiles
+func (s *Server) handleToolStats(c echo.Context) error {
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint": "/admin/stats/tools",
+		"method":   "GET",
+		"clientIP": c.RealIP(),
+	})
+
+	snapshots := s.toolStats.Snapshot()
+
+	requestLogger.WithField("toolsCount", len(snapshots)).Debug("Tool statistics generated")
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tools": snapshots,
+	})
+}
+
+// handleMetrics exposes tool metrics in the Prometheus text format
+func (s *Server) handleMetrics(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	c.Response().WriteHeader(http.StatusOK)
+	if err := s.toolStats.WritePrometheus(c.Response()); err != nil {
+		return err
+	}
+	if err := s.memoryStore.WritePrometheus(c.Response()); err != nil {
+		return err
+	}
+	if err := s.authGuard.WritePrometheus(c.Response()); err != nil {
+		return err
+	}
+	return s.tenants.WritePrometheus(c.Response())
+}
+
+// RestartRequested returns a channel that receives a value when a self-update
+// has been installed and the process should shut down gracefully and restart.
+func (s *Server) RestartRequested() <-chan struct{} {
+	return s.restartCh
+}
+
+// handleVersion returns build information and enabled features
+func (s *Server) handleVersion(c echo.Context) error {
+	s.logger.WithFields(logrus.Fields{
+		"endpoint": "/version",
+		"method":   "GET",
+		"clientIP": c.RealIP(),
+	}).Debug("Version requested")
+
+	return c.JSON(http.StatusOK, BuildInfo(s.currentConfig()))
+}
+
+// handleUpdateCheck reports whether a newer release is available at the update endpoint
+func (s *Server) handleUpdateCheck(c echo.Context) error {
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint": "/update",
+		"method":   "GET",
+		"clientIP": c.RealIP(),
+	})
+
+	if s.currentConfig().UpdateURL == "" {
+		requestLogger.Warn("Update check requested but no update URL is configured")
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Update URL is not configured"})
+	}
+
+	release, available, err := s.updater.Check(c.Request().Context())
+	if err != nil {
+		requestLogger.WithError(err).Error("Update check failed")
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": "Failed to check for updates"})
+	}
+
+	requestLogger.WithFields(logrus.Fields{
+		"currentVersion":  Version,
+		"latestVersion":   release.Version,
+		"updateAvailable": available,
+	}).Info("Update check completed")
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"currentVersion":  Version,
+		"latestVersion":   release.Version,
+		"updateAvailable": available,
+		"notes":           release.Notes,
+	})
+}
+
+// handleUpdateApply installs the latest release and schedules a graceful restart
+func (s *Server) handleUpdateApply(c echo.Context) error {
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint": "/admin/update",
+		"method":   "POST",
+		"clientIP": c.RealIP(),
+	})
+
+	if !s.currentConfig().SelfUpdateEnabled {
+		requestLogger.Warn("Self-update requested but it is disabled")
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Self-update is not enabled"})
+	}
+
+	release, available, err := s.updater.Check(c.Request().Context())
+	if err != nil {
+		requestLogger.WithError(err).Error("Update check failed")
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": "Failed to check for updates"})
+	}
+
+	if !available {
+		requestLogger.WithFields(logrus.Fields{
+			"version":       Version,
+			"latestVersion": release.Version,
+		}).Info("No newer release is available")
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message": "No newer release is available",
+			"version": Version,
+			"updated": false,
+		})
+	}
+
+	if err := s.updater.Apply(c.Request().Context(), release); err != nil {
+		requestLogger.WithError(err).WithField("version", release.Version).Error("Failed to apply update")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("Failed to apply update: %v", err)})
+	}
+
+	// Signal main to shut down gracefully and re-execute the new binary
+	select {
+	case s.restartCh <- struct{}{}:
+	default:
+	}
+
+	requestLogger.WithField("version", release.Version).Info("Update applied, restarting")
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Update installed, restarting",
+		"version": release.Version,
+		"updated": true,
+	})
+}
+
 // RegisterRoutes registers all HTTP routes for the server
 func (s *Server) RegisterRoutes(e *echo.Echo) {
 	s.logger.Info("Registering routes")
 
+	// Reject clients outside ALLOWED_CIDRS before routing
+	e.Pre(s.allowlistMiddleware)
+
+	// Identify and trace every request, continuing the caller's trace when one is propagated
+	e.Use(s.requestIDMiddleware)
+	e.Use(s.tracingMiddleware)
+
 	// API routes
 	e.POST("/chat", s.handleChat)
 	e.POST("/chat/stream", s.handleStreamChat)
+	e.POST("/chat/audio", s.handleAudioChat)
+	e.POST("/speech", s.handleSynthesizeSpeech)
+	e.GET("/speech/:id", s.handleGetSpeech)
 	e.GET("/status", s.handleStatus)
+	e.GET("/readyz", s.handleReadyz)
+	e.GET("/executions/:id/trace", s.handleExecutionTrace)
+	e.GET("/workspace", s.handleWorkspace)
+	e.GET("/targets", s.handleListTargets)
+	e.GET("/usage/quota", s.handleUsageQuota)
 
 	// Session management routes
 	e.GET("/sessions", s.handleListSessions)
 	e.GET("/sessions/:sessionId", s.handleGetSession)
 	e.POST("/sessions/:sessionId/clear", s.handleClearSession)
+	e.POST("/sessions/:sessionId/env", s.handleSessionEnv)
+	e.POST("/sessions/:sessionId/approve", s.handleApproveSession)
 	e.DELETE("/sessions/:sessionId", s.handleDeleteSession)
 	e.POST("/stop", s.handleStopExecution)
 
+	// Version and self-update routes
+	e.GET("/version", s.handleVersion)
+	e.GET("/update", s.handleUpdateCheck)
+
+	// Tool management routes
+	e.GET("/tools", s.handleListTools)
+
+	// Metrics routes
+	e.GET("/metrics", s.handleMetrics)
+
+	// Analytics routes
+	e.GET("/analytics", s.handleAnalytics)
+
+	// Integration routes, authenticated with their own tokens
+	e.POST("/integrations/alertmanager", s.handleAlertmanager)
+	e.POST("/integrations/slack/events", s.handleSlackEvents)
+	e.POST("/integrations/slack/interactions", s.handleSlackInteractions)
+	e.POST("/integrations/github", s.handleGitHub)
+	e.POST("/hooks/:name", s.handleHook)
+
+	// Workflow routes
+	e.GET("/workflows", s.handleListWorkflows)
+	e.POST("/workflows/:name/run", s.handleRunWorkflow)
+
+	// Background task routes
+	e.POST("/tasks", s.handleCreateTask)
+	e.GET("/tasks", s.handleListTasks)
+	e.GET("/tasks/:id", s.handleGetTask)
+	e.POST("/tasks/:id/pause", s.handleTaskAction("pause"))
+	e.POST("/tasks/:id/resume", s.handleTaskAction("resume"))
+	e.POST("/tasks/:id/cancel", s.handleTaskAction("cancel"))
+
+	// Admin routes, authenticated with the admin token
+	admin := e.Group("/admin", s.requireAdmin)
+	admin.POST("/reload", s.handleReload)
+	admin.GET("/config", s.handleAdminConfig)
+	admin.GET("/logs/stream", s.handleAdminLogStream)
+	admin.GET("/keys", s.handleListAPIKeys)
+	admin.POST("/keys", s.handleCreateAPIKey)
+	admin.DELETE("/keys/:id", s.handleRevokeAPIKey)
+	admin.GET("/tenants", s.handleListTenants)
+	admin.GET("/stats/tools", s.handleToolStats)
+	admin.GET("/audit", s.handleAudit)
+	admin.POST("/tools", s.handleRegisterTool)
+	admin.POST("/tools/:name/invoke", s.handleInvokeTool)
+	admin.POST("/update", s.handleUpdateApply)
+
 	// Serve static files
 	e.Static("/", "static")
 	s.logger.Info("Routes registered successfully")
@@ -0,0 +1,420 @@
+/*
+Package core provides the Slack bot integration for the Skynet Agent application.
+
+The bot uses the Slack Events API: Slack posts events to /integrations/slack/events
+and button clicks to /integrations/slack/interactions, both signed with the app's
+signing secret. Messages in the channels listed in SLACK_CHANNELS, and direct
+messages when SLACK_ALLOW_DMS is enabled, become chat requests. Each channel has
+its own session (slack_<channel>), so a conversation continues across messages.
+
+The bot answers in a thread under the message. While the agent works, every tool
+it runs is posted to the thread, and an answer that asks for approval or
+confirmation gets Approve and Deny buttons; a click decides the operations the
+session's tools are waiting to have approved and is sent to the agent as the
+user's reply in the same session.
+
+Slack apps need the chat:write scope and the message.channels, message.groups,
+and message.im bot events.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// slackAPIURL is the base URL of the Slack Web API
+const slackAPIURL = "https://slack.com/api"
+
+// slackSignatureMaxAge rejects signed requests older than this, preventing replays
+const slackSignatureMaxAge = 5 * time.Minute
+
+// slackSectionLimit is the maximum length of a section block's text
+const slackSectionLimit = 3000
+
+// approvalRequestPattern matches answers asking the user for approval or confirmation
+var approvalRequestPattern = regexp.MustCompile(`(?i)\b(approv\w*|confirm\w*)\b`)
+
+// slackMentionPattern matches user mentions such as <@U123ABC>
+var slackMentionPattern = regexp.MustCompile(`<@[A-Z0-9]+>`)
+
+// slackEventEnvelope is the outer payload of an Events API request.
+type slackEventEnvelope struct {
+	Type      string     `json:"type"`      // url_verification or event_callback
+	Challenge string     `json:"challenge"` // Echoed back to verify the request URL
+	TeamID    string     `json:"team_id"`
+	Event     slackEvent `json:"event"`
+}
+
+// slackEvent is a message event.
+type slackEvent struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"` // Set for edits, joins, bot messages, and other non-user messages
+	User        string `json:"user"`
+	BotID       string `json:"bot_id"`
+	Text        string `json:"text"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type"` // channel, group, or im
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts"`
+}
+
+// slackInteraction is the payload of a button click.
+type slackInteraction struct {
+	Type string `json:"type"` // block_actions for button clicks
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	Message struct {
+		TS       string `json:"ts"`
+		ThreadTS string `json:"thread_ts"`
+		Text     string `json:"text"`
+	} `json:"message"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+	} `json:"actions"`
+}
+
+// slackClient calls the Slack Web API with a bot token.
+type slackClient struct {
+	token  string
+	client *http.Client
+}
+
+// newSlackClient creates a client for the bot token.
+func newSlackClient(token string) *slackClient {
+	return &slackClient{token: token, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// call invokes a Web API method and returns the timestamp of the affected message.
+func (sc *slackClient) call(ctx context.Context, method string, payload map[string]interface{}) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+sc.token)
+
+	resp, err := sc.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("slack %s: %s", method, resp.Status)
+	}
+	if !result.OK {
+		return "", fmt.Errorf("slack %s: %s", method, result.Error)
+	}
+	return result.TS, nil
+}
+
+// postMessage posts a message, in a thread when threadTS is set.
+func (sc *slackClient) postMessage(ctx context.Context, channel, threadTS, text string, blocks []map[string]interface{}) error {
+	payload := map[string]interface{}{"channel": channel, "text": text}
+	if threadTS != "" {
+		payload["thread_ts"] = threadTS
+	}
+	if blocks != nil {
+		payload["blocks"] = blocks
+	}
+	_, err := sc.call(ctx, "chat.postMessage", payload)
+	return err
+}
+
+// slackProgressHandler posts the tools the agent runs to the Slack thread of the request.
+type slackProgressHandler struct {
+	*VerboseCallbackHandler
+	ctx      context.Context // Context for posting, independent of the agent's callbacks
+	slack    *slackClient
+	channel  string
+	threadTS string
+}
+
+func (h *slackProgressHandler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
+	h.VerboseCallbackHandler.HandleAgentAction(ctx, action)
+	input := action.ToolInput
+	if len(input) > 200 {
+		input = input[:200] + "..."
+	}
+	text := fmt.Sprintf(":gear: Running `%s` `%s`", action.Tool, strings.ReplaceAll(input, "`", "'"))
+	if err := h.slack.postMessage(h.ctx, h.channel, h.threadTS, text, nil); err != nil {
+		h.requestLogger.WithError(err).Warn("Failed to post tool progress to Slack")
+	}
+}
+
+// Ensure slackProgressHandler implements the callbacks.Handler interface
+var _ callbacks.Handler = (*slackProgressHandler)(nil)
+
+// verifySlackSignature checks the signature Slack computes over the request body
+// with the signing secret.
+func verifySlackSignature(secret string, header http.Header, body []byte) bool {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > slackSignatureMaxAge || age < -slackSignatureMaxAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(header.Get("X-Slack-Signature"))) == 1
+}
+
+// readSlackRequest reads the body of a Slack request and verifies its signature.
+// A rejected request has already been answered: the body is nil and the error is
+// the result of writing the response.
+func (s *Server) readSlackRequest(c echo.Context, requestLogger *logrus.Entry) ([]byte, error) {
+	config := s.currentConfig()
+	if config.SlackBotToken == "" || config.SlackSigningSecret == "" {
+		return nil, c.JSON(http.StatusForbidden, map[string]string{"error": "Slack integration is disabled; set SLACK_BOT_TOKEN and SLACK_SIGNING_SECRET to enable it"})
+	}
+
+	if locked, err := s.rejectLockedOut(c, "slack"); locked {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, 1<<20))
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read request body"})
+	}
+	if !verifySlackSignature(config.SlackSigningSecret, c.Request().Header, body) {
+		requestLogger.Warn("Rejected Slack request with invalid signature")
+		s.authFailed(c, "slack")
+		return nil, c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid signature"})
+	}
+	s.authSucceeded(c)
+	return body, nil
+}
+
+// handleSlackEvents handles POST /integrations/slack/events requests from the
+// Slack Events API by starting a chat request for every accepted message.
+func (s *Server) handleSlackEvents(c echo.Context) error {
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"endpoint": "/integrations/slack/events",
+		"method":   "POST",
+		"clientIP": c.RealIP(),
+	})
+
+	body, err := s.readSlackRequest(c, requestLogger)
+	if body == nil {
+		return err
+	}
+
+	var envelope slackEventEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		requestLogger.WithError(err).Error("Failed to parse Slack event")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid Slack event"})
+	}
+	if envelope.Type == "url_verification" {
+		return c.JSON(http.StatusOK, map[string]string{"challenge": envelope.Challenge})
+	}
+
+	// Slack retries events it did not see acknowledged in time; the first delivery is already being handled
+	if c.Request().Header.Get("X-Slack-Retry-Num") != "" {
+		return c.NoContent(http.StatusOK)
+	}
+
+	event := envelope.Event
+	if envelope.Type != "event_callback" || event.Type != "message" || event.Subtype != "" || event.BotID != "" || event.User == "" {
+		return c.NoContent(http.StatusOK)
+	}
+
+	config := s.currentConfig()
+	allowed := event.ChannelType == "im" && config.SlackAllowDMs
+	for _, channel := range config.SlackChannels {
+		allowed = allowed || channel == event.Channel
+	}
+	eventLogger := requestLogger.WithFields(logrus.Fields{
+		"channel":   event.Channel,
+		"slackUser": event.User,
+	})
+	if !allowed {
+		eventLogger.Debug("Ignoring Slack message from a channel that is not configured")
+		return c.NoContent(http.StatusOK)
+	}
+
+	message := strings.TrimSpace(slackMentionPattern.ReplaceAllString(event.Text, ""))
+	if message == "" {
+		return c.NoContent(http.StatusOK)
+	}
+	threadTS := event.ThreadTS
+	if threadTS == "" {
+		threadTS = event.TS
+	}
+
+	// Slack expects an answer within three seconds, so the agent runs after responding
+	ctx := localtools.WithRequestID(context.Background(), localtools.RequestIDFromContext(c.Request().Context()))
+	go s.runSlackChat(ctx, event.Channel, threadTS, event.User, message, eventLogger)
+
+	eventLogger.Info("Accepted Slack message")
+	return c.NoContent(http.StatusOK)
+}
+
+// handleSlackInteractions handles POST /integrations/slack/interactions requests,
+// sent when a user clicks the Approve or Deny button of an answer.
+func (s *Server) handleSlackInteractions(c echo.Context) error {
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"endpoint": "/integrations/slack/interactions",
+		"method":   "POST",
+		"clientIP": c.RealIP(),
+	})
+
+	body, err := s.readSlackRequest(c, requestLogger)
+	if body == nil {
+		return err
+	}
+
+	// Interactions arrive form-encoded with the JSON in the payload field
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid Slack interaction"})
+	}
+	var interaction slackInteraction
+	if err := json.Unmarshal([]byte(form.Get("payload")), &interaction); err != nil || interaction.Type != "block_actions" || len(interaction.Actions) == 0 {
+		return c.NoContent(http.StatusOK)
+	}
+
+	action := interaction.Actions[0].ActionID
+	if action != "approve" && action != "deny" {
+		return c.NoContent(http.StatusOK)
+	}
+
+	channel := interaction.Channel.ID
+	threadTS := interaction.Message.ThreadTS
+	if threadTS == "" {
+		threadTS = interaction.Message.TS
+	}
+	interactionLogger := requestLogger.WithFields(logrus.Fields{
+		"channel":   channel,
+		"slackUser": interaction.User.ID,
+		"action":    action,
+	})
+
+	decision, reply := "Approved", "Approved, go ahead."
+	if action == "deny" {
+		decision, reply = "Denied", "Denied, do not go ahead."
+	}
+	s.decideApprovals("slack_"+channel, action == "approve", interactionLogger)
+
+	ctx := localtools.WithRequestID(context.Background(), localtools.RequestIDFromContext(c.Request().Context()))
+	go func() {
+		// Replace the buttons with the decision so the answer cannot be approved twice
+		slack := newSlackClient(s.currentConfig().SlackBotToken)
+		if _, err := slack.call(ctx, "chat.update", map[string]interface{}{
+			"channel": channel,
+			"ts":      interaction.Message.TS,
+			"text":    interaction.Message.Text,
+			"blocks": []map[string]interface{}{
+				slackSection(interaction.Message.Text),
+				{"type": "context", "elements": []map[string]interface{}{
+					{"type": "mrkdwn", "text": fmt.Sprintf("%s by <@%s>", decision, interaction.User.ID)},
+				}},
+			},
+		}); err != nil {
+			interactionLogger.WithError(err).Warn("Failed to update Slack approval message")
+		}
+		s.runSlackChat(ctx, channel, threadTS, interaction.User.ID, reply, interactionLogger)
+	}()
+
+	interactionLogger.Info("Accepted Slack approval decision")
+	return c.NoContent(http.StatusOK)
+}
+
+// runSlackChat runs a message through the agent in the channel's session and
+// posts the answer to the thread.
+func (s *Server) runSlackChat(ctx context.Context, channel, threadTS, user, message string, eventLogger *logrus.Entry) {
+	slack := newSlackClient(s.currentConfig().SlackBotToken)
+
+	ticket, err := s.queue.Enqueue()
+	if err != nil {
+		eventLogger.Warn("Request queue is full, rejecting Slack message")
+		if err := slack.postMessage(ctx, channel, threadTS, ":hourglass: The server is busy, please try again later.", nil); err != nil {
+			eventLogger.WithError(err).Warn("Failed to post to Slack")
+		}
+		return
+	}
+	defer ticket.Release()
+	if err := ticket.Wait(ctx, nil); err != nil {
+		return
+	}
+
+	progress := &slackProgressHandler{
+		VerboseCallbackHandler: NewVerboseCallbackHandler(eventLogger.WithField("component", "slack_agent"), s.currentConfig()),
+		ctx:                    ctx,
+		slack:                  slack,
+		channel:                channel,
+		threadTS:               threadTS,
+	}
+	response, _ := s.chat(WithCallbackHandler(ctx, progress), "slack_"+channel, message, "slack:"+user, eventLogger)
+
+	var blocks []map[string]interface{}
+	if approvalRequestPattern.MatchString(response.Response) {
+		blocks = []map[string]interface{}{
+			slackSection(response.Response),
+			{"type": "actions", "block_id": "approval", "elements": []map[string]interface{}{
+				slackButton("Approve", "approve", "primary"),
+				slackButton("Deny", "deny", "danger"),
+			}},
+		}
+	}
+	if err := slack.postMessage(ctx, channel, threadTS, response.Response, blocks); err != nil {
+		eventLogger.WithError(err).Error("Failed to post answer to Slack")
+	}
+}
+
+// slackSection returns a section block with text truncated to Slack's limit.
+func slackSection(text string) map[string]interface{} {
+	if len(text) > slackSectionLimit {
+		text = text[:slackSectionLimit-3] + "..."
+	}
+	return map[string]interface{}{"type": "section", "text": map[string]interface{}{"type": "mrkdwn", "text": text}}
+}
+
+// slackButton returns a button element.
+func slackButton(label, actionID, style string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":      "button",
+		"text":      map[string]interface{}{"type": "plain_text", "text": label},
+		"action_id": actionID,
+		"value":     actionID,
+		"style":     style,
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Package core provides spoken answers for the Skynet Agent application.
+
+For hands-free operation a chat request can set "speech": the final answer is
+synthesized to a WAV file with the configured text-to-speech backend and made
+available at GET /speech/:id for a limited time, to the callers that may use the
+session of the answer. POST /chat and POST /chat/audio
+return its address as speechUrl, and POST /chat/stream sends it in a "speech"
+event after the response. POST /speech synthesizes any text directly.
+
+TTS_BACKEND selects the synthesizer, both of which run locally:
+
+	piper   Piper neural voices; TTS_VOICE is the voice model file (.onnx)
+	espeak  eSpeak NG; TTS_VOICE is an optional voice name such as "en-us"
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Supported values of the TTS_BACKEND setting
+const (
+	TTSPiper  = "piper"  // Piper neural text-to-speech
+	TTSEspeak = "espeak" // eSpeak NG formant synthesis
+)
+
+// Limits of synthesized speech
+const (
+	maxSpeechChars  = 5000             // Longer answers are cut, which also bounds synthesis time
+	speechRetention = 15 * time.Minute // How long synthesized audio can be downloaded
+	speechTimeout   = time.Minute      // Bound on one synthesis
+)
+
+// speechMarkup matches markdown a synthesizer would read out literally
+var speechMarkup = regexp.MustCompile("```[a-z]*|[`*_#>]")
+
+// storedSpeech is a synthesized answer awaiting download.
+type storedSpeech struct {
+	audio   []byte    // WAV audio
+	created time.Time // When the audio was stored, for expiry
+	owner   string    // Owner of the session the answer belongs to; empty when open to every caller
+	tenant  string    // Tenant of that session
+}
+
+// SpeechStore keeps synthesized answers for a limited time.
+type SpeechStore struct {
+	speech map[string]*storedSpeech // Map of speech ID to audio
+	mutex  sync.Mutex               // Mutex for thread-safe map operations
+}
+
+// NewSpeechStore creates an empty speech store.
+func NewSpeechStore() *SpeechStore {
+	return &SpeechStore{speech: make(map[string]*storedSpeech)}
+}
+
+// Save stores audio for the callers that may use a session of the given owner and
+// tenant, expiring older entries, and returns its identifier.
+func (s *SpeechStore) Save(audio []byte, owner, tenant string) string {
+	idBytes := make([]byte, 8)
+	rand.Read(idBytes)
+	id := "speech_" + hex.EncodeToString(idBytes)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, stored := range s.speech {
+		if time.Since(stored.created) > speechRetention {
+			delete(s.speech, key)
+		}
+	}
+	s.speech[id] = &storedSpeech{audio: audio, created: time.Now(), owner: owner, tenant: tenant}
+	return id
+}
+
+// Get returns stored audio that has not expired, if the caller may use it.
+func (s *SpeechStore) Get(id string, caller sessionCaller) (audio []byte, found bool, allowed bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored, ok := s.speech[id]
+	if !ok || time.Since(stored.created) > speechRetention {
+		return nil, false, false
+	}
+	if !caller.mayAccess(stored.owner, stored.tenant) {
+		return nil, true, false
+	}
+	return stored.audio, true, true
+}
+
+// synthesize converts text to WAV audio with the configured backend.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - text: Text to speak; markdown is removed and long text is cut
+//
+// Returns:
+//   - []byte: WAV audio
+//   - error: Backend failure, or speech not configured
+func (s *Server) synthesize(ctx context.Context, text string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, speechTimeout)
+	defer cancel()
+
+	text = strings.TrimSpace(speechMarkup.ReplaceAllString(text, ""))
+	if len(text) > maxSpeechChars {
+		text = text[:maxSpeechChars]
+	}
+
+	config := s.currentConfig()
+	var cmd *exec.Cmd
+	var outputFile string
+	switch config.TTSBackend {
+	case TTSPiper:
+		file, err := os.CreateTemp("", "skynet-speech-*.wav")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create speech file: %w", err)
+		}
+		file.Close()
+		outputFile = file.Name()
+		defer os.Remove(outputFile)
+		cmd = exec.CommandContext(ctx, ttsBinary(config), "--model", config.TTSVoice, "--output_file", outputFile)
+	case TTSEspeak:
+		args := []string{"--stdout"}
+		if config.TTSVoice != "" {
+			args = append(args, "-v", config.TTSVoice)
+		}
+		cmd = exec.CommandContext(ctx, ttsBinary(config), args...)
+	default:
+		return nil, fmt.Errorf("speech output is not configured")
+	}
+
+	// Both synthesizers read the text from stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %v: %s", config.TTSBackend, err, strings.TrimSpace(stderr.String()))
+	}
+	if outputFile != "" {
+		return os.ReadFile(outputFile)
+	}
+	return stdout.Bytes(), nil
+}
+
+// ttsBinary returns the synthesizer program, TTS_BINARY or the backend's usual name.
+func ttsBinary(config *Config) string {
+	if config.TTSBinary != "" {
+		return config.TTSBinary
+	}
+	if config.TTSBackend == TTSEspeak {
+		return "espeak-ng"
+	}
+	return "piper"
+}
+
+// speak synthesizes an answer of a session and returns the address it can be
+// downloaded from by the callers that may use the session. Failures are logged
+// and yield an empty address, since the text answer stands on its own.
+func (s *Server) speak(ctx context.Context, session *ChatSession, text string, requestLogger *logrus.Entry) string {
+	if s.currentConfig().TTSBackend == "" {
+		requestLogger.Warn("Speech was requested but TTS_BACKEND is not set")
+		return ""
+	}
+	startTime := time.Now()
+	audio, err := s.synthesize(ctx, text)
+	if err != nil {
+		requestLogger.WithError(err).Error("Speech synthesis failed")
+		return ""
+	}
+	requestLogger.WithFields(logrus.Fields{
+		"audioBytes": len(audio),
+		"duration":   time.Since(startTime),
+	}).Info("Answer synthesized to speech")
+	return "/speech/" + s.speechStore.Save(audio, session.Owner, session.Tenant)
+}
+
+// handleGetSpeech handles GET /speech/:id requests by serving synthesized audio
+// to the callers that may use the session of the answer.
+func (s *Server) handleGetSpeech(c echo.Context) error {
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+	audio, found, allowed := s.speechStore.Get(c.Param("id"), caller)
+	if !found {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Speech not found or expired"})
+	}
+	if !allowed {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Speech belongs to another caller"})
+	}
+	return c.Blob(http.StatusOK, "audio/wav", audio)
+}
+
+// handleSynthesizeSpeech handles POST /speech requests, synthesizing {"text": "..."} to WAV audio.
+func (s *Server) handleSynthesizeSpeech(c echo.Context) error {
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+	if limited, err := s.rejectRateLimited(c, caller, s.logger.WithContext(c.Request().Context())); limited {
+		return err
+	}
+	if s.currentConfig().TTSBackend == "" {
+		return c.JSON(http.StatusNotImplemented, map[string]string{"error": "Speech output is disabled; set TTS_BACKEND"})
+	}
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := c.Bind(&req); err != nil || strings.TrimSpace(req.Text) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "A non-empty text is required"})
+	}
+	audio, err := s.synthesize(c.Request().Context(), req.Text)
+	if err != nil {
+		s.logger.WithContext(c.Request().Context()).WithError(err).Error("Speech synthesis failed")
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	}
+	return c.Blob(http.StatusOK, "audio/wav", audio)
+}
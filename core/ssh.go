@@ -0,0 +1,332 @@
+/*
+Package core provides the SSH chat server of the Skynet Agent application.
+
+When SSH_ADDR is set, the agent accepts SSH connections on that address, so it
+can be reached where only the SSH port is open:
+
+	ssh -p 2222 skynet@host                Start a new conversation
+	ssh -t -p 2222 skynet@host <session>   Continue an earlier conversation
+
+Clients authenticate with a public key listed in SSH_AUTHORIZED_KEYS; passwords
+are not accepted. Every connection gets an interactive chat terminal with line
+editing and history. While the agent works, the tools it runs and their output
+are shown as they happen, and Ctrl-C interrupts the running request. The
+conversation is kept in the shared session store, so it can also be continued
+through the HTTP API, and its session ID is printed when the connection closes.
+
+The host key is read from SSH_HOST_KEY_FILE, and an Ed25519 key is generated
+there on first start. The protocol side (handshake, channels, pty and window
+requests, exit status) is handled by gliderlabs/ssh; this file only
+authenticates keys and runs the chat terminal of each session.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/gliderlabs/ssh"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/schema"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// sshToolOutputLines is the number of lines of tool output shown in the terminal
+const sshToolOutputLines = 20
+
+// sshInterrupt is the byte a terminal sends for Ctrl-C
+const sshInterrupt = 0x03
+
+// sshHandshakeTimeout is the time a client has to authenticate before its connection is closed
+const sshHandshakeTimeout = 30 * time.Second
+
+// StartSSH starts the SSH chat server in the background when SSH_ADDR is set.
+// The server stops accepting connections when the server is closed.
+//
+// Returns:
+//   - error: Host key, authorized keys, or listener error
+func (s *Server) StartSSH() error {
+	config := s.currentConfig()
+	if config.SSHAddr == "" {
+		return nil
+	}
+
+	hostKey, err := loadSSHHostKey(config.SSHHostKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load SSH host key: %w", err)
+	}
+	authorizedKeys, err := loadSSHAuthorizedKeys(config.SSHAuthorizedKeys)
+	if err != nil {
+		return fmt.Errorf("failed to load SSH authorized keys: %w", err)
+	}
+
+	sshLogger := s.logger.WithField("component", "ssh")
+	server := &ssh.Server{
+		Handler: func(session ssh.Session) {
+			s.serveSSHSession(session, sshLogger)
+		},
+		PublicKeyHandler: func(ctx ssh.Context, key ssh.PublicKey) bool {
+			comment, ok := authorizedKeys[string(key.Marshal())]
+			if ok {
+				ctx.Permissions().Extensions = map[string]string{"key": comment}
+			}
+			return ok
+		},
+		ConnCallback: func(ctx ssh.Context, conn net.Conn) net.Conn {
+			// The connection is set on the context once the handshake completes
+			time.AfterFunc(sshHandshakeTimeout, func() {
+				if ctx.Value(ssh.ContextKeyConn) == nil {
+					conn.Close()
+				}
+			})
+			return conn
+		},
+		ConnectionFailedCallback: func(conn net.Conn, err error) {
+			sshLogger.WithError(err).WithField("clientIP", conn.RemoteAddr().String()).Warn("SSH handshake failed")
+		},
+	}
+	server.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", config.SSHAddr)
+	if err != nil {
+		return err
+	}
+	s.stopSSH = func() { server.Close() }
+
+	sshLogger.WithFields(logrus.Fields{
+		"addr":           config.SSHAddr,
+		"authorizedKeys": len(authorizedKeys),
+		"fingerprint":    gossh.FingerprintSHA256(hostKey.PublicKey()),
+	}).Info("Starting SSH server")
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+			sshLogger.WithError(err).Error("SSH server stopped accepting connections")
+		}
+	}()
+	return nil
+}
+
+// loadSSHHostKey reads the host key, generating an Ed25519 key when the file does not exist.
+func loadSSHHostKey(path string) (gossh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		block, err := gossh.MarshalPrivateKey(privateKey, "skynet host key")
+		if err != nil {
+			return nil, err
+		}
+		data = pem.EncodeToMemory(block)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return gossh.ParsePrivateKey(data)
+}
+
+// loadSSHAuthorizedKeys reads an authorized_keys file into a set of wire-format
+// keys, each mapped to its comment for logging.
+func loadSSHAuthorizedKeys(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]string)
+	for len(bytes.TrimSpace(data)) > 0 {
+		key, comment, _, rest, err := gossh.ParseAuthorizedKey(data)
+		if err != nil {
+			return nil, err
+		}
+		keys[string(key.Marshal())] = comment
+		data = rest
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s lists no keys", path)
+	}
+	return keys, nil
+}
+
+// serveSSHSession runs the chat terminal of a session that requested a shell, or
+// an exec naming the session to continue.
+func (s *Server) serveSSHSession(session ssh.Session, sshLogger *logrus.Entry) {
+	connLogger := sshLogger.WithFields(logrus.Fields{
+		"sshUser":  session.User(),
+		"key":      session.Permissions().Extensions["key"],
+		"clientIP": session.RemoteAddr().String(),
+	})
+	connLogger.Info("Accepted SSH session")
+
+	// The pump forwards client input to the terminal, except while a request runs,
+	// when Ctrl-C interrupts the request and other input is dropped
+	input, inputWriter := io.Pipe()
+	var runMutex sync.Mutex
+	var cancelRun context.CancelFunc
+	go func() {
+		defer inputWriter.Close()
+		buf := make([]byte, 1024)
+		for {
+			n, err := session.Read(buf)
+			if n > 0 {
+				runMutex.Lock()
+				cancel := cancelRun
+				runMutex.Unlock()
+				if cancel != nil {
+					if bytes.IndexByte(buf[:n], sshInterrupt) >= 0 {
+						cancel()
+					}
+					continue
+				}
+				if _, err := inputWriter.Write(buf[:n]); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{input, session}, "skynet> ")
+	if _, windows, ok := session.Pty(); ok {
+		go func() {
+			for window := range windows {
+				terminal.SetSize(window.Width, window.Height)
+			}
+		}()
+	}
+
+	s.runSSHChat(session, terminal, strings.TrimSpace(session.RawCommand()), func(cancel context.CancelFunc) {
+		runMutex.Lock()
+		cancelRun = cancel
+		runMutex.Unlock()
+	}, connLogger)
+}
+
+// runSSHChat runs the conversation of an SSH session until the client quits.
+// setCancel is called with the cancel function of each running request, and with
+// nil when it ends.
+func (s *Server) runSSHChat(session ssh.Session, terminal *term.Terminal, sessionID string, setCancel func(context.CancelFunc), connLogger *logrus.Entry) {
+	config := s.currentConfig()
+	hostname, _ := os.Hostname()
+	fmt.Fprintf(terminal, "Skynet %s on %s (%s). Type \"exit\" to quit; Ctrl-C interrupts a running request.\n", Version, hostname, config.LLMProvider)
+	if sessionID != "" {
+		fmt.Fprintf(terminal, "Continuing session %s\n", sessionID)
+	}
+
+	for {
+		line, err := terminal.ReadLine()
+		if err != nil {
+			break
+		}
+		message := strings.TrimSpace(line)
+		if message == "" {
+			continue
+		}
+		if message == "exit" || message == "quit" {
+			break
+		}
+
+		ctx, cancel := context.WithCancel(ensureRequestID(context.Background()))
+		requestLogger := connLogger.WithFields(logrus.Fields{
+			"interface": "ssh",
+			"requestId": localtools.RequestIDFromContext(ctx),
+		})
+		setCancel(cancel)
+		response, ok := s.runSSHMessage(ctx, terminal, session, sessionID, message, requestLogger)
+		setCancel(nil)
+		cancel()
+
+		if !ok {
+			continue
+		}
+		sessionID = response.SessionID
+		fmt.Fprintln(terminal, response.Response)
+	}
+
+	if sessionID != "" {
+		fmt.Fprintf(terminal, "Continue this conversation with: ssh -t %s@<host> %s\n", session.User(), sessionID)
+	}
+}
+
+// runSSHMessage runs one message through the agent once the request queue admits
+// it, streaming the tools it runs to the terminal.
+func (s *Server) runSSHMessage(ctx context.Context, terminal *term.Terminal, session ssh.Session, sessionID, message string, requestLogger *logrus.Entry) (ChatResponse, bool) {
+	ticket, err := s.queue.Enqueue()
+	if err != nil {
+		requestLogger.Warn("Request queue is full, rejecting SSH message")
+		fmt.Fprint(terminal, "The server is busy, please try again later.\n")
+		return ChatResponse{}, false
+	}
+	defer ticket.Release()
+	if err := ticket.Wait(ctx, func(position int, eta time.Duration) {
+		fmt.Fprintf(terminal, "Waiting in queue (position %d, about %s)\n", position, eta.Round(time.Second))
+	}); err != nil {
+		fmt.Fprint(terminal, "Interrupted\n")
+		return ChatResponse{}, false
+	}
+
+	progress := &sshProgressHandler{
+		VerboseCallbackHandler: NewVerboseCallbackHandler(requestLogger.WithField("component", "ssh_agent"), s.currentConfig()),
+		terminal:               terminal,
+	}
+	user := fmt.Sprintf("ssh:%s@%s", session.User(), session.RemoteAddr())
+	response, _ := s.chat(WithCallbackHandler(ctx, progress), sessionID, message, user, requestLogger)
+	return response, true
+}
+
+// sshProgressHandler writes the tools the agent runs and their output to the SSH terminal.
+type sshProgressHandler struct {
+	*VerboseCallbackHandler
+	terminal *term.Terminal
+}
+
+func (h *sshProgressHandler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
+	h.VerboseCallbackHandler.HandleAgentAction(ctx, action)
+	escape := h.terminal.Escape
+	fmt.Fprintf(h.terminal, "%s⚙ %s%s %s\n", escape.Cyan, action.Tool, escape.Reset, strings.TrimSpace(action.ToolInput))
+}
+
+// HandleToolOutput shows the first lines of the tool's output.
+func (h *sshProgressHandler) HandleToolOutput(ctx context.Context, tool, input, output string, err error) {
+	escape := h.terminal.Escape
+	if err != nil {
+		fmt.Fprintf(h.terminal, "%s  %s%s\n", escape.Red, err, escape.Reset)
+		return
+	}
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > sshToolOutputLines {
+		lines = append(lines[:sshToolOutputLines], fmt.Sprintf("... (%d more lines)", len(lines)-sshToolOutputLines))
+	}
+	for _, line := range lines {
+		fmt.Fprintf(h.terminal, "%s  %s%s\n", escape.Blue, line, escape.Reset)
+	}
+}
+
+// Ensure sshProgressHandler implements the callbacks.Handler and ToolOutputHandler interfaces
+var (
+	_ callbacks.Handler = (*sshProgressHandler)(nil)
+	_ ToolOutputHandler = (*sshProgressHandler)(nil)
+)
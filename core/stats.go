@@ -0,0 +1,248 @@
+/*
+Package core provides per-tool usage statistics for the Skynet Agent application.
+
+This file implements:
+- ToolStats: Collector of per-tool call counts, failures, and latencies
+- StatsTool: Tool wrapper feeding the collector on every call
+- Prometheus text exposition of the collected metrics
+
+A call counts as failed when the tool returns an error or, following the tool
+convention of reporting problems as observations, when its output starts with
+"Error". Latency percentiles are computed over a sliding window of recent calls.
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// latencyWindow is the number of recent call latencies kept per tool for percentiles
+const latencyWindow = 1000
+
+// latencyQuantiles are the percentiles reported for every tool
+var latencyQuantiles = []float64{0.5, 0.9, 0.99}
+
+// toolMetrics holds the raw counters of a single tool.
+type toolMetrics struct {
+	calls     int64           // Total number of calls
+	failures  int64           // Calls that returned an error or an error observation
+	total     time.Duration   // Sum of all call durations
+	latencies []time.Duration // Ring buffer of the most recent call durations
+	next      int             // Next ring buffer position to overwrite
+}
+
+// ToolStatsSnapshot is a point-in-time summary of a tool's usage.
+type ToolStatsSnapshot struct {
+	Tool        string  `json:"tool"`        // Tool name
+	Calls       int64   `json:"calls"`       // Total number of calls
+	Failures    int64   `json:"failures"`    // Number of failed calls
+	FailureRate float64 `json:"failureRate"` // Failures divided by calls
+	MeanMs      float64 `json:"meanMs"`      // Mean latency in milliseconds
+	P50Ms       float64 `json:"p50Ms"`       // Median latency over the recent window
+	P90Ms       float64 `json:"p90Ms"`       // 90th percentile latency over the recent window
+	P99Ms       float64 `json:"p99Ms"`       // 99th percentile latency over the recent window
+}
+
+// ToolStats collects usage statistics for every wrapped tool.
+type ToolStats struct {
+	metrics map[string]*toolMetrics // Map of tool name to its counters
+	mutex   sync.Mutex              // Guards metrics against concurrent tool calls
+}
+
+// NewToolStats creates an empty statistics collector.
+//
+// Returns:
+//   - *ToolStats: Collector ready for use
+func NewToolStats() *ToolStats {
+	return &ToolStats{metrics: make(map[string]*toolMetrics)}
+}
+
+// Record adds a single call to the named tool's statistics.
+//
+// Parameters:
+//   - name: Tool name
+//   - duration: Wall-clock duration of the call
+//   - failed: Whether the call failed
+func (s *ToolStats) Record(name string, duration time.Duration, failed bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	metrics, exists := s.metrics[name]
+	if !exists {
+		metrics = &toolMetrics{latencies: make([]time.Duration, 0, latencyWindow)}
+		s.metrics[name] = metrics
+	}
+
+	metrics.calls++
+	if failed {
+		metrics.failures++
+	}
+	metrics.total += duration
+
+	if len(metrics.latencies) < latencyWindow {
+		metrics.latencies = append(metrics.latencies, duration)
+	} else {
+		metrics.latencies[metrics.next] = duration
+	}
+	metrics.next = (metrics.next + 1) % latencyWindow
+}
+
+// percentile returns the q-th quantile of sorted latencies using nearest-rank.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(q*float64(len(sorted)) + 0.5)
+	if index < 1 {
+		index = 1
+	}
+	if index > len(sorted) {
+		index = len(sorted)
+	}
+	return sorted[index-1]
+}
+
+// milliseconds converts a duration to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Snapshot returns the current statistics of every tool ordered by name.
+//
+// Returns:
+//   - []ToolStatsSnapshot: Per-tool usage summaries
+func (s *ToolStats) Snapshot() []ToolStatsSnapshot {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshots := make([]ToolStatsSnapshot, 0, len(s.metrics))
+	for name, metrics := range s.metrics {
+		sorted := append([]time.Duration(nil), metrics.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		snapshot := ToolStatsSnapshot{
+			Tool:     name,
+			Calls:    metrics.calls,
+			Failures: metrics.failures,
+			P50Ms:    milliseconds(percentile(sorted, 0.5)),
+			P90Ms:    milliseconds(percentile(sorted, 0.9)),
+			P99Ms:    milliseconds(percentile(sorted, 0.99)),
+		}
+		if metrics.calls > 0 {
+			snapshot.FailureRate = float64(metrics.failures) / float64(metrics.calls)
+			snapshot.MeanMs = milliseconds(metrics.total) / float64(metrics.calls)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Tool < snapshots[j].Tool })
+	return snapshots
+}
+
+// WritePrometheus writes the statistics in the Prometheus text exposition format.
+//
+// Parameters:
+//   - w: Destination writer, typically the HTTP response body
+//
+// Returns:
+//   - error: Any error writing to w
+func (s *ToolStats) WritePrometheus(w io.Writer) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	names := make([]string, 0, len(s.metrics))
+	for name := range s.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# HELP skynet_tool_calls_total Total number of tool calls.\n")
+	b.WriteString("# TYPE skynet_tool_calls_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "skynet_tool_calls_total{tool=%q} %d\n", name, s.metrics[name].calls)
+	}
+
+	b.WriteString("# HELP skynet_tool_failures_total Total number of failed tool calls.\n")
+	b.WriteString("# TYPE skynet_tool_failures_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "skynet_tool_failures_total{tool=%q} %d\n", name, s.metrics[name].failures)
+	}
+
+	b.WriteString("# HELP skynet_tool_duration_seconds Tool call latency over recent calls.\n")
+	b.WriteString("# TYPE skynet_tool_duration_seconds summary\n")
+	for _, name := range names {
+		metrics := s.metrics[name]
+		sorted := append([]time.Duration(nil), metrics.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		for _, q := range latencyQuantiles {
+			fmt.Fprintf(&b, "skynet_tool_duration_seconds{tool=%q,quantile=\"%g\"} %g\n", name, q, percentile(sorted, q).Seconds())
+		}
+		fmt.Fprintf(&b, "skynet_tool_duration_seconds_sum{tool=%q} %g\n", name, metrics.total.Seconds())
+		fmt.Fprintf(&b, "skynet_tool_duration_seconds_count{tool=%q} %d\n", name, metrics.calls)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// StatsTool wraps a tool and records each call's latency and outcome.
+type StatsTool struct {
+	tool  tools.Tool // The underlying tool being measured
+	stats *ToolStats // Collector receiving the measurements
+}
+
+// Name returns the wrapped tool's name.
+func (t *StatsTool) Name() string {
+	return t.tool.Name()
+}
+
+// Description returns the wrapped tool's description.
+func (t *StatsTool) Description() string {
+	return t.tool.Description()
+}
+
+// Call invokes the wrapped tool and records its latency and outcome.
+//
+// Parameters:
+//   - ctx: Execution context
+//   - input: Tool input provided by the agent
+//
+// Returns:
+//   - string: The wrapped tool's output
+//   - error: The wrapped tool's error
+func (t *StatsTool) Call(ctx context.Context, input string) (string, error) {
+	startTime := time.Now()
+	output, err := t.tool.Call(ctx, input)
+
+	failed := toolCallFailed(output, err)
+	t.stats.Record(t.tool.Name(), time.Since(startTime), failed)
+	return output, err
+}
+
+// WrapToolsWithStats records usage statistics for every tool in the list.
+//
+// Parameters:
+//   - toolsList: Tools to wrap
+//   - stats: Collector receiving the measurements
+//
+// Returns:
+//   - []tools.Tool: Tools with statistics collection applied
+func WrapToolsWithStats(toolsList []tools.Tool, stats *ToolStats) []tools.Tool {
+	wrapped := make([]tools.Tool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		wrapped = append(wrapped, &StatsTool{tool: tool, stats: stats})
+	}
+	return wrapped
+}
+
+var _ tools.Tool = (*StatsTool)(nil)
@@ -0,0 +1,50 @@
+/*
+Package core provides execution target selection for the Skynet Agent application.
+
+A chat request may name the host its tool commands run on with the "target"
+field: "local", "ssh:<host>", or "docker:<container>" (see the tools package for
+how commands reach each kind). Besides this host, only the targets listed in
+TARGETS may be used, so a client cannot point the agent at arbitrary hosts the
+server's SSH keys or Docker socket can reach. GET /targets lists them.
+*/
+package core
+
+import (
+	"fmt"
+	"net/http"
+
+	localtools "skynet/tools"
+
+	"github.com/labstack/echo/v4"
+)
+
+// resolveTarget parses a requested target and checks that it is configured.
+//
+// Parameters:
+//   - spec: Target of the request; empty means local
+//
+// Returns:
+//   - localtools.Target: The target the execution's commands run on
+//   - error: Invalid or unconfigured target, suitable for the client
+func (s *Server) resolveTarget(spec string) (localtools.Target, error) {
+	target, err := localtools.ParseTarget(spec)
+	if err != nil || target.Kind == localtools.TargetLocal {
+		return target, err
+	}
+	for _, configured := range s.currentConfig().Targets {
+		if parsed, err := localtools.ParseTarget(configured); err == nil && parsed == target {
+			return target, nil
+		}
+	}
+	return localtools.Target{}, fmt.Errorf("target %q is not configured; see GET /targets", target)
+}
+
+// handleListTargets handles GET /targets requests.
+func (s *Server) handleListTargets(c echo.Context) error {
+	targets := []string{localtools.TargetLocal}
+	targets = append(targets, s.currentConfig().Targets...)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"targets": targets,
+		"count":   len(targets),
+	})
+}
@@ -0,0 +1,672 @@
+/*
+Package core provides background autonomous tasks for the Skynet Agent application.
+
+A task pursues a long goal, such as "migrate all containers to the new registry",
+outside the lifecycle of any HTTP request. The agent works on it one step at a
+time: every step is an agent execution in the task's session that is told the
+goal and the progress of the previous steps and carries out the next step. After
+each step the task is checkpointed to TASKS_PATH, so tasks that were running when
+the server stopped continue with their next step after a restart. A task ends
+when the agent starts an answer with TASK COMPLETE, when a step fails, or when it
+reaches its step limit.
+
+Tasks are managed through the API:
+
+	POST /tasks               {"goal": "...", "maxSteps": 20}
+	GET  /tasks
+	GET  /tasks/:id
+	POST /tasks/:id/pause     stop after the current step
+	POST /tasks/:id/resume    continue a paused or failed task
+	POST /tasks/:id/cancel    stop immediately, abandoning the current step
+
+Steps wait in the request queue like chat requests. Finished tasks are delivered
+to the notification sinks as reports.
+
+A task runs as the caller that created it: its session belongs to the caller, its
+steps may only use the tools of the caller's tenant, and their executions and
+tokens are accounted to the caller's key and tenant, also after a restart.
+Creating a task counts against the tenant's rate limit like a chat request.
+Like its session, a task of a key may only be listed, read, paused, resumed, or
+cancelled by the key, the other keys of its tenant, and admins.
+*/
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Task statuses
+const (
+	TaskRunning   = "running"   // Steps are being carried out
+	TaskPaused    = "paused"    // Waiting to be resumed
+	TaskCompleted = "completed" // The agent reported the goal achieved
+	TaskFailed    = "failed"    // A step failed or the step limit was reached; can be resumed
+	TaskCancelled = "cancelled" // Stopped for good
+)
+
+// taskCompleteMarker starts the answer of the step that achieves the goal
+const taskCompleteMarker = "TASK COMPLETE"
+
+// taskProgressSteps is the number of previous steps summarized in a step's prompt
+const taskProgressSteps = 10
+
+// taskProgressLength is the maximum length of a previous step's answer in a step's prompt
+const taskProgressLength = 600
+
+// TaskStep is a step of a task, checkpointed once it finished.
+type TaskStep struct {
+	Number      int       `json:"number"`
+	ExecutionID string    `json:"executionId"`     // Execution whose timeline GET /executions/:id/trace returns
+	Response    string    `json:"response"`        // Answer of the agent
+	Error       string    `json:"error,omitempty"` // Why the step failed
+	StartedAt   time.Time `json:"startedAt"`
+	FinishedAt  time.Time `json:"finishedAt"`
+}
+
+// Task is a background goal the agent works on step by step.
+type Task struct {
+	ID        string     `json:"id"`
+	Goal      string     `json:"goal"`
+	Status    string     `json:"status"`
+	SessionID string     `json:"sessionId"` // Session the steps run in
+	MaxSteps  int        `json:"maxSteps"`  // Steps after which the task fails
+	Steps     []TaskStep `json:"steps"`
+	Result    string     `json:"result,omitempty"` // Summary reported by the agent on completion
+	Error     string     `json:"error,omitempty"`  // Why the task failed
+	Owner     string     `json:"owner,omitempty"`  // Owner of the creating caller; empty for anonymous callers
+	KeyID     string     `json:"keyId,omitempty"`  // API key of the creating caller
+	Tenant    string     `json:"tenant,omitempty"` // Tenant the steps are accounted to and restricted by
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// caller returns the caller the task's steps run as.
+func (task *Task) caller() sessionCaller {
+	return sessionCaller{
+		owner:  task.Owner,
+		keyID:  task.KeyID,
+		tenant: task.Tenant,
+	}
+}
+
+// copyTask returns a copy of a task that does not share its steps.
+func copyTask(task *Task) Task {
+	copied := *task
+	copied.Steps = append([]TaskStep(nil), task.Steps...)
+	return copied
+}
+
+// TaskStore holds the tasks, persists them to disk, and tracks their runners.
+type TaskStore struct {
+	tasks   map[string]*Task              // Map of task ID to task
+	runners map[string]context.CancelFunc // Running tasks, by ID
+	path    string                        // JSON file the tasks are persisted to; empty keeps them in memory
+	cipher  *FieldCipher                  // Encrypts goals, answers, and errors on disk; nil stores them in plain text
+	mutex   sync.Mutex                    // Guards tasks and runners
+	logger  *logrus.Logger                // Structured logger for operational monitoring
+}
+
+// NewTaskStore creates a store and loads previously persisted tasks. A missing
+// file is not an error; it is created with the first task.
+//
+// Parameters:
+//   - path: JSON file used for persistence; empty disables persistence
+//   - cipher: Cipher for goals, answers, and errors, or nil to store them in plain text
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *TaskStore: Store ready for use
+//   - error: Any error reading, decoding, or decrypting the persisted tasks
+func NewTaskStore(path string, cipher *FieldCipher, logger *logrus.Logger) (*TaskStore, error) {
+	store := &TaskStore{
+		tasks:   make(map[string]*Task),
+		runners: make(map[string]context.CancelFunc),
+		path:    path,
+		cipher:  cipher,
+		logger:  logger,
+	}
+
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tasks file: %w", err)
+	}
+
+	var tasks []*Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to decode tasks file: %w", err)
+	}
+	for _, task := range tasks {
+		if err := store.decrypt(task); err != nil {
+			return nil, fmt.Errorf("failed to decrypt task %s: %w", task.ID, err)
+		}
+		store.tasks[task.ID] = task
+	}
+
+	logger.WithFields(logrus.Fields{
+		"path":  path,
+		"tasks": len(store.tasks),
+	}).Info("Tasks loaded")
+	return store, nil
+}
+
+// save writes all tasks to the persistence file. Callers must hold the mutex.
+func (s *TaskStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	tasks := make([]*Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, s.encrypt(task))
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tasks: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial file behind
+	staged := s.path + ".tmp"
+	if err := os.WriteFile(staged, data, 0600); err != nil {
+		return fmt.Errorf("failed to write tasks file: %w", err)
+	}
+	if err := os.Rename(staged, s.path); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to replace tasks file: %w", err)
+	}
+	return nil
+}
+
+// encrypt returns a copy of a task with its goal, answers, and errors encrypted.
+func (s *TaskStore) encrypt(task *Task) *Task {
+	encrypted := copyTask(task)
+	encrypted.Goal = s.cipher.Encrypt(encrypted.Goal)
+	encrypted.Result = s.cipher.Encrypt(encrypted.Result)
+	encrypted.Error = s.cipher.Encrypt(encrypted.Error)
+	for i := range encrypted.Steps {
+		encrypted.Steps[i].Response = s.cipher.Encrypt(encrypted.Steps[i].Response)
+		encrypted.Steps[i].Error = s.cipher.Encrypt(encrypted.Steps[i].Error)
+	}
+	return &encrypted
+}
+
+// decrypt restores the fields encrypted by encrypt in place.
+func (s *TaskStore) decrypt(task *Task) error {
+	fields := []*string{&task.Goal, &task.Result, &task.Error}
+	for i := range task.Steps {
+		fields = append(fields, &task.Steps[i].Response, &task.Steps[i].Error)
+	}
+	for _, field := range fields {
+		plaintext, err := s.cipher.Decrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = plaintext
+	}
+	return nil
+}
+
+// Add stores a new task and persists the store.
+func (s *TaskStore) Add(task *Task) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tasks[task.ID] = task
+	if err := s.save(); err != nil {
+		delete(s.tasks, task.ID)
+		return err
+	}
+	return nil
+}
+
+// Get returns a copy of a task.
+func (s *TaskStore) Get(id string) (Task, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return Task{}, false
+	}
+	return copyTask(task), true
+}
+
+// List returns copies of all tasks, newest first.
+func (s *TaskStore) List() []Task {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, copyTask(task))
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+	})
+	return tasks
+}
+
+// Update changes a task under the store's lock and checkpoints it. A failed
+// checkpoint is logged; the change is kept so the running task is not disturbed.
+//
+// Parameters:
+//   - id: ID of the task
+//   - change: Function changing the task; it returns an error to reject the change
+//
+// Returns:
+//   - Task: Copy of the task after the change
+//   - error: Unknown task or the error returned by change
+func (s *TaskStore) Update(id string, change func(task *Task) error) (Task, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return Task{}, fmt.Errorf("task %q not found", id)
+	}
+	if err := change(task); err != nil {
+		return copyTask(task), err
+	}
+	task.UpdatedAt = time.Now()
+	if err := s.save(); err != nil {
+		s.logger.WithError(err).WithField("taskID", id).Error("Failed to checkpoint task")
+	}
+	return copyTask(task), nil
+}
+
+// startRunner registers the runner of a task; it returns false if one is already running.
+func (s *TaskStore) startRunner(id string, cancel context.CancelFunc) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, running := s.runners[id]; running {
+		return false
+	}
+	s.runners[id] = cancel
+	return true
+}
+
+// finishRunner unregisters the runner of a task once it returned, unless the task
+// was resumed while the runner was stopping; it returns false if the runner must
+// continue.
+func (s *TaskStore) finishRunner(ctx context.Context, id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if task, exists := s.tasks[id]; exists && task.Status == TaskRunning && ctx.Err() == nil {
+		return false
+	}
+	if cancel, running := s.runners[id]; running {
+		cancel()
+		delete(s.runners, id)
+	}
+	return true
+}
+
+// stopRunner cancels and unregisters the runner of a task, if any.
+func (s *TaskStore) stopRunner(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if cancel, running := s.runners[id]; running {
+		cancel()
+		delete(s.runners, id)
+	}
+}
+
+// taskPrompt returns the message of a task's next step.
+func taskPrompt(task Task) string {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "You are working on a long-running task in the background, one step at a time, without a user to answer questions.\n\nGoal: %s\n\n", task.Goal)
+
+	if len(task.Steps) == 0 {
+		prompt.WriteString("Plan the work and carry out the first step now.")
+	} else {
+		prompt.WriteString("Progress so far:\n")
+		first := len(task.Steps) - taskProgressSteps
+		if first < 0 {
+			first = 0
+		}
+		for _, step := range task.Steps[first:] {
+			summary := step.Response
+			if step.Error != "" {
+				summary = "failed: " + step.Error
+			}
+			if len(summary) > taskProgressLength {
+				summary = summary[:taskProgressLength] + "..."
+			}
+			fmt.Fprintf(&prompt, "Step %d: %s\n", step.Number, summary)
+		}
+		prompt.WriteString("\nCheck where the work stands and carry out the next step now.")
+	}
+
+	fmt.Fprintf(&prompt, " End your answer with a short summary of what you did. When the whole goal is achieved, start your answer with %s followed by a summary of the outcome.", taskCompleteMarker)
+	return prompt.String()
+}
+
+// ResumeTasks starts the runners of the tasks that were running when the server
+// last stopped. It is called once the server is ready to execute requests.
+func (s *Server) ResumeTasks() {
+	for _, task := range s.tasks.List() {
+		if task.Status == TaskRunning {
+			s.logger.WithField("taskID", task.ID).Info("Resuming task")
+			s.startTask(task.ID)
+		}
+	}
+}
+
+// startTask starts the runner of a task unless one is already running.
+func (s *Server) startTask(id string) {
+	ctx, cancel := context.WithCancel(s.tasksCtx)
+	if !s.tasks.startRunner(id, cancel) {
+		cancel()
+		return
+	}
+	go func() {
+		for {
+			s.runTask(ctx, id)
+			if s.tasks.finishRunner(ctx, id) {
+				return
+			}
+		}
+	}()
+}
+
+// runTask carries out the steps of a task until it finishes, is paused or
+// cancelled, or the server stops.
+func (s *Server) runTask(ctx context.Context, id string) {
+	taskLogger := s.logger.WithFields(logrus.Fields{
+		"component": "tasks",
+		"taskID":    id,
+	})
+
+	for {
+		task, exists := s.tasks.Get(id)
+		if !exists || task.Status != TaskRunning {
+			return
+		}
+		if len(task.Steps) >= task.MaxSteps {
+			task, _ = s.tasks.Update(id, func(task *Task) error {
+				task.Status = TaskFailed
+				task.Error = fmt.Sprintf("the goal was not achieved within %d steps", task.MaxSteps)
+				return nil
+			})
+			s.notifyTask(task)
+			taskLogger.Warn("Task reached its step limit")
+			return
+		}
+
+		// Steps consume the caller's tokens; a task over a quota fails and can be resumed later
+		if err := s.quotas.Check(s.quotaSubjects(task.caller())); err != nil {
+			task, _ = s.tasks.Update(id, func(task *Task) error {
+				task.Status = TaskFailed
+				task.Error = err.Error()
+				return nil
+			})
+			s.notifyTask(task)
+			taskLogger.WithError(err).Warn("Task stopped by a quota")
+			return
+		}
+
+		// Steps share the request queue with chat requests; wait while it is full
+		ticket, err := s.queue.Enqueue()
+		for err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.queue.RetryAfter()):
+			}
+			ticket, err = s.queue.Enqueue()
+		}
+		if err := ticket.Wait(ctx, nil); err != nil {
+			ticket.Release()
+			return
+		}
+
+		step := TaskStep{Number: len(task.Steps) + 1, StartedAt: time.Now()}
+		stepLogger := taskLogger.WithField("step", step.Number)
+		stepLogger.Info("Starting task step")
+		response, err := s.chat(s.withCaller(ctx, task.caller()), task.SessionID, taskPrompt(task), "task:"+id, stepLogger)
+		ticket.Release()
+
+		// A step interrupted by cancellation or shutdown is not recorded and runs again on resume
+		if ctx.Err() != nil {
+			return
+		}
+
+		step.FinishedAt = time.Now()
+		step.ExecutionID = response.ExecutionID
+		step.Response = response.Response
+		if err != nil {
+			step.Error = err.Error()
+		}
+		task, _ = s.tasks.Update(id, func(task *Task) error {
+			task.Steps = append(task.Steps, step)
+			switch {
+			case step.Error != "":
+				task.Status = TaskFailed
+				task.Error = fmt.Sprintf("step %d failed: %s", step.Number, step.Error)
+			case strings.Contains(step.Response, taskCompleteMarker):
+				task.Status = TaskCompleted
+				_, result, _ := strings.Cut(step.Response, taskCompleteMarker)
+				task.Result = strings.TrimSpace(strings.TrimLeft(result, ":.- \n"))
+			}
+			return nil
+		})
+
+		switch task.Status {
+		case TaskCompleted:
+			stepLogger.Info("Task completed")
+			s.notifyTask(task)
+			return
+		case TaskFailed:
+			stepLogger.WithError(err).Warn("Task step failed")
+			s.notifyTask(task)
+			return
+		}
+		stepLogger.Info("Task step completed")
+	}
+}
+
+// notifyTask delivers a finished task to the notification sinks as a report.
+func (s *Server) notifyTask(task Task) {
+	notification := Notification{
+		Kind:      NotifyOnReport,
+		Status:    "succeeded",
+		Title:     fmt.Sprintf("Task %s", task.ID),
+		SessionID: task.SessionID,
+		User:      "task:" + task.ID,
+		Duration:  task.UpdatedAt.Sub(task.CreatedAt),
+		Message:   task.Goal,
+		Response:  task.Result,
+		Error:     task.Error,
+	}
+	if task.Status != TaskCompleted {
+		notification.Status = "failed"
+	}
+	if len(task.Steps) > 0 {
+		notification.ExecutionID = task.Steps[len(task.Steps)-1].ExecutionID
+	}
+	s.notifications.Notify(notification)
+}
+
+// handleCreateTask handles POST /tasks requests by starting a background task.
+func (s *Server) handleCreateTask(c echo.Context) error {
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"endpoint": "/tasks",
+		"method":   "POST",
+		"clientIP": c.RealIP(),
+	})
+
+	var req struct {
+		Goal     string `json:"goal"`
+		MaxSteps int    `json:"maxSteps"`
+	}
+	if err := c.Bind(&req); err != nil {
+		requestLogger.WithError(err).Error("Failed to parse task request")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	req.Goal = strings.TrimSpace(req.Goal)
+	if req.Goal == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Goal is required"})
+	}
+	if req.MaxSteps <= 0 {
+		req.MaxSteps = s.currentConfig().TaskMaxSteps
+	}
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+	if limited, err := s.rejectRateLimited(c, caller, requestLogger); limited {
+		return err
+	}
+	if exceeded, err := s.rejectQuotaExceeded(c, caller, requestLogger); exceeded {
+		return err
+	}
+
+	now := time.Now()
+	id := fmt.Sprintf("task_%d", now.UnixNano())
+	task := &Task{
+		ID:        id,
+		Goal:      req.Goal,
+		Status:    TaskRunning,
+		SessionID: id,
+		MaxSteps:  req.MaxSteps,
+		Steps:     []TaskStep{},
+		Owner:     caller.owner,
+		KeyID:     caller.keyID,
+		Tenant:    caller.tenant,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.memoryStore.GetOrCreateOwnedSession(id, caller.owner, caller.tenant)
+	if err := s.tasks.Add(task); err != nil {
+		requestLogger.WithError(err).Error("Failed to persist task")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to persist task"})
+	}
+	s.startTask(id)
+
+	requestLogger.WithFields(logrus.Fields{
+		"taskID":   id,
+		"maxSteps": req.MaxSteps,
+	}).Info("Task created")
+	created, _ := s.tasks.Get(id)
+	return c.JSON(http.StatusCreated, created)
+}
+
+// handleListTasks handles GET /tasks requests, listing the tasks the caller may use.
+func (s *Server) handleListTasks(c echo.Context) error {
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+
+	tasks := make([]Task, 0)
+	for _, task := range s.tasks.List() {
+		if caller.mayAccess(task.Owner, task.Tenant) {
+			tasks = append(tasks, task)
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tasks": tasks,
+		"count": len(tasks),
+	})
+}
+
+// handleGetTask handles GET /tasks/:id requests.
+func (s *Server) handleGetTask(c echo.Context) error {
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+
+	task, exists := s.tasks.Get(c.Param("id"))
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Task not found"})
+	}
+	if !caller.mayAccess(task.Owner, task.Tenant) {
+		return s.rejectForeignTask(c, task, s.logger.WithContext(c.Request().Context()).WithField("taskID", task.ID))
+	}
+	return c.JSON(http.StatusOK, task)
+}
+
+// rejectForeignTask answers a request for a task the caller does not own with 403.
+func (s *Server) rejectForeignTask(c echo.Context, task Task, requestLogger *logrus.Entry) error {
+	requestLogger.WithField("owner", task.Owner).Warn("Rejected access to a task owned by another caller")
+	return c.JSON(http.StatusForbidden, map[string]string{"error": "Task belongs to another caller"})
+}
+
+// handleTaskAction handles POST /tasks/:id/pause, /resume, and /cancel requests.
+func (s *Server) handleTaskAction(action string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+			"endpoint": "/tasks/:id/" + action,
+			"method":   "POST",
+			"taskID":   id,
+			"clientIP": c.RealIP(),
+		})
+
+		caller, answered, err := s.callerOf(c)
+		if answered {
+			return err
+		}
+		existing, exists := s.tasks.Get(id)
+		if !exists {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Task not found"})
+		}
+		// A resumed task runs with its creator's identity, so only the creator's side may act on it
+		if !caller.mayAccess(existing.Owner, existing.Tenant) {
+			return s.rejectForeignTask(c, existing, requestLogger)
+		}
+
+		task, err := s.tasks.Update(id, func(task *Task) error {
+			switch {
+			case action == "pause" && task.Status == TaskRunning:
+				task.Status = TaskPaused
+			case action == "resume" && (task.Status == TaskPaused || task.Status == TaskFailed):
+				task.Status = TaskRunning
+				task.Error = ""
+			case action == "cancel" && task.Status != TaskCompleted && task.Status != TaskCancelled:
+				task.Status = TaskCancelled
+			default:
+				return fmt.Errorf("cannot %s a task that is %s", action, task.Status)
+			}
+			return nil
+		})
+		if err != nil {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+
+		// Pausing lets the current step finish; cancelling abandons it
+		switch action {
+		case "resume":
+			s.startTask(id)
+		case "cancel":
+			s.tasks.stopRunner(id)
+		}
+
+		requestLogger.WithField("status", task.Status).Info("Task " + action + " applied")
+		return c.JSON(http.StatusOK, task)
+	}
+}
@@ -0,0 +1,359 @@
+/*
+Package core provides multi-tenancy for the Skynet Agent application.
+
+One deployment can serve several teams with different permissions. Tenants are
+declared in a YAML file:
+
+	tenants:
+	  - id: payments
+	    name: Payments team
+	    tools: [sysinfo, ls, cat, kubectl]
+	    requests_per_minute: 30
+	  - id: sre
+	    name: Site reliability
+
+A caller belongs to a tenant through its API key, created with a tenant:
+
+	POST /admin/keys {"name": "payments-ci", "scopes": ["chat"], "tenant": "payments"}
+
+The tenant then applies to every request made with the key:
+
+  - sessions: sessions created with the key belong to the tenant, and every key
+    of the tenant may use them; other tenants' callers receive 403
+  - tools: with tools set, executions may only call the listed tools
+  - rate limits: with requests_per_minute set, requests that start executions
+    (chat, tasks, workflow runs, and hooks) beyond the limit receive 429 with a
+    Retry-After header; short bursts up to the limit are allowed
+  - quotas: with quota set, daily and monthly requests and LLM tokens are limited;
+    see quota.go
+  - usage: requests, rate-limited requests, executions, failed executions,
+    execution time, and LLM tokens are accounted per tenant, reported by GET /admin/tenants and
+    GET /metrics, and the tenant is recorded in the audit log
+
+A hook declared with a tenant runs its executions as that tenant. Admins are not
+bound to a tenant and are not limited. Anonymous callers are not limited either,
+which is why, once tenants are declared, they are rejected unless ANONYMOUS_ACCESS
+is "on" (see ownership.go). A key whose tenant is no longer declared is rejected
+with 403.
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// tenantIDPattern matches valid tenant IDs
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Tenant is a team sharing the deployment, declared in the tenants file.
+type Tenant struct {
+	ID                string   `yaml:"id" json:"id"`                                 // Identifier used by API keys and sessions
+	Name              string   `yaml:"name" json:"name,omitempty"`                   // Display name
+	Tools             []string `yaml:"tools" json:"tools,omitempty"`                 // Tools the tenant's executions may call; empty allows all
+	RequestsPerMinute int      `yaml:"requests_per_minute" json:"requestsPerMinute"` // Requests starting executions allowed per minute; 0 is unlimited
+	Quota             Quota    `yaml:"quota" json:"quota"`                           // Daily and monthly usage limits; zero limits are unlimited
+}
+
+// TenantUsage is the usage accounted to a tenant since startup.
+type TenantUsage struct {
+	Requests         int64   `json:"requests"`         // Requests accepted
+	RateLimited      int64   `json:"rateLimited"`      // Requests rejected by the rate limit
+	Executions       int64   `json:"executions"`       // Agent executions finished
+	Failures         int64   `json:"failures"`         // Agent executions that failed
+	ExecutionSeconds float64 `json:"executionSeconds"` // Total execution time
+	Tokens           int64   `json:"tokens"`           // LLM tokens used by the tenant's executions
+}
+
+// TenantReport is a tenant with its usage, as returned by GET /admin/tenants.
+type TenantReport struct {
+	Tenant
+	Usage TenantUsage `json:"usage"`
+}
+
+// tenantsFile is the top-level structure of the tenants file.
+type tenantsFile struct {
+	Tenants []Tenant `yaml:"tenants"`
+}
+
+// tenantState is a tenant with its rate limit bucket and usage.
+type tenantState struct {
+	tenant   Tenant
+	tokens   float64   // Requests available in the rate limit bucket
+	refilled time.Time // Last time tokens were added to the bucket
+	usage    TenantUsage
+}
+
+// TenantStore holds the declared tenants, their rate limits, and their usage.
+type TenantStore struct {
+	tenants map[string]*tenantState // Map of tenant ID to tenant
+	mutex   sync.Mutex              // Guards the rate limit buckets and usage
+}
+
+// NewTenantStore loads the tenants file. A missing file is not an error; the
+// deployment then has no tenants.
+//
+// Parameters:
+//   - path: YAML file declaring the tenants; empty disables tenants
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *TenantStore: Store holding the declared tenants
+//   - error: Any error reading, decoding, or validating the file
+func NewTenantStore(path string, logger *logrus.Logger) (*TenantStore, error) {
+	store := &TenantStore{tenants: make(map[string]*tenantState)}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+
+	var file tenantsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode tenants file: %w", err)
+	}
+
+	now := time.Now()
+	for _, tenant := range file.Tenants {
+		if !tenantIDPattern.MatchString(tenant.ID) {
+			return nil, fmt.Errorf("invalid tenant ID %q: use lowercase letters, digits, '-', and '_'", tenant.ID)
+		}
+		if _, exists := store.tenants[tenant.ID]; exists {
+			return nil, fmt.Errorf("tenant %q is declared more than once", tenant.ID)
+		}
+		if tenant.RequestsPerMinute < 0 {
+			return nil, fmt.Errorf("tenant %q: requests_per_minute must not be negative", tenant.ID)
+		}
+		if err := tenant.Quota.validate(); err != nil {
+			return nil, fmt.Errorf("tenant %q: %w", tenant.ID, err)
+		}
+		store.tenants[tenant.ID] = &tenantState{
+			tenant:   tenant,
+			tokens:   float64(tenant.RequestsPerMinute),
+			refilled: now,
+		}
+
+		logger.WithFields(logrus.Fields{
+			"tenant":            tenant.ID,
+			"tools":             tenant.Tools,
+			"requestsPerMinute": tenant.RequestsPerMinute,
+			"quota":             tenant.Quota,
+		}).Info("Tenant loaded")
+	}
+	return store, nil
+}
+
+// Declared reports whether the tenants file declares any tenant.
+func (t *TenantStore) Declared() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return len(t.tenants) > 0
+}
+
+// Get returns a declared tenant.
+func (t *TenantStore) Get(id string) (Tenant, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	state, exists := t.tenants[id]
+	if !exists {
+		return Tenant{}, false
+	}
+	return state.tenant, true
+}
+
+// Allow takes a request from a tenant's rate limit bucket and accounts it.
+//
+// Parameters:
+//   - id: Tenant ID
+//
+// Returns:
+//   - time.Duration: Time until the next request is allowed, when this one is not
+//   - bool: Whether the request is allowed; requests of unknown tenants always are
+func (t *TenantStore) Allow(id string) (time.Duration, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	state, exists := t.tenants[id]
+	if !exists {
+		return 0, true
+	}
+	limit := float64(state.tenant.RequestsPerMinute)
+	if limit == 0 {
+		state.usage.Requests++
+		return 0, true
+	}
+
+	// The bucket refills at the limit's rate and holds at most a minute's worth
+	now := time.Now()
+	state.tokens = math.Min(limit, state.tokens+now.Sub(state.refilled).Minutes()*limit)
+	state.refilled = now
+	if state.tokens < 1 {
+		state.usage.RateLimited++
+		return time.Duration((1 - state.tokens) / limit * float64(time.Minute)), false
+	}
+	state.tokens--
+	state.usage.Requests++
+	return 0, true
+}
+
+// RecordExecution accounts a finished agent execution to a tenant.
+//
+// Parameters:
+//   - id: Tenant ID; executions without a tenant are not accounted
+//   - duration: Execution time
+//   - err: Execution error, nil on success
+func (t *TenantStore) RecordExecution(id string, duration time.Duration, err error) {
+	if id == "" {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	state, exists := t.tenants[id]
+	if !exists {
+		return
+	}
+	state.usage.Executions++
+	state.usage.ExecutionSeconds += duration.Seconds()
+	if err != nil {
+		state.usage.Failures++
+	}
+}
+
+// RecordTokens accounts the LLM tokens of a finished execution to a tenant.
+func (t *TenantStore) RecordTokens(id string, tokens int64) {
+	if id == "" || tokens <= 0 {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if state, exists := t.tenants[id]; exists {
+		state.usage.Tokens += tokens
+	}
+}
+
+// List returns the tenants with their usage, ordered by ID.
+func (t *TenantStore) List() []TenantReport {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	reports := make([]TenantReport, 0, len(t.tenants))
+	for _, state := range t.tenants {
+		reports = append(reports, TenantReport{Tenant: state.tenant, Usage: state.usage})
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].ID < reports[j].ID
+	})
+	return reports
+}
+
+// WritePrometheus writes the usage counters of every tenant in the Prometheus
+// text exposition format.
+//
+// Parameters:
+//   - w: Destination of the metrics
+//
+// Returns:
+//   - error: Write failure
+func (t *TenantStore) WritePrometheus(w io.Writer) error {
+	reports := t.List()
+	if len(reports) == 0 {
+		return nil
+	}
+
+	metrics := []struct {
+		name, help, kind string
+		value            func(usage TenantUsage) string
+	}{
+		{"skynet_tenant_requests_total", "Total number of chat requests accepted per tenant.", "counter",
+			func(usage TenantUsage) string { return strconv.FormatInt(usage.Requests, 10) }},
+		{"skynet_tenant_rate_limited_total", "Total number of chat requests rejected by the tenant's rate limit.", "counter",
+			func(usage TenantUsage) string { return strconv.FormatInt(usage.RateLimited, 10) }},
+		{"skynet_tenant_executions_total", "Total number of agent executions per tenant.", "counter",
+			func(usage TenantUsage) string { return strconv.FormatInt(usage.Executions, 10) }},
+		{"skynet_tenant_execution_failures_total", "Total number of failed agent executions per tenant.", "counter",
+			func(usage TenantUsage) string { return strconv.FormatInt(usage.Failures, 10) }},
+		{"skynet_tenant_execution_seconds_total", "Total agent execution time per tenant.", "counter",
+			func(usage TenantUsage) string { return strconv.FormatFloat(usage.ExecutionSeconds, 'f', 3, 64) }},
+		{"skynet_tenant_tokens_total", "Total number of LLM tokens used per tenant.", "counter",
+			func(usage TenantUsage) string { return strconv.FormatInt(usage.Tokens, 10) }},
+	}
+
+	var b strings.Builder
+	for _, metric := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", metric.name, metric.kind)
+		for _, report := range reports {
+			fmt.Fprintf(&b, "%s{tenant=%q} %s\n", metric.name, report.ID, metric.value(report.Usage))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// callerKey is the unexported context key type for the caller of an execution
+type callerKey struct{}
+
+// withCaller returns a derived context attributing the execution to a caller and
+// restricting it to the tools of the caller's tenant.
+func (s *Server) withCaller(ctx context.Context, caller sessionCaller) context.Context {
+	ctx = context.WithValue(ctx, callerKey{}, caller)
+	if tenant, exists := s.tenants.Get(caller.tenant); caller.tenant != "" && exists {
+		ctx = WithAllowedTools(ctx, tenant.Tools)
+	}
+	return ctx
+}
+
+// callerFromContext returns the caller an execution is attributed to; anonymous
+// when the context carries none.
+func callerFromContext(ctx context.Context) sessionCaller {
+	caller, _ := ctx.Value(callerKey{}).(sessionCaller)
+	return caller
+}
+
+// rejectRateLimited answers a request beyond its tenant's rate limit with 429. It
+// reports whether the request was answered, along with the result of writing the response.
+func (s *Server) rejectRateLimited(c echo.Context, caller sessionCaller, requestLogger *logrus.Entry) (bool, error) {
+	if caller.tenant == "" {
+		return false, nil
+	}
+	retry, allowed := s.tenants.Allow(caller.tenant)
+	if allowed {
+		return false, nil
+	}
+	requestLogger.WithField("tenant", caller.tenant).Warn("Rejected request beyond the tenant's rate limit")
+	c.Response().Header().Set("Retry-After", strconv.Itoa(max(int(math.Ceil(retry.Seconds())), 1)))
+	return true, c.JSON(http.StatusTooManyRequests, map[string]string{"error": "Rate limit of the tenant exceeded; try again later"})
+}
+
+// handleListTenants handles GET /admin/tenants requests.
+func (s *Server) handleListTenants(c echo.Context) error {
+	tenants := s.tenants.List()
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tenants": tenants,
+		"count":   len(tenants),
+	})
+}
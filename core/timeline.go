@@ -0,0 +1,304 @@
+/*
+Package core provides structured execution timelines for the Skynet Agent application.
+
+Every agent execution records an ExecutionTrace: the LLM calls, tool calls, agent
+decisions, and errors it went through, each with a timestamp. The callback
+handlers only log these events; the timeline keeps them so that clients and
+operators can inspect how an answer came about with GET /executions/:id/trace,
+while the execution is running and after it has finished. Like the session it
+ran in, a timeline can only be read by the session's owner, its tenant, and admins.
+
+Timelines are kept in memory for the most recent executions only.
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// maxExecutionTraces is the number of most recent execution timelines kept
+const maxExecutionTraces = 500
+
+// Execution timeline event types
+const (
+	TraceEventLLMStart    = "llm_start"
+	TraceEventLLMEnd      = "llm_end"
+	TraceEventLLMError    = "llm_error"
+	TraceEventToolStart   = "tool_start"
+	TraceEventToolEnd     = "tool_end"
+	TraceEventToolError   = "tool_error"
+	TraceEventAgentAction = "agent_action"
+	TraceEventAgentFinish = "agent_finish"
+	TraceEventError       = "error"
+)
+
+// Execution statuses
+const (
+	ExecutionRunning   = "running"
+	ExecutionCompleted = "completed"
+	ExecutionFailed    = "failed"
+	ExecutionCancelled = "cancelled"
+)
+
+// TraceEvent is one step of an execution timeline.
+type TraceEvent struct {
+	Type       string    `json:"type"`                 // Event type, e.g. "tool_start" or "llm_end"
+	Time       time.Time `json:"time"`                 // When the event occurred
+	Tool       string    `json:"tool,omitempty"`       // Tool the event refers to
+	Content    string    `json:"content,omitempty"`    // Tool input or output, LLM response, or agent reasoning (truncated)
+	Error      string    `json:"error,omitempty"`      // Error message of failed steps
+	DurationMs int64     `json:"durationMs,omitempty"` // Duration of the finished LLM or tool call
+}
+
+// ExecutionTrace is the timeline of one agent execution. It is safe for concurrent use.
+type ExecutionTrace struct {
+	mu          sync.Mutex
+	executionID string
+	sessionID   string
+	owner       string // Owner of the execution's session, which limits who may read the timeline
+	tenant      string // Tenant the execution is accounted to
+	status      string
+	startedAt   time.Time
+	endedAt     time.Time
+	events      []TraceEvent
+	redactor    *Redactor // Masks secrets in event content and errors; nil records them unchanged
+}
+
+// ExecutionTraceView is the JSON representation of an execution timeline.
+type ExecutionTraceView struct {
+	ExecutionID string       `json:"executionId"`       // Execution the timeline belongs to
+	SessionID   string       `json:"sessionId"`         // Chat session of the execution
+	Status      string       `json:"status"`            // running, completed, failed, or cancelled
+	StartedAt   time.Time    `json:"startedAt"`         // When the execution started
+	EndedAt     *time.Time   `json:"endedAt,omitempty"` // When the execution ended
+	DurationMs  int64        `json:"durationMs"`        // Execution time so far
+	Events      []TraceEvent `json:"events"`            // Recorded events in order
+}
+
+// Record appends an event to the timeline, stamping it with the current time,
+// redacting secrets from its content and error, and truncating its content.
+// Events of finished executions are ignored.
+//
+// Parameters:
+//   - event: Event to record
+func (t *ExecutionTrace) Record(event TraceEvent) {
+	if t == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	event.Content = truncateAttribute(t.redactor.Redact(event.Content))
+	event.Error = t.redactor.Redact(event.Error)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status == ExecutionRunning {
+		t.events = append(t.events, event)
+	}
+}
+
+// Progress summarizes the execution so far for progress reports.
+//
+// Returns:
+//   - int: Agent iteration, counted by the LLM calls made
+//   - []string: Tools whose calls have started but not finished
+func (t *ExecutionTrace) Progress() (int, []string) {
+	if t == nil {
+		return 0, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	iteration := 0
+	running := make(map[string]int)
+	for _, event := range t.events {
+		switch event.Type {
+		case TraceEventLLMStart:
+			iteration++
+		case TraceEventToolStart:
+			running[event.Tool]++
+		case TraceEventToolEnd, TraceEventToolError:
+			running[event.Tool]--
+		}
+	}
+	var tools []string
+	for tool, count := range running {
+		if count > 0 {
+			tools = append(tools, tool)
+		}
+	}
+	sort.Strings(tools)
+	return iteration, tools
+}
+
+// Finish ends the timeline with the outcome of the execution.
+//
+// Parameters:
+//   - ctx: Execution context; the execution counts as cancelled when it was cancelled
+//   - err: Execution error, or nil when the execution completed
+func (t *ExecutionTrace) Finish(ctx context.Context, err error) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status != ExecutionRunning {
+		return
+	}
+	t.endedAt = time.Now()
+	switch {
+	case err == nil:
+		t.status = ExecutionCompleted
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		t.status = ExecutionCancelled
+	default:
+		t.status = ExecutionFailed
+		t.events = append(t.events, TraceEvent{Type: TraceEventError, Time: t.endedAt, Error: err.Error()})
+	}
+}
+
+// view returns a snapshot of the timeline.
+func (t *ExecutionTrace) view() ExecutionTraceView {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	view := ExecutionTraceView{
+		ExecutionID: t.executionID,
+		SessionID:   t.sessionID,
+		Status:      t.status,
+		StartedAt:   t.startedAt,
+		DurationMs:  time.Since(t.startedAt).Milliseconds(),
+		Events:      append([]TraceEvent{}, t.events...),
+	}
+	if !t.endedAt.IsZero() {
+		endedAt := t.endedAt
+		view.EndedAt = &endedAt
+		view.DurationMs = endedAt.Sub(t.startedAt).Milliseconds()
+	}
+	return view
+}
+
+// ExecutionTraceStore keeps the timelines of the most recent executions.
+type ExecutionTraceStore struct {
+	mu       sync.RWMutex
+	traces   map[string]*ExecutionTrace // Map of execution ID to timeline
+	order    []string                   // Execution IDs from oldest to newest, for eviction
+	redactor *Redactor                  // Masks secrets in recorded events; nil records them unchanged
+}
+
+// NewExecutionTraceStore creates an empty timeline store.
+//
+// Parameters:
+//   - redactor: Redactor masking secrets in recorded events, or nil
+//
+// Returns:
+//   - *ExecutionTraceStore: Store ready for use
+func NewExecutionTraceStore(redactor *Redactor) *ExecutionTraceStore {
+	return &ExecutionTraceStore{traces: make(map[string]*ExecutionTrace), redactor: redactor}
+}
+
+// Start creates the timeline of a new execution, evicting the oldest timeline
+// when the store is full, and attaches it to the execution context.
+//
+// Parameters:
+//   - ctx: Execution context
+//   - info: Execution the timeline belongs to
+//
+// Returns:
+//   - context.Context: Derived context carrying the timeline
+//   - *ExecutionTrace: The new timeline, to be finished with Finish
+func (s *ExecutionTraceStore) Start(ctx context.Context, info ExecutionInfo) (context.Context, *ExecutionTrace) {
+	trace := &ExecutionTrace{
+		executionID: info.ExecutionID,
+		sessionID:   info.SessionID,
+		owner:       info.Owner,
+		tenant:      info.Tenant,
+		status:      ExecutionRunning,
+		startedAt:   time.Now(),
+		redactor:    s.redactor,
+	}
+
+	s.mu.Lock()
+	if _, exists := s.traces[info.ExecutionID]; !exists {
+		s.order = append(s.order, info.ExecutionID)
+	}
+	s.traces[info.ExecutionID] = trace
+	for len(s.order) > maxExecutionTraces {
+		delete(s.traces, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.mu.Unlock()
+
+	return context.WithValue(ctx, executionTraceKey{}, trace), trace
+}
+
+// Get returns the timeline of an execution.
+//
+// Parameters:
+//   - executionID: Execution to look up
+//
+// Returns:
+//   - *ExecutionTrace: The timeline
+//   - bool: Whether the execution is known
+func (s *ExecutionTraceStore) Get(executionID string) (*ExecutionTrace, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	trace, ok := s.traces[executionID]
+	return trace, ok
+}
+
+// executionTraceKey is the unexported context key type for execution timelines
+type executionTraceKey struct{}
+
+// ExecutionTraceFromContext returns the timeline attached to an execution context.
+// Recording on the nil timeline of contexts without one is a no-op.
+//
+// Parameters:
+//   - ctx: Context to inspect
+//
+// Returns:
+//   - *ExecutionTrace: The attached timeline, or nil
+func ExecutionTraceFromContext(ctx context.Context) *ExecutionTrace {
+	trace, _ := ctx.Value(executionTraceKey{}).(*ExecutionTrace)
+	return trace
+}
+
+// handleExecutionTrace handles GET /executions/:id/trace requests by returning the
+// timeline of a running or recently finished execution.
+func (s *Server) handleExecutionTrace(c echo.Context) error {
+	executionID := c.Param("id")
+	requestLogger := s.logger.WithFields(logrus.Fields{
+		"endpoint":    "/executions/:id/trace",
+		"method":      "GET",
+		"clientIP":    c.RealIP(),
+		"executionID": executionID,
+	})
+
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+
+	trace, ok := s.executionTraces.Get(executionID)
+	if !ok {
+		requestLogger.Debug("Execution trace not found")
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Execution not found"})
+	}
+	if !caller.mayAccess(trace.owner, trace.tenant) {
+		requestLogger.WithField("owner", trace.owner).Warn("Rejected access to the trace of another caller's execution")
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Execution belongs to another caller"})
+	}
+
+	requestLogger.Debug("Returning execution trace")
+	return c.JSON(http.StatusOK, trace.view())
+}
@@ -0,0 +1,86 @@
+/*
+Package core provides uniform tool execution timeouts for the Skynet Agent application.
+
+This file implements the TimeoutTool wrapper, which bounds every tool call with a
+context deadline taken from configuration. Tools execute their commands with
+exec.CommandContext, so the deadline terminates the underlying process and the
+wrapper reports a consistent timeout message back to the agent.
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// TimeoutTool wraps a tool and bounds each call with a configured timeout.
+type TimeoutTool struct {
+	tool    tools.Tool     // The underlying tool being bounded
+	timeout time.Duration  // Maximum duration of a single call
+	logger  *logrus.Logger // Structured logger for timeout reporting
+}
+
+// Name returns the wrapped tool's name.
+func (t *TimeoutTool) Name() string {
+	return t.tool.Name()
+}
+
+// Description returns the wrapped tool's description.
+func (t *TimeoutTool) Description() string {
+	return t.tool.Description()
+}
+
+// Call invokes the wrapped tool under a context deadline.
+// When the deadline is exceeded a timeout message is returned to the agent as the observation.
+//
+// Parameters:
+//   - ctx: Parent execution context
+//   - input: Tool input provided by the agent
+//
+// Returns:
+//   - string: The wrapped tool's output, or a timeout message
+//   - error: The wrapped tool's error
+func (t *TimeoutTool) Call(ctx context.Context, input string) (string, error) {
+	toolCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	output, err := t.tool.Call(toolCtx, input)
+
+	// Only report a tool timeout when this wrapper's deadline fired, not the parent request's
+	if toolCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		t.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"tool":    t.tool.Name(),
+			"timeout": t.timeout,
+		}).Warn("Tool execution timed out")
+		return fmt.Sprintf("Error: %s command timed out after %s", t.tool.Name(), t.timeout), nil
+	}
+
+	return output, err
+}
+
+// WrapToolsWithTimeouts bounds every tool with its configured timeout.
+//
+// Parameters:
+//   - toolsList: Tools to wrap
+//   - config: Configuration providing global and per-tool timeouts
+//   - logger: Logger for timeout reporting
+//
+// Returns:
+//   - []tools.Tool: Tools with timeouts applied
+func WrapToolsWithTimeouts(toolsList []tools.Tool, config *Config, logger *logrus.Logger) []tools.Tool {
+	wrapped := make([]tools.Tool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		wrapped = append(wrapped, &TimeoutTool{
+			tool:    tool,
+			timeout: config.TimeoutForTool(tool.Name()),
+			logger:  logger,
+		})
+	}
+	return wrapped
+}
+
+var _ tools.Tool = (*TimeoutTool)(nil)
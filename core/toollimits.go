@@ -0,0 +1,333 @@
+/*
+Package core provides resource limits for tool commands in the Skynet Agent application.
+
+Tool timeouts bound how long a command runs, not what it consumes meanwhile. The
+tool limits file sets CPU time, memory, process, and output limits for the
+commands of the tools, with a default and classes of tools that differ from it:
+
+	default:
+	  cpu_seconds: 60
+	  memory_mb: 1024
+	  processes: 256
+	  output_bytes: 10485760
+	classes:
+	  - name: build
+	    tools: [gotool, npm, pip]
+	    cpu_seconds: 600
+	    memory_mb: 4096
+
+A class inherits the default's limits it does not set. The LimitedTool wrapper
+attaches the limits of a tool's class to each call, and the tools run their
+commands under them (see tools/limits.go). With TOOL_CGROUP_PATH set, every
+class also gets a cgroup v2 directory below it, whose memory.max and pids.max
+cap the memory and processes of all of the class's running local commands
+together. Unlike the per-process rlimits, these apply to root and cannot be
+escaped by forking.
+
+The wrapper also attaches the scheduling priority set by TOOL_NICE and
+TOOL_IONICE_CLASS, which applies to the commands of every tool (see
+tools/priority.go).
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+	"gopkg.in/yaml.v3"
+
+	localtools "skynet/tools"
+)
+
+// defaultToolClass names the limits of tools without a class, and their cgroup
+const defaultToolClass = "default"
+
+// toolClassPattern matches valid tool class names
+var toolClassPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// toolLimitSpec is a set of limits in the tool limits file. Zero limits are unset.
+type toolLimitSpec struct {
+	CPUSeconds  int   `yaml:"cpu_seconds"`  // CPU time of each process
+	MemoryMB    int64 `yaml:"memory_mb"`    // Address space of each process, and memory of the class's cgroup
+	Processes   int   `yaml:"processes"`    // Processes of the command's user, and of the class's cgroup
+	OutputBytes int64 `yaml:"output_bytes"` // Output collected before a command is killed
+}
+
+// inherit fills the limits the spec does not set from another spec.
+func (spec toolLimitSpec) inherit(from toolLimitSpec) toolLimitSpec {
+	if spec.CPUSeconds == 0 {
+		spec.CPUSeconds = from.CPUSeconds
+	}
+	if spec.MemoryMB == 0 {
+		spec.MemoryMB = from.MemoryMB
+	}
+	if spec.Processes == 0 {
+		spec.Processes = from.Processes
+	}
+	if spec.OutputBytes == 0 {
+		spec.OutputBytes = from.OutputBytes
+	}
+	return spec
+}
+
+// validate reports a negative limit.
+func (spec toolLimitSpec) validate() error {
+	if spec.CPUSeconds < 0 || spec.MemoryMB < 0 || spec.Processes < 0 || spec.OutputBytes < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return nil
+}
+
+// toolLimitClass is a class of tools sharing limits.
+type toolLimitClass struct {
+	Name          string   `yaml:"name"`
+	Tools         []string `yaml:"tools"`
+	toolLimitSpec `yaml:",inline"`
+}
+
+// toolLimitsFile is the top-level structure of the tool limits file.
+type toolLimitsFile struct {
+	Default toolLimitSpec    `yaml:"default"`
+	Classes []toolLimitClass `yaml:"classes"`
+}
+
+// ToolLimits holds the resource limits of every tool.
+type ToolLimits struct {
+	byTool   map[string]localtools.ResourceLimits // Limits of the tools in a class, keyed by tool name
+	fallback localtools.ResourceLimits            // Limits of the tools without a class
+	cgroups  []*os.File                           // Open cgroup directories, closed by Close
+}
+
+// LoadToolLimits loads the tool limits file and creates the cgroups of its classes.
+// A missing file is not an error; tool commands then run without limits.
+//
+// Parameters:
+//   - path: YAML file declaring the limits; empty disables limits
+//   - cgroupPath: cgroup v2 directory the classes' cgroups are created in; empty disables cgroups
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *ToolLimits: Limits ready to be attached to tool calls
+//   - error: Any error reading or validating the file, or setting up the cgroups
+func LoadToolLimits(path, cgroupPath string, logger *logrus.Logger) (*ToolLimits, error) {
+	limits := &ToolLimits{byTool: make(map[string]localtools.ResourceLimits)}
+	if path == "" {
+		return limits, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return limits, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tool limits file: %w", err)
+	}
+
+	var file toolLimitsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode tool limits file: %w", err)
+	}
+	if err := file.Default.validate(); err != nil {
+		return nil, fmt.Errorf("default tool limits: %w", err)
+	}
+
+	if cgroupPath != "" {
+		if err := enableToolCgroups(cgroupPath); err != nil {
+			return nil, err
+		}
+	}
+
+	limits.fallback, err = limits.resolve(defaultToolClass, file.Default, cgroupPath)
+	if err != nil {
+		limits.Close()
+		return nil, err
+	}
+	logger.WithFields(toolLimitFields(defaultToolClass, file.Default)).Info("Tool limits loaded")
+
+	classes := map[string]bool{defaultToolClass: true}
+	for _, class := range file.Classes {
+		if !toolClassPattern.MatchString(class.Name) {
+			limits.Close()
+			return nil, fmt.Errorf("invalid tool class name %q: use lowercase letters, digits, '-', and '_'", class.Name)
+		}
+		if classes[class.Name] {
+			limits.Close()
+			return nil, fmt.Errorf("tool class %q is declared more than once", class.Name)
+		}
+		classes[class.Name] = true
+		if err := class.validate(); err != nil {
+			limits.Close()
+			return nil, fmt.Errorf("tool class %q: %w", class.Name, err)
+		}
+
+		spec := class.toolLimitSpec.inherit(file.Default)
+		resolved, err := limits.resolve(class.Name, spec, cgroupPath)
+		if err != nil {
+			limits.Close()
+			return nil, err
+		}
+		for _, tool := range class.Tools {
+			if _, exists := limits.byTool[tool]; exists {
+				limits.Close()
+				return nil, fmt.Errorf("tool %q belongs to more than one tool class", tool)
+			}
+			limits.byTool[tool] = resolved
+		}
+
+		fields := toolLimitFields(class.Name, spec)
+		fields["tools"] = class.Tools
+		logger.WithFields(fields).Info("Tool limits loaded")
+	}
+	return limits, nil
+}
+
+// resolve converts a spec into the limits attached to tool calls, creating the
+// class's cgroup when cgroupPath is set.
+func (l *ToolLimits) resolve(class string, spec toolLimitSpec, cgroupPath string) (localtools.ResourceLimits, error) {
+	resolved := localtools.ResourceLimits{
+		CPUSeconds:     spec.CPUSeconds,
+		MemoryBytes:    spec.MemoryMB << 20,
+		MaxProcesses:   spec.Processes,
+		MaxOutputBytes: spec.OutputBytes,
+	}
+	if cgroupPath == "" {
+		return resolved, nil
+	}
+
+	dir := filepath.Join(cgroupPath, class)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return resolved, fmt.Errorf("failed to create cgroup of tool class %q: %w", class, err)
+	}
+	settings := map[string]string{
+		"memory.max": cgroupLimit(spec.MemoryMB << 20),
+		"pids.max":   cgroupLimit(int64(spec.Processes)),
+	}
+	for name, value := range settings {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0644); err != nil {
+			return resolved, fmt.Errorf("failed to set %s of tool class %q: %w", name, class, err)
+		}
+	}
+
+	cgroup, err := os.Open(dir)
+	if err != nil {
+		return resolved, fmt.Errorf("failed to open cgroup of tool class %q: %w", class, err)
+	}
+	l.cgroups = append(l.cgroups, cgroup)
+	resolved.Cgroup = cgroup
+	return resolved, nil
+}
+
+// For returns the limits of a tool's commands.
+func (l *ToolLimits) For(name string) localtools.ResourceLimits {
+	if limits, exists := l.byTool[name]; exists {
+		return limits
+	}
+	return l.fallback
+}
+
+// Close releases the cgroup directories.
+func (l *ToolLimits) Close() {
+	for _, cgroup := range l.cgroups {
+		cgroup.Close()
+	}
+	l.cgroups = nil
+}
+
+// enableToolCgroups creates the parent cgroup of the tool classes and delegates
+// the memory and pids controllers to the classes' cgroups.
+func enableToolCgroups(cgroupPath string) error {
+	if err := os.MkdirAll(cgroupPath, 0755); err != nil {
+		return fmt.Errorf("failed to create tool cgroup %s: %w", cgroupPath, err)
+	}
+	control := filepath.Join(cgroupPath, "cgroup.subtree_control")
+	if err := os.WriteFile(control, []byte("+memory +pids"), 0644); err != nil {
+		return fmt.Errorf("failed to enable the memory and pids controllers in %s; the parent cgroup must delegate them: %w", cgroupPath, err)
+	}
+	return nil
+}
+
+// cgroupLimit formats a cgroup limit; zero is unlimited.
+func cgroupLimit(value int64) string {
+	if value <= 0 {
+		return "max"
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+// toolLimitFields returns the log fields describing a class's limits.
+func toolLimitFields(class string, spec toolLimitSpec) logrus.Fields {
+	return logrus.Fields{
+		"class":       class,
+		"cpuSeconds":  spec.CPUSeconds,
+		"memoryMB":    spec.MemoryMB,
+		"processes":   spec.Processes,
+		"outputBytes": spec.OutputBytes,
+	}
+}
+
+// LimitedTool wraps a tool and runs its commands under the limits of its class, at
+// the configured scheduling priority.
+type LimitedTool struct {
+	tool     tools.Tool                    // The underlying tool
+	limits   localtools.ResourceLimits     // Limits of the tool's commands
+	priority localtools.SchedulingPriority // CPU and I/O priority of the tool's commands
+}
+
+// Name returns the wrapped tool's name.
+func (t *LimitedTool) Name() string {
+	return t.tool.Name()
+}
+
+// Description returns the wrapped tool's description.
+func (t *LimitedTool) Description() string {
+	return t.tool.Description()
+}
+
+// Call invokes the wrapped tool with its limits and priority attached to the context.
+//
+// Parameters:
+//   - ctx: Parent execution context
+//   - input: Tool input provided by the agent
+//
+// Returns:
+//   - string: The wrapped tool's output
+//   - error: The wrapped tool's error
+func (t *LimitedTool) Call(ctx context.Context, input string) (string, error) {
+	ctx = localtools.WithResourceLimits(ctx, t.limits)
+	ctx = localtools.WithSchedulingPriority(ctx, t.priority)
+	return t.tool.Call(ctx, input)
+}
+
+// WrapToolsWithLimits attaches the resource limits of each tool's class and the
+// configured scheduling priority to its calls. Tools with neither are returned unwrapped.
+//
+// Parameters:
+//   - toolsList: Tools to wrap
+//   - limits: Limits of the tool classes
+//   - config: Configuration providing the scheduling priority of tool commands
+//
+// Returns:
+//   - []tools.Tool: Tools with resource limits and priority applied
+func WrapToolsWithLimits(toolsList []tools.Tool, limits *ToolLimits, config *Config) []tools.Tool {
+	priority := localtools.SchedulingPriority{
+		Nice:    config.ToolNice,
+		IOClass: config.ToolIOClass,
+		IOLevel: config.ToolIOLevel,
+	}
+	wrapped := make([]tools.Tool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		toolLimits := limits.For(tool.Name())
+		if toolLimits == (localtools.ResourceLimits{}) && priority.Nice == 0 && priority.IOClass == "" {
+			wrapped = append(wrapped, tool)
+			continue
+		}
+		wrapped = append(wrapped, &LimitedTool{tool: tool, limits: toolLimits, priority: priority})
+	}
+	return wrapped
+}
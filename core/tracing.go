@@ -0,0 +1,217 @@
+/*
+Package core provides OpenTelemetry tracing for the Skynet Agent application.
+
+Skynet is instrumented with the OpenTelemetry API: every HTTP request gets a root
+span (continuing a W3C traceparent sent by the client), each agent iteration,
+LLM call, and tool execution a child span. When OTEL_EXPORTER_OTLP_ENDPOINT is
+set, the OpenTelemetry SDK records the spans, and its batch span processor
+exports them with the OTLP/HTTP exporter to <endpoint>/v1/traces of a collector,
+Jaeger, or Tempo; otherwise the no-op provider keeps the instrumentation free.
+
+The batch processor exports from its own goroutine and drops spans when its
+queue is full, and export failures are only logged, so tracing problems can
+never slow down or fail a chat request.
+*/
+package core
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans of Skynet; it follows the provider installed by InitTracing
+var tracer = otel.Tracer("skynet")
+
+// maxSpanAttributeLength bounds string attributes such as tool inputs
+const maxSpanAttributeLength = 512
+
+const (
+	spanBatchSize     = 256              // Spans sent per export request
+	spanBufferSize    = 4096             // Finished spans queued before new ones are dropped
+	spanFlushInterval = 5 * time.Second  // Maximum delay before finished spans are exported
+	spanExportTimeout = 10 * time.Second // Timeout of one export request
+)
+
+// InitTracing installs the SDK tracer provider exporting to the configured OTLP
+// endpoint, and the W3C trace context propagator.
+//
+// Parameters:
+//   - config: Configuration providing the OTLP endpoint, headers, and service name
+//   - logger: Logger for export failures
+//
+// Returns:
+//   - func(context.Context): Flushes the queued spans and stops the provider; a no-op when tracing is disabled
+func InitTracing(config *Config, logger *logrus.Logger) func(context.Context) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	if config.OTelEndpoint == "" {
+		return func(context.Context) {}
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(strings.TrimRight(config.OTelEndpoint, "/")+"/v1/traces"),
+		otlptracehttp.WithHeaders(config.OTelHeaders),
+		otlptracehttp.WithTimeout(spanExportTimeout),
+	)
+	if err != nil {
+		logger.WithError(err).WithField("endpoint", config.OTelEndpoint).Error("Failed to create trace exporter, tracing disabled")
+		return func(context.Context) {}
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter,
+			sdktrace.WithMaxExportBatchSize(spanBatchSize),
+			sdktrace.WithMaxQueueSize(spanBufferSize),
+			sdktrace.WithBatchTimeout(spanFlushInterval),
+			sdktrace.WithExportTimeout(spanExportTimeout),
+		),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", config.OTelServiceName),
+			attribute.String("service.version", Version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.WithError(err).Warn("Failed to export trace spans")
+	}))
+
+	logger.WithFields(logrus.Fields{
+		"endpoint": config.OTelEndpoint,
+		"service":  config.OTelServiceName,
+	}).Info("OpenTelemetry tracing enabled")
+	return func(ctx context.Context) {
+		if err := provider.Shutdown(ctx); err != nil {
+			logger.WithError(err).Warn("Failed to flush trace spans")
+		}
+	}
+}
+
+// truncateAttribute shortens a string span attribute to maxSpanAttributeLength bytes.
+func truncateAttribute(value string) string {
+	if len(value) <= maxSpanAttributeLength {
+		return value
+	}
+	return value[:maxSpanAttributeLength] + "...[truncated]"
+}
+
+// tracingMiddleware starts the root span of every HTTP request, continuing the
+// trace of the caller when a traceparent header is present.
+func (s *Server) tracingMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		request := c.Request()
+		ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+		ctx, span := tracer.Start(ctx, request.Method+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", request.Method),
+				attribute.String("http.route", c.Path()),
+				attribute.String("url.path", request.URL.Path),
+				attribute.String("client.address", c.RealIP()),
+				attribute.String("http.request.id", localtools.RequestIDFromContext(request.Context())),
+			),
+		)
+		defer span.End()
+		c.SetRequest(request.WithContext(ctx))
+
+		err := next(c)
+		if err != nil {
+			span.RecordError(err)
+			c.Error(err)
+		}
+
+		status := c.Response().Status
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		return nil
+	}
+}
+
+// TracingTool wraps a tool and records each call as a span and in the execution timeline.
+type TracingTool struct {
+	tool tools.Tool // The underlying tool being traced
+}
+
+// Name returns the wrapped tool's name.
+func (t *TracingTool) Name() string {
+	return t.tool.Name()
+}
+
+// Description returns the wrapped tool's description.
+func (t *TracingTool) Description() string {
+	return t.tool.Description()
+}
+
+// Call invokes the wrapped tool inside a span carrying the tool name and truncated input.
+// Outputs reporting an error mark the span as failed.
+//
+// Parameters:
+//   - ctx: Execution context carrying the parent span
+//   - input: Tool input provided by the agent
+//
+// Returns:
+//   - string: The wrapped tool's output
+//   - error: The wrapped tool's error
+func (t *TracingTool) Call(ctx context.Context, input string) (string, error) {
+	ctx, span := tracer.Start(ctx, "tool "+t.tool.Name(), trace.WithAttributes(
+		attribute.String("tool.name", t.tool.Name()),
+		attribute.String("tool.input", truncateAttribute(input)),
+	))
+	defer span.End()
+
+	executionTrace := ExecutionTraceFromContext(ctx)
+	executionTrace.Record(TraceEvent{Type: TraceEventToolStart, Tool: t.tool.Name(), Content: input})
+	startTime := time.Now()
+
+	output, err := t.tool.Call(ctx, input)
+	span.SetAttributes(attribute.Int("tool.output_length", len(output)))
+	event := TraceEvent{Type: TraceEventToolEnd, Tool: t.tool.Name(), Content: output, DurationMs: time.Since(startTime).Milliseconds()}
+	failure := toolOutputFailure(output)
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		event.Type, event.Error = TraceEventToolError, err.Error()
+	case failure != "":
+		span.SetStatus(codes.Error, failure)
+		event.Type, event.Error = TraceEventToolError, failure
+	}
+	executionTrace.Record(event)
+	if handler, ok := toolOutputHandlerFromContext(ctx); ok {
+		handler.HandleToolOutput(ctx, t.tool.Name(), input, output, err)
+	}
+	return output, err
+}
+
+// WrapToolsWithTracing records every tool call as a span of the execution's trace.
+//
+// Parameters:
+//   - toolsList: Tools to wrap
+//
+// Returns:
+//   - []tools.Tool: Tools with tracing applied
+func WrapToolsWithTracing(toolsList []tools.Tool) []tools.Tool {
+	wrapped := make([]tools.Tool, 0, len(toolsList))
+	for _, tool := range toolsList {
+		wrapped = append(wrapped, &TracingTool{tool: tool})
+	}
+	return wrapped
+}
+
+var _ tools.Tool = (*TracingTool)(nil)
@@ -0,0 +1,217 @@
+/*
+Package core provides audio input for the Skynet Agent application.
+
+POST /chat/audio accepts a recorded question as a multipart form, transcribes it,
+and runs the transcript through the agent like a POST /chat message. The answer
+is returned together with the transcript, so clients can show what was heard.
+
+TRANSCRIPTION_BACKEND selects how audio is transcribed:
+
+	whisper  Locally with the whisper.cpp command-line program and WHISPER_MODEL
+	gemini   By the configured Gemini model, which accepts audio input
+
+Clips are limited to 25 MB. whisper.cpp reads WAV, MP3, FLAC, and Ogg files;
+Gemini also accepts AAC and other common formats.
+*/
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Supported values of the TRANSCRIPTION_BACKEND setting
+const (
+	TranscriptionWhisper = "whisper" // Local whisper.cpp
+	TranscriptionGemini  = "gemini"  // The Gemini provider's audio understanding
+)
+
+// maxAudioBytes limits the size of an uploaded clip
+const maxAudioBytes = 25 << 20
+
+// transcriptionTimeout bounds the transcription of one clip
+const transcriptionTimeout = 2 * time.Minute
+
+// transcriptionPrompt asks the model for a plain transcript
+const transcriptionPrompt = "Transcribe this audio recording verbatim. Reply with the transcript only, without comments, labels, or timestamps. If nothing is spoken, reply with an empty message."
+
+// AudioChatResponse is the response of POST /chat/audio: the chat response and what was transcribed.
+type AudioChatResponse struct {
+	Transcript string `json:"transcript"` // Text transcribed from the audio and sent to the agent
+	ChatResponse
+}
+
+// transcribe converts speech in an audio clip to text with the configured backend.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - audio: Audio clip
+//   - filename: Uploaded file name, whose extension tells whisper.cpp the format
+//   - mimeType: Audio type such as "audio/wav"
+//
+// Returns:
+//   - string: Transcript, trimmed
+//   - error: Backend failure
+func (s *Server) transcribe(ctx context.Context, audio []byte, filename, mimeType string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, transcriptionTimeout)
+	defer cancel()
+
+	config := s.currentConfig()
+	switch config.TranscriptionBackend {
+	case TranscriptionWhisper:
+		return transcribeWhisper(ctx, config, audio, filename)
+	case TranscriptionGemini:
+		response, err := s.llm.GenerateContent(ctx, []llms.MessageContent{{
+			Role:  llms.ChatMessageTypeHuman,
+			Parts: []llms.ContentPart{llms.TextPart(transcriptionPrompt), llms.BinaryPart(mimeType, audio)},
+		}})
+		if err != nil {
+			return "", fmt.Errorf("gemini transcription failed: %w", err)
+		}
+		if len(response.Choices) == 0 {
+			return "", nil
+		}
+		return strings.TrimSpace(response.Choices[0].Content), nil
+	default:
+		return "", fmt.Errorf("audio transcription is not configured")
+	}
+}
+
+// transcribeWhisper runs whisper.cpp on the clip, written to a temporary file.
+func transcribeWhisper(ctx context.Context, config *Config, audio []byte, filename string) (string, error) {
+	file, err := os.CreateTemp("", "skynet-audio-*"+filepath.Ext(filename))
+	if err != nil {
+		return "", fmt.Errorf("failed to store audio: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(audio); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to store audio: %w", err)
+	}
+	file.Close()
+
+	// -nt omits timestamps and -np the progress output, leaving only the text on stdout
+	cmd := exec.CommandContext(ctx, config.WhisperBinary, "-m", config.WhisperModel, "-f", file.Name(), "-nt", "-np")
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("whisper failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("whisper failed: %w", err)
+	}
+	return strings.Join(strings.Fields(string(output)), " "), nil
+}
+
+// handleAudioChat handles POST /chat/audio requests. The multipart form carries the
+// clip as "audio" and optionally "sessionId", "target", and "speech" as in POST /chat.
+func (s *Server) handleAudioChat(c echo.Context) error {
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"requestId": localtools.RequestIDFromContext(c.Request().Context()),
+		"endpoint":  "/chat/audio",
+		"method":    "POST",
+		"clientIP":  c.RealIP(),
+	})
+
+	requestLogger.Info("Received audio chat request")
+
+	if s.currentConfig().TranscriptionBackend == "" {
+		return c.JSON(http.StatusNotImplemented, map[string]string{"error": "Audio input is disabled; set TRANSCRIPTION_BACKEND"})
+	}
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+	if limited, err := s.rejectRateLimited(c, caller, requestLogger); limited {
+		return err
+	}
+	if exceeded, err := s.rejectQuotaExceeded(c, caller, requestLogger); exceeded {
+		return err
+	}
+
+	header, err := c.FormFile("audio")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "An audio file is required in the \"audio\" form field"})
+	}
+	if header.Size > maxAudioBytes {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": fmt.Sprintf("Audio clips may be at most %d MB", maxAudioBytes>>20)})
+	}
+	file, err := header.Open()
+	if err != nil {
+		requestLogger.WithError(err).Error("Failed to open uploaded audio")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid audio upload"})
+	}
+	audio, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		requestLogger.WithError(err).Error("Failed to read uploaded audio")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid audio upload"})
+	}
+
+	mimeType := header.Header.Get("Content-Type")
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = mime.TypeByExtension(filepath.Ext(header.Filename))
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(audio)
+	}
+
+	// Tool commands run on the requested target
+	target, err := s.resolveTarget(c.FormValue("target"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	requestLogger = requestLogger.WithField("target", target.String())
+
+	startTime := time.Now()
+	transcript, err := s.transcribe(c.Request().Context(), audio, header.Filename, mimeType)
+	if err != nil {
+		requestLogger.WithError(err).Error("Audio transcription failed")
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	}
+	requestLogger.WithFields(logrus.Fields{
+		"audioBytes":       len(audio),
+		"transcriptLength": len(transcript),
+		"duration":         time.Since(startTime),
+	}).Info("Audio transcribed")
+	if transcript == "" {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "No speech was recognized in the audio"})
+	}
+
+	// Wait for an execution slot; the client disconnecting leaves the queue
+	ticket, err := s.queue.Enqueue()
+	if err != nil {
+		return s.rejectQueueFull(c, requestLogger)
+	}
+	defer ticket.Release()
+	if err := ticket.Wait(c.Request().Context(), nil); err != nil {
+		requestLogger.WithError(err).Info("Client left the request queue")
+		return nil
+	}
+
+	// The execution outlives a disconnected client but stays part of the request's trace
+	ctx := localtools.WithTarget(context.WithoutCancel(c.Request().Context()), target)
+	ctx = s.withCaller(ctx, caller)
+	session := s.memoryStore.GetOrCreateOwnedSession(c.FormValue("sessionId"), caller.owner, caller.tenant)
+	if !caller.mayUse(session) {
+		return s.rejectForeignSession(c, session, requestLogger)
+	}
+	response, err := s.chat(ctx, session.ID, transcript, c.RealIP(), requestLogger)
+	if err == nil && c.FormValue("speech") == "true" {
+		response.SpeechURL = s.speak(ctx, session, response.Response, requestLogger)
+	}
+	return c.JSON(http.StatusOK, AudioChatResponse{Transcript: transcript, ChatResponse: response})
+}
@@ -7,32 +7,54 @@ communication, and execution control. These types serve as the contract between
 the client and server, ensuring consistent data exchange formats.
 
 Key type categories:
-- Chat API types (ChatRequest, ChatResponse)
+- Chat API types (ChatRequest, Attachment, FileAttachment, ChatResponse)
 - Real-time streaming types (StreamMessage)
 - Execution control types (StopRequest, StopResponse)
+- Tool API types (ToolInfo, ToolInvokeRequest, ToolInvokeResponse)
 */
 package core
 
+import "encoding/json"
+
 // ChatRequest represents incoming chat requests from clients.
 // This is the primary input structure for chat interactions with the agent.
 type ChatRequest struct {
-	Message   string `json:"message"`             // The user's message/query to the agent
-	SessionID string `json:"sessionId,omitempty"` // Optional session ID for conversation memory continuity
-	Debug     bool   `json:"debug,omitempty"`     // Enable debug mode for internal chain streaming and detailed logs
+	Message     string           `json:"message"`               // The user's message/query to the agent
+	SessionID   string           `json:"sessionId,omitempty"`   // Optional session ID for conversation memory continuity
+	Verbosity   Verbosity        `json:"verbosity,omitempty"`   // Stream detail: "final", "progress", "tools", or "debug"; defaults by DEBUG_MODE
+	Target      string           `json:"target,omitempty"`      // Where tool commands run: "local" (default), "ssh:<host>", or "docker:<container>"
+	Attachments []Attachment     `json:"attachments,omitempty"` // Images shown to the model along with the message
+	Files       []FileAttachment `json:"files,omitempty"`       // Text files saved in the session workspace for the agent to read
+	Speech      bool             `json:"speech,omitempty"`      // Also synthesize the answer to audio (requires TTS_BACKEND)
+}
+
+// Attachment is an image sent with a chat message, either inline or by URL.
+type Attachment struct {
+	Data     string `json:"data,omitempty"`     // Base64-encoded image, optionally as a "data:image/png;base64,..." URL
+	URL      string `json:"url,omitempty"`      // HTTP(S) URL the server downloads the image from
+	MimeType string `json:"mimeType,omitempty"` // Image type such as "image/png"; detected from the content when empty
+}
+
+// FileAttachment is a small text file sent with a chat message, such as a config snippet or log excerpt.
+type FileAttachment struct {
+	Name    string `json:"name"`    // File name; directories are stripped
+	Content string `json:"content"` // File content as UTF-8 text
 }
 
 // ChatResponse represents the final response returned by the chat API.
 // This contains the agent's response along with session management information.
 type ChatResponse struct {
-	Response  string `json:"response"`  // The agent's final response message
-	SessionID string `json:"sessionId"` // Session ID returned to client for maintaining conversation context
+	Response    string `json:"response"`              // The agent's final response message
+	SessionID   string `json:"sessionId"`             // Session ID returned to client for maintaining conversation context
+	ExecutionID string `json:"executionId,omitempty"` // Execution whose timeline GET /executions/:id/trace returns
+	SpeechURL   string `json:"speechUrl,omitempty"`   // Address of the spoken answer when speech was requested
 }
 
 // StreamMessage represents real-time streaming messages sent to clients via WebSocket.
 // This enables live updates during agent execution, including tool usage, thinking processes,
 // and intermediate results. The Type field determines how the client should handle each message.
 type StreamMessage struct {
-	Type      string                 `json:"type"`                // Message type: "thinking", "tool", "response", "error", "debug", "chain_start", "chain_step", "llm_call", "agent_action", "session", "execution_started", "stopped"
+	Type      string                 `json:"type"`                // Message type: "thinking", "tool", "response", "error", "debug", "chain_start", "chain_step", "llm_call", "agent_action", "session", "queued", "execution_started", "stopped", "step_start", "step_result", "workflow_completed", "workflow_failed", "speech", "progress"
 	Content   string                 `json:"content"`             // Main message content or description
 	Tool      string                 `json:"tool,omitempty"`      // Name of the tool being executed (when Type is "tool")
 	Complete  bool                   `json:"complete"`            // Whether this message represents completion of an operation
@@ -44,8 +66,11 @@ type StreamMessage struct {
 
 // StopRequest represents a client request to stop an ongoing agent execution.
 // This allows users to cancel long-running operations or infinite loops.
+// Exactly one of the identifiers selects what to stop.
 type StopRequest struct {
-	ExecutionID string `json:"executionId"` // Unique identifier of the execution to stop
+	ExecutionID string `json:"executionId,omitempty"` // Unique identifier of the execution to stop
+	SessionID   string `json:"sessionId,omitempty"`   // Stop every running execution of this session
+	RequestID   string `json:"requestId,omitempty"`   // Stop every execution started by this request (its X-Request-ID)
 }
 
 // StopResponse represents the server's response to a stop request.
@@ -54,4 +79,40 @@ type StopResponse struct {
 	Success bool   `json:"success"` // Whether the stop request was processed successfully
 	Message string `json:"message"` // Human-readable message describing the result
 	Stopped bool   `json:"stopped"` // Whether the execution was actually stopped (may already be completed)
+
+	Executions []string `json:"executions,omitempty"` // IDs of the stopped executions
+}
+
+// SessionEnvRequest represents a change to the environment variables of a session.
+// The variables are injected into the commands that tools run for the session.
+type SessionEnvRequest struct {
+	Set   map[string]string `json:"set,omitempty"`   // Variables to set or replace
+	Unset []string          `json:"unset,omitempty"` // Variables to remove
+}
+
+// SessionApprovalRequest is the user's decision on the operations a session's
+// tools are waiting to have approved, such as certificate issuance.
+type SessionApprovalRequest struct {
+	Deny bool `json:"deny,omitempty"` // Deny the pending operations instead of approving them
+}
+
+// ToolInfo describes a tool available to the agent, as listed by GET /tools.
+type ToolInfo struct {
+	Name        string          `json:"name"`             // Tool identifier used in Action lines
+	Description string          `json:"description"`      // Usage description shown to the agent
+	Source      string          `json:"source"`           // Where the tool comes from: builtin, command, plugin, mcp, or custom
+	Schema      json.RawMessage `json:"schema,omitempty"` // JSON schema of structured input, when the tool publishes one
+}
+
+// ToolInvokeRequest represents a request to execute a tool directly, bypassing the LLM.
+type ToolInvokeRequest struct {
+	Input string `json:"input"` // Action Input passed to the tool verbatim
+}
+
+// ToolInvokeResponse represents the result of a direct tool invocation.
+type ToolInvokeResponse struct {
+	Tool     string `json:"tool"`            // Name of the invoked tool
+	Output   string `json:"output"`          // Observation the agent would have received
+	Error    string `json:"error,omitempty"` // Error returned by the tool, if any
+	Duration string `json:"duration"`        // Wall-clock execution time
 }
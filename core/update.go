@@ -0,0 +1,256 @@
+/*
+Package core provides the self-update mechanism for the Skynet Agent application.
+
+Single-binary deployments can check a release endpoint for newer builds and
+replace themselves in place. The release endpoint returns a JSON manifest:
+
+	{"version": "1.3.0", "url": "https://example.com/skynet-linux-amd64", "sha256": "<hex>", "signature": "<base64>"}
+
+The signature is an Ed25519 signature over the release statement, which binds the
+version to the SHA-256 digest of the binary:
+
+	skynet-release
+	version=1.3.0
+	sha256=<hex>
+
+with each line ending in a newline. Both the statement and the downloaded binary
+are verified against the configured public key before anything on disk is
+touched, so an older signed binary cannot be passed off as a newer version. Only
+a release whose semantic version is strictly greater than the running one is
+installed; development builds without a semantic version are never replaced.
+After a successful update the server shuts down gracefully and re-executes the
+new binary.
+*/
+package core
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// maxUpdateSize bounds the size of a downloaded release binary.
+const maxUpdateSize = 512 << 20 // 512 MiB
+
+// ReleaseInfo describes the latest release published at the update endpoint.
+type ReleaseInfo struct {
+	Version   string `json:"version"`         // Version of the published release
+	URL       string `json:"url"`             // Download URL of the release binary
+	SHA256    string `json:"sha256"`          // Hex SHA-256 digest of the release binary
+	Signature string `json:"signature"`       // Base64 Ed25519 signature over the release statement
+	Notes     string `json:"notes,omitempty"` // Optional human-readable release notes
+}
+
+// Updater checks for, verifies, and installs new releases of the running binary.
+type Updater struct {
+	config *Config        // Configuration providing the endpoint and public key
+	client *http.Client   // HTTP client used for manifest and binary downloads
+	logger *logrus.Logger // Structured logger for operational monitoring
+}
+
+// NewUpdater creates an updater for the configured release endpoint.
+//
+// Parameters:
+//   - config: Configuration providing the update URL and public key
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - *Updater: Updater ready to check for releases
+func NewUpdater(config *Config, logger *logrus.Logger) *Updater {
+	return &Updater{
+		config: config,
+		client: &http.Client{Timeout: 5 * time.Minute},
+		logger: logger,
+	}
+}
+
+// statement returns the bytes the release signature covers, binding the version
+// to the digest of the binary.
+func (r *ReleaseInfo) statement() []byte {
+	return []byte(fmt.Sprintf("skynet-release\nversion=%s\nsha256=%s\n", r.Version, r.SHA256))
+}
+
+// isNewerRelease reports whether version is a semantic version strictly greater
+// than the running one. A running build without a semantic version, such as a
+// development build, has no newer release.
+func isNewerRelease(version string) (bool, error) {
+	latest, err := semver.StrictNewVersion(version)
+	if err != nil {
+		return false, fmt.Errorf("release version %q is not a semantic version: %w", version, err)
+	}
+	current, err := semver.NewVersion(Version)
+	if err != nil {
+		return false, nil
+	}
+	return latest.GreaterThan(current), nil
+}
+
+// Check fetches the release manifest and reports whether it is newer than the running version.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//
+// Returns:
+//   - *ReleaseInfo: The published release
+//   - bool: Whether the published version is strictly greater than the running version
+//   - error: Any error fetching or decoding the manifest
+func (u *Updater) Check(ctx context.Context) (*ReleaseInfo, bool, error) {
+	if u.config.UpdateURL == "" {
+		return nil, false, fmt.Errorf("update URL is not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.config.UpdateURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create update request: %w", err)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch release manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("release endpoint returned status %d", resp.StatusCode)
+	}
+
+	var release ReleaseInfo
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&release); err != nil {
+		return nil, false, fmt.Errorf("failed to decode release manifest: %w", err)
+	}
+	if release.Version == "" || release.URL == "" || release.SHA256 == "" {
+		return nil, false, fmt.Errorf("release manifest is missing version, url, or sha256")
+	}
+
+	newer, err := isNewerRelease(release.Version)
+	if err != nil {
+		return nil, false, err
+	}
+	return &release, newer, nil
+}
+
+// Apply verifies the release statement, downloads the release binary, checks it
+// against the signed digest, and atomically replaces the running executable.
+// Releases that are not strictly newer than the running version are refused. The
+// process must be restarted afterwards.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - release: Release to install, as returned by Check
+//
+// Returns:
+//   - error: Any error downloading, verifying, or installing the binary
+func (u *Updater) Apply(ctx context.Context, release *ReleaseInfo) error {
+	publicKey, err := base64.StdEncoding.DecodeString(u.config.UpdatePublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("update public key is missing or invalid")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(release.Signature)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("release signature is missing or invalid")
+	}
+
+	digest, err := hex.DecodeString(release.SHA256)
+	if err != nil || len(digest) != sha256.Size {
+		return fmt.Errorf("release digest is missing or invalid")
+	}
+
+	// Verify the signed version before downloading so a downgrade is refused up front
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), release.statement(), signature) {
+		return fmt.Errorf("release signature verification failed")
+	}
+	newer, err := isNewerRelease(release.Version)
+	if err != nil {
+		return err
+	}
+	if !newer {
+		return fmt.Errorf("release %s is not newer than the running version %s", release.Version, Version)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, release.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create download request: %w", err)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("release download returned status %d", resp.StatusCode)
+	}
+
+	binary, err := io.ReadAll(io.LimitReader(resp.Body, maxUpdateSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read release binary: %w", err)
+	}
+	if len(binary) > maxUpdateSize {
+		return fmt.Errorf("release binary exceeds maximum size of %d bytes", maxUpdateSize)
+	}
+
+	// Verify before touching the filesystem so a tampered binary is never written
+	sum := sha256.Sum256(binary)
+	if subtle.ConstantTimeCompare(sum[:], digest) != 1 {
+		return fmt.Errorf("release binary does not match the signed digest")
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate running executable: %w", err)
+	}
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return fmt.Errorf("failed to resolve running executable: %w", err)
+	}
+
+	// Write next to the executable so the final rename is atomic on the same filesystem
+	staged := executable + ".new"
+	if err := os.WriteFile(staged, binary, 0755); err != nil {
+		return fmt.Errorf("failed to stage release binary: %w", err)
+	}
+	if err := os.Rename(staged, executable); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to replace executable: %w", err)
+	}
+
+	u.logger.WithFields(logrus.Fields{
+		"fromVersion": Version,
+		"toVersion":   release.Version,
+		"executable":  executable,
+	}).Info("Release installed, restart required")
+	return nil
+}
+
+// RestartProcess replaces the current process with a fresh instance of the
+// executable on disk, preserving arguments and environment. It only returns on failure.
+//
+// Returns:
+//   - error: Any error locating or executing the binary
+func RestartProcess() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return fmt.Errorf("failed to resolve executable: %w", err)
+	}
+	return syscall.Exec(executable, os.Args, os.Environ())
+}
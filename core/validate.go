@@ -0,0 +1,386 @@
+/*
+Package core provides configuration validation for the Skynet Agent application.
+
+Contradictory settings are rejected by LoadConfig through Config.Validate, so the
+server fails fast at startup instead of running with a configuration the operator
+did not intend. ValidateEnvironment goes further for the "skynet validate"
+command: it also checks what the configuration depends on outside the process,
+such as the reachability of the Ollama endpoint, the presence of the configured
+model, referenced files, and the binaries used by the enabled tools, and collects
+the results in a printable report.
+*/
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	localtools "skynet/tools"
+)
+
+// CheckStatus is the outcome of a single validation check.
+type CheckStatus string
+
+const (
+	CheckOK      CheckStatus = "ok"      // The check passed
+	CheckWarning CheckStatus = "warning" // Skynet runs, but a feature will not work
+	CheckFailed  CheckStatus = "failed"  // Skynet cannot run as configured
+)
+
+// ValidationCheck is the result of one validation check.
+type ValidationCheck struct {
+	Name    string      `json:"name"`    // Short name of what was checked
+	Status  CheckStatus `json:"status"`  // Outcome of the check
+	Message string      `json:"message"` // Human-readable detail
+}
+
+// ValidationReport collects the results of ValidateEnvironment.
+type ValidationReport struct {
+	Checks []ValidationCheck `json:"checks"` // Checks in the order they were run
+}
+
+// add records a check result.
+func (r *ValidationReport) add(name string, status CheckStatus, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, ValidationCheck{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// Failed reports whether any check failed.
+func (r *ValidationReport) Failed() bool {
+	for _, check := range r.Checks {
+		if check.Status == CheckFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// Write prints the report as one line per check followed by a summary.
+//
+// Parameters:
+//   - w: Writer receiving the report
+func (r *ValidationReport) Write(w io.Writer) {
+	counts := make(map[CheckStatus]int)
+	for _, check := range r.Checks {
+		counts[check.Status]++
+		fmt.Fprintf(w, "%-8s %-18s %s\n", strings.ToUpper(string(check.Status)), check.Name, check.Message)
+	}
+	fmt.Fprintf(w, "\n%d ok, %d warnings, %d failed\n", counts[CheckOK], counts[CheckWarning], counts[CheckFailed])
+}
+
+// Validate checks the configuration for missing or contradictory settings that
+// would make Skynet misbehave. It is run by LoadConfig.
+//
+// Returns:
+//   - error: All problems found, joined, or nil
+func (c *Config) Validate() error {
+	var problems []error
+
+	switch c.LLMProvider {
+	case "ollama":
+		if c.OllamaEndpoint == "" || c.OllamaModel == "" {
+			problems = append(problems, errors.New("the ollama provider requires OLLAMA_ENDPOINT and OLLAMA_MODEL"))
+		}
+	case "gemini":
+		if c.GeminiAPIKey == "" {
+			problems = append(problems, errors.New("LLM_PROVIDER is gemini but GEMINI_API_KEY is not set"))
+		}
+	default:
+		problems = append(problems, fmt.Errorf("unsupported LLM_PROVIDER %q: use ollama or gemini", c.LLMProvider))
+	}
+
+	problems = append(problems, c.validateLogOutput()...)
+
+	if c.SentryDSN != "" {
+		if _, err := parseSentryDSN(c.SentryDSN); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if _, err := template.New("alert").Parse(c.AlertmanagerPrompt); err != nil {
+		problems = append(problems, fmt.Errorf("invalid ALERTMANAGER_PROMPT template: %w", err))
+	}
+
+	if (c.SlackBotToken == "") != (c.SlackSigningSecret == "") {
+		problems = append(problems, errors.New("the Slack bot requires both SLACK_BOT_TOKEN and SLACK_SIGNING_SECRET"))
+	}
+
+	if (c.MatrixHomeserverURL == "") != (c.MatrixAccessToken == "") {
+		problems = append(problems, errors.New("the Matrix bot requires both MATRIX_HOMESERVER_URL and MATRIX_ACCESS_TOKEN"))
+	}
+
+	if c.MQTTBrokerURL != "" {
+		if u, err := url.Parse(c.MQTTBrokerURL); err != nil || u.Host == "" {
+			problems = append(problems, fmt.Errorf("MQTT_BROKER_URL must be a broker URL such as tcp://broker:1883, got %q", c.MQTTBrokerURL))
+		}
+		if strings.ContainsAny(c.MQTTResultTopic, "+#") {
+			problems = append(problems, errors.New("MQTT_RESULT_TOPIC must not contain the wildcards + or #"))
+		}
+	}
+
+	switch c.ToolIOClass {
+	case "", localtools.IOClassBestEffort, localtools.IOClassIdle:
+	default:
+		problems = append(problems, fmt.Errorf("unsupported TOOL_IONICE_CLASS %q: use best-effort or idle", c.ToolIOClass))
+	}
+
+	if _, err := parseAllowedCIDRs(c.AllowedCIDRs); err != nil {
+		problems = append(problems, err)
+	}
+
+	if _, err := NewRedactor(c.RedactionPatterns); err != nil {
+		problems = append(problems, err)
+	}
+
+	if _, err := NewFieldCipher(c.EncryptionKey); err != nil {
+		problems = append(problems, err)
+	}
+
+	// Non-streaming chat responses are written when the execution ends
+	if c.HTTPWriteTimeout > 0 && c.HTTPWriteTimeout < c.RequestTimeout {
+		problems = append(problems, fmt.Errorf("HTTP_WRITE_TIMEOUT (%s) must not be shorter than REQUEST_TIMEOUT (%s), or chat responses are cut off", c.HTTPWriteTimeout, c.RequestTimeout))
+	}
+
+	switch c.AnonymousAccess {
+	case AnonymousAccessAuto, AnonymousAccessOn, AnonymousAccessOff:
+	default:
+		problems = append(problems, fmt.Errorf("ANONYMOUS_ACCESS must be %q, %q, or %q, got %q", AnonymousAccessAuto, AnonymousAccessOn, AnonymousAccessOff, c.AnonymousAccess))
+	}
+
+	switch c.ClusterMode {
+	case ClusterModeAuto, ClusterModeOn, ClusterModeOff:
+	default:
+		problems = append(problems, fmt.Errorf("CLUSTER_MODE must be %q, %q, or %q, got %q", ClusterModeAuto, ClusterModeOn, ClusterModeOff, c.ClusterMode))
+	}
+
+	for _, target := range c.Targets {
+		if _, err := localtools.ParseTarget(target); err != nil {
+			problems = append(problems, fmt.Errorf("invalid TARGETS entry: %w", err))
+		}
+	}
+
+	switch c.TranscriptionBackend {
+	case "", TranscriptionGemini:
+		if c.TranscriptionBackend == TranscriptionGemini && c.LLMProvider != "gemini" {
+			problems = append(problems, errors.New("TRANSCRIPTION_BACKEND gemini requires LLM_PROVIDER gemini"))
+		}
+	case TranscriptionWhisper:
+		if c.WhisperModel == "" {
+			problems = append(problems, errors.New("TRANSCRIPTION_BACKEND whisper requires WHISPER_MODEL"))
+		}
+	default:
+		problems = append(problems, fmt.Errorf("unsupported TRANSCRIPTION_BACKEND %q: use whisper or gemini", c.TranscriptionBackend))
+	}
+
+	switch c.TTSBackend {
+	case "", TTSEspeak:
+	case TTSPiper:
+		if c.TTSVoice == "" {
+			problems = append(problems, errors.New("TTS_BACKEND piper requires TTS_VOICE, the voice model file"))
+		}
+	default:
+		problems = append(problems, fmt.Errorf("unsupported TTS_BACKEND %q: use piper or espeak", c.TTSBackend))
+	}
+
+	if c.SSHAddr != "" && c.SSHAuthorizedKeys == "" {
+		problems = append(problems, errors.New("the SSH chat server requires SSH_AUTHORIZED_KEYS"))
+	}
+
+	if (c.GitHubWebhookSecret == "") != (c.GitHubToken == "") {
+		problems = append(problems, errors.New("the GitHub integration requires both GITHUB_WEBHOOK_SECRET and GITHUB_TOKEN"))
+	}
+
+	if _, err := template.New("subject").Parse(c.EmailSubject); err != nil {
+		problems = append(problems, fmt.Errorf("invalid EMAIL_SUBJECT template: %w", err))
+	}
+	for _, kind := range c.EmailNotifyOn {
+		if !validNotifyOn(kind) {
+			problems = append(problems, fmt.Errorf("unsupported EMAIL_NOTIFY_ON value %q: use failure, success, report, or security", kind))
+		}
+	}
+	if len(c.EmailRecipients) > 0 && c.SMTPHost == "" {
+		problems = append(problems, errors.New("EMAIL_RECIPIENTS requires SMTP_HOST"))
+	}
+	if c.SMTPHost != "" && c.EmailFrom == "" && c.SMTPUsername == "" {
+		problems = append(problems, errors.New("email notifications require EMAIL_FROM or SMTP_USERNAME as the sender"))
+	}
+
+	if c.SelfUpdateEnabled && (c.UpdateURL == "" || c.UpdatePublicKey == "") {
+		problems = append(problems, errors.New("SELF_UPDATE_ENABLED requires UPDATE_URL and UPDATE_PUBLIC_KEY"))
+	}
+
+	return errors.Join(problems...)
+}
+
+// toolBinaries lists the executables each built-in tool runs; any one of them is enough.
+var toolBinaries = map[string][]string{
+	"ansible":   {"ansible", "ansible-playbook"},
+	"apk":       {"apk"},
+	"aws":       {"aws"},
+	"capture":   {"tcpdump"},
+	"certbot":   {"certbot"},
+	"cron":      {"crontab"},
+	"dmesg":     {"dmesg"},
+	"fail2ban":  {"fail2ban-client"},
+	"go":        {"go"},
+	"gpg":       {"gpg"},
+	"helm":      {"helm"},
+	"kubectl":   {"kubectl"},
+	"lsof":      {"lsof"},
+	"netstat":   {"netstat"},
+	"network":   {"ip"},
+	"npm":       {"npm"},
+	"perf":      {"perf"},
+	"pip":       {"pip3", "pip", "python3"},
+	"pod_logs":  {"kubectl"},
+	"ps":        {"ps"},
+	"shell":     {"bash", "sh"},
+	"smart":     {"smartctl"},
+	"systemctl": {"systemctl"},
+	"top":       {"top"},
+	"transfer":  {"rsync", "scp"},
+	"webserver": {"nginx", "caddy", "apachectl"},
+	"wireguard": {"wg"},
+}
+
+// ValidateEnvironment checks the configuration against the host it runs on and
+// returns a report. It does not modify anything.
+//
+// Parameters:
+//   - ctx: Context bounding the network checks
+//   - config: Configuration to check
+//
+// Returns:
+//   - *ValidationReport: Result of every check
+func ValidateEnvironment(ctx context.Context, config *Config) *ValidationReport {
+	report := &ValidationReport{}
+
+	if config.ConfigFile != "" {
+		report.add("config", CheckOK, "loaded %s and the environment", config.ConfigFile)
+	} else {
+		report.add("config", CheckOK, "loaded from the environment")
+	}
+
+	validateProvider(ctx, config, report)
+
+	if config.KubectlKubeconfig != "" {
+		validateFile(report, "kubeconfig", config.KubectlKubeconfig)
+	}
+	if config.AnsibleInventory != "" {
+		validateFile(report, "ansible inventory", config.AnsibleInventory)
+	}
+	if config.AuditLogPath != "" {
+		validateDir(report, "audit log", config.AuditLogPath)
+	}
+	if config.AnalyticsLogPath != "" {
+		if !config.AnalyticsEnabled {
+			report.add("analytics", CheckWarning, "ANALYTICS_LOG_PATH is set but ANALYTICS_ENABLED is false; no tags are written")
+		} else {
+			validateDir(report, "analytics log", config.AnalyticsLogPath)
+		}
+	}
+
+	if config.TranscriptionBackend == TranscriptionWhisper && config.WhisperModel != "" {
+		validateFile(report, "whisper model", config.WhisperModel)
+		if _, err := exec.LookPath(config.WhisperBinary); err != nil {
+			report.add("whisper", CheckFailed, "%s is not installed; POST /chat/audio cannot transcribe", config.WhisperBinary)
+		}
+	}
+
+	if config.TTSBackend != "" {
+		if _, err := exec.LookPath(ttsBinary(config)); err != nil {
+			report.add("speech", CheckFailed, "%s is not installed; spoken answers cannot be synthesized", ttsBinary(config))
+		}
+	}
+
+	validateToolBinaries(config, report)
+	return report
+}
+
+// validateProvider checks that the configured LLM provider can be used.
+func validateProvider(ctx context.Context, config *Config, report *ValidationReport) {
+	if config.LLMProvider == "gemini" {
+		if config.GeminiAPIKey != "" {
+			report.add("llm", CheckOK, "gemini with model %s", config.GeminiModel)
+		}
+		return
+	}
+	if config.LLMProvider != "ollama" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	models, err := ollamaModels(ctx, config.OllamaEndpoint)
+	if err != nil {
+		report.add("llm", CheckFailed, "%v", err)
+		return
+	}
+	for _, model := range models {
+		if model == config.OllamaModel || model == config.OllamaModel+":latest" {
+			report.add("llm", CheckOK, "ollama at %s serves model %s", config.OllamaEndpoint, config.OllamaModel)
+			return
+		}
+	}
+	if config.OllamaAutoPull {
+		report.add("llm", CheckWarning, "ollama at %s does not have model %s yet; it is pulled at startup", config.OllamaEndpoint, config.OllamaModel)
+		return
+	}
+	report.add("llm", CheckFailed, "ollama at %s does not have model %s; run: ollama pull %s", config.OllamaEndpoint, config.OllamaModel, config.OllamaModel)
+}
+
+// validateFile checks that a referenced file exists.
+func validateFile(report *ValidationReport, name, path string) {
+	if _, err := os.Stat(path); err != nil {
+		report.add(name, CheckFailed, "%v", err)
+		return
+	}
+	report.add(name, CheckOK, "%s exists", path)
+}
+
+// validateDir checks that the directory of a file Skynet writes to exists.
+func validateDir(report *ValidationReport, name, path string) {
+	dir := filepath.Dir(path)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		report.add(name, CheckFailed, "directory %s of %s does not exist", dir, path)
+		return
+	}
+	report.add(name, CheckOK, "%s is writable in an existing directory", path)
+}
+
+// validateToolBinaries warns about enabled built-in tools whose executables are missing.
+func validateToolBinaries(config *Config, report *ValidationReport) {
+	enabled := EnabledTools(config)
+	var missing []string
+	for _, tool := range builtinTools(config, localtools.NewWorkspaceContext(""), nil) {
+		binaries, ok := toolBinaries[tool.Name()]
+		if !ok || !enabled(RegisteredTool{Tool: tool, Source: ToolSourceBuiltin}) {
+			continue
+		}
+		found := false
+		for _, binary := range binaries {
+			if _, err := exec.LookPath(binary); err == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, fmt.Sprintf("%s (%s)", tool.Name(), strings.Join(binaries, " or ")))
+		}
+	}
+
+	if len(missing) > 0 {
+		report.add("tool binaries", CheckWarning, "tools will report errors until installed or disabled with TOOLS_DISABLED: %s", strings.Join(missing, ", "))
+		return
+	}
+	report.add("tool binaries", CheckOK, "all enabled built-in tools have their executables")
+}
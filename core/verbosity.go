@@ -0,0 +1,94 @@
+/*
+Package core provides stream verbosity levels for the Skynet Agent application.
+
+A POST /chat/stream client chooses how much of the execution it receives with
+the request's "verbosity", each level including the ones before it:
+
+	final     Only the answer, errors, and the session and queue events
+	progress  Also "thinking" and heartbeat "progress" events
+	tools     Also a "tool" event for every tool call with its command and output
+	debug     Also the agent's internal events, such as LLM prompt previews
+
+Without a verbosity the stream uses debug when DEBUG_MODE is enabled and tools
+otherwise. The debug level requires DEBUG_MODE; without it, tools is used.
+*/
+package core
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Verbosity is the amount of execution detail streamed to a client.
+type Verbosity string
+
+// Supported stream verbosity levels, from least to most detailed
+const (
+	VerbosityFinal    Verbosity = "final"    // Final answer only
+	VerbosityProgress Verbosity = "progress" // Plus thinking and progress events
+	VerbosityTools    Verbosity = "tools"    // Plus tool calls and their output
+	VerbosityDebug    Verbosity = "debug"    // Plus internal agent events
+)
+
+// verbosityKey is the echo context key of a stream's verbosity
+const verbosityKey = "streamVerbosity"
+
+// verbosityLevels orders the verbosity levels
+var verbosityLevels = map[Verbosity]int{
+	VerbosityFinal:    0,
+	VerbosityProgress: 1,
+	VerbosityTools:    2,
+	VerbosityDebug:    3,
+}
+
+// Includes reports whether a stream at this verbosity receives events of another level.
+func (v Verbosity) Includes(level Verbosity) bool {
+	return verbosityLevels[v] >= verbosityLevels[level]
+}
+
+// streamVerbosity resolves the verbosity of a streaming request.
+//
+// Parameters:
+//   - requested: The request's verbosity; empty selects the server default
+//
+// Returns:
+//   - Verbosity: Level to stream at, limited to tools without DEBUG_MODE
+//   - error: Unknown verbosity, suitable for the client
+func (s *Server) streamVerbosity(requested Verbosity) (Verbosity, error) {
+	debugMode := s.currentConfig().DebugMode
+	if requested == "" {
+		if debugMode {
+			return VerbosityDebug, nil
+		}
+		return VerbosityTools, nil
+	}
+	if _, ok := verbosityLevels[requested]; !ok {
+		return "", fmt.Errorf("unknown verbosity %q; use final, progress, tools, or debug", requested)
+	}
+	if requested == VerbosityDebug && !debugMode {
+		return VerbosityTools, nil
+	}
+	return requested, nil
+}
+
+// eventVerbosity returns the lowest verbosity at which a stream message is sent.
+func eventVerbosity(msg StreamMessage) Verbosity {
+	switch msg.Type {
+	case "thinking", "progress":
+		return VerbosityProgress
+	case "tool":
+		return VerbosityTools
+	case "debug", "chain_start", "chain_step", "llm_call", "agent_action":
+		return VerbosityDebug
+	default:
+		return VerbosityFinal
+	}
+}
+
+// streamIncludes reports whether a stream message is sent to the client, by the
+// verbosity set on the request context. Streams without a verbosity receive everything.
+func streamIncludes(c echo.Context, msg StreamMessage) bool {
+	verbosity, ok := c.Get(verbosityKey).(Verbosity)
+	return !ok || verbosity.Includes(eventVerbosity(msg))
+}
@@ -0,0 +1,62 @@
+/*
+Package core provides build and version reporting for the Skynet Agent application.
+
+Version details are injected at build time through linker flags, for example:
+
+	go build -ldflags "-X skynet/core.Version=1.2.0 -X skynet/core.GitCommit=$(git rev-parse --short HEAD)"
+
+When no commit is injected, the VCS revision embedded by the Go toolchain is used instead.
+*/
+package core
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata populated via -ldflags at build time
+var (
+	Version   = "dev" // Semantic version of the build
+	GitCommit = ""    // Git commit SHA the binary was built from
+	BuildDate = ""    // Build timestamp (RFC3339)
+)
+
+// gitCommit returns the injected commit SHA, falling back to the VCS revision
+// recorded by the Go toolchain when the binary was built from a git checkout.
+func gitCommit() string {
+	if GitCommit != "" {
+		return GitCommit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
+
+// BuildInfo describes the running binary and the features enabled by configuration.
+//
+// Parameters:
+//   - config: Active configuration used to report enabled features
+//
+// Returns:
+//   - map[string]interface{}: Version, commit, build date, Go version, platform, and feature flags
+func BuildInfo(config *Config) map[string]interface{} {
+	return map[string]interface{}{
+		"version":   Version,
+		"gitCommit": gitCommit(),
+		"buildDate": BuildDate,
+		"goVersion": runtime.Version(),
+		"platform":  runtime.GOOS + "/" + runtime.GOARCH,
+		"features": map[string]interface{}{
+			"llmProvider": config.LLMProvider,
+			"audit":       config.AuditLogPath != "",
+			"analytics":   config.AnalyticsEnabled,
+			"selfUpdate":  config.SelfUpdateEnabled,
+			"debugMode":   config.DebugMode,
+		},
+	}
+}
@@ -0,0 +1,390 @@
+/*
+Package core provides declarative workflows for the Skynet Agent application.
+
+A workflow is a repeatable runbook: a sequence of steps, declared in a YAML file,
+that the agent carries out one after the other in a shared session:
+
+	workflows:
+	  - name: disk-cleanup
+	    description: Free disk space below a directory
+	    params: [path]
+	    steps:
+	      - name: inspect
+	        prompt: Report the disk usage of {{.Params.path}} and its largest subdirectories.
+	        tools: [sysinfo, ls, stat]
+	      - name: check
+	        prompt: Is less than 10% of the disk free? Answer YES or NO first.
+	        success: ^YES
+	        on_failure: end
+	      - name: clean
+	        prompt: Remove old log files below {{.Params.path}}. Usage before was {{.Steps.inspect}}.
+	        tools: [ls, file]
+
+Prompts are Go templates rendered with .Params, the parameters of the run,
+.Steps, the answers of the steps run so far by name, and .Previous, the answer of
+the previous step. A step restricted with tools may only call those tools. A
+step succeeds when its execution succeeds and, if it sets success, its answer
+matches that regular expression. The next step is on_success or on_failure, a
+step name or "end"; by default a successful step continues with the following
+step and a failed step fails the run.
+
+POST /workflows/:name/run streams the progress of every step as server-sent
+events; GET /workflows lists the declared workflows. A run is a request of its
+caller: it counts against the tenant's rate limit, its session belongs to the
+caller, and a step may only use the tools both the step and the tenant allow.
+*/
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowEnd is the branch target ending a run
+const workflowEnd = "end"
+
+// workflowStepLimit bounds the steps of one run, so branches cannot loop forever
+const workflowStepLimit = 50
+
+// WorkflowStep declares a step of a workflow.
+type WorkflowStep struct {
+	Name      string   `yaml:"name" json:"name"`                      // Step name, used by branches and .Steps
+	Prompt    string   `yaml:"prompt" json:"prompt"`                  // Go template of the message sent to the agent
+	Tools     []string `yaml:"tools" json:"tools,omitempty"`          // Tools the step may call (default: all)
+	Success   string   `yaml:"success" json:"success,omitempty"`      // Regular expression the answer must match to succeed
+	OnSuccess string   `yaml:"on_success" json:"onSuccess,omitempty"` // Next step after success (default: the following step)
+	OnFailure string   `yaml:"on_failure" json:"onFailure,omitempty"` // Next step after failure (default: fail the run)
+	prompt    *template.Template
+	success   *regexp.Regexp
+}
+
+// Workflow declares a multi-step runbook.
+type Workflow struct {
+	Name        string         `yaml:"name" json:"name"`
+	Description string         `yaml:"description" json:"description"`
+	Params      []string       `yaml:"params" json:"params"` // Parameters every run must provide
+	Steps       []WorkflowStep `yaml:"steps" json:"steps"`
+}
+
+// workflowsFile is the top-level structure of the workflows file.
+type workflowsFile struct {
+	Workflows []Workflow `yaml:"workflows"`
+}
+
+// workflowData is the data step prompts are rendered with.
+type workflowData struct {
+	Params   map[string]string // Parameters of the run
+	Steps    map[string]string // Answers of the steps run so far, by name
+	Previous string            // Answer of the previous step
+}
+
+// WorkflowRunRequest is the body of POST /workflows/:name/run.
+type WorkflowRunRequest struct {
+	Params    map[string]string `json:"params"`
+	SessionID string            `json:"sessionId"` // Session the steps run in (default: a new session)
+}
+
+// loadWorkflows reads the workflows file and validates every workflow. A missing
+// file is not an error.
+//
+// Parameters:
+//   - path: Path of the YAML workflows file; empty disables workflows
+//
+// Returns:
+//   - map[string]*Workflow: Workflows by name
+//   - error: Any error reading or decoding the file, or an invalid workflow
+func loadWorkflows(path string) (map[string]*Workflow, error) {
+	workflows := make(map[string]*Workflow)
+	if path == "" {
+		return workflows, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return workflows, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflows file: %w", err)
+	}
+
+	var file workflowsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode workflows file: %w", err)
+	}
+
+	for i := range file.Workflows {
+		workflow := &file.Workflows[i]
+		if err := workflow.compile(); err != nil {
+			return nil, fmt.Errorf("workflow %q: %w", workflow.Name, err)
+		}
+		if _, exists := workflows[workflow.Name]; exists {
+			return nil, fmt.Errorf("workflow %q is declared more than once", workflow.Name)
+		}
+		workflows[workflow.Name] = workflow
+	}
+	return workflows, nil
+}
+
+// compile validates a workflow and parses its templates and success patterns.
+func (w *Workflow) compile() error {
+	if w.Name == "" || strings.Contains(w.Name, "/") {
+		return fmt.Errorf("name must be non-empty and must not contain '/'")
+	}
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+
+	names := map[string]bool{workflowEnd: true}
+	for i := range w.Steps {
+		step := &w.Steps[i]
+		if step.Name == "" || names[step.Name] {
+			return fmt.Errorf("step %d: name must be unique and must not be %q", i+1, workflowEnd)
+		}
+		names[step.Name] = true
+	}
+
+	for i := range w.Steps {
+		step := &w.Steps[i]
+		if step.Prompt == "" {
+			return fmt.Errorf("step %q: prompt is required", step.Name)
+		}
+		var err error
+		if step.prompt, err = template.New(step.Name).Option("missingkey=zero").Parse(step.Prompt); err != nil {
+			return fmt.Errorf("step %q: invalid prompt template: %w", step.Name, err)
+		}
+		if step.Success != "" {
+			if step.success, err = regexp.Compile(step.Success); err != nil {
+				return fmt.Errorf("step %q: invalid success pattern: %w", step.Name, err)
+			}
+		}
+		for _, target := range []string{step.OnSuccess, step.OnFailure} {
+			if target != "" && !names[target] {
+				return fmt.Errorf("step %q: unknown branch target %q", step.Name, target)
+			}
+		}
+	}
+	return nil
+}
+
+// stepIndex returns the index of a named step, or -1.
+func (w *Workflow) stepIndex(name string) int {
+	for i, step := range w.Steps {
+		if step.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// workflowProgressHandler streams the tools a workflow step runs to the client.
+type workflowProgressHandler struct {
+	*VerboseCallbackHandler
+	step string
+	send func(msg StreamMessage)
+}
+
+func (h *workflowProgressHandler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
+	h.VerboseCallbackHandler.HandleAgentAction(ctx, action)
+	h.send(StreamMessage{
+		Type:    "tool",
+		Tool:    action.Tool,
+		Content: action.ToolInput,
+		Step:    h.step,
+	})
+}
+
+// Ensure workflowProgressHandler implements the callbacks.Handler interface
+var _ callbacks.Handler = (*workflowProgressHandler)(nil)
+
+// handleListWorkflows handles GET /workflows requests.
+func (s *Server) handleListWorkflows(c echo.Context) error {
+	workflows := make([]*Workflow, 0, len(s.workflows))
+	for _, workflow := range s.workflows {
+		workflows = append(workflows, workflow)
+	}
+	sort.Slice(workflows, func(i, j int) bool {
+		return workflows[i].Name < workflows[j].Name
+	})
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"workflows": workflows,
+		"count":     len(workflows),
+	})
+}
+
+// handleRunWorkflow handles POST /workflows/:name/run requests by running the
+// steps of a workflow and streaming their progress as server-sent events.
+func (s *Server) handleRunWorkflow(c echo.Context) error {
+	name := c.Param("name")
+	requestLogger := s.logger.WithContext(c.Request().Context()).WithFields(logrus.Fields{
+		"endpoint": "/workflows/:name/run",
+		"method":   "POST",
+		"workflow": name,
+		"clientIP": c.RealIP(),
+	})
+
+	workflow, exists := s.workflows[name]
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": fmt.Sprintf("Workflow %q not found", name)})
+	}
+
+	var req WorkflowRunRequest
+	if err := c.Bind(&req); err != nil {
+		requestLogger.WithError(err).Error("Failed to parse workflow run request")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	for _, param := range workflow.Params {
+		if req.Params[param] == "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Parameter %q is required", param)})
+		}
+	}
+	if req.SessionID == "" {
+		req.SessionID = fmt.Sprintf("workflow_%s_%d", name, time.Now().UnixNano())
+	}
+	caller, answered, err := s.callerOf(c)
+	if answered {
+		return err
+	}
+	if limited, err := s.rejectRateLimited(c, caller, requestLogger); limited {
+		return err
+	}
+	if exceeded, err := s.rejectQuotaExceeded(c, caller, requestLogger); exceeded {
+		return err
+	}
+	if session := s.memoryStore.GetOrCreateOwnedSession(req.SessionID, caller.owner, caller.tenant); !caller.mayUse(session) {
+		return s.rejectForeignSession(c, session, requestLogger)
+	}
+
+	// Reserve a place in the request queue before the stream starts, so a full
+	// queue can still be rejected with a status code
+	ticket, err := s.queue.Enqueue()
+	if err != nil {
+		return s.rejectQueueFull(c, requestLogger)
+	}
+	defer ticket.Release()
+
+	c.Response().Header().Set("Content-Type", "text/event-stream")
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	c.Response().Header().Set("Connection", "keep-alive")
+	c.Response().Header().Set("Access-Control-Allow-Origin", "*")
+
+	// Steps run in their own executions, so the stream only receives keepalives
+	heartbeat := s.startHeartbeat(c)
+	defer heartbeat.Stop()
+
+	// Tool progress and step results are sent from the agent's callbacks and this handler
+	var streamMutex sync.Mutex
+	send := func(msg StreamMessage) {
+		streamMutex.Lock()
+		defer streamMutex.Unlock()
+		s.sendStreamMessage(c, msg)
+	}
+	send(StreamMessage{Type: "session", Content: req.SessionID})
+
+	if err := ticket.Wait(c.Request().Context(), func(position int, eta time.Duration) {
+		send(StreamMessage{
+			Type:    "queued",
+			Content: fmt.Sprintf("Waiting in queue (position %d, about %s)", position, eta.Round(time.Second)),
+			Details: map[string]interface{}{
+				"position":   position,
+				"etaSeconds": int(eta.Seconds()),
+			},
+		})
+	}); err != nil {
+		requestLogger.WithError(err).Info("Client left the request queue")
+		return nil
+	}
+
+	requestLogger.WithField("sessionID", req.SessionID).Info("Starting workflow run")
+	data := workflowData{Params: req.Params, Steps: make(map[string]string)}
+	ctx := s.withCaller(c.Request().Context(), caller)
+	succeeded := true
+	for index, count := 0, 0; index >= 0 && index < len(workflow.Steps); count++ {
+		if count == workflowStepLimit {
+			send(StreamMessage{Type: "error", Content: fmt.Sprintf("Workflow stopped after %d steps", workflowStepLimit)})
+			succeeded = false
+			break
+		}
+		step := workflow.Steps[index]
+		stepLogger := requestLogger.WithField("step", step.Name)
+
+		var prompt bytes.Buffer
+		if err := step.prompt.Execute(&prompt, data); err != nil {
+			stepLogger.WithError(err).Error("Failed to render workflow step prompt")
+			send(StreamMessage{Type: "error", Content: fmt.Sprintf("Failed to render the prompt of step %q: %v", step.Name, err), Step: step.Name})
+			succeeded = false
+			break
+		}
+		message := prompt.String()
+		if len(step.Tools) > 0 {
+			message += fmt.Sprintf("\n\nUse only these tools: %s.", strings.Join(step.Tools, ", "))
+		}
+		send(StreamMessage{Type: "step_start", Content: message, Step: step.Name})
+
+		progress := &workflowProgressHandler{
+			VerboseCallbackHandler: NewVerboseCallbackHandler(stepLogger, s.currentConfig()),
+			step:                   step.Name,
+			send:                   send,
+		}
+		stepCtx := WithAllowedTools(WithCallbackHandler(ctx, progress), step.Tools)
+		response, err := s.chat(stepCtx, req.SessionID, message, c.RealIP(), stepLogger)
+		if ctx.Err() != nil {
+			stepLogger.Info("Client disconnected, stopping workflow run")
+			return nil
+		}
+
+		success := err == nil && (step.success == nil || step.success.MatchString(response.Response))
+		data.Steps[step.Name] = response.Response
+		data.Previous = response.Response
+		send(StreamMessage{
+			Type:     "step_result",
+			Content:  response.Response,
+			Step:     step.Name,
+			Complete: true,
+			Details: map[string]interface{}{
+				"success":     success,
+				"executionId": response.ExecutionID,
+			},
+		})
+		stepLogger.WithField("success", success).Info("Workflow step finished")
+
+		next := step.OnSuccess
+		if !success {
+			next = step.OnFailure
+			if next == "" {
+				succeeded = false
+				break
+			}
+		}
+		switch next {
+		case "":
+			index++
+		case workflowEnd:
+			index = -1
+		default:
+			index = workflow.stepIndex(next)
+		}
+	}
+
+	result := "completed"
+	if !succeeded {
+		result = "failed"
+	}
+	requestLogger.WithField("result", result).Info("Workflow run finished")
+	send(StreamMessage{Type: "workflow_" + result, Content: fmt.Sprintf("Workflow %s %s", name, result), Complete: true})
+	return nil
+}
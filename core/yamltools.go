@@ -0,0 +1,129 @@
+/*
+Package core provides operator-declared command tools for the Skynet Agent application.
+
+Site-specific utilities can be exposed to the agent by declaring them in a
+tools.yaml file loaded at startup:
+
+	tools:
+	  - name: zfs
+	    description: "Inspect ZFS pools and datasets. Usage: '<subcommand> [args]'"
+	    binary: /usr/sbin/zfs
+	    args: []
+	    timeout: 20s
+	    subcommands: [list, get]
+
+Each entry becomes a command tool running the binary with the fixed args followed
+by the agent's input. When subcommands are listed, the first word of the input
+must be one of them. A timeout overrides the tool's execution timeout.
+*/
+package core
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	localtools "skynet/tools"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// CommandToolDefinition declares a command-backed tool in the tools file.
+type CommandToolDefinition struct {
+	Name        string   `yaml:"name"`        // Tool identifier exposed to the agent
+	Description string   `yaml:"description"` // Usage description exposed to the agent
+	Binary      string   `yaml:"binary"`      // Executable name or path
+	Args        []string `yaml:"args"`        // Fixed arguments placed before the agent's input
+	Timeout     string   `yaml:"timeout"`     // Optional execution timeout, e.g. "30s"
+	Subcommands []string `yaml:"subcommands"` // Optional allowlist of subcommands
+}
+
+// commandToolsFile is the top-level structure of the tools file.
+type commandToolsFile struct {
+	Tools []CommandToolDefinition `yaml:"tools"`
+}
+
+// LoadCommandTools reads and validates the tools file. A missing file is not an error.
+// Timeouts declared in the file are applied to the configuration's per-tool timeouts.
+//
+// Parameters:
+//   - path: Path of the YAML tools file; empty disables loading
+//   - config: Configuration receiving per-tool timeout overrides
+//   - logger: Logger instance for operational monitoring
+//
+// Returns:
+//   - []CommandToolDefinition: Validated tool definitions
+//   - error: Any error reading, decoding, or validating the file
+func LoadCommandTools(path string, config *Config, logger *logrus.Logger) ([]CommandToolDefinition, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		logger.WithField("path", path).Debug("No tools file found")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tools file: %w", err)
+	}
+
+	var file commandToolsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode tools file: %w", err)
+	}
+
+	seen := make(map[string]bool, len(file.Tools))
+	for _, definition := range file.Tools {
+		if !toolNamePattern.MatchString(definition.Name) {
+			return nil, fmt.Errorf("tool %q: name must be lowercase letters, digits, '-' or '_' and start with a letter", definition.Name)
+		}
+		if seen[definition.Name] {
+			return nil, fmt.Errorf("tool %q is declared more than once", definition.Name)
+		}
+		seen[definition.Name] = true
+
+		if definition.Description == "" || definition.Binary == "" {
+			return nil, fmt.Errorf("tool %q: description and binary are required", definition.Name)
+		}
+
+		if definition.Timeout != "" {
+			timeout, err := time.ParseDuration(definition.Timeout)
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("tool %q: invalid timeout %q", definition.Name, definition.Timeout)
+			}
+			config.ToolTimeouts[definition.Name] = timeout
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"path":  path,
+		"tools": len(file.Tools),
+	}).Info("Command tools loaded")
+	return file.Tools, nil
+}
+
+// newCommandTools instantiates a tool for every declared definition.
+//
+// Parameters:
+//   - definitions: Definitions returned by LoadCommandTools
+//   - workspace: Workspace shared with the other tools
+//
+// Returns:
+//   - []tools.Tool: Instantiated command tools
+func newCommandTools(definitions []CommandToolDefinition, workspace *localtools.WorkspaceContext) []tools.Tool {
+	toolsList := make([]tools.Tool, 0, len(definitions))
+	for _, definition := range definitions {
+		toolsList = append(toolsList, localtools.NewCommandTool(
+			definition.Name,
+			definition.Description,
+			definition.Binary,
+			definition.Args,
+			definition.Subcommands,
+			workspace,
+		))
+	}
+	return toolsList
+}
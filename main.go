@@ -6,7 +6,8 @@ AI-powered agent capabilities through a REST API. The server is built using
 the Echo web framework and includes proper configuration loading, logging,
 graceful shutdown, and error handling.
 
-The application follows these initialization steps:
+The binary offers the subcommands "serve" (the default), "chat", "exec", "tui",
+and "validate", implemented in commands.go. Serving follows these initialization steps:
 1. Load configuration from environment variables and files
 2. Initialize structured logging
 3. Create the core server instance with dependencies
@@ -33,19 +34,39 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
-// main is the application entry point that initializes and starts the Skynet Agent server.
-// It handles the complete lifecycle of the application including:
+// main is the application entry point. It runs the selected subcommand, serving
+// the HTTP API when none is given, and exits with its status; invalid command
+// lines exit with status 2.
+func main() {
+	exitCode := 0
+	if err := newRootCommand(&exitCode).Execute(); err != nil {
+		os.Exit(2)
+	}
+	os.Exit(exitCode)
+}
+
+// runServe initializes and starts the Skynet Agent server. It handles the complete
+// lifecycle of the application including:
 // - Configuration loading
 // - Dependency initialization
 // - HTTP server setup
 // - Graceful shutdown on interrupt signals
-func main() {
+//
+// Parameters:
+//   - configPath: Configuration file given with the global --config flag
+//
+// Returns:
+//   - int: Process exit code
+func runServe(configPath string) int {
 	// Load configuration from environment variables and config files
-	config := core.LoadConfig()
+	config, ok := loadConfig(configPath)
+	if !ok {
+		return 1
+	}
 
 	// Initialize structured logger with the loaded configuration
 	logger := core.InitializeLogger(config)
-	logger.Info("Starting Skynet Agent server")
+	logger.WithField("version", core.Version).Info("Starting Skynet Agent server")
 
 	// Create the core server instance with all dependencies
 	server, err := core.NewServer(config, logger)
@@ -61,9 +82,26 @@ func main() {
 	e.Use(middleware.Recover()) // Panic recovery
 	e.Use(middleware.CORS())    // Cross-Origin Resource Sharing
 
+	// Apply timeouts, keep-alive, and compression
+	core.ConfigureHTTP(e, config)
+
 	// Register all API routes and handlers
 	server.RegisterRoutes(e)
 
+	// Continue the background tasks that were running before the last shutdown
+	server.ResumeTasks()
+
+	// Follow the configured Matrix rooms when the Matrix bot is enabled
+	server.StartMatrixBot()
+
+	// Take commands from the MQTT broker when the MQTT integration is enabled
+	server.StartMQTT()
+
+	// Accept terminal conversations over SSH when the SSH chat server is enabled
+	if err := server.StartSSH(); err != nil {
+		logger.WithError(err).Fatal("Failed to start SSH server")
+	}
+
 	// Start the HTTP server in a separate goroutine to allow for graceful shutdown
 	go func() {
 		logger.WithField("port", config.Port).Info("Starting server")
@@ -72,13 +110,30 @@ func main() {
 		}
 	}()
 
+	// Reload the configuration on SIGHUP without dropping sessions
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			logger.Info("Received SIGHUP, reloading configuration")
+			if err := server.Reload(); err != nil {
+				logger.WithError(err).Error("Failed to reload configuration")
+			}
+		}
+	}()
+
 	// Set up graceful shutdown handling
 	// Create a channel to receive OS interrupt signals
 	quit := make(chan os.Signal, 1)
 	// Register the channel to receive specific signals (SIGINT, SIGTERM)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	// Block until a signal is received
-	<-quit
+	// Block until a signal is received or a self-update requests a restart
+	restart := false
+	select {
+	case <-quit:
+	case <-server.RestartRequested():
+		restart = true
+	}
 
 	logger.Info("Shutting down server...")
 
@@ -93,4 +148,16 @@ func main() {
 	} else {
 		logger.Info("Server shutdown complete")
 	}
+
+	// Stop child processes such as MCP servers
+	server.Close()
+
+	// Re-execute the updated binary after a self-update
+	if restart {
+		logger.WithField("version", core.Version).Info("Restarting into updated binary")
+		if err := core.RestartProcess(); err != nil {
+			logger.WithError(err).Fatal("Failed to restart after update")
+		}
+	}
+	return 0
 }
@@ -12,6 +12,7 @@
  * - Responsive design with automatic text area resizing
  * - Status monitoring and connection health checks
  * - Execution control (start/stop operations)
+ * - Pasted screenshots sent to the agent as image attachments
  * 
  * Architecture:
  * - Single-page application using vanilla JavaScript
@@ -55,6 +56,8 @@ class ChatApp {
         this.currentExecutionId = null;          // Track current agent execution for stop functionality
         this.currentResponseMessage = null;      // Reference to the currently streaming message element
         this.accumulatedContent = '';            // Accumulate streaming content for proper rendering
+        this.pendingAttachments = [];            // Images pasted into the input, sent with the next message
+        this.defaultPlaceholder = this.chatInput.placeholder;
         
         // Initialize the application components
         this.init();
@@ -71,6 +74,7 @@ class ChatApp {
         this.chatForm.addEventListener('submit', (e) => this.handleSubmit(e));
         this.chatInput.addEventListener('keydown', (e) => this.handleKeyDown(e));
         this.chatInput.addEventListener('input', () => this.autoResize());
+        this.chatInput.addEventListener('paste', (e) => this.handlePaste(e));
         this.stopButton.addEventListener('click', () => this.handleStop());
         
         // Perform initial setup tasks
@@ -112,6 +116,31 @@ class ChatApp {
         }
     }
 
+    /**
+     * Attach images pasted into the input field to the next message
+     * 
+     * Screenshots are read as data URLs, which the server accepts as base64
+     * attachments. Text pastes are left to the browser.
+     * 
+     * @param {ClipboardEvent} e - The paste event to handle
+     */
+    handlePaste(e) {
+        const images = Array.from(e.clipboardData?.items || [])
+            .filter(item => item.type.startsWith('image/'))
+            .map(item => item.getAsFile());
+        if (images.length === 0) return;
+
+        e.preventDefault();
+        images.forEach(file => {
+            const reader = new FileReader();
+            reader.onload = () => {
+                this.pendingAttachments.push({ data: reader.result });
+                this.chatInput.placeholder = `${this.pendingAttachments.length} image(s) attached - describe what to look for`;
+            };
+            reader.readAsDataURL(file);
+        });
+    }
+
     /**
      * Auto-resize the input textarea based on content
      * 
@@ -138,14 +167,19 @@ class ChatApp {
         // Prevent empty messages or submissions during active conversations
         if (!message || this.isTyping) return;
 
+        const attachments = this.pendingAttachments;
+        this.pendingAttachments = [];
+        this.chatInput.placeholder = this.defaultPlaceholder;
+
         // Add user message to the chat interface
-        this.addMessage(message, 'user');
+        const note = attachments.length > 0 ? `\n[${attachments.length} image(s) attached]` : '';
+        this.addMessage(message + note, 'user');
         this.chatInput.value = '';
         this.autoResize();
         this.setTyping(true);
 
         try {
-            await this.sendMessage(message);
+            await this.sendMessage(message, attachments);
         } catch (error) {
             this.addMessage(`Error: ${error.message}`, 'assistant error');
         } finally {
@@ -250,7 +284,7 @@ class ChatApp {
         }
     }
 
-    async sendMessage(message) {
+    async sendMessage(message, attachments = []) {
         try {
             // Reset streaming state
             this.currentResponseMessage = null;
@@ -263,7 +297,8 @@ class ChatApp {
                 },
                 body: JSON.stringify({ 
                     message: message,
-                    debug: this.debugMode // Always true now
+                    verbosity: this.debugMode ? 'debug' : 'tools',
+                    attachments: attachments
                 })
             });
 
@@ -320,6 +355,8 @@ class ChatApp {
                 break;
                 
             case 'thinking':
+            case 'queued':
+            case 'progress':
                 this.updateTypingMessage(data.content);
                 break;
                 
@@ -327,6 +364,10 @@ class ChatApp {
                 // Always show debug messages now
                 this.addDebugMessage(data);
                 break;
+
+            case 'tool':
+                this.addToolMessage(data);
+                break;
                 
             case 'response':
                 // Handle streaming response with plain text rendering
@@ -388,6 +429,30 @@ class ChatApp {
         }
     }
 
+    /**
+     * Render a finished tool call like a terminal: the command, then its output
+     * 
+     * @param {Object} data - The "tool" stream message
+     */
+    addToolMessage(data) {
+        const details = data.details || {};
+        const messageDiv = document.createElement('div');
+        messageDiv.className = 'message tool-output';
+
+        const commandP = document.createElement('p');
+        commandP.className = 'tool-command';
+        commandP.textContent = `$ ${data.tool} ${details.input || ''}`;
+
+        const outputPre = document.createElement('pre');
+        outputPre.className = 'tool-result';
+        outputPre.textContent = details.error ? `${data.content}\n${details.error}` : data.content;
+
+        messageDiv.appendChild(commandP);
+        messageDiv.appendChild(outputPre);
+        this.messagesContainer.appendChild(messageDiv);
+        this.scrollToBottom();
+    }
+
     addDebugMessage(data) {
         const messageDiv = document.createElement('div');
         messageDiv.className = 'message debug';
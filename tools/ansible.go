@@ -0,0 +1,221 @@
+/*
+Package tools provides fleet-wide configuration management for the Skynet Agent.
+
+This file implements the AnsibleTool, which runs ad-hoc Ansible modules and
+playbooks against an inventory so the agent can apply configuration tasks to
+many hosts at once instead of one shell session at a time.
+
+Check mode ("check": true) reports what would change without changing anything
+and is recommended before every playbook run against production hosts.
+
+Input is a JSON object, for example:
+
+	{"operation": "adhoc", "hosts": "webservers", "module": "ping"}
+	{"operation": "adhoc", "hosts": "all", "module": "apt", "args": "name=nginx state=latest", "become": true, "check": true}
+	{"operation": "playbook", "playbook": "site.yml", "limit": "db1", "extraVars": {"version": "1.2"}, "check": true}
+	{"operation": "inventory", "hosts": "all"}
+*/
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// ansibleLogger provides structured logging for all Ansible operations
+// with a consistent tool identifier for easy filtering and monitoring
+var ansibleLogger = logrus.WithField("tool", "ansible")
+
+// ansibleArgs holds the structured arguments of an Ansible run.
+type ansibleArgs struct {
+	Operation string            `json:"operation"`           // adhoc, playbook, or inventory
+	Hosts     string            `json:"hosts,omitempty"`     // Host pattern for adhoc and inventory (default: all)
+	Module    string            `json:"module,omitempty"`    // Module for adhoc runs (default: command)
+	Args      string            `json:"args,omitempty"`      // Module arguments for adhoc runs
+	Playbook  string            `json:"playbook,omitempty"`  // Playbook file for playbook runs
+	Inventory string            `json:"inventory,omitempty"` // Inventory file overriding the configured one
+	Limit     string            `json:"limit,omitempty"`     // Restrict a playbook to matching hosts
+	ExtraVars map[string]string `json:"extraVars,omitempty"` // Extra variables (-e)
+	Check     bool              `json:"check,omitempty"`     // Dry run: report changes without making them
+	Become    bool              `json:"become,omitempty"`    // Run with privilege escalation
+}
+
+// AnsibleTool runs ad-hoc Ansible modules and playbooks against an inventory.
+type AnsibleTool struct {
+	inventory string            // Default inventory file; empty uses Ansible's own default
+	workspace *WorkspaceContext // Shared workspace providing the working directory for relative paths
+}
+
+// NewAnsibleTool creates a new instance of the Ansible tool.
+// The tool requires ansible and ansible-playbook to be installed and accessible in the system PATH.
+//
+// Parameters:
+//   - inventory: Default inventory file; empty uses /etc/ansible/hosts or ansible.cfg
+//   - workspace: Shared workspace providing the working directory for relative paths
+//
+// Returns:
+//   - *AnsibleTool: Configured Ansible tool ready for use
+func NewAnsibleTool(inventory string, workspace *WorkspaceContext) *AnsibleTool {
+	ansibleLogger.WithField("inventory", inventory).Debug("Initializing ansible tool")
+	return &AnsibleTool{
+		inventory: inventory,
+		workspace: workspace,
+	}
+}
+
+// Description returns a description of the Ansible tool's operations and input format.
+//
+// Returns:
+//   - string: Description of the supported Ansible operations
+func (a *AnsibleTool) Description() string {
+	description := "Run configuration tasks across many hosts with Ansible. Input is a JSON object: {\"operation\": \"adhoc\", \"hosts\": \"<pattern>\", \"module\": \"<module>\", \"args\": \"<module args>\"} (e.g. module ping, or apt with args \"name=nginx state=latest\"), {\"operation\": \"playbook\", \"playbook\": \"<file.yml>\", \"limit\": \"<hosts>\", \"extraVars\": {\"key\": \"value\"}}, {\"operation\": \"inventory\", \"hosts\": \"<pattern>\"} (list matching hosts). Set \"check\": true for a dry run that only reports changes (do this before changing production hosts) and \"become\": true for root privileges."
+	if a.inventory != "" {
+		description += fmt.Sprintf(" The inventory is %s unless \"inventory\" is given.", a.inventory)
+	}
+	return description
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (a *AnsibleTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"operation":{"type":"string","enum":["adhoc","playbook","inventory"]},"hosts":{"type":"string"},"module":{"type":"string"},"args":{"type":"string"},"playbook":{"type":"string"},"inventory":{"type":"string"},"limit":{"type":"string"},"extraVars":{"type":"object","additionalProperties":{"type":"string"}},"check":{"type":"boolean"},"become":{"type":"boolean"}},"required":["operation"]}`)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("ansible")
+func (a *AnsibleTool) Name() string {
+	return "ansible"
+}
+
+// Call runs the Ansible operation described by the input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON operation object
+//
+// Returns:
+//   - string: Ansible output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (a *AnsibleTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := ansibleLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Ansible tool called")
+	startTime := time.Now()
+
+	var args ansibleArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid ansible arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+	if !isJSON {
+		return "Error: Please provide a JSON object with an operation (adhoc, playbook, or inventory)", nil
+	}
+
+	hosts := args.Hosts
+	if hosts == "" {
+		hosts = "all"
+	}
+
+	var program string
+	var cmdArgs []string
+	operation := strings.ToLower(args.Operation)
+	switch operation {
+	case "adhoc":
+		module := args.Module
+		if module == "" {
+			module = "command"
+		}
+		if (module == "command" || module == "shell") && args.Args == "" {
+			return fmt.Sprintf("Error: The %s module requires args with the command to run", module), nil
+		}
+		program = "ansible"
+		cmdArgs = []string{hosts, "-m", module}
+		if args.Args != "" {
+			cmdArgs = append(cmdArgs, "-a", args.Args)
+		}
+	case "playbook":
+		if args.Playbook == "" {
+			return "Error: Please provide the playbook file", nil
+		}
+		program = "ansible-playbook"
+		cmdArgs = []string{a.resolve(ctx, args.Playbook)}
+		if args.Limit != "" {
+			cmdArgs = append(cmdArgs, "--limit", args.Limit)
+		}
+	case "inventory":
+		program = "ansible"
+		cmdArgs = []string{hosts, "--list-hosts"}
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use adhoc, playbook, or inventory", args.Operation), nil
+	}
+
+	inventory := a.inventory
+	if args.Inventory != "" {
+		inventory = a.resolve(ctx, args.Inventory)
+	}
+	if inventory != "" {
+		cmdArgs = append(cmdArgs, "-i", inventory)
+	}
+	if len(args.ExtraVars) > 0 {
+		extraVars, _ := json.Marshal(args.ExtraVars)
+		cmdArgs = append(cmdArgs, "-e", string(extraVars))
+	}
+	if args.Check && operation != "inventory" {
+		cmdArgs = append(cmdArgs, "--check", "--diff")
+	}
+	if args.Become {
+		cmdArgs = append(cmdArgs, "--become")
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, program, cmdArgs...)
+	cmd.Dir = a.workspace.For(ctx).Dir()
+	cmd.Env = a.workspace.For(ctx).Environ()
+	// Host key prompts would block the run until it times out
+	cmd.Env = append(cmd.Environ(), "ANSIBLE_HOST_KEY_CHECKING=False", "ANSIBLE_NOCOLOR=1")
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"operation": operation,
+			"output":    string(output),
+		}).Error("Ansible command failed")
+
+		if _, lookErr := exec.LookPath(program); lookErr != nil {
+			return fmt.Sprintf("Error: %s is not installed or not accessible", program), nil
+		}
+		// Failed or unreachable hosts are reported in the output, which is more useful than the exit status
+		if len(output) > 0 {
+			return fmt.Sprintf("Error: %s reported failures:\n%s", program, string(output)), nil
+		}
+		return fmt.Sprintf("Error: %s failed: %v", program, err), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"check":         args.Check,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Ansible command completed")
+
+	return string(output), nil
+}
+
+// resolve makes a path absolute relative to the working directory.
+func (a *AnsibleTool) resolve(ctx context.Context, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(a.workspace.For(ctx).Dir(), path)
+}
+
+// Ensure AnsibleTool implements the tools.Tool interface
+var _ tools.Tool = (*AnsibleTool)(nil)
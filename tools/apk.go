@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -28,7 +27,7 @@ func (a *ApkTool) Name() string {
 }
 
 func (a *ApkTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := apkLogger.WithField("input", input)
+	toolLogger := apkLogger.WithContext(ctx).WithField("input", input)
 	toolLogger.Info("APK tool called")
 	startTime := time.Now()
 
@@ -39,22 +38,16 @@ func (a *ApkTool) Call(ctx context.Context, input string) (string, error) {
 		return "Error: Please provide an APK command. All APK commands are supported.", nil
 	}
 
-	// Execute command with timeout
-	cmdCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(cmdCtx, "apk", parts...)
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "apk", parts...)
 
-	output, err := cmd.CombinedOutput()
+	output, err := limitedCombinedOutput(ctx, cmd)
 	if err != nil {
 		toolLogger.WithError(err).WithFields(logrus.Fields{
 			"command": parts[0],
 			"output":  string(output),
 		}).Error("APK command failed")
 
-		if cmdCtx.Err() == context.DeadlineExceeded {
-			return "Error: APK command timed out after 60 seconds", nil
-		}
-
 		return string(output), nil
 	}
 
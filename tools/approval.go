@@ -0,0 +1,148 @@
+/*
+Package tools provides human approval of risky tool operations for the Skynet Agent.
+
+Some operations must not run on the agent's word alone, because the agent decides
+what to call and with which arguments. A tool that needs a human decision records
+an approval request in the session's Approvals and refuses to go ahead until the
+request is approved. Approval happens outside the agent: the Approve button of a
+Slack answer, the approval reaction of a Matrix answer, or POST
+/sessions/:sessionId/approve by a caller that may use the session. The agent can
+only ask the user for it.
+
+Each session has its own Approvals, attached to the execution context with
+WithApprovals. An approval is used once: the tool takes it when it goes ahead.
+*/
+package tools
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// approvalsKey is the unexported context key type for session approvals
+type approvalsKey struct{}
+
+// approvalRequest is an operation waiting for, or granted, a human decision.
+type approvalRequest struct {
+	description string    // What the operation does, shown to the approver
+	requestedAt time.Time // When the tool asked for approval
+	approved    bool      // Whether a human approved the request
+}
+
+// Approvals holds the approval requests of one session. It is safe for concurrent use.
+type Approvals struct {
+	mu       sync.Mutex
+	requests map[string]approvalRequest // Requests by operation key
+}
+
+// NewApprovals creates an empty set of approval requests.
+//
+// Returns:
+//   - *Approvals: Approvals ready for use
+func NewApprovals() *Approvals {
+	return &Approvals{requests: make(map[string]approvalRequest)}
+}
+
+// Request records an operation that needs approval, replacing an earlier
+// request for the same key and any approval it was given.
+//
+// Parameters:
+//   - key: Identifies the operation, e.g. the tool and its arguments
+//   - description: What the operation does, shown to the approver
+func (a *Approvals) Request(key, description string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.requests[key] = approvalRequest{description: description, requestedAt: time.Now()}
+}
+
+// Approve approves every pending request. It is called on a human decision only,
+// never by tools.
+//
+// Returns:
+//   - []string: Descriptions of the approved operations, sorted
+func (a *Approvals) Approve() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var approved []string
+	for key, request := range a.requests {
+		if !request.approved {
+			request.approved = true
+			a.requests[key] = request
+			approved = append(approved, request.description)
+		}
+	}
+	sort.Strings(approved)
+	return approved
+}
+
+// Deny drops every pending request, so the operations must be requested again.
+//
+// Returns:
+//   - []string: Descriptions of the denied operations, sorted
+func (a *Approvals) Deny() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var denied []string
+	for key, request := range a.requests {
+		if !request.approved {
+			delete(a.requests, key)
+			denied = append(denied, request.description)
+		}
+	}
+	sort.Strings(denied)
+	return denied
+}
+
+// Take uses the approval of an operation requested within the window. An
+// approved request is removed, so each approval allows the operation once.
+//
+// Parameters:
+//   - key: Operation key passed to Request
+//   - window: How long a request stays valid
+//
+// Returns:
+//   - requested: Whether the operation was requested within the window
+//   - approved: Whether a human approved it; the operation may then go ahead
+func (a *Approvals) Take(key string, window time.Duration) (requested, approved bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	request, ok := a.requests[key]
+	if !ok {
+		return false, false
+	}
+	if time.Since(request.requestedAt) > window {
+		delete(a.requests, key)
+		return false, false
+	}
+	if request.approved {
+		delete(a.requests, key)
+	}
+	return true, request.approved
+}
+
+// WithApprovals returns a copy of the parent context carrying the approvals of
+// the session the execution belongs to.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - approvals: Approvals of the session
+//
+// Returns:
+//   - context.Context: Derived context carrying the approvals
+func WithApprovals(ctx context.Context, approvals *Approvals) context.Context {
+	return context.WithValue(ctx, approvalsKey{}, approvals)
+}
+
+// ApprovalsFromContext returns the approvals attached with WithApprovals.
+//
+// Parameters:
+//   - ctx: Context to inspect
+//
+// Returns:
+//   - *Approvals: The session's approvals, or nil if none are attached
+func ApprovalsFromContext(ctx context.Context) *Approvals {
+	approvals, _ := ctx.Value(approvalsKey{}).(*Approvals)
+	return approvals
+}
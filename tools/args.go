@@ -0,0 +1,52 @@
+/*
+Package tools provides structured argument parsing shared by the Skynet Agent tools.
+
+Tools historically split their input on whitespace, which breaks on filenames
+containing spaces and on multi-line content. Tools that need reliable arguments
+accept a JSON object as Action Input instead, for example:
+
+	{"operation": "write", "path": "/etc/motd", "content": "line one\nline two"}
+
+Plain string input is still accepted for backward compatibility; JSON is only
+used when the input is a JSON object.
+*/
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// parseJSONArgs decodes the input into args when it is a JSON object.
+// Surrounding whitespace and Markdown code fences added by the model are ignored.
+//
+// Parameters:
+//   - input: Raw tool input from the agent
+//   - args: Pointer to the tool's argument struct
+//
+// Returns:
+//   - bool: Whether the input was a JSON object (false means use legacy parsing)
+//   - error: Decoding error when the input looked like JSON but was invalid
+func parseJSONArgs(input string, args interface{}) (bool, error) {
+	trimmed := strings.TrimSpace(input)
+
+	// Models sometimes wrap JSON in ```json fences
+	if strings.HasPrefix(trimmed, "```") {
+		trimmed = strings.TrimPrefix(trimmed, "```json")
+		trimmed = strings.TrimPrefix(trimmed, "```")
+		trimmed = strings.TrimSuffix(trimmed, "```")
+		trimmed = strings.TrimSpace(trimmed)
+	}
+
+	if !strings.HasPrefix(trimmed, "{") {
+		return false, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(args); err != nil {
+		return true, fmt.Errorf("invalid JSON arguments: %w", err)
+	}
+	return true, nil
+}
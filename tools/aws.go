@@ -0,0 +1,198 @@
+/*
+Package tools provides AWS cloud resource inspection and management for the Skynet Agent.
+
+This file implements the AwsTool, which wraps the aws CLI so the agent can inspect
+cloud resources such as EC2 instances, S3 buckets, and CloudWatch logs during
+incident response.
+
+A profile and region can be configured; they are added to every command that does
+not pass --profile or --region itself. In read-only mode only operations that
+cannot change resources are allowed: describe-*, list-*, get-*, "s3 ls", "logs tail",
+"logs filter-log-events", and "sts get-caller-identity".
+
+Input is an aws command without the leading "aws", for example:
+
+	ec2 describe-instances --filters Name=instance-state-name,Values=running
+	s3 ls s3://backups/
+	logs tail /aws/lambda/api --since 30m
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// awsLogger provides structured logging for all AWS operations
+// with a consistent tool identifier for easy filtering and monitoring
+var awsLogger = logrus.WithField("tool", "aws")
+
+// awsReadOnlyPrefixes are the operation name prefixes that never modify resources
+var awsReadOnlyPrefixes = []string{"describe-", "list-", "get-"}
+
+// awsReadOnlyOperations are additional "service operation" pairs that never modify resources
+var awsReadOnlyOperations = map[string]bool{
+	"s3 ls":                  true,
+	"logs tail":              true,
+	"logs filter-log-events": true,
+}
+
+// AwsTool wraps the aws CLI with optional profile and region defaults and a read-only mode.
+type AwsTool struct {
+	profile  string // Named profile passed to the aws CLI; empty uses the CLI's default
+	region   string // Region passed to the aws CLI; empty uses the profile's region
+	readOnly bool   // Whether only operations that cannot change resources are allowed
+}
+
+// NewAwsTool creates a new instance of the AWS tool.
+// The tool requires the aws CLI to be installed and credentials to be available to it.
+//
+// Parameters:
+//   - profile: Named profile; empty uses AWS_PROFILE or the default profile
+//   - region: Region; empty uses AWS_REGION or the profile's region
+//   - readOnly: Restrict the tool to describe, list, and get operations
+//
+// Returns:
+//   - *AwsTool: Configured AWS tool ready for use
+func NewAwsTool(profile, region string, readOnly bool) *AwsTool {
+	awsLogger.WithFields(logrus.Fields{
+		"profile":  profile,
+		"region":   region,
+		"readOnly": readOnly,
+	}).Debug("Initializing aws tool")
+	return &AwsTool{
+		profile:  profile,
+		region:   region,
+		readOnly: readOnly,
+	}
+}
+
+// Description returns a description of the AWS tool's capabilities.
+// The description reflects whether the tool is in read-only mode.
+//
+// Returns:
+//   - string: Description of the supported AWS operations
+func (a *AwsTool) Description() string {
+	description := "Inspect AWS cloud resources with the aws CLI. Input is an aws command without 'aws', e.g. 'ec2 describe-instances --instance-ids i-0abc', 's3 ls s3://bucket/prefix/', 'logs tail <log group> --since 30m', 'cloudwatch get-metric-statistics ...', 'sts get-caller-identity' (which account and role are in use). Output is JSON unless --output is given."
+	if a.readOnly {
+		description += " The tool is read-only: only describe-*, list-*, get-*, 's3 ls', and 'logs tail' or 'logs filter-log-events' are allowed."
+	} else {
+		description += " Operations that change resources (e.g. 'ec2 start-instances', 's3 cp') are also available."
+	}
+	if a.region != "" {
+		description += fmt.Sprintf(" Commands use region %s unless --region is given.", a.region)
+	}
+	return description
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("aws")
+func (a *AwsTool) Name() string {
+	return "aws"
+}
+
+// Call executes an aws CLI command based on the provided input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: aws command string (e.g., "ec2 describe-instances", "s3 ls")
+//
+// Returns:
+//   - string: Output of the aws command or error message
+//   - error: Always nil (errors are returned as string messages)
+func (a *AwsTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := awsLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Aws tool called")
+	startTime := time.Now()
+
+	// Parse the input command, tolerating a leading "aws"
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) > 0 && parts[0] == "aws" {
+		parts = parts[1:]
+	}
+	if len(parts) < 2 {
+		toolLogger.Warn("Incomplete aws command provided")
+		return "Error: Please provide an aws service and operation, e.g. 'ec2 describe-instances'", nil
+	}
+
+	service, operation := strings.ToLower(parts[0]), strings.ToLower(parts[1])
+	if a.readOnly && !awsReadOnly(service, operation) {
+		toolLogger.WithFields(logrus.Fields{
+			"service":   service,
+			"operation": operation,
+		}).Warn("Aws write operation rejected in read-only mode")
+		return fmt.Sprintf("Error: aws %s %s is not allowed, the aws tool is read-only", service, operation), nil
+	}
+
+	args := append([]string{}, parts...)
+	if a.profile != "" && !hasOption(parts, "--profile") {
+		args = append(args, "--profile", a.profile)
+	}
+	if a.region != "" && !hasOption(parts, "--region") {
+		args = append(args, "--region", a.region)
+	}
+	args = append(args, "--no-cli-pager")
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "aws", args...)
+	cmd.Env = sessionEnviron(ctx)
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"service":   service,
+			"operation": operation,
+			"output":    string(output),
+		}).Error("Aws command failed")
+
+		if _, lookErr := exec.LookPath("aws"); lookErr != nil {
+			return "Error: the aws CLI is not installed or not accessible", nil
+		}
+		return fmt.Sprintf("Error: aws %s %s failed: %s", service, operation, strings.TrimSpace(string(output))), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"service":       service,
+		"operation":     operation,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Aws command completed")
+
+	if len(output) == 0 {
+		return fmt.Sprintf("aws %s %s completed with no output", service, operation), nil
+	}
+	return string(output), nil
+}
+
+// awsReadOnly reports whether an aws service operation cannot change resources.
+func awsReadOnly(service, operation string) bool {
+	if awsReadOnlyOperations[service+" "+operation] {
+		return true
+	}
+	for _, prefix := range awsReadOnlyPrefixes {
+		if strings.HasPrefix(operation, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOption reports whether command arguments contain an option, either separate or as --option=value.
+func hasOption(args []string, option string) bool {
+	for _, arg := range args {
+		if arg == option || strings.HasPrefix(arg, option+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// Ensure AwsTool implements the tools.Tool interface
+var _ tools.Tool = (*AwsTool)(nil)
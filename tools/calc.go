@@ -0,0 +1,528 @@
+/*
+Package tools provides deterministic calculations for the Skynet Agent.
+
+This file implements the CalcTool, which evaluates arithmetic expressions,
+converts between units, and does date arithmetic, so capacity planning numbers
+come from a calculation instead of mental math in the agent's reasoning.
+
+Supported inputs:
+  - <expression>: Arithmetic with + - * / % ^, parentheses, the constants pi and e,
+    and the functions sqrt, abs, ceil, floor, round, log (natural), log2, log10,
+    min, and max, e.g. "ceil(1200 / 64) * 1.25"
+  - convert <value> <unit> to <unit>: Data sizes (B, KB, MB, GB, TB, PB and KiB
+    to PiB, bits b to Pb), data rates (bps to Gbps, B/s to GB/s, KiB/s to GiB/s),
+    and durations (ms, s, min, h, d, w), e.g. "convert 2.5 TiB to GB"
+  - date <date> +|- <duration>: Add to or subtract from a date, e.g. "date today + 90d"
+  - days <date> <date>: Time between two dates, e.g. "days 2024-01-01 2024-03-01"
+
+Dates are "now", "today", YYYY-MM-DD, "YYYY-MM-DD HH:MM", or RFC 3339. Durations
+combine numbers with the units w, d, h, m, s, e.g. "1w2d" or "36h".
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// calcLogger provides structured logging for all calculations
+// with a consistent tool identifier for easy filtering and monitoring
+var calcLogger = logrus.WithField("tool", "calc")
+
+// calcUnit describes a unit by its category and its factor to the category's base unit
+type calcUnit struct {
+	category string
+	factor   float64
+}
+
+// calcUnits maps unit names to categories and factors (bytes, bytes per second, seconds)
+var calcUnits = map[string]calcUnit{
+	"b": {"data", 1.0 / 8}, "kb": {"data", 1e3}, "mb": {"data", 1e6}, "gb": {"data", 1e9}, "tb": {"data", 1e12}, "pb": {"data", 1e15},
+	"B": {"data", 1}, "KB": {"data", 1e3}, "MB": {"data", 1e6}, "GB": {"data", 1e9}, "TB": {"data", 1e12}, "PB": {"data", 1e15},
+	"Kb": {"data", 1e3 / 8}, "Mb": {"data", 1e6 / 8}, "Gb": {"data", 1e9 / 8}, "Tb": {"data", 1e12 / 8}, "Pb": {"data", 1e15 / 8},
+	"KiB": {"data", 1 << 10}, "MiB": {"data", 1 << 20}, "GiB": {"data", 1 << 30}, "TiB": {"data", 1 << 40}, "PiB": {"data", 1 << 50},
+
+	"bps": {"rate", 1.0 / 8}, "Kbps": {"rate", 1e3 / 8}, "Mbps": {"rate", 1e6 / 8}, "Gbps": {"rate", 1e9 / 8},
+	"B/s": {"rate", 1}, "KB/s": {"rate", 1e3}, "MB/s": {"rate", 1e6}, "GB/s": {"rate", 1e9},
+	"KiB/s": {"rate", 1 << 10}, "MiB/s": {"rate", 1 << 20}, "GiB/s": {"rate", 1 << 30},
+
+	"ms": {"time", 1e-3}, "s": {"time", 1}, "sec": {"time", 1}, "min": {"time", 60},
+	"h": {"time", 3600}, "hour": {"time", 3600}, "hours": {"time", 3600},
+	"d": {"time", 86400}, "day": {"time", 86400}, "days": {"time", 86400},
+	"w": {"time", 604800}, "week": {"time", 604800}, "weeks": {"time", 604800},
+}
+
+// calcConvertPattern matches "convert <value> <unit> to <unit>"
+var calcConvertPattern = regexp.MustCompile(`^convert\s+([0-9.eE+\-]+)\s*(\S+)\s+(?:to|in)\s+(\S+)$`)
+
+// calcDurationPattern matches one component of a duration such as "2d" or "1.5h"
+var calcDurationPattern = regexp.MustCompile(`([0-9.]+)\s*(w|d|h|m|s)`)
+
+// calcDateLayouts are the accepted date formats, tried in order
+var calcDateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// CalcTool evaluates arithmetic, converts units, and does date arithmetic.
+type CalcTool struct{}
+
+// NewCalcTool creates a new instance of the calculator tool.
+//
+// Returns:
+//   - *CalcTool: Configured calculator tool ready for use
+func NewCalcTool() *CalcTool {
+	calcLogger.Debug("Initializing calc tool")
+	return &CalcTool{}
+}
+
+// Description returns a description of the calculator's inputs.
+//
+// Returns:
+//   - string: Description of the supported calculations
+func (c *CalcTool) Description() string {
+	return "Calculate exactly instead of estimating; use this for any capacity, sizing, or date math. Usage: '<expression>' (+ - * / % ^, parentheses, pi, e, sqrt, abs, ceil, floor, round, log, log2, log10, min, max; e.g. 'ceil(1200 / 64) * 1.25'), 'convert <value> <unit> to <unit>' (data sizes B/KB/MB/GB/TB and KiB/MiB/GiB/TiB, bits, rates Mbps/Gbps/MB/s/MiB/s, durations ms/s/min/h/d/w; e.g. 'convert 2.5 TiB to GB', 'convert 1 Gbps to MB/s'), 'date <date> + <duration>' or 'date <date> - <duration>' (e.g. 'date today + 90d', durations like 1w2d or 36h), 'days <date> <date>' (time between dates). Dates: now, today, YYYY-MM-DD, 'YYYY-MM-DD HH:MM', RFC 3339."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("calc")
+func (c *CalcTool) Name() string {
+	return "calc"
+}
+
+// Call performs the requested calculation.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Expression, conversion, or date calculation
+//
+// Returns:
+//   - string: Calculation result or error message
+//   - error: Always nil (errors are returned as string messages)
+func (c *CalcTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := calcLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Calc tool called")
+	startTime := time.Now()
+
+	expression := strings.TrimSpace(input)
+	if expression == "" {
+		return "Error: Please provide an expression, conversion, or date calculation", nil
+	}
+
+	var result string
+	var err error
+	lower := strings.ToLower(expression)
+	switch {
+	case strings.HasPrefix(lower, "convert "):
+		result, err = calcConvert(expression)
+	case strings.HasPrefix(lower, "date "):
+		result, err = calcDate(strings.TrimSpace(expression[len("date "):]))
+	case strings.HasPrefix(lower, "days "):
+		result, err = calcDays(strings.Fields(expression[len("days "):]))
+	default:
+		var value float64
+		value, err = evaluateExpression(expression)
+		if err == nil {
+			result = fmt.Sprintf("%s = %s", expression, formatNumber(value))
+		}
+	}
+	if err != nil {
+		toolLogger.WithError(err).Warn("Calculation failed")
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+
+	toolLogger.WithField("executionTime", time.Since(startTime)).Info("Calculation completed")
+	return result, nil
+}
+
+// calcConvert converts a value between two units of the same category.
+func calcConvert(input string) (string, error) {
+	match := calcConvertPattern.FindStringSubmatch(input)
+	if match == nil {
+		return "", fmt.Errorf("usage: convert <value> <unit> to <unit>, e.g. convert 500 GiB to GB")
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid value %q", match[1])
+	}
+	from, ok := lookupUnit(match[2])
+	if !ok {
+		return "", fmt.Errorf("unknown unit %q", match[2])
+	}
+	to, ok := lookupUnit(match[3])
+	if !ok {
+		return "", fmt.Errorf("unknown unit %q", match[3])
+	}
+	if from.category != to.category {
+		return "", fmt.Errorf("cannot convert %s (%s) to %s (%s)", match[2], from.category, match[3], to.category)
+	}
+	converted := value * from.factor / to.factor
+	return fmt.Sprintf("%s %s = %s %s", match[1], match[2], formatNumber(converted), match[3]), nil
+}
+
+// lookupUnit finds a unit by its exact name, then by a case-insensitive match for unambiguous units.
+func lookupUnit(name string) (calcUnit, bool) {
+	if unit, ok := calcUnits[name]; ok {
+		return unit, true
+	}
+	// Byte and bit units differ only in case, so only time units are matched case-insensitively
+	if unit, ok := calcUnits[strings.ToLower(name)]; ok && unit.category == "time" {
+		return unit, true
+	}
+	return calcUnit{}, false
+}
+
+// calcDate adds a duration to or subtracts it from a date.
+func calcDate(input string) (string, error) {
+	index := strings.LastIndexAny(input, "+-")
+	// A "-" inside YYYY-MM-DD is not an operator; the operator is surrounded by spaces
+	for index > 0 && input[index-1] != ' ' {
+		index = strings.LastIndexAny(input[:index], "+-")
+	}
+	if index <= 0 {
+		return "", fmt.Errorf("usage: date <date> + <duration> or date <date> - <duration>, e.g. date today + 90d")
+	}
+
+	start, err := parseCalcDate(strings.TrimSpace(input[:index]))
+	if err != nil {
+		return "", err
+	}
+	duration, err := parseCalcDuration(strings.TrimSpace(input[index+1:]))
+	if err != nil {
+		return "", err
+	}
+	if input[index] == '-' {
+		duration = -duration
+	}
+
+	result := start.Add(duration)
+	return fmt.Sprintf("%s (%s)", result.Format("2006-01-02 15:04:05 MST"), result.Weekday()), nil
+}
+
+// calcDays reports the time between two dates.
+func calcDays(args []string) (string, error) {
+	// Dates with a time of day span two fields
+	var dates []string
+	for i := 0; i < len(args); i++ {
+		if i+1 < len(args) && strings.Contains(args[i+1], ":") && !strings.Contains(args[i], "T") {
+			dates = append(dates, args[i]+" "+args[i+1])
+			i++
+			continue
+		}
+		dates = append(dates, args[i])
+	}
+	if len(dates) != 2 {
+		return "", fmt.Errorf("usage: days <date> <date>, e.g. days 2024-01-01 2024-03-01")
+	}
+
+	from, err := parseCalcDate(dates[0])
+	if err != nil {
+		return "", err
+	}
+	to, err := parseCalcDate(dates[1])
+	if err != nil {
+		return "", err
+	}
+	between := to.Sub(from)
+	return fmt.Sprintf("%s days (%s hours, %s weeks) from %s to %s", formatNumber(between.Hours()/24), formatNumber(between.Hours()), formatNumber(between.Hours()/168), dates[0], dates[1]), nil
+}
+
+// parseCalcDate parses now, today, or a date in one of the accepted layouts.
+func parseCalcDate(value string) (time.Time, error) {
+	now := time.Now()
+	switch strings.ToLower(value) {
+	case "now":
+		return now, nil
+	case "today":
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), nil
+	}
+	for _, layout := range calcDateLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, use now, today, YYYY-MM-DD, 'YYYY-MM-DD HH:MM', or RFC 3339", value)
+}
+
+// parseCalcDuration parses durations such as "90d", "1w2d", or "36h 30m".
+func parseCalcDuration(value string) (time.Duration, error) {
+	compact := strings.ReplaceAll(strings.ToLower(value), " ", "")
+	matches := calcDurationPattern.FindAllStringSubmatch(compact, -1)
+	if len(matches) == 0 || len(strings.Join(calcDurationPattern.FindAllString(compact, -1), "")) != len(compact) {
+		return 0, fmt.Errorf("invalid duration %q, use numbers with w, d, h, m, s, e.g. 90d or 1w2d", value)
+	}
+
+	var total time.Duration
+	for _, match := range matches {
+		amount, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		unit := map[string]time.Duration{"w": 7 * 24 * time.Hour, "d": 24 * time.Hour, "h": time.Hour, "m": time.Minute, "s": time.Second}[match[2]]
+		total += time.Duration(amount * float64(unit))
+	}
+	return total, nil
+}
+
+// formatNumber formats a result without float noise, switching to exponent form for extreme magnitudes.
+func formatNumber(value float64) string {
+	rounded := math.Round(value*1e9) / 1e9
+	if rounded != 0 && (math.Abs(rounded) >= 1e15 || math.Abs(rounded) < 1e-6) {
+		return strconv.FormatFloat(value, 'g', 10, 64)
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// evaluateExpression evaluates an arithmetic expression.
+func evaluateExpression(expression string) (float64, error) {
+	parser := &exprParser{input: expression}
+	value, err := parser.parseExpression()
+	if err != nil {
+		return 0, err
+	}
+	parser.skipSpaces()
+	if parser.pos < len(parser.input) {
+		return 0, fmt.Errorf("unexpected %q at position %d", parser.input[parser.pos:], parser.pos+1)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("the result is not a finite number (division by zero or invalid operation)")
+	}
+	return value, nil
+}
+
+// exprParser is a recursive descent parser for arithmetic expressions.
+type exprParser struct {
+	input string
+	pos   int
+}
+
+// skipSpaces advances past whitespace.
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// consume advances past the given operator when it is next.
+func (p *exprParser) consume(op byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.input) && p.input[p.pos] == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseExpression parses additions and subtractions.
+func (p *exprParser) parseExpression() (float64, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch {
+		case p.consume('+'):
+			right, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			left += right
+		case p.consume('-'):
+			right, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			left -= right
+		default:
+			return left, nil
+		}
+	}
+}
+
+// parseTerm parses multiplications, divisions, and remainders.
+func (p *exprParser) parseTerm() (float64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch {
+		case p.consume('*'):
+			right, err := p.parseUnary()
+			if err != nil {
+				return 0, err
+			}
+			left *= right
+		case p.consume('/'):
+			right, err := p.parseUnary()
+			if err != nil {
+				return 0, err
+			}
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			left /= right
+		case p.consume('%'):
+			right, err := p.parseUnary()
+			if err != nil {
+				return 0, err
+			}
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			left = math.Mod(left, right)
+		default:
+			return left, nil
+		}
+	}
+}
+
+// parseUnary parses a leading sign; -2^2 is -(2^2).
+func (p *exprParser) parseUnary() (float64, error) {
+	if p.consume('-') {
+		value, err := p.parseUnary()
+		return -value, err
+	}
+	if p.consume('+') {
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+// parsePower parses right-associative exponentiation.
+func (p *exprParser) parsePower() (float64, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return 0, err
+	}
+	if p.consume('^') {
+		exponent, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(base, exponent), nil
+	}
+	return base, nil
+}
+
+// parsePrimary parses numbers, constants, function calls, and parenthesized expressions.
+func (p *exprParser) parsePrimary() (float64, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0, fmt.Errorf("unexpected end of expression")
+	}
+
+	if p.consume('(') {
+		value, err := p.parseExpression()
+		if err != nil {
+			return 0, err
+		}
+		if !p.consume(')') {
+			return 0, fmt.Errorf("missing closing parenthesis")
+		}
+		return value, nil
+	}
+
+	start := p.pos
+	ch := p.input[p.pos]
+	switch {
+	case ch >= '0' && ch <= '9' || ch == '.':
+		for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.' || p.input[p.pos] == '_') {
+			p.pos++
+		}
+		// Exponent notation such as 1e6 or 2.5E-3
+		if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+			next := p.pos + 1
+			if next < len(p.input) && (p.input[next] == '+' || p.input[next] == '-') {
+				next++
+			}
+			if next < len(p.input) && p.input[next] >= '0' && p.input[next] <= '9' {
+				p.pos = next
+				for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+					p.pos++
+				}
+			}
+		}
+		value, err := strconv.ParseFloat(strings.ReplaceAll(p.input[start:p.pos], "_", ""), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+		}
+		return value, nil
+
+	case unicode.IsLetter(rune(ch)):
+		for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos]))) {
+			p.pos++
+		}
+		name := strings.ToLower(p.input[start:p.pos])
+		switch name {
+		case "pi":
+			return math.Pi, nil
+		case "e":
+			return math.E, nil
+		}
+		return p.parseCall(name)
+	}
+	return 0, fmt.Errorf("unexpected %q at position %d", string(ch), p.pos+1)
+}
+
+// parseCall parses the arguments of a function call and applies the function.
+func (p *exprParser) parseCall(name string) (float64, error) {
+	if !p.consume('(') {
+		return 0, fmt.Errorf("unknown name %q", name)
+	}
+	var args []float64
+	if !p.consume(')') {
+		for {
+			value, err := p.parseExpression()
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, value)
+			if p.consume(')') {
+				break
+			}
+			if !p.consume(',') {
+				return 0, fmt.Errorf("expected ',' or ')' in call to %s", name)
+			}
+		}
+	}
+
+	unary := map[string]func(float64) float64{
+		"sqrt": math.Sqrt, "abs": math.Abs, "ceil": math.Ceil, "floor": math.Floor,
+		"round": math.Round, "log": math.Log, "log2": math.Log2, "log10": math.Log10,
+	}
+	if fn, ok := unary[name]; ok {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("%s takes one argument", name)
+		}
+		return fn(args[0]), nil
+	}
+
+	switch name {
+	case "min", "max":
+		if len(args) == 0 {
+			return 0, fmt.Errorf("%s takes at least one argument", name)
+		}
+		result := args[0]
+		for _, arg := range args[1:] {
+			if name == "min" {
+				result = math.Min(result, arg)
+			} else {
+				result = math.Max(result, arg)
+			}
+		}
+		return result, nil
+	}
+	return 0, fmt.Errorf("unknown function %q", name)
+}
+
+// Ensure CalcTool implements the tools.Tool interface
+var _ tools.Tool = (*CalcTool)(nil)
@@ -0,0 +1,224 @@
+/*
+Package tools provides bounded packet capture capabilities for the Skynet Agent.
+
+This file implements the CaptureTool, which runs tcpdump with a packet count and
+time limit so a capture can never run unattended. Packets are written to a pcap
+file in the captures directory of the agent's working directory, for later
+analysis with Wireshark or tcpdump -r, and a text summary of the captured
+packets is returned to the agent.
+
+Input is a JSON object, for example:
+
+	{"interface": "eth0", "filter": "tcp port 443 and host 10.0.0.5", "count": 200, "duration": 15}
+*/
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// captureLogger provides structured logging for all packet captures
+// with a consistent tool identifier for easy filtering and monitoring
+var captureLogger = logrus.WithField("tool", "capture")
+
+// Capture limits; requests above the maximums are clamped
+const (
+	captureDefaultCount    = 100
+	captureMaxCount        = 10000
+	captureDefaultDuration = 10 * time.Second
+	captureMaxDuration     = 120 * time.Second
+	captureSummaryLines    = 50
+)
+
+// captureDir is the directory below the working directory where pcap files are saved
+const captureDir = "captures"
+
+// captureArgs holds the structured arguments of a packet capture.
+type captureArgs struct {
+	Interface string `json:"interface,omitempty"` // Interface to capture on (default: any)
+	Filter    string `json:"filter,omitempty"`    // BPF filter expression, e.g. "tcp port 80"
+	Count     int    `json:"count,omitempty"`     // Maximum packets to capture (default: 100)
+	Duration  int    `json:"duration,omitempty"`  // Maximum capture time in seconds (default: 10)
+}
+
+// CaptureTool runs bounded tcpdump captures and summarizes the result.
+type CaptureTool struct {
+	workspace *WorkspaceContext // Shared workspace providing the working directory where captures are saved
+}
+
+// NewCaptureTool creates a new instance of the packet capture tool.
+//
+// Parameters:
+//   - workspace: Shared workspace providing the working directory where captures are saved
+//
+// Returns:
+//   - *CaptureTool: Configured capture tool ready for use
+func NewCaptureTool(workspace *WorkspaceContext) *CaptureTool {
+	captureLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing capture tool")
+	return &CaptureTool{workspace: workspace}
+}
+
+// Description returns a description of the capture tool's options and input format.
+//
+// Returns:
+//   - string: Description of the supported capture options
+func (c *CaptureTool) Description() string {
+	return fmt.Sprintf("Capture network packets with tcpdump for debugging traffic. Input is a JSON object: {\"interface\": \"eth0\", \"filter\": \"<BPF filter, e.g. tcp port 443 and host 10.0.0.5>\", \"count\": <packets, default %d, max %d>, \"duration\": <seconds, default %d, max %d>}; all fields are optional. The capture stops at whichever limit is reached first, is saved as a pcap file under %s/ in the working directory, and a summary of the packets is returned.",
+		captureDefaultCount, captureMaxCount, int(captureDefaultDuration.Seconds()), int(captureMaxDuration.Seconds()), captureDir)
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (c *CaptureTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"interface":{"type":"string"},"filter":{"type":"string"},"count":{"type":"integer"},"duration":{"type":"integer"}}}`)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("capture")
+func (c *CaptureTool) Name() string {
+	return "capture"
+}
+
+// Call runs a bounded capture and returns its summary.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON capture options, or a bare BPF filter
+//
+// Returns:
+//   - string: Capture file path and packet summary, or an error message
+//   - error: Always nil (errors are returned as string messages)
+func (c *CaptureTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := captureLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Capture tool called")
+	startTime := time.Now()
+
+	var args captureArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid capture arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+	if !isJSON {
+		args.Filter = strings.TrimSpace(input)
+	}
+
+	if _, err := exec.LookPath("tcpdump"); err != nil {
+		return "Error: tcpdump is not installed or not accessible", nil
+	}
+
+	iface := args.Interface
+	if iface == "" {
+		iface = "any"
+	}
+	if strings.HasPrefix(iface, "-") {
+		return fmt.Sprintf("Error: Invalid interface %q", iface), nil
+	}
+	count := args.Count
+	if count <= 0 {
+		count = captureDefaultCount
+	}
+	if count > captureMaxCount {
+		count = captureMaxCount
+	}
+	duration := captureDefaultDuration
+	if args.Duration > 0 {
+		duration = time.Duration(args.Duration) * time.Second
+	}
+	if duration > captureMaxDuration {
+		duration = captureMaxDuration
+	}
+
+	dir := filepath.Join(c.workspace.For(ctx).Dir(), captureDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Sprintf("Error: Failed to create capture directory: %v", err), nil
+	}
+	path := filepath.Join(dir, fmt.Sprintf("capture_%s.pcap", time.Now().Format("20060102_150405")))
+
+	// Interrupt rather than kill tcpdump at the time limit so the pcap file is flushed
+	captureCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	cmdArgs := []string{"-i", iface, "-c", strconv.Itoa(count), "-w", path, "-U"}
+	if args.Filter != "" {
+		cmdArgs = append(cmdArgs, "--")
+		cmdArgs = append(cmdArgs, strings.Fields(args.Filter)...)
+	}
+	cmd := targetCommand(captureCtx, "tcpdump", cmdArgs...)
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 5 * time.Second
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	timedOut := captureCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+	if err != nil && !timedOut {
+		toolLogger.WithError(err).WithField("stderr", stderr.String()).Error("Tcpdump capture failed")
+		os.Remove(path)
+		return fmt.Sprintf("Error: tcpdump failed: %s", strings.TrimSpace(stderr.String())), nil
+	}
+
+	summary, packets, err := summarizeCapture(ctx, path)
+	if err != nil {
+		toolLogger.WithError(err).WithField("path", path).Error("Failed to read capture file")
+		return fmt.Sprintf("Error: Capture saved to %s but could not be read: %v", path, err), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"interface":     iface,
+		"filter":        args.Filter,
+		"packets":       packets,
+		"path":          path,
+		"executionTime": time.Since(startTime),
+	}).Info("Packet capture completed")
+
+	stopReason := fmt.Sprintf("reached %d packets", count)
+	if timedOut {
+		stopReason = fmt.Sprintf("reached the %s time limit", duration)
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Captured %d packets on %s (%s). Saved to %s\n", packets, iface, stopReason, path)
+	if packets > captureSummaryLines {
+		fmt.Fprintf(&result, "First %d packets:\n", captureSummaryLines)
+	}
+	result.WriteString(summary)
+	return strings.TrimRight(result.String(), "\n"), nil
+}
+
+// summarizeCapture reads a pcap file back and returns the first packet lines and the packet count.
+func summarizeCapture(ctx context.Context, path string) (string, int, error) {
+	cmd := targetCommand(ctx, "tcpdump", "-nn", "-r", path)
+	output, err := limitedOutput(ctx, cmd)
+	if err != nil && len(output) == 0 {
+		return "", 0, err
+	}
+
+	var summary strings.Builder
+	packets := 0
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		packets++
+		if packets <= captureSummaryLines {
+			summary.WriteString(scanner.Text() + "\n")
+		}
+	}
+	return summary.String(), packets, nil
+}
+
+// Ensure CaptureTool implements the tools.Tool interface
+var _ tools.Tool = (*CaptureTool)(nil)
@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -14,12 +13,12 @@ import (
 var catLogger = logrus.WithField("tool", "cat")
 
 type CatTool struct {
-	workingDir *string
+	workspace *WorkspaceContext
 }
 
-func NewCatTool(workingDir *string) *CatTool {
-	catLogger.WithField("workingDir", *workingDir).Debug("Initializing cat tool")
-	return &CatTool{workingDir: workingDir}
+func NewCatTool(workspace *WorkspaceContext) *CatTool {
+	catLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing cat tool")
+	return &CatTool{workspace: workspace}
 }
 
 func (c *CatTool) Description() string {
@@ -31,9 +30,9 @@ func (c *CatTool) Name() string {
 }
 
 func (c *CatTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := catLogger.WithFields(logrus.Fields{
+	toolLogger := catLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"input":      input,
-		"workingDir": *c.workingDir,
+		"workingDir": c.workspace.For(ctx).Dir(),
 	})
 
 	toolLogger.Info("Cat tool called")
@@ -46,13 +45,13 @@ func (c *CatTool) Call(ctx context.Context, input string) (string, error) {
 	}
 
 	// Handle relative paths
-	if !filepath.IsAbs(targetPath) && c.workingDir != nil {
-		targetPath = filepath.Join(*c.workingDir, targetPath)
+	if !filepath.IsAbs(targetPath) && c.workspace != nil {
+		targetPath = filepath.Join(c.workspace.For(ctx).Dir(), targetPath)
 	}
 
 	// Execute cat command
-	cmd := exec.CommandContext(ctx, "cat", targetPath)
-	output, err := cmd.CombinedOutput()
+	cmd := targetCommand(ctx, "cat", targetPath)
+	output, err := limitedCombinedOutput(ctx, cmd)
 
 	if err != nil {
 		toolLogger.WithError(err).WithField("targetPath", targetPath).Error("cat command failed")
@@ -14,12 +14,12 @@ import (
 var cdLogger = logrus.WithField("tool", "cd")
 
 type CdTool struct {
-	workingDir *string
+	workspace *WorkspaceContext
 }
 
-func NewCdTool(workingDir *string) *CdTool {
-	cdLogger.WithField("workingDir", *workingDir).Debug("Initializing cd tool")
-	return &CdTool{workingDir: workingDir}
+func NewCdTool(workspace *WorkspaceContext) *CdTool {
+	cdLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing cd tool")
+	return &CdTool{workspace: workspace}
 }
 
 func (c *CdTool) Description() string {
@@ -31,15 +31,19 @@ func (c *CdTool) Name() string {
 }
 
 func (c *CdTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := cdLogger.WithFields(logrus.Fields{
+	toolLogger := cdLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"input":      input,
-		"workingDir": *c.workingDir,
+		"workingDir": c.workspace.For(ctx).Dir(),
 	})
 	toolLogger.Info("CD tool called")
 	startTime := time.Now()
 
 	targetPath := strings.TrimSpace(input)
 
+	if !isLocalTarget(ctx) {
+		return c.changeOnTarget(ctx, targetPath, toolLogger), nil
+	}
+
 	// Handle empty input (go to home directory)
 	if targetPath == "" {
 		if homeDir, err := os.UserHomeDir(); err == nil {
@@ -51,7 +55,7 @@ func (c *CdTool) Call(ctx context.Context, input string) (string, error) {
 
 	// Resolve relative paths
 	if !filepath.IsAbs(targetPath) {
-		targetPath = filepath.Join(*c.workingDir, targetPath)
+		targetPath = filepath.Join(c.workspace.For(ctx).Dir(), targetPath)
 	}
 
 	// Clean the path
@@ -70,7 +74,7 @@ func (c *CdTool) Call(ctx context.Context, input string) (string, error) {
 	}
 
 	// Update working directory
-	*c.workingDir = targetPath
+	c.workspace.For(ctx).SetDir(targetPath)
 
 	executionTime := time.Since(startTime)
 	toolLogger.WithFields(logrus.Fields{
@@ -81,4 +85,26 @@ func (c *CdTool) Call(ctx context.Context, input string) (string, error) {
 	return "Changed directory to: " + targetPath, nil
 }
 
+// changeOnTarget changes the working directory to a directory of a remote
+// target, resolved there, where an empty path is the home directory.
+func (c *CdTool) changeOnTarget(ctx context.Context, targetPath string, toolLogger *logrus.Entry) string {
+	if targetPath != "" && !filepath.IsAbs(targetPath) {
+		targetPath = filepath.Join(c.workspace.For(ctx).Dir(), targetPath)
+	}
+	cmd := targetCommand(ctx, "sh", "-c", `cd "${1:-$HOME}" && pwd -P`, "sh", targetPath)
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithField("targetPath", targetPath).Error("Directory does not exist on target")
+		return "Error: Directory does not exist: " + targetPath
+	}
+
+	resolved := strings.TrimSpace(string(output))
+	c.workspace.For(ctx).SetDir(resolved)
+	toolLogger.WithFields(logrus.Fields{
+		"newWorkingDir": resolved,
+		"target":        TargetFromContext(ctx).String(),
+	}).Info("Directory changed on target")
+	return "Changed directory to: " + resolved
+}
+
 var _ tools.Tool = (*CdTool)(nil)
@@ -0,0 +1,263 @@
+/*
+Package tools provides TLS certificate management through certbot for the Skynet Agent.
+
+This file implements the CertbotTool, which lists certificates with their expiry
+dates, renews them, and issues new ones, so expired or failing TLS renewals can be
+fixed end to end.
+
+Issuance is gated in two steps: a request without "confirm" only runs a dry run
+against the ACME staging environment and asks for the user's approval. Only a
+confirmed request with the same arguments, made within 30 minutes of a
+successful dry run that a human then approved (see approval.go), issues the real
+certificate. The agent cannot approve issuance itself, so a human stays in the
+loop, and Let's Encrypt rate limits are not burned on requests that would fail.
+
+Input is a JSON object, for example:
+
+	{"operation": "list"}
+	{"operation": "renew", "certName": "example.com", "dryRun": true}
+	{"operation": "issue", "domains": ["example.com", "www.example.com"], "email": "ops@example.com", "method": "webroot", "webroot": "/var/www/html"}
+	{"operation": "issue", "domains": ["example.com", "www.example.com"], "email": "ops@example.com", "method": "webroot", "webroot": "/var/www/html", "confirm": true}
+*/
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// certbotLogger provides structured logging for all certificate operations
+// with a consistent tool identifier for easy filtering and monitoring
+var certbotLogger = logrus.WithField("tool", "certbot")
+
+// certbotApprovalWindow is how long a successful dry run allows an approved issuance
+const certbotApprovalWindow = 30 * time.Minute
+
+// certbotDomainPattern matches domain names, including wildcards
+var certbotDomainPattern = regexp.MustCompile(`^(\*\.)?([A-Za-z0-9]([A-Za-z0-9\-]{0,61}[A-Za-z0-9])?\.)+[A-Za-z]{2,}$`)
+
+// certbotMethods maps issuance methods to their certbot plugin flags
+var certbotMethods = map[string]string{
+	"webroot":    "--webroot",
+	"standalone": "--standalone",
+	"nginx":      "--nginx",
+}
+
+// certbotArgs holds the structured arguments of a certificate operation.
+type certbotArgs struct {
+	Operation string   `json:"operation"`          // list, renew, or issue
+	CertName  string   `json:"certName,omitempty"` // Certificate to renew (default: all due)
+	DryRun    bool     `json:"dryRun,omitempty"`   // Renew against the staging environment only
+	Domains   []string `json:"domains,omitempty"`  // Domains of the certificate to issue
+	Email     string   `json:"email,omitempty"`    // Account email for expiry notices
+	Method    string   `json:"method,omitempty"`   // webroot, standalone, or nginx (default: webroot)
+	Webroot   string   `json:"webroot,omitempty"`  // Document root for the webroot method
+	Confirm   bool     `json:"confirm,omitempty"`  // Issue for real after a dry run approved by the user
+}
+
+// CertbotTool lists, renews, and issues TLS certificates with certbot.
+type CertbotTool struct{}
+
+// NewCertbotTool creates a new instance of the certbot tool.
+// The tool requires certbot to be installed and accessible in the system PATH.
+//
+// Returns:
+//   - *CertbotTool: Configured certbot tool ready for use
+func NewCertbotTool() *CertbotTool {
+	certbotLogger.Debug("Initializing certbot tool")
+	return &CertbotTool{}
+}
+
+// Description returns a description of the certbot tool's operations and input format.
+//
+// Returns:
+//   - string: Description of the supported certificate operations
+func (c *CertbotTool) Description() string {
+	return "Manage TLS certificates with certbot (Let's Encrypt). Input is a JSON object: {\"operation\": \"list\"} (certificates, domains, and expiry), {\"operation\": \"renew\", \"certName\": \"<name>\", \"dryRun\": true} (renew certificates that are due, or test renewal with dryRun), {\"operation\": \"issue\", \"domains\": [\"example.com\"], \"email\": \"<email>\", \"method\": \"webroot|standalone|nginx\", \"webroot\": \"/var/www/html\"}. Issuing first runs a dry run only; if it succeeds, ask the user to approve the issuance and, once they have, repeat the same request with \"confirm\": true to issue the real certificate. You cannot approve it yourself."
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (c *CertbotTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"operation":{"type":"string","enum":["list","renew","issue"]},"certName":{"type":"string"},"dryRun":{"type":"boolean"},"domains":{"type":"array","items":{"type":"string"}},"email":{"type":"string"},"method":{"type":"string","enum":["webroot","standalone","nginx"]},"webroot":{"type":"string"},"confirm":{"type":"boolean"}},"required":["operation"]}`)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("certbot")
+func (c *CertbotTool) Name() string {
+	return "certbot"
+}
+
+// Call performs the certificate operation described by the input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON operation object, or a plain operation name such as "list"
+//
+// Returns:
+//   - string: certbot output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (c *CertbotTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := certbotLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Certbot tool called")
+	startTime := time.Now()
+
+	var args certbotArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid certbot arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+	if !isJSON {
+		args.Operation = strings.TrimSpace(input)
+	}
+
+	operation := strings.ToLower(args.Operation)
+	var result string
+	switch operation {
+	case "list", "certificates":
+		result = c.run(ctx, "certificates")
+	case "renew":
+		cmdArgs := []string{"renew", "--non-interactive"}
+		if args.CertName != "" {
+			cmdArgs = append(cmdArgs, "--cert-name", args.CertName)
+		}
+		if args.DryRun {
+			cmdArgs = append(cmdArgs, "--dry-run")
+		}
+		result = c.run(ctx, cmdArgs...)
+	case "issue":
+		result = c.issue(ctx, args)
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use list, renew, or issue", args.Operation), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"executionTime": time.Since(startTime),
+	}).Info("Certbot operation completed")
+
+	return result, nil
+}
+
+// issue runs an issuance dry run, or the real issuance when it is confirmed after a dry run the user approved.
+func (c *CertbotTool) issue(ctx context.Context, args certbotArgs) string {
+	if len(args.Domains) == 0 {
+		return "Error: Please provide the domains of the certificate"
+	}
+	for _, domain := range args.Domains {
+		if !certbotDomainPattern.MatchString(domain) {
+			return fmt.Sprintf("Error: Invalid domain %q", domain)
+		}
+	}
+
+	method := strings.ToLower(args.Method)
+	if method == "" {
+		method = "webroot"
+	}
+	flag, ok := certbotMethods[method]
+	if !ok {
+		return fmt.Sprintf("Error: Unsupported method %q. Use webroot, standalone, or nginx", args.Method)
+	}
+
+	cmdArgs := []string{"certonly", "--non-interactive", "--agree-tos", flag}
+	if method == "webroot" {
+		if args.Webroot == "" {
+			return "Error: The webroot method requires the webroot directory served for the domains"
+		}
+		cmdArgs = append(cmdArgs, "-w", args.Webroot)
+	}
+	for _, domain := range args.Domains {
+		cmdArgs = append(cmdArgs, "-d", domain)
+	}
+	if args.Email != "" {
+		cmdArgs = append(cmdArgs, "--email", args.Email)
+	} else {
+		cmdArgs = append(cmdArgs, "--register-unsafely-without-email")
+	}
+
+	// Issuance needs a human decision, recorded in the session's approvals
+	approvals := ApprovalsFromContext(ctx)
+	if approvals == nil {
+		return "Error: Certificate issuance needs a chat session whose user can approve it"
+	}
+	key := fmt.Sprintf("certbot issue %s %s %s %s", certbotDomainKey(args.Domains), method, args.Webroot, args.Email)
+	domains := strings.Join(args.Domains, ", ")
+	if !args.Confirm {
+		output, err := c.exec(ctx, append(cmdArgs, "--dry-run")...)
+		if err != nil {
+			return fmt.Sprintf("Error: Dry run failed, fix the problem before issuing:\n%s", output)
+		}
+		approvals.Request(key, "Issue a TLS certificate for "+domains)
+		return fmt.Sprintf("Dry run succeeded for %s:\n%s\n\nNo certificate was issued. Ask the user to approve the issuance with the Approve button in Slack, the approval reaction in Matrix, or POST /sessions/:sessionId/approve. Once they have, repeat the request with \"confirm\": true within %s.", domains, output, certbotApprovalWindow)
+	}
+
+	requested, approved := approvals.Take(key, certbotApprovalWindow)
+	if !requested {
+		certbotLogger.WithField("domains", args.Domains).Warn("Certificate issuance rejected without a recent successful dry run")
+		return fmt.Sprintf("Error: No successful dry run for %s with these arguments in the last %s. Run the request without confirm first", domains, certbotApprovalWindow)
+	}
+	if !approved {
+		certbotLogger.WithField("domains", args.Domains).Warn("Certificate issuance rejected without the user's approval")
+		return fmt.Sprintf("Error: The user has not approved the issuance for %s. Ask them to approve it; you cannot approve it yourself", domains)
+	}
+
+	certbotLogger.WithField("domains", args.Domains).Warn("Issuing certificate")
+	output, err := c.exec(ctx, cmdArgs...)
+	if err != nil {
+		return fmt.Sprintf("Error: Issuance failed:\n%s", output)
+	}
+	return output
+}
+
+// run executes certbot and formats failures as error messages.
+func (c *CertbotTool) run(ctx context.Context, args ...string) string {
+	output, err := c.exec(ctx, args...)
+	if err != nil {
+		return fmt.Sprintf("Error: certbot %s failed: %s", args[0], output)
+	}
+	if output == "" {
+		return fmt.Sprintf("certbot %s completed with no output", args[0])
+	}
+	return output
+}
+
+// exec runs certbot and returns its trimmed combined output.
+func (c *CertbotTool) exec(ctx context.Context, args ...string) (string, error) {
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "certbot", args...)
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		certbotLogger.WithError(err).WithFields(logrus.Fields{
+			"command": args[0],
+			"output":  string(output),
+		}).Error("Certbot command failed")
+		if _, lookErr := exec.LookPath("certbot"); lookErr != nil {
+			return "certbot is not installed or not accessible", err
+		}
+	}
+	return strings.TrimSpace(string(output)), err
+}
+
+// certbotDomainKey identifies a set of domains regardless of order and case.
+func certbotDomainKey(domains []string) string {
+	normalized := make([]string, len(domains))
+	for i, domain := range domains {
+		normalized[i] = strings.ToLower(domain)
+	}
+	sort.Strings(normalized)
+	return strings.Join(normalized, ",")
+}
+
+// Ensure CertbotTool implements the tools.Tool interface
+var _ tools.Tool = (*CertbotTool)(nil)
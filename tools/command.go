@@ -0,0 +1,139 @@
+/*
+Package tools provides operator-defined command tools for the Skynet Agent.
+
+This file implements the CommandTool, which exposes a single binary to the agent
+with a fixed set of leading arguments and an optional allowlist of subcommands.
+It lets site-specific utilities become agent tools without writing Go, while
+keeping the agent restricted to the subcommands the operator has approved.
+
+Commands are executed directly without a shell, so shell metacharacters in the
+agent's input are passed to the binary as literal arguments.
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// commandLogger provides structured logging for all operator-defined command tools
+var commandLogger = logrus.WithField("tool", "command")
+
+// CommandTool runs a configured binary with fixed arguments followed by the agent's input.
+type CommandTool struct {
+	name        string            // Tool identifier exposed to the agent
+	description string            // Usage description exposed to the agent
+	binary      string            // Executable name or path
+	args        []string          // Fixed arguments placed before the agent's input
+	subcommands map[string]bool   // Allowed first arguments; empty allows any input
+	workspace   *WorkspaceContext // Shared workspace providing the working directory
+}
+
+// NewCommandTool creates a tool that runs the given binary.
+//
+// Parameters:
+//   - name: Tool identifier exposed to the agent
+//   - description: Usage description exposed to the agent
+//   - binary: Executable name or path
+//   - args: Fixed arguments placed before the agent's input
+//   - subcommands: Allowed subcommands; empty allows any input
+//   - workspace: Shared workspace providing the working directory
+//
+// Returns:
+//   - *CommandTool: Configured tool ready for use
+func NewCommandTool(name, description, binary string, args, subcommands []string, workspace *WorkspaceContext) *CommandTool {
+	commandLogger.WithFields(logrus.Fields{
+		"name":   name,
+		"binary": binary,
+	}).Debug("Initializing command tool")
+
+	allowed := make(map[string]bool, len(subcommands))
+	for _, subcommand := range subcommands {
+		allowed[subcommand] = true
+	}
+
+	return &CommandTool{
+		name:        name,
+		description: description,
+		binary:      binary,
+		args:        args,
+		subcommands: allowed,
+		workspace:   workspace,
+	}
+}
+
+// Description returns the operator-provided usage description, including the
+// allowed subcommands so the agent does not attempt disallowed ones.
+func (t *CommandTool) Description() string {
+	if len(t.subcommands) == 0 {
+		return t.description
+	}
+
+	allowed := make([]string, 0, len(t.subcommands))
+	for subcommand := range t.subcommands {
+		allowed = append(allowed, subcommand)
+	}
+	sort.Strings(allowed)
+	return fmt.Sprintf("%s Allowed subcommands: %s.", t.description, strings.Join(allowed, ", "))
+}
+
+// Name returns the operator-provided tool identifier.
+func (t *CommandTool) Name() string {
+	return t.name
+}
+
+// Call validates the subcommand against the allowlist and runs the binary.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Arguments appended after the fixed arguments
+//
+// Returns:
+//   - string: Combined command output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (t *CommandTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := commandLogger.WithContext(ctx).WithFields(logrus.Fields{
+		"name":  t.name,
+		"input": input,
+	})
+	toolLogger.Info("Command tool called")
+	startTime := time.Now()
+
+	inputArgs := strings.Fields(strings.TrimSpace(input))
+
+	if len(t.subcommands) > 0 {
+		if len(inputArgs) == 0 {
+			return fmt.Sprintf("Error: Please provide a subcommand for %s", t.name), nil
+		}
+		if !t.subcommands[inputArgs[0]] {
+			toolLogger.WithField("subcommand", inputArgs[0]).Warn("Subcommand not allowed")
+			return fmt.Sprintf("Error: Subcommand '%s' is not allowed for %s", inputArgs[0], t.name), nil
+		}
+	}
+
+	args := append(append([]string{}, t.args...), inputArgs...)
+	cmd := targetCommand(ctx, t.binary, args...)
+	cmd.Dir = t.workspace.For(ctx).Dir()
+	cmd.Env = t.workspace.For(ctx).Environ()
+
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).Error("Command tool failed")
+		return fmt.Sprintf("%s\nError: %v", string(output), err), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Command tool completed")
+
+	return string(output), nil
+}
+
+var _ tools.Tool = (*CommandTool)(nil)
@@ -0,0 +1,369 @@
+/*
+Package tools provides scheduled job management for the Skynet Agent.
+
+This file implements the CronTool, which lists, adds, and removes crontab entries
+without an interactive editor: the current crontab is read with "crontab -l",
+changed in memory, and installed again with "crontab -". On Alpine, jobs can
+also be placed in the /etc/periodic/{15min,hourly,daily,weekly,monthly}
+directories that the default root crontab runs with run-parts.
+
+Input is a JSON object, for example:
+
+	{"operation": "add", "schedule": "0 3 * * *", "command": "/usr/local/bin/backup.sh"}
+	{"operation": "add", "periodic": "daily", "name": "cleanup-tmp", "command": "find /tmp -mtime +7 -delete"}
+	{"operation": "remove", "entry": "0 3 * * * /usr/local/bin/backup.sh"}
+*/
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// cronLogger provides structured logging for all cron operations
+// with a consistent tool identifier for easy filtering and monitoring
+var cronLogger = logrus.WithField("tool", "cron")
+
+// cronPeriodicDir is the Alpine directory of run-parts job directories
+const cronPeriodicDir = "/etc/periodic"
+
+// cronPeriodicIntervals are the run-parts directories scheduled by Alpine's root crontab
+var cronPeriodicIntervals = []string{"15min", "hourly", "daily", "weekly", "monthly"}
+
+// cronPeriodicName restricts periodic script names to what run-parts executes (no dots)
+var cronPeriodicName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// cronFieldPattern matches a single crontab time field
+var cronFieldPattern = regexp.MustCompile(`^[0-9A-Za-z*/,\-]+$`)
+
+// cronMacros are the schedule shortcuts supported by cron implementations
+var cronMacros = map[string]bool{
+	"@reboot": true, "@yearly": true, "@annually": true, "@monthly": true,
+	"@weekly": true, "@daily": true, "@midnight": true, "@hourly": true,
+}
+
+// cronArgs holds the structured arguments of a cron operation.
+type cronArgs struct {
+	Operation string `json:"operation"`          // list, add, or remove
+	Schedule  string `json:"schedule,omitempty"` // Crontab schedule, e.g. "*/5 * * * *" or "@daily"
+	Command   string `json:"command,omitempty"`  // Command run by the job
+	Entry     string `json:"entry,omitempty"`    // Full crontab line to remove, as shown by list
+	User      string `json:"user,omitempty"`     // Crontab owner (default: current user)
+	Periodic  string `json:"periodic,omitempty"` // Alpine periodic interval: 15min, hourly, daily, weekly, monthly
+	Name      string `json:"name,omitempty"`     // Script name for periodic jobs
+}
+
+// CronTool manages crontab entries and Alpine periodic jobs.
+type CronTool struct{}
+
+// NewCronTool creates a new instance of the cron management tool.
+//
+// Returns:
+//   - *CronTool: Configured cron tool ready for use
+func NewCronTool() *CronTool {
+	cronLogger.Debug("Initializing cron tool")
+	return &CronTool{}
+}
+
+// Description returns a description of the cron tool's operations and input format.
+//
+// Returns:
+//   - string: Description of the supported cron operations
+func (c *CronTool) Description() string {
+	return "Manage scheduled jobs. Input is a JSON object: {\"operation\": \"list\"} (show crontab entries and /etc/periodic jobs), {\"operation\": \"add\", \"schedule\": \"0 3 * * *\", \"command\": \"<command>\"} (add a crontab entry), {\"operation\": \"remove\", \"entry\": \"<full crontab line as listed>\"} (remove a crontab entry). Optional \"user\" selects another user's crontab. On Alpine, {\"operation\": \"add|remove\", \"periodic\": \"15min|hourly|daily|weekly|monthly\", \"name\": \"<script-name>\", \"command\": \"<command>\"} manages scripts in /etc/periodic. Plain 'list' is also accepted."
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (c *CronTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"operation":{"type":"string","enum":["list","add","remove"]},"schedule":{"type":"string"},"command":{"type":"string"},"entry":{"type":"string"},"user":{"type":"string"},"periodic":{"type":"string","enum":["15min","hourly","daily","weekly","monthly"]},"name":{"type":"string"}},"required":["operation"]}`)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("cron")
+func (c *CronTool) Name() string {
+	return "cron"
+}
+
+// Call performs the cron operation described by the input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON operation object, or "list"
+//
+// Returns:
+//   - string: Result of the operation or error message
+//   - error: Always nil (errors are returned as string messages)
+func (c *CronTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := cronLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Cron tool called")
+	startTime := time.Now()
+
+	var args cronArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid cron arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+	if !isJSON {
+		args.Operation = strings.TrimSpace(input)
+	}
+
+	var result string
+	switch strings.ToLower(args.Operation) {
+	case "list", "":
+		result = c.list(ctx, args.User)
+	case "add":
+		if args.Periodic != "" {
+			result = localOnly(ctx, "Periodic jobs")
+			if result == "" {
+				result = c.addPeriodic(args)
+			}
+		} else {
+			result = c.add(ctx, args)
+		}
+	case "remove":
+		if args.Periodic != "" {
+			result = localOnly(ctx, "Periodic jobs")
+			if result == "" {
+				result = c.removePeriodic(args)
+			}
+		} else {
+			result = c.remove(ctx, args)
+		}
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use list, add, or remove", args.Operation), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     args.Operation,
+		"executionTime": time.Since(startTime),
+	}).Info("Cron operation completed")
+
+	return result, nil
+}
+
+// list shows the crontab entries and the Alpine periodic jobs.
+func (c *CronTool) list(ctx context.Context, user string) string {
+	var result strings.Builder
+
+	entries, err := c.readCrontab(ctx, user)
+	if err != nil {
+		fmt.Fprintf(&result, "Error reading crontab: %v\n", err)
+	} else if len(entries) == 0 {
+		result.WriteString("Crontab: no entries\n")
+	} else {
+		result.WriteString("Crontab:\n")
+		for _, line := range entries {
+			result.WriteString("  " + line + "\n")
+		}
+	}
+
+	// Periodic scripts are listed only for this host
+	for _, interval := range cronPeriodicIntervals {
+		if !isLocalTarget(ctx) {
+			break
+		}
+		scripts, err := os.ReadDir(filepath.Join(cronPeriodicDir, interval))
+		if err != nil {
+			continue
+		}
+		for _, script := range scripts {
+			if !script.IsDir() {
+				fmt.Fprintf(&result, "Periodic (%s): %s\n", interval, filepath.Join(cronPeriodicDir, interval, script.Name()))
+			}
+		}
+	}
+
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// add appends a validated entry to the crontab unless it is already present.
+func (c *CronTool) add(ctx context.Context, args cronArgs) string {
+	if err := validateCronSchedule(args.Schedule); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	command := strings.TrimSpace(args.Command)
+	if command == "" || strings.ContainsAny(command, "\r\n") {
+		return "Error: Please provide a single-line command"
+	}
+
+	entry := strings.Join(strings.Fields(args.Schedule), " ") + " " + command
+	lines, err := c.readCrontabLines(ctx, args.User)
+	if err != nil {
+		return fmt.Sprintf("Error: Failed to read crontab: %v", err)
+	}
+	for _, line := range lines {
+		if strings.TrimSpace(line) == entry {
+			return fmt.Sprintf("Crontab entry already exists: %s", entry)
+		}
+	}
+
+	if err := c.writeCrontab(ctx, args.User, append(lines, entry)); err != nil {
+		cronLogger.WithError(err).Error("Failed to install crontab")
+		return fmt.Sprintf("Error: Failed to install crontab: %v", err)
+	}
+	return fmt.Sprintf("Added crontab entry: %s", entry)
+}
+
+// remove deletes every crontab line equal to the given entry.
+func (c *CronTool) remove(ctx context.Context, args cronArgs) string {
+	entry := strings.TrimSpace(args.Entry)
+	if entry == "" {
+		return "Error: Please provide the full crontab entry to remove, as shown by list"
+	}
+
+	lines, err := c.readCrontabLines(ctx, args.User)
+	if err != nil {
+		return fmt.Sprintf("Error: Failed to read crontab: %v", err)
+	}
+
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.Join(strings.Fields(line), " ") != strings.Join(strings.Fields(entry), " ") {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == len(lines) {
+		return fmt.Sprintf("Error: No crontab entry matches %q", entry)
+	}
+
+	if err := c.writeCrontab(ctx, args.User, kept); err != nil {
+		cronLogger.WithError(err).Error("Failed to install crontab")
+		return fmt.Sprintf("Error: Failed to install crontab: %v", err)
+	}
+	return fmt.Sprintf("Removed crontab entry: %s", entry)
+}
+
+// periodicPath validates the interval and name of a periodic job and returns its script path.
+func periodicPath(args cronArgs) (string, error) {
+	valid := false
+	for _, interval := range cronPeriodicIntervals {
+		if args.Periodic == interval {
+			valid = true
+		}
+	}
+	if !valid {
+		return "", fmt.Errorf("invalid periodic interval %q, use one of %s", args.Periodic, strings.Join(cronPeriodicIntervals, ", "))
+	}
+	if !cronPeriodicName.MatchString(args.Name) {
+		return "", fmt.Errorf("invalid script name %q, use letters, digits, '-' and '_' only", args.Name)
+	}
+	return filepath.Join(cronPeriodicDir, args.Periodic, args.Name), nil
+}
+
+// addPeriodic writes an executable script into an /etc/periodic directory.
+func (c *CronTool) addPeriodic(args cronArgs) string {
+	path, err := periodicPath(args)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if strings.TrimSpace(args.Command) == "" {
+		return "Error: Please provide a command"
+	}
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		return fmt.Sprintf("Error: %s does not exist; periodic jobs require Alpine's default crontab", filepath.Dir(path))
+	}
+
+	script := "#!/bin/sh\n" + strings.TrimSpace(args.Command) + "\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		cronLogger.WithError(err).WithField("path", path).Error("Failed to write periodic script")
+		return fmt.Sprintf("Error: Failed to write %s: %v", path, err)
+	}
+	return fmt.Sprintf("Added %s job: %s", args.Periodic, path)
+}
+
+// removePeriodic deletes a script from an /etc/periodic directory.
+func (c *CronTool) removePeriodic(args cronArgs) string {
+	path, err := periodicPath(args)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Sprintf("Error: Failed to remove %s: %v", path, err)
+	}
+	return fmt.Sprintf("Removed %s job: %s", args.Periodic, path)
+}
+
+// crontabArgs returns the crontab arguments selecting the given user.
+func crontabArgs(user string, args ...string) []string {
+	if user != "" {
+		return append([]string{"-u", user}, args...)
+	}
+	return args
+}
+
+// readCrontabLines returns all lines of the crontab, including comments.
+// A missing crontab yields no lines.
+func (c *CronTool) readCrontabLines(ctx context.Context, user string) ([]string, error) {
+	cmd := targetCommand(ctx, "crontab", crontabArgs(user, "-l")...)
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		if strings.Contains(strings.ToLower(string(output)), "no crontab") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	text := strings.TrimRight(string(output), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// readCrontab returns the crontab entries without comments and blank lines.
+func (c *CronTool) readCrontab(ctx context.Context, user string) ([]string, error) {
+	lines, err := c.readCrontabLines(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			entries = append(entries, trimmed)
+		}
+	}
+	return entries, nil
+}
+
+// writeCrontab installs the given lines as the crontab.
+func (c *CronTool) writeCrontab(ctx context.Context, user string, lines []string) error {
+	cmd := targetCommand(ctx, "crontab", crontabArgs(user, "-")...)
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// validateCronSchedule checks that a schedule is a macro or has five time fields.
+func validateCronSchedule(schedule string) error {
+	fields := strings.Fields(schedule)
+	if len(fields) == 1 && cronMacros[fields[0]] {
+		return nil
+	}
+	if len(fields) != 5 {
+		return fmt.Errorf("invalid schedule %q, expected five fields (minute hour day month weekday) or a macro such as @daily", schedule)
+	}
+	for _, field := range fields {
+		if !cronFieldPattern.MatchString(field) {
+			return fmt.Errorf("invalid schedule field %q", field)
+		}
+	}
+	return nil
+}
+
+// Ensure CronTool implements the tools.Tool interface
+var _ tools.Tool = (*CronTool)(nil)
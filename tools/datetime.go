@@ -28,7 +28,7 @@ func (d *DateTimeTool) Name() string {
 }
 
 func (d *DateTimeTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := datetimeLogger.WithField("input", input)
+	toolLogger := datetimeLogger.WithContext(ctx).WithField("input", input)
 	toolLogger.Info("DateTime tool called")
 	startTime := time.Now()
 
@@ -43,17 +43,17 @@ func (d *DateTimeTool) Call(ctx context.Context, input string) (string, error) {
 	switch parts[0] {
 	case "date":
 		if len(parts) > 1 {
-			cmd = exec.CommandContext(ctx, "date", parts[1:]...)
+			cmd = targetCommand(ctx, "date", parts[1:]...)
 		} else {
-			cmd = exec.CommandContext(ctx, "date")
+			cmd = targetCommand(ctx, "date")
 		}
 	case "timedatectl":
-		cmd = exec.CommandContext(ctx, "timedatectl")
+		cmd = targetCommand(ctx, "timedatectl")
 	default:
-		cmd = exec.CommandContext(ctx, "date")
+		cmd = targetCommand(ctx, "date")
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := limitedCombinedOutput(ctx, cmd)
 	if err != nil {
 		toolLogger.WithError(err).WithField("command", command).Error("DateTime command failed")
 		return string(output), nil
@@ -0,0 +1,195 @@
+/*
+Package tools provides kernel log inspection capabilities for the Skynet Agent.
+
+This file implements the DmesgTool, which reads the kernel ring buffer with level
+filtering and a limit on the number of most recent lines. Lines reporting
+out-of-memory kills, hardware and I/O errors, and process crashes are marked and
+counted, so the agent can correlate service failures with kernel events.
+
+The raw "dmesg -r" output is parsed by the tool itself, which works with both
+util-linux and busybox dmesg.
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// dmesgLogger provides structured logging for all kernel log queries
+// with a consistent tool identifier for easy filtering and monitoring
+var dmesgLogger = logrus.WithField("tool", "dmesg")
+
+// Line count bounds for kernel log queries
+const (
+	dmesgDefaultLines = 100
+	dmesgMaxLines     = 2000
+)
+
+// dmesgLevels are the kernel log level names indexed by level number
+var dmesgLevels = []string{"emerg", "alert", "crit", "err", "warn", "notice", "info", "debug"}
+
+// dmesgRawLine matches a raw line: "<facility*8+level>[timestamp] message"
+var dmesgRawLine = regexp.MustCompile(`^<(\d+)>(.*)$`)
+
+// dmesgHighlight is a category of notable kernel events
+type dmesgHighlight struct {
+	label   string         // Marker shown in front of matching lines
+	pattern *regexp.Regexp // Pattern identifying the event
+}
+
+// dmesgHighlights are checked in order; a line is marked with the first matching category
+var dmesgHighlights = []dmesgHighlight{
+	{"OOM", regexp.MustCompile(`(?i)out of memory|oom-kill|oom_reaper|invoked oom-killer|Killed process \d+`)},
+	{"HARDWARE", regexp.MustCompile(`(?i)hardware error|machine check|\bmce\b|i/o error|ata\d+.*(error|failed)|medium error|EXT4-fs error|XFS.*(error|corruption)|nvme.*(timeout|error)|edac`)},
+	{"CRASH", regexp.MustCompile(`(?i)segfault|general protection|kernel bug|call trace|hung_task|blocked for more than|soft lockup|rcu_sched detected stall`)},
+}
+
+// DmesgTool reads and annotates the kernel ring buffer.
+type DmesgTool struct{}
+
+// NewDmesgTool creates a new instance of the kernel log tool.
+//
+// Returns:
+//   - *DmesgTool: Configured dmesg tool ready for use
+func NewDmesgTool() *DmesgTool {
+	dmesgLogger.Debug("Initializing dmesg tool")
+	return &DmesgTool{}
+}
+
+// Description returns a description of the dmesg tool's filters.
+//
+// Returns:
+//   - string: Description of the supported kernel log queries
+func (d *DmesgTool) Description() string {
+	return "Read the kernel log (dmesg) to correlate service crashes with kernel events. Usage: '[level] [lines]', e.g. 'err 50' (last 50 messages of level err or more severe), 'all 200', or empty for the last 100 messages. Levels: emerg, alert, crit, err, warn, notice, info, debug, all. Out-of-memory kills, hardware/I-O errors, and crashes are marked [OOM], [HARDWARE], and [CRASH] and counted."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("dmesg")
+func (d *DmesgTool) Name() string {
+	return "dmesg"
+}
+
+// Call reads the kernel log with the requested level and line limit.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Optional level and line count (e.g., "err 50")
+//
+// Returns:
+//   - string: Annotated kernel messages and event counts, or an error message
+//   - error: Always nil (errors are returned as string messages)
+func (d *DmesgTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := dmesgLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Dmesg tool called")
+	startTime := time.Now()
+
+	maxLevel := len(dmesgLevels) - 1
+	lines := dmesgDefaultLines
+	for _, part := range strings.Fields(strings.ToLower(input)) {
+		if n, err := strconv.Atoi(part); err == nil {
+			lines = n
+			continue
+		}
+		level := dmesgLevelIndex(part)
+		if level < 0 {
+			return fmt.Sprintf("Error: Unknown level %q. Use emerg, alert, crit, err, warn, notice, info, debug, or all", part), nil
+		}
+		maxLevel = level
+	}
+	if lines <= 0 {
+		lines = dmesgDefaultLines
+	}
+	if lines > dmesgMaxLines {
+		lines = dmesgMaxLines
+	}
+
+	// Execute command; the caller's context bounds execution time
+	output, err := limitedCombinedOutput(ctx, targetCommand(ctx, "dmesg", "-r"))
+	if err != nil {
+		toolLogger.WithError(err).WithField("output", string(output)).Error("Dmesg command failed")
+		return fmt.Sprintf("Error: dmesg failed: %s", strings.TrimSpace(string(output))), nil
+	}
+
+	var selected []string
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		level, message := maxLevel, line
+		if match := dmesgRawLine.FindStringSubmatch(line); match != nil {
+			priority, _ := strconv.Atoi(match[1])
+			level, message = priority&7, match[2]
+		}
+		if level <= maxLevel && strings.TrimSpace(message) != "" {
+			selected = append(selected, fmt.Sprintf("%-6s %s", dmesgLevels[level], strings.TrimSpace(message)))
+		}
+	}
+	if len(selected) > lines {
+		selected = selected[len(selected)-lines:]
+	}
+	if len(selected) == 0 {
+		return fmt.Sprintf("No kernel messages at level %s or more severe", dmesgLevels[maxLevel]), nil
+	}
+
+	counts := make(map[string]int)
+	var result strings.Builder
+	for _, line := range selected {
+		for _, highlight := range dmesgHighlights {
+			if highlight.pattern.MatchString(line) {
+				counts[highlight.label]++
+				line = "[" + highlight.label + "] " + line
+				break
+			}
+		}
+		result.WriteString(line + "\n")
+	}
+
+	var notable []string
+	for _, highlight := range dmesgHighlights {
+		if counts[highlight.label] > 0 {
+			notable = append(notable, fmt.Sprintf("%s: %d", highlight.label, counts[highlight.label]))
+		}
+	}
+	if len(notable) > 0 {
+		result.WriteString("Notable events in these messages: " + strings.Join(notable, ", "))
+	} else {
+		result.WriteString("No OOM, hardware, or crash events in these messages")
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"maxLevel":      dmesgLevels[maxLevel],
+		"lines":         len(selected),
+		"executionTime": time.Since(startTime),
+	}).Info("Dmesg query completed")
+
+	return result.String(), nil
+}
+
+// dmesgLevelIndex returns the numeric level of a level name, or -1 if unknown.
+func dmesgLevelIndex(name string) int {
+	switch name {
+	case "all":
+		return len(dmesgLevels) - 1
+	case "error":
+		name = "err"
+	case "warning":
+		name = "warn"
+	}
+	for i, level := range dmesgLevels {
+		if level == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Ensure DmesgTool implements the tools.Tool interface
+var _ tools.Tool = (*DmesgTool)(nil)
@@ -82,7 +82,7 @@ func (d *DockerTool) Name() string {
 //   - string: Formatted result of the Docker operation or error message
 //   - error: Always nil (errors are returned as string messages)
 func (d *DockerTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := dockerLogger.WithField("input", input)
+	toolLogger := dockerLogger.WithContext(ctx).WithField("input", input)
 	toolLogger.Info("Docker tool called")
 	startTime := time.Now()
 
@@ -103,24 +103,18 @@ func (d *DockerTool) Call(ctx context.Context, input string) (string, error) {
 		return "Error: Docker is not installed or not accessible", nil
 	}
 
-	// Execute command with timeout to prevent hanging operations
-	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(cmdCtx, "docker", parts...)
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "docker", parts...)
+	cmd.Env = sessionEnviron(ctx)
 
 	// Execute the Docker command and capture output
-	output, err := cmd.CombinedOutput()
+	output, err := limitedCombinedOutput(ctx, cmd)
 	if err != nil {
 		toolLogger.WithError(err).WithFields(logrus.Fields{
 			"command": command,
 			"output":  string(output),
 		}).Error("Docker command failed")
 
-		// Handle timeout specifically
-		if cmdCtx.Err() == context.DeadlineExceeded {
-			return "Error: Docker command timed out after 30 seconds", nil
-		}
-
 		return string(output), nil
 	}
 
@@ -0,0 +1,288 @@
+/*
+Package tools provides environment, resource limit, and kernel parameter inspection for the Skynet Agent.
+
+This file implements the EnvTool, which answers questions such as "why is this
+environment variable not set for the service" and "what is the open files limit"
+by reading /proc directly, and reads and sets kernel parameters (sysctl) through
+/proc/sys.
+
+Environment values whose names suggest credentials (keys, tokens, passwords,
+secrets) are masked. Setting kernel parameters changes system-wide behavior and
+is refused unless sysctl writes are enabled in the configuration.
+
+Supported operations:
+- env [pid] [filter]: Environment variables of the agent or another process
+- limits [pid]: Resource limits (ulimits) of the agent or another process
+- sysctl <name or prefix>: Kernel parameter values, e.g. "vm.swappiness" or "net.ipv4.tcp"
+- sysctl <name>=<value>: Set a kernel parameter (when enabled)
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// envLogger provides structured logging for all environment inspection operations
+// with a consistent tool identifier for easy filtering and monitoring
+var envLogger = logrus.WithField("tool", "env")
+
+// envSecretPattern matches variable names whose values must not be shown
+var envSecretPattern = regexp.MustCompile(`(?i)(key|secret|token|passw|pwd|credential|private|auth|cookie|session)`)
+
+// sysctlNamePattern restricts kernel parameter names to dotted identifiers
+var sysctlNamePattern = regexp.MustCompile(`^[A-Za-z0-9_\-]+(\.[A-Za-z0-9_\-]+)*$`)
+
+// sysctlMaxResults caps the number of parameters listed for a prefix
+const sysctlMaxResults = 200
+
+// sysctlRoot is the procfs directory of kernel parameters
+const sysctlRoot = "/proc/sys"
+
+// EnvTool inspects environments, resource limits, and kernel parameters.
+type EnvTool struct {
+	sysctlWrite bool // Whether setting kernel parameters is allowed
+}
+
+// NewEnvTool creates a new instance of the environment inspection tool.
+//
+// Parameters:
+//   - sysctlWrite: Allow setting kernel parameters
+//
+// Returns:
+//   - *EnvTool: Configured env tool ready for use
+func NewEnvTool(sysctlWrite bool) *EnvTool {
+	envLogger.WithField("sysctlWrite", sysctlWrite).Debug("Initializing env tool")
+	return &EnvTool{sysctlWrite: sysctlWrite}
+}
+
+// Description returns a description of the env tool's operations.
+//
+// Returns:
+//   - string: Description of the supported operations
+func (e *EnvTool) Description() string {
+	description := "Inspect environment variables, resource limits, and kernel parameters. Usage: 'env [pid] [filter]' (environment of the agent or of a process, optionally only variables containing filter; secrets are masked), 'limits [pid]' (ulimits such as max open files), 'sysctl <name or prefix>' (kernel parameters, e.g. 'sysctl vm.swappiness' or 'sysctl net.core')."
+	if e.sysctlWrite {
+		description += " Set a kernel parameter with 'sysctl <name>=<value>' (not persistent across reboots)."
+	} else {
+		description += " Setting kernel parameters is disabled."
+	}
+	return description
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("env")
+func (e *EnvTool) Name() string {
+	return "env"
+}
+
+// Call performs the requested inspection or sysctl change.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Operation string (e.g., "env 1234 PATH", "limits", "sysctl vm.swappiness=10")
+//
+// Returns:
+//   - string: Requested information or error message
+//   - error: Always nil (errors are returned as string messages)
+func (e *EnvTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := envLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Env tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) == 0 {
+		return "Error: Please provide an operation: env, limits, or sysctl", nil
+	}
+
+	// Environments, limits, and kernel parameters are read from this host's /proc
+	if refusal := localOnly(ctx, "The env tool"); refusal != "" {
+		return refusal, nil
+	}
+
+	operation := strings.ToLower(parts[0])
+	var result string
+	switch operation {
+	case "env":
+		pid, rest := optionalPID(parts[1:])
+		filter := strings.Join(rest, " ")
+		result = e.environment(pid, filter)
+	case "limits", "ulimit":
+		pid, _ := optionalPID(parts[1:])
+		result = e.limits(pid)
+	case "sysctl":
+		if len(parts) < 2 {
+			return "Error: Please provide a kernel parameter name or prefix", nil
+		}
+		expression := strings.Join(parts[1:], "")
+		if name, value, isSet := strings.Cut(expression, "="); isSet {
+			result = e.setSysctl(name, value)
+		} else {
+			result = e.readSysctl(expression)
+		}
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use env, limits, or sysctl", operation), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"executionTime": time.Since(startTime),
+	}).Info("Env operation completed")
+
+	return result, nil
+}
+
+// optionalPID splits a leading PID argument from the remaining arguments; 0 means the agent itself.
+func optionalPID(args []string) (int, []string) {
+	if len(args) > 0 {
+		if pid, err := strconv.Atoi(args[0]); err == nil && pid > 0 {
+			return pid, args[1:]
+		}
+	}
+	return 0, args
+}
+
+// procDir returns the /proc directory of a process, or of the agent for pid 0.
+func procDir(pid int) string {
+	if pid == 0 {
+		return "/proc/self"
+	}
+	return filepath.Join("/proc", strconv.Itoa(pid))
+}
+
+// environment lists the environment of a process with secrets masked.
+func (e *EnvTool) environment(pid int, filter string) string {
+	data, err := os.ReadFile(filepath.Join(procDir(pid), "environ"))
+	if err != nil {
+		return fmt.Sprintf("Error: Cannot read environment of process %d: %v", pid, err)
+	}
+
+	var variables []string
+	for _, entry := range strings.Split(string(data), "\x00") {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			continue
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(name), strings.ToLower(filter)) {
+			continue
+		}
+		if envSecretPattern.MatchString(name) && value != "" {
+			value = "******** (masked)"
+		}
+		variables = append(variables, name+"="+value)
+	}
+	sort.Strings(variables)
+
+	if len(variables) == 0 {
+		if filter != "" {
+			return fmt.Sprintf("No environment variable matching %q is set", filter)
+		}
+		return "The environment is empty"
+	}
+	return strings.Join(variables, "\n")
+}
+
+// limits returns the resource limits of a process.
+func (e *EnvTool) limits(pid int) string {
+	data, err := os.ReadFile(filepath.Join(procDir(pid), "limits"))
+	if err != nil {
+		return fmt.Sprintf("Error: Cannot read limits of process %d: %v", pid, err)
+	}
+	return strings.TrimRight(string(data), "\n")
+}
+
+// sysctlPath converts a dotted kernel parameter name into its /proc/sys path.
+func sysctlPath(name string) (string, error) {
+	if !sysctlNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid kernel parameter name %q", name)
+	}
+	return filepath.Join(sysctlRoot, strings.ReplaceAll(name, ".", "/")), nil
+}
+
+// readSysctl returns a kernel parameter, or every parameter below a prefix.
+func (e *EnvTool) readSysctl(name string) string {
+	path, err := sysctlPath(name)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Sprintf("Error: Unknown kernel parameter %q", name)
+	}
+	if !info.IsDir() {
+		value, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Sprintf("Error: Cannot read %s: %v", name, err)
+		}
+		return fmt.Sprintf("%s = %s", name, strings.TrimSpace(string(value)))
+	}
+
+	var values []string
+	filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if len(values) >= sysctlMaxResults {
+			return filepath.SkipAll
+		}
+		value, readErr := os.ReadFile(current)
+		if readErr != nil {
+			// Write-only and permission-restricted parameters are skipped
+			return nil
+		}
+		key := strings.ReplaceAll(strings.TrimPrefix(current, sysctlRoot+"/"), "/", ".")
+		values = append(values, fmt.Sprintf("%s = %s", key, strings.Join(strings.Fields(string(value)), " ")))
+		return nil
+	})
+
+	if len(values) >= sysctlMaxResults {
+		values = append(values, fmt.Sprintf("... output limited to %d parameters, use a more specific prefix", sysctlMaxResults))
+	}
+	return strings.Join(values, "\n")
+}
+
+// setSysctl writes a kernel parameter when sysctl writes are enabled.
+func (e *EnvTool) setSysctl(name, value string) string {
+	if !e.sysctlWrite {
+		envLogger.WithField("name", name).Warn("Sysctl write rejected because writes are disabled")
+		return "Error: Setting kernel parameters is disabled (enable SYSCTL_WRITE_ENABLED)"
+	}
+
+	path, err := sysctlPath(name)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	previous, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Sprintf("Error: Unknown kernel parameter %q", name)
+	}
+
+	if err := os.WriteFile(path, []byte(value), 0644); err != nil {
+		envLogger.WithError(err).WithField("name", name).Error("Failed to set kernel parameter")
+		return fmt.Sprintf("Error: Failed to set %s: %v", name, err)
+	}
+
+	envLogger.WithFields(logrus.Fields{
+		"name":     name,
+		"previous": strings.TrimSpace(string(previous)),
+		"value":    value,
+	}).Warn("Kernel parameter changed")
+	return fmt.Sprintf("%s changed from %s to %s (add it to /etc/sysctl.conf to persist across reboots)", name, strings.TrimSpace(string(previous)), value)
+}
+
+// Ensure EnvTool implements the tools.Tool interface
+var _ tools.Tool = (*EnvTool)(nil)
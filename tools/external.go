@@ -0,0 +1,165 @@
+/*
+Package tools provides external-process tools for the Skynet Agent.
+
+This file implements the ExternalTool, which delegates tool calls to a separate
+executable speaking a simple JSON protocol over stdin/stdout. Each request is a
+single JSON object written to the executable's stdin, and the executable replies
+with a single JSON object on stdout before exiting.
+
+Describe request and response (sent once when the plugin is loaded):
+
+	{"method": "describe"}
+	{"name": "weather", "description": "Show the weather. Usage: '<city>'"}
+
+Call request and response:
+
+	{"method": "call", "input": "Berlin", "workingDir": "/root"}
+	{"output": "12°C, cloudy"}  or  {"error": "unknown city"}
+
+Plugins can be written in any language and are versioned independently of Skynet.
+*/
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// externalLogger provides structured logging for all external plugin tools
+var externalLogger = logrus.WithField("tool", "external")
+
+// externalRequest is the JSON message written to a plugin's stdin.
+type externalRequest struct {
+	Method     string `json:"method"`               // "describe" or "call"
+	Input      string `json:"input,omitempty"`      // Action Input provided by the agent
+	WorkingDir string `json:"workingDir,omitempty"` // Agent's current working directory
+}
+
+// externalResponse is the JSON message read from a plugin's stdout.
+type externalResponse struct {
+	Name        string `json:"name,omitempty"`        // Tool name (describe only)
+	Description string `json:"description,omitempty"` // Tool description (describe only)
+	Output      string `json:"output,omitempty"`      // Tool output (call only)
+	Error       string `json:"error,omitempty"`       // Error reported by the plugin
+}
+
+// ExternalTool delegates tool calls to an executable speaking the JSON plugin protocol.
+type ExternalTool struct {
+	path        string            // Path of the plugin executable
+	name        string            // Tool name reported by the plugin
+	description string            // Tool description reported by the plugin
+	workspace   *WorkspaceContext // Shared workspace providing the working directory
+}
+
+// NewExternalTool queries the executable for its name and description.
+//
+// Parameters:
+//   - ctx: Context bounding the describe request
+//   - path: Path of the plugin executable
+//   - workspace: Shared workspace providing the working directory
+//
+// Returns:
+//   - *ExternalTool: Tool ready for use
+//   - error: Any error running the executable or decoding its description
+func NewExternalTool(ctx context.Context, path string, workspace *WorkspaceContext) (*ExternalTool, error) {
+	response, err := runExternal(ctx, path, workspace.Dir(), externalRequest{Method: "describe"})
+	if err != nil {
+		return nil, err
+	}
+	if response.Name == "" || response.Description == "" {
+		return nil, fmt.Errorf("plugin %s did not report a name and description", path)
+	}
+
+	externalLogger.WithFields(logrus.Fields{
+		"name": response.Name,
+		"path": path,
+	}).Debug("Initializing external tool")
+	return &ExternalTool{
+		path:        path,
+		name:        response.Name,
+		description: response.Description,
+		workspace:   workspace,
+	}, nil
+}
+
+// runExternal executes the plugin with a single request and decodes its response.
+func runExternal(ctx context.Context, path, workingDir string, request externalRequest) (*externalResponse, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = workingDir
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %v: %s", path, err, stderr.String())
+	}
+
+	var response externalResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid JSON: %w", path, err)
+	}
+	return &response, nil
+}
+
+// Description returns the plugin-provided usage description.
+func (t *ExternalTool) Description() string {
+	return t.description
+}
+
+// Name returns the plugin-provided tool identifier.
+func (t *ExternalTool) Name() string {
+	return t.name
+}
+
+// Call sends the agent's input to the plugin and returns its output.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Action Input forwarded to the plugin
+//
+// Returns:
+//   - string: Plugin output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (t *ExternalTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := externalLogger.WithContext(ctx).WithFields(logrus.Fields{
+		"name":  t.name,
+		"input": input,
+	})
+	toolLogger.Info("External tool called")
+	startTime := time.Now()
+
+	response, err := runExternal(ctx, t.path, t.workspace.For(ctx).Dir(), externalRequest{
+		Method:     "call",
+		Input:      input,
+		WorkingDir: t.workspace.For(ctx).Dir(),
+	})
+	if err != nil {
+		toolLogger.WithError(err).Error("External tool failed")
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	if response.Error != "" {
+		return fmt.Sprintf("Error: %s", response.Error), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(response.Output),
+	}).Info("External tool completed")
+
+	return response.Output, nil
+}
+
+var _ tools.Tool = (*ExternalTool)(nil)
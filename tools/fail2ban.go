@@ -0,0 +1,228 @@
+/*
+Package tools provides intrusion prevention status and control through fail2ban for the Skynet Agent.
+
+This file implements the Fail2banTool, which wraps fail2ban-client so security
+questions such as "is this IP banned?" and "unban it" are answered without
+generic shell access.
+
+Supported operations:
+- status: Overall status and the list of jails
+- jail <name>: Failure counters and banned IPs of a jail
+- banned [ip]: Banned IPs of every jail, or the jails banning one IP
+- unban <ip> [jail]: Lift a ban in one jail or in all jails
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// fail2banLogger provides structured logging for all fail2ban operations
+// with a consistent tool identifier for easy filtering and monitoring
+var fail2banLogger = logrus.WithField("tool", "fail2ban")
+
+// fail2banJailPattern matches valid jail names
+var fail2banJailPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+// Fail2banTool reports fail2ban jail status and lifts bans.
+type Fail2banTool struct{}
+
+// NewFail2banTool creates a new instance of the fail2ban tool.
+// The tool requires fail2ban-client and a running fail2ban server.
+//
+// Returns:
+//   - *Fail2banTool: Configured fail2ban tool ready for use
+func NewFail2banTool() *Fail2banTool {
+	fail2banLogger.Debug("Initializing fail2ban tool")
+	return &Fail2banTool{}
+}
+
+// Description returns a description of the fail2ban tool's operations.
+//
+// Returns:
+//   - string: Description of the supported fail2ban operations
+func (f *Fail2banTool) Description() string {
+	return "Check and control fail2ban intrusion prevention. Usage: 'status' (jails), 'jail <name>' (failures and banned IPs of a jail, e.g. 'jail sshd'), 'banned' (banned IPs of every jail), 'banned <ip>' (is this IP banned, and by which jails), 'unban <ip> [jail]' (lift a ban in one jail or all jails)."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("fail2ban")
+func (f *Fail2banTool) Name() string {
+	return "fail2ban"
+}
+
+// Call performs the requested fail2ban operation.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Operation string (e.g., "jail sshd", "banned 203.0.113.7", "unban 203.0.113.7")
+//
+// Returns:
+//   - string: Operation result or error message
+//   - error: Always nil (errors are returned as string messages)
+func (f *Fail2banTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := fail2banLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Fail2ban tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) == 0 {
+		return "Error: Please provide an operation: status, jail, banned, or unban", nil
+	}
+
+	operation := strings.ToLower(parts[0])
+	var result string
+	switch operation {
+	case "status":
+		output, err := f.client(ctx, "status")
+		result = output
+		if err != nil {
+			result = "Error: " + output
+		}
+
+	case "jail":
+		if len(parts) != 2 || !fail2banJailPattern.MatchString(parts[1]) {
+			return "Error: Please provide a jail name, e.g. 'jail sshd'", nil
+		}
+		output, err := f.client(ctx, "status", parts[1])
+		result = output
+		if err != nil {
+			result = "Error: " + output
+		}
+
+	case "banned":
+		ip := ""
+		if len(parts) > 1 {
+			ip = parts[1]
+			if net.ParseIP(ip) == nil {
+				return fmt.Sprintf("Error: Invalid IP address %q", ip), nil
+			}
+		}
+		result = f.banned(ctx, ip)
+
+	case "unban":
+		if len(parts) < 2 || net.ParseIP(parts[1]) == nil {
+			return "Error: Please provide the IP address to unban", nil
+		}
+		ip := parts[1]
+		var output string
+		var err error
+		if len(parts) > 2 {
+			if !fail2banJailPattern.MatchString(parts[2]) {
+				return fmt.Sprintf("Error: Invalid jail name %q", parts[2]), nil
+			}
+			output, err = f.client(ctx, "set", parts[2], "unbanip", ip)
+		} else {
+			output, err = f.client(ctx, "unban", ip)
+		}
+		if err != nil {
+			result = fmt.Sprintf("Error: Failed to unban %s: %s", ip, output)
+			break
+		}
+		toolLogger.WithField("ip", ip).Warn("IP address unbanned")
+		result = fmt.Sprintf("Unbanned %s (%s). It is banned again if it keeps failing", ip, output)
+
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use status, jail, banned, or unban", operation), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"executionTime": time.Since(startTime),
+	}).Info("Fail2ban operation completed")
+
+	return result, nil
+}
+
+// banned lists the banned IPs of every jail, or only the jails that ban the given IP.
+func (f *Fail2banTool) banned(ctx context.Context, ip string) string {
+	jails, err := f.jails(ctx)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+
+	var lines []string
+	for _, jail := range jails {
+		output, err := f.client(ctx, "status", jail)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("%s: error: %s", jail, output))
+			continue
+		}
+		bannedIPs := fail2banField(output, "Banned IP list")
+		if ip == "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", jail, strings.Join(bannedIPs, " ")))
+			continue
+		}
+		for _, banned := range bannedIPs {
+			if banned == ip {
+				lines = append(lines, jail)
+			}
+		}
+	}
+
+	if ip != "" {
+		if len(lines) == 0 {
+			return fmt.Sprintf("%s is not banned in any jail (%s)", ip, strings.Join(jails, ", "))
+		}
+		return fmt.Sprintf("%s is banned in: %s", ip, strings.Join(lines, ", "))
+	}
+	if len(lines) == 0 {
+		return "No jails are configured"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// jails returns the names of the configured jails.
+func (f *Fail2banTool) jails(ctx context.Context) ([]string, error) {
+	output, err := f.client(ctx, "status")
+	if err != nil {
+		return nil, fmt.Errorf("%s", output)
+	}
+	var jails []string
+	for _, jail := range fail2banField(output, "Jail list") {
+		jails = append(jails, strings.TrimSuffix(jail, ","))
+	}
+	return jails, nil
+}
+
+// fail2banField returns the values of a "Name: values" line of fail2ban-client status output.
+func fail2banField(output, name string) []string {
+	for _, line := range strings.Split(output, "\n") {
+		// Lines are prefixed with tree characters such as "`- " or "|- "
+		if _, value, found := strings.Cut(line, name+":"); found {
+			return strings.Fields(strings.ReplaceAll(value, ",", " "))
+		}
+	}
+	return nil
+}
+
+// client runs fail2ban-client and returns its trimmed combined output.
+func (f *Fail2banTool) client(ctx context.Context, args ...string) (string, error) {
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "fail2ban-client", args...)
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		fail2banLogger.WithError(err).WithFields(logrus.Fields{
+			"args":   args,
+			"output": string(output),
+		}).Error("Fail2ban command failed")
+		if _, lookErr := exec.LookPath("fail2ban-client"); lookErr != nil {
+			return "fail2ban-client is not installed or not accessible", err
+		}
+	}
+	return strings.TrimSpace(string(output)), err
+}
+
+// Ensure Fail2banTool implements the tools.Tool interface
+var _ tools.Tool = (*Fail2banTool)(nil)
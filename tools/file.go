@@ -20,6 +20,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -39,7 +40,7 @@ var fileLogger = logrus.WithField("tool", "file")
 // It maintains a working directory context and implements all standard
 // file operations with proper error handling and logging.
 type FileTool struct {
-	workingDir *string // Reference to the current working directory for relative path resolution
+	workspace *WorkspaceContext // Shared workspace providing the working directory for relative path resolution
 }
 
 // NewFileTool creates a new instance of the file operations tool.
@@ -47,13 +48,13 @@ type FileTool struct {
 // and maintains this context throughout its lifecycle.
 //
 // Parameters:
-//   - workingDir: Pointer to the current working directory string
+//   - workspace: Shared workspace providing the working directory
 //
 // Returns:
 //   - *FileTool: Configured file tool ready for use
-func NewFileTool(workingDir *string) *FileTool {
+func NewFileTool(workspace *WorkspaceContext) *FileTool {
 	fileLogger.Debug("Initializing file tool")
-	return &FileTool{workingDir: workingDir}
+	return &FileTool{workspace: workspace}
 }
 
 // Description returns a comprehensive description of the file tool's capabilities.
@@ -63,7 +64,15 @@ func NewFileTool(workingDir *string) *FileTool {
 // Returns:
 //   - string: Detailed description of all supported file operations
 func (f *FileTool) Description() string {
-	return "File operations with full system access. Usage: 'read <path>' (read file content), 'head <path>' (first 20 lines), 'tail <path>' (last 20 lines), 'size <path>' (file size), 'exists <path>' (check existence), 'type <path>' (file type), 'permissions <path>' (file permissions), 'write <path> <content>' (write file content), 'edit <path> <content>' (edit file content), 'create <path> <content>' (create file), 'delete <path>' (delete file), 'move <src> <dst>' (move file), 'copy <src> <dst>' (copy file), 'chmod <mode> <path>' (change file permissions), 'mkdir <path>' (create directory), 'rmdir <path>' (remove directory)."
+	return "File operations with full system access. Preferred input is a JSON object: {\"operation\": \"<op>\", \"path\": \"<path>\", \"content\": \"<text>\", \"destination\": \"<path>\", \"mode\": \"<mode>\"} where operation is one of read, head, tail, size, exists, type, permissions, write, edit, create, delete, move, copy, chmod, mkdir, rmdir; content is used by write/edit/create, destination by move/copy, and mode by chmod. JSON input supports paths with spaces and multi-line content. Legacy usage is also accepted: 'read <path>', 'write <path> <content>', 'move <src> <dst>', 'chmod <mode> <path>', etc."
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+//
+// Returns:
+//   - json.RawMessage: JSON schema describing fileArgs
+func (f *FileTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"operation":{"type":"string","enum":["read","head","tail","size","exists","type","permissions","write","edit","create","delete","move","copy","chmod","mkdir","rmdir"]},"path":{"type":"string"},"content":{"type":"string"},"destination":{"type":"string"},"mode":{"type":"string"}},"required":["operation","path"]}`)
 }
 
 // Name returns the identifier for this tool.
@@ -75,46 +84,110 @@ func (f *FileTool) Name() string {
 	return "file"
 }
 
+// fileArgs holds the structured arguments of a file operation.
+// It is decoded from a JSON object input or built from the legacy space-separated format.
+type fileArgs struct {
+	Operation   string `json:"operation"`             // Operation to perform, e.g. "read" or "write"
+	Path        string `json:"path"`                  // Target file or directory path
+	Content     string `json:"content,omitempty"`     // Content for write, edit, and create
+	Destination string `json:"destination,omitempty"` // Destination path for move and copy
+	Mode        string `json:"mode,omitempty"`        // Permission mode for chmod, e.g. "0644"
+}
+
+// parseLegacyFileArgs builds file arguments from the space-separated input format.
+// This format cannot express paths containing spaces or preserve content whitespace.
+//
+// Parameters:
+//   - input: Space-separated command such as "write notes.txt hello world"
+//
+// Returns:
+//   - fileArgs: Parsed arguments (empty operation if input was empty)
+func parseLegacyFileArgs(input string) fileArgs {
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) == 0 {
+		return fileArgs{}
+	}
+
+	args := fileArgs{Operation: parts[0]}
+	if len(parts) < 2 {
+		return args
+	}
+
+	switch strings.ToLower(parts[0]) {
+	case "chmod":
+		// Legacy chmod syntax is 'chmod <mode> <path>'
+		args.Mode = parts[1]
+		if len(parts) > 2 {
+			args.Path = parts[2]
+		}
+	case "move", "copy":
+		args.Path = parts[1]
+		if len(parts) > 2 {
+			args.Destination = parts[2]
+		}
+	default:
+		args.Path = parts[1]
+		if len(parts) > 2 {
+			args.Content = strings.Join(parts[2:], " ")
+		}
+	}
+	return args
+}
+
+// resolvePath resolves a path relative to the tool's working directory.
+func (f *FileTool) resolvePath(ctx context.Context, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(f.workspace.For(ctx).Dir(), path)
+}
+
 // Call executes a file operation based on the provided input command.
 // This is the main entry point for all file operations. The method parses
-// the input command, validates parameters, resolves paths, and executes
-// the requested operation with proper error handling and logging.
+// the input (a JSON object or the legacy space-separated format), validates
+// parameters, resolves paths, and executes the requested operation with
+// proper error handling and logging.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
-//   - input: Command string containing operation and parameters
+//   - input: JSON arguments object or command string containing operation and parameters
 //
 // Returns:
 //   - string: Formatted result of the operation or error message
 //   - error: Always nil (errors are returned as string messages)
 func (f *FileTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := fileLogger.WithField("input", input)
+	toolLogger := fileLogger.WithContext(ctx).WithField("input", input)
 	toolLogger.Info("File tool called")
 	startTime := time.Now()
 
-	// Parse the input command into component parts
-	parts := strings.Fields(strings.TrimSpace(input))
-	if len(parts) == 0 {
+	// Prefer structured JSON arguments, falling back to space-separated parsing
+	var args fileArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid file tool arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+	if !isJSON {
+		args = parseLegacyFileArgs(input)
+	}
+
+	if args.Operation == "" {
 		toolLogger.Warn("Empty file command provided")
 		return "Error: Please provide a file command. Supported: read <path>, head <path>, tail <path>, size <path>, exists <path>, type <path>, permissions <path>, write <path> <content>, edit <path> <content>, create <path> <content>, delete <path>, move <src> <dst>, copy <src> <dst>, chmod <mode> <path>", nil
 	}
 
-	command := strings.ToLower(parts[0])
+	command := strings.ToLower(args.Operation)
 
 	// Validate that a path was provided
-	if len(parts) < 2 {
+	if args.Path == "" {
+		if command == "chmod" && args.Mode == "" {
+			return "Error: Please provide file mode", nil
+		}
 		return "Error: Please specify a file path", nil
 	}
 
-	path := parts[1]
-
 	// Resolve relative paths against the working directory
-	var targetPath string
-	if filepath.IsAbs(path) {
-		targetPath = path
-	} else {
-		targetPath = filepath.Join(*f.workingDir, path)
-	}
+	targetPath := f.resolvePath(ctx, args.Path)
 
 	var cmd *exec.Cmd
 	var err error
@@ -123,24 +196,24 @@ func (f *FileTool) Call(ctx context.Context, input string) (string, error) {
 	switch command {
 	case "read":
 		// Use cat command
-		cmd = exec.CommandContext(ctx, "cat", targetPath)
+		cmd = targetCommand(ctx, "cat", targetPath)
 
 	case "head":
 		// Use head command
-		cmd = exec.CommandContext(ctx, "head", "-20", targetPath)
+		cmd = targetCommand(ctx, "head", "-20", targetPath)
 
 	case "tail":
 		// Use tail command
-		cmd = exec.CommandContext(ctx, "tail", "-20", targetPath)
+		cmd = targetCommand(ctx, "tail", "-20", targetPath)
 
 	case "size":
 		// Use wc command for file size
-		cmd = exec.CommandContext(ctx, "wc", "-c", targetPath)
+		cmd = targetCommand(ctx, "wc", "-c", targetPath)
 
 	case "exists":
 		// Use test command
-		cmd = exec.CommandContext(ctx, "test", "-e", targetPath)
-		output, err = cmd.CombinedOutput()
+		cmd = targetCommand(ctx, "test", "-e", targetPath)
+		output, err = limitedCombinedOutput(ctx, cmd)
 		if err != nil {
 			return "false", nil
 		}
@@ -148,24 +221,39 @@ func (f *FileTool) Call(ctx context.Context, input string) (string, error) {
 
 	case "type":
 		// Use file command
-		cmd = exec.CommandContext(ctx, "file", targetPath)
+		cmd = targetCommand(ctx, "file", targetPath)
 
 	case "permissions":
 		// Use stat command for permissions
-		cmd = exec.CommandContext(ctx, "stat", "-c", "%A", targetPath)
+		cmd = targetCommand(ctx, "stat", "-c", "%A", targetPath)
 
 	case "write", "edit", "create":
-		if len(parts) < 3 {
+		if args.Content == "" && !isJSON {
 			return "Error: Please provide content to write", nil
 		}
-		content := strings.Join(parts[2:], " ")
-		err := os.WriteFile(targetPath, []byte(content), 0644)
+		if !isLocalTarget(ctx) {
+			// Write on the target through the shell, feeding the content on stdin
+			cmd = targetCommand(ctx, "sh", "-c", `cat > "$1"`, "sh", targetPath)
+			cmd.Stdin = strings.NewReader(args.Content)
+			if output, err := limitedCombinedOutput(ctx, cmd); err != nil {
+				return fmt.Sprintf("Error writing file: %v %s", err, strings.TrimSpace(string(output))), nil
+			}
+			return fmt.Sprintf("File written successfully: %s", targetPath), nil
+		}
+		err := os.WriteFile(targetPath, []byte(args.Content), 0644)
 		if err != nil {
 			return fmt.Sprintf("Error writing file: %v", err), nil
 		}
 		return fmt.Sprintf("File written successfully: %s", targetPath), nil
 
 	case "delete":
+		if !isLocalTarget(ctx) {
+			cmd = targetCommand(ctx, "rm", "--", targetPath)
+			if output, err := limitedCombinedOutput(ctx, cmd); err != nil {
+				return fmt.Sprintf("Error deleting file: %v %s", err, strings.TrimSpace(string(output))), nil
+			}
+			return fmt.Sprintf("File deleted successfully: %s", targetPath), nil
+		}
 		err := os.Remove(targetPath)
 		if err != nil {
 			return fmt.Sprintf("Error deleting file: %v", err), nil
@@ -173,48 +261,35 @@ func (f *FileTool) Call(ctx context.Context, input string) (string, error) {
 		return fmt.Sprintf("File deleted successfully: %s", targetPath), nil
 
 	case "move":
-		if len(parts) < 3 {
+		if args.Destination == "" {
 			return "Error: Please provide destination path", nil
 		}
-		dstPath := parts[2]
-		if !filepath.IsAbs(dstPath) {
-			dstPath = filepath.Join(*f.workingDir, dstPath)
-		}
-		cmd = exec.CommandContext(ctx, "mv", targetPath, dstPath)
+		cmd = targetCommand(ctx, "mv", targetPath, f.resolvePath(ctx, args.Destination))
 
 	case "copy":
-		if len(parts) < 3 {
+		if args.Destination == "" {
 			return "Error: Please provide destination path", nil
 		}
-		dstPath := parts[2]
-		if !filepath.IsAbs(dstPath) {
-			dstPath = filepath.Join(*f.workingDir, dstPath)
-		}
-		cmd = exec.CommandContext(ctx, "cp", targetPath, dstPath)
+		cmd = targetCommand(ctx, "cp", targetPath, f.resolvePath(ctx, args.Destination))
 
 	case "chmod":
-		if len(parts) < 3 {
+		if args.Mode == "" {
 			return "Error: Please provide file mode", nil
 		}
-		mode := parts[1]
-		filePath := parts[2]
-		if !filepath.IsAbs(filePath) {
-			filePath = filepath.Join(*f.workingDir, filePath)
-		}
-		cmd = exec.CommandContext(ctx, "chmod", mode, filePath)
+		cmd = targetCommand(ctx, "chmod", args.Mode, targetPath)
 
 	case "mkdir":
-		cmd = exec.CommandContext(ctx, "mkdir", "-p", targetPath)
+		cmd = targetCommand(ctx, "mkdir", "-p", targetPath)
 
 	case "rmdir":
-		cmd = exec.CommandContext(ctx, "rmdir", targetPath)
+		cmd = targetCommand(ctx, "rmdir", targetPath)
 
 	default:
 		return fmt.Sprintf("Unknown command '%s'. Supported commands: read, head, tail, size, exists, type, permissions, write, edit, create, delete, move, copy, chmod, mkdir, rmdir", command), nil
 	}
 
 	if cmd != nil {
-		output, err = cmd.CombinedOutput()
+		output, err = limitedCombinedOutput(ctx, cmd)
 		if err != nil {
 			toolLogger.WithError(err).WithField("command", command).Error("File command failed")
 			return string(output), nil
@@ -0,0 +1,193 @@
+/*
+Package tools provides Go toolchain operations for the Skynet Agent.
+
+This file implements the GoTool, which runs the go command inside the agent's
+working directory so the agent can compile and test small Go utilities or
+reproduce issues on the host.
+
+Supported operations:
+- build [packages/flags]: Compile packages
+- test [packages/flags]: Run tests
+- run <file or package> [args]: Compile and run a program
+- vet [packages]: Report suspicious constructs
+- mod tidy: Add missing and remove unused module requirements
+
+Package patterns default to "./..." for build, test, and vet. Commands run in
+the working directory, or in the directory given with "--dir <path>".
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// goLogger provides structured logging for all Go toolchain operations
+// with a consistent tool identifier for easy filtering and monitoring
+var goLogger = logrus.WithField("tool", "go")
+
+// goPackageCommands are the subcommands that default to all packages when none are given
+var goPackageCommands = map[string]bool{
+	"build": true,
+	"test":  true,
+	"vet":   true,
+}
+
+// GoTool runs Go toolchain commands in the workspace.
+type GoTool struct {
+	workspace *WorkspaceContext // Shared workspace providing the working directory the commands run in
+}
+
+// NewGoTool creates a new instance of the Go toolchain tool.
+// The tool requires the go command to be installed and accessible in the system PATH.
+//
+// Parameters:
+//   - workspace: Shared workspace providing the working directory the commands run in
+//
+// Returns:
+//   - *GoTool: Configured Go tool ready for use
+func NewGoTool(workspace *WorkspaceContext) *GoTool {
+	goLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing go tool")
+	return &GoTool{workspace: workspace}
+}
+
+// Description returns a description of the Go tool's operations.
+//
+// Returns:
+//   - string: Description of the supported Go toolchain operations
+func (g *GoTool) Description() string {
+	return "Build, test, and run Go code with the go toolchain. Usage: 'build [packages] [flags]' (e.g. 'build -o app .'), 'test [packages] [flags]' (e.g. 'test -run TestParse ./parser'), 'run <file.go or package> [args]', 'vet [packages]', 'mod tidy'. build, test, and vet default to './...'. Commands run in the working directory; add '--dir <path>' to use another module directory. Create a module first with the shell tool ('go mod init <name>') when needed."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("go")
+func (g *GoTool) Name() string {
+	return "go"
+}
+
+// Call runs the requested go command.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Operation string (e.g., "test ./...", "run main.go", "mod tidy --dir ./tool")
+//
+// Returns:
+//   - string: go command output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (g *GoTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := goLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Go tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) > 0 && parts[0] == "go" {
+		parts = parts[1:]
+	}
+	parts, dir := extractDir(parts)
+	if len(parts) == 0 {
+		return "Error: Please provide an operation: build, test, run, vet, or mod tidy", nil
+	}
+
+	operation := strings.ToLower(parts[0])
+	args := parts
+	switch {
+	case goPackageCommands[operation]:
+		if !hasPackageArgument(parts[1:]) {
+			args = append(args, "./...")
+		}
+	case operation == "run":
+		if len(parts) < 2 {
+			return "Error: Please provide the file or package to run", nil
+		}
+	case operation == "mod":
+		if len(parts) != 2 || parts[1] != "tidy" {
+			return "Error: Only 'mod tidy' is supported", nil
+		}
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use build, test, run, vet, or mod tidy", operation), nil
+	}
+
+	cmdDir := g.workspace.For(ctx).Dir()
+	if dir != "" {
+		if filepath.IsAbs(dir) {
+			cmdDir = dir
+		} else {
+			cmdDir = filepath.Join(g.workspace.For(ctx).Dir(), dir)
+		}
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "go", args...)
+	cmd.Dir = cmdDir
+	cmd.Env = g.workspace.For(ctx).Environ()
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"operation": operation,
+			"dir":       cmdDir,
+			"output":    string(output),
+		}).Error("Go command failed")
+
+		if _, lookErr := exec.LookPath("go"); lookErr != nil {
+			return "Error: go is not installed or not accessible", nil
+		}
+		return fmt.Sprintf("Error: go %s failed: %s", operation, strings.TrimSpace(string(output))), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"dir":           cmdDir,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Go command completed")
+
+	if len(output) == 0 {
+		return fmt.Sprintf("go %s succeeded in %s", strings.Join(parts, " "), cmdDir), nil
+	}
+	return string(output), nil
+}
+
+// extractDir removes a "--dir <path>" or "--dir=<path>" option from the arguments.
+func extractDir(args []string) ([]string, string) {
+	var remaining []string
+	dir := ""
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--dir" && i+1 < len(args):
+			dir = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--dir="):
+			dir = strings.TrimPrefix(args[i], "--dir=")
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return remaining, dir
+}
+
+// hasPackageArgument reports whether go arguments name a package or file rather than only flags.
+func hasPackageArgument(args []string) bool {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return true
+		}
+		// Flags such as -o and -run take a separate value that is not a package
+		if (arg == "-o" || arg == "-run" || arg == "-tags" || arg == "-count" || arg == "-timeout" || arg == "-bench") && i+1 < len(args) {
+			i++
+		}
+	}
+	return false
+}
+
+// Ensure GoTool implements the tools.Tool interface
+var _ tools.Tool = (*GoTool)(nil)
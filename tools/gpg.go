@@ -0,0 +1,238 @@
+/*
+Package tools provides GnuPG signature verification and file encryption for the Skynet Agent.
+
+This file implements the GpgTool, which verifies detached and inline signatures,
+imports public keys, lists the keyring, and encrypts and decrypts files in the
+workspace, so artifact verification workflows can run without the shell tool.
+
+gpg always runs in batch mode so it never waits for interactive input, and existing
+output files are never overwritten. Relative paths are resolved against the agent's
+working directory.
+
+Input is a JSON object, for example:
+
+	{"operation": "verify", "path": "app.tar.gz", "signature": "app.tar.gz.asc"}
+	{"operation": "import", "path": "release-key.asc"}
+	{"operation": "import", "keyId": "0x1234ABCD5678EF90", "keyserver": "hkps://keys.openpgp.org"}
+	{"operation": "list"}
+	{"operation": "encrypt", "path": "backup.tar", "recipient": "ops@example.com"}
+	{"operation": "decrypt", "path": "backup.tar.gpg", "output": "backup.tar"}
+*/
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// gpgLogger provides structured logging for all GnuPG operations
+// with a consistent tool identifier for easy filtering and monitoring
+var gpgLogger = logrus.WithField("tool", "gpg")
+
+// gpgDefaultKeyserver is used to import keys by ID when no keyserver is given
+const gpgDefaultKeyserver = "hkps://keys.openpgp.org"
+
+// gpgArgs holds the structured arguments of a GnuPG operation.
+type gpgArgs struct {
+	Operation string `json:"operation"`           // verify, import, list, encrypt, or decrypt
+	Path      string `json:"path,omitempty"`      // File to verify, import, encrypt, or decrypt
+	Signature string `json:"signature,omitempty"` // Detached signature for verify
+	KeyID     string `json:"keyId,omitempty"`     // Key ID or fingerprint to import from a keyserver
+	Keyserver string `json:"keyserver,omitempty"` // Keyserver for keyId imports
+	Recipient string `json:"recipient,omitempty"` // Recipient key for encrypt
+	Output    string `json:"output,omitempty"`    // Output file for encrypt and decrypt
+	Armor     bool   `json:"armor,omitempty"`     // ASCII-armored encryption output
+}
+
+// GpgTool verifies signatures, manages the public keyring, and encrypts and decrypts files.
+type GpgTool struct {
+	workspace *WorkspaceContext // Shared workspace providing the working directory for relative paths
+}
+
+// NewGpgTool creates a new instance of the GnuPG tool.
+// The tool requires gpg to be installed and accessible in the system PATH.
+//
+// Parameters:
+//   - workspace: Shared workspace providing the working directory for relative paths
+//
+// Returns:
+//   - *GpgTool: Configured gpg tool ready for use
+func NewGpgTool(workspace *WorkspaceContext) *GpgTool {
+	gpgLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing gpg tool")
+	return &GpgTool{workspace: workspace}
+}
+
+// Description returns a description of the gpg tool's operations and input format.
+//
+// Returns:
+//   - string: Description of the supported GnuPG operations
+func (g *GpgTool) Description() string {
+	return "Verify signatures and encrypt or decrypt files with GnuPG. Input is a JSON object: {\"operation\": \"verify\", \"path\": \"<file>\", \"signature\": \"<file.asc or .sig>\"} (omit signature for signed files), {\"operation\": \"import\", \"path\": \"<key file>\"} or {\"operation\": \"import\", \"keyId\": \"<fingerprint>\", \"keyserver\": \"hkps://keys.openpgp.org\"}, {\"operation\": \"list\"} (keys in the keyring), {\"operation\": \"encrypt\", \"path\": \"<file>\", \"recipient\": \"<key or email>\", \"armor\": false}, {\"operation\": \"decrypt\", \"path\": \"<file.gpg>\", \"output\": \"<file>\"}. Import the signer's key before verifying, and compare its fingerprint with the one published by the vendor. Existing output files are never overwritten."
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (g *GpgTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"operation":{"type":"string","enum":["verify","import","list","encrypt","decrypt"]},"path":{"type":"string"},"signature":{"type":"string"},"keyId":{"type":"string"},"keyserver":{"type":"string"},"recipient":{"type":"string"},"output":{"type":"string"},"armor":{"type":"boolean"}},"required":["operation"]}`)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("gpg")
+func (g *GpgTool) Name() string {
+	return "gpg"
+}
+
+// Call performs the GnuPG operation described by the input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON operation object
+//
+// Returns:
+//   - string: gpg output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (g *GpgTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := gpgLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Gpg tool called")
+	startTime := time.Now()
+
+	var args gpgArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid gpg arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+	if !isJSON {
+		args.Operation = strings.TrimSpace(input)
+	}
+
+	operation := strings.ToLower(args.Operation)
+	if (operation == "verify" || operation == "encrypt" || operation == "decrypt") && args.Path == "" {
+		return fmt.Sprintf("Error: Please provide the path of the file to %s", operation), nil
+	}
+
+	cmdArgs := []string{"--batch", "--no-tty"}
+	output := ""
+	switch operation {
+	case "verify":
+		cmdArgs = append(cmdArgs, "--verify")
+		if args.Signature != "" {
+			cmdArgs = append(cmdArgs, g.resolve(ctx, args.Signature))
+		}
+		cmdArgs = append(cmdArgs, g.resolve(ctx, args.Path))
+
+	case "import":
+		switch {
+		case args.Path != "":
+			cmdArgs = append(cmdArgs, "--import", g.resolve(ctx, args.Path))
+		case args.KeyID != "":
+			keyserver := args.Keyserver
+			if keyserver == "" {
+				keyserver = gpgDefaultKeyserver
+			}
+			cmdArgs = append(cmdArgs, "--keyserver", keyserver, "--recv-keys", args.KeyID)
+		default:
+			return "Error: Please provide a key file path or a keyId to import", nil
+		}
+
+	case "list":
+		cmdArgs = append(cmdArgs, "--list-keys", "--with-fingerprint")
+
+	case "encrypt":
+		if args.Recipient == "" {
+			return "Error: Please provide the recipient key or email to encrypt for", nil
+		}
+		output = args.Output
+		if output == "" {
+			output = args.Path + ".gpg"
+			if args.Armor {
+				output = args.Path + ".asc"
+			}
+		}
+		cmdArgs = append(cmdArgs, "--encrypt", "--recipient", args.Recipient)
+		if args.Armor {
+			cmdArgs = append(cmdArgs, "--armor")
+		}
+
+	case "decrypt":
+		output = args.Output
+		if output == "" {
+			output = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(args.Path, ".gpg"), ".asc"), ".pgp")
+			if output == args.Path {
+				return "Error: Please provide the output path for the decrypted file", nil
+			}
+		}
+		cmdArgs = append(cmdArgs, "--decrypt")
+
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use verify, import, list, encrypt, or decrypt", args.Operation), nil
+	}
+
+	if output != "" {
+		output = g.resolve(ctx, output)
+		if _, err := os.Stat(output); err == nil {
+			return fmt.Sprintf("Error: %s already exists, choose another output path", output), nil
+		}
+		cmdArgs = append(cmdArgs, "--output", output, g.resolve(ctx, args.Path))
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "gpg", cmdArgs...)
+	cmd.Dir = g.workspace.For(ctx).Dir()
+	result, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"operation": operation,
+			"output":    string(result),
+		}).Error("Gpg command failed")
+
+		if _, lookErr := exec.LookPath("gpg"); lookErr != nil {
+			return "Error: gpg is not installed or not accessible", nil
+		}
+		if operation == "verify" {
+			return fmt.Sprintf("Error: Signature verification FAILED, do not trust this file:\n%s", strings.TrimSpace(string(result))), nil
+		}
+		return fmt.Sprintf("Error: gpg %s failed: %s", operation, strings.TrimSpace(string(result))), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"executionTime": time.Since(startTime),
+	}).Info("Gpg command completed")
+
+	text := strings.TrimSpace(string(result))
+	switch {
+	case operation == "verify":
+		return "Signature verified:\n" + text, nil
+	case output != "":
+		verb := "Encrypted"
+		if operation == "decrypt" {
+			verb = "Decrypted"
+		}
+		return strings.TrimSpace(fmt.Sprintf("%s %s to %s\n%s", verb, g.resolve(ctx, args.Path), output, text)), nil
+	case text == "":
+		return fmt.Sprintf("gpg %s completed with no output", operation), nil
+	}
+	return text, nil
+}
+
+// resolve makes a path absolute relative to the working directory.
+func (g *GpgTool) resolve(ctx context.Context, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(g.workspace.For(ctx).Dir(), path)
+}
+
+// Ensure GpgTool implements the tools.Tool interface
+var _ tools.Tool = (*GpgTool)(nil)
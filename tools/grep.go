@@ -21,8 +21,6 @@ package tools
 
 import (
 	"context"
-	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -38,7 +36,7 @@ var grepLogger = logrus.WithField("tool", "grep")
 // It wraps file system operations to provide agent-accessible text search with
 // regular expression support, intelligent file filtering, and result formatting.
 type GrepTool struct {
-	workingDir string // Base directory for relative path resolution
+	workspace *WorkspaceContext // Shared workspace providing the base directory for relative path resolution
 }
 
 // NewGrepTool creates a new instance of the text search tool.
@@ -46,13 +44,13 @@ type GrepTool struct {
 // provides context-aware search operations.
 //
 // Parameters:
-//   - workingDir: Pointer to the base directory for relative path resolution
+//   - workspace: Shared workspace providing the base directory for relative path resolution
 //
 // Returns:
 //   - *GrepTool: Configured grep tool ready for use
-func NewGrepTool(workingDir *string) *GrepTool {
-	grepLogger.WithField("workingDir", *workingDir).Debug("Initializing grep tool")
-	return &GrepTool{workingDir: *workingDir}
+func NewGrepTool(workspace *WorkspaceContext) *GrepTool {
+	grepLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing grep tool")
+	return &GrepTool{workspace: workspace}
 }
 
 // Description returns a comprehensive description of the grep tool's capabilities.
@@ -92,9 +90,9 @@ func (g *GrepTool) Name() string {
 //   - string: Formatted search results with matches and summary information
 //   - error: Always nil (errors are returned as string messages)
 func (g *GrepTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := grepLogger.WithFields(logrus.Fields{
+	toolLogger := grepLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"input":      input,
-		"workingDir": g.workingDir,
+		"workingDir": g.workspace.For(ctx).Dir(),
 	})
 
 	toolLogger.Info("Grep tool called")
@@ -112,19 +110,15 @@ func (g *GrepTool) Call(ctx context.Context, input string) (string, error) {
 
 	// Determine target path (file or directory)
 	if len(parts) == 2 && parts[1] != "" {
-		targetPath := parts[1]
-		if !filepath.IsAbs(targetPath) {
-			targetPath = filepath.Join(g.workingDir, targetPath)
-		}
-		args = append(args, targetPath)
+		args = append(args, g.workspace.For(ctx).Resolve(parts[1]))
 	} else {
 		// Search in current working directory
-		args = append(args, g.workingDir)
+		args = append(args, g.workspace.For(ctx).Dir())
 	}
 
 	// Execute grep command
-	cmd := exec.CommandContext(ctx, "grep", args...)
-	output, err := cmd.CombinedOutput()
+	cmd := targetCommand(ctx, "grep", args...)
+	output, err := limitedCombinedOutput(ctx, cmd)
 
 	if err != nil {
 		toolLogger.WithError(err).WithField("pattern", pattern).Error("grep command failed")
@@ -0,0 +1,284 @@
+/*
+Package tools provides file checksum and integrity verification for the Skynet Agent.
+
+This file implements the HashTool, which computes MD5, SHA-1, SHA-256, and SHA-512
+digests of files, compares a file against an expected digest, and verifies every
+entry of a checksum file (the "sha256sum" format and the BSD "SHA256 (file) = ..."
+format), so downloaded artifacts can be validated before they are installed.
+
+Input is a JSON object, for example:
+
+	{"operation": "hash", "path": "app.tar.gz", "algorithm": "sha512"}
+	{"operation": "verify", "path": "app.tar.gz", "expected": "9f86d081884c7d65..."}
+	{"operation": "check", "path": "SHA256SUMS"}
+*/
+package tools
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// hashLogger provides structured logging for all checksum operations
+// with a consistent tool identifier for easy filtering and monitoring
+var hashLogger = logrus.WithField("tool", "hash")
+
+// hashAlgorithms maps algorithm names to digest constructors
+var hashAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// hashAlgorithmByLength infers the algorithm from the length of a hex digest
+var hashAlgorithmByLength = map[int]string{
+	32:  "md5",
+	40:  "sha1",
+	64:  "sha256",
+	128: "sha512",
+}
+
+// hashBSDLine matches the BSD checksum format: "SHA256 (file) = digest"
+var hashBSDLine = regexp.MustCompile(`^(MD5|SHA1|SHA256|SHA512) \((.+)\) = ([0-9a-fA-F]+)$`)
+
+// hashArgs holds the structured arguments of a checksum operation.
+type hashArgs struct {
+	Operation string `json:"operation,omitempty"` // hash, verify, or check (default: hash)
+	Path      string `json:"path"`                // File to hash or checksum file to check
+	Algorithm string `json:"algorithm,omitempty"` // md5, sha1, sha256, or sha512
+	Expected  string `json:"expected,omitempty"`  // Expected hex digest for verify
+}
+
+// HashTool computes and verifies file checksums.
+type HashTool struct {
+	workspace *WorkspaceContext // Shared workspace providing the working directory for relative paths
+}
+
+// NewHashTool creates a new instance of the checksum tool.
+//
+// Parameters:
+//   - workspace: Shared workspace providing the working directory for relative paths
+//
+// Returns:
+//   - *HashTool: Configured hash tool ready for use
+func NewHashTool(workspace *WorkspaceContext) *HashTool {
+	hashLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing hash tool")
+	return &HashTool{workspace: workspace}
+}
+
+// Description returns a description of the hash tool's operations and input format.
+//
+// Returns:
+//   - string: Description of the supported checksum operations
+func (h *HashTool) Description() string {
+	return "Compute and verify file checksums (md5, sha1, sha256, sha512) to validate downloads before installing them. Input is a JSON object: {\"operation\": \"hash\", \"path\": \"<file>\", \"algorithm\": \"sha256\"} (compute a digest), {\"operation\": \"verify\", \"path\": \"<file>\", \"expected\": \"<hex digest>\"} (compare with an expected digest; the algorithm is inferred from its length), {\"operation\": \"check\", \"path\": \"<checksum file such as SHA256SUMS>\"} (verify every file listed). Plain '<file>' computes its sha256."
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (h *HashTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"operation":{"type":"string","enum":["hash","verify","check"]},"path":{"type":"string"},"algorithm":{"type":"string","enum":["md5","sha1","sha256","sha512"]},"expected":{"type":"string"}},"required":["path"]}`)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("hash")
+func (h *HashTool) Name() string {
+	return "hash"
+}
+
+// Call performs the checksum operation described by the input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON operation object, or a file path
+//
+// Returns:
+//   - string: Digest or verification result, or an error message
+//   - error: Always nil (errors are returned as string messages)
+func (h *HashTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := hashLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Hash tool called")
+	startTime := time.Now()
+
+	var args hashArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid hash arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+	if !isJSON {
+		args.Path = strings.TrimSpace(input)
+	}
+	if args.Path == "" {
+		return "Error: Please provide a path", nil
+	}
+	path := h.resolve(ctx, args.Path)
+
+	var result string
+	switch strings.ToLower(args.Operation) {
+	case "", "hash":
+		algorithm := strings.ToLower(args.Algorithm)
+		if algorithm == "" {
+			algorithm = "sha256"
+		}
+		digest, err := fileDigest(ctx, path, algorithm)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		result = fmt.Sprintf("%s  %s (%s)", digest, path, algorithm)
+
+	case "verify":
+		expected := strings.ToLower(strings.TrimSpace(args.Expected))
+		algorithm := strings.ToLower(args.Algorithm)
+		if algorithm == "" {
+			algorithm = hashAlgorithmByLength[len(expected)]
+		}
+		if expected == "" || algorithm == "" {
+			return "Error: Please provide the expected hex digest (md5, sha1, sha256, or sha512)", nil
+		}
+		digest, err := fileDigest(ctx, path, algorithm)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if digest == expected {
+			result = fmt.Sprintf("OK: %s matches the expected %s digest", path, algorithm)
+		} else {
+			result = fmt.Sprintf("MISMATCH: %s %s digest is %s, expected %s. Do not install this file", path, algorithm, digest, expected)
+		}
+
+	case "check":
+		result = h.check(ctx, path)
+
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use hash, verify, or check", args.Operation), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     args.Operation,
+		"path":          path,
+		"executionTime": time.Since(startTime),
+	}).Info("Hash operation completed")
+
+	return result, nil
+}
+
+// check verifies every entry of a checksum file; listed paths are relative to its directory.
+func (h *HashTool) check(ctx context.Context, checksumFile string) string {
+	file, err := os.Open(checksumFile)
+	if err != nil {
+		return fmt.Sprintf("Error: Failed to open checksum file: %v", err)
+	}
+	defer file.Close()
+
+	baseDir := filepath.Dir(checksumFile)
+	var result strings.Builder
+	passed, failed := 0, 0
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var expected, name, algorithm string
+		if match := hashBSDLine.FindStringSubmatch(line); match != nil {
+			algorithm, name, expected = strings.ToLower(match[1]), match[2], strings.ToLower(match[3])
+		} else if fields := strings.SplitN(line, " ", 2); len(fields) == 2 {
+			expected = strings.ToLower(fields[0])
+			name = strings.TrimPrefix(strings.TrimSpace(fields[1]), "*")
+			algorithm = hashAlgorithmByLength[len(expected)]
+		}
+		if algorithm == "" || name == "" {
+			fmt.Fprintf(&result, "SKIPPED: unrecognized line %q\n", line)
+			continue
+		}
+
+		target := name
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(baseDir, target)
+		}
+		digest, err := fileDigest(ctx, target, algorithm)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(&result, "FAILED: %s (%v)\n", name, err)
+		case digest != expected:
+			failed++
+			fmt.Fprintf(&result, "FAILED: %s (%s mismatch)\n", name, algorithm)
+		default:
+			passed++
+			fmt.Fprintf(&result, "OK: %s\n", name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Sprintf("Error: Failed to read checksum file: %v", err)
+	}
+
+	fmt.Fprintf(&result, "%d passed, %d failed", passed, failed)
+	return result.String()
+}
+
+// resolve makes a path absolute relative to the working directory.
+func (h *HashTool) resolve(ctx context.Context, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(h.workspace.For(ctx).Dir(), path)
+}
+
+// fileDigest returns the hex digest of a file with the named algorithm.
+func fileDigest(ctx context.Context, path, algorithm string) (string, error) {
+	newHash, ok := hashAlgorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm %q, use md5, sha1, sha256, or sha512", algorithm)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	digest := newHash()
+	if _, err := io.Copy(digest, &contextReader{ctx: ctx, reader: file}); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// contextReader stops reading once its context is done, so hashing large files honors timeouts.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+// Read reads from the underlying reader unless the context is done.
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// Ensure HashTool implements the tools.Tool interface
+var _ tools.Tool = (*HashTool)(nil)
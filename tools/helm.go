@@ -0,0 +1,179 @@
+/*
+Package tools provides Helm release management for the Skynet Agent.
+
+This file implements the HelmTool, which wraps the helm CLI so the agent can
+inspect and manage the releases installed in a Kubernetes cluster.
+
+Supported operations:
+- Inspection: list, status, history, get (values, manifest, notes, all), search, repo list
+- Changes: install, upgrade, rollback, uninstall, repo add, repo update (unavailable in read-only mode)
+
+Like the kubectl tool, it uses the configured kubeconfig and adds the default
+namespace to commands that do not select one. Relative chart and values paths
+are resolved against the agent's working directory.
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// helmLogger provides structured logging for all helm operations
+var helmLogger = logrus.WithField("tool", "helm")
+
+// helmReadOnlyCommands are the subcommands that never modify releases
+var helmReadOnlyCommands = map[string]bool{
+	"list":    true,
+	"ls":      true,
+	"status":  true,
+	"history": true,
+	"get":     true,
+	"search":  true,
+}
+
+// helmWriteCommands are the subcommands that install, change, or remove releases
+var helmWriteCommands = map[string]bool{
+	"install":   true,
+	"upgrade":   true,
+	"rollback":  true,
+	"uninstall": true,
+}
+
+// HelmTool manages Helm releases with a fixed set of subcommands, optional
+// kubeconfig and namespace defaults, and a read-only mode.
+type HelmTool struct {
+	kubeconfig string            // Kubeconfig file passed to helm; empty uses helm's own resolution
+	namespace  string            // Default namespace for commands that do not select one
+	readOnly   bool              // Whether only inspection subcommands are allowed
+	workspace  *WorkspaceContext // Shared workspace providing the working directory for chart paths
+}
+
+// NewHelmTool creates a new instance of the helm tool.
+//
+// Parameters:
+//   - kubeconfig: Kubeconfig file path; empty uses KUBECONFIG, ~/.kube/config, or the in-cluster service account
+//   - namespace: Default namespace; empty uses the kubeconfig context's namespace
+//   - readOnly: Restrict the tool to inspection subcommands
+//   - workspace: Shared workspace providing the working directory for chart paths
+//
+// Returns:
+//   - *HelmTool: Configured helm tool ready for use
+func NewHelmTool(kubeconfig, namespace string, readOnly bool, workspace *WorkspaceContext) *HelmTool {
+	helmLogger.WithFields(logrus.Fields{
+		"kubeconfig": kubeconfig,
+		"namespace":  namespace,
+		"readOnly":   readOnly,
+	}).Debug("Initializing helm tool")
+	return &HelmTool{
+		kubeconfig: kubeconfig,
+		namespace:  namespace,
+		readOnly:   readOnly,
+		workspace:  workspace,
+	}
+}
+
+// Name returns the identifier for this tool.
+func (h *HelmTool) Name() string {
+	return "helm"
+}
+
+// Description returns a description of the helm tool's capabilities.
+func (h *HelmTool) Description() string {
+	description := "Inspect Helm releases. Usage: 'list [-A]' (releases), 'status <release>', 'history <release>' (revisions), 'get values|manifest|notes|all <release> [--revision N]', 'search repo <keyword>', 'repo list'."
+	if !h.readOnly {
+		description += " Manage releases with 'install <release> <chart> [-f values.yaml] [--set k=v]', 'upgrade <release> <chart> [--install] [-f values.yaml]', 'rollback <release> <revision>', 'uninstall <release>', 'repo add <name> <url>', and 'repo update'."
+	} else {
+		description += " The tool is read-only: install, upgrade, rollback, uninstall, and repo changes are not available."
+	}
+	if h.namespace != "" {
+		description += fmt.Sprintf(" Commands run in namespace %q unless -n or -A is given.", h.namespace)
+	}
+	return description
+}
+
+// Call runs a helm command after validating the subcommand.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: helm command string (e.g., "list -A", "rollback web 3")
+//
+// Returns:
+//   - string: Output of the helm command or error message
+//   - error: Always nil (errors are returned as string messages)
+func (h *HelmTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := helmLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Helm tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) > 0 && parts[0] == "helm" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return "Error: Please provide a helm command (list, status, history, get, upgrade, rollback, ...)", nil
+	}
+
+	command := strings.ToLower(parts[0])
+	write := helmWriteCommands[command]
+	switch {
+	case helmReadOnlyCommands[command], write:
+	case command == "repo" && len(parts) > 1 && parts[1] == "list":
+	case command == "repo" && len(parts) > 1 && (parts[1] == "add" || parts[1] == "update"):
+		write = true
+	default:
+		toolLogger.WithField("command", command).Warn("Unsupported helm command")
+		return fmt.Sprintf("Error: Unsupported helm command %q. Supported: list, status, history, get, search, repo list, install, upgrade, rollback, uninstall, repo add, repo update", command), nil
+	}
+	if write && h.readOnly {
+		toolLogger.WithField("command", command).Warn("Helm write command rejected in read-only mode")
+		return fmt.Sprintf("Error: helm %s is not allowed, the helm tool is read-only", command), nil
+	}
+
+	args := append([]string{command}, parts[1:]...)
+	if h.kubeconfig != "" {
+		args = append(args, "--kubeconfig", h.kubeconfig)
+	}
+	if h.namespace != "" && command != "repo" && command != "search" && !selectsNamespace(parts[1:]) {
+		args = append(args, "--namespace", h.namespace)
+	}
+
+	cmd := targetCommand(ctx, "helm", args...)
+	if h.workspace != nil {
+		cmd.Dir = h.workspace.For(ctx).Dir()
+		cmd.Env = h.workspace.For(ctx).Environ()
+	}
+
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"command": command,
+			"output":  string(output),
+		}).Error("Helm command failed")
+
+		if _, lookErr := exec.LookPath("helm"); lookErr != nil {
+			return "Error: helm is not installed or not accessible", nil
+		}
+		return fmt.Sprintf("Error: helm %s failed: %s", command, strings.TrimSpace(string(output))), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"command":       command,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Helm command completed")
+
+	if len(output) == 0 {
+		return fmt.Sprintf("helm %s completed with no output", command), nil
+	}
+	return string(output), nil
+}
+
+// Ensure HelmTool implements the tools.Tool interface
+var _ tools.Tool = (*HelmTool)(nil)
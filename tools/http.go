@@ -0,0 +1,203 @@
+/*
+Package tools provides an HTTP client for calling service APIs from the Skynet Agent.
+
+This file implements the HttpTool, which sends arbitrary HTTP requests so the agent
+can query internal service APIs, health endpoints, and webhooks. Unlike the curl
+support of the network tool, requests may use any method and carry headers and a
+body, and the response status line, headers, and body are all returned.
+
+The tool accepts a JSON object as Action Input:
+
+	{"method": "POST", "url": "http://localhost:9000/api/jobs", "headers": {"Content-Type": "application/json"}, "body": "{\"name\": \"backup\"}", "timeout": 10}
+
+For quick checks the legacy form '<url>' or '<METHOD> <url>' is also accepted.
+*/
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// httpLogger provides structured logging for all HTTP requests
+// with a consistent tool identifier for easy filtering and monitoring
+var httpLogger = logrus.WithField("tool", "http")
+
+// httpDefaultTimeout bounds requests that do not specify a timeout
+const httpDefaultTimeout = 30 * time.Second
+
+// httpMaxResponseBody caps the response body read into memory; larger bodies are cut off
+const httpMaxResponseBody = 1 << 20
+
+// httpArgs holds the structured arguments of an HTTP request.
+type httpArgs struct {
+	Method  string            `json:"method,omitempty"`  // HTTP method (default: GET)
+	URL     string            `json:"url"`               // Absolute http or https URL
+	Headers map[string]string `json:"headers,omitempty"` // Request headers
+	Body    string            `json:"body,omitempty"`    // Request body
+	Timeout int               `json:"timeout,omitempty"` // Request timeout in seconds (default: 30)
+}
+
+// HttpTool sends HTTP requests and returns the full response.
+type HttpTool struct {
+	client *http.Client // Client used for all requests; timeouts are applied per request
+}
+
+// NewHttpTool creates a new instance of the HTTP client tool.
+//
+// Returns:
+//   - *HttpTool: Configured HTTP tool ready for use
+func NewHttpTool() *HttpTool {
+	httpLogger.Debug("Initializing http tool")
+	return &HttpTool{client: &http.Client{}}
+}
+
+// Description returns a description of the HTTP tool's capabilities and input format.
+//
+// Returns:
+//   - string: Description of the supported request options
+func (h *HttpTool) Description() string {
+	return "Send HTTP requests to APIs and health endpoints and get the status, headers, and body of the response. Input is a JSON object: {\"method\": \"GET|POST|PUT|PATCH|DELETE|HEAD\", \"url\": \"http://host:port/path\", \"headers\": {\"Name\": \"value\"}, \"body\": \"<request body>\", \"timeout\": <seconds>}; only url is required. Quick form: '<url>' or '<METHOD> <url>'."
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (h *HttpTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"method":{"type":"string"},"url":{"type":"string"},"headers":{"type":"object","additionalProperties":{"type":"string"}},"body":{"type":"string"},"timeout":{"type":"integer"}},"required":["url"]}`)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("http")
+func (h *HttpTool) Name() string {
+	return "http"
+}
+
+// Call sends the HTTP request described by the input.
+// Non-2xx responses are returned like any other response so the agent can read
+// error bodies; only transport failures are reported as errors.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON request description, or '<url>' / '<METHOD> <url>'
+//
+// Returns:
+//   - string: Status line, response headers, and body, or an error message
+//   - error: Always nil (errors are returned as string messages)
+func (h *HttpTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := httpLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Http tool called")
+	startTime := time.Now()
+
+	var args httpArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid http arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+
+	if !isJSON {
+		parts := strings.Fields(strings.TrimSpace(input))
+		switch len(parts) {
+		case 1:
+			args.URL = parts[0]
+		case 2:
+			args.Method, args.URL = parts[0], parts[1]
+		default:
+			return "Error: Please provide a JSON request or '<METHOD> <url>'", nil
+		}
+	}
+
+	if args.URL == "" {
+		return "Error: Please provide a url", nil
+	}
+	if !strings.HasPrefix(args.URL, "http://") && !strings.HasPrefix(args.URL, "https://") {
+		return fmt.Sprintf("Error: URL %q must start with http:// or https://", args.URL), nil
+	}
+
+	method := strings.ToUpper(args.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	timeout := httpDefaultTimeout
+	if args.Timeout > 0 {
+		timeout = time.Duration(args.Timeout) * time.Second
+	}
+	requestCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if args.Body != "" {
+		body = bytes.NewBufferString(args.Body)
+	}
+	req, err := http.NewRequestWithContext(requestCtx, method, args.URL, body)
+	if err != nil {
+		toolLogger.WithError(err).Warn("Invalid http request")
+		return fmt.Sprintf("Error: Invalid request: %v", err), nil
+	}
+	for name, value := range args.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"method": method,
+			"url":    args.URL,
+		}).Error("Http request failed")
+		return fmt.Sprintf("Error: %s %s failed: %v", method, args.URL, err), nil
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, httpMaxResponseBody+1))
+	if err != nil {
+		toolLogger.WithError(err).Error("Failed to read http response body")
+		return fmt.Sprintf("Error: Failed to read response body: %v", err), nil
+	}
+	truncated := len(respBody) > httpMaxResponseBody
+	if truncated {
+		respBody = respBody[:httpMaxResponseBody]
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "%s %s\n", resp.Proto, resp.Status)
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range resp.Header[name] {
+			fmt.Fprintf(&result, "%s: %s\n", name, value)
+		}
+	}
+	result.WriteString("\n")
+	result.Write(respBody)
+	if truncated {
+		fmt.Fprintf(&result, "\n[response body cut off after %d bytes]", httpMaxResponseBody)
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"method":        method,
+		"url":           args.URL,
+		"status":        resp.StatusCode,
+		"executionTime": time.Since(startTime),
+		"bodyLength":    len(respBody),
+	}).Info("Http request completed")
+
+	return result.String(), nil
+}
+
+// Ensure HttpTool implements the tools.Tool interface
+var _ tools.Tool = (*HttpTool)(nil)
@@ -0,0 +1,205 @@
+/*
+Package tools provides Kubernetes cluster inspection and management capabilities for the Skynet Agent.
+
+This file implements the KubectlTool, which wraps the kubectl CLI so the agent can
+troubleshoot workloads running in a Kubernetes cluster in addition to the local
+Docker daemon.
+
+Supported operations:
+- Inspection: get, describe, logs, top, auth can-i, auth whoami
+- Changes: apply, delete (unavailable in read-only mode)
+
+A kubeconfig file and default namespace can be configured. The default namespace
+is added to every command that does not select a namespace itself with -n,
+--namespace, -A or --all-namespaces. Relative manifest paths given to apply -f
+are resolved against the agent's working directory.
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// kubectlLogger provides structured logging for all kubectl operations
+// with a consistent tool identifier for easy filtering and monitoring
+var kubectlLogger = logrus.WithField("tool", "kubectl")
+
+// kubectlReadOnlyCommands are the subcommands that never modify cluster state
+var kubectlReadOnlyCommands = map[string]bool{
+	"get":      true,
+	"describe": true,
+	"logs":     true,
+	"top":      true,
+}
+
+// kubectlWriteCommands are the subcommands that modify cluster state
+var kubectlWriteCommands = map[string]bool{
+	"apply":  true,
+	"delete": true,
+}
+
+// KubectlTool provides Kubernetes cluster inspection and management capabilities.
+// It wraps the kubectl CLI with a fixed set of subcommands, optional kubeconfig
+// and namespace defaults, and a read-only mode for clusters the agent must not change.
+type KubectlTool struct {
+	kubeconfig string            // Kubeconfig file passed to kubectl; empty uses kubectl's own resolution
+	namespace  string            // Default namespace for commands that do not select one
+	readOnly   bool              // Whether only inspection subcommands are allowed
+	workspace  *WorkspaceContext // Shared workspace providing the working directory for relative manifest paths
+}
+
+// NewKubectlTool creates a new instance of the kubectl tool.
+// The tool requires kubectl to be installed and accessible in the system PATH.
+//
+// Parameters:
+//   - kubeconfig: Kubeconfig file path; empty uses KUBECONFIG or ~/.kube/config
+//   - namespace: Default namespace; empty uses the kubeconfig context's namespace
+//   - readOnly: Restrict the tool to get, describe, logs, and top
+//   - workspace: Shared workspace providing the working directory for relative manifest paths
+//
+// Returns:
+//   - *KubectlTool: Configured kubectl tool ready for use
+func NewKubectlTool(kubeconfig, namespace string, readOnly bool, workspace *WorkspaceContext) *KubectlTool {
+	kubectlLogger.WithFields(logrus.Fields{
+		"kubeconfig": kubeconfig,
+		"namespace":  namespace,
+		"readOnly":   readOnly,
+	}).Debug("Initializing kubectl tool")
+	return &KubectlTool{
+		kubeconfig: kubeconfig,
+		namespace:  namespace,
+		readOnly:   readOnly,
+		workspace:  workspace,
+	}
+}
+
+// Description returns a description of the kubectl tool's capabilities.
+// The list of operations reflects whether the tool is in read-only mode.
+//
+// Returns:
+//   - string: Description of the supported kubectl operations
+func (k *KubectlTool) Description() string {
+	description := "Inspect Kubernetes clusters with kubectl. Usage: 'get <resource> [name] [-o wide|yaml]' (list resources, e.g. 'get pods -A'), 'describe <resource> <name>' (detailed state and events), 'logs <pod> [-c container] [--tail=100] [--previous]' (container logs), 'top pods|nodes' (resource usage), 'auth can-i <verb> <resource>' (check the agent's own permissions)."
+	if !k.readOnly {
+		description += " Manage resources with 'apply -f <manifest>' (create or update from a file) and 'delete <resource> <name>' (remove a resource)."
+	} else {
+		description += " The tool is read-only: apply and delete are not available."
+	}
+	if k.namespace != "" {
+		description += fmt.Sprintf(" Commands run in namespace %q unless -n or -A is given.", k.namespace)
+	}
+	return description
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("kubectl")
+func (k *KubectlTool) Name() string {
+	return "kubectl"
+}
+
+// Call executes a kubectl command based on the provided input.
+// The subcommand is validated against the allowed set, the configured kubeconfig
+// and default namespace are applied, and the command runs without a shell.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: kubectl command string (e.g., "get pods -n kube-system", "logs web-1 --tail=50")
+//
+// Returns:
+//   - string: Output of the kubectl command or error message
+//   - error: Always nil (errors are returned as string messages)
+func (k *KubectlTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := kubectlLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Kubectl tool called")
+	startTime := time.Now()
+
+	// Parse the input command, tolerating a leading "kubectl"
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) > 0 && parts[0] == "kubectl" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		toolLogger.Warn("Empty kubectl command provided")
+		return "Error: Please provide a kubectl command (get, describe, logs, top, apply, delete)", nil
+	}
+
+	command := strings.ToLower(parts[0])
+	switch {
+	case kubectlReadOnlyCommands[command]:
+	case command == "auth" && len(parts) > 1 && (parts[1] == "can-i" || parts[1] == "whoami"):
+		// Checking the agent's own permissions never changes the cluster
+	case kubectlWriteCommands[command]:
+		if k.readOnly {
+			toolLogger.WithField("command", command).Warn("Kubectl write command rejected in read-only mode")
+			return fmt.Sprintf("Error: kubectl %s is not allowed, the kubectl tool is read-only", command), nil
+		}
+	default:
+		toolLogger.WithField("command", command).Warn("Unsupported kubectl command")
+		return fmt.Sprintf("Error: Unsupported kubectl command %q. Supported: get, describe, logs, top, auth can-i, apply, delete", command), nil
+	}
+
+	args := append([]string{command}, parts[1:]...)
+	if k.kubeconfig != "" {
+		args = append(args, "--kubeconfig", k.kubeconfig)
+	}
+	if k.namespace != "" && !selectsNamespace(parts[1:]) {
+		args = append(args, "--namespace", k.namespace)
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "kubectl", args...)
+	if k.workspace != nil {
+		cmd.Dir = k.workspace.For(ctx).Dir()
+		cmd.Env = k.workspace.For(ctx).Environ()
+	}
+
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"command": command,
+			"output":  string(output),
+		}).Error("Kubectl command failed")
+
+		if _, lookErr := exec.LookPath("kubectl"); lookErr != nil {
+			return "Error: kubectl is not installed or not accessible", nil
+		}
+		return fmt.Sprintf("Error: kubectl %s failed: %s", command, strings.TrimSpace(string(output))), nil
+	}
+
+	executionTime := time.Since(startTime)
+	toolLogger.WithFields(logrus.Fields{
+		"command":       command,
+		"executionTime": executionTime,
+		"outputLength":  len(output),
+	}).Info("Kubectl command completed")
+
+	if len(output) == 0 {
+		return fmt.Sprintf("kubectl %s completed with no output", command), nil
+	}
+	return string(output), nil
+}
+
+// selectsNamespace reports whether kubectl arguments already choose a namespace.
+func selectsNamespace(args []string) bool {
+	for _, arg := range args {
+		switch {
+		case arg == "-n", arg == "--namespace", arg == "-A", arg == "--all-namespaces":
+			return true
+		case strings.HasPrefix(arg, "-n="), strings.HasPrefix(arg, "--namespace="), strings.HasPrefix(arg, "--all-namespaces="):
+			return true
+		}
+	}
+	return false
+}
+
+// Ensure KubectlTool implements the tools.Tool interface
+var _ tools.Tool = (*KubectlTool)(nil)
@@ -0,0 +1,186 @@
+/*
+Package tools provides resource limits for tool commands in the Skynet Agent.
+
+The agent writes the commands its tools run, and a bad one can take down the host
+it is supposed to manage: a fork bomb, a grep over / that never returns, a loop
+that prints until memory runs out. An execution attaches ResourceLimits to the
+context of each tool call with WithResourceLimits, and every command created with
+targetCommand runs under them:
+
+  - CPU time and address space are set as rlimits of the command by running it
+    through prlimit(1) from util-linux, on the local host and on remote targets
+  - processes are limited with RLIMIT_NPROC as well, which the kernel does not
+    enforce for root; a cgroup attached with the limits enforces them for every
+    user, along with a memory ceiling, on the local host
+  - output collected with limitedCombinedOutput or limitedOutput is cut at the
+    limit, and the command is killed instead of filling the server's memory
+
+Commands without limits in their context run unchanged.
+*/
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// ResourceLimits constrain the processes of a tool command. Zero values are unlimited.
+type ResourceLimits struct {
+	CPUSeconds     int      // CPU time of each process (RLIMIT_CPU)
+	MemoryBytes    int64    // Address space of each process (RLIMIT_AS)
+	MaxProcesses   int      // Processes of the command's user (RLIMIT_NPROC)
+	MaxOutputBytes int64    // Output collected before the command is killed
+	Cgroup         *os.File // Open cgroup v2 directory local commands are started in; nil for none
+}
+
+// resourceLimitsKey is the unexported context key type for resource limits
+type resourceLimitsKey struct{}
+
+// WithResourceLimits returns a copy of the parent context whose tool commands run
+// under limits.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - limits: Limits of the commands of the call
+//
+// Returns:
+//   - context.Context: Derived context carrying the limits
+func WithResourceLimits(ctx context.Context, limits ResourceLimits) context.Context {
+	return context.WithValue(ctx, resourceLimitsKey{}, limits)
+}
+
+// ResourceLimitsFromContext returns the limits attached with WithResourceLimits, or none.
+//
+// Parameters:
+//   - ctx: Context to inspect
+//
+// Returns:
+//   - ResourceLimits: The limits of the context's commands
+func ResourceLimitsFromContext(ctx context.Context) ResourceLimits {
+	limits, _ := ctx.Value(resourceLimitsKey{}).(ResourceLimits)
+	return limits
+}
+
+// prlimitArgs returns the prlimit(1) arguments setting the rlimits, or nil when
+// none is set.
+func (l ResourceLimits) prlimitArgs() []string {
+	var args []string
+	if l.CPUSeconds > 0 {
+		args = append(args, "--cpu="+strconv.Itoa(l.CPUSeconds))
+	}
+	if l.MemoryBytes > 0 {
+		args = append(args, "--as="+strconv.FormatInt(l.MemoryBytes, 10))
+	}
+	if l.MaxProcesses > 0 {
+		args = append(args, "--nproc="+strconv.Itoa(l.MaxProcesses))
+	}
+	return args
+}
+
+// limitCommand returns the command running name with args under the rlimits of
+// the context's limits: prlimit with the limits followed by the command, or the
+// command itself when no rlimit is set.
+func limitCommand(ctx context.Context, name string, args []string) (string, []string) {
+	prlimit := ResourceLimitsFromContext(ctx).prlimitArgs()
+	if prlimit == nil {
+		return name, args
+	}
+	wrapped := append(prlimit, "--", name)
+	return "prlimit", append(wrapped, args...)
+}
+
+// outputWaitDelay bounds the wait for the output of a command's children after it exits
+const outputWaitDelay = 5 * time.Second
+
+// errOutputLimit reports a command killed for exceeding the output limit
+var errOutputLimit = errors.New("output limit exceeded")
+
+// cappedBuffer collects command output up to a limit and kills the command when
+// the output goes beyond it.
+type cappedBuffer struct {
+	buffer   bytes.Buffer
+	limit    int64
+	cmd      *exec.Cmd
+	exceeded bool
+}
+
+// Write keeps what fits under the limit and discards the rest. It never fails, so
+// the command is stopped by the kill rather than by a broken pipe.
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.exceeded {
+		return len(p), nil
+	}
+	if room := b.limit - int64(b.buffer.Len()); int64(len(p)) > room {
+		b.buffer.Write(p[:room])
+		b.exceeded = true
+		if b.cmd.Process != nil {
+			b.cmd.Process.Kill()
+		}
+		return len(p), nil
+	}
+	return b.buffer.Write(p)
+}
+
+// result returns the collected output and the command's error, which reports the
+// exceeded limit instead of the kill.
+func (b *cappedBuffer) result(err error) ([]byte, error) {
+	if !b.exceeded {
+		return b.buffer.Bytes(), err
+	}
+	output := append(b.buffer.Bytes(), fmt.Sprintf("\n[output stopped at the limit of %d bytes]", b.limit)...)
+	return output, fmt.Errorf("%w: command stopped after %d bytes", errOutputLimit, b.limit)
+}
+
+// limitedCombinedOutput runs the command like cmd.CombinedOutput, stopping it
+// when its output exceeds the context's output limit.
+//
+// Parameters:
+//   - ctx: Context carrying the limits
+//   - cmd: Command to run, with Stdout and Stderr unset
+//
+// Returns:
+//   - []byte: Combined standard output and standard error, cut at the limit
+//   - error: The command's error, or an error reporting the exceeded limit
+func limitedCombinedOutput(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	limit := ResourceLimitsFromContext(ctx).MaxOutputBytes
+	if limit <= 0 || cmd.Stdout != nil || cmd.Stderr != nil {
+		return cmd.CombinedOutput()
+	}
+	output := &cappedBuffer{limit: limit, cmd: cmd}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if cmd.WaitDelay == 0 {
+		// Children that inherited the output pipe must not keep a killed command waiting
+		cmd.WaitDelay = outputWaitDelay
+	}
+	return output.result(cmd.Run())
+}
+
+// limitedOutput runs the command like cmd.Output, stopping it when its standard
+// output exceeds the context's output limit.
+//
+// Parameters:
+//   - ctx: Context carrying the limits
+//   - cmd: Command to run, with Stdout unset
+//
+// Returns:
+//   - []byte: Standard output, cut at the limit
+//   - error: The command's error, or an error reporting the exceeded limit
+func limitedOutput(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	limit := ResourceLimitsFromContext(ctx).MaxOutputBytes
+	if limit <= 0 || cmd.Stdout != nil {
+		return cmd.Output()
+	}
+	output := &cappedBuffer{limit: limit, cmd: cmd}
+	cmd.Stdout = output
+	if cmd.WaitDelay == 0 {
+		cmd.WaitDelay = outputWaitDelay
+	}
+	return output.result(cmd.Run())
+}
@@ -0,0 +1,20 @@
+package tools
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// startInCgroup makes a local command start in the cgroup of the context's limits.
+// The kernel places the process in the cgroup as it is created, so neither the
+// command nor any process it forks runs outside it.
+func startInCgroup(cmd *exec.Cmd, limits ResourceLimits) {
+	if limits.Cgroup == nil {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(limits.Cgroup.Fd())
+}
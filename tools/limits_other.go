@@ -0,0 +1,8 @@
+//go:build !linux
+
+package tools
+
+import "os/exec"
+
+// startInCgroup does nothing: cgroups exist only on Linux.
+func startInCgroup(cmd *exec.Cmd, limits ResourceLimits) {}
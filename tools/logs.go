@@ -0,0 +1,261 @@
+/*
+Package tools provides system log inspection capabilities for the Skynet Agent.
+
+This file implements the LogsTool, which reads the systemd journal through
+journalctl with unit, time range, priority, and line-count filters. Services that
+log only to journald cannot be found by grepping files, so the journal is the
+primary source.
+
+On systems without systemd (such as Alpine with OpenRC) the tool falls back to
+tailing log files under /var/log: the unit's own log file when one exists, or
+the system log otherwise. Time range and priority filters are journal features
+and are not applied in the fallback.
+*/
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// logsLogger provides structured logging for all log inspection operations
+// with a consistent tool identifier for easy filtering and monitoring
+var logsLogger = logrus.WithField("tool", "logs")
+
+// Line count bounds for log queries
+const (
+	logsDefaultLines = 100
+	logsMaxLines     = 5000
+)
+
+// logsDir is the directory searched by the file fallback
+const logsDir = "/var/log"
+
+// logsSystemFiles are the system log files tried, in order, when no unit is given
+var logsSystemFiles = []string{"messages", "syslog"}
+
+// logsPriorities are the journal priority names accepted by the priority filter
+var logsPriorities = map[string]bool{
+	"emerg": true, "alert": true, "crit": true, "err": true,
+	"warning": true, "notice": true, "info": true, "debug": true,
+}
+
+// logsArgs holds the structured arguments of a log query.
+type logsArgs struct {
+	Unit     string `json:"unit,omitempty"`     // Service unit, e.g. "nginx" or "nginx.service"
+	Since    string `json:"since,omitempty"`    // Start of the time range, e.g. "1 hour ago" or "2024-05-01 10:00"
+	Until    string `json:"until,omitempty"`    // End of the time range
+	Priority string `json:"priority,omitempty"` // Maximum priority: emerg, alert, crit, err, warning, notice, info, debug
+	Lines    int    `json:"lines,omitempty"`    // Number of most recent lines (default: 100)
+}
+
+// LogsTool reads the systemd journal, falling back to /var/log files.
+type LogsTool struct{}
+
+// NewLogsTool creates a new instance of the log inspection tool.
+//
+// Returns:
+//   - *LogsTool: Configured log tool ready for use
+func NewLogsTool() *LogsTool {
+	logsLogger.Debug("Initializing logs tool")
+	return &LogsTool{}
+}
+
+// Description returns a description of the log tool's filters and input format.
+//
+// Returns:
+//   - string: Description of the supported log queries
+func (l *LogsTool) Description() string {
+	return "Read system and service logs from the systemd journal (journalctl), or from /var/log on systems without systemd. Input is a JSON object: {\"unit\": \"<service>\", \"since\": \"1 hour ago\", \"until\": \"<time>\", \"priority\": \"err\", \"lines\": 100}; all fields are optional and an empty input returns the most recent system log lines. priority is one of emerg, alert, crit, err, warning, notice, info, debug and includes more severe levels. Quick form: '<unit> [lines]'."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("logs")
+func (l *LogsTool) Name() string {
+	return "logs"
+}
+
+// Call queries the logs described by the input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON filter object, '<unit> [lines]', or empty for recent system logs
+//
+// Returns:
+//   - string: Matching log lines or an error message
+//   - error: Always nil (errors are returned as string messages)
+func (l *LogsTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := logsLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Logs tool called")
+	startTime := time.Now()
+
+	var args logsArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid logs arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+
+	if !isJSON {
+		parts := strings.Fields(strings.TrimSpace(input))
+		if len(parts) > 0 {
+			args.Unit = parts[0]
+		}
+		if len(parts) > 1 {
+			lines, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return fmt.Sprintf("Error: Invalid line count %q", parts[1]), nil
+			}
+			args.Lines = lines
+		}
+	}
+
+	if args.Lines <= 0 {
+		args.Lines = logsDefaultLines
+	}
+	if args.Lines > logsMaxLines {
+		args.Lines = logsMaxLines
+	}
+	if args.Priority != "" && !logsPriorities[strings.ToLower(args.Priority)] {
+		return fmt.Sprintf("Error: Invalid priority %q. Use emerg, alert, crit, err, warning, notice, info, or debug", args.Priority), nil
+	}
+
+	var output string
+	var source string
+	if _, err := exec.LookPath("journalctl"); err == nil {
+		source = "journal"
+		output = l.queryJournal(ctx, args)
+	} else {
+		source = "files"
+		output = l.tailFiles(args)
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"source":        source,
+		"unit":          args.Unit,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Logs query completed")
+
+	return output, nil
+}
+
+// queryJournal runs journalctl with the requested filters.
+func (l *LogsTool) queryJournal(ctx context.Context, args logsArgs) string {
+	cmdArgs := []string{"--no-pager", "--lines", strconv.Itoa(args.Lines)}
+	if args.Unit != "" {
+		cmdArgs = append(cmdArgs, "--unit", args.Unit)
+	}
+	if args.Since != "" {
+		cmdArgs = append(cmdArgs, "--since", args.Since)
+	}
+	if args.Until != "" {
+		cmdArgs = append(cmdArgs, "--until", args.Until)
+	}
+	if args.Priority != "" {
+		cmdArgs = append(cmdArgs, "--priority", strings.ToLower(args.Priority))
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "journalctl", cmdArgs...)
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		logsLogger.WithError(err).WithField("output", string(output)).Error("Journalctl command failed")
+		return fmt.Sprintf("Error: journalctl failed: %s", strings.TrimSpace(string(output)))
+	}
+	if len(strings.TrimSpace(string(output))) == 0 || strings.TrimSpace(string(output)) == "-- No entries --" {
+		return "No journal entries match the given filters"
+	}
+	return string(output)
+}
+
+// tailFiles returns the last lines of the unit's log file or the system log.
+func (l *LogsTool) tailFiles(args logsArgs) string {
+	var candidates []string
+	if args.Unit != "" {
+		unit := strings.TrimSuffix(filepath.Base(args.Unit), ".service")
+		candidates = []string{
+			filepath.Join(logsDir, unit+".log"),
+			filepath.Join(logsDir, unit, unit+".log"),
+			filepath.Join(logsDir, unit, "error.log"),
+			filepath.Join(logsDir, unit),
+		}
+	} else {
+		for _, name := range logsSystemFiles {
+			candidates = append(candidates, filepath.Join(logsDir, name))
+		}
+	}
+
+	for _, path := range candidates {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		lines, err := tailLines(path, args.Lines)
+		if err != nil {
+			logsLogger.WithError(err).WithField("path", path).Error("Failed to read log file")
+			return fmt.Sprintf("Error: Failed to read %s: %v", path, err)
+		}
+
+		var result strings.Builder
+		fmt.Fprintf(&result, "journalctl is not available; showing the last %d lines of %s", len(lines), path)
+		if args.Since != "" || args.Until != "" || args.Priority != "" {
+			result.WriteString(" (since, until, and priority filters are not applied to log files)")
+		}
+		result.WriteString("\n")
+		result.WriteString(strings.Join(lines, "\n"))
+		return result.String()
+	}
+
+	if args.Unit != "" {
+		return fmt.Sprintf("Error: journalctl is not available and no log file for %q was found under %s", args.Unit, logsDir)
+	}
+	return fmt.Sprintf("Error: journalctl is not available and no system log was found under %s", logsDir)
+}
+
+// tailLines returns the last n lines of a file.
+func tailLines(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Keep a ring of the most recent lines to avoid holding the whole file
+	ring := make([]string, 0, n)
+	next := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(ring) < n {
+			ring = append(ring, scanner.Text())
+		} else {
+			ring[next] = scanner.Text()
+		}
+		next = (next + 1) % n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ring) < n {
+		return ring, nil
+	}
+	return append(ring[next:], ring[:next]...), nil
+}
+
+// Ensure LogsTool implements the tools.Tool interface
+var _ tools.Tool = (*LogsTool)(nil)
@@ -2,9 +2,6 @@ package tools
 
 import (
 	"context"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -15,13 +12,12 @@ import (
 var lsLogger = logrus.WithField("tool", "ls")
 
 type LsTool struct {
-	workingDir string
+	workspace *WorkspaceContext
 }
 
-func NewLsTool() *LsTool {
-	wd, _ := os.Getwd()
-	lsLogger.WithField("workingDir", wd).Debug("Initializing ls tool")
-	return &LsTool{workingDir: wd}
+func NewLsTool(workspace *WorkspaceContext) *LsTool {
+	lsLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing ls tool")
+	return &LsTool{workspace: workspace}
 }
 
 func (l *LsTool) Description() string {
@@ -33,9 +29,9 @@ func (l *LsTool) Name() string {
 }
 
 func (l *LsTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := lsLogger.WithFields(logrus.Fields{
+	toolLogger := lsLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"input":      input,
-		"workingDir": l.workingDir,
+		"workingDir": l.workspace.For(ctx).Dir(),
 	})
 
 	toolLogger.Info("Ls tool called")
@@ -48,13 +44,11 @@ func (l *LsTool) Call(ctx context.Context, input string) (string, error) {
 	}
 
 	// If not absolute path, resolve relative to working directory
-	if !filepath.IsAbs(targetPath) && l.workingDir != "" {
-		targetPath = filepath.Join(l.workingDir, targetPath)
-	}
+	targetPath = l.workspace.For(ctx).Resolve(targetPath)
 
 	// Execute ls command
-	cmd := exec.CommandContext(ctx, "ls", "-la", targetPath)
-	output, err := cmd.CombinedOutput()
+	cmd := targetCommand(ctx, "ls", "-la", targetPath)
+	output, err := limitedCombinedOutput(ctx, cmd)
 
 	if err != nil {
 		toolLogger.WithError(err).WithField("targetPath", targetPath).Error("ls command failed")
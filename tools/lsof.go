@@ -0,0 +1,326 @@
+/*
+Package tools provides open file inspection capabilities for the Skynet Agent.
+
+This file implements the LsofTool, which answers questions such as "what is
+holding port 8080" and "why can't I unmount this volume" by reading the file
+descriptor tables under /proc directly. Busybox-based systems ship an lsof
+without filtering options, so the tool does not depend on an lsof binary.
+
+Supported queries:
+- pid <pid>: Open files of a process
+- port <port>: Processes with a TCP or UDP socket bound to the port
+- file <path>: Processes using the file, or any file below the directory (including working directories)
+- deleted: Files that were deleted but are still held open, and the space they pin
+*/
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// lsofLogger provides structured logging for all open file queries
+// with a consistent tool identifier for easy filtering and monitoring
+var lsofLogger = logrus.WithField("tool", "lsof")
+
+// lsofSocketTables are the /proc/net tables searched for port owners
+var lsofSocketTables = []string{"tcp", "tcp6", "udp", "udp6"}
+
+// lsofMaxResults caps the number of open files listed per query
+const lsofMaxResults = 500
+
+// openFile is a single file descriptor (or cwd/exe reference) of a process.
+type openFile struct {
+	pid     int    // Owning process ID
+	command string // Owning process name
+	fd      string // Descriptor number, or "cwd" / "exe"
+	target  string // Link target: a path, socket:[inode], pipe:[inode], ...
+}
+
+// LsofTool lists open files, port owners, and deleted-but-open files.
+type LsofTool struct{}
+
+// NewLsofTool creates a new instance of the open file inspection tool.
+//
+// Returns:
+//   - *LsofTool: Configured lsof tool ready for use
+func NewLsofTool() *LsofTool {
+	lsofLogger.Debug("Initializing lsof tool")
+	return &LsofTool{}
+}
+
+// Description returns a description of the lsof tool's queries.
+//
+// Returns:
+//   - string: Description of the supported open file queries
+func (l *LsofTool) Description() string {
+	return "Find open files and the processes holding them. Usage: 'pid <pid>' (open files of a process), 'port <port>' (which process is listening on or using a TCP/UDP port), 'file <path>' (processes using a file, or anything below a directory, e.g. to see why a volume cannot be unmounted), 'deleted' (deleted files still held open and the disk space they use)."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("lsof")
+func (l *LsofTool) Name() string {
+	return "lsof"
+}
+
+// Call runs the open file query described by the input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Query string (e.g., "port 8080", "file /mnt/data", "deleted")
+//
+// Returns:
+//   - string: Matching open files or an error message
+//   - error: Always nil (errors are returned as string messages)
+func (l *LsofTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := lsofLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Lsof tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) == 0 {
+		return "Error: Please provide a query: 'pid <pid>', 'port <port>', 'file <path>', or 'deleted'", nil
+	}
+
+	query := strings.ToLower(parts[0])
+	var result string
+	switch query {
+	case "pid":
+		if len(parts) < 2 {
+			return "Error: Please provide a pid", nil
+		}
+		pid, err := strconv.Atoi(parts[1])
+		if err != nil || pid <= 0 {
+			return fmt.Sprintf("Error: Invalid pid %q", parts[1]), nil
+		}
+		result = l.byPID(pid)
+
+	case "port":
+		if len(parts) < 2 {
+			return "Error: Please provide a port", nil
+		}
+		port, err := strconv.Atoi(strings.TrimPrefix(parts[1], ":"))
+		if err != nil || port <= 0 || port > 65535 {
+			return fmt.Sprintf("Error: Invalid port %q", parts[1]), nil
+		}
+		result = l.byPort(ctx, port)
+
+	case "file":
+		if len(parts) < 2 {
+			return "Error: Please provide a path", nil
+		}
+		result = l.byPath(ctx, strings.Join(parts[1:], " "))
+
+	case "deleted":
+		result = l.deleted(ctx)
+
+	default:
+		return fmt.Sprintf("Error: Unsupported query %q. Use pid, port, file, or deleted", query), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"query":         query,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(result),
+	}).Info("Lsof query completed")
+
+	return result, nil
+}
+
+// byPID lists the open files of one process.
+func (l *LsofTool) byPID(pid int) string {
+	files, err := processFiles(pid)
+	if err != nil {
+		return fmt.Sprintf("Error: Cannot read open files of process %d: %v", pid, err)
+	}
+	if len(files) == 0 {
+		return fmt.Sprintf("Process %d has no open files", pid)
+	}
+	return formatOpenFiles(files)
+}
+
+// byPort lists the processes owning sockets bound to the port.
+func (l *LsofTool) byPort(ctx context.Context, port int) string {
+	inodes := make(map[string]string)
+	for _, table := range lsofSocketTables {
+		for inode, state := range socketInodes(table, port) {
+			inodes["socket:["+inode+"]"] = table + " " + state
+		}
+	}
+	if len(inodes) == 0 {
+		return fmt.Sprintf("No socket is bound to port %d", port)
+	}
+
+	var matches []openFile
+	eachOpenFile(ctx, func(file openFile) {
+		if description, ok := inodes[file.target]; ok {
+			file.target = fmt.Sprintf("%s port %d (%s)", file.target, port, description)
+			matches = append(matches, file)
+		}
+	})
+	if len(matches) == 0 {
+		return fmt.Sprintf("A socket is bound to port %d but its owner is not visible (kernel socket or insufficient permissions)", port)
+	}
+	return formatOpenFiles(matches)
+}
+
+// byPath lists the processes using a file or anything below a directory.
+func (l *LsofTool) byPath(ctx context.Context, path string) string {
+	path = filepath.Clean(path)
+	var matches []openFile
+	eachOpenFile(ctx, func(file openFile) {
+		target := strings.TrimSuffix(file.target, " (deleted)")
+		if target == path || strings.HasPrefix(target, strings.TrimSuffix(path, "/")+"/") {
+			matches = append(matches, file)
+		}
+	})
+	if len(matches) == 0 {
+		return fmt.Sprintf("No process is using %s", path)
+	}
+	return formatOpenFiles(matches)
+}
+
+// deleted lists deleted files that are still open, with their sizes.
+func (l *LsofTool) deleted(ctx context.Context) string {
+	var matches []openFile
+	var total int64
+	eachOpenFile(ctx, func(file openFile) {
+		if !strings.HasSuffix(file.target, " (deleted)") || file.fd == "cwd" || file.fd == "exe" {
+			return
+		}
+		// Stat through the descriptor; the path itself no longer exists
+		if info, err := os.Stat(filepath.Join("/proc", strconv.Itoa(file.pid), "fd", file.fd)); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+			file.target = fmt.Sprintf("%s, %d bytes", file.target, info.Size())
+		}
+		matches = append(matches, file)
+	})
+	if len(matches) == 0 {
+		return "No deleted files are held open"
+	}
+	return formatOpenFiles(matches) + fmt.Sprintf("\nTotal space held by deleted files: %d bytes (freed when the processes close them or restart)", total)
+}
+
+// processFiles returns the descriptors, working directory, and executable of a process.
+func processFiles(pid int) ([]openFile, error) {
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+	entries, err := os.ReadDir(filepath.Join(dir, "fd"))
+	if err != nil {
+		return nil, err
+	}
+	command := processName(pid)
+
+	files := make([]openFile, 0, len(entries)+2)
+	for _, name := range []string{"cwd", "exe"} {
+		if target, err := os.Readlink(filepath.Join(dir, name)); err == nil {
+			files = append(files, openFile{pid: pid, command: command, fd: name, target: target})
+		}
+	}
+	for _, entry := range entries {
+		if target, err := os.Readlink(filepath.Join(dir, "fd", entry.Name())); err == nil {
+			files = append(files, openFile{pid: pid, command: command, fd: entry.Name(), target: target})
+		}
+	}
+	return files, nil
+}
+
+// eachOpenFile calls fn for every open file of every readable process.
+func eachOpenFile(ctx context.Context, fn func(openFile)) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		files, err := processFiles(pid)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			fn(file)
+		}
+	}
+}
+
+// socketInodes returns the inodes and states of sockets in a /proc/net table bound to the port.
+func socketInodes(table string, port int) map[string]string {
+	file, err := os.Open(filepath.Join("/proc/net", table))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	inodes := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip the header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		// local_address is HEXIP:HEXPORT
+		colon := strings.LastIndex(fields[1], ":")
+		if colon < 0 {
+			continue
+		}
+		localPort, err := strconv.ParseInt(fields[1][colon+1:], 16, 32)
+		if err != nil || int(localPort) != port || fields[9] == "0" {
+			continue
+		}
+		inodes[fields[9]] = socketState(table, fields[3])
+	}
+	return inodes
+}
+
+// socketState names the TCP state of a /proc/net entry; UDP sockets have no connection state.
+func socketState(table, code string) string {
+	if strings.HasPrefix(table, "udp") {
+		return "bound"
+	}
+	states := map[string]string{
+		"01": "ESTABLISHED", "02": "SYN_SENT", "03": "SYN_RECV", "04": "FIN_WAIT1",
+		"05": "FIN_WAIT2", "06": "TIME_WAIT", "07": "CLOSE", "08": "CLOSE_WAIT",
+		"09": "LAST_ACK", "0A": "LISTEN", "0B": "CLOSING",
+	}
+	if state, ok := states[code]; ok {
+		return state
+	}
+	return code
+}
+
+// formatOpenFiles renders open files as an lsof-like table ordered by PID.
+func formatOpenFiles(files []openFile) string {
+	sort.SliceStable(files, func(i, j int) bool { return files[i].pid < files[j].pid })
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "%-8s %-16s %-6s %s\n", "PID", "COMMAND", "FD", "NAME")
+	for i, file := range files {
+		if i == lsofMaxResults {
+			fmt.Fprintf(&result, "... %d more entries not shown\n", len(files)-lsofMaxResults)
+			break
+		}
+		fmt.Fprintf(&result, "%-8d %-16s %-6s %s\n", file.pid, file.command, file.fd, file.target)
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// Ensure LsofTool implements the tools.Tool interface
+var _ tools.Tool = (*LsofTool)(nil)
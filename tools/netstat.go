@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -28,7 +27,7 @@ func (n *NetstatTool) Name() string {
 }
 
 func (n *NetstatTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := netstatLogger.WithField("input", input)
+	toolLogger := netstatLogger.WithContext(ctx).WithField("input", input)
 	toolLogger.Info("Netstat tool called")
 	startTime := time.Now()
 
@@ -40,8 +39,8 @@ func (n *NetstatTool) Call(ctx context.Context, input string) (string, error) {
 	}
 
 	// Execute netstat command
-	cmd := exec.CommandContext(ctx, "netstat", args...)
-	output, err := cmd.CombinedOutput()
+	cmd := targetCommand(ctx, "netstat", args...)
+	output, err := limitedCombinedOutput(ctx, cmd)
 
 	if err != nil {
 		toolLogger.WithError(err).WithField("args", args).Error("netstat command failed")
@@ -7,23 +7,29 @@ and network monitoring. The tool acts as a bridge between the agent and various
 utilities, enabling full network diagnostic capabilities through the agent interface.
 
 Supported operations:
-- Interface Management: interfaces (show network interfaces and statistics)
-- Routing: routes (display routing table information)
-- DNS: dns (show DNS configuration and hosts file)
-- Connectivity: ping (test network connectivity to hosts)
-- ARP: arp (display ARP table entries)
-- Connections: connections (show active network connections and listening ports)
-- All standard network commands: ifconfig, ip, netstat, ss, route, traceroute, nslookup, dig, wget, curl, etc.
-
-The tool provides enhanced formatting for common diagnostic operations while
-supporting the full range of network utilities for advanced operations.
+- Addresses: ip addr [interface] (interface state and addresses, one line each)
+- Routing: ip route (routing table)
+- Sockets: ss [options] (listening sockets and connections with owning processes)
+- Neighbors: arp (ARP table read from /proc/net/arp)
+- Interface statistics: stats (traffic, error, and drop counters from /proc/net/dev)
+- Connectivity: ping, traceroute, wget, curl
+- DNS and registration: dig, nslookup, whois
+
+The arp and stats operations read /proc directly and format the result as a table,
+so they work on minimal systems without net-tools.
 */
 package tools
 
 import (
+	"bufio"
 	"context"
+	"fmt"
+	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -34,6 +40,12 @@ import (
 // with a consistent tool identifier for easy filtering and monitoring
 var networkLogger = logrus.WithField("tool", "network")
 
+// networkInterfacePattern matches valid interface names
+var networkInterfacePattern = regexp.MustCompile(`^[A-Za-z0-9_.@:\-]{1,32}$`)
+
+// networkSSFlags matches ss options made only of single-letter flags, such as "-tlnp"
+var networkSSFlags = regexp.MustCompile(`^-[a-zA-Z46]+$`)
+
 // NetworkTool provides comprehensive network diagnostics and configuration capabilities.
 // It wraps various network utilities to provide agent-accessible network operations with
 // enhanced formatting, error handling, and logging for operational monitoring.
@@ -56,7 +68,7 @@ func NewNetworkTool() *NetworkTool {
 // Returns:
 //   - string: Detailed description of all supported network operations
 func (n *NetworkTool) Description() string {
-	return "Network connectivity and diagnostics. Usage: 'ping <host>' (ping host), 'wget <url>' (download), 'curl <url>' (HTTP request), 'dig <domain>' (DNS lookup), 'traceroute <host>' (trace route), 'whois <domain>' (domain info), 'nslookup <domain>' (DNS lookup)."
+	return "Network configuration, connectivity, and diagnostics. Usage: 'ip addr [interface]' (interfaces, state, and addresses), 'ip route' (routing table), 'ss [options]' (sockets; default '-tulpn' lists listening TCP/UDP ports with processes, e.g. 'ss -tnp' for established TCP connections), 'arp' (neighbor table), 'stats' (per-interface traffic, errors, and drops), 'ping <host>' (ping host), 'wget <url>' (download), 'curl <url>' (HTTP request), 'dig <domain>' (DNS lookup), 'traceroute <host>' (trace route), 'whois <domain>' (domain info), 'nslookup <domain>' (DNS lookup)."
 }
 
 // Name returns the identifier for this tool.
@@ -86,7 +98,7 @@ func (n *NetworkTool) Name() string {
 //   - string: Formatted result of the network operation or error message
 //   - error: Always nil (errors are returned as string messages)
 func (n *NetworkTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := networkLogger.WithField("input", input)
+	toolLogger := networkLogger.WithContext(ctx).WithField("input", input)
 	toolLogger.Info("Network tool called")
 	startTime := time.Now()
 
@@ -107,55 +119,93 @@ func (n *NetworkTool) Call(ctx context.Context, input string) (string, error) {
 			return "Error: Please specify a host to ping", nil
 		}
 		host := parts[1]
-		cmd = exec.CommandContext(ctx, "ping", "-c", "4", host)
+		cmd = targetCommand(ctx, "ping", "-c", "4", host)
 
 	case "wget":
 		if len(parts) < 2 {
 			return "Error: Please specify a URL to download", nil
 		}
 		url := parts[1]
-		cmd = exec.CommandContext(ctx, "wget", "-q", "-O", "-", url)
+		cmd = targetCommand(ctx, "wget", "-q", "-O", "-", url)
 
 	case "curl":
 		if len(parts) < 2 {
 			return "Error: Please specify a URL for curl", nil
 		}
 		url := parts[1]
-		cmd = exec.CommandContext(ctx, "curl", "-s", url)
+		cmd = targetCommand(ctx, "curl", "-s", url)
 
 	case "dig":
 		if len(parts) < 2 {
 			return "Error: Please specify a domain for dig", nil
 		}
 		domain := parts[1]
-		cmd = exec.CommandContext(ctx, "dig", domain)
+		cmd = targetCommand(ctx, "dig", domain)
 
 	case "traceroute":
 		if len(parts) < 2 {
 			return "Error: Please specify a host for traceroute", nil
 		}
 		host := parts[1]
-		cmd = exec.CommandContext(ctx, "traceroute", host)
+		cmd = targetCommand(ctx, "traceroute", host)
 
 	case "whois":
 		if len(parts) < 2 {
 			return "Error: Please specify a domain for whois", nil
 		}
 		domain := parts[1]
-		cmd = exec.CommandContext(ctx, "whois", domain)
+		cmd = targetCommand(ctx, "whois", domain)
 
 	case "nslookup":
 		if len(parts) < 2 {
 			return "Error: Please specify a domain for nslookup", nil
 		}
 		domain := parts[1]
-		cmd = exec.CommandContext(ctx, "nslookup", domain)
+		cmd = targetCommand(ctx, "nslookup", domain)
+
+	case "ip":
+		if len(parts) < 2 {
+			return "Error: Please specify 'ip addr [interface]' or 'ip route'", nil
+		}
+		switch strings.ToLower(parts[1]) {
+		case "addr", "address", "a":
+			args := []string{"-brief", "address", "show"}
+			if len(parts) > 2 {
+				if !networkInterfacePattern.MatchString(parts[2]) {
+					return fmt.Sprintf("Error: Invalid interface name %q", parts[2]), nil
+				}
+				args = append(args, "dev", parts[2])
+			}
+			cmd = targetCommand(ctx, "ip", args...)
+		case "route", "r":
+			cmd = targetCommand(ctx, "ip", "route", "show")
+		default:
+			return "Error: Supported ip commands: 'ip addr [interface]', 'ip route'", nil
+		}
+
+	case "ss":
+		args := []string{"-tulpn"}
+		if len(parts) > 1 {
+			for _, flag := range parts[1:] {
+				if !networkSSFlags.MatchString(flag) {
+					return fmt.Sprintf("Error: Unsupported ss option %q, only flags such as -t, -u, -l, -n, -p, -a are allowed", flag), nil
+				}
+			}
+			args = parts[1:]
+		}
+		cmd = targetCommand(ctx, "ss", args...)
+
+	case "arp":
+		return n.arpTable(), nil
+
+	case "stats", "interfaces":
+		return n.interfaceStats(), nil
 
 	default:
-		return "Error: Unsupported network command. Supported: ping, wget, curl, dig, traceroute, whois, nslookup", nil
+		return "Error: Unsupported network command. Supported: ip addr, ip route, ss, arp, stats, ping, wget, curl, dig, traceroute, whois, nslookup", nil
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := limitedCombinedOutput(ctx, cmd)
 	if err != nil {
 		toolLogger.WithError(err).WithField("command", command).Error("Network command failed")
 		return string(output), nil
@@ -171,5 +221,76 @@ func (n *NetworkTool) Call(ctx context.Context, input string) (string, error) {
 	return string(output), nil
 }
 
+// arpTable formats the kernel's ARP table.
+func (n *NetworkTool) arpTable() string {
+	file, err := os.Open("/proc/net/arp")
+	if err != nil {
+		return fmt.Sprintf("Error: Cannot read the ARP table: %v", err)
+	}
+	defer file.Close()
+
+	var result strings.Builder
+	writer := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ADDRESS\tHW ADDRESS\tSTATE\tINTERFACE")
+	entries := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip the header line
+	for scanner.Scan() {
+		// IP address, HW type, Flags, HW address, Mask, Device
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		state := "complete"
+		if fields[2] == "0x0" {
+			state = "incomplete"
+		} else if fields[2] == "0x6" {
+			state = "permanent"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", fields[0], fields[3], state, fields[5])
+		entries++
+	}
+	writer.Flush()
+
+	if entries == 0 {
+		return "The ARP table is empty"
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// interfaceStats formats per-interface traffic, error, and drop counters.
+func (n *NetworkTool) interfaceStats() string {
+	file, err := os.Open("/proc/net/dev")
+	if err != nil {
+		return fmt.Sprintf("Error: Cannot read interface statistics: %v", err)
+	}
+	defer file.Close()
+
+	var result strings.Builder
+	writer := tabwriter.NewWriter(&result, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "INTERFACE\tRX BYTES\tRX PACKETS\tRX ERRS\tRX DROP\tTX BYTES\tTX PACKETS\tTX ERRS\tTX DROP\t")
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, counters, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue // Header lines
+		}
+		// Receive: bytes packets errs drop fifo frame compressed multicast; transmit: bytes packets errs drop ...
+		fields := strings.Fields(counters)
+		if len(fields) < 12 {
+			continue
+		}
+		value := func(i int) int64 {
+			v, _ := strconv.ParseInt(fields[i], 10, 64)
+			return v
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%d\t%s\t%d\t%d\t%d\t\n",
+			strings.TrimSpace(name), formatBytes(value(0)), value(1), value(2), value(3),
+			formatBytes(value(8)), value(9), value(10), value(11))
+	}
+	writer.Flush()
+	return strings.TrimRight(result.String(), "\n")
+}
+
 // Ensure NetworkTool implements the tools.Tool interface
 var _ tools.Tool = (*NetworkTool)(nil)
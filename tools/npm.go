@@ -0,0 +1,177 @@
+/*
+Package tools provides Node.js package management and script execution for the Skynet Agent.
+
+This file implements the NpmTool, which wraps npm and node so the agent can
+maintain Node services on the host: install dependencies, run package scripts,
+inspect the dependency tree, audit for vulnerabilities, and execute node scripts.
+
+Supported operations:
+- install [package...]: Install dependencies of the project or the given packages
+- run <script> [args...]: Run a script from package.json
+- ls [package]: Installed dependency tree
+- audit: Known vulnerabilities in installed dependencies
+- node <file> [args...]: Execute a node script with a time limit
+
+Commands run in the agent's working directory; npm's own --prefix option selects
+another project directory.
+*/
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// npmLogger provides structured logging for all npm and node operations
+// with a consistent tool identifier for easy filtering and monitoring
+var npmLogger = logrus.WithField("tool", "npm")
+
+// npmCommands are the npm subcommands the tool runs
+var npmCommands = map[string]bool{
+	"install": true,
+	"run":     true,
+	"ls":      true,
+	"audit":   true,
+}
+
+// npmNodeTimeout bounds node script execution so long-running servers are not started in the foreground
+const npmNodeTimeout = 60 * time.Second
+
+// NpmTool manages Node.js dependencies and runs node scripts.
+type NpmTool struct {
+	workspace *WorkspaceContext // Shared workspace providing the working directory for projects and relative script paths
+}
+
+// NewNpmTool creates a new instance of the npm tool.
+// The tool requires npm and node to be installed and accessible in the system PATH.
+//
+// Parameters:
+//   - workspace: Shared workspace providing the working directory for projects and relative script paths
+//
+// Returns:
+//   - *NpmTool: Configured npm tool ready for use
+func NewNpmTool(workspace *WorkspaceContext) *NpmTool {
+	npmLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing npm tool")
+	return &NpmTool{workspace: workspace}
+}
+
+// Description returns a description of the npm tool's operations.
+//
+// Returns:
+//   - string: Description of the supported npm and node operations
+func (n *NpmTool) Description() string {
+	return fmt.Sprintf("Maintain Node.js projects with npm and node. Usage: 'install [package...]' (project dependencies, or add packages, e.g. 'install express@4'), 'run <script> [args]' (a package.json script, e.g. 'run build'), 'ls [package]' (dependency tree), 'audit' (known vulnerabilities), 'node <file> [args]' (execute a script, stopped after %s). Commands run in the working directory; add '--prefix <dir>' to target another project.", npmNodeTimeout)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("npm")
+func (n *NpmTool) Name() string {
+	return "npm"
+}
+
+// Call runs the requested npm or node operation.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Operation string (e.g., "install", "run test", "node server-check.js")
+//
+// Returns:
+//   - string: Command output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (n *NpmTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := npmLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Npm tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) > 0 && parts[0] == "npm" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return "Error: Please provide an operation: install, run, ls, audit, or node", nil
+	}
+
+	operation := strings.ToLower(parts[0])
+	program := "npm"
+	var args []string
+	switch {
+	case operation == "node":
+		if len(parts) < 2 {
+			return "Error: Please provide the node script to execute", nil
+		}
+		script := parts[1]
+		if !filepath.IsAbs(script) {
+			script = filepath.Join(n.workspace.For(ctx).Dir(), script)
+		}
+		program = "node"
+		args = append([]string{script}, parts[2:]...)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, npmNodeTimeout)
+		defer cancel()
+
+	case npmCommands[operation]:
+		if operation == "run" && len(parts) < 2 {
+			return "Error: Please provide the script to run (see the scripts section of package.json)", nil
+		}
+		args = append([]string{operation}, parts[1:]...)
+		if operation == "install" || operation == "audit" {
+			args = append(args, "--no-fund")
+		}
+
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use install, run, ls, audit, or node", operation), nil
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, program, args...)
+	cmd.Dir = n.workspace.For(ctx).Dir()
+	cmd.Env = n.workspace.For(ctx).Environ()
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		if _, lookErr := exec.LookPath(program); lookErr != nil {
+			return fmt.Sprintf("Error: %s is not installed or not accessible", program), nil
+		}
+		if operation == "node" && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			toolLogger.WithField("timeout", npmNodeTimeout).Warn("Node script stopped after timeout")
+			return fmt.Sprintf("Error: node script stopped after %s. Output so far:\n%s", npmNodeTimeout, string(output)), nil
+		}
+
+		// npm ls and npm audit exit non-zero to report problems while still printing the result
+		exitErr, isExit := err.(*exec.ExitError)
+		if isExit && (operation == "ls" || operation == "audit") && len(output) > 0 {
+			toolLogger.WithField("exitCode", exitErr.ExitCode()).Warn("Npm reported dependency problems")
+			return string(output), nil
+		}
+
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"operation": operation,
+			"output":    string(output),
+		}).Error("Npm command failed")
+		return fmt.Sprintf("Error: %s %s failed: %s", program, operation, strings.TrimSpace(string(output))), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Npm command completed")
+
+	if len(output) == 0 {
+		return fmt.Sprintf("%s completed with no output", operation), nil
+	}
+	return string(output), nil
+}
+
+// Ensure NpmTool implements the tools.Tool interface
+var _ tools.Tool = (*NpmTool)(nil)
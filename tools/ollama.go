@@ -0,0 +1,320 @@
+/*
+Package tools provides management of the local Ollama instance for the Skynet Agent.
+
+This file implements the OllamaTool, which manages the Ollama server that may also
+serve Skynet's own model: listing installed models, pulling and deleting models,
+showing model details, and showing which models are loaded in memory. It talks to
+the Ollama HTTP API, so it works whether Ollama runs on the host or in a container.
+
+Supported operations:
+- list: Installed models with size and modification time
+- ps: Models loaded in memory, their memory use, and when they unload
+- pull <model>: Download a model, e.g. "pull llama3.2:3b"
+- show <model>: Model family, parameters, quantization, and context length
+- delete <model>: Remove an installed model
+*/
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// ollamaLogger provides structured logging for all Ollama management operations
+// with a consistent tool identifier for easy filtering and monitoring
+var ollamaLogger = logrus.WithField("tool", "ollama")
+
+// ollamaModelPattern matches model references such as "qwen3", "llama3.2:3b", or "library/model:tag"
+var ollamaModelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._\-/]*(:[A-Za-z0-9._\-]+)?$`)
+
+// ollamaModel describes an installed or loaded model in Ollama API responses
+type ollamaModel struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	SizeVRAM   int64     `json:"size_vram"`
+	ModifiedAt time.Time `json:"modified_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Details    struct {
+		Family            string `json:"family"`
+		ParameterSize     string `json:"parameter_size"`
+		QuantizationLevel string `json:"quantization_level"`
+	} `json:"details"`
+}
+
+// OllamaTool manages models of an Ollama server through its HTTP API.
+type OllamaTool struct {
+	endpoint     string       // Base URL of the Ollama API
+	currentModel string       // Model Skynet itself uses, protected from deletion
+	client       *http.Client // Client used for all requests; the caller's context bounds them
+}
+
+// NewOllamaTool creates a new instance of the Ollama management tool.
+//
+// Parameters:
+//   - endpoint: Base URL of the Ollama API (e.g., "http://localhost:11434")
+//   - currentModel: Model used by Skynet itself; it cannot be deleted through the tool
+//
+// Returns:
+//   - *OllamaTool: Configured Ollama tool ready for use
+func NewOllamaTool(endpoint, currentModel string) *OllamaTool {
+	ollamaLogger.WithFields(logrus.Fields{
+		"endpoint":     endpoint,
+		"currentModel": currentModel,
+	}).Debug("Initializing ollama tool")
+	return &OllamaTool{
+		endpoint:     strings.TrimRight(endpoint, "/"),
+		currentModel: currentModel,
+		client:       &http.Client{},
+	}
+}
+
+// Description returns a description of the Ollama tool's operations.
+//
+// Returns:
+//   - string: Description of the supported model management operations
+func (o *OllamaTool) Description() string {
+	return fmt.Sprintf("Manage the models of the Ollama server at %s. Usage: 'list' (installed models), 'ps' (models loaded in memory and their memory use), 'pull <model>' (download a model, e.g. 'pull llama3.2:3b'; large models take minutes), 'show <model>' (family, parameters, quantization, context length), 'delete <model>' (remove a model). The model Skynet runs on (%s) cannot be deleted.", o.endpoint, o.currentModel)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("ollama")
+func (o *OllamaTool) Name() string {
+	return "ollama"
+}
+
+// Call performs the requested model management operation.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Operation string (e.g., "list", "pull qwen3:8b", "delete old-model")
+//
+// Returns:
+//   - string: Operation result or error message
+//   - error: Always nil (errors are returned as string messages)
+func (o *OllamaTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := ollamaLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Ollama tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) == 0 {
+		return "Error: Please provide an operation: list, ps, pull, show, or delete", nil
+	}
+
+	operation := strings.ToLower(parts[0])
+	model := ""
+	switch operation {
+	case "list", "ps":
+	case "pull", "show", "delete":
+		if len(parts) != 2 || !ollamaModelPattern.MatchString(parts[1]) {
+			return fmt.Sprintf("Error: Please provide one model name for %s, e.g. '%s qwen3:8b'", operation, operation), nil
+		}
+		model = parts[1]
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use list, ps, pull, show, or delete", operation), nil
+	}
+
+	var result string
+	var err error
+	switch operation {
+	case "list":
+		result, err = o.list(ctx)
+	case "ps":
+		result, err = o.running(ctx)
+	case "pull":
+		var body []byte
+		body, err = o.request(ctx, http.MethodPost, "/api/pull", map[string]interface{}{"model": model, "stream": false})
+		if err == nil {
+			result = fmt.Sprintf("Pulled %s (%s)", model, strings.TrimSpace(string(body)))
+		}
+	case "show":
+		result, err = o.show(ctx, model)
+	case "delete":
+		if sameOllamaModel(model, o.currentModel) {
+			toolLogger.WithField("model", model).Warn("Refused to delete the model Skynet runs on")
+			return fmt.Sprintf("Error: %s is the model Skynet runs on and cannot be deleted", model), nil
+		}
+		_, err = o.request(ctx, http.MethodDelete, "/api/delete", map[string]interface{}{"model": model})
+		if err == nil {
+			result = fmt.Sprintf("Deleted %s", model)
+		}
+	}
+	if err != nil {
+		toolLogger.WithError(err).WithField("operation", operation).Error("Ollama operation failed")
+		return fmt.Sprintf("Error: %s failed: %v", operation, err), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"model":         model,
+		"executionTime": time.Since(startTime),
+	}).Info("Ollama operation completed")
+
+	return result, nil
+}
+
+// list formats the installed models.
+func (o *OllamaTool) list(ctx context.Context) (string, error) {
+	body, err := o.request(ctx, http.MethodGet, "/api/tags", nil)
+	if err != nil {
+		return "", err
+	}
+	var response struct {
+		Models []ollamaModel `json:"models"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("invalid response: %w", err)
+	}
+	if len(response.Models) == 0 {
+		return "No models are installed", nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "%-40s %10s %8s %-8s %s\n", "NAME", "SIZE", "PARAMS", "QUANT", "MODIFIED")
+	for _, model := range response.Models {
+		fmt.Fprintf(&result, "%-40s %10s %8s %-8s %s\n", model.Name, formatBytes(model.Size), model.Details.ParameterSize, model.Details.QuantizationLevel, model.ModifiedAt.Format("2006-01-02 15:04"))
+	}
+	return strings.TrimRight(result.String(), "\n"), nil
+}
+
+// running formats the models loaded in memory.
+func (o *OllamaTool) running(ctx context.Context) (string, error) {
+	body, err := o.request(ctx, http.MethodGet, "/api/ps", nil)
+	if err != nil {
+		return "", err
+	}
+	var response struct {
+		Models []ollamaModel `json:"models"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("invalid response: %w", err)
+	}
+	if len(response.Models) == 0 {
+		return "No models are loaded in memory", nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "%-40s %10s %10s %s\n", "NAME", "SIZE", "GPU", "UNLOADS")
+	for _, model := range response.Models {
+		gpu := "0%"
+		if model.Size > 0 {
+			gpu = fmt.Sprintf("%d%%", model.SizeVRAM*100/model.Size)
+		}
+		fmt.Fprintf(&result, "%-40s %10s %10s %s\n", model.Name, formatBytes(model.Size), gpu, model.ExpiresAt.Format("2006-01-02 15:04:05"))
+	}
+	return strings.TrimRight(result.String(), "\n"), nil
+}
+
+// show summarizes a model's details and parameters.
+func (o *OllamaTool) show(ctx context.Context, model string) (string, error) {
+	body, err := o.request(ctx, http.MethodPost, "/api/show", map[string]interface{}{"model": model})
+	if err != nil {
+		return "", err
+	}
+	var response struct {
+		Parameters string                 `json:"parameters"`
+		Details    map[string]interface{} `json:"details"`
+		ModelInfo  map[string]interface{} `json:"model_info"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("invalid response: %w", err)
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Model: %s\n", model)
+	for _, key := range []string{"family", "parameter_size", "quantization_level", "format"} {
+		if value, ok := response.Details[key]; ok {
+			fmt.Fprintf(&result, "%s: %v\n", key, value)
+		}
+	}
+	for key, value := range response.ModelInfo {
+		if strings.HasSuffix(key, ".context_length") {
+			fmt.Fprintf(&result, "context_length: %v\n", value)
+		}
+	}
+	if response.Parameters != "" {
+		fmt.Fprintf(&result, "Parameters:\n%s\n", strings.TrimSpace(response.Parameters))
+	}
+	return strings.TrimRight(result.String(), "\n"), nil
+}
+
+// request sends a request to the Ollama API and returns the response body.
+func (o *OllamaTool) request(ctx context.Context, method, path string, payload interface{}) ([]byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, o.endpoint+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach Ollama at %s: %w", o.endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("%s", apiErr.Error)
+		}
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// sameOllamaModel reports whether two model references name the same model; a missing tag means "latest".
+func sameOllamaModel(a, b string) bool {
+	normalize := func(name string) string {
+		if !strings.Contains(name, ":") {
+			name += ":latest"
+		}
+		return strings.ToLower(name)
+	}
+	return normalize(a) == normalize(b)
+}
+
+// formatBytes formats a byte count with binary units.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// Ensure OllamaTool implements the tools.Tool interface
+var _ tools.Tool = (*OllamaTool)(nil)
@@ -0,0 +1,157 @@
+/*
+Package tools provides sampled performance measurement capabilities for the Skynet Agent.
+
+This file implements the PerfTool, which runs vmstat, iostat, and mpstat for a few
+short sampling intervals. Unlike a single top snapshot, the samples show rates
+over time, which answers questions such as "is this box IO-bound?" (high iowait
+and device utilization) or "is one CPU saturated?" with real measurements.
+
+Supported reports:
+- vmstat: Run queue, memory, swap, I/O, and CPU breakdown including iowait and steal
+- iostat: Extended per-device utilization, queue size, and latency
+- mpstat: Per-CPU utilization
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// perfLogger provides structured logging for all performance measurements
+// with a consistent tool identifier for easy filtering and monitoring
+var perfLogger = logrus.WithField("tool", "perf")
+
+// Sampling bounds; the total sampling time is capped so measurements stay short
+const (
+	perfDefaultInterval = 1
+	perfDefaultCount    = 5
+	perfMaxInterval     = 10
+	perfMaxTotalSeconds = 30
+)
+
+// perfReports maps each report to its command and fixed arguments
+var perfReports = map[string][]string{
+	"vmstat": {"vmstat", "-w"},
+	"iostat": {"iostat", "-x", "-y"},
+	"mpstat": {"mpstat", "-P", "ALL"},
+}
+
+// perfPackages names the package providing each report's command on Alpine
+var perfPackages = map[string]string{
+	"vmstat": "procps",
+	"iostat": "sysstat",
+	"mpstat": "sysstat",
+}
+
+// PerfTool runs short sampled vmstat, iostat, and mpstat measurements.
+type PerfTool struct{}
+
+// NewPerfTool creates a new instance of the performance measurement tool.
+//
+// Returns:
+//   - *PerfTool: Configured perf tool ready for use
+func NewPerfTool() *PerfTool {
+	perfLogger.Debug("Initializing perf tool")
+	return &PerfTool{}
+}
+
+// Description returns a description of the perf tool's reports.
+//
+// Returns:
+//   - string: Description of the supported performance reports
+func (p *PerfTool) Description() string {
+	return fmt.Sprintf("Measure system performance over a few seconds. Usage: '<report> [interval] [count]' where report is 'vmstat' (CPU, memory, swap, run queue; high 'wa' means waiting on I/O), 'iostat' (per-disk utilization %%util, queue and latency; near 100%% util means IO-bound), or 'mpstat' (per-CPU usage). Defaults: %d second interval, %d samples; total sampling time is limited to %d seconds.",
+		perfDefaultInterval, perfDefaultCount, perfMaxTotalSeconds)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("perf")
+func (p *PerfTool) Name() string {
+	return "perf"
+}
+
+// Call runs the requested sampled report.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Report name with optional interval and count (e.g., "iostat 2 5")
+//
+// Returns:
+//   - string: Report output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (p *PerfTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := perfLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Perf tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.ToLower(strings.TrimSpace(input)))
+	if len(parts) == 0 {
+		return "Error: Please provide a report: vmstat, iostat, or mpstat", nil
+	}
+
+	report := parts[0]
+	command, ok := perfReports[report]
+	if !ok {
+		return fmt.Sprintf("Error: Unsupported report %q. Use vmstat, iostat, or mpstat", report), nil
+	}
+
+	interval, count := perfDefaultInterval, perfDefaultCount
+	if len(parts) > 1 {
+		val, err := strconv.Atoi(parts[1])
+		if err != nil || val <= 0 {
+			return fmt.Sprintf("Error: Invalid interval %q", parts[1]), nil
+		}
+		interval = val
+	}
+	if len(parts) > 2 {
+		val, err := strconv.Atoi(parts[2])
+		if err != nil || val <= 0 {
+			return fmt.Sprintf("Error: Invalid count %q", parts[2]), nil
+		}
+		count = val
+	}
+	if interval > perfMaxInterval {
+		interval = perfMaxInterval
+	}
+	if interval*count > perfMaxTotalSeconds {
+		count = perfMaxTotalSeconds / interval
+	}
+
+	args := append(append([]string{}, command[1:]...), strconv.Itoa(interval), strconv.Itoa(count))
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, command[0], args...)
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"report": report,
+			"output": string(output),
+		}).Error("Perf command failed")
+		if _, lookErr := exec.LookPath(command[0]); lookErr != nil {
+			return fmt.Sprintf("Error: %s is not installed (provided by the %s package)", command[0], perfPackages[report]), nil
+		}
+		return fmt.Sprintf("Error: %s failed: %s", command[0], strings.TrimSpace(string(output))), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"report":        report,
+		"interval":      interval,
+		"count":         count,
+		"executionTime": time.Since(startTime),
+	}).Info("Perf measurement completed")
+
+	return fmt.Sprintf("%s: %d samples at %d second intervals\n%s", report, count, interval, string(output)), nil
+}
+
+// Ensure PerfTool implements the tools.Tool interface
+var _ tools.Tool = (*PerfTool)(nil)
@@ -0,0 +1,193 @@
+/*
+Package tools provides Python package and virtual environment management for the Skynet Agent.
+
+This file implements the PipTool, which runs pip through the Python interpreter
+("python3 -m pip") so the agent can manage the dependencies of Python scripts it
+creates without guessing pip invocations through the shell tool.
+
+Supported operations:
+- list: Installed packages
+- install <package...>: Install packages (version specifiers such as "requests==2.31.0" are allowed)
+- uninstall <package...>: Remove packages
+- show <package>: Package metadata and dependencies
+- freeze: Installed packages in requirements format
+- venv <path>: Create a virtual environment
+
+Every pip operation accepts "--venv <path>" to act on a virtual environment
+instead of the system interpreter. Relative paths are resolved against the
+agent's working directory.
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// pipLogger provides structured logging for all pip operations
+// with a consistent tool identifier for easy filtering and monitoring
+var pipLogger = logrus.WithField("tool", "pip")
+
+// pipOperations maps each operation to its pip arguments (package names appended)
+var pipOperations = map[string][]string{
+	"list":      {"list"},
+	"install":   {"install", "--disable-pip-version-check"},
+	"uninstall": {"uninstall", "-y"},
+	"show":      {"show"},
+	"freeze":    {"freeze"},
+}
+
+// PipTool manages Python packages and virtual environments.
+type PipTool struct {
+	workspace *WorkspaceContext // Shared workspace providing the working directory for relative paths
+}
+
+// NewPipTool creates a new instance of the pip tool.
+// The tool requires python3 with the pip and venv modules.
+//
+// Parameters:
+//   - workspace: Shared workspace providing the working directory for relative paths
+//
+// Returns:
+//   - *PipTool: Configured pip tool ready for use
+func NewPipTool(workspace *WorkspaceContext) *PipTool {
+	pipLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing pip tool")
+	return &PipTool{workspace: workspace}
+}
+
+// Description returns a description of the pip tool's operations.
+//
+// Returns:
+//   - string: Description of the supported pip operations
+func (p *PipTool) Description() string {
+	return "Manage Python packages and virtual environments. Usage: 'list', 'install <package...>' (e.g. 'install requests==2.31.0', or 'install -r requirements.txt'), 'uninstall <package...>', 'show <package>', 'freeze', 'venv <path>' (create a virtual environment). Add '--venv <path>' to any pip operation to use that virtual environment, e.g. 'install requests --venv ./venv'. Prefer a virtual environment for script dependencies: the system interpreter may refuse installs (externally managed environment)."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("pip")
+func (p *PipTool) Name() string {
+	return "pip"
+}
+
+// Call runs the requested pip or venv operation.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Operation string (e.g., "install requests --venv ./venv", "venv ./venv")
+//
+// Returns:
+//   - string: pip output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (p *PipTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := pipLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Pip tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) > 0 && (parts[0] == "pip" || parts[0] == "pip3") {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return "Error: Please provide an operation: list, install, uninstall, show, freeze, or venv", nil
+	}
+
+	operation := strings.ToLower(parts[0])
+	args, venv := extractVenv(parts[1:])
+
+	python := "python3"
+	var cmdArgs []string
+	switch {
+	case operation == "venv":
+		if len(args) != 1 {
+			return "Error: Please provide the path of the virtual environment to create", nil
+		}
+		cmdArgs = []string{"-m", "venv", p.resolve(ctx, args[0])}
+
+	case pipOperations[operation] != nil:
+		if (operation == "install" || operation == "uninstall" || operation == "show") && len(args) == 0 {
+			return fmt.Sprintf("Error: Please provide at least one package for %s", operation), nil
+		}
+		if venv != "" {
+			python = filepath.Join(p.resolve(ctx, venv), "bin", "python")
+			if _, err := os.Stat(python); err != nil {
+				return fmt.Sprintf("Error: %s is not a virtual environment (no bin/python), create it with 'venv %s'", venv, venv), nil
+			}
+		}
+		cmdArgs = append(append([]string{"-m", "pip"}, pipOperations[operation]...), args...)
+
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use list, install, uninstall, show, freeze, or venv", operation), nil
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, python, cmdArgs...)
+	cmd.Dir = p.workspace.For(ctx).Dir()
+	cmd.Env = p.workspace.For(ctx).Environ()
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"operation": operation,
+			"output":    string(output),
+		}).Error("Pip command failed")
+
+		if _, lookErr := exec.LookPath(python); lookErr != nil {
+			return fmt.Sprintf("Error: %s is not installed or not accessible", python), nil
+		}
+		return fmt.Sprintf("Error: %s failed: %s", operation, strings.TrimSpace(string(output))), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"venv":          venv,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Pip command completed")
+
+	if operation == "venv" {
+		path := p.resolve(ctx, args[0])
+		return fmt.Sprintf("Created virtual environment %s (run scripts with %s)", path, filepath.Join(path, "bin", "python")), nil
+	}
+	if len(output) == 0 {
+		return fmt.Sprintf("%s completed with no output", operation), nil
+	}
+	return string(output), nil
+}
+
+// extractVenv removes a "--venv <path>" or "--venv=<path>" option from the arguments.
+func extractVenv(args []string) ([]string, string) {
+	var remaining []string
+	venv := ""
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--venv" && i+1 < len(args):
+			venv = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--venv="):
+			venv = strings.TrimPrefix(args[i], "--venv=")
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return remaining, venv
+}
+
+// resolve makes a path absolute relative to the working directory.
+func (p *PipTool) resolve(ctx context.Context, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.workspace.For(ctx).Dir(), path)
+}
+
+// Ensure PipTool implements the tools.Tool interface
+var _ tools.Tool = (*PipTool)(nil)
@@ -0,0 +1,131 @@
+/*
+Package tools provides pod log tailing for the Skynet Agent.
+
+This file implements the PodLogsTool, which follows the logs of a pod, or of the
+pods behind a workload or label selector, for a bounded time with
+"kubectl logs -f" and returns what was written meanwhile. It lets the agent
+watch a workload while reproducing a problem, which a one-off "kubectl logs"
+cannot show.
+*/
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// podLogsLogger provides structured logging for all pod log tailing
+var podLogsLogger = logrus.WithField("tool", "pod_logs")
+
+// Bounds of the time logs are followed
+const (
+	podLogsDefaultDuration = 30 * time.Second
+	podLogsMaxDuration     = 5 * time.Minute
+)
+
+// PodLogsTool follows pod logs for a bounded time.
+type PodLogsTool struct {
+	kubeconfig string // Kubeconfig file passed to kubectl; empty uses kubectl's own resolution
+	namespace  string // Default namespace for commands that do not select one
+}
+
+// NewPodLogsTool creates a new instance of the pod log tailing tool.
+//
+// Parameters:
+//   - kubeconfig: Kubeconfig file path; empty uses KUBECONFIG, ~/.kube/config, or the in-cluster service account
+//   - namespace: Default namespace; empty uses the kubeconfig context's namespace
+//
+// Returns:
+//   - *PodLogsTool: Configured tool ready for use
+func NewPodLogsTool(kubeconfig, namespace string) *PodLogsTool {
+	return &PodLogsTool{kubeconfig: kubeconfig, namespace: namespace}
+}
+
+// Name returns the identifier for this tool.
+func (p *PodLogsTool) Name() string {
+	return "pod_logs"
+}
+
+// Description returns a description of the tool's usage.
+func (p *PodLogsTool) Description() string {
+	return fmt.Sprintf("Follow Kubernetes pod logs for a while and return the lines written meanwhile. Usage: '<pod|deployment/name|-l app=web> [-c container] [--all-containers] [--for 30s]'. --for sets how long to follow (default %s, at most %s); the last 20 lines before following are included. Use kubectl logs for past logs.", podLogsDefaultDuration, podLogsMaxDuration)
+}
+
+// Call follows the selected logs until the duration elapses.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Log selection and options
+//
+// Returns:
+//   - string: Collected log lines or error message
+//   - error: Always nil (errors are returned as string messages)
+func (p *PodLogsTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := podLogsLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Pod logs tool called")
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	duration := podLogsDefaultDuration
+	args := []string{"logs", "-f", "--prefix"}
+	hasTail := false
+	for i := 0; i < len(parts); i++ {
+		switch part := parts[i]; {
+		case part == "--for" && i+1 < len(parts):
+			parsed, err := time.ParseDuration(parts[i+1])
+			if err != nil || parsed <= 0 {
+				return fmt.Sprintf("Error: invalid --for duration %q", parts[i+1]), nil
+			}
+			duration = parsed
+			i++
+		case part == "-f", part == "--follow":
+		default:
+			hasTail = hasTail || strings.HasPrefix(part, "--tail")
+			args = append(args, part)
+		}
+	}
+	if len(args) == 3 {
+		return "Error: Please name a pod, workload, or -l selector whose logs to follow", nil
+	}
+	if duration > podLogsMaxDuration {
+		duration = podLogsMaxDuration
+	}
+	if !hasTail {
+		args = append(args, "--tail=20")
+	}
+	if p.kubeconfig != "" {
+		args = append(args, "--kubeconfig", p.kubeconfig)
+	}
+	if p.namespace != "" && !selectsNamespace(parts) {
+		args = append(args, "--namespace", p.namespace)
+	}
+
+	followCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	cmd := targetCommand(followCtx, "kubectl", args...)
+	output, err := limitedCombinedOutput(ctx, cmd)
+
+	// Following ends when the time is up, which is the expected outcome
+	if err != nil && !errors.Is(followCtx.Err(), context.DeadlineExceeded) {
+		toolLogger.WithError(err).WithField("output", string(output)).Error("Following pod logs failed")
+		return fmt.Sprintf("Error: kubectl logs failed: %s", strings.TrimSpace(string(output))), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"duration":     duration,
+		"outputLength": len(output),
+	}).Info("Pod logs tool completed")
+
+	if len(output) == 0 {
+		return fmt.Sprintf("No log lines were written in %s", duration), nil
+	}
+	return fmt.Sprintf("Logs followed for %s:\n%s", duration, output), nil
+}
+
+// Ensure PodLogsTool implements the tools.Tool interface
+var _ tools.Tool = (*PodLogsTool)(nil)
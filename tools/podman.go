@@ -0,0 +1,139 @@
+/*
+Package tools provides Podman container and image management capabilities for the Skynet Agent.
+
+This file implements the PodmanTool, the counterpart of the DockerTool for hosts
+that run podman instead of docker. It accepts the same command syntax, so the
+agent manages containers the same way on either runtime.
+
+Supported operations:
+- Container Management: ps, logs, inspect, stats, run, stop, start, rm
+- Image Management: images, build, pull, push, rmi
+- Pods: pod create, pod ps, pod rm, and the other podman pod commands
+- System Operations: version, info, system commands
+
+ContainerRuntimeTools detects which runtimes are installed so that only the
+tools for runtimes actually present are offered to the agent.
+*/
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// podmanLogger provides structured logging for all Podman operations
+// with a consistent tool identifier for easy filtering and monitoring
+var podmanLogger = logrus.WithField("tool", "podman")
+
+// PodmanTool provides Podman container and image management capabilities.
+// It wraps the Podman CLI with the same interface as the DockerTool.
+type PodmanTool struct{}
+
+// NewPodmanTool creates a new instance of the Podman management tool.
+// The tool requires Podman to be installed and accessible in the system PATH.
+//
+// Returns:
+//   - *PodmanTool: Configured Podman tool ready for use
+func NewPodmanTool() *PodmanTool {
+	podmanLogger.Debug("Initializing podman tool")
+	return &PodmanTool{}
+}
+
+// ContainerRuntimeTools returns the tools for the container runtimes installed on the host.
+// Docker and Podman are offered when their CLIs are found in the system PATH; when
+// neither is found the DockerTool is returned so requests still get a clear error.
+//
+// Returns:
+//   - []tools.Tool: DockerTool and/or PodmanTool depending on the installed runtimes
+func ContainerRuntimeTools() []tools.Tool {
+	var runtimeTools []tools.Tool
+	if _, err := exec.LookPath("docker"); err == nil {
+		runtimeTools = append(runtimeTools, NewDockerTool())
+	}
+	if _, err := exec.LookPath("podman"); err == nil {
+		runtimeTools = append(runtimeTools, NewPodmanTool())
+	}
+	if len(runtimeTools) == 0 {
+		podmanLogger.Warn("No container runtime found, offering the docker tool")
+		runtimeTools = append(runtimeTools, NewDockerTool())
+	}
+	return runtimeTools
+}
+
+// Description returns a description of the Podman tool's capabilities.
+//
+// Returns:
+//   - string: Description of the supported Podman operations
+func (p *PodmanTool) Description() string {
+	return "Manage Podman containers, pods, and images (this host uses podman; the syntax matches docker). Supports all Podman commands including: 'ps' (list containers), 'images' (list images), 'logs <container>' (view logs), 'inspect <container>' (inspect container), 'stats --no-stream' (container stats), 'version', 'run', 'stop', 'start', 'rm', 'rmi', 'build', 'pull', 'push', 'pod ps' (list pods), etc."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("podman")
+func (p *PodmanTool) Name() string {
+	return "podman"
+}
+
+// Call executes a Podman command based on the provided input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Podman command string (e.g., "ps -a", "logs container_name")
+//
+// Returns:
+//   - string: Result of the Podman operation or error message
+//   - error: Always nil (errors are returned as string messages)
+func (p *PodmanTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := podmanLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Podman tool called")
+	startTime := time.Now()
+
+	// Parse the input command, tolerating a leading "podman"
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) > 0 && parts[0] == "podman" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		toolLogger.Warn("Empty podman command provided")
+		return "Error: Please provide a podman command. All Podman commands are supported.", nil
+	}
+
+	command := strings.ToLower(parts[0])
+
+	// Verify Podman availability before attempting operations
+	if _, err := exec.LookPath("podman"); err != nil {
+		toolLogger.WithError(err).Error("Podman not available")
+		return "Error: Podman is not installed or not accessible", nil
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "podman", parts...)
+	cmd.Env = sessionEnviron(ctx)
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"command": command,
+			"output":  string(output),
+		}).Error("Podman command failed")
+
+		return string(output), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"command":       command,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Podman command completed")
+
+	return string(output), nil
+}
+
+// Ensure PodmanTool implements the tools.Tool interface
+var _ tools.Tool = (*PodmanTool)(nil)
@@ -0,0 +1,82 @@
+/*
+Package tools provides the scheduling priority of tool commands in the Skynet Agent.
+
+The agent runs on the hosts it manages, next to their production workloads. A
+heavy diagnostic, such as a grep over a large tree or a tar of a big directory,
+competes with those workloads for CPU and disk. An execution attaches a
+SchedulingPriority to the context of each tool call with WithSchedulingPriority,
+and every command created with targetCommand runs at it: through nice(1) for a
+lower CPU priority and ionice(1) for a lower I/O priority, on the local host and
+on remote targets. Processes the command starts inherit the priority.
+*/
+package tools
+
+import (
+	"context"
+	"strconv"
+)
+
+// I/O scheduling classes of tool commands
+const (
+	IOClassBestEffort = "best-effort" // Served after realtime I/O, by level within the class
+	IOClassIdle       = "idle"        // Served only when no other process needs the disk
+)
+
+// SchedulingPriority is the CPU and I/O priority of a tool command. The zero value
+// leaves the priority of the server process unchanged.
+type SchedulingPriority struct {
+	Nice    int    // Niceness added to the command, 1 (slightly lower) to 19 (lowest); 0 leaves it unchanged
+	IOClass string // IOClassBestEffort or IOClassIdle; empty leaves the I/O priority unchanged
+	IOLevel int    // Level within the best-effort class, 0 (highest) to 7 (lowest)
+}
+
+// schedulingPriorityKey is the unexported context key type for scheduling priorities
+type schedulingPriorityKey struct{}
+
+// WithSchedulingPriority returns a copy of the parent context whose tool commands
+// run at priority.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - priority: Priority of the commands of the call
+//
+// Returns:
+//   - context.Context: Derived context carrying the priority
+func WithSchedulingPriority(ctx context.Context, priority SchedulingPriority) context.Context {
+	return context.WithValue(ctx, schedulingPriorityKey{}, priority)
+}
+
+// SchedulingPriorityFromContext returns the priority attached with
+// WithSchedulingPriority, or the zero priority.
+//
+// Parameters:
+//   - ctx: Context to inspect
+//
+// Returns:
+//   - SchedulingPriority: The priority of the context's commands
+func SchedulingPriorityFromContext(ctx context.Context) SchedulingPriority {
+	priority, _ := ctx.Value(schedulingPriorityKey{}).(SchedulingPriority)
+	return priority
+}
+
+// prioritizeCommand returns the command running name with args at the context's
+// priority: ionice and nice followed by the command, or the command itself when
+// the priority is unchanged.
+func prioritizeCommand(ctx context.Context, name string, args []string) (string, []string) {
+	priority := SchedulingPriorityFromContext(ctx)
+
+	// Numeric classes, which both util-linux and BusyBox ionice accept
+	switch priority.IOClass {
+	case IOClassBestEffort:
+		args = append([]string{"-c", "2", "-n", strconv.Itoa(priority.IOLevel), name}, args...)
+		name = "ionice"
+	case IOClassIdle:
+		args = append([]string{"-c", "3", name}, args...)
+		name = "ionice"
+	}
+	if priority.Nice > 0 {
+		args = append([]string{"-n", strconv.Itoa(priority.Nice), name}, args...)
+		name = "nice"
+	}
+	return name, args
+}
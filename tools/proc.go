@@ -0,0 +1,330 @@
+/*
+Package tools provides process control capabilities for the Skynet Agent.
+
+This file implements the ProcTool, which sends signals to processes by PID or by
+name, changes process priorities, and reports per-process details read from
+/proc. It lets the agent stop a runaway process without falling back to the raw
+shell tool.
+
+Signals and priority changes are refused for protected processes: PID 1, the
+agent itself, and any process whose name is in the configured protected list.
+
+Input is a JSON object, for example:
+
+	{"operation": "info", "pid": 4242}
+	{"operation": "kill", "pid": 4242, "signal": "TERM"}
+	{"operation": "killall", "name": "stress", "signal": "KILL"}
+	{"operation": "renice", "pid": 4242, "priority": 10}
+*/
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// procLogger provides structured logging for all process control operations
+// with a consistent tool identifier for easy filtering and monitoring
+var procLogger = logrus.WithField("tool", "proc")
+
+// procSignals maps accepted signal names to signals
+var procSignals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+	"CONT": syscall.SIGCONT,
+	"STOP": syscall.SIGSTOP,
+}
+
+// procStatusFields are the /proc/<pid>/status fields included in process details
+var procStatusFields = []string{"Name", "State", "PPid", "Uid", "Gid", "Threads", "VmPeak", "VmRSS", "VmSwap"}
+
+// procArgs holds the structured arguments of a process operation.
+type procArgs struct {
+	Operation string `json:"operation"`          // info, kill, killall, or renice
+	PID       int    `json:"pid,omitempty"`      // Target process ID
+	Name      string `json:"name,omitempty"`     // Process name for killall
+	Signal    string `json:"signal,omitempty"`   // Signal name (default: TERM)
+	Priority  *int   `json:"priority,omitempty"` // Nice value from -20 (highest) to 19 (lowest) for renice
+}
+
+// ProcTool signals, renices, and inspects processes.
+type ProcTool struct {
+	protected map[string]bool // Process names that must never be signalled or reniced
+}
+
+// NewProcTool creates a new instance of the process control tool.
+//
+// Parameters:
+//   - protected: Process names that must never be signalled or reniced
+//
+// Returns:
+//   - *ProcTool: Configured process tool ready for use
+func NewProcTool(protected []string) *ProcTool {
+	procLogger.WithField("protected", protected).Debug("Initializing proc tool")
+	names := make(map[string]bool, len(protected))
+	for _, name := range protected {
+		names[name] = true
+	}
+	return &ProcTool{protected: names}
+}
+
+// Description returns a description of the process tool's operations and input format.
+//
+// Returns:
+//   - string: Description of the supported process operations
+func (p *ProcTool) Description() string {
+	return "Control processes. Input is a JSON object: {\"operation\": \"info\", \"pid\": <pid>} (state, memory, command line, open files count), {\"operation\": \"kill\", \"pid\": <pid>, \"signal\": \"TERM\"} (send a signal: TERM, KILL, HUP, INT, QUIT, USR1, USR2, STOP, CONT), {\"operation\": \"killall\", \"name\": \"<process name>\", \"signal\": \"TERM\"} (signal every process with that name), {\"operation\": \"renice\", \"pid\": <pid>, \"priority\": <-20..19>} (change scheduling priority). Prefer TERM before KILL. System-critical processes are protected."
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (p *ProcTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"operation":{"type":"string","enum":["info","kill","killall","renice"]},"pid":{"type":"integer"},"name":{"type":"string"},"signal":{"type":"string"},"priority":{"type":"integer","minimum":-20,"maximum":19}},"required":["operation"]}`)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("proc")
+func (p *ProcTool) Name() string {
+	return "proc"
+}
+
+// Call performs the process operation described by the input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON operation object
+//
+// Returns:
+//   - string: Result of the operation or error message
+//   - error: Always nil (errors are returned as string messages)
+func (p *ProcTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := procLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Proc tool called")
+	startTime := time.Now()
+
+	var args procArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid proc arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+	if !isJSON {
+		return "Error: Please provide a JSON object with an operation (info, kill, killall, renice)", nil
+	}
+
+	// Processes are read from and signalled through this host's /proc
+	if refusal := localOnly(ctx, "The proc tool"); refusal != "" {
+		return refusal, nil
+	}
+
+	var result string
+	switch strings.ToLower(args.Operation) {
+	case "info":
+		result = p.info(args.PID)
+	case "kill":
+		result = p.kill(args.PID, args.Signal)
+	case "killall":
+		result = p.killall(args.Name, args.Signal)
+	case "renice":
+		result = p.renice(args.PID, args.Priority)
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use info, kill, killall, or renice", args.Operation), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     args.Operation,
+		"executionTime": time.Since(startTime),
+	}).Info("Proc operation completed")
+
+	return result, nil
+}
+
+// info reports details of a process from /proc.
+func (p *ProcTool) info(pid int) string {
+	if pid <= 0 {
+		return "Error: Please provide a pid"
+	}
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return fmt.Sprintf("Error: Process %d not found", pid)
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "PID: %d\n", pid)
+	for _, line := range strings.Split(string(status), "\n") {
+		for _, field := range procStatusFields {
+			if strings.HasPrefix(line, field+":") {
+				fmt.Fprintf(&result, "%s: %s\n", field, strings.Join(strings.Fields(strings.TrimPrefix(line, field+":")), " "))
+			}
+		}
+	}
+
+	if nice, err := syscall.Getpriority(syscall.PRIO_PROCESS, pid); err == nil {
+		// The raw syscall returns 20 - nice on Linux
+		fmt.Fprintf(&result, "Nice: %d\n", 20-nice)
+	}
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		fmt.Fprintf(&result, "Command: %s\n", strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " ")))
+	}
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		fmt.Fprintf(&result, "Executable: %s\n", exe)
+	}
+	if cwd, err := os.Readlink(filepath.Join(dir, "cwd")); err == nil {
+		fmt.Fprintf(&result, "Working directory: %s\n", cwd)
+	}
+	if fds, err := os.ReadDir(filepath.Join(dir, "fd")); err == nil {
+		fmt.Fprintf(&result, "Open files: %d\n", len(fds))
+	}
+
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// kill sends a signal to a single process.
+func (p *ProcTool) kill(pid int, signalName string) string {
+	if pid <= 0 {
+		return "Error: Please provide a pid"
+	}
+	signal, name, err := parseSignal(signalName)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if reason := p.protectedReason(pid); reason != "" {
+		procLogger.WithField("pid", pid).Warn("Refused to signal protected process")
+		return fmt.Sprintf("Error: Refusing to signal process %d: %s", pid, reason)
+	}
+
+	if err := syscall.Kill(pid, signal); err != nil {
+		procLogger.WithError(err).WithField("pid", pid).Error("Failed to signal process")
+		return fmt.Sprintf("Error: Failed to send SIG%s to process %d: %v", name, pid, err)
+	}
+	procLogger.WithFields(logrus.Fields{"pid": pid, "signal": name}).Warn("Signal sent to process")
+	return fmt.Sprintf("Sent SIG%s to process %d", name, pid)
+}
+
+// killall sends a signal to every process with the given name.
+func (p *ProcTool) killall(processName, signalName string) string {
+	if processName == "" {
+		return "Error: Please provide a process name"
+	}
+	signal, name, err := parseSignal(signalName)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	pids := findProcesses(processName)
+	if len(pids) == 0 {
+		return fmt.Sprintf("Error: No process named %q found", processName)
+	}
+
+	var result strings.Builder
+	for _, pid := range pids {
+		if reason := p.protectedReason(pid); reason != "" {
+			fmt.Fprintf(&result, "Skipped process %d: %s\n", pid, reason)
+			continue
+		}
+		if err := syscall.Kill(pid, signal); err != nil {
+			fmt.Fprintf(&result, "Failed to signal process %d: %v\n", pid, err)
+			continue
+		}
+		fmt.Fprintf(&result, "Sent SIG%s to process %d\n", name, pid)
+	}
+	procLogger.WithFields(logrus.Fields{"name": processName, "signal": name, "pids": pids}).Warn("Signal sent to processes by name")
+
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// renice changes the scheduling priority of a process.
+func (p *ProcTool) renice(pid int, priority *int) string {
+	if pid <= 0 {
+		return "Error: Please provide a pid"
+	}
+	if priority == nil || *priority < -20 || *priority > 19 {
+		return "Error: Please provide a priority between -20 (highest) and 19 (lowest)"
+	}
+	if reason := p.protectedReason(pid); reason != "" {
+		return fmt.Sprintf("Error: Refusing to renice process %d: %s", pid, reason)
+	}
+
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, *priority); err != nil {
+		procLogger.WithError(err).WithField("pid", pid).Error("Failed to renice process")
+		return fmt.Sprintf("Error: Failed to renice process %d: %v", pid, err)
+	}
+	procLogger.WithFields(logrus.Fields{"pid": pid, "priority": *priority}).Info("Process reniced")
+	return fmt.Sprintf("Set priority of process %d to %d", pid, *priority)
+}
+
+// protectedReason explains why a process must not be touched, or returns "" if it may be.
+func (p *ProcTool) protectedReason(pid int) string {
+	if pid == 1 {
+		return "PID 1 is the init process"
+	}
+	if pid == os.Getpid() {
+		return "it is the agent itself"
+	}
+	if name := processName(pid); p.protected[name] {
+		return fmt.Sprintf("%s is a protected process", name)
+	}
+	return ""
+}
+
+// parseSignal converts a signal name such as "TERM" or "SIGKILL" into a signal.
+func parseSignal(name string) (syscall.Signal, string, error) {
+	name = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+	if name == "" {
+		name = "TERM"
+	}
+	signal, ok := procSignals[name]
+	if !ok {
+		return 0, "", fmt.Errorf("unsupported signal %q, use TERM, KILL, HUP, INT, QUIT, USR1, USR2, STOP, or CONT", name)
+	}
+	return signal, name, nil
+}
+
+// processName returns the short command name of a process from /proc.
+func processName(pid int) string {
+	comm, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+// findProcesses returns the PIDs of all processes with the given name.
+func findProcesses(name string) []int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if processName(pid) == name {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// Ensure ProcTool implements the tools.Tool interface
+var _ tools.Tool = (*ProcTool)(nil)
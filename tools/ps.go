@@ -28,7 +28,7 @@ func (p *PsTool) Name() string {
 }
 
 func (p *PsTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := psLogger.WithField("input", input)
+	toolLogger := psLogger.WithContext(ctx).WithField("input", input)
 	toolLogger.Info("PS tool called")
 	startTime := time.Now()
 
@@ -39,12 +39,12 @@ func (p *PsTool) Call(ctx context.Context, input string) (string, error) {
 	// Handle different ps options
 	if len(args) == 0 || input == "" {
 		// Default: show user processes
-		cmd = exec.Command("ps", "-u", getUsername())
+		cmd = targetCommand(ctx, "ps", "-u", getUsername())
 	} else if len(args) >= 2 && args[0] == "grep" {
 		// Custom grep functionality
 		pattern := strings.Join(args[1:], " ")
-		psCmd := exec.Command("ps", "aux")
-		grepCmd := exec.Command("grep", "-i", pattern)
+		psCmd := targetCommand(ctx, "ps", "aux")
+		grepCmd := targetCommand(ctx, "grep", "-i", pattern)
 
 		// Pipe ps output to grep
 		pipe, err := psCmd.StdoutPipe()
@@ -60,7 +60,7 @@ func (p *PsTool) Call(ctx context.Context, input string) (string, error) {
 			return "Error: Failed to start ps command", nil
 		}
 
-		output, err := grepCmd.CombinedOutput()
+		output, err := limitedCombinedOutput(ctx, grepCmd)
 		if err := psCmd.Wait(); err != nil {
 			toolLogger.WithError(err).Error("PS command failed")
 		}
@@ -79,22 +79,14 @@ func (p *PsTool) Call(ctx context.Context, input string) (string, error) {
 		return string(output), nil
 	} else {
 		// Handle standard ps options directly
-		cmd = exec.Command("ps", args...)
+		cmd = targetCommand(ctx, "ps", args...)
 	}
 
-	// Execute command with timeout
-	cmdCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
-	defer cancel()
-	cmd = exec.CommandContext(cmdCtx, cmd.Path, cmd.Args[1:]...)
-
-	output, err := cmd.CombinedOutput()
+	// Execute command; the caller's context bounds execution time
+	output, err := limitedCombinedOutput(ctx, cmd)
 	if err != nil {
 		toolLogger.WithError(err).WithField("output", string(output)).Error("PS command failed")
 
-		if cmdCtx.Err() == context.DeadlineExceeded {
-			return "Error: PS command timed out after 15 seconds", nil
-		}
-
 		return string(output), nil
 	}
 
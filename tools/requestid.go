@@ -0,0 +1,38 @@
+/*
+Package tools provides request ID propagation for the Skynet Agent.
+
+The HTTP layer attaches the ID of each request to the execution context with
+WithRequestID. Tool loggers are created with the context of the call, so the
+request ID is added to every line they log and all log lines of one chat turn
+can be correlated.
+*/
+package tools
+
+import "context"
+
+// requestIDKey is the unexported context key type for request IDs
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of the parent context carrying a request ID.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - requestID: ID of the request the context belongs to
+//
+// Returns:
+//   - context.Context: Derived context carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID attached with WithRequestID.
+//
+// Parameters:
+//   - ctx: Context to inspect
+//
+// Returns:
+//   - string: The request ID, or an empty string if none is attached
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -24,7 +24,6 @@ package tools
 
 import (
 	"context"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -40,7 +39,7 @@ var shellLogger = logrus.WithField("tool", "shell")
 // It wraps the system shell to provide agent-accessible command execution with
 // full privileges, proper working directory management, and comprehensive logging.
 type ShellTool struct {
-	workingDir *string // Pointer to the working directory for command execution
+	workspace *WorkspaceContext // Shared workspace providing the working directory for command execution
 }
 
 // NewShellTool creates a new instance of the shell command execution tool.
@@ -48,13 +47,13 @@ type ShellTool struct {
 // and provides full shell access with root privileges.
 //
 // Parameters:
-//   - workingDir: Pointer to the working directory for command execution context
+//   - workspace: Shared workspace providing the working directory for command execution context
 //
 // Returns:
 //   - *ShellTool: Configured shell tool ready for command execution
-func NewShellTool(workingDir *string) *ShellTool {
+func NewShellTool(workspace *WorkspaceContext) *ShellTool {
 	shellLogger.Debug("Initializing shell tool")
-	return &ShellTool{workingDir: workingDir}
+	return &ShellTool{workspace: workspace}
 }
 
 // Description returns a comprehensive description of the shell tool's capabilities.
@@ -93,9 +92,9 @@ func (s *ShellTool) Name() string {
 //   - string: Command output (stdout and stderr combined) or error message
 //   - error: Always nil (errors are returned as string messages)
 func (s *ShellTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := shellLogger.WithFields(logrus.Fields{
+	toolLogger := shellLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"input":      input,
-		"workingDir": *s.workingDir,
+		"workingDir": s.workspace.For(ctx).Dir(),
 	})
 	toolLogger.Info("Shell tool called")
 	startTime := time.Now()
@@ -107,10 +106,11 @@ func (s *ShellTool) Call(ctx context.Context, input string) (string, error) {
 	}
 
 	// Execute command in working directory
-	cmd := exec.CommandContext(ctx, "bash", "-c", command)
-	cmd.Dir = *s.workingDir
+	cmd := targetCommand(ctx, "bash", "-c", command)
+	cmd.Dir = s.workspace.For(ctx).Dir()
+	cmd.Env = s.workspace.For(ctx).Environ()
 
-	output, err := cmd.CombinedOutput()
+	output, err := limitedCombinedOutput(ctx, cmd)
 
 	if err != nil {
 		toolLogger.WithError(err).WithField("command", command).Error("Shell command failed")
@@ -0,0 +1,156 @@
+/*
+Package tools provides disk health diagnostics for the Skynet Agent.
+
+This file implements the SmartTool, which wraps smartctl from smartmontools to
+read the SMART health status and attributes of disks and to start and follow
+drive self-tests, so failing hardware can be diagnosed and acted upon.
+
+Supported operations:
+- scan: List devices smartctl can query
+- health <device>: Overall health self-assessment
+- info <device>: Model, serial number, firmware, and capacity
+- attributes <device>: SMART attributes (reallocated sectors, pending sectors, temperature, ...)
+- test <device> [short|long|conveyance]: Start a self-test
+- status <device>: Self-test progress and log
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// smartLogger provides structured logging for all disk health operations
+// with a consistent tool identifier for easy filtering and monitoring
+var smartLogger = logrus.WithField("tool", "smart")
+
+// smartOperations maps each operation to its smartctl arguments (device appended)
+var smartOperations = map[string][]string{
+	"health":     {"-H"},
+	"info":       {"-i"},
+	"attributes": {"-A", "-H"},
+	"status":     {"-c", "-l", "selftest"},
+}
+
+// smartTestTypes are the self-test types accepted by the test operation
+var smartTestTypes = map[string]bool{"short": true, "long": true, "conveyance": true}
+
+// SmartTool reads disk SMART data and runs drive self-tests.
+type SmartTool struct{}
+
+// NewSmartTool creates a new instance of the disk health tool.
+// The tool requires smartctl (smartmontools) and root privileges.
+//
+// Returns:
+//   - *SmartTool: Configured SMART tool ready for use
+func NewSmartTool() *SmartTool {
+	smartLogger.Debug("Initializing smart tool")
+	return &SmartTool{}
+}
+
+// Description returns a description of the SMART tool's operations.
+//
+// Returns:
+//   - string: Description of the supported disk health operations
+func (s *SmartTool) Description() string {
+	return "Diagnose disk hardware health with smartctl. Usage: 'scan' (list disks), 'health <device>' (overall PASSED/FAILED assessment), 'info <device>' (model, serial, firmware), 'attributes <device>' (SMART attributes; non-zero Reallocated_Sector_Ct, Current_Pending_Sector, or Offline_Uncorrectable indicate a failing disk), 'test <device> [short|long]' (start a self-test), 'status <device>' (self-test progress and results). Devices are paths such as /dev/sda or /dev/nvme0."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("smart")
+func (s *SmartTool) Name() string {
+	return "smart"
+}
+
+// Call runs the requested smartctl operation.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Operation and device (e.g., "health /dev/sda", "test /dev/sda short")
+//
+// Returns:
+//   - string: smartctl output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (s *SmartTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := smartLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Smart tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) == 0 {
+		return "Error: Please provide an operation: scan, health, info, attributes, test, or status", nil
+	}
+
+	operation := strings.ToLower(parts[0])
+	var args []string
+	switch {
+	case operation == "scan":
+		args = []string{"--scan"}
+
+	case operation == "test" || smartOperations[operation] != nil:
+		if len(parts) < 2 {
+			return fmt.Sprintf("Error: Please provide a device for %s, e.g. /dev/sda", operation), nil
+		}
+		device := parts[1]
+		if !strings.HasPrefix(device, "/dev/") {
+			return fmt.Sprintf("Error: Invalid device %q, expected a path under /dev/", device), nil
+		}
+
+		if operation == "test" {
+			testType := "short"
+			if len(parts) > 2 {
+				testType = strings.ToLower(parts[2])
+			}
+			if !smartTestTypes[testType] {
+				return fmt.Sprintf("Error: Unsupported test type %q. Use short, long, or conveyance", testType), nil
+			}
+			args = []string{"-t", testType, device}
+		} else {
+			args = append(append([]string{}, smartOperations[operation]...), device)
+		}
+
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use scan, health, info, attributes, test, or status", operation), nil
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "smartctl", args...)
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		if _, lookErr := exec.LookPath("smartctl"); lookErr != nil {
+			return "Error: smartctl is not installed (provided by the smartmontools package)", nil
+		}
+
+		// smartctl reports disk problems through exit status bits while still printing
+		// the requested data, so only treat it as a failure when there is no output
+		exitErr, isExit := err.(*exec.ExitError)
+		if !isExit || exitErr.ExitCode()&0x3 != 0 || len(output) == 0 {
+			toolLogger.WithError(err).WithFields(logrus.Fields{
+				"operation": operation,
+				"output":    string(output),
+			}).Error("Smartctl command failed")
+			return fmt.Sprintf("Error: smartctl failed: %s", strings.TrimSpace(string(output))), nil
+		}
+		toolLogger.WithField("exitCode", exitErr.ExitCode()).Warn("Smartctl reported disk problems")
+		output = append(output, []byte(fmt.Sprintf("\nsmartctl exit status %d indicates disk problems (bit 3: failing, bit 4: prefail attributes at threshold, bit 5: attributes past threshold, bit 6: error log entries, bit 7: self-test errors)", exitErr.ExitCode()))...)
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Smartctl command completed")
+
+	return string(output), nil
+}
+
+// Ensure SmartTool implements the tools.Tool interface
+var _ tools.Tool = (*SmartTool)(nil)
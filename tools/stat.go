@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -14,12 +13,12 @@ import (
 var statLogger = logrus.WithField("tool", "stat")
 
 type StatTool struct {
-	workingDir *string
+	workspace *WorkspaceContext
 }
 
-func NewStatTool(workingDir *string) *StatTool {
-	statLogger.WithField("workingDir", *workingDir).Debug("Initializing stat tool")
-	return &StatTool{workingDir: workingDir}
+func NewStatTool(workspace *WorkspaceContext) *StatTool {
+	statLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing stat tool")
+	return &StatTool{workspace: workspace}
 }
 
 func (s *StatTool) Description() string {
@@ -31,9 +30,9 @@ func (s *StatTool) Name() string {
 }
 
 func (s *StatTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := statLogger.WithFields(logrus.Fields{
+	toolLogger := statLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"input":      input,
-		"workingDir": *s.workingDir,
+		"workingDir": s.workspace.For(ctx).Dir(),
 	})
 
 	toolLogger.Info("Stat tool called")
@@ -46,13 +45,13 @@ func (s *StatTool) Call(ctx context.Context, input string) (string, error) {
 	}
 
 	// Handle relative paths
-	if !filepath.IsAbs(targetPath) && s.workingDir != nil {
-		targetPath = filepath.Join(*s.workingDir, targetPath)
+	if !filepath.IsAbs(targetPath) && s.workspace != nil {
+		targetPath = filepath.Join(s.workspace.For(ctx).Dir(), targetPath)
 	}
 
 	// Execute stat command
-	cmd := exec.CommandContext(ctx, "stat", targetPath)
-	output, err := cmd.CombinedOutput()
+	cmd := targetCommand(ctx, "stat", targetPath)
+	output, err := limitedCombinedOutput(ctx, cmd)
 
 	if err != nil {
 		toolLogger.WithError(err).WithField("targetPath", targetPath).Error("stat command failed")
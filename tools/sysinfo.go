@@ -28,7 +28,7 @@ func (s *SysInfoTool) Name() string {
 }
 
 func (s *SysInfoTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := sysinfoLogger.WithField("input", input)
+	toolLogger := sysinfoLogger.WithContext(ctx).WithField("input", input)
 	toolLogger.Info("Sysinfo tool called")
 	startTime := time.Now()
 
@@ -44,34 +44,34 @@ func (s *SysInfoTool) Call(ctx context.Context, input string) (string, error) {
 	switch command {
 	case "all":
 		// Show basic system overview
-		cmd = exec.CommandContext(ctx, "uname", "-a")
+		cmd = targetCommand(ctx, "uname", "-a")
 
 	case "uname":
-		cmd = exec.CommandContext(ctx, "uname", "-a")
+		cmd = targetCommand(ctx, "uname", "-a")
 
 	case "uptime":
-		cmd = exec.CommandContext(ctx, "uptime")
+		cmd = targetCommand(ctx, "uptime")
 
 	case "free":
-		cmd = exec.CommandContext(ctx, "free", "-h")
+		cmd = targetCommand(ctx, "free", "-h")
 
 	case "df":
-		cmd = exec.CommandContext(ctx, "df", "-h")
+		cmd = targetCommand(ctx, "df", "-h")
 
 	case "lscpu":
-		cmd = exec.CommandContext(ctx, "lscpu")
+		cmd = targetCommand(ctx, "lscpu")
 
 	case "lsblk":
-		cmd = exec.CommandContext(ctx, "lsblk")
+		cmd = targetCommand(ctx, "lsblk")
 
 	case "mount":
-		cmd = exec.CommandContext(ctx, "mount")
+		cmd = targetCommand(ctx, "mount")
 
 	default:
 		return "Error: Unsupported sysinfo command. Supported: all, uname, uptime, free, df, lscpu, lsblk, mount", nil
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := limitedCombinedOutput(ctx, cmd)
 	if err != nil {
 		toolLogger.WithError(err).WithField("command", command).Error("Sysinfo command failed")
 		return string(output), nil
@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -28,7 +27,7 @@ func (s *SystemctlTool) Name() string {
 }
 
 func (s *SystemctlTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := systemctlLogger.WithField("input", input)
+	toolLogger := systemctlLogger.WithContext(ctx).WithField("input", input)
 	toolLogger.Info("Systemctl tool called")
 	startTime := time.Now()
 
@@ -41,22 +40,16 @@ func (s *SystemctlTool) Call(ctx context.Context, input string) (string, error)
 
 	command := strings.ToLower(parts[0])
 
-	// Execute command with timeout
-	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(cmdCtx, "systemctl", parts...)
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, "systemctl", parts...)
 
-	output, err := cmd.CombinedOutput()
+	output, err := limitedCombinedOutput(ctx, cmd)
 	if err != nil {
 		toolLogger.WithError(err).WithFields(logrus.Fields{
 			"command": command,
 			"output":  string(output),
 		}).Error("Systemctl command failed")
 
-		if cmdCtx.Err() == context.DeadlineExceeded {
-			return "Error: Systemctl command timed out after 30 seconds", nil
-		}
-
 		return string(output), nil
 	}
 
@@ -0,0 +1,166 @@
+/*
+Package tools provides execution targets for the Skynet Agent.
+
+A target is the host where the commands of the tools run. By default every
+command runs locally. A chat request can name another target, attached to the
+execution context with WithTarget, and the tools then run their commands there:
+
+	local              This host (default)
+	ssh:host           Over SSH, as in "ssh host"; user@host and host:port are accepted
+	docker:container   Inside a running container, as in "docker exec -i container"
+
+Tools create their commands with targetCommand, which wraps the command for the
+target's backend and applies the resource limits and scheduling priority of the
+context (see limits.go and priority.go). SSH uses the server's SSH client
+configuration and keys in batch mode, so hosts must accept key authentication
+without a prompt. The command runs in the session's working directory when that
+exists on the target. Session environment variables apply only to local
+commands. Operations that work without running a command, such as writing a
+file in Go or signalling a process, run a command on remote targets instead or
+refuse them with localOnly, so they never act on this host by mistake.
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+)
+
+// Target kinds
+const (
+	TargetLocal  = "local"
+	TargetSSH    = "ssh"
+	TargetDocker = "docker"
+)
+
+// Target is a host where tool commands execute.
+type Target struct {
+	Kind string // TargetLocal, TargetSSH, or TargetDocker
+	Host string // SSH destination or container name; empty for local
+}
+
+// ParseTarget parses a target specification such as "local", "ssh:web1", or "docker:postgres".
+//
+// Parameters:
+//   - spec: Target specification; empty means local
+//
+// Returns:
+//   - Target: The parsed target
+//   - error: Unknown kind or missing host
+func ParseTarget(spec string) (Target, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == TargetLocal {
+		return Target{Kind: TargetLocal}, nil
+	}
+	kind, host, found := strings.Cut(spec, ":")
+	host = strings.TrimSpace(host)
+	if !found || host == "" {
+		return Target{}, fmt.Errorf("invalid target %q; use local, ssh:<host>, or docker:<container>", spec)
+	}
+	switch kind {
+	case TargetSSH, TargetDocker:
+	default:
+		return Target{}, fmt.Errorf("unknown target kind %q in %q; use local, ssh, or docker", kind, spec)
+	}
+	if strings.HasPrefix(host, "-") {
+		return Target{}, fmt.Errorf("invalid target host %q", host)
+	}
+	return Target{Kind: kind, Host: host}, nil
+}
+
+// String returns the target specification.
+func (t Target) String() string {
+	if t.Kind == "" || t.Kind == TargetLocal {
+		return TargetLocal
+	}
+	return t.Kind + ":" + t.Host
+}
+
+// targetKey is the unexported context key type for execution targets
+type targetKey struct{}
+
+// WithTarget returns a copy of the parent context whose tool commands run on target.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - target: Host where the execution's commands run
+//
+// Returns:
+//   - context.Context: Derived context carrying the target
+func WithTarget(ctx context.Context, target Target) context.Context {
+	return context.WithValue(ctx, targetKey{}, target)
+}
+
+// TargetFromContext returns the target attached with WithTarget, or the local target.
+//
+// Parameters:
+//   - ctx: Context to inspect
+//
+// Returns:
+//   - Target: The execution's target
+func TargetFromContext(ctx context.Context) Target {
+	if target, ok := ctx.Value(targetKey{}).(Target); ok {
+		return target
+	}
+	return Target{Kind: TargetLocal}
+}
+
+// isLocalTarget reports whether the execution's commands run on this host.
+func isLocalTarget(ctx context.Context) bool {
+	target := TargetFromContext(ctx)
+	return target.Kind == TargetLocal || target.Kind == ""
+}
+
+// localOnly returns an error message when the execution's target is not this
+// host, for operations that can only act on this host, or "" when they may run.
+func localOnly(ctx context.Context, operation string) string {
+	if isLocalTarget(ctx) {
+		return ""
+	}
+	return fmt.Sprintf("Error: %s is only supported on the local target, not %s", operation, TargetFromContext(ctx))
+}
+
+// targetCommand creates the command running name with args on the execution's
+// target. For local targets it is exec.CommandContext; for remote targets the
+// returned command runs the target's client, which forwards stdin and output.
+func targetCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	target := TargetFromContext(ctx)
+	name, args = limitCommand(ctx, name, args)
+	name, args = prioritizeCommand(ctx, name, args)
+	if isLocalTarget(ctx) {
+		cmd := exec.CommandContext(ctx, name, args...)
+		startInCgroup(cmd, ResourceLimitsFromContext(ctx))
+		return cmd
+	}
+
+	// Run in the session's working directory when the target has it
+	remote := "exec " + shellQuote(name)
+	for _, arg := range args {
+		remote += " " + shellQuote(arg)
+	}
+	if workspace, ok := WorkspaceFromContext(ctx); ok {
+		remote = "cd " + shellQuote(workspace.Dir()) + " 2>/dev/null; " + remote
+	}
+
+	switch target.Kind {
+	case TargetDocker:
+		return exec.CommandContext(ctx, "docker", "exec", "-i", target.Host, "sh", "-c", remote)
+	default:
+		sshArgs := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+		host := target.Host
+		if h, port, err := net.SplitHostPort(host); err == nil {
+			host = h
+			sshArgs = append(sshArgs, "-p", port)
+		}
+		sshArgs = append(sshArgs, "--", host, remote)
+		return exec.CommandContext(ctx, "ssh", sshArgs...)
+	}
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -2,7 +2,8 @@ package tools
 
 import (
 	"context"
-	"os/exec"
+	"encoding/json"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
@@ -13,17 +14,29 @@ import (
 
 var teeLogger = logrus.WithField("tool", "tee")
 
+// teeArgs holds the structured arguments of a tee invocation.
+type teeArgs struct {
+	File    string `json:"file"`             // Target file path
+	Content string `json:"content"`          // Text written to the file and echoed back
+	Append  bool   `json:"append,omitempty"` // Append instead of overwriting
+}
+
 type TeeTool struct {
-	workingDir *string
+	workspace *WorkspaceContext
 }
 
-func NewTeeTool(workingDir *string) *TeeTool {
-	teeLogger.WithField("workingDir", *workingDir).Debug("Initializing tee tool")
-	return &TeeTool{workingDir: workingDir}
+func NewTeeTool(workspace *WorkspaceContext) *TeeTool {
+	teeLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing tee tool")
+	return &TeeTool{workspace: workspace}
 }
 
 func (t *TeeTool) Description() string {
-	return "Write input to both stdout and file(s). Usage: 'tee <file> <input>' (write to file and display), 'tee -a <file> <input>' (append to file and display)."
+	return "Write input to both stdout and file(s). Preferred input is a JSON object: {\"file\": \"<path>\", \"content\": \"<text>\", \"append\": false}, which supports paths with spaces and multi-line content. Legacy usage is also accepted: '<file> <input>' (write to file and display), '-a <file> <input>' (append to file and display)."
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (t *TeeTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"file":{"type":"string"},"content":{"type":"string"},"append":{"type":"boolean"}},"required":["file","content"]}`)
 }
 
 func (t *TeeTool) Name() string {
@@ -31,51 +44,64 @@ func (t *TeeTool) Name() string {
 }
 
 func (t *TeeTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := teeLogger.WithFields(logrus.Fields{
+	toolLogger := teeLogger.WithContext(ctx).WithFields(logrus.Fields{
 		"input":      input,
-		"workingDir": *t.workingDir,
+		"workingDir": t.workspace.For(ctx).Dir(),
 	})
 	toolLogger.Info("Tee tool called")
 	startTime := time.Now()
 
-	parts := strings.Fields(strings.TrimSpace(input))
-	if len(parts) < 2 {
-		toolLogger.Warn("Insufficient arguments provided")
-		return "Error: Please provide a filename and input text", nil
+	var parsed teeArgs
+	isJSON, parseErr := parseJSONArgs(input, &parsed)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid tee arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
 	}
 
-	var args []string
-	var filename string
-	var content string
+	if !isJSON {
+		parts := strings.Fields(strings.TrimSpace(input))
+		if len(parts) < 2 {
+			toolLogger.Warn("Insufficient arguments provided")
+			return "Error: Please provide a filename and input text", nil
+		}
 
-	// Check for append flag
-	if parts[0] == "-a" {
-		if len(parts) < 3 {
-			return "Error: Please provide a filename and input text after -a flag", nil
+		// Check for append flag
+		if parts[0] == "-a" {
+			if len(parts) < 3 {
+				return "Error: Please provide a filename and input text after -a flag", nil
+			}
+			parsed = teeArgs{File: parts[1], Content: strings.Join(parts[2:], " "), Append: true}
+		} else {
+			parsed = teeArgs{File: parts[0], Content: strings.Join(parts[1:], " ")}
 		}
+	}
+
+	if parsed.File == "" {
+		return "Error: Please provide a filename", nil
+	}
+
+	var args []string
+	if parsed.Append {
 		args = append(args, "-a")
-		filename = parts[1]
-		content = strings.Join(parts[2:], " ")
-	} else {
-		filename = parts[0]
-		content = strings.Join(parts[1:], " ")
 	}
+	filename := parsed.File
+	content := parsed.Content
 
 	// Handle relative paths
 	if !filepath.IsAbs(filename) {
-		filename = filepath.Join(*t.workingDir, filename)
+		filename = filepath.Join(t.workspace.For(ctx).Dir(), filename)
 	}
 
 	args = append(args, filename)
 
 	// Execute tee command
-	cmd := exec.CommandContext(ctx, "tee", args...)
-	cmd.Dir = *t.workingDir
+	cmd := targetCommand(ctx, "tee", args...)
+	cmd.Dir = t.workspace.For(ctx).Dir()
 
 	// Provide input to tee
 	cmd.Stdin = strings.NewReader(content)
 
-	output, err := cmd.CombinedOutput()
+	output, err := limitedCombinedOutput(ctx, cmd)
 	if err != nil {
 		toolLogger.WithError(err).WithField("filename", filename).Error("Tee command failed")
 		return string(output), nil
@@ -0,0 +1,135 @@
+/*
+Package tools provides command-template tools for the Skynet Agent.
+
+This file implements the TemplateTool, a tool defined at runtime by a name, a
+description, and a command template such as "kubectl {{.args}}". The agent's
+Action Input is rendered into the template and the resulting command line is
+executed directly, without a shell, so shell metacharacters in the input are
+passed as literal arguments rather than interpreted. The template must start with
+a fixed executable, so the input can only add arguments to it and never choose
+the program that runs.
+
+Template fields:
+- {{.args}}: The complete Action Input provided by the agent
+- {{.workingDir}}: The agent's current working directory
+*/
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// templateLogger provides structured logging for all template tool executions
+var templateLogger = logrus.WithField("tool", "template")
+
+// TemplateTool executes a command rendered from a template and the agent's input.
+type TemplateTool struct {
+	name        string             // Tool identifier exposed to the agent
+	description string             // Usage description exposed to the agent
+	command     *template.Template // Parsed command template
+	workspace   *WorkspaceContext  // Shared workspace providing the working directory
+}
+
+// NewTemplateTool creates a tool that runs the given command template.
+//
+// Parameters:
+//   - name: Tool identifier exposed to the agent
+//   - description: Usage description exposed to the agent
+//   - commandTemplate: Go text/template command line, e.g. "kubectl {{.args}}"
+//   - workspace: Shared workspace providing the working directory
+//
+// Returns:
+//   - *TemplateTool: Configured tool ready for use
+//   - error: Template parse error
+func NewTemplateTool(name, description, commandTemplate string, workspace *WorkspaceContext) (*TemplateTool, error) {
+	fields := strings.Fields(commandTemplate)
+	if len(fields) == 0 || strings.Contains(fields[0], "{{") {
+		return nil, fmt.Errorf("command template must start with a fixed executable, such as \"kubectl {{.args}}\"")
+	}
+	command, err := template.New(name).Option("missingkey=error").Parse(commandTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command template: %w", err)
+	}
+
+	templateLogger.WithFields(logrus.Fields{
+		"name":     name,
+		"template": commandTemplate,
+	}).Debug("Initializing template tool")
+	return &TemplateTool{
+		name:        name,
+		description: description,
+		command:     command,
+		workspace:   workspace,
+	}, nil
+}
+
+// Description returns the operator-provided usage description.
+func (t *TemplateTool) Description() string {
+	return t.description
+}
+
+// Name returns the operator-provided tool identifier.
+func (t *TemplateTool) Name() string {
+	return t.name
+}
+
+// Call renders the command template with the agent's input and executes it.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Arguments substituted for {{.args}}
+//
+// Returns:
+//   - string: Combined command output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (t *TemplateTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := templateLogger.WithContext(ctx).WithFields(logrus.Fields{
+		"name":  t.name,
+		"input": input,
+	})
+	toolLogger.Info("Template tool called")
+	startTime := time.Now()
+
+	var rendered bytes.Buffer
+	data := map[string]string{
+		"args":       strings.TrimSpace(input),
+		"workingDir": t.workspace.For(ctx).Dir(),
+	}
+	if err := t.command.Execute(&rendered, data); err != nil {
+		toolLogger.WithError(err).Error("Failed to render command template")
+		return fmt.Sprintf("Error: failed to render command: %v", err), nil
+	}
+
+	parts := strings.Fields(rendered.String())
+	if len(parts) == 0 {
+		return "Error: rendered command is empty", nil
+	}
+
+	cmd := targetCommand(ctx, parts[0], parts[1:]...)
+	cmd.Dir = t.workspace.For(ctx).Dir()
+	cmd.Env = t.workspace.For(ctx).Environ()
+
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithField("command", rendered.String()).Error("Template command failed")
+		return fmt.Sprintf("%s\nError: %v", string(output), err), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"command":       rendered.String(),
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Template command completed")
+
+	return string(output), nil
+}
+
+var _ tools.Tool = (*TemplateTool)(nil)
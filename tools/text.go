@@ -0,0 +1,253 @@
+/*
+Package tools provides sed and awk text transformations for the Skynet Agent.
+
+This file implements the TextTool, which applies a sed expression or an awk
+program to a file or to given text. Editing a single line of a configuration
+file no longer requires rewriting the whole file through the file tool.
+
+In-place edits keep a timestamped backup of the original file by default and
+replace the file atomically, preserving its permissions. Without inPlace the
+transformed text is only returned, which is useful to preview an edit.
+
+Input is a JSON object, for example:
+
+	{"operation": "sed", "path": "/etc/ssh/sshd_config", "expression": "s/^PermitRootLogin yes/PermitRootLogin no/", "inPlace": true}
+	{"operation": "awk", "path": "/etc/passwd", "separator": ":", "program": "$3 >= 1000 {print $1}"}
+	{"operation": "awk", "text": "a 1\nb 2", "program": "{s += $2} END {print s}"}
+*/
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// textLogger provides structured logging for all text transformations
+// with a consistent tool identifier for easy filtering and monitoring
+var textLogger = logrus.WithField("tool", "text")
+
+// textArgs holds the structured arguments of a text transformation.
+type textArgs struct {
+	Operation  string `json:"operation"`            // sed or awk
+	Path       string `json:"path,omitempty"`       // File to transform
+	Text       string `json:"text,omitempty"`       // Text to transform when no path is given
+	Expression string `json:"expression,omitempty"` // sed expression
+	Program    string `json:"program,omitempty"`    // awk program
+	Separator  string `json:"separator,omitempty"`  // awk field separator (-F)
+	InPlace    bool   `json:"inPlace,omitempty"`    // Write the result back to the file
+	Backup     *bool  `json:"backup,omitempty"`     // Keep a backup before an in-place edit (default: true)
+}
+
+// TextTool transforms files and text with sed and awk.
+type TextTool struct {
+	workspace *WorkspaceContext // Shared workspace providing the working directory for relative paths
+}
+
+// NewTextTool creates a new instance of the text transformation tool.
+//
+// Parameters:
+//   - workspace: Shared workspace providing the working directory for relative paths
+//
+// Returns:
+//   - *TextTool: Configured text tool ready for use
+func NewTextTool(workspace *WorkspaceContext) *TextTool {
+	textLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing text tool")
+	return &TextTool{workspace: workspace}
+}
+
+// Description returns a description of the text tool's operations and input format.
+//
+// Returns:
+//   - string: Description of the supported text transformations
+func (t *TextTool) Description() string {
+	return "Transform text with sed or awk, e.g. to change one line of a config file without rewriting it. Input is a JSON object: {\"operation\": \"sed\", \"path\": \"<file>\", \"expression\": \"s/old/new/\", \"inPlace\": true} or {\"operation\": \"awk\", \"path\": \"<file>\", \"program\": \"{print $1}\", \"separator\": \":\"}. Use \"text\" instead of \"path\" to transform given text. Without inPlace the result is only shown (preview). In-place edits keep a backup of the original unless \"backup\": false."
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (t *TextTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"operation":{"type":"string","enum":["sed","awk"]},"path":{"type":"string"},"text":{"type":"string"},"expression":{"type":"string"},"program":{"type":"string"},"separator":{"type":"string"},"inPlace":{"type":"boolean"},"backup":{"type":"boolean"}},"required":["operation"]}`)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("text")
+func (t *TextTool) Name() string {
+	return "text"
+}
+
+// Call performs the text transformation described by the input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON transformation object
+//
+// Returns:
+//   - string: Transformed text or in-place edit summary, or an error message
+//   - error: Always nil (errors are returned as string messages)
+func (t *TextTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := textLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Text tool called")
+	startTime := time.Now()
+
+	var args textArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid text arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+	if !isJSON {
+		return "Error: Please provide a JSON object with operation and expression or program", nil
+	}
+
+	var cmdArgs []string
+	operation := strings.ToLower(args.Operation)
+	switch operation {
+	case "sed":
+		if args.Expression == "" {
+			return "Error: Please provide a sed expression", nil
+		}
+		cmdArgs = []string{"-e", args.Expression}
+	case "awk":
+		if args.Program == "" {
+			return "Error: Please provide an awk program", nil
+		}
+		if args.Separator != "" {
+			cmdArgs = append(cmdArgs, "-F", args.Separator)
+		}
+		cmdArgs = append(cmdArgs, "--", args.Program)
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use sed or awk", args.Operation), nil
+	}
+
+	var original []byte
+	var path string
+	if args.Path != "" {
+		// Files are read and edited in place on this host
+		if refusal := localOnly(ctx, "Reading files with the text tool"); refusal != "" {
+			return refusal + "; pass the content as text instead", nil
+		}
+		path = args.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(t.workspace.For(ctx).Dir(), path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Sprintf("Error: Failed to read %s: %v", path, err), nil
+		}
+		original = content
+	} else {
+		if args.InPlace {
+			return "Error: inPlace requires a path", nil
+		}
+		original = []byte(args.Text)
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, operation, cmdArgs...)
+	cmd.Dir = t.workspace.For(ctx).Dir()
+	cmd.Stdin = bytes.NewReader(original)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	transformed, err := limitedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithField("stderr", stderr.String()).Error("Text transformation failed")
+		return fmt.Sprintf("Error: %s failed: %s", operation, strings.TrimSpace(stderr.String())), nil
+	}
+
+	if !args.InPlace {
+		toolLogger.WithFields(logrus.Fields{
+			"operation":     operation,
+			"executionTime": time.Since(startTime),
+		}).Info("Text transformation completed")
+		if len(transformed) == 0 {
+			return fmt.Sprintf("%s produced no output", operation), nil
+		}
+		return string(transformed), nil
+	}
+
+	if bytes.Equal(original, transformed) {
+		return fmt.Sprintf("No changes: the %s transformation left %s unchanged", operation, path), nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Sprintf("Error: Failed to stat %s: %v", path, err), nil
+	}
+
+	backupPath := ""
+	if args.Backup == nil || *args.Backup {
+		backupPath = fmt.Sprintf("%s.bak.%s", path, time.Now().Format("20060102150405"))
+		if err := os.WriteFile(backupPath, original, info.Mode().Perm()); err != nil {
+			toolLogger.WithError(err).WithField("backup", backupPath).Error("Failed to write backup")
+			return fmt.Sprintf("Error: Failed to write backup %s: %v", backupPath, err), nil
+		}
+	}
+
+	// Replace the file atomically so readers never see a partial edit
+	tmpPath := fmt.Sprintf("%s.tmp.%d", path, time.Now().UnixNano())
+	if err := os.WriteFile(tmpPath, transformed, info.Mode().Perm()); err != nil {
+		return fmt.Sprintf("Error: Failed to write %s: %v", tmpPath, err), nil
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Sprintf("Error: Failed to replace %s: %v", path, err), nil
+	}
+
+	changed := changedLines(string(original), string(transformed))
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"path":          path,
+		"backup":        backupPath,
+		"changedLines":  len(changed),
+		"executionTime": time.Since(startTime),
+	}).Info("In-place text edit completed")
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Edited %s with %s", path, operation)
+	if backupPath != "" {
+		fmt.Fprintf(&result, " (backup: %s)", backupPath)
+	}
+	fmt.Fprintf(&result, "\n%d lines changed", len(changed))
+	for i, line := range changed {
+		if i == 20 {
+			result.WriteString("\n...")
+			break
+		}
+		result.WriteString("\n" + line)
+	}
+	return result.String(), nil
+}
+
+// changedLines lists the lines that differ between two texts, compared position by position.
+func changedLines(before, after string) []string {
+	oldLines := strings.Split(before, "\n")
+	newLines := strings.Split(after, "\n")
+
+	var changed []string
+	for i := 0; i < len(oldLines) || i < len(newLines); i++ {
+		var oldLine, newLine string
+		if i < len(oldLines) {
+			oldLine = oldLines[i]
+		}
+		if i < len(newLines) {
+			newLine = newLines[i]
+		}
+		if oldLine != newLine {
+			changed = append(changed, fmt.Sprintf("line %d: %q -> %q", i+1, oldLine, newLine))
+		}
+	}
+	return changed
+}
+
+// Ensure TextTool implements the tools.Tool interface
+var _ tools.Tool = (*TextTool)(nil)
@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"os/exec"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -27,13 +26,13 @@ func (t *TopTool) Name() string {
 }
 
 func (t *TopTool) Call(ctx context.Context, input string) (string, error) {
-	toolLogger := topLogger.WithField("input", input)
+	toolLogger := topLogger.WithContext(ctx).WithField("input", input)
 	toolLogger.Info("Top tool called")
 	startTime := time.Now()
 
 	// Use top with batch mode for one-time output
-	cmd := exec.CommandContext(ctx, "top", "-b", "-n", "1")
-	output, err := cmd.CombinedOutput()
+	cmd := targetCommand(ctx, "top", "-b", "-n", "1")
+	output, err := limitedCombinedOutput(ctx, cmd)
 
 	if err != nil {
 		toolLogger.WithError(err).Error("top command failed")
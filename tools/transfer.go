@@ -0,0 +1,225 @@
+/*
+Package tools provides remote file transfer capabilities for the Skynet Agent.
+
+This file implements the TransferTool, which copies files and directories to and
+from remote hosts with rsync (preferred) or scp. Remote locations use the usual
+"[user@]host:path" syntax; local paths are resolved against the agent's working
+directory. SSH runs in batch mode, so transfers rely on key-based authentication
+and never hang on a password prompt.
+
+Input is a JSON object, for example:
+
+	{"source": "dist/", "destination": "deploy@web1:/srv/app/", "recursive": true, "dryRun": true, "bandwidthLimit": 5000}
+*/
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// transferLogger provides structured logging for all file transfers
+// with a consistent tool identifier for easy filtering and monitoring
+var transferLogger = logrus.WithField("tool", "transfer")
+
+// transferSSHOptions make SSH fail instead of prompting for passwords or host keys
+var transferSSHOptions = []string{"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=accept-new"}
+
+// transferArgs holds the structured arguments of a file transfer.
+type transferArgs struct {
+	Method         string `json:"method,omitempty"`         // rsync or scp (default: rsync)
+	Source         string `json:"source"`                   // Local path or [user@]host:path
+	Destination    string `json:"destination"`              // Local path or [user@]host:path
+	Recursive      bool   `json:"recursive,omitempty"`      // Copy directories recursively
+	DryRun         bool   `json:"dryRun,omitempty"`         // Show what would be transferred without copying
+	BandwidthLimit int    `json:"bandwidthLimit,omitempty"` // Bandwidth limit in KB/s
+	Delete         bool   `json:"delete,omitempty"`         // rsync only: delete destination files missing from the source
+	Port           int    `json:"port,omitempty"`           // SSH port (default: 22)
+}
+
+// TransferTool copies files to and from remote hosts with rsync or scp.
+type TransferTool struct {
+	workspace *WorkspaceContext // Shared workspace providing the working directory for relative local paths
+}
+
+// NewTransferTool creates a new instance of the file transfer tool.
+//
+// Parameters:
+//   - workspace: Shared workspace providing the working directory for relative local paths
+//
+// Returns:
+//   - *TransferTool: Configured transfer tool ready for use
+func NewTransferTool(workspace *WorkspaceContext) *TransferTool {
+	transferLogger.WithField("workingDir", workspace.Dir()).Debug("Initializing transfer tool")
+	return &TransferTool{workspace: workspace}
+}
+
+// Description returns a description of the transfer tool's options and input format.
+//
+// Returns:
+//   - string: Description of the supported transfer options
+func (t *TransferTool) Description() string {
+	return "Copy files and directories to or from remote hosts over SSH with rsync or scp. Input is a JSON object: {\"source\": \"<path or user@host:path>\", \"destination\": \"<path or user@host:path>\", \"method\": \"rsync|scp\", \"recursive\": true, \"dryRun\": true, \"bandwidthLimit\": <KB/s>, \"delete\": false, \"port\": 22}; only source and destination are required. Use dryRun to preview an rsync transfer. SSH key authentication is required."
+}
+
+// InputSchema returns the JSON schema of the structured arguments accepted by Call.
+func (t *TransferTool) InputSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"method":{"type":"string","enum":["rsync","scp"]},"source":{"type":"string"},"destination":{"type":"string"},"recursive":{"type":"boolean"},"dryRun":{"type":"boolean"},"bandwidthLimit":{"type":"integer"},"delete":{"type":"boolean"},"port":{"type":"integer"}},"required":["source","destination"]}`)
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("transfer")
+func (t *TransferTool) Name() string {
+	return "transfer"
+}
+
+// Call performs the file transfer described by the input.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: JSON transfer description
+//
+// Returns:
+//   - string: Transfer output and statistics, or an error message
+//   - error: Always nil (errors are returned as string messages)
+func (t *TransferTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := transferLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Transfer tool called")
+	startTime := time.Now()
+
+	var args transferArgs
+	isJSON, parseErr := parseJSONArgs(input, &args)
+	if parseErr != nil {
+		toolLogger.WithError(parseErr).Warn("Invalid transfer arguments")
+		return fmt.Sprintf("Error: %v", parseErr), nil
+	}
+	if !isJSON {
+		return "Error: Please provide a JSON object with source and destination", nil
+	}
+	if args.Source == "" || args.Destination == "" {
+		return "Error: Please provide both source and destination", nil
+	}
+	if strings.HasPrefix(args.Source, "-") || strings.HasPrefix(args.Destination, "-") {
+		return "Error: Source and destination must not start with '-'", nil
+	}
+
+	source := t.resolve(ctx, args.Source)
+	destination := t.resolve(ctx, args.Destination)
+
+	method := strings.ToLower(args.Method)
+	var cmd *exec.Cmd
+	switch method {
+	case "", "rsync":
+		method = "rsync"
+		cmdArgs := []string{"-a", "--stats", "--human-readable"}
+		if !args.Recursive {
+			cmdArgs = append(cmdArgs, "--no-recursive", "--dirs")
+		}
+		if args.DryRun {
+			cmdArgs = append(cmdArgs, "--dry-run", "--itemize-changes")
+		}
+		if args.BandwidthLimit > 0 {
+			cmdArgs = append(cmdArgs, "--bwlimit="+strconv.Itoa(args.BandwidthLimit))
+		}
+		if args.Delete {
+			cmdArgs = append(cmdArgs, "--delete")
+		}
+		ssh := append([]string{"ssh"}, transferSSHOptions...)
+		if args.Port > 0 {
+			ssh = append(ssh, "-p", strconv.Itoa(args.Port))
+		}
+		cmdArgs = append(cmdArgs, "-e", strings.Join(ssh, " "), source, destination)
+		cmd = targetCommand(ctx, "rsync", cmdArgs...)
+
+	case "scp":
+		if args.DryRun {
+			return "Error: scp has no dry-run mode; use method rsync with dryRun to preview the transfer", nil
+		}
+		if args.Delete {
+			return "Error: delete is only supported with method rsync", nil
+		}
+		cmdArgs := append([]string{"-p"}, transferSSHOptions...)
+		if args.Recursive {
+			cmdArgs = append(cmdArgs, "-r")
+		}
+		if args.BandwidthLimit > 0 {
+			// scp limits are in Kbit/s
+			cmdArgs = append(cmdArgs, "-l", strconv.Itoa(args.BandwidthLimit*8))
+		}
+		if args.Port > 0 {
+			cmdArgs = append(cmdArgs, "-P", strconv.Itoa(args.Port))
+		}
+		cmdArgs = append(cmdArgs, source, destination)
+		cmd = targetCommand(ctx, "scp", cmdArgs...)
+
+	default:
+		return fmt.Sprintf("Error: Unsupported method %q. Use rsync or scp", args.Method), nil
+	}
+	cmd.Dir = t.workspace.For(ctx).Dir()
+
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"method": method,
+			"output": string(output),
+		}).Error("Transfer failed")
+		if _, lookErr := exec.LookPath(method); lookErr != nil {
+			return fmt.Sprintf("Error: %s is not installed or not accessible", method), nil
+		}
+		return fmt.Sprintf("Error: %s failed: %s", method, strings.TrimSpace(string(output))), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"method":        method,
+		"source":        source,
+		"destination":   destination,
+		"dryRun":        args.DryRun,
+		"executionTime": time.Since(startTime),
+	}).Info("Transfer completed")
+
+	summary := fmt.Sprintf("Transferred %s to %s with %s", source, destination, method)
+	if args.DryRun {
+		summary = fmt.Sprintf("Dry run of %s to %s with %s (nothing was copied)", source, destination, method)
+	}
+	if text := strings.TrimSpace(string(output)); text != "" {
+		summary += "\n" + text
+	}
+	return summary, nil
+}
+
+// resolve makes local paths absolute relative to the working directory
+// and leaves remote [user@]host:path locations unchanged.
+func (t *TransferTool) resolve(ctx context.Context, path string) string {
+	if isRemotePath(path) || filepath.IsAbs(path) {
+		return path
+	}
+	resolved := filepath.Join(t.workspace.For(ctx).Dir(), path)
+	// Keep rsync's trailing-slash semantics (copy contents rather than the directory)
+	if strings.HasSuffix(path, "/") {
+		resolved += "/"
+	}
+	return resolved
+}
+
+// isRemotePath reports whether a path uses the [user@]host:path syntax.
+func isRemotePath(path string) bool {
+	colon := strings.Index(path, ":")
+	if colon <= 0 {
+		return false
+	}
+	return !strings.Contains(path[:colon], "/")
+}
+
+// Ensure TransferTool implements the tools.Tool interface
+var _ tools.Tool = (*TransferTool)(nil)
@@ -0,0 +1,314 @@
+/*
+Package tools provides nginx and Caddy web server management for the Skynet Agent.
+
+This file implements the WebServerTool, which covers the everyday web server
+tasks that otherwise go through the raw shell: validating the configuration,
+reloading it, listing the configured virtual hosts, and reading the access and
+error logs.
+
+The server is detected from the installed binaries (nginx is preferred when
+both are present) and can be chosen explicitly by starting the input with
+"nginx" or "caddy". A reload always validates the configuration first, so a
+broken configuration never takes the running server down.
+
+Supported operations:
+- test: Validate the configuration (nginx -t, caddy validate)
+- reload: Validate, then gracefully reload the configuration
+- vhosts: Configured sites with their listen addresses
+- logs access|error [lines]: Last lines of the access or error log
+*/
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// webServerLogger provides structured logging for all web server operations
+// with a consistent tool identifier for easy filtering and monitoring
+var webServerLogger = logrus.WithField("tool", "webserver")
+
+// webServerCaddyfile is the configuration file used for Caddy operations
+const webServerCaddyfile = "/etc/caddy/Caddyfile"
+
+// webServerDefaultLines is the number of log lines returned when none are requested
+const webServerDefaultLines = 50
+
+// webServerLogFiles maps each server and log kind to its conventional log file
+var webServerLogFiles = map[string]map[string]string{
+	"nginx": {"access": "/var/log/nginx/access.log", "error": "/var/log/nginx/error.log"},
+	"caddy": {"access": "/var/log/caddy/access.log", "error": "/var/log/caddy/error.log"},
+}
+
+// WebServerTool tests, reloads, and inspects nginx and Caddy.
+type WebServerTool struct{}
+
+// NewWebServerTool creates a new instance of the web server tool.
+// The tool requires nginx or caddy to be installed and accessible in the system PATH.
+//
+// Returns:
+//   - *WebServerTool: Configured web server tool ready for use
+func NewWebServerTool() *WebServerTool {
+	webServerLogger.Debug("Initializing webserver tool")
+	return &WebServerTool{}
+}
+
+// Description returns a description of the web server tool's operations.
+//
+// Returns:
+//   - string: Description of the supported web server operations
+func (w *WebServerTool) Description() string {
+	return "Manage the nginx or Caddy web server (detected automatically; prefix the input with 'nginx' or 'caddy' to choose). Usage: 'test' (validate the configuration), 'reload' (validate, then gracefully reload; nothing is reloaded when validation fails), 'vhosts' (configured sites and listen addresses), 'logs access [lines]' or 'logs error [lines]' (last lines of the logs, default 50). Edit configuration files with the file or text tool, then 'test' and 'reload'."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("webserver")
+func (w *WebServerTool) Name() string {
+	return "webserver"
+}
+
+// Call performs the requested web server operation.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Operation string (e.g., "test", "caddy reload", "logs error 100")
+//
+// Returns:
+//   - string: Operation result or error message
+//   - error: Always nil (errors are returned as string messages)
+func (w *WebServerTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := webServerLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Webserver tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.ToLower(strings.TrimSpace(input)))
+	server := ""
+	if len(parts) > 0 && (parts[0] == "nginx" || parts[0] == "caddy") {
+		server, parts = parts[0], parts[1:]
+	}
+	if len(parts) == 0 {
+		return "Error: Please provide an operation: test, reload, vhosts, or logs", nil
+	}
+	if server == "" {
+		server = detectWebServer()
+		if server == "" {
+			return "Error: Neither nginx nor caddy is installed", nil
+		}
+	}
+
+	operation := parts[0]
+	var result string
+	switch operation {
+	case "test":
+		result = w.test(ctx, server)
+	case "reload":
+		if output, ok := w.validate(ctx, server); !ok {
+			toolLogger.WithField("server", server).Warn("Reload skipped because the configuration is invalid")
+			result = fmt.Sprintf("Error: %s configuration is invalid, not reloading:\n%s", server, output)
+			break
+		}
+		result = w.reload(ctx, server)
+	case "vhosts":
+		result = w.vhosts(ctx, server)
+	case "logs":
+		kind := "access"
+		lines := webServerDefaultLines
+		if len(parts) > 1 {
+			kind = parts[1]
+		}
+		if len(parts) > 2 {
+			if n, err := strconv.Atoi(parts[2]); err == nil && n > 0 {
+				lines = n
+			}
+		}
+		result = w.logs(server, kind, lines)
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use test, reload, vhosts, or logs", operation), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"server":        server,
+		"operation":     operation,
+		"executionTime": time.Since(startTime),
+	}).Info("Webserver operation completed")
+
+	return result, nil
+}
+
+// detectWebServer returns the installed web server, preferring nginx, or "" when none is installed.
+func detectWebServer() string {
+	for _, server := range []string{"nginx", "caddy"} {
+		if _, err := exec.LookPath(server); err == nil {
+			return server
+		}
+	}
+	return ""
+}
+
+// validate checks the configuration and returns the validator output and whether it passed.
+func (w *WebServerTool) validate(ctx context.Context, server string) (string, bool) {
+	var cmd *exec.Cmd
+	if server == "nginx" {
+		cmd = targetCommand(ctx, "nginx", "-t")
+	} else {
+		cmd = targetCommand(ctx, "caddy", "validate", "--config", webServerCaddyfile)
+	}
+	output, err := limitedCombinedOutput(ctx, cmd)
+	return strings.TrimSpace(string(output)), err == nil
+}
+
+// test reports the result of validating the configuration.
+func (w *WebServerTool) test(ctx context.Context, server string) string {
+	output, ok := w.validate(ctx, server)
+	if !ok {
+		return fmt.Sprintf("Error: %s configuration is invalid:\n%s", server, output)
+	}
+	return fmt.Sprintf("%s configuration is valid\n%s", server, output)
+}
+
+// reload gracefully reloads the running server with the current configuration.
+func (w *WebServerTool) reload(ctx context.Context, server string) string {
+	// Execute command; the caller's context bounds execution time
+	var cmd *exec.Cmd
+	if server == "nginx" {
+		cmd = targetCommand(ctx, "nginx", "-s", "reload")
+	} else {
+		cmd = targetCommand(ctx, "caddy", "reload", "--config", webServerCaddyfile)
+	}
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		webServerLogger.WithError(err).WithFields(logrus.Fields{
+			"server": server,
+			"output": string(output),
+		}).Error("Web server reload failed")
+		return fmt.Sprintf("Error: %s reload failed (is it running?): %s", server, strings.TrimSpace(string(output)))
+	}
+	return fmt.Sprintf("%s configuration validated and reloaded", server)
+}
+
+// vhosts lists the configured sites and their listen addresses.
+func (w *WebServerTool) vhosts(ctx context.Context, server string) string {
+	var config string
+	if server == "nginx" {
+		// nginx -T prints the full configuration with all includes resolved
+		output, err := limitedCombinedOutput(ctx, targetCommand(ctx, "nginx", "-T"))
+		if err != nil {
+			return fmt.Sprintf("Error: Failed to read nginx configuration: %s", strings.TrimSpace(string(output)))
+		}
+		config = string(output)
+	} else {
+		content, err := os.ReadFile(webServerCaddyfile)
+		if err != nil {
+			return fmt.Sprintf("Error: Failed to read %s: %v", webServerCaddyfile, err)
+		}
+		config = string(content)
+	}
+
+	var sites []string
+	if server == "nginx" {
+		sites = nginxVhosts(config)
+	} else {
+		sites = caddyVhosts(config)
+	}
+	if len(sites) == 0 {
+		return fmt.Sprintf("No sites found in the %s configuration", server)
+	}
+	return strings.Join(sites, "\n")
+}
+
+// nginxVhosts summarizes each server block of a full nginx configuration.
+func nginxVhosts(config string) []string {
+	var sites []string
+	var names, listens []string
+	file := ""
+	depth, serverDepth := 0, -1
+
+	scanner := bufio.NewScanner(strings.NewReader(config))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "# configuration file ") {
+			file = strings.TrimSuffix(strings.TrimPrefix(line, "# configuration file "), ":")
+			continue
+		}
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		if serverDepth < 0 && (line == "server {" || line == "server{") {
+			serverDepth = depth
+			names, listens = nil, nil
+		}
+		if serverDepth >= 0 && depth == serverDepth+1 {
+			if fields := strings.Fields(strings.TrimSuffix(line, ";")); len(fields) > 1 {
+				switch fields[0] {
+				case "server_name":
+					names = append(names, fields[1:]...)
+				case "listen":
+					listens = append(listens, strings.Join(fields[1:], " "))
+				}
+			}
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if serverDepth >= 0 && depth <= serverDepth {
+			if len(names) == 0 {
+				names = []string{"_"}
+			}
+			sites = append(sites, fmt.Sprintf("%s (listen: %s; file: %s)", strings.Join(names, " "), strings.Join(listens, ", "), file))
+			serverDepth = -1
+		}
+	}
+	return sites
+}
+
+// caddyVhosts lists the site addresses declared at the top level of a Caddyfile.
+func caddyVhosts(config string) []string {
+	var sites []string
+	depth := 0
+	scanner := bufio.NewScanner(strings.NewReader(config))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Site blocks open at depth 0; the global options block is "{" alone and snippets are "(name)"
+		if depth == 0 && strings.HasSuffix(line, "{") && line != "{" && !strings.HasPrefix(line, "(") {
+			sites = append(sites, strings.TrimSpace(strings.TrimSuffix(line, "{")))
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+	}
+	return sites
+}
+
+// logs returns the last lines of the server's access or error log.
+func (w *WebServerTool) logs(server, kind string, lines int) string {
+	path, ok := webServerLogFiles[server][kind]
+	if !ok {
+		return fmt.Sprintf("Error: Unknown log %q. Use access or error", kind)
+	}
+	entries, err := tailLines(path, lines)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: %s does not exist; the %s log may be configured elsewhere or go to the journal (try the logs tool with unit %s)", path, server, server)
+		}
+		return fmt.Sprintf("Error: Failed to read %s: %v", path, err)
+	}
+	if len(entries) == 0 {
+		return fmt.Sprintf("%s is empty", path)
+	}
+	return fmt.Sprintf("Last %d lines of %s:\n%s", len(entries), path, strings.Join(entries, "\n"))
+}
+
+// Ensure WebServerTool implements the tools.Tool interface
+var _ tools.Tool = (*WebServerTool)(nil)
@@ -0,0 +1,196 @@
+/*
+Package tools provides WireGuard VPN diagnostics and management for the Skynet Agent.
+
+This file implements the WgTool, which wraps wg and wg-quick so the agent can
+diagnose VPN connectivity on edge boxes (latest handshakes, transfer counters,
+endpoints) and manage peers and interfaces.
+
+Supported operations:
+- show [interface]: Interface and peer status (private keys are never shown)
+- peer add <interface> <public key> <allowed ips> [endpoint] [keepalive]: Add or update a peer
+- peer remove <interface> <public key>: Remove a peer
+- up <interface>: Bring an interface up from /etc/wireguard/<interface>.conf
+- down <interface>: Take an interface down
+
+Peer changes apply to the running interface only; they are lost when the
+interface is restarted unless the configuration file is updated as well.
+*/
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// wgLogger provides structured logging for all WireGuard operations
+// with a consistent tool identifier for easy filtering and monitoring
+var wgLogger = logrus.WithField("tool", "wireguard")
+
+// wgInterfacePattern matches valid interface names
+var wgInterfacePattern = regexp.MustCompile(`^[A-Za-z0-9_=+.\-]{1,15}$`)
+
+// wgKeyPattern matches base64-encoded WireGuard public keys
+var wgKeyPattern = regexp.MustCompile(`^[A-Za-z0-9+/]{42}[AEIMQUYcgkosw480]=$`)
+
+// WgTool inspects and manages WireGuard interfaces and peers.
+type WgTool struct{}
+
+// NewWgTool creates a new instance of the WireGuard tool.
+// The tool requires wireguard-tools (wg and wg-quick) and root privileges.
+//
+// Returns:
+//   - *WgTool: Configured WireGuard tool ready for use
+func NewWgTool() *WgTool {
+	wgLogger.Debug("Initializing wireguard tool")
+	return &WgTool{}
+}
+
+// Description returns a description of the WireGuard tool's operations.
+//
+// Returns:
+//   - string: Description of the supported WireGuard operations
+func (w *WgTool) Description() string {
+	return "Diagnose and manage WireGuard VPN connectivity. Usage: 'show [interface]' (peers, endpoints, latest handshakes, transfer; a handshake older than about 2 minutes means the tunnel is not working), 'peer add <interface> <public key> <allowed ips> [endpoint host:port] [keepalive seconds]' (add or update a peer, e.g. 'peer add wg0 xTIB...= 10.0.0.2/32 203.0.113.5:51820 25'), 'peer remove <interface> <public key>', 'up <interface>' and 'down <interface>' (wg-quick with /etc/wireguard/<interface>.conf). Peer changes are not written to the configuration file."
+}
+
+// Name returns the identifier for this tool.
+//
+// Returns:
+//   - string: The tool's identifier ("wireguard")
+func (w *WgTool) Name() string {
+	return "wireguard"
+}
+
+// Call runs the requested WireGuard operation.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - input: Operation string (e.g., "show wg0", "peer remove wg0 <key>", "up wg0")
+//
+// Returns:
+//   - string: Command output or error message
+//   - error: Always nil (errors are returned as string messages)
+func (w *WgTool) Call(ctx context.Context, input string) (string, error) {
+	toolLogger := wgLogger.WithContext(ctx).WithField("input", input)
+	toolLogger.Info("Wireguard tool called")
+	startTime := time.Now()
+
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) == 0 {
+		return "Error: Please provide an operation: show, peer add, peer remove, up, or down", nil
+	}
+
+	operation := strings.ToLower(parts[0])
+	var program string
+	var args []string
+	switch operation {
+	case "show":
+		program = "wg"
+		args = []string{"show"}
+		if len(parts) > 1 {
+			if !wgInterfacePattern.MatchString(parts[1]) {
+				return fmt.Sprintf("Error: Invalid interface name %q", parts[1]), nil
+			}
+			args = append(args, parts[1])
+		}
+
+	case "peer":
+		peerArgs, errMsg := wgPeerArgs(parts[1:])
+		if errMsg != "" {
+			return errMsg, nil
+		}
+		program = "wg"
+		args = peerArgs
+		operation = "peer " + strings.ToLower(parts[1])
+
+	case "up", "down":
+		if len(parts) != 2 || !wgInterfacePattern.MatchString(parts[1]) {
+			return fmt.Sprintf("Error: Please provide a valid interface name for %s, e.g. '%s wg0'", operation, operation), nil
+		}
+		program = "wg-quick"
+		args = []string{operation, parts[1]}
+
+	default:
+		return fmt.Sprintf("Error: Unsupported operation %q. Use show, peer add, peer remove, up, or down", operation), nil
+	}
+
+	// Execute command; the caller's context bounds execution time
+	cmd := targetCommand(ctx, program, args...)
+	output, err := limitedCombinedOutput(ctx, cmd)
+	if err != nil {
+		toolLogger.WithError(err).WithFields(logrus.Fields{
+			"operation": operation,
+			"output":    string(output),
+		}).Error("Wireguard command failed")
+
+		if _, lookErr := exec.LookPath(program); lookErr != nil {
+			return fmt.Sprintf("Error: %s is not installed (provided by the wireguard-tools package)", program), nil
+		}
+		return fmt.Sprintf("Error: %s failed: %s", operation, strings.TrimSpace(string(output))), nil
+	}
+
+	toolLogger.WithFields(logrus.Fields{
+		"operation":     operation,
+		"executionTime": time.Since(startTime),
+		"outputLength":  len(output),
+	}).Info("Wireguard command completed")
+
+	if len(output) == 0 {
+		switch {
+		case operation == "show":
+			return "No WireGuard interfaces are up", nil
+		case strings.HasPrefix(operation, "peer"):
+			return fmt.Sprintf("%s completed on %s (not persisted to the configuration file)", operation, parts[2]), nil
+		}
+		return fmt.Sprintf("%s completed", operation), nil
+	}
+	return string(output), nil
+}
+
+// wgPeerArgs validates a peer add or remove request and builds the wg set arguments.
+// It returns an error message instead of arguments when the request is invalid.
+func wgPeerArgs(parts []string) ([]string, string) {
+	if len(parts) < 3 {
+		return nil, "Error: Usage: 'peer add <interface> <public key> <allowed ips> [endpoint] [keepalive]' or 'peer remove <interface> <public key>'"
+	}
+	action, iface, key := strings.ToLower(parts[0]), parts[1], parts[2]
+	if !wgInterfacePattern.MatchString(iface) {
+		return nil, fmt.Sprintf("Error: Invalid interface name %q", iface)
+	}
+	if !wgKeyPattern.MatchString(key) {
+		return nil, fmt.Sprintf("Error: Invalid public key %q, expected a 44-character base64 WireGuard key", key)
+	}
+
+	switch action {
+	case "remove":
+		return []string{"set", iface, "peer", key, "remove"}, ""
+	case "add":
+		if len(parts) < 4 {
+			return nil, "Error: Please provide the peer's allowed IPs, e.g. 10.0.0.2/32"
+		}
+		args := []string{"set", iface, "peer", key, "allowed-ips", parts[3]}
+		if len(parts) > 4 {
+			args = append(args, "endpoint", parts[4])
+		}
+		if len(parts) > 5 {
+			if _, err := strconv.Atoi(parts[5]); err != nil {
+				return nil, fmt.Sprintf("Error: Invalid keepalive %q, expected seconds", parts[5])
+			}
+			args = append(args, "persistent-keepalive", parts[5])
+		}
+		return args, ""
+	default:
+		return nil, fmt.Sprintf("Error: Unsupported peer action %q. Use add or remove", action)
+	}
+}
+
+// Ensure WgTool implements the tools.Tool interface
+var _ tools.Tool = (*WgTool)(nil)
@@ -0,0 +1,167 @@
+/*
+Package tools provides the shared workspace state for the Skynet Agent.
+
+This file implements the WorkspaceContext, which holds the agent's current working
+directory and environment variables. A default context is injected into every tool, so a directory change made
+by the cd tool is seen by ls, grep, shell, and all other tools that resolve relative
+paths, and by the GET /workspace endpoint that reports it.
+
+Chat sessions get their own workspace, attached to the execution context with
+WithWorkspace. Tools resolve the workspace at execution time with For, so "cd /etc"
+in one conversation does not redirect the file operations of another. Environment
+variables set on a session workspace, such as credentials or a KUBECONFIG path, are
+injected into the commands that tools run for that session without changing the
+environment of the server process.
+*/
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// workspaceKey is the unexported context key type for session workspaces
+type workspaceKey struct{}
+
+// WorkspaceContext holds the working directory shared by all tools.
+// It is safe for concurrent use.
+type WorkspaceContext struct {
+	mu  sync.RWMutex
+	dir *string           // Current working directory; the pointer is handed to Go plugins
+	env map[string]string // Variables added to the environment of commands run by tools
+}
+
+// NewWorkspaceContext creates a workspace rooted at the given directory.
+//
+// Parameters:
+//   - dir: Initial working directory
+//
+// Returns:
+//   - *WorkspaceContext: Workspace ready to be shared by the tools
+func NewWorkspaceContext(dir string) *WorkspaceContext {
+	return &WorkspaceContext{dir: &dir, env: make(map[string]string)}
+}
+
+// WithWorkspace returns a copy of the parent context carrying a session workspace
+// that takes precedence over the default workspace injected into the tools.
+//
+// Parameters:
+//   - ctx: Parent context
+//   - workspace: Workspace of the session the execution belongs to
+//
+// Returns:
+//   - context.Context: Derived context carrying the workspace
+func WithWorkspace(ctx context.Context, workspace *WorkspaceContext) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspace)
+}
+
+// WorkspaceFromContext extracts a session workspace previously attached with WithWorkspace.
+//
+// Parameters:
+//   - ctx: Context to inspect
+//
+// Returns:
+//   - *WorkspaceContext: The attached workspace, or nil if none
+//   - bool: Whether a workspace was present
+func WorkspaceFromContext(ctx context.Context) (*WorkspaceContext, bool) {
+	workspace, ok := ctx.Value(workspaceKey{}).(*WorkspaceContext)
+	return workspace, ok && workspace != nil
+}
+
+// For returns the workspace attached to the context with WithWorkspace, or w when
+// the execution does not belong to a session.
+func (w *WorkspaceContext) For(ctx context.Context) *WorkspaceContext {
+	if workspace, ok := WorkspaceFromContext(ctx); ok {
+		return workspace
+	}
+	return w
+}
+
+// Dir returns the current working directory.
+func (w *WorkspaceContext) Dir() string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return *w.dir
+}
+
+// SetDir changes the current working directory.
+// The caller is responsible for checking that the directory exists.
+func (w *WorkspaceContext) SetDir(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	*w.dir = dir
+}
+
+// Resolve makes a path absolute relative to the current working directory.
+func (w *WorkspaceContext) Resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(w.Dir(), path)
+}
+
+// DirRef returns a pointer to the working directory for Go plugins built against
+// the "func Tools(workingDir *string)" contract. It follows SetDir, but reads through
+// it bypass the workspace lock.
+func (w *WorkspaceContext) DirRef() *string {
+	return w.dir
+}
+
+// SetEnv sets an environment variable for the commands run in this workspace.
+func (w *WorkspaceContext) SetEnv(name, value string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.env[name] = value
+}
+
+// UnsetEnv removes an environment variable previously set with SetEnv.
+func (w *WorkspaceContext) UnsetEnv(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.env, name)
+}
+
+// EnvNames returns the sorted names of the variables set with SetEnv.
+// Values are not exposed because they often hold credentials.
+func (w *WorkspaceContext) EnvNames() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	names := make([]string, 0, len(w.env))
+	for name := range w.env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Environ returns the environment for a command run in this workspace: the server
+// process environment with the workspace variables applied. It returns nil when no
+// variables are set, so exec.Cmd inherits the process environment unchanged.
+func (w *WorkspaceContext) Environ() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if len(w.env) == 0 {
+		return nil
+	}
+	// exec.Cmd keeps the last value of duplicate variables, so the workspace values win
+	environ := os.Environ()
+	for name, value := range w.env {
+		environ = append(environ, name+"="+value)
+	}
+	return environ
+}
+
+// sessionEnviron returns the environment of the session workspace attached to the
+// context, or nil to inherit the process environment, for tools that do not resolve
+// paths against a workspace.
+func sessionEnviron(ctx context.Context) []string {
+	if workspace, ok := WorkspaceFromContext(ctx); ok {
+		return workspace.Environ()
+	}
+	return nil
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"skynet/core"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+// tuiToolOutputLines is the number of lines of tool output shown per tool call
+const tuiToolOutputLines = 10
+
+// tuiHelp lists the commands of the terminal UI.
+const tuiHelp = `Commands:
+  /sessions       List the sessions on the server
+  /switch <id>    Continue another session
+  /new            Start a new session
+  /help           Show this help
+  /quit           Leave (also "exit", or Ctrl-D)
+Ctrl-C stops the running request.`
+
+// Styles of the terminal UI output
+var (
+	tuiPromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("6")) // Session prompt
+	tuiToolStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("6")) // Tool name of a tool call
+	tuiOutputStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("4")) // Tool output
+	tuiNoticeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("3")) // Queue, thinking, and stop notices
+	tuiErrorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // Errors
+)
+
+// tuiClient talks to a Skynet server over its HTTP API.
+type tuiClient struct {
+	server string       // Base URL of the server
+	client *http.Client // Client without a timeout, for streaming
+}
+
+// tuiModel is the bubbletea model of the terminal UI. Finished output is printed
+// above the program, so it stays in the terminal's scrollback, and the view only
+// holds the prompt, or a spinner while a request runs.
+type tuiModel struct {
+	tui         *tuiClient
+	input       textinput.Model
+	spinner     spinner.Model
+	sessionID   string             // Session of the conversation; empty starts a new one
+	running     bool               // Whether a request is running
+	events      chan tea.Msg       // Events of the running request
+	executionID string             // Execution of the running request, once the server started it
+	cancelRun   context.CancelFunc // Cancels the running request
+}
+
+// tuiPrintMsg is output to print above the program.
+type tuiPrintMsg string
+
+// tuiEventMsg is output of the running request to print above the program.
+type tuiEventMsg string
+
+// tuiSessionMsg reports the session of the running request.
+type tuiSessionMsg string
+
+// tuiExecutionMsg reports the execution ID of the running request.
+type tuiExecutionMsg string
+
+// tuiDoneMsg reports the end of the running request.
+type tuiDoneMsg struct{}
+
+// runTUI runs the terminal UI against a local or remote server. Unlike chat, it
+// runs no agent itself: messages are sent to the server's streaming chat API, and
+// the agent's thinking and tool events are shown live as they arrive.
+//
+// Parameters:
+//   - serverURL: Base URL of the server
+//   - sessionID: Session to continue; empty starts a new session
+//
+// Returns:
+//   - int: Process exit code
+func runTUI(serverURL, sessionID string) int {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintln(os.Stderr, "skynet tui needs an interactive terminal; use exec for scripts")
+		return 2
+	}
+
+	input := textinput.New()
+	input.Focus()
+	model := &tuiModel{
+		tui: &tuiClient{
+			server: strings.TrimSuffix(serverURL, "/"),
+			client: &http.Client{},
+		},
+		input:     input,
+		spinner:   spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(tuiNoticeStyle)),
+		sessionID: sessionID,
+	}
+	model.updatePrompt()
+
+	if _, err := tea.NewProgram(model).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to run the terminal UI: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// Init prints the greeting.
+func (m *tuiModel) Init() tea.Cmd {
+	return tea.Sequence(
+		tea.Println(fmt.Sprintf("Skynet %s client connected to %s. Type /help for commands.", core.Version, m.tui.server)),
+		textinput.Blink,
+	)
+}
+
+// Update handles keys, window changes, and the events of the running request.
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.input.Width = msg.Width - lipgloss.Width(m.input.Prompt) - 1
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC:
+			if m.running {
+				return m, m.stop()
+			}
+			m.input.Reset()
+			return m, nil
+		case tea.KeyCtrlD:
+			if !m.running && m.input.Value() == "" {
+				return m, tea.Quit
+			}
+		case tea.KeyEnter:
+			if !m.running {
+				return m.submit()
+			}
+		}
+		if m.running {
+			return m, nil
+		}
+
+	case tuiPrintMsg:
+		return m, tea.Println(string(msg))
+
+	case tuiEventMsg:
+		return m, tea.Sequence(tea.Println(string(msg)), m.nextEvent())
+
+	case tuiSessionMsg:
+		m.sessionID = string(msg)
+		m.updatePrompt()
+		return m, m.nextEvent()
+
+	case tuiExecutionMsg:
+		m.executionID = string(msg)
+		return m, m.nextEvent()
+
+	case tuiDoneMsg:
+		m.running = false
+		m.executionID = ""
+		m.cancelRun()
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// View shows the prompt, or a spinner while a request runs.
+func (m *tuiModel) View() string {
+	if m.running {
+		return m.spinner.View() + tuiNoticeStyle.Render(" Working, Ctrl-C stops") + "\n"
+	}
+	return m.input.View() + "\n"
+}
+
+// updatePrompt shows the current session in the prompt.
+func (m *tuiModel) updatePrompt() {
+	sessionID := m.sessionID
+	if sessionID == "" {
+		sessionID = "new"
+	}
+	m.input.Prompt = tuiPromptStyle.Render("["+sessionID+"]") + " skynet> "
+}
+
+// submit runs the entered line as a command or sends it to the agent.
+func (m *tuiModel) submit() (tea.Model, tea.Cmd) {
+	line := m.input.Value()
+	m.input.Reset()
+	message := strings.TrimSpace(line)
+	if message == "" {
+		return m, nil
+	}
+	echo := tea.Println(m.input.Prompt + line)
+
+	if strings.HasPrefix(message, "/") || message == "exit" || message == "quit" {
+		command, argument, _ := strings.Cut(message, " ")
+		switch command {
+		case "/quit", "/exit", "exit", "quit":
+			return m, tea.Sequence(echo, tea.Quit)
+		case "/help":
+			return m, tea.Sequence(echo, tea.Println(tuiHelp))
+		case "/new":
+			m.sessionID = ""
+			m.updatePrompt()
+			return m, tea.Sequence(echo, tea.Println("Starting a new session"))
+		case "/switch":
+			if argument = strings.TrimSpace(argument); argument == "" {
+				return m, tea.Sequence(echo, tea.Println("Usage: /switch <session id>"))
+			}
+			m.sessionID = argument
+			m.updatePrompt()
+			return m, tea.Sequence(echo, tea.Println("Switched to session "+argument))
+		case "/sessions":
+			tui, current := m.tui, m.sessionID
+			return m, tea.Sequence(echo, func() tea.Msg {
+				return tuiPrintMsg(tui.listSessions(current))
+			})
+		default:
+			return m, tea.Sequence(echo, tea.Println(fmt.Sprintf("Unknown command %s; type /help for commands", command)))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.running = true
+	m.cancelRun = cancel
+	m.events = make(chan tea.Msg, 64)
+	go m.tui.stream(ctx, m.sessionID, message, m.events)
+	return m, tea.Batch(tea.Sequence(echo, m.nextEvent()), m.spinner.Tick)
+}
+
+// nextEvent waits for the next event of the running request.
+func (m *tuiModel) nextEvent() tea.Cmd {
+	events := m.events
+	return func() tea.Msg {
+		if msg, ok := <-events; ok {
+			return msg
+		}
+		return tuiDoneMsg{}
+	}
+}
+
+// stop stops the running request. A started execution is stopped on the server;
+// a queued request just leaves the queue.
+func (m *tuiModel) stop() tea.Cmd {
+	tui, executionID, cancel := m.tui, m.executionID, m.cancelRun
+	if executionID == "" {
+		cancel()
+		return nil
+	}
+	return func() tea.Msg {
+		if tui.stop(executionID) != nil {
+			cancel()
+		}
+		return nil
+	}
+}
+
+// stream sends a message to the streaming chat API and reports its events until
+// the execution ends, closing events when done.
+func (t *tuiClient) stream(ctx context.Context, sessionID, message string, events chan<- tea.Msg) {
+	defer close(events)
+	payload, _ := json.Marshal(core.ChatRequest{Message: message, SessionID: sessionID, Verbosity: core.VerbosityTools})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.server+"/chat/stream", bytes.NewReader(payload))
+	if err != nil {
+		events <- tuiEventMsg(tuiErrorStyle.Render(err.Error()))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			events <- tuiEventMsg(tuiNoticeStyle.Render("Stopped"))
+		} else {
+			events <- tuiEventMsg(tuiErrorStyle.Render(fmt.Sprintf("Failed to reach the server: %v", err)))
+		}
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		events <- tuiEventMsg(tuiErrorStyle.Render(responseError(resp)))
+		return
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		data, found := strings.CutPrefix(scanner.Text(), "data: ")
+		if !found {
+			continue
+		}
+		var msg core.StreamMessage
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			continue
+		}
+
+		switch msg.Type {
+		case "session":
+			events <- tuiSessionMsg(msg.Content)
+		case "execution_started":
+			events <- tuiExecutionMsg(msg.Content)
+		case "queued", "thinking":
+			events <- tuiEventMsg(tuiNoticeStyle.Render("… " + msg.Content))
+		case "tool":
+			input, _ := msg.Details["input"].(string)
+			lines := strings.Split(strings.TrimRight(msg.Content, "\n"), "\n")
+			if len(lines) > tuiToolOutputLines {
+				lines = append(lines[:tuiToolOutputLines], fmt.Sprintf("... (%d more lines)", len(lines)-tuiToolOutputLines))
+			}
+			rendered := []string{tuiToolStyle.Render("⚙ "+msg.Tool) + " " + strings.TrimSpace(input)}
+			for _, line := range lines {
+				rendered = append(rendered, tuiOutputStyle.Render("  "+line))
+			}
+			events <- tuiEventMsg(strings.Join(rendered, "\n"))
+		case "response":
+			events <- tuiEventMsg(msg.Content)
+		case "error":
+			events <- tuiEventMsg(tuiErrorStyle.Render(msg.Content))
+		case "stopped":
+			events <- tuiEventMsg(tuiNoticeStyle.Render(msg.Content))
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		events <- tuiEventMsg(tuiErrorStyle.Render(fmt.Sprintf("Connection lost: %v", err)))
+	} else if ctx.Err() != nil {
+		events <- tuiEventMsg(tuiNoticeStyle.Render("Stopped"))
+	}
+}
+
+// stop asks the server to stop an execution.
+func (t *tuiClient) stop(executionID string) error {
+	payload, _ := json.Marshal(core.StopRequest{ExecutionID: executionID})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.server+"/stop", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", responseError(resp))
+	}
+	return nil
+}
+
+// listSessions returns the sessions on the server, most recently active first.
+func (t *tuiClient) listSessions(current string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.server+"/sessions", nil)
+	if err != nil {
+		return tuiErrorStyle.Render(err.Error())
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return tuiErrorStyle.Render(fmt.Sprintf("Failed to reach the server: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return tuiErrorStyle.Render(responseError(resp))
+	}
+
+	var result struct {
+		Sessions []struct {
+			ID       string             `json:"id"`
+			Messages []core.ChatMessage `json:"messages"`
+			Updated  time.Time          `json:"updated"`
+		} `json:"sessions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return tuiErrorStyle.Render(fmt.Sprintf("Invalid sessions response: %v", err))
+	}
+	if len(result.Sessions) == 0 {
+		return "No sessions"
+	}
+	sort.Slice(result.Sessions, func(i, j int) bool {
+		return result.Sessions[i].Updated.After(result.Sessions[j].Updated)
+	})
+	lines := make([]string, 0, len(result.Sessions))
+	for _, session := range result.Sessions {
+		marker := " "
+		if session.ID == current {
+			marker = "*"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s  %d messages, active %s ago", marker, session.ID, len(session.Messages), time.Since(session.Updated).Round(time.Second)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// responseError returns the error message of a failed API response.
+func responseError(resp *http.Response) string {
+	var body struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body)
+	if body.Error != "" {
+		return body.Error
+	}
+	if body.Message != "" {
+		return body.Message
+	}
+	return "Server returned " + resp.Status
+}